@@ -19,21 +19,30 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DpiBypassService_Health_FullMethodName             = "/dpi.DpiBypassService/Health"
-	DpiBypassService_CreateBypassConfig_FullMethodName = "/dpi.DpiBypassService/CreateBypassConfig"
-	DpiBypassService_GetBypassConfig_FullMethodName    = "/dpi.DpiBypassService/GetBypassConfig"
-	DpiBypassService_ListBypassConfigs_FullMethodName  = "/dpi.DpiBypassService/ListBypassConfigs"
-	DpiBypassService_UpdateBypassConfig_FullMethodName = "/dpi.DpiBypassService/UpdateBypassConfig"
-	DpiBypassService_DeleteBypassConfig_FullMethodName = "/dpi.DpiBypassService/DeleteBypassConfig"
-	DpiBypassService_StartBypass_FullMethodName        = "/dpi.DpiBypassService/StartBypass"
-	DpiBypassService_StopBypass_FullMethodName         = "/dpi.DpiBypassService/StopBypass"
-	DpiBypassService_GetBypassStatus_FullMethodName    = "/dpi.DpiBypassService/GetBypassStatus"
-	DpiBypassService_GetBypassStats_FullMethodName     = "/dpi.DpiBypassService/GetBypassStats"
-	DpiBypassService_GetBypassHistory_FullMethodName   = "/dpi.DpiBypassService/GetBypassHistory"
-	DpiBypassService_AddBypassRule_FullMethodName      = "/dpi.DpiBypassService/AddBypassRule"
-	DpiBypassService_UpdateBypassRule_FullMethodName   = "/dpi.DpiBypassService/UpdateBypassRule"
-	DpiBypassService_DeleteBypassRule_FullMethodName   = "/dpi.DpiBypassService/DeleteBypassRule"
-	DpiBypassService_ListBypassRules_FullMethodName    = "/dpi.DpiBypassService/ListBypassRules"
+	DpiBypassService_Health_FullMethodName                   = "/dpi.DpiBypassService/Health"
+	DpiBypassService_CreateBypassConfig_FullMethodName       = "/dpi.DpiBypassService/CreateBypassConfig"
+	DpiBypassService_GetBypassConfig_FullMethodName          = "/dpi.DpiBypassService/GetBypassConfig"
+	DpiBypassService_ListBypassConfigs_FullMethodName        = "/dpi.DpiBypassService/ListBypassConfigs"
+	DpiBypassService_UpdateBypassConfig_FullMethodName       = "/dpi.DpiBypassService/UpdateBypassConfig"
+	DpiBypassService_DeleteBypassConfig_FullMethodName       = "/dpi.DpiBypassService/DeleteBypassConfig"
+	DpiBypassService_StartBypass_FullMethodName              = "/dpi.DpiBypassService/StartBypass"
+	DpiBypassService_StopBypass_FullMethodName               = "/dpi.DpiBypassService/StopBypass"
+	DpiBypassService_GetBypassStatus_FullMethodName          = "/dpi.DpiBypassService/GetBypassStatus"
+	DpiBypassService_GetBypassStats_FullMethodName           = "/dpi.DpiBypassService/GetBypassStats"
+	DpiBypassService_GetBypassHistory_FullMethodName         = "/dpi.DpiBypassService/GetBypassHistory"
+	DpiBypassService_GetCensorshipSignals_FullMethodName     = "/dpi.DpiBypassService/GetCensorshipSignals"
+	DpiBypassService_AddBypassRule_FullMethodName            = "/dpi.DpiBypassService/AddBypassRule"
+	DpiBypassService_UpdateBypassRule_FullMethodName         = "/dpi.DpiBypassService/UpdateBypassRule"
+	DpiBypassService_DeleteBypassRule_FullMethodName         = "/dpi.DpiBypassService/DeleteBypassRule"
+	DpiBypassService_ListBypassRules_FullMethodName          = "/dpi.DpiBypassService/ListBypassRules"
+	DpiBypassService_AddBypassUser_FullMethodName            = "/dpi.DpiBypassService/AddBypassUser"
+	DpiBypassService_RevokeBypassUser_FullMethodName         = "/dpi.DpiBypassService/RevokeBypassUser"
+	DpiBypassService_ListBypassUsers_FullMethodName          = "/dpi.DpiBypassService/ListBypassUsers"
+	DpiBypassService_GetShareLink_FullMethodName             = "/dpi.DpiBypassService/GetShareLink"
+	DpiBypassService_CreateSubscriptionToken_FullMethodName  = "/dpi.DpiBypassService/CreateSubscriptionToken"
+	DpiBypassService_RevokeSubscriptionToken_FullMethodName  = "/dpi.DpiBypassService/RevokeSubscriptionToken"
+	DpiBypassService_ListSubscriptionTokens_FullMethodName   = "/dpi.DpiBypassService/ListSubscriptionTokens"
+	DpiBypassService_ListSubscriptionAccesses_FullMethodName = "/dpi.DpiBypassService/ListSubscriptionAccesses"
 )
 
 // DpiBypassServiceClient is the client API for DpiBypassService service.
@@ -57,11 +66,22 @@ type DpiBypassServiceClient interface {
 	// Statistics and monitoring
 	GetBypassStats(ctx context.Context, in *GetBypassStatsRequest, opts ...grpc.CallOption) (*BypassStats, error)
 	GetBypassHistory(ctx context.Context, in *GetBypassHistoryRequest, opts ...grpc.CallOption) (*GetBypassHistoryResponse, error)
+	GetCensorshipSignals(ctx context.Context, in *GetCensorshipSignalsRequest, opts ...grpc.CallOption) (*GetCensorshipSignalsResponse, error)
 	// Rule management
 	AddBypassRule(ctx context.Context, in *AddBypassRuleRequest, opts ...grpc.CallOption) (*BypassRule, error)
 	UpdateBypassRule(ctx context.Context, in *UpdateBypassRuleRequest, opts ...grpc.CallOption) (*BypassRule, error)
 	DeleteBypassRule(ctx context.Context, in *DeleteBypassRuleRequest, opts ...grpc.CallOption) (*DeleteBypassRuleResponse, error)
 	ListBypassRules(ctx context.Context, in *ListBypassRulesRequest, opts ...grpc.CallOption) (*ListBypassRulesResponse, error)
+	// User management
+	AddBypassUser(ctx context.Context, in *AddBypassUserRequest, opts ...grpc.CallOption) (*BypassUser, error)
+	RevokeBypassUser(ctx context.Context, in *RevokeBypassUserRequest, opts ...grpc.CallOption) (*RevokeBypassUserResponse, error)
+	ListBypassUsers(ctx context.Context, in *ListBypassUsersRequest, opts ...grpc.CallOption) (*ListBypassUsersResponse, error)
+	// Share links and subscriptions
+	GetShareLink(ctx context.Context, in *GetShareLinkRequest, opts ...grpc.CallOption) (*ShareLink, error)
+	CreateSubscriptionToken(ctx context.Context, in *CreateSubscriptionTokenRequest, opts ...grpc.CallOption) (*SubscriptionToken, error)
+	RevokeSubscriptionToken(ctx context.Context, in *RevokeSubscriptionTokenRequest, opts ...grpc.CallOption) (*RevokeSubscriptionTokenResponse, error)
+	ListSubscriptionTokens(ctx context.Context, in *ListSubscriptionTokensRequest, opts ...grpc.CallOption) (*ListSubscriptionTokensResponse, error)
+	ListSubscriptionAccesses(ctx context.Context, in *ListSubscriptionAccessesRequest, opts ...grpc.CallOption) (*ListSubscriptionAccessesResponse, error)
 }
 
 type dpiBypassServiceClient struct {
@@ -182,6 +202,16 @@ func (c *dpiBypassServiceClient) GetBypassHistory(ctx context.Context, in *GetBy
 	return out, nil
 }
 
+func (c *dpiBypassServiceClient) GetCensorshipSignals(ctx context.Context, in *GetCensorshipSignalsRequest, opts ...grpc.CallOption) (*GetCensorshipSignalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCensorshipSignalsResponse)
+	err := c.cc.Invoke(ctx, DpiBypassService_GetCensorshipSignals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dpiBypassServiceClient) AddBypassRule(ctx context.Context, in *AddBypassRuleRequest, opts ...grpc.CallOption) (*BypassRule, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BypassRule)
@@ -222,6 +252,86 @@ func (c *dpiBypassServiceClient) ListBypassRules(ctx context.Context, in *ListBy
 	return out, nil
 }
 
+func (c *dpiBypassServiceClient) AddBypassUser(ctx context.Context, in *AddBypassUserRequest, opts ...grpc.CallOption) (*BypassUser, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BypassUser)
+	err := c.cc.Invoke(ctx, DpiBypassService_AddBypassUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dpiBypassServiceClient) RevokeBypassUser(ctx context.Context, in *RevokeBypassUserRequest, opts ...grpc.CallOption) (*RevokeBypassUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeBypassUserResponse)
+	err := c.cc.Invoke(ctx, DpiBypassService_RevokeBypassUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dpiBypassServiceClient) ListBypassUsers(ctx context.Context, in *ListBypassUsersRequest, opts ...grpc.CallOption) (*ListBypassUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBypassUsersResponse)
+	err := c.cc.Invoke(ctx, DpiBypassService_ListBypassUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dpiBypassServiceClient) GetShareLink(ctx context.Context, in *GetShareLinkRequest, opts ...grpc.CallOption) (*ShareLink, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShareLink)
+	err := c.cc.Invoke(ctx, DpiBypassService_GetShareLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dpiBypassServiceClient) CreateSubscriptionToken(ctx context.Context, in *CreateSubscriptionTokenRequest, opts ...grpc.CallOption) (*SubscriptionToken, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubscriptionToken)
+	err := c.cc.Invoke(ctx, DpiBypassService_CreateSubscriptionToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dpiBypassServiceClient) RevokeSubscriptionToken(ctx context.Context, in *RevokeSubscriptionTokenRequest, opts ...grpc.CallOption) (*RevokeSubscriptionTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeSubscriptionTokenResponse)
+	err := c.cc.Invoke(ctx, DpiBypassService_RevokeSubscriptionToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dpiBypassServiceClient) ListSubscriptionTokens(ctx context.Context, in *ListSubscriptionTokensRequest, opts ...grpc.CallOption) (*ListSubscriptionTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSubscriptionTokensResponse)
+	err := c.cc.Invoke(ctx, DpiBypassService_ListSubscriptionTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dpiBypassServiceClient) ListSubscriptionAccesses(ctx context.Context, in *ListSubscriptionAccessesRequest, opts ...grpc.CallOption) (*ListSubscriptionAccessesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSubscriptionAccessesResponse)
+	err := c.cc.Invoke(ctx, DpiBypassService_ListSubscriptionAccesses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DpiBypassServiceServer is the server API for DpiBypassService service.
 // All implementations must embed UnimplementedDpiBypassServiceServer
 // for forward compatibility.
@@ -243,11 +353,22 @@ type DpiBypassServiceServer interface {
 	// Statistics and monitoring
 	GetBypassStats(context.Context, *GetBypassStatsRequest) (*BypassStats, error)
 	GetBypassHistory(context.Context, *GetBypassHistoryRequest) (*GetBypassHistoryResponse, error)
+	GetCensorshipSignals(context.Context, *GetCensorshipSignalsRequest) (*GetCensorshipSignalsResponse, error)
 	// Rule management
 	AddBypassRule(context.Context, *AddBypassRuleRequest) (*BypassRule, error)
 	UpdateBypassRule(context.Context, *UpdateBypassRuleRequest) (*BypassRule, error)
 	DeleteBypassRule(context.Context, *DeleteBypassRuleRequest) (*DeleteBypassRuleResponse, error)
 	ListBypassRules(context.Context, *ListBypassRulesRequest) (*ListBypassRulesResponse, error)
+	// User management
+	AddBypassUser(context.Context, *AddBypassUserRequest) (*BypassUser, error)
+	RevokeBypassUser(context.Context, *RevokeBypassUserRequest) (*RevokeBypassUserResponse, error)
+	ListBypassUsers(context.Context, *ListBypassUsersRequest) (*ListBypassUsersResponse, error)
+	// Share links and subscriptions
+	GetShareLink(context.Context, *GetShareLinkRequest) (*ShareLink, error)
+	CreateSubscriptionToken(context.Context, *CreateSubscriptionTokenRequest) (*SubscriptionToken, error)
+	RevokeSubscriptionToken(context.Context, *RevokeSubscriptionTokenRequest) (*RevokeSubscriptionTokenResponse, error)
+	ListSubscriptionTokens(context.Context, *ListSubscriptionTokensRequest) (*ListSubscriptionTokensResponse, error)
+	ListSubscriptionAccesses(context.Context, *ListSubscriptionAccessesRequest) (*ListSubscriptionAccessesResponse, error)
 	mustEmbedUnimplementedDpiBypassServiceServer()
 }
 
@@ -291,6 +412,9 @@ func (UnimplementedDpiBypassServiceServer) GetBypassStats(context.Context, *GetB
 func (UnimplementedDpiBypassServiceServer) GetBypassHistory(context.Context, *GetBypassHistoryRequest) (*GetBypassHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBypassHistory not implemented")
 }
+func (UnimplementedDpiBypassServiceServer) GetCensorshipSignals(context.Context, *GetCensorshipSignalsRequest) (*GetCensorshipSignalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCensorshipSignals not implemented")
+}
 func (UnimplementedDpiBypassServiceServer) AddBypassRule(context.Context, *AddBypassRuleRequest) (*BypassRule, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddBypassRule not implemented")
 }
@@ -303,6 +427,30 @@ func (UnimplementedDpiBypassServiceServer) DeleteBypassRule(context.Context, *De
 func (UnimplementedDpiBypassServiceServer) ListBypassRules(context.Context, *ListBypassRulesRequest) (*ListBypassRulesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListBypassRules not implemented")
 }
+func (UnimplementedDpiBypassServiceServer) AddBypassUser(context.Context, *AddBypassUserRequest) (*BypassUser, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBypassUser not implemented")
+}
+func (UnimplementedDpiBypassServiceServer) RevokeBypassUser(context.Context, *RevokeBypassUserRequest) (*RevokeBypassUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeBypassUser not implemented")
+}
+func (UnimplementedDpiBypassServiceServer) ListBypassUsers(context.Context, *ListBypassUsersRequest) (*ListBypassUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBypassUsers not implemented")
+}
+func (UnimplementedDpiBypassServiceServer) GetShareLink(context.Context, *GetShareLinkRequest) (*ShareLink, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShareLink not implemented")
+}
+func (UnimplementedDpiBypassServiceServer) CreateSubscriptionToken(context.Context, *CreateSubscriptionTokenRequest) (*SubscriptionToken, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSubscriptionToken not implemented")
+}
+func (UnimplementedDpiBypassServiceServer) RevokeSubscriptionToken(context.Context, *RevokeSubscriptionTokenRequest) (*RevokeSubscriptionTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSubscriptionToken not implemented")
+}
+func (UnimplementedDpiBypassServiceServer) ListSubscriptionTokens(context.Context, *ListSubscriptionTokensRequest) (*ListSubscriptionTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscriptionTokens not implemented")
+}
+func (UnimplementedDpiBypassServiceServer) ListSubscriptionAccesses(context.Context, *ListSubscriptionAccessesRequest) (*ListSubscriptionAccessesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscriptionAccesses not implemented")
+}
 func (UnimplementedDpiBypassServiceServer) mustEmbedUnimplementedDpiBypassServiceServer() {}
 func (UnimplementedDpiBypassServiceServer) testEmbeddedByValue()                          {}
 
@@ -522,6 +670,24 @@ func _DpiBypassService_GetBypassHistory_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DpiBypassService_GetCensorshipSignals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCensorshipSignalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).GetCensorshipSignals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_GetCensorshipSignals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).GetCensorshipSignals(ctx, req.(*GetCensorshipSignalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DpiBypassService_AddBypassRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AddBypassRuleRequest)
 	if err := dec(in); err != nil {
@@ -594,6 +760,150 @@ func _DpiBypassService_ListBypassRules_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DpiBypassService_AddBypassUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBypassUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).AddBypassUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_AddBypassUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).AddBypassUser(ctx, req.(*AddBypassUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DpiBypassService_RevokeBypassUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeBypassUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).RevokeBypassUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_RevokeBypassUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).RevokeBypassUser(ctx, req.(*RevokeBypassUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DpiBypassService_ListBypassUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBypassUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).ListBypassUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_ListBypassUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).ListBypassUsers(ctx, req.(*ListBypassUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DpiBypassService_GetShareLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShareLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).GetShareLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_GetShareLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).GetShareLink(ctx, req.(*GetShareLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DpiBypassService_CreateSubscriptionToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSubscriptionTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).CreateSubscriptionToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_CreateSubscriptionToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).CreateSubscriptionToken(ctx, req.(*CreateSubscriptionTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DpiBypassService_RevokeSubscriptionToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSubscriptionTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).RevokeSubscriptionToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_RevokeSubscriptionToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).RevokeSubscriptionToken(ctx, req.(*RevokeSubscriptionTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DpiBypassService_ListSubscriptionTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscriptionTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).ListSubscriptionTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_ListSubscriptionTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).ListSubscriptionTokens(ctx, req.(*ListSubscriptionTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DpiBypassService_ListSubscriptionAccesses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscriptionAccessesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DpiBypassServiceServer).ListSubscriptionAccesses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DpiBypassService_ListSubscriptionAccesses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DpiBypassServiceServer).ListSubscriptionAccesses(ctx, req.(*ListSubscriptionAccessesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DpiBypassService_ServiceDesc is the grpc.ServiceDesc for DpiBypassService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -645,6 +955,10 @@ var DpiBypassService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBypassHistory",
 			Handler:    _DpiBypassService_GetBypassHistory_Handler,
 		},
+		{
+			MethodName: "GetCensorshipSignals",
+			Handler:    _DpiBypassService_GetCensorshipSignals_Handler,
+		},
 		{
 			MethodName: "AddBypassRule",
 			Handler:    _DpiBypassService_AddBypassRule_Handler,
@@ -661,6 +975,38 @@ var DpiBypassService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListBypassRules",
 			Handler:    _DpiBypassService_ListBypassRules_Handler,
 		},
+		{
+			MethodName: "AddBypassUser",
+			Handler:    _DpiBypassService_AddBypassUser_Handler,
+		},
+		{
+			MethodName: "RevokeBypassUser",
+			Handler:    _DpiBypassService_RevokeBypassUser_Handler,
+		},
+		{
+			MethodName: "ListBypassUsers",
+			Handler:    _DpiBypassService_ListBypassUsers_Handler,
+		},
+		{
+			MethodName: "GetShareLink",
+			Handler:    _DpiBypassService_GetShareLink_Handler,
+		},
+		{
+			MethodName: "CreateSubscriptionToken",
+			Handler:    _DpiBypassService_CreateSubscriptionToken_Handler,
+		},
+		{
+			MethodName: "RevokeSubscriptionToken",
+			Handler:    _DpiBypassService_RevokeSubscriptionToken_Handler,
+		},
+		{
+			MethodName: "ListSubscriptionTokens",
+			Handler:    _DpiBypassService_ListSubscriptionTokens_Handler,
+		},
+		{
+			MethodName: "ListSubscriptionAccesses",
+			Handler:    _DpiBypassService_ListSubscriptionAccesses_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/dpi-bypass/dpi.proto",
@@ -19,25 +19,42 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ServerManagerService_Health_FullMethodName               = "/server.ServerManagerService/Health"
-	ServerManagerService_CreateServer_FullMethodName         = "/server.ServerManagerService/CreateServer"
-	ServerManagerService_GetServer_FullMethodName            = "/server.ServerManagerService/GetServer"
-	ServerManagerService_ListServers_FullMethodName          = "/server.ServerManagerService/ListServers"
-	ServerManagerService_UpdateServer_FullMethodName         = "/server.ServerManagerService/UpdateServer"
-	ServerManagerService_DeleteServer_FullMethodName         = "/server.ServerManagerService/DeleteServer"
-	ServerManagerService_StartServer_FullMethodName          = "/server.ServerManagerService/StartServer"
-	ServerManagerService_StopServer_FullMethodName           = "/server.ServerManagerService/StopServer"
-	ServerManagerService_RestartServer_FullMethodName        = "/server.ServerManagerService/RestartServer"
-	ServerManagerService_GetServerStats_FullMethodName       = "/server.ServerManagerService/GetServerStats"
-	ServerManagerService_GetServerHealth_FullMethodName      = "/server.ServerManagerService/GetServerHealth"
-	ServerManagerService_MonitorServer_FullMethodName        = "/server.ServerManagerService/MonitorServer"
-	ServerManagerService_GetServersByType_FullMethodName     = "/server.ServerManagerService/GetServersByType"
-	ServerManagerService_GetServersByRegion_FullMethodName   = "/server.ServerManagerService/GetServersByRegion"
-	ServerManagerService_GetServersByStatus_FullMethodName   = "/server.ServerManagerService/GetServersByStatus"
-	ServerManagerService_ScaleServer_FullMethodName          = "/server.ServerManagerService/ScaleServer"
-	ServerManagerService_CreateBackup_FullMethodName         = "/server.ServerManagerService/CreateBackup"
-	ServerManagerService_RestoreBackup_FullMethodName        = "/server.ServerManagerService/RestoreBackup"
-	ServerManagerService_UpdateServerSoftware_FullMethodName = "/server.ServerManagerService/UpdateServerSoftware"
+	ServerManagerService_Health_FullMethodName                     = "/server.ServerManagerService/Health"
+	ServerManagerService_CreateServer_FullMethodName               = "/server.ServerManagerService/CreateServer"
+	ServerManagerService_GetServer_FullMethodName                  = "/server.ServerManagerService/GetServer"
+	ServerManagerService_ListServers_FullMethodName                = "/server.ServerManagerService/ListServers"
+	ServerManagerService_UpdateServer_FullMethodName               = "/server.ServerManagerService/UpdateServer"
+	ServerManagerService_DeleteServer_FullMethodName               = "/server.ServerManagerService/DeleteServer"
+	ServerManagerService_StartServer_FullMethodName                = "/server.ServerManagerService/StartServer"
+	ServerManagerService_StopServer_FullMethodName                 = "/server.ServerManagerService/StopServer"
+	ServerManagerService_RestartServer_FullMethodName              = "/server.ServerManagerService/RestartServer"
+	ServerManagerService_GetServerStats_FullMethodName             = "/server.ServerManagerService/GetServerStats"
+	ServerManagerService_GetServerHealth_FullMethodName            = "/server.ServerManagerService/GetServerHealth"
+	ServerManagerService_MonitorServer_FullMethodName              = "/server.ServerManagerService/MonitorServer"
+	ServerManagerService_GetServersByType_FullMethodName           = "/server.ServerManagerService/GetServersByType"
+	ServerManagerService_GetServersByRegion_FullMethodName         = "/server.ServerManagerService/GetServersByRegion"
+	ServerManagerService_GetServersByStatus_FullMethodName         = "/server.ServerManagerService/GetServersByStatus"
+	ServerManagerService_ScaleServer_FullMethodName                = "/server.ServerManagerService/ScaleServer"
+	ServerManagerService_EvaluateScaling_FullMethodName            = "/server.ServerManagerService/EvaluateScaling"
+	ServerManagerService_CreateBackup_FullMethodName               = "/server.ServerManagerService/CreateBackup"
+	ServerManagerService_RestoreBackup_FullMethodName              = "/server.ServerManagerService/RestoreBackup"
+	ServerManagerService_UpdateServerSoftware_FullMethodName       = "/server.ServerManagerService/UpdateServerSoftware"
+	ServerManagerService_GetServerSoftwareUpdate_FullMethodName    = "/server.ServerManagerService/GetServerSoftwareUpdate"
+	ServerManagerService_CancelServerSoftwareUpdate_FullMethodName = "/server.ServerManagerService/CancelServerSoftwareUpdate"
+	ServerManagerService_ListRegions_FullMethodName                = "/server.ServerManagerService/ListRegions"
+	ServerManagerService_SaveRegion_FullMethodName                 = "/server.ServerManagerService/SaveRegion"
+	ServerManagerService_DeleteRegion_FullMethodName               = "/server.ServerManagerService/DeleteRegion"
+	ServerManagerService_RecommendServer_FullMethodName            = "/server.ServerManagerService/RecommendServer"
+	ServerManagerService_RegisterNode_FullMethodName               = "/server.ServerManagerService/RegisterNode"
+	ServerManagerService_ListNodes_FullMethodName                  = "/server.ServerManagerService/ListNodes"
+	ServerManagerService_RemoveNode_FullMethodName                 = "/server.ServerManagerService/RemoveNode"
+	ServerManagerService_CordonNode_FullMethodName                 = "/server.ServerManagerService/CordonNode"
+	ServerManagerService_UncordonNode_FullMethodName               = "/server.ServerManagerService/UncordonNode"
+	ServerManagerService_DrainNode_FullMethodName                  = "/server.ServerManagerService/DrainNode"
+	ServerManagerService_GetDriftReport_FullMethodName             = "/server.ServerManagerService/GetDriftReport"
+	ServerManagerService_ReconcileServers_FullMethodName           = "/server.ServerManagerService/ReconcileServers"
+	ServerManagerService_ProvisionServer_FullMethodName            = "/server.ServerManagerService/ProvisionServer"
+	ServerManagerService_GetServerProvision_FullMethodName         = "/server.ServerManagerService/GetServerProvision"
 )
 
 // ServerManagerServiceClient is the client API for ServerManagerService service.
@@ -68,9 +85,30 @@ type ServerManagerServiceClient interface {
 	GetServersByStatus(ctx context.Context, in *GetServersByStatusRequest, opts ...grpc.CallOption) (*GetServersByStatusResponse, error)
 	// Scaling and management
 	ScaleServer(ctx context.Context, in *ScaleServerRequest, opts ...grpc.CallOption) (*ScaleServerResponse, error)
+	EvaluateScaling(ctx context.Context, in *EvaluateScalingRequest, opts ...grpc.CallOption) (*EvaluateScalingResponse, error)
 	CreateBackup(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*CreateBackupResponse, error)
 	RestoreBackup(ctx context.Context, in *RestoreBackupRequest, opts ...grpc.CallOption) (*RestoreBackupResponse, error)
 	UpdateServerSoftware(ctx context.Context, in *UpdateServerSoftwareRequest, opts ...grpc.CallOption) (*UpdateServerSoftwareResponse, error)
+	GetServerSoftwareUpdate(ctx context.Context, in *GetServerSoftwareUpdateRequest, opts ...grpc.CallOption) (*UpdateStatus, error)
+	CancelServerSoftwareUpdate(ctx context.Context, in *CancelServerSoftwareUpdateRequest, opts ...grpc.CallOption) (*CancelServerSoftwareUpdateResponse, error)
+	// Regions and placement
+	ListRegions(ctx context.Context, in *ListRegionsRequest, opts ...grpc.CallOption) (*ListRegionsResponse, error)
+	SaveRegion(ctx context.Context, in *Region, opts ...grpc.CallOption) (*Region, error)
+	DeleteRegion(ctx context.Context, in *DeleteRegionRequest, opts ...grpc.CallOption) (*DeleteRegionResponse, error)
+	RecommendServer(ctx context.Context, in *RecommendServerRequest, opts ...grpc.CallOption) (*RecommendServerResponse, error)
+	// Nodes
+	RegisterNode(ctx context.Context, in *Node, opts ...grpc.CallOption) (*Node, error)
+	ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error)
+	RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*RemoveNodeResponse, error)
+	CordonNode(ctx context.Context, in *CordonNodeRequest, opts ...grpc.CallOption) (*Node, error)
+	UncordonNode(ctx context.Context, in *UncordonNodeRequest, opts ...grpc.CallOption) (*Node, error)
+	DrainNode(ctx context.Context, in *DrainNodeRequest, opts ...grpc.CallOption) (*Node, error)
+	// Reconciliation
+	GetDriftReport(ctx context.Context, in *GetDriftReportRequest, opts ...grpc.CallOption) (*DriftReport, error)
+	ReconcileServers(ctx context.Context, in *ReconcileServersRequest, opts ...grpc.CallOption) (*DriftReport, error)
+	// Provisioning
+	ProvisionServer(ctx context.Context, in *ProvisionServerRequest, opts ...grpc.CallOption) (*Server, error)
+	GetServerProvision(ctx context.Context, in *GetServerProvisionRequest, opts ...grpc.CallOption) (*Provision, error)
 }
 
 type serverManagerServiceClient struct {
@@ -250,6 +288,16 @@ func (c *serverManagerServiceClient) ScaleServer(ctx context.Context, in *ScaleS
 	return out, nil
 }
 
+func (c *serverManagerServiceClient) EvaluateScaling(ctx context.Context, in *EvaluateScalingRequest, opts ...grpc.CallOption) (*EvaluateScalingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EvaluateScalingResponse)
+	err := c.cc.Invoke(ctx, ServerManagerService_EvaluateScaling_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serverManagerServiceClient) CreateBackup(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*CreateBackupResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateBackupResponse)
@@ -280,6 +328,166 @@ func (c *serverManagerServiceClient) UpdateServerSoftware(ctx context.Context, i
 	return out, nil
 }
 
+func (c *serverManagerServiceClient) GetServerSoftwareUpdate(ctx context.Context, in *GetServerSoftwareUpdateRequest, opts ...grpc.CallOption) (*UpdateStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateStatus)
+	err := c.cc.Invoke(ctx, ServerManagerService_GetServerSoftwareUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) CancelServerSoftwareUpdate(ctx context.Context, in *CancelServerSoftwareUpdateRequest, opts ...grpc.CallOption) (*CancelServerSoftwareUpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelServerSoftwareUpdateResponse)
+	err := c.cc.Invoke(ctx, ServerManagerService_CancelServerSoftwareUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) ListRegions(ctx context.Context, in *ListRegionsRequest, opts ...grpc.CallOption) (*ListRegionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRegionsResponse)
+	err := c.cc.Invoke(ctx, ServerManagerService_ListRegions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) SaveRegion(ctx context.Context, in *Region, opts ...grpc.CallOption) (*Region, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Region)
+	err := c.cc.Invoke(ctx, ServerManagerService_SaveRegion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) DeleteRegion(ctx context.Context, in *DeleteRegionRequest, opts ...grpc.CallOption) (*DeleteRegionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRegionResponse)
+	err := c.cc.Invoke(ctx, ServerManagerService_DeleteRegion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) RecommendServer(ctx context.Context, in *RecommendServerRequest, opts ...grpc.CallOption) (*RecommendServerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecommendServerResponse)
+	err := c.cc.Invoke(ctx, ServerManagerService_RecommendServer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) RegisterNode(ctx context.Context, in *Node, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, ServerManagerService_RegisterNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNodesResponse)
+	err := c.cc.Invoke(ctx, ServerManagerService_ListNodes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*RemoveNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveNodeResponse)
+	err := c.cc.Invoke(ctx, ServerManagerService_RemoveNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) CordonNode(ctx context.Context, in *CordonNodeRequest, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, ServerManagerService_CordonNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) UncordonNode(ctx context.Context, in *UncordonNodeRequest, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, ServerManagerService_UncordonNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) DrainNode(ctx context.Context, in *DrainNodeRequest, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, ServerManagerService_DrainNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) GetDriftReport(ctx context.Context, in *GetDriftReportRequest, opts ...grpc.CallOption) (*DriftReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DriftReport)
+	err := c.cc.Invoke(ctx, ServerManagerService_GetDriftReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) ReconcileServers(ctx context.Context, in *ReconcileServersRequest, opts ...grpc.CallOption) (*DriftReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DriftReport)
+	err := c.cc.Invoke(ctx, ServerManagerService_ReconcileServers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) ProvisionServer(ctx context.Context, in *ProvisionServerRequest, opts ...grpc.CallOption) (*Server, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Server)
+	err := c.cc.Invoke(ctx, ServerManagerService_ProvisionServer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverManagerServiceClient) GetServerProvision(ctx context.Context, in *GetServerProvisionRequest, opts ...grpc.CallOption) (*Provision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Provision)
+	err := c.cc.Invoke(ctx, ServerManagerService_GetServerProvision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServerManagerServiceServer is the server API for ServerManagerService service.
 // All implementations must embed UnimplementedServerManagerServiceServer
 // for forward compatibility.
@@ -308,9 +516,30 @@ type ServerManagerServiceServer interface {
 	GetServersByStatus(context.Context, *GetServersByStatusRequest) (*GetServersByStatusResponse, error)
 	// Scaling and management
 	ScaleServer(context.Context, *ScaleServerRequest) (*ScaleServerResponse, error)
+	EvaluateScaling(context.Context, *EvaluateScalingRequest) (*EvaluateScalingResponse, error)
 	CreateBackup(context.Context, *CreateBackupRequest) (*CreateBackupResponse, error)
 	RestoreBackup(context.Context, *RestoreBackupRequest) (*RestoreBackupResponse, error)
 	UpdateServerSoftware(context.Context, *UpdateServerSoftwareRequest) (*UpdateServerSoftwareResponse, error)
+	GetServerSoftwareUpdate(context.Context, *GetServerSoftwareUpdateRequest) (*UpdateStatus, error)
+	CancelServerSoftwareUpdate(context.Context, *CancelServerSoftwareUpdateRequest) (*CancelServerSoftwareUpdateResponse, error)
+	// Regions and placement
+	ListRegions(context.Context, *ListRegionsRequest) (*ListRegionsResponse, error)
+	SaveRegion(context.Context, *Region) (*Region, error)
+	DeleteRegion(context.Context, *DeleteRegionRequest) (*DeleteRegionResponse, error)
+	RecommendServer(context.Context, *RecommendServerRequest) (*RecommendServerResponse, error)
+	// Nodes
+	RegisterNode(context.Context, *Node) (*Node, error)
+	ListNodes(context.Context, *ListNodesRequest) (*ListNodesResponse, error)
+	RemoveNode(context.Context, *RemoveNodeRequest) (*RemoveNodeResponse, error)
+	CordonNode(context.Context, *CordonNodeRequest) (*Node, error)
+	UncordonNode(context.Context, *UncordonNodeRequest) (*Node, error)
+	DrainNode(context.Context, *DrainNodeRequest) (*Node, error)
+	// Reconciliation
+	GetDriftReport(context.Context, *GetDriftReportRequest) (*DriftReport, error)
+	ReconcileServers(context.Context, *ReconcileServersRequest) (*DriftReport, error)
+	// Provisioning
+	ProvisionServer(context.Context, *ProvisionServerRequest) (*Server, error)
+	GetServerProvision(context.Context, *GetServerProvisionRequest) (*Provision, error)
 	mustEmbedUnimplementedServerManagerServiceServer()
 }
 
@@ -369,6 +598,9 @@ func (UnimplementedServerManagerServiceServer) GetServersByStatus(context.Contex
 func (UnimplementedServerManagerServiceServer) ScaleServer(context.Context, *ScaleServerRequest) (*ScaleServerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ScaleServer not implemented")
 }
+func (UnimplementedServerManagerServiceServer) EvaluateScaling(context.Context, *EvaluateScalingRequest) (*EvaluateScalingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluateScaling not implemented")
+}
 func (UnimplementedServerManagerServiceServer) CreateBackup(context.Context, *CreateBackupRequest) (*CreateBackupResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateBackup not implemented")
 }
@@ -378,6 +610,54 @@ func (UnimplementedServerManagerServiceServer) RestoreBackup(context.Context, *R
 func (UnimplementedServerManagerServiceServer) UpdateServerSoftware(context.Context, *UpdateServerSoftwareRequest) (*UpdateServerSoftwareResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateServerSoftware not implemented")
 }
+func (UnimplementedServerManagerServiceServer) GetServerSoftwareUpdate(context.Context, *GetServerSoftwareUpdateRequest) (*UpdateStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerSoftwareUpdate not implemented")
+}
+func (UnimplementedServerManagerServiceServer) CancelServerSoftwareUpdate(context.Context, *CancelServerSoftwareUpdateRequest) (*CancelServerSoftwareUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelServerSoftwareUpdate not implemented")
+}
+func (UnimplementedServerManagerServiceServer) ListRegions(context.Context, *ListRegionsRequest) (*ListRegionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRegions not implemented")
+}
+func (UnimplementedServerManagerServiceServer) SaveRegion(context.Context, *Region) (*Region, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveRegion not implemented")
+}
+func (UnimplementedServerManagerServiceServer) DeleteRegion(context.Context, *DeleteRegionRequest) (*DeleteRegionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRegion not implemented")
+}
+func (UnimplementedServerManagerServiceServer) RecommendServer(context.Context, *RecommendServerRequest) (*RecommendServerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecommendServer not implemented")
+}
+func (UnimplementedServerManagerServiceServer) RegisterNode(context.Context, *Node) (*Node, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterNode not implemented")
+}
+func (UnimplementedServerManagerServiceServer) ListNodes(context.Context, *ListNodesRequest) (*ListNodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNodes not implemented")
+}
+func (UnimplementedServerManagerServiceServer) RemoveNode(context.Context, *RemoveNodeRequest) (*RemoveNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveNode not implemented")
+}
+func (UnimplementedServerManagerServiceServer) CordonNode(context.Context, *CordonNodeRequest) (*Node, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CordonNode not implemented")
+}
+func (UnimplementedServerManagerServiceServer) UncordonNode(context.Context, *UncordonNodeRequest) (*Node, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UncordonNode not implemented")
+}
+func (UnimplementedServerManagerServiceServer) DrainNode(context.Context, *DrainNodeRequest) (*Node, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrainNode not implemented")
+}
+func (UnimplementedServerManagerServiceServer) GetDriftReport(context.Context, *GetDriftReportRequest) (*DriftReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDriftReport not implemented")
+}
+func (UnimplementedServerManagerServiceServer) ReconcileServers(context.Context, *ReconcileServersRequest) (*DriftReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileServers not implemented")
+}
+func (UnimplementedServerManagerServiceServer) ProvisionServer(context.Context, *ProvisionServerRequest) (*Server, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProvisionServer not implemented")
+}
+func (UnimplementedServerManagerServiceServer) GetServerProvision(context.Context, *GetServerProvisionRequest) (*Provision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerProvision not implemented")
+}
 func (UnimplementedServerManagerServiceServer) mustEmbedUnimplementedServerManagerServiceServer() {}
 func (UnimplementedServerManagerServiceServer) testEmbeddedByValue()                              {}
 
@@ -680,6 +960,24 @@ func _ServerManagerService_ScaleServer_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServerManagerService_EvaluateScaling_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateScalingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).EvaluateScaling(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_EvaluateScaling_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).EvaluateScaling(ctx, req.(*EvaluateScalingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ServerManagerService_CreateBackup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateBackupRequest)
 	if err := dec(in); err != nil {
@@ -734,6 +1032,294 @@ func _ServerManagerService_UpdateServerSoftware_Handler(srv interface{}, ctx con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServerManagerService_GetServerSoftwareUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerSoftwareUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).GetServerSoftwareUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_GetServerSoftwareUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).GetServerSoftwareUpdate(ctx, req.(*GetServerSoftwareUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_CancelServerSoftwareUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelServerSoftwareUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).CancelServerSoftwareUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_CancelServerSoftwareUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).CancelServerSoftwareUpdate(ctx, req.(*CancelServerSoftwareUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_ListRegions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRegionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).ListRegions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_ListRegions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).ListRegions(ctx, req.(*ListRegionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_SaveRegion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Region)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).SaveRegion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_SaveRegion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).SaveRegion(ctx, req.(*Region))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_DeleteRegion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRegionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).DeleteRegion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_DeleteRegion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).DeleteRegion(ctx, req.(*DeleteRegionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_RecommendServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecommendServerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).RecommendServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_RecommendServer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).RecommendServer(ctx, req.(*RecommendServerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_RegisterNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Node)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).RegisterNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_RegisterNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).RegisterNode(ctx, req.(*Node))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_ListNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).ListNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_ListNodes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).ListNodes(ctx, req.(*ListNodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_RemoveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).RemoveNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_RemoveNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).RemoveNode(ctx, req.(*RemoveNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_CordonNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CordonNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).CordonNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_CordonNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).CordonNode(ctx, req.(*CordonNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_UncordonNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UncordonNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).UncordonNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_UncordonNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).UncordonNode(ctx, req.(*UncordonNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_DrainNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).DrainNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_DrainNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).DrainNode(ctx, req.(*DrainNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_GetDriftReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDriftReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).GetDriftReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_GetDriftReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).GetDriftReport(ctx, req.(*GetDriftReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_ReconcileServers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileServersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).ReconcileServers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_ReconcileServers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).ReconcileServers(ctx, req.(*ReconcileServersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_ProvisionServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProvisionServerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).ProvisionServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_ProvisionServer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).ProvisionServer(ctx, req.(*ProvisionServerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServerManagerService_GetServerProvision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerProvisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerManagerServiceServer).GetServerProvision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerManagerService_GetServerProvision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerManagerServiceServer).GetServerProvision(ctx, req.(*GetServerProvisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ServerManagerService_ServiceDesc is the grpc.ServiceDesc for ServerManagerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -801,6 +1387,10 @@ var ServerManagerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ScaleServer",
 			Handler:    _ServerManagerService_ScaleServer_Handler,
 		},
+		{
+			MethodName: "EvaluateScaling",
+			Handler:    _ServerManagerService_EvaluateScaling_Handler,
+		},
 		{
 			MethodName: "CreateBackup",
 			Handler:    _ServerManagerService_CreateBackup_Handler,
@@ -813,6 +1403,70 @@ var ServerManagerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateServerSoftware",
 			Handler:    _ServerManagerService_UpdateServerSoftware_Handler,
 		},
+		{
+			MethodName: "GetServerSoftwareUpdate",
+			Handler:    _ServerManagerService_GetServerSoftwareUpdate_Handler,
+		},
+		{
+			MethodName: "CancelServerSoftwareUpdate",
+			Handler:    _ServerManagerService_CancelServerSoftwareUpdate_Handler,
+		},
+		{
+			MethodName: "ListRegions",
+			Handler:    _ServerManagerService_ListRegions_Handler,
+		},
+		{
+			MethodName: "SaveRegion",
+			Handler:    _ServerManagerService_SaveRegion_Handler,
+		},
+		{
+			MethodName: "DeleteRegion",
+			Handler:    _ServerManagerService_DeleteRegion_Handler,
+		},
+		{
+			MethodName: "RecommendServer",
+			Handler:    _ServerManagerService_RecommendServer_Handler,
+		},
+		{
+			MethodName: "RegisterNode",
+			Handler:    _ServerManagerService_RegisterNode_Handler,
+		},
+		{
+			MethodName: "ListNodes",
+			Handler:    _ServerManagerService_ListNodes_Handler,
+		},
+		{
+			MethodName: "RemoveNode",
+			Handler:    _ServerManagerService_RemoveNode_Handler,
+		},
+		{
+			MethodName: "CordonNode",
+			Handler:    _ServerManagerService_CordonNode_Handler,
+		},
+		{
+			MethodName: "UncordonNode",
+			Handler:    _ServerManagerService_UncordonNode_Handler,
+		},
+		{
+			MethodName: "DrainNode",
+			Handler:    _ServerManagerService_DrainNode_Handler,
+		},
+		{
+			MethodName: "GetDriftReport",
+			Handler:    _ServerManagerService_GetDriftReport_Handler,
+		},
+		{
+			MethodName: "ReconcileServers",
+			Handler:    _ServerManagerService_ReconcileServers_Handler,
+		},
+		{
+			MethodName: "ProvisionServer",
+			Handler:    _ServerManagerService_ProvisionServer_Handler,
+		},
+		{
+			MethodName: "GetServerProvision",
+			Handler:    _ServerManagerService_GetServerProvision_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -17,6 +17,9 @@ WHERE NOT EXISTS (SELECT FROM pg_database WHERE datname = 'silence_vpn')\gexec
 SELECT 'CREATE DATABASE silence_analytics OWNER postgres'
 WHERE NOT EXISTS (SELECT FROM pg_database WHERE datname = 'silence_analytics')\gexec
 
+SELECT 'CREATE DATABASE silence_dpi_bypass OWNER postgres'
+WHERE NOT EXISTS (SELECT FROM pg_database WHERE datname = 'silence_dpi_bypass')\gexec
+
 -- Create roles for different services (optional, for better security)
 DO
 $do$
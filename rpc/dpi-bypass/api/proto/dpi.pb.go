@@ -22,6 +22,58 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ReloadOutcome int32
+
+const (
+	ReloadOutcome_RELOAD_OUTCOME_UNSPECIFIED ReloadOutcome = 0
+	ReloadOutcome_RELOAD_OUTCOME_APPLIED     ReloadOutcome = 1
+	ReloadOutcome_RELOAD_OUTCOME_RESTARTED   ReloadOutcome = 2
+	ReloadOutcome_RELOAD_OUTCOME_FAILED      ReloadOutcome = 3
+)
+
+// Enum value maps for ReloadOutcome.
+var (
+	ReloadOutcome_name = map[int32]string{
+		0: "RELOAD_OUTCOME_UNSPECIFIED",
+		1: "RELOAD_OUTCOME_APPLIED",
+		2: "RELOAD_OUTCOME_RESTARTED",
+		3: "RELOAD_OUTCOME_FAILED",
+	}
+	ReloadOutcome_value = map[string]int32{
+		"RELOAD_OUTCOME_UNSPECIFIED": 0,
+		"RELOAD_OUTCOME_APPLIED":     1,
+		"RELOAD_OUTCOME_RESTARTED":   2,
+		"RELOAD_OUTCOME_FAILED":      3,
+	}
+)
+
+func (x ReloadOutcome) Enum() *ReloadOutcome {
+	p := new(ReloadOutcome)
+	*p = x
+	return p
+}
+
+func (x ReloadOutcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReloadOutcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_dpi_proto_enumTypes[0].Descriptor()
+}
+
+func (ReloadOutcome) Type() protoreflect.EnumType {
+	return &file_dpi_proto_enumTypes[0]
+}
+
+func (x ReloadOutcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReloadOutcome.Descriptor instead.
+func (ReloadOutcome) EnumDescriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{0}
+}
+
 type BypassType int32
 
 const (
@@ -64,11 +116,11 @@ func (x BypassType) String() string {
 }
 
 func (BypassType) Descriptor() protoreflect.EnumDescriptor {
-	return file_dpi_proto_enumTypes[0].Descriptor()
+	return file_dpi_proto_enumTypes[1].Descriptor()
 }
 
 func (BypassType) Type() protoreflect.EnumType {
-	return &file_dpi_proto_enumTypes[0]
+	return &file_dpi_proto_enumTypes[1]
 }
 
 func (x BypassType) Number() protoreflect.EnumNumber {
@@ -77,7 +129,7 @@ func (x BypassType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use BypassType.Descriptor instead.
 func (BypassType) EnumDescriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{0}
+	return file_dpi_proto_rawDescGZIP(), []int{1}
 }
 
 type BypassMethod int32
@@ -89,6 +141,7 @@ const (
 	BypassMethod_BYPASS_METHOD_TCP_FRAGMENT  BypassMethod = 3
 	BypassMethod_BYPASS_METHOD_UDP_FRAGMENT  BypassMethod = 4
 	BypassMethod_BYPASS_METHOD_PROXY_CHAIN   BypassMethod = 5
+	BypassMethod_BYPASS_METHOD_AUTO          BypassMethod = 6
 )
 
 // Enum value maps for BypassMethod.
@@ -100,6 +153,7 @@ var (
 		3: "BYPASS_METHOD_TCP_FRAGMENT",
 		4: "BYPASS_METHOD_UDP_FRAGMENT",
 		5: "BYPASS_METHOD_PROXY_CHAIN",
+		6: "BYPASS_METHOD_AUTO",
 	}
 	BypassMethod_value = map[string]int32{
 		"BYPASS_METHOD_UNSPECIFIED":   0,
@@ -108,6 +162,7 @@ var (
 		"BYPASS_METHOD_TCP_FRAGMENT":  3,
 		"BYPASS_METHOD_UDP_FRAGMENT":  4,
 		"BYPASS_METHOD_PROXY_CHAIN":   5,
+		"BYPASS_METHOD_AUTO":          6,
 	}
 )
 
@@ -122,11 +177,11 @@ func (x BypassMethod) String() string {
 }
 
 func (BypassMethod) Descriptor() protoreflect.EnumDescriptor {
-	return file_dpi_proto_enumTypes[1].Descriptor()
+	return file_dpi_proto_enumTypes[2].Descriptor()
 }
 
 func (BypassMethod) Type() protoreflect.EnumType {
-	return &file_dpi_proto_enumTypes[1]
+	return &file_dpi_proto_enumTypes[2]
 }
 
 func (x BypassMethod) Number() protoreflect.EnumNumber {
@@ -135,7 +190,7 @@ func (x BypassMethod) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use BypassMethod.Descriptor instead.
 func (BypassMethod) EnumDescriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{1}
+	return file_dpi_proto_rawDescGZIP(), []int{2}
 }
 
 type BypassStatus int32
@@ -177,11 +232,11 @@ func (x BypassStatus) String() string {
 }
 
 func (BypassStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_dpi_proto_enumTypes[2].Descriptor()
+	return file_dpi_proto_enumTypes[3].Descriptor()
 }
 
 func (BypassStatus) Type() protoreflect.EnumType {
-	return &file_dpi_proto_enumTypes[2]
+	return &file_dpi_proto_enumTypes[3]
 }
 
 func (x BypassStatus) Number() protoreflect.EnumNumber {
@@ -190,7 +245,7 @@ func (x BypassStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use BypassStatus.Descriptor instead.
 func (BypassStatus) EnumDescriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{2}
+	return file_dpi_proto_rawDescGZIP(), []int{3}
 }
 
 type RuleType int32
@@ -235,11 +290,11 @@ func (x RuleType) String() string {
 }
 
 func (RuleType) Descriptor() protoreflect.EnumDescriptor {
-	return file_dpi_proto_enumTypes[3].Descriptor()
+	return file_dpi_proto_enumTypes[4].Descriptor()
 }
 
 func (RuleType) Type() protoreflect.EnumType {
-	return &file_dpi_proto_enumTypes[3]
+	return &file_dpi_proto_enumTypes[4]
 }
 
 func (x RuleType) Number() protoreflect.EnumNumber {
@@ -248,7 +303,7 @@ func (x RuleType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RuleType.Descriptor instead.
 func (RuleType) EnumDescriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{3}
+	return file_dpi_proto_rawDescGZIP(), []int{4}
 }
 
 type RuleAction int32
@@ -293,11 +348,11 @@ func (x RuleAction) String() string {
 }
 
 func (RuleAction) Descriptor() protoreflect.EnumDescriptor {
-	return file_dpi_proto_enumTypes[4].Descriptor()
+	return file_dpi_proto_enumTypes[5].Descriptor()
 }
 
 func (RuleAction) Type() protoreflect.EnumType {
-	return &file_dpi_proto_enumTypes[4]
+	return &file_dpi_proto_enumTypes[5]
 }
 
 func (x RuleAction) Number() protoreflect.EnumNumber {
@@ -306,7 +361,7 @@ func (x RuleAction) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RuleAction.Descriptor instead.
 func (RuleAction) EnumDescriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{4}
+	return file_dpi_proto_rawDescGZIP(), []int{5}
 }
 
 // Health
@@ -419,6 +474,7 @@ type BypassConfig struct {
 	Rules         []*BypassRule          `protobuf:"bytes,8,rep,name=rules,proto3" json:"rules,omitempty"`
 	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Reloads       []*SessionReload       `protobuf:"bytes,11,rep,name=reloads,proto3" json:"reloads,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -523,6 +579,74 @@ func (x *BypassConfig) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *BypassConfig) GetReloads() []*SessionReload {
+	if x != nil {
+		return x.Reloads
+	}
+	return nil
+}
+
+// Per-session result of applying an updated configuration
+type SessionReload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Outcome       ReloadOutcome          `protobuf:"varint,2,opt,name=outcome,proto3,enum=dpi.ReloadOutcome" json:"outcome,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionReload) Reset() {
+	*x = SessionReload{}
+	mi := &file_dpi_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionReload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionReload) ProtoMessage() {}
+
+func (x *SessionReload) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionReload.ProtoReflect.Descriptor instead.
+func (*SessionReload) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SessionReload) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionReload) GetOutcome() ReloadOutcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return ReloadOutcome_RELOAD_OUTCOME_UNSPECIFIED
+}
+
+func (x *SessionReload) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 type CreateBypassConfigRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -536,7 +660,7 @@ type CreateBypassConfigRequest struct {
 
 func (x *CreateBypassConfigRequest) Reset() {
 	*x = CreateBypassConfigRequest{}
-	mi := &file_dpi_proto_msgTypes[3]
+	mi := &file_dpi_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -548,7 +672,7 @@ func (x *CreateBypassConfigRequest) String() string {
 func (*CreateBypassConfigRequest) ProtoMessage() {}
 
 func (x *CreateBypassConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[3]
+	mi := &file_dpi_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -561,7 +685,7 @@ func (x *CreateBypassConfigRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateBypassConfigRequest.ProtoReflect.Descriptor instead.
 func (*CreateBypassConfigRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{3}
+	return file_dpi_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CreateBypassConfigRequest) GetName() string {
@@ -608,7 +732,7 @@ type GetBypassConfigRequest struct {
 
 func (x *GetBypassConfigRequest) Reset() {
 	*x = GetBypassConfigRequest{}
-	mi := &file_dpi_proto_msgTypes[4]
+	mi := &file_dpi_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -620,7 +744,7 @@ func (x *GetBypassConfigRequest) String() string {
 func (*GetBypassConfigRequest) ProtoMessage() {}
 
 func (x *GetBypassConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[4]
+	mi := &file_dpi_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -633,7 +757,7 @@ func (x *GetBypassConfigRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBypassConfigRequest.ProtoReflect.Descriptor instead.
 func (*GetBypassConfigRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{4}
+	return file_dpi_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetBypassConfigRequest) GetId() string {
@@ -655,7 +779,7 @@ type ListBypassConfigsRequest struct {
 
 func (x *ListBypassConfigsRequest) Reset() {
 	*x = ListBypassConfigsRequest{}
-	mi := &file_dpi_proto_msgTypes[5]
+	mi := &file_dpi_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -667,7 +791,7 @@ func (x *ListBypassConfigsRequest) String() string {
 func (*ListBypassConfigsRequest) ProtoMessage() {}
 
 func (x *ListBypassConfigsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[5]
+	mi := &file_dpi_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -680,7 +804,7 @@ func (x *ListBypassConfigsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListBypassConfigsRequest.ProtoReflect.Descriptor instead.
 func (*ListBypassConfigsRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{5}
+	return file_dpi_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ListBypassConfigsRequest) GetType() BypassType {
@@ -721,7 +845,7 @@ type ListBypassConfigsResponse struct {
 
 func (x *ListBypassConfigsResponse) Reset() {
 	*x = ListBypassConfigsResponse{}
-	mi := &file_dpi_proto_msgTypes[6]
+	mi := &file_dpi_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -733,7 +857,7 @@ func (x *ListBypassConfigsResponse) String() string {
 func (*ListBypassConfigsResponse) ProtoMessage() {}
 
 func (x *ListBypassConfigsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[6]
+	mi := &file_dpi_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -746,7 +870,7 @@ func (x *ListBypassConfigsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListBypassConfigsResponse.ProtoReflect.Descriptor instead.
 func (*ListBypassConfigsResponse) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{6}
+	return file_dpi_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ListBypassConfigsResponse) GetConfigs() []*BypassConfig {
@@ -777,7 +901,7 @@ type UpdateBypassConfigRequest struct {
 
 func (x *UpdateBypassConfigRequest) Reset() {
 	*x = UpdateBypassConfigRequest{}
-	mi := &file_dpi_proto_msgTypes[7]
+	mi := &file_dpi_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -789,7 +913,7 @@ func (x *UpdateBypassConfigRequest) String() string {
 func (*UpdateBypassConfigRequest) ProtoMessage() {}
 
 func (x *UpdateBypassConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[7]
+	mi := &file_dpi_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -802,7 +926,7 @@ func (x *UpdateBypassConfigRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateBypassConfigRequest.ProtoReflect.Descriptor instead.
 func (*UpdateBypassConfigRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{7}
+	return file_dpi_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *UpdateBypassConfigRequest) GetId() string {
@@ -856,7 +980,7 @@ type DeleteBypassConfigRequest struct {
 
 func (x *DeleteBypassConfigRequest) Reset() {
 	*x = DeleteBypassConfigRequest{}
-	mi := &file_dpi_proto_msgTypes[8]
+	mi := &file_dpi_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -868,7 +992,7 @@ func (x *DeleteBypassConfigRequest) String() string {
 func (*DeleteBypassConfigRequest) ProtoMessage() {}
 
 func (x *DeleteBypassConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[8]
+	mi := &file_dpi_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -881,7 +1005,7 @@ func (x *DeleteBypassConfigRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteBypassConfigRequest.ProtoReflect.Descriptor instead.
 func (*DeleteBypassConfigRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{8}
+	return file_dpi_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *DeleteBypassConfigRequest) GetId() string {
@@ -900,7 +1024,7 @@ type DeleteBypassConfigResponse struct {
 
 func (x *DeleteBypassConfigResponse) Reset() {
 	*x = DeleteBypassConfigResponse{}
-	mi := &file_dpi_proto_msgTypes[9]
+	mi := &file_dpi_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -912,7 +1036,7 @@ func (x *DeleteBypassConfigResponse) String() string {
 func (*DeleteBypassConfigResponse) ProtoMessage() {}
 
 func (x *DeleteBypassConfigResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[9]
+	mi := &file_dpi_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -925,7 +1049,7 @@ func (x *DeleteBypassConfigResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteBypassConfigResponse.ProtoReflect.Descriptor instead.
 func (*DeleteBypassConfigResponse) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{9}
+	return file_dpi_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *DeleteBypassConfigResponse) GetSuccess() bool {
@@ -948,7 +1072,7 @@ type StartBypassRequest struct {
 
 func (x *StartBypassRequest) Reset() {
 	*x = StartBypassRequest{}
-	mi := &file_dpi_proto_msgTypes[10]
+	mi := &file_dpi_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -960,7 +1084,7 @@ func (x *StartBypassRequest) String() string {
 func (*StartBypassRequest) ProtoMessage() {}
 
 func (x *StartBypassRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[10]
+	mi := &file_dpi_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -973,7 +1097,7 @@ func (x *StartBypassRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartBypassRequest.ProtoReflect.Descriptor instead.
 func (*StartBypassRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{10}
+	return file_dpi_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *StartBypassRequest) GetConfigId() string {
@@ -1015,7 +1139,7 @@ type StartBypassResponse struct {
 
 func (x *StartBypassResponse) Reset() {
 	*x = StartBypassResponse{}
-	mi := &file_dpi_proto_msgTypes[11]
+	mi := &file_dpi_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1027,7 +1151,7 @@ func (x *StartBypassResponse) String() string {
 func (*StartBypassResponse) ProtoMessage() {}
 
 func (x *StartBypassResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[11]
+	mi := &file_dpi_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1040,7 +1164,7 @@ func (x *StartBypassResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartBypassResponse.ProtoReflect.Descriptor instead.
 func (*StartBypassResponse) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{11}
+	return file_dpi_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *StartBypassResponse) GetSuccess() bool {
@@ -1073,7 +1197,7 @@ type StopBypassRequest struct {
 
 func (x *StopBypassRequest) Reset() {
 	*x = StopBypassRequest{}
-	mi := &file_dpi_proto_msgTypes[12]
+	mi := &file_dpi_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1085,7 +1209,7 @@ func (x *StopBypassRequest) String() string {
 func (*StopBypassRequest) ProtoMessage() {}
 
 func (x *StopBypassRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[12]
+	mi := &file_dpi_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1098,7 +1222,7 @@ func (x *StopBypassRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopBypassRequest.ProtoReflect.Descriptor instead.
 func (*StopBypassRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{12}
+	return file_dpi_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *StopBypassRequest) GetSessionId() string {
@@ -1118,7 +1242,7 @@ type StopBypassResponse struct {
 
 func (x *StopBypassResponse) Reset() {
 	*x = StopBypassResponse{}
-	mi := &file_dpi_proto_msgTypes[13]
+	mi := &file_dpi_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1130,7 +1254,7 @@ func (x *StopBypassResponse) String() string {
 func (*StopBypassResponse) ProtoMessage() {}
 
 func (x *StopBypassResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[13]
+	mi := &file_dpi_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1143,7 +1267,7 @@ func (x *StopBypassResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopBypassResponse.ProtoReflect.Descriptor instead.
 func (*StopBypassResponse) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{13}
+	return file_dpi_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *StopBypassResponse) GetSuccess() bool {
@@ -1169,7 +1293,7 @@ type GetBypassStatusRequest struct {
 
 func (x *GetBypassStatusRequest) Reset() {
 	*x = GetBypassStatusRequest{}
-	mi := &file_dpi_proto_msgTypes[14]
+	mi := &file_dpi_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1181,7 +1305,7 @@ func (x *GetBypassStatusRequest) String() string {
 func (*GetBypassStatusRequest) ProtoMessage() {}
 
 func (x *GetBypassStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[14]
+	mi := &file_dpi_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1194,7 +1318,7 @@ func (x *GetBypassStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBypassStatusRequest.ProtoReflect.Descriptor instead.
 func (*GetBypassStatusRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{14}
+	return file_dpi_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GetBypassStatusRequest) GetSessionId() string {
@@ -1220,7 +1344,7 @@ type GetBypassStatusResponse struct {
 
 func (x *GetBypassStatusResponse) Reset() {
 	*x = GetBypassStatusResponse{}
-	mi := &file_dpi_proto_msgTypes[15]
+	mi := &file_dpi_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1232,7 +1356,7 @@ func (x *GetBypassStatusResponse) String() string {
 func (*GetBypassStatusResponse) ProtoMessage() {}
 
 func (x *GetBypassStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[15]
+	mi := &file_dpi_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1245,7 +1369,7 @@ func (x *GetBypassStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBypassStatusResponse.ProtoReflect.Descriptor instead.
 func (*GetBypassStatusResponse) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{15}
+	return file_dpi_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GetBypassStatusResponse) GetSessionId() string {
@@ -1320,13 +1444,14 @@ type BypassStats struct {
 	AverageLatency         float64                `protobuf:"fixed64,11,opt,name=average_latency,json=averageLatency,proto3" json:"average_latency,omitempty"`
 	StartTime              *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
 	EndTime                *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Users                  []*UserStats           `protobuf:"bytes,14,rep,name=users,proto3" json:"users,omitempty"`
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
 }
 
 func (x *BypassStats) Reset() {
 	*x = BypassStats{}
-	mi := &file_dpi_proto_msgTypes[16]
+	mi := &file_dpi_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1338,7 +1463,7 @@ func (x *BypassStats) String() string {
 func (*BypassStats) ProtoMessage() {}
 
 func (x *BypassStats) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[16]
+	mi := &file_dpi_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1351,7 +1476,7 @@ func (x *BypassStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BypassStats.ProtoReflect.Descriptor instead.
 func (*BypassStats) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{16}
+	return file_dpi_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *BypassStats) GetId() string {
@@ -1445,6 +1570,13 @@ func (x *BypassStats) GetEndTime() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *BypassStats) GetUsers() []*UserStats {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
 type GetBypassStatsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
@@ -1455,7 +1587,7 @@ type GetBypassStatsRequest struct {
 
 func (x *GetBypassStatsRequest) Reset() {
 	*x = GetBypassStatsRequest{}
-	mi := &file_dpi_proto_msgTypes[17]
+	mi := &file_dpi_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1467,7 +1599,7 @@ func (x *GetBypassStatsRequest) String() string {
 func (*GetBypassStatsRequest) ProtoMessage() {}
 
 func (x *GetBypassStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[17]
+	mi := &file_dpi_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1480,7 +1612,7 @@ func (x *GetBypassStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBypassStatsRequest.ProtoReflect.Descriptor instead.
 func (*GetBypassStatsRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{17}
+	return file_dpi_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *GetBypassStatsRequest) GetSessionId() string {
@@ -1510,7 +1642,7 @@ type GetBypassHistoryRequest struct {
 
 func (x *GetBypassHistoryRequest) Reset() {
 	*x = GetBypassHistoryRequest{}
-	mi := &file_dpi_proto_msgTypes[18]
+	mi := &file_dpi_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1522,7 +1654,7 @@ func (x *GetBypassHistoryRequest) String() string {
 func (*GetBypassHistoryRequest) ProtoMessage() {}
 
 func (x *GetBypassHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[18]
+	mi := &file_dpi_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1535,7 +1667,7 @@ func (x *GetBypassHistoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBypassHistoryRequest.ProtoReflect.Descriptor instead.
 func (*GetBypassHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{18}
+	return file_dpi_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *GetBypassHistoryRequest) GetConfigId() string {
@@ -1583,7 +1715,7 @@ type GetBypassHistoryResponse struct {
 
 func (x *GetBypassHistoryResponse) Reset() {
 	*x = GetBypassHistoryResponse{}
-	mi := &file_dpi_proto_msgTypes[19]
+	mi := &file_dpi_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1595,7 +1727,7 @@ func (x *GetBypassHistoryResponse) String() string {
 func (*GetBypassHistoryResponse) ProtoMessage() {}
 
 func (x *GetBypassHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[19]
+	mi := &file_dpi_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1608,7 +1740,7 @@ func (x *GetBypassHistoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBypassHistoryResponse.ProtoReflect.Descriptor instead.
 func (*GetBypassHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{19}
+	return file_dpi_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *GetBypassHistoryResponse) GetEntries() []*BypassHistoryEntry {
@@ -1644,7 +1776,7 @@ type BypassHistoryEntry struct {
 
 func (x *BypassHistoryEntry) Reset() {
 	*x = BypassHistoryEntry{}
-	mi := &file_dpi_proto_msgTypes[20]
+	mi := &file_dpi_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1656,7 +1788,7 @@ func (x *BypassHistoryEntry) String() string {
 func (*BypassHistoryEntry) ProtoMessage() {}
 
 func (x *BypassHistoryEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[20]
+	mi := &file_dpi_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1669,7 +1801,7 @@ func (x *BypassHistoryEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BypassHistoryEntry.ProtoReflect.Descriptor instead.
 func (*BypassHistoryEntry) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{20}
+	return file_dpi_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *BypassHistoryEntry) GetId() string {
@@ -1749,39 +1881,38 @@ func (x *BypassHistoryEntry) GetErrorMessage() string {
 	return ""
 }
 
-// Bypass Rules
-type BypassRule struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	ConfigId      string                 `protobuf:"bytes,2,opt,name=config_id,json=configId,proto3" json:"config_id,omitempty"`
-	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
-	Type          RuleType               `protobuf:"varint,4,opt,name=type,proto3,enum=dpi.RuleType" json:"type,omitempty"`
-	Action        RuleAction             `protobuf:"varint,5,opt,name=action,proto3,enum=dpi.RuleAction" json:"action,omitempty"`
-	Pattern       string                 `protobuf:"bytes,6,opt,name=pattern,proto3" json:"pattern,omitempty"`
-	Parameters    map[string]string      `protobuf:"bytes,7,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Priority      int32                  `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`
-	Enabled       bool                   `protobuf:"varint,9,opt,name=enabled,proto3" json:"enabled,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *BypassRule) Reset() {
-	*x = BypassRule{}
-	mi := &file_dpi_proto_msgTypes[21]
+// Censorship signals aggregated per method, target and time window
+type CensorshipSignalStats struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Method              string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Target              string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	WindowStart         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=window_start,json=windowStart,proto3" json:"window_start,omitempty"`
+	WindowEnd           *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=window_end,json=windowEnd,proto3" json:"window_end,omitempty"`
+	Connections         int64                  `protobuf:"varint,5,opt,name=connections,proto3" json:"connections,omitempty"`
+	RstAfterClientHello int64                  `protobuf:"varint,6,opt,name=rst_after_client_hello,json=rstAfterClientHello,proto3" json:"rst_after_client_hello,omitempty"`
+	TimeoutAfterSni     int64                  `protobuf:"varint,7,opt,name=timeout_after_sni,json=timeoutAfterSni,proto3" json:"timeout_after_sni,omitempty"`
+	DnsPoisoning        int64                  `protobuf:"varint,8,opt,name=dns_poisoning,json=dnsPoisoning,proto3" json:"dns_poisoning,omitempty"`
+	Throttling          int64                  `protobuf:"varint,9,opt,name=throttling,proto3" json:"throttling,omitempty"`
+	Blocked             int64                  `protobuf:"varint,10,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CensorshipSignalStats) Reset() {
+	*x = CensorshipSignalStats{}
+	mi := &file_dpi_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BypassRule) String() string {
+func (x *CensorshipSignalStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BypassRule) ProtoMessage() {}
+func (*CensorshipSignalStats) ProtoMessage() {}
 
-func (x *BypassRule) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[21]
+func (x *CensorshipSignalStats) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1792,26 +1923,264 @@ func (x *BypassRule) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BypassRule.ProtoReflect.Descriptor instead.
-func (*BypassRule) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use CensorshipSignalStats.ProtoReflect.Descriptor instead.
+func (*CensorshipSignalStats) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *BypassRule) GetId() string {
+func (x *CensorshipSignalStats) GetMethod() string {
 	if x != nil {
-		return x.Id
+		return x.Method
 	}
 	return ""
 }
 
-func (x *BypassRule) GetConfigId() string {
+func (x *CensorshipSignalStats) GetTarget() string {
 	if x != nil {
-		return x.ConfigId
+		return x.Target
 	}
 	return ""
 }
 
-func (x *BypassRule) GetName() string {
+func (x *CensorshipSignalStats) GetWindowStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WindowStart
+	}
+	return nil
+}
+
+func (x *CensorshipSignalStats) GetWindowEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WindowEnd
+	}
+	return nil
+}
+
+func (x *CensorshipSignalStats) GetConnections() int64 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *CensorshipSignalStats) GetRstAfterClientHello() int64 {
+	if x != nil {
+		return x.RstAfterClientHello
+	}
+	return 0
+}
+
+func (x *CensorshipSignalStats) GetTimeoutAfterSni() int64 {
+	if x != nil {
+		return x.TimeoutAfterSni
+	}
+	return 0
+}
+
+func (x *CensorshipSignalStats) GetDnsPoisoning() int64 {
+	if x != nil {
+		return x.DnsPoisoning
+	}
+	return 0
+}
+
+func (x *CensorshipSignalStats) GetThrottling() int64 {
+	if x != nil {
+		return x.Throttling
+	}
+	return 0
+}
+
+func (x *CensorshipSignalStats) GetBlocked() int64 {
+	if x != nil {
+		return x.Blocked
+	}
+	return 0
+}
+
+type GetCensorshipSignalsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	WindowSeconds int64                  `protobuf:"varint,5,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCensorshipSignalsRequest) Reset() {
+	*x = GetCensorshipSignalsRequest{}
+	mi := &file_dpi_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCensorshipSignalsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCensorshipSignalsRequest) ProtoMessage() {}
+
+func (x *GetCensorshipSignalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCensorshipSignalsRequest.ProtoReflect.Descriptor instead.
+func (*GetCensorshipSignalsRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetCensorshipSignalsRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *GetCensorshipSignalsRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *GetCensorshipSignalsRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetCensorshipSignalsRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *GetCensorshipSignalsRequest) GetWindowSeconds() int64 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+type GetCensorshipSignalsResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Signals       []*CensorshipSignalStats `protobuf:"bytes,1,rep,name=signals,proto3" json:"signals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCensorshipSignalsResponse) Reset() {
+	*x = GetCensorshipSignalsResponse{}
+	mi := &file_dpi_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCensorshipSignalsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCensorshipSignalsResponse) ProtoMessage() {}
+
+func (x *GetCensorshipSignalsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCensorshipSignalsResponse.ProtoReflect.Descriptor instead.
+func (*GetCensorshipSignalsResponse) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetCensorshipSignalsResponse) GetSignals() []*CensorshipSignalStats {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+// Bypass Rules
+type BypassRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ConfigId      string                 `protobuf:"bytes,2,opt,name=config_id,json=configId,proto3" json:"config_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Type          RuleType               `protobuf:"varint,4,opt,name=type,proto3,enum=dpi.RuleType" json:"type,omitempty"`
+	Action        RuleAction             `protobuf:"varint,5,opt,name=action,proto3,enum=dpi.RuleAction" json:"action,omitempty"`
+	Pattern       string                 `protobuf:"bytes,6,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Parameters    map[string]string      `protobuf:"bytes,7,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Priority      int32                  `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`
+	Enabled       bool                   `protobuf:"varint,9,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BypassRule) Reset() {
+	*x = BypassRule{}
+	mi := &file_dpi_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BypassRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BypassRule) ProtoMessage() {}
+
+func (x *BypassRule) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BypassRule.ProtoReflect.Descriptor instead.
+func (*BypassRule) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BypassRule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BypassRule) GetConfigId() string {
+	if x != nil {
+		return x.ConfigId
+	}
+	return ""
+}
+
+func (x *BypassRule) GetName() string {
 	if x != nil {
 		return x.Name
 	}
@@ -1889,7 +2258,7 @@ type AddBypassRuleRequest struct {
 
 func (x *AddBypassRuleRequest) Reset() {
 	*x = AddBypassRuleRequest{}
-	mi := &file_dpi_proto_msgTypes[22]
+	mi := &file_dpi_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1901,7 +2270,7 @@ func (x *AddBypassRuleRequest) String() string {
 func (*AddBypassRuleRequest) ProtoMessage() {}
 
 func (x *AddBypassRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[22]
+	mi := &file_dpi_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1914,7 +2283,7 @@ func (x *AddBypassRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddBypassRuleRequest.ProtoReflect.Descriptor instead.
 func (*AddBypassRuleRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{22}
+	return file_dpi_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *AddBypassRuleRequest) GetConfigId() string {
@@ -1982,7 +2351,7 @@ type UpdateBypassRuleRequest struct {
 
 func (x *UpdateBypassRuleRequest) Reset() {
 	*x = UpdateBypassRuleRequest{}
-	mi := &file_dpi_proto_msgTypes[23]
+	mi := &file_dpi_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1994,7 +2363,7 @@ func (x *UpdateBypassRuleRequest) String() string {
 func (*UpdateBypassRuleRequest) ProtoMessage() {}
 
 func (x *UpdateBypassRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[23]
+	mi := &file_dpi_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2007,7 +2376,7 @@ func (x *UpdateBypassRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateBypassRuleRequest.ProtoReflect.Descriptor instead.
 func (*UpdateBypassRuleRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{23}
+	return file_dpi_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *UpdateBypassRuleRequest) GetId() string {
@@ -2075,7 +2444,7 @@ type DeleteBypassRuleRequest struct {
 
 func (x *DeleteBypassRuleRequest) Reset() {
 	*x = DeleteBypassRuleRequest{}
-	mi := &file_dpi_proto_msgTypes[24]
+	mi := &file_dpi_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2087,7 +2456,7 @@ func (x *DeleteBypassRuleRequest) String() string {
 func (*DeleteBypassRuleRequest) ProtoMessage() {}
 
 func (x *DeleteBypassRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[24]
+	mi := &file_dpi_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2100,7 +2469,7 @@ func (x *DeleteBypassRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteBypassRuleRequest.ProtoReflect.Descriptor instead.
 func (*DeleteBypassRuleRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{24}
+	return file_dpi_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *DeleteBypassRuleRequest) GetId() string {
@@ -2119,7 +2488,7 @@ type DeleteBypassRuleResponse struct {
 
 func (x *DeleteBypassRuleResponse) Reset() {
 	*x = DeleteBypassRuleResponse{}
-	mi := &file_dpi_proto_msgTypes[25]
+	mi := &file_dpi_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2131,7 +2500,7 @@ func (x *DeleteBypassRuleResponse) String() string {
 func (*DeleteBypassRuleResponse) ProtoMessage() {}
 
 func (x *DeleteBypassRuleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[25]
+	mi := &file_dpi_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2144,7 +2513,7 @@ func (x *DeleteBypassRuleResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteBypassRuleResponse.ProtoReflect.Descriptor instead.
 func (*DeleteBypassRuleResponse) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{25}
+	return file_dpi_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *DeleteBypassRuleResponse) GetSuccess() bool {
@@ -2167,7 +2536,7 @@ type ListBypassRulesRequest struct {
 
 func (x *ListBypassRulesRequest) Reset() {
 	*x = ListBypassRulesRequest{}
-	mi := &file_dpi_proto_msgTypes[26]
+	mi := &file_dpi_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2179,7 +2548,7 @@ func (x *ListBypassRulesRequest) String() string {
 func (*ListBypassRulesRequest) ProtoMessage() {}
 
 func (x *ListBypassRulesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[26]
+	mi := &file_dpi_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2192,7 +2561,7 @@ func (x *ListBypassRulesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListBypassRulesRequest.ProtoReflect.Descriptor instead.
 func (*ListBypassRulesRequest) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{26}
+	return file_dpi_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *ListBypassRulesRequest) GetConfigId() string {
@@ -2240,7 +2609,7 @@ type ListBypassRulesResponse struct {
 
 func (x *ListBypassRulesResponse) Reset() {
 	*x = ListBypassRulesResponse{}
-	mi := &file_dpi_proto_msgTypes[27]
+	mi := &file_dpi_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2252,7 +2621,7 @@ func (x *ListBypassRulesResponse) String() string {
 func (*ListBypassRulesResponse) ProtoMessage() {}
 
 func (x *ListBypassRulesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_dpi_proto_msgTypes[27]
+	mi := &file_dpi_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2265,7 +2634,7 @@ func (x *ListBypassRulesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListBypassRulesResponse.ProtoReflect.Descriptor instead.
 func (*ListBypassRulesResponse) Descriptor() ([]byte, []int) {
-	return file_dpi_proto_rawDescGZIP(), []int{27}
+	return file_dpi_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *ListBypassRulesResponse) GetRules() []*BypassRule {
@@ -2282,46 +2651,1246 @@ func (x *ListBypassRulesResponse) GetTotal() int32 {
 	return 0
 }
 
-var File_dpi_proto protoreflect.FileDescriptor
+// Bypass Users
+type BypassUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ConfigId      string                 `protobuf:"bytes,2,opt,name=config_id,json=configId,proto3" json:"config_id,omitempty"`
+	Credential    string                 `protobuf:"bytes,3,opt,name=credential,proto3" json:"credential,omitempty"`
+	Revoked       bool                   `protobuf:"varint,4,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_dpi_proto_rawDesc = "" +
-	"\n" +
-	"\tdpi.proto\x12\x03dpi\x1a\x1fgoogle/protobuf/timestamp.proto\"\x0f\n" +
-	"\rHealthRequest\"|\n" +
-	"\x0eHealthResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion\x128\n" +
-	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xee\x03\n" +
-	"\fBypassConfig\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12#\n" +
-	"\x04type\x18\x04 \x01(\x0e2\x0f.dpi.BypassTypeR\x04type\x12)\n" +
-	"\x06method\x18\x05 \x01(\x0e2\x11.dpi.BypassMethodR\x06method\x12)\n" +
-	"\x06status\x18\x06 \x01(\x0e2\x11.dpi.BypassStatusR\x06status\x12A\n" +
-	"\n" +
-	"parameters\x18\a \x03(\v2!.dpi.BypassConfig.ParametersEntryR\n" +
-	"parameters\x12%\n" +
-	"\x05rules\x18\b \x03(\v2\x0f.dpi.BypassRuleR\x05rules\x129\n" +
-	"\n" +
-	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
-	"\n" +
-	"updated_at\x18\n" +
-	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x1a=\n" +
-	"\x0fParametersEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb0\x02\n" +
-	"\x19CreateBypassConfigRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x02 \x01(\tR\vdescription\x12#\n" +
-	"\x04type\x18\x03 \x01(\x0e2\x0f.dpi.BypassTypeR\x04type\x12)\n" +
-	"\x06method\x18\x04 \x01(\x0e2\x11.dpi.BypassMethodR\x06method\x12N\n" +
-	"\n" +
-	"parameters\x18\x05 \x03(\v2..dpi.CreateBypassConfigRequest.ParametersEntryR\n" +
-	"parameters\x1a=\n" +
-	"\x0fParametersEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"(\n" +
+func (x *BypassUser) Reset() {
+	*x = BypassUser{}
+	mi := &file_dpi_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BypassUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BypassUser) ProtoMessage() {}
+
+func (x *BypassUser) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BypassUser.ProtoReflect.Descriptor instead.
+func (*BypassUser) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *BypassUser) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BypassUser) GetConfigId() string {
+	if x != nil {
+		return x.ConfigId
+	}
+	return ""
+}
+
+func (x *BypassUser) GetCredential() string {
+	if x != nil {
+		return x.Credential
+	}
+	return ""
+}
+
+func (x *BypassUser) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+func (x *BypassUser) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *BypassUser) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type UserStats struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	UserId            string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	BytesSent         int64                  `protobuf:"varint,2,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	BytesReceived     int64                  `protobuf:"varint,3,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	Connections       int64                  `protobuf:"varint,4,opt,name=connections,proto3" json:"connections,omitempty"`
+	ActiveConnections int64                  `protobuf:"varint,5,opt,name=active_connections,json=activeConnections,proto3" json:"active_connections,omitempty"`
+	LastActivity      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_activity,json=lastActivity,proto3" json:"last_activity,omitempty"`
+	Revoked           bool                   `protobuf:"varint,7,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *UserStats) Reset() {
+	*x = UserStats{}
+	mi := &file_dpi_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserStats) ProtoMessage() {}
+
+func (x *UserStats) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserStats.ProtoReflect.Descriptor instead.
+func (*UserStats) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UserStats) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UserStats) GetBytesSent() int64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *UserStats) GetBytesReceived() int64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+func (x *UserStats) GetConnections() int64 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *UserStats) GetActiveConnections() int64 {
+	if x != nil {
+		return x.ActiveConnections
+	}
+	return 0
+}
+
+func (x *UserStats) GetLastActivity() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivity
+	}
+	return nil
+}
+
+func (x *UserStats) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+type AddBypassUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConfigId      string                 `protobuf:"bytes,1,opt,name=config_id,json=configId,proto3" json:"config_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Credential    string                 `protobuf:"bytes,3,opt,name=credential,proto3" json:"credential,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddBypassUserRequest) Reset() {
+	*x = AddBypassUserRequest{}
+	mi := &file_dpi_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddBypassUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBypassUserRequest) ProtoMessage() {}
+
+func (x *AddBypassUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBypassUserRequest.ProtoReflect.Descriptor instead.
+func (*AddBypassUserRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *AddBypassUserRequest) GetConfigId() string {
+	if x != nil {
+		return x.ConfigId
+	}
+	return ""
+}
+
+func (x *AddBypassUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AddBypassUserRequest) GetCredential() string {
+	if x != nil {
+		return x.Credential
+	}
+	return ""
+}
+
+type RevokeBypassUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConfigId      string                 `protobuf:"bytes,1,opt,name=config_id,json=configId,proto3" json:"config_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeBypassUserRequest) Reset() {
+	*x = RevokeBypassUserRequest{}
+	mi := &file_dpi_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeBypassUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeBypassUserRequest) ProtoMessage() {}
+
+func (x *RevokeBypassUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeBypassUserRequest.ProtoReflect.Descriptor instead.
+func (*RevokeBypassUserRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RevokeBypassUserRequest) GetConfigId() string {
+	if x != nil {
+		return x.ConfigId
+	}
+	return ""
+}
+
+func (x *RevokeBypassUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type RevokeBypassUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeBypassUserResponse) Reset() {
+	*x = RevokeBypassUserResponse{}
+	mi := &file_dpi_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeBypassUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeBypassUserResponse) ProtoMessage() {}
+
+func (x *RevokeBypassUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeBypassUserResponse.ProtoReflect.Descriptor instead.
+func (*RevokeBypassUserResponse) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RevokeBypassUserResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ListBypassUsersRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConfigId       string                 `protobuf:"bytes,1,opt,name=config_id,json=configId,proto3" json:"config_id,omitempty"`
+	IncludeRevoked bool                   `protobuf:"varint,2,opt,name=include_revoked,json=includeRevoked,proto3" json:"include_revoked,omitempty"`
+	Limit          int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset         int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListBypassUsersRequest) Reset() {
+	*x = ListBypassUsersRequest{}
+	mi := &file_dpi_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBypassUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBypassUsersRequest) ProtoMessage() {}
+
+func (x *ListBypassUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBypassUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListBypassUsersRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ListBypassUsersRequest) GetConfigId() string {
+	if x != nil {
+		return x.ConfigId
+	}
+	return ""
+}
+
+func (x *ListBypassUsersRequest) GetIncludeRevoked() bool {
+	if x != nil {
+		return x.IncludeRevoked
+	}
+	return false
+}
+
+func (x *ListBypassUsersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListBypassUsersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListBypassUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*BypassUser          `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBypassUsersResponse) Reset() {
+	*x = ListBypassUsersResponse{}
+	mi := &file_dpi_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBypassUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBypassUsersResponse) ProtoMessage() {}
+
+func (x *ListBypassUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBypassUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListBypassUsersResponse) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListBypassUsersResponse) GetUsers() []*BypassUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListBypassUsersResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// Share Links and Subscriptions
+type ShareLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConfigId      string                 `protobuf:"bytes,1,opt,name=config_id,json=configId,proto3" json:"config_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Method        string                 `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Server        string                 `protobuf:"bytes,5,opt,name=server,proto3" json:"server,omitempty"`
+	Port          int32                  `protobuf:"varint,6,opt,name=port,proto3" json:"port,omitempty"`
+	Uri           string                 `protobuf:"bytes,7,opt,name=uri,proto3" json:"uri,omitempty"`
+	Cipher        string                 `protobuf:"bytes,8,opt,name=cipher,proto3" json:"cipher,omitempty"`
+	Password      string                 `protobuf:"bytes,9,opt,name=password,proto3" json:"password,omitempty"`
+	Uuid          string                 `protobuf:"bytes,10,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Tls           bool                   `protobuf:"varint,11,opt,name=tls,proto3" json:"tls,omitempty"`
+	ServerName    string                 `protobuf:"bytes,12,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	Secret        string                 `protobuf:"bytes,13,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareLink) Reset() {
+	*x = ShareLink{}
+	mi := &file_dpi_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareLink) ProtoMessage() {}
+
+func (x *ShareLink) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareLink.ProtoReflect.Descriptor instead.
+func (*ShareLink) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ShareLink) GetConfigId() string {
+	if x != nil {
+		return x.ConfigId
+	}
+	return ""
+}
+
+func (x *ShareLink) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ShareLink) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ShareLink) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ShareLink) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *ShareLink) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ShareLink) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *ShareLink) GetCipher() string {
+	if x != nil {
+		return x.Cipher
+	}
+	return ""
+}
+
+func (x *ShareLink) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ShareLink) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *ShareLink) GetTls() bool {
+	if x != nil {
+		return x.Tls
+	}
+	return false
+}
+
+func (x *ShareLink) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *ShareLink) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+type GetShareLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConfigId      string                 `protobuf:"bytes,1,opt,name=config_id,json=configId,proto3" json:"config_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShareLinkRequest) Reset() {
+	*x = GetShareLinkRequest{}
+	mi := &file_dpi_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShareLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShareLinkRequest) ProtoMessage() {}
+
+func (x *GetShareLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShareLinkRequest.ProtoReflect.Descriptor instead.
+func (*GetShareLinkRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetShareLinkRequest) GetConfigId() string {
+	if x != nil {
+		return x.ConfigId
+	}
+	return ""
+}
+
+func (x *GetShareLinkRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type SubscriptionToken struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId         string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Token          string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	Revoked        bool                   `protobuf:"varint,4,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastAccessedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_accessed_at,json=lastAccessedAt,proto3" json:"last_accessed_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SubscriptionToken) Reset() {
+	*x = SubscriptionToken{}
+	mi := &file_dpi_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriptionToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriptionToken) ProtoMessage() {}
+
+func (x *SubscriptionToken) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriptionToken.ProtoReflect.Descriptor instead.
+func (*SubscriptionToken) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *SubscriptionToken) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SubscriptionToken) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SubscriptionToken) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *SubscriptionToken) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+func (x *SubscriptionToken) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *SubscriptionToken) GetLastAccessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastAccessedAt
+	}
+	return nil
+}
+
+type CreateSubscriptionTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSubscriptionTokenRequest) Reset() {
+	*x = CreateSubscriptionTokenRequest{}
+	mi := &file_dpi_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSubscriptionTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSubscriptionTokenRequest) ProtoMessage() {}
+
+func (x *CreateSubscriptionTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSubscriptionTokenRequest.ProtoReflect.Descriptor instead.
+func (*CreateSubscriptionTokenRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CreateSubscriptionTokenRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type RevokeSubscriptionTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSubscriptionTokenRequest) Reset() {
+	*x = RevokeSubscriptionTokenRequest{}
+	mi := &file_dpi_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSubscriptionTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSubscriptionTokenRequest) ProtoMessage() {}
+
+func (x *RevokeSubscriptionTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSubscriptionTokenRequest.ProtoReflect.Descriptor instead.
+func (*RevokeSubscriptionTokenRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *RevokeSubscriptionTokenRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RevokeSubscriptionTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSubscriptionTokenResponse) Reset() {
+	*x = RevokeSubscriptionTokenResponse{}
+	mi := &file_dpi_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSubscriptionTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSubscriptionTokenResponse) ProtoMessage() {}
+
+func (x *RevokeSubscriptionTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSubscriptionTokenResponse.ProtoReflect.Descriptor instead.
+func (*RevokeSubscriptionTokenResponse) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *RevokeSubscriptionTokenResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ListSubscriptionTokensRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	IncludeRevoked bool                   `protobuf:"varint,2,opt,name=include_revoked,json=includeRevoked,proto3" json:"include_revoked,omitempty"`
+	Limit          int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset         int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionTokensRequest) Reset() {
+	*x = ListSubscriptionTokensRequest{}
+	mi := &file_dpi_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionTokensRequest) ProtoMessage() {}
+
+func (x *ListSubscriptionTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionTokensRequest.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionTokensRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ListSubscriptionTokensRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListSubscriptionTokensRequest) GetIncludeRevoked() bool {
+	if x != nil {
+		return x.IncludeRevoked
+	}
+	return false
+}
+
+func (x *ListSubscriptionTokensRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListSubscriptionTokensRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListSubscriptionTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*SubscriptionToken   `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionTokensResponse) Reset() {
+	*x = ListSubscriptionTokensResponse{}
+	mi := &file_dpi_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionTokensResponse) ProtoMessage() {}
+
+func (x *ListSubscriptionTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionTokensResponse.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionTokensResponse) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ListSubscriptionTokensResponse) GetTokens() []*SubscriptionToken {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *ListSubscriptionTokensResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type SubscriptionAccess struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TokenId       string                 `protobuf:"bytes,2,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`
+	RemoteAddr    string                 `protobuf:"bytes,5,opt,name=remote_addr,json=remoteAddr,proto3" json:"remote_addr,omitempty"`
+	UserAgent     string                 `protobuf:"bytes,6,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	AccessedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=accessed_at,json=accessedAt,proto3" json:"accessed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscriptionAccess) Reset() {
+	*x = SubscriptionAccess{}
+	mi := &file_dpi_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriptionAccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriptionAccess) ProtoMessage() {}
+
+func (x *SubscriptionAccess) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriptionAccess.ProtoReflect.Descriptor instead.
+func (*SubscriptionAccess) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *SubscriptionAccess) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SubscriptionAccess) GetTokenId() string {
+	if x != nil {
+		return x.TokenId
+	}
+	return ""
+}
+
+func (x *SubscriptionAccess) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SubscriptionAccess) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *SubscriptionAccess) GetRemoteAddr() string {
+	if x != nil {
+		return x.RemoteAddr
+	}
+	return ""
+}
+
+func (x *SubscriptionAccess) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *SubscriptionAccess) GetAccessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AccessedAt
+	}
+	return nil
+}
+
+type ListSubscriptionAccessesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenId       string                 `protobuf:"bytes,1,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionAccessesRequest) Reset() {
+	*x = ListSubscriptionAccessesRequest{}
+	mi := &file_dpi_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionAccessesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionAccessesRequest) ProtoMessage() {}
+
+func (x *ListSubscriptionAccessesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionAccessesRequest.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionAccessesRequest) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ListSubscriptionAccessesRequest) GetTokenId() string {
+	if x != nil {
+		return x.TokenId
+	}
+	return ""
+}
+
+func (x *ListSubscriptionAccessesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListSubscriptionAccessesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListSubscriptionAccessesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListSubscriptionAccessesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accesses      []*SubscriptionAccess  `protobuf:"bytes,1,rep,name=accesses,proto3" json:"accesses,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionAccessesResponse) Reset() {
+	*x = ListSubscriptionAccessesResponse{}
+	mi := &file_dpi_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionAccessesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionAccessesResponse) ProtoMessage() {}
+
+func (x *ListSubscriptionAccessesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dpi_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionAccessesResponse.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionAccessesResponse) Descriptor() ([]byte, []int) {
+	return file_dpi_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ListSubscriptionAccessesResponse) GetAccesses() []*SubscriptionAccess {
+	if x != nil {
+		return x.Accesses
+	}
+	return nil
+}
+
+func (x *ListSubscriptionAccessesResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+var File_dpi_proto protoreflect.FileDescriptor
+
+const file_dpi_proto_rawDesc = "" +
+	"\n" +
+	"\tdpi.proto\x12\x03dpi\x1a\x1fgoogle/protobuf/timestamp.proto\"\x0f\n" +
+	"\rHealthRequest\"|\n" +
+	"\x0eHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x9c\x04\n" +
+	"\fBypassConfig\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12#\n" +
+	"\x04type\x18\x04 \x01(\x0e2\x0f.dpi.BypassTypeR\x04type\x12)\n" +
+	"\x06method\x18\x05 \x01(\x0e2\x11.dpi.BypassMethodR\x06method\x12)\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x11.dpi.BypassStatusR\x06status\x12A\n" +
+	"\n" +
+	"parameters\x18\a \x03(\v2!.dpi.BypassConfig.ParametersEntryR\n" +
+	"parameters\x12%\n" +
+	"\x05rules\x18\b \x03(\v2\x0f.dpi.BypassRuleR\x05rules\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12,\n" +
+	"\areloads\x18\v \x03(\v2\x12.dpi.SessionReloadR\areloads\x1a=\n" +
+	"\x0fParametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"v\n" +
+	"\rSessionReload\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12,\n" +
+	"\aoutcome\x18\x02 \x01(\x0e2\x12.dpi.ReloadOutcomeR\aoutcome\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xb0\x02\n" +
+	"\x19CreateBypassConfigRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12#\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x0f.dpi.BypassTypeR\x04type\x12)\n" +
+	"\x06method\x18\x04 \x01(\x0e2\x11.dpi.BypassMethodR\x06method\x12N\n" +
+	"\n" +
+	"parameters\x18\x05 \x03(\v2..dpi.CreateBypassConfigRequest.ParametersEntryR\n" +
+	"parameters\x1a=\n" +
+	"\x0fParametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"(\n" +
 	"\x16GetBypassConfigRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"\x98\x01\n" +
 	"\x18ListBypassConfigsRequest\x12#\n" +
@@ -2384,7 +3953,7 @@ const file_dpi_proto_rawDesc = "" +
 	"\n" +
 	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12)\n" +
 	"\x10duration_seconds\x18\a \x01(\x03R\x0fdurationSeconds\x12\x18\n" +
-	"\amessage\x18\b \x01(\tR\amessage\"\x93\x04\n" +
+	"\amessage\x18\b \x01(\tR\amessage\"\xb9\x04\n" +
 	"\vBypassStats\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
 	"\tconfig_id\x18\x02 \x01(\tR\bconfigId\x12\x1d\n" +
@@ -2402,7 +3971,8 @@ const file_dpi_proto_rawDesc = "" +
 	"\x0faverage_latency\x18\v \x01(\x01R\x0eaverageLatency\x129\n" +
 	"\n" +
 	"start_time\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
-	"\bend_time\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\"S\n" +
+	"\bend_time\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12$\n" +
+	"\x05users\x18\x0e \x03(\v2\x0e.dpi.UserStatsR\x05users\"S\n" +
 	"\x15GetBypassStatsRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
@@ -2433,7 +4003,31 @@ const file_dpi_proto_rawDesc = "" +
 	"\x10duration_seconds\x18\t \x01(\x03R\x0fdurationSeconds\x12+\n" +
 	"\x11bytes_transferred\x18\n" +
 	" \x01(\x03R\x10bytesTransferred\x12#\n" +
-	"\rerror_message\x18\v \x01(\tR\ferrorMessage\"\xdf\x03\n" +
+	"\rerror_message\x18\v \x01(\tR\ferrorMessage\"\xa3\x03\n" +
+	"\x15CensorshipSignalStats\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12=\n" +
+	"\fwindow_start\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vwindowStart\x129\n" +
+	"\n" +
+	"window_end\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\twindowEnd\x12 \n" +
+	"\vconnections\x18\x05 \x01(\x03R\vconnections\x123\n" +
+	"\x16rst_after_client_hello\x18\x06 \x01(\x03R\x13rstAfterClientHello\x12*\n" +
+	"\x11timeout_after_sni\x18\a \x01(\x03R\x0ftimeoutAfterSni\x12#\n" +
+	"\rdns_poisoning\x18\b \x01(\x03R\fdnsPoisoning\x12\x1e\n" +
+	"\n" +
+	"throttling\x18\t \x01(\x03R\n" +
+	"throttling\x12\x18\n" +
+	"\ablocked\x18\n" +
+	" \x01(\x03R\ablocked\"\xe6\x01\n" +
+	"\x1bGetCensorshipSignalsRequest\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12%\n" +
+	"\x0ewindow_seconds\x18\x05 \x01(\x03R\rwindowSeconds\"T\n" +
+	"\x1cGetCensorshipSignalsResponse\x124\n" +
+	"\asignals\x18\x01 \x03(\v2\x1a.dpi.CensorshipSignalStatsR\asignals\"\xdf\x03\n" +
 	"\n" +
 	"BypassRule\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
@@ -2494,7 +4088,112 @@ const file_dpi_proto_rawDesc = "" +
 	"\x06offset\x18\x05 \x01(\x05R\x06offset\"V\n" +
 	"\x17ListBypassRulesResponse\x12%\n" +
 	"\x05rules\x18\x01 \x03(\v2\x0f.dpi.BypassRuleR\x05rules\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total*\xd7\x01\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xf2\x01\n" +
+	"\n" +
+	"BypassUser\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tconfig_id\x18\x02 \x01(\tR\bconfigId\x12\x1e\n" +
+	"\n" +
+	"credential\x18\x03 \x01(\tR\n" +
+	"credential\x12\x18\n" +
+	"\arevoked\x18\x04 \x01(\bR\arevoked\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x96\x02\n" +
+	"\tUserStats\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"bytes_sent\x18\x02 \x01(\x03R\tbytesSent\x12%\n" +
+	"\x0ebytes_received\x18\x03 \x01(\x03R\rbytesReceived\x12 \n" +
+	"\vconnections\x18\x04 \x01(\x03R\vconnections\x12-\n" +
+	"\x12active_connections\x18\x05 \x01(\x03R\x11activeConnections\x12?\n" +
+	"\rlast_activity\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\flastActivity\x12\x18\n" +
+	"\arevoked\x18\a \x01(\bR\arevoked\"l\n" +
+	"\x14AddBypassUserRequest\x12\x1b\n" +
+	"\tconfig_id\x18\x01 \x01(\tR\bconfigId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1e\n" +
+	"\n" +
+	"credential\x18\x03 \x01(\tR\n" +
+	"credential\"O\n" +
+	"\x17RevokeBypassUserRequest\x12\x1b\n" +
+	"\tconfig_id\x18\x01 \x01(\tR\bconfigId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"4\n" +
+	"\x18RevokeBypassUserResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x8c\x01\n" +
+	"\x16ListBypassUsersRequest\x12\x1b\n" +
+	"\tconfig_id\x18\x01 \x01(\tR\bconfigId\x12'\n" +
+	"\x0finclude_revoked\x18\x02 \x01(\bR\x0eincludeRevoked\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"V\n" +
+	"\x17ListBypassUsersResponse\x12%\n" +
+	"\x05users\x18\x01 \x03(\v2\x0f.dpi.BypassUserR\x05users\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xbe\x02\n" +
+	"\tShareLink\x12\x1b\n" +
+	"\tconfig_id\x18\x01 \x01(\tR\bconfigId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x16\n" +
+	"\x06server\x18\x05 \x01(\tR\x06server\x12\x12\n" +
+	"\x04port\x18\x06 \x01(\x05R\x04port\x12\x10\n" +
+	"\x03uri\x18\a \x01(\tR\x03uri\x12\x16\n" +
+	"\x06cipher\x18\b \x01(\tR\x06cipher\x12\x1a\n" +
+	"\bpassword\x18\t \x01(\tR\bpassword\x12\x12\n" +
+	"\x04uuid\x18\n" +
+	" \x01(\tR\x04uuid\x12\x10\n" +
+	"\x03tls\x18\v \x01(\bR\x03tls\x12\x1f\n" +
+	"\vserver_name\x18\f \x01(\tR\n" +
+	"serverName\x12\x16\n" +
+	"\x06secret\x18\r \x01(\tR\x06secret\"K\n" +
+	"\x13GetShareLinkRequest\x12\x1b\n" +
+	"\tconfig_id\x18\x01 \x01(\tR\bconfigId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\xed\x01\n" +
+	"\x11SubscriptionToken\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\x12\x18\n" +
+	"\arevoked\x18\x04 \x01(\bR\arevoked\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12D\n" +
+	"\x10last_accessed_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0elastAccessedAt\"9\n" +
+	"\x1eCreateSubscriptionTokenRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"0\n" +
+	"\x1eRevokeSubscriptionTokenRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\";\n" +
+	"\x1fRevokeSubscriptionTokenResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x8f\x01\n" +
+	"\x1dListSubscriptionTokensRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12'\n" +
+	"\x0finclude_revoked\x18\x02 \x01(\bR\x0eincludeRevoked\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"f\n" +
+	"\x1eListSubscriptionTokensResponse\x12.\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x16.dpi.SubscriptionTokenR\x06tokens\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xed\x01\n" +
+	"\x12SubscriptionAccess\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\btoken_id\x18\x02 \x01(\tR\atokenId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x1f\n" +
+	"\vremote_addr\x18\x05 \x01(\tR\n" +
+	"remoteAddr\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x06 \x01(\tR\tuserAgent\x12;\n" +
+	"\vaccessed_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"accessedAt\"\x83\x01\n" +
+	"\x1fListSubscriptionAccessesRequest\x12\x19\n" +
+	"\btoken_id\x18\x01 \x01(\tR\atokenId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"m\n" +
+	" ListSubscriptionAccessesResponse\x123\n" +
+	"\baccesses\x18\x01 \x03(\v2\x17.dpi.SubscriptionAccessR\baccesses\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total*\x84\x01\n" +
+	"\rReloadOutcome\x12\x1e\n" +
+	"\x1aRELOAD_OUTCOME_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16RELOAD_OUTCOME_APPLIED\x10\x01\x12\x1c\n" +
+	"\x18RELOAD_OUTCOME_RESTARTED\x10\x02\x12\x19\n" +
+	"\x15RELOAD_OUTCOME_FAILED\x10\x03*\xd7\x01\n" +
 	"\n" +
 	"BypassType\x12\x1b\n" +
 	"\x17BYPASS_TYPE_UNSPECIFIED\x10\x00\x12\x1f\n" +
@@ -2502,14 +4201,15 @@ const file_dpi_proto_rawDesc = "" +
 	"\x17BYPASS_TYPE_SNI_MASKING\x10\x02\x12$\n" +
 	" BYPASS_TYPE_PACKET_FRAGMENTATION\x10\x03\x12$\n" +
 	" BYPASS_TYPE_PROTOCOL_OBFUSCATION\x10\x04\x12\"\n" +
-	"\x1eBYPASS_TYPE_TUNNEL_OBFUSCATION\x10\x05*\xcc\x01\n" +
+	"\x1eBYPASS_TYPE_TUNNEL_OBFUSCATION\x10\x05*\xe4\x01\n" +
 	"\fBypassMethod\x12\x1d\n" +
 	"\x19BYPASS_METHOD_UNSPECIFIED\x10\x00\x12\x1d\n" +
 	"\x19BYPASS_METHOD_HTTP_HEADER\x10\x01\x12\x1f\n" +
 	"\x1bBYPASS_METHOD_TLS_HANDSHAKE\x10\x02\x12\x1e\n" +
 	"\x1aBYPASS_METHOD_TCP_FRAGMENT\x10\x03\x12\x1e\n" +
 	"\x1aBYPASS_METHOD_UDP_FRAGMENT\x10\x04\x12\x1d\n" +
-	"\x19BYPASS_METHOD_PROXY_CHAIN\x10\x05*\x97\x01\n" +
+	"\x19BYPASS_METHOD_PROXY_CHAIN\x10\x05\x12\x16\n" +
+	"\x12BYPASS_METHOD_AUTO\x10\x06*\x97\x01\n" +
 	"\fBypassStatus\x12\x1d\n" +
 	"\x19BYPASS_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
 	"\x16BYPASS_STATUS_INACTIVE\x10\x01\x12\x18\n" +
@@ -2530,7 +4230,7 @@ const file_dpi_proto_rawDesc = "" +
 	"\x11RULE_ACTION_BLOCK\x10\x02\x12\x16\n" +
 	"\x12RULE_ACTION_BYPASS\x10\x03\x12\x18\n" +
 	"\x14RULE_ACTION_FRAGMENT\x10\x04\x12\x19\n" +
-	"\x15RULE_ACTION_OBFUSCATE\x10\x052\xc4\b\n" +
+	"\x15RULE_ACTION_OBFUSCATE\x10\x052\xc1\x0e\n" +
 	"\x10DpiBypassService\x121\n" +
 	"\x06Health\x12\x12.dpi.HealthRequest\x1a\x13.dpi.HealthResponse\x12G\n" +
 	"\x12CreateBypassConfig\x12\x1e.dpi.CreateBypassConfigRequest\x1a\x11.dpi.BypassConfig\x12A\n" +
@@ -2543,11 +4243,20 @@ const file_dpi_proto_rawDesc = "" +
 	"StopBypass\x12\x16.dpi.StopBypassRequest\x1a\x17.dpi.StopBypassResponse\x12L\n" +
 	"\x0fGetBypassStatus\x12\x1b.dpi.GetBypassStatusRequest\x1a\x1c.dpi.GetBypassStatusResponse\x12>\n" +
 	"\x0eGetBypassStats\x12\x1a.dpi.GetBypassStatsRequest\x1a\x10.dpi.BypassStats\x12O\n" +
-	"\x10GetBypassHistory\x12\x1c.dpi.GetBypassHistoryRequest\x1a\x1d.dpi.GetBypassHistoryResponse\x12;\n" +
+	"\x10GetBypassHistory\x12\x1c.dpi.GetBypassHistoryRequest\x1a\x1d.dpi.GetBypassHistoryResponse\x12[\n" +
+	"\x14GetCensorshipSignals\x12 .dpi.GetCensorshipSignalsRequest\x1a!.dpi.GetCensorshipSignalsResponse\x12;\n" +
 	"\rAddBypassRule\x12\x19.dpi.AddBypassRuleRequest\x1a\x0f.dpi.BypassRule\x12A\n" +
 	"\x10UpdateBypassRule\x12\x1c.dpi.UpdateBypassRuleRequest\x1a\x0f.dpi.BypassRule\x12O\n" +
 	"\x10DeleteBypassRule\x12\x1c.dpi.DeleteBypassRuleRequest\x1a\x1d.dpi.DeleteBypassRuleResponse\x12L\n" +
-	"\x0fListBypassRules\x12\x1b.dpi.ListBypassRulesRequest\x1a\x1c.dpi.ListBypassRulesResponseB5Z3github.com/par1ram/silence/rpc/dpi-bypass/api/protob\x06proto3"
+	"\x0fListBypassRules\x12\x1b.dpi.ListBypassRulesRequest\x1a\x1c.dpi.ListBypassRulesResponse\x12;\n" +
+	"\rAddBypassUser\x12\x19.dpi.AddBypassUserRequest\x1a\x0f.dpi.BypassUser\x12O\n" +
+	"\x10RevokeBypassUser\x12\x1c.dpi.RevokeBypassUserRequest\x1a\x1d.dpi.RevokeBypassUserResponse\x12L\n" +
+	"\x0fListBypassUsers\x12\x1b.dpi.ListBypassUsersRequest\x1a\x1c.dpi.ListBypassUsersResponse\x128\n" +
+	"\fGetShareLink\x12\x18.dpi.GetShareLinkRequest\x1a\x0e.dpi.ShareLink\x12V\n" +
+	"\x17CreateSubscriptionToken\x12#.dpi.CreateSubscriptionTokenRequest\x1a\x16.dpi.SubscriptionToken\x12d\n" +
+	"\x17RevokeSubscriptionToken\x12#.dpi.RevokeSubscriptionTokenRequest\x1a$.dpi.RevokeSubscriptionTokenResponse\x12a\n" +
+	"\x16ListSubscriptionTokens\x12\".dpi.ListSubscriptionTokensRequest\x1a#.dpi.ListSubscriptionTokensResponse\x12g\n" +
+	"\x18ListSubscriptionAccesses\x12$.dpi.ListSubscriptionAccessesRequest\x1a%.dpi.ListSubscriptionAccessesResponseB5Z3github.com/par1ram/silence/rpc/dpi-bypass/api/protob\x06proto3"
 
 var (
 	file_dpi_proto_rawDescOnce sync.Once
@@ -2561,128 +4270,186 @@ func file_dpi_proto_rawDescGZIP() []byte {
 	return file_dpi_proto_rawDescData
 }
 
-var file_dpi_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_dpi_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_dpi_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_dpi_proto_msgTypes = make([]protoimpl.MessageInfo, 57)
 var file_dpi_proto_goTypes = []any{
-	(BypassType)(0),                    // 0: dpi.BypassType
-	(BypassMethod)(0),                  // 1: dpi.BypassMethod
-	(BypassStatus)(0),                  // 2: dpi.BypassStatus
-	(RuleType)(0),                      // 3: dpi.RuleType
-	(RuleAction)(0),                    // 4: dpi.RuleAction
-	(*HealthRequest)(nil),              // 5: dpi.HealthRequest
-	(*HealthResponse)(nil),             // 6: dpi.HealthResponse
-	(*BypassConfig)(nil),               // 7: dpi.BypassConfig
-	(*CreateBypassConfigRequest)(nil),  // 8: dpi.CreateBypassConfigRequest
-	(*GetBypassConfigRequest)(nil),     // 9: dpi.GetBypassConfigRequest
-	(*ListBypassConfigsRequest)(nil),   // 10: dpi.ListBypassConfigsRequest
-	(*ListBypassConfigsResponse)(nil),  // 11: dpi.ListBypassConfigsResponse
-	(*UpdateBypassConfigRequest)(nil),  // 12: dpi.UpdateBypassConfigRequest
-	(*DeleteBypassConfigRequest)(nil),  // 13: dpi.DeleteBypassConfigRequest
-	(*DeleteBypassConfigResponse)(nil), // 14: dpi.DeleteBypassConfigResponse
-	(*StartBypassRequest)(nil),         // 15: dpi.StartBypassRequest
-	(*StartBypassResponse)(nil),        // 16: dpi.StartBypassResponse
-	(*StopBypassRequest)(nil),          // 17: dpi.StopBypassRequest
-	(*StopBypassResponse)(nil),         // 18: dpi.StopBypassResponse
-	(*GetBypassStatusRequest)(nil),     // 19: dpi.GetBypassStatusRequest
-	(*GetBypassStatusResponse)(nil),    // 20: dpi.GetBypassStatusResponse
-	(*BypassStats)(nil),                // 21: dpi.BypassStats
-	(*GetBypassStatsRequest)(nil),      // 22: dpi.GetBypassStatsRequest
-	(*GetBypassHistoryRequest)(nil),    // 23: dpi.GetBypassHistoryRequest
-	(*GetBypassHistoryResponse)(nil),   // 24: dpi.GetBypassHistoryResponse
-	(*BypassHistoryEntry)(nil),         // 25: dpi.BypassHistoryEntry
-	(*BypassRule)(nil),                 // 26: dpi.BypassRule
-	(*AddBypassRuleRequest)(nil),       // 27: dpi.AddBypassRuleRequest
-	(*UpdateBypassRuleRequest)(nil),    // 28: dpi.UpdateBypassRuleRequest
-	(*DeleteBypassRuleRequest)(nil),    // 29: dpi.DeleteBypassRuleRequest
-	(*DeleteBypassRuleResponse)(nil),   // 30: dpi.DeleteBypassRuleResponse
-	(*ListBypassRulesRequest)(nil),     // 31: dpi.ListBypassRulesRequest
-	(*ListBypassRulesResponse)(nil),    // 32: dpi.ListBypassRulesResponse
-	nil,                                // 33: dpi.BypassConfig.ParametersEntry
-	nil,                                // 34: dpi.CreateBypassConfigRequest.ParametersEntry
-	nil,                                // 35: dpi.UpdateBypassConfigRequest.ParametersEntry
-	nil,                                // 36: dpi.StartBypassRequest.OptionsEntry
-	nil,                                // 37: dpi.BypassRule.ParametersEntry
-	nil,                                // 38: dpi.AddBypassRuleRequest.ParametersEntry
-	nil,                                // 39: dpi.UpdateBypassRuleRequest.ParametersEntry
-	(*timestamppb.Timestamp)(nil),      // 40: google.protobuf.Timestamp
+	(ReloadOutcome)(0),                       // 0: dpi.ReloadOutcome
+	(BypassType)(0),                          // 1: dpi.BypassType
+	(BypassMethod)(0),                        // 2: dpi.BypassMethod
+	(BypassStatus)(0),                        // 3: dpi.BypassStatus
+	(RuleType)(0),                            // 4: dpi.RuleType
+	(RuleAction)(0),                          // 5: dpi.RuleAction
+	(*HealthRequest)(nil),                    // 6: dpi.HealthRequest
+	(*HealthResponse)(nil),                   // 7: dpi.HealthResponse
+	(*BypassConfig)(nil),                     // 8: dpi.BypassConfig
+	(*SessionReload)(nil),                    // 9: dpi.SessionReload
+	(*CreateBypassConfigRequest)(nil),        // 10: dpi.CreateBypassConfigRequest
+	(*GetBypassConfigRequest)(nil),           // 11: dpi.GetBypassConfigRequest
+	(*ListBypassConfigsRequest)(nil),         // 12: dpi.ListBypassConfigsRequest
+	(*ListBypassConfigsResponse)(nil),        // 13: dpi.ListBypassConfigsResponse
+	(*UpdateBypassConfigRequest)(nil),        // 14: dpi.UpdateBypassConfigRequest
+	(*DeleteBypassConfigRequest)(nil),        // 15: dpi.DeleteBypassConfigRequest
+	(*DeleteBypassConfigResponse)(nil),       // 16: dpi.DeleteBypassConfigResponse
+	(*StartBypassRequest)(nil),               // 17: dpi.StartBypassRequest
+	(*StartBypassResponse)(nil),              // 18: dpi.StartBypassResponse
+	(*StopBypassRequest)(nil),                // 19: dpi.StopBypassRequest
+	(*StopBypassResponse)(nil),               // 20: dpi.StopBypassResponse
+	(*GetBypassStatusRequest)(nil),           // 21: dpi.GetBypassStatusRequest
+	(*GetBypassStatusResponse)(nil),          // 22: dpi.GetBypassStatusResponse
+	(*BypassStats)(nil),                      // 23: dpi.BypassStats
+	(*GetBypassStatsRequest)(nil),            // 24: dpi.GetBypassStatsRequest
+	(*GetBypassHistoryRequest)(nil),          // 25: dpi.GetBypassHistoryRequest
+	(*GetBypassHistoryResponse)(nil),         // 26: dpi.GetBypassHistoryResponse
+	(*BypassHistoryEntry)(nil),               // 27: dpi.BypassHistoryEntry
+	(*CensorshipSignalStats)(nil),            // 28: dpi.CensorshipSignalStats
+	(*GetCensorshipSignalsRequest)(nil),      // 29: dpi.GetCensorshipSignalsRequest
+	(*GetCensorshipSignalsResponse)(nil),     // 30: dpi.GetCensorshipSignalsResponse
+	(*BypassRule)(nil),                       // 31: dpi.BypassRule
+	(*AddBypassRuleRequest)(nil),             // 32: dpi.AddBypassRuleRequest
+	(*UpdateBypassRuleRequest)(nil),          // 33: dpi.UpdateBypassRuleRequest
+	(*DeleteBypassRuleRequest)(nil),          // 34: dpi.DeleteBypassRuleRequest
+	(*DeleteBypassRuleResponse)(nil),         // 35: dpi.DeleteBypassRuleResponse
+	(*ListBypassRulesRequest)(nil),           // 36: dpi.ListBypassRulesRequest
+	(*ListBypassRulesResponse)(nil),          // 37: dpi.ListBypassRulesResponse
+	(*BypassUser)(nil),                       // 38: dpi.BypassUser
+	(*UserStats)(nil),                        // 39: dpi.UserStats
+	(*AddBypassUserRequest)(nil),             // 40: dpi.AddBypassUserRequest
+	(*RevokeBypassUserRequest)(nil),          // 41: dpi.RevokeBypassUserRequest
+	(*RevokeBypassUserResponse)(nil),         // 42: dpi.RevokeBypassUserResponse
+	(*ListBypassUsersRequest)(nil),           // 43: dpi.ListBypassUsersRequest
+	(*ListBypassUsersResponse)(nil),          // 44: dpi.ListBypassUsersResponse
+	(*ShareLink)(nil),                        // 45: dpi.ShareLink
+	(*GetShareLinkRequest)(nil),              // 46: dpi.GetShareLinkRequest
+	(*SubscriptionToken)(nil),                // 47: dpi.SubscriptionToken
+	(*CreateSubscriptionTokenRequest)(nil),   // 48: dpi.CreateSubscriptionTokenRequest
+	(*RevokeSubscriptionTokenRequest)(nil),   // 49: dpi.RevokeSubscriptionTokenRequest
+	(*RevokeSubscriptionTokenResponse)(nil),  // 50: dpi.RevokeSubscriptionTokenResponse
+	(*ListSubscriptionTokensRequest)(nil),    // 51: dpi.ListSubscriptionTokensRequest
+	(*ListSubscriptionTokensResponse)(nil),   // 52: dpi.ListSubscriptionTokensResponse
+	(*SubscriptionAccess)(nil),               // 53: dpi.SubscriptionAccess
+	(*ListSubscriptionAccessesRequest)(nil),  // 54: dpi.ListSubscriptionAccessesRequest
+	(*ListSubscriptionAccessesResponse)(nil), // 55: dpi.ListSubscriptionAccessesResponse
+	nil,                                      // 56: dpi.BypassConfig.ParametersEntry
+	nil,                                      // 57: dpi.CreateBypassConfigRequest.ParametersEntry
+	nil,                                      // 58: dpi.UpdateBypassConfigRequest.ParametersEntry
+	nil,                                      // 59: dpi.StartBypassRequest.OptionsEntry
+	nil,                                      // 60: dpi.BypassRule.ParametersEntry
+	nil,                                      // 61: dpi.AddBypassRuleRequest.ParametersEntry
+	nil,                                      // 62: dpi.UpdateBypassRuleRequest.ParametersEntry
+	(*timestamppb.Timestamp)(nil),            // 63: google.protobuf.Timestamp
 }
 var file_dpi_proto_depIdxs = []int32{
-	40, // 0: dpi.HealthResponse.timestamp:type_name -> google.protobuf.Timestamp
-	0,  // 1: dpi.BypassConfig.type:type_name -> dpi.BypassType
-	1,  // 2: dpi.BypassConfig.method:type_name -> dpi.BypassMethod
-	2,  // 3: dpi.BypassConfig.status:type_name -> dpi.BypassStatus
-	33, // 4: dpi.BypassConfig.parameters:type_name -> dpi.BypassConfig.ParametersEntry
-	26, // 5: dpi.BypassConfig.rules:type_name -> dpi.BypassRule
-	40, // 6: dpi.BypassConfig.created_at:type_name -> google.protobuf.Timestamp
-	40, // 7: dpi.BypassConfig.updated_at:type_name -> google.protobuf.Timestamp
-	0,  // 8: dpi.CreateBypassConfigRequest.type:type_name -> dpi.BypassType
-	1,  // 9: dpi.CreateBypassConfigRequest.method:type_name -> dpi.BypassMethod
-	34, // 10: dpi.CreateBypassConfigRequest.parameters:type_name -> dpi.CreateBypassConfigRequest.ParametersEntry
-	0,  // 11: dpi.ListBypassConfigsRequest.type:type_name -> dpi.BypassType
-	2,  // 12: dpi.ListBypassConfigsRequest.status:type_name -> dpi.BypassStatus
-	7,  // 13: dpi.ListBypassConfigsResponse.configs:type_name -> dpi.BypassConfig
-	0,  // 14: dpi.UpdateBypassConfigRequest.type:type_name -> dpi.BypassType
-	1,  // 15: dpi.UpdateBypassConfigRequest.method:type_name -> dpi.BypassMethod
-	35, // 16: dpi.UpdateBypassConfigRequest.parameters:type_name -> dpi.UpdateBypassConfigRequest.ParametersEntry
-	36, // 17: dpi.StartBypassRequest.options:type_name -> dpi.StartBypassRequest.OptionsEntry
-	2,  // 18: dpi.GetBypassStatusResponse.status:type_name -> dpi.BypassStatus
-	40, // 19: dpi.GetBypassStatusResponse.started_at:type_name -> google.protobuf.Timestamp
-	40, // 20: dpi.BypassStats.start_time:type_name -> google.protobuf.Timestamp
-	40, // 21: dpi.BypassStats.end_time:type_name -> google.protobuf.Timestamp
-	40, // 22: dpi.GetBypassHistoryRequest.start_time:type_name -> google.protobuf.Timestamp
-	40, // 23: dpi.GetBypassHistoryRequest.end_time:type_name -> google.protobuf.Timestamp
-	25, // 24: dpi.GetBypassHistoryResponse.entries:type_name -> dpi.BypassHistoryEntry
-	2,  // 25: dpi.BypassHistoryEntry.status:type_name -> dpi.BypassStatus
-	40, // 26: dpi.BypassHistoryEntry.started_at:type_name -> google.protobuf.Timestamp
-	40, // 27: dpi.BypassHistoryEntry.ended_at:type_name -> google.protobuf.Timestamp
-	3,  // 28: dpi.BypassRule.type:type_name -> dpi.RuleType
-	4,  // 29: dpi.BypassRule.action:type_name -> dpi.RuleAction
-	37, // 30: dpi.BypassRule.parameters:type_name -> dpi.BypassRule.ParametersEntry
-	40, // 31: dpi.BypassRule.created_at:type_name -> google.protobuf.Timestamp
-	40, // 32: dpi.BypassRule.updated_at:type_name -> google.protobuf.Timestamp
-	3,  // 33: dpi.AddBypassRuleRequest.type:type_name -> dpi.RuleType
-	4,  // 34: dpi.AddBypassRuleRequest.action:type_name -> dpi.RuleAction
-	38, // 35: dpi.AddBypassRuleRequest.parameters:type_name -> dpi.AddBypassRuleRequest.ParametersEntry
-	3,  // 36: dpi.UpdateBypassRuleRequest.type:type_name -> dpi.RuleType
-	4,  // 37: dpi.UpdateBypassRuleRequest.action:type_name -> dpi.RuleAction
-	39, // 38: dpi.UpdateBypassRuleRequest.parameters:type_name -> dpi.UpdateBypassRuleRequest.ParametersEntry
-	3,  // 39: dpi.ListBypassRulesRequest.type:type_name -> dpi.RuleType
-	26, // 40: dpi.ListBypassRulesResponse.rules:type_name -> dpi.BypassRule
-	5,  // 41: dpi.DpiBypassService.Health:input_type -> dpi.HealthRequest
-	8,  // 42: dpi.DpiBypassService.CreateBypassConfig:input_type -> dpi.CreateBypassConfigRequest
-	9,  // 43: dpi.DpiBypassService.GetBypassConfig:input_type -> dpi.GetBypassConfigRequest
-	10, // 44: dpi.DpiBypassService.ListBypassConfigs:input_type -> dpi.ListBypassConfigsRequest
-	12, // 45: dpi.DpiBypassService.UpdateBypassConfig:input_type -> dpi.UpdateBypassConfigRequest
-	13, // 46: dpi.DpiBypassService.DeleteBypassConfig:input_type -> dpi.DeleteBypassConfigRequest
-	15, // 47: dpi.DpiBypassService.StartBypass:input_type -> dpi.StartBypassRequest
-	17, // 48: dpi.DpiBypassService.StopBypass:input_type -> dpi.StopBypassRequest
-	19, // 49: dpi.DpiBypassService.GetBypassStatus:input_type -> dpi.GetBypassStatusRequest
-	22, // 50: dpi.DpiBypassService.GetBypassStats:input_type -> dpi.GetBypassStatsRequest
-	23, // 51: dpi.DpiBypassService.GetBypassHistory:input_type -> dpi.GetBypassHistoryRequest
-	27, // 52: dpi.DpiBypassService.AddBypassRule:input_type -> dpi.AddBypassRuleRequest
-	28, // 53: dpi.DpiBypassService.UpdateBypassRule:input_type -> dpi.UpdateBypassRuleRequest
-	29, // 54: dpi.DpiBypassService.DeleteBypassRule:input_type -> dpi.DeleteBypassRuleRequest
-	31, // 55: dpi.DpiBypassService.ListBypassRules:input_type -> dpi.ListBypassRulesRequest
-	6,  // 56: dpi.DpiBypassService.Health:output_type -> dpi.HealthResponse
-	7,  // 57: dpi.DpiBypassService.CreateBypassConfig:output_type -> dpi.BypassConfig
-	7,  // 58: dpi.DpiBypassService.GetBypassConfig:output_type -> dpi.BypassConfig
-	11, // 59: dpi.DpiBypassService.ListBypassConfigs:output_type -> dpi.ListBypassConfigsResponse
-	7,  // 60: dpi.DpiBypassService.UpdateBypassConfig:output_type -> dpi.BypassConfig
-	14, // 61: dpi.DpiBypassService.DeleteBypassConfig:output_type -> dpi.DeleteBypassConfigResponse
-	16, // 62: dpi.DpiBypassService.StartBypass:output_type -> dpi.StartBypassResponse
-	18, // 63: dpi.DpiBypassService.StopBypass:output_type -> dpi.StopBypassResponse
-	20, // 64: dpi.DpiBypassService.GetBypassStatus:output_type -> dpi.GetBypassStatusResponse
-	21, // 65: dpi.DpiBypassService.GetBypassStats:output_type -> dpi.BypassStats
-	24, // 66: dpi.DpiBypassService.GetBypassHistory:output_type -> dpi.GetBypassHistoryResponse
-	26, // 67: dpi.DpiBypassService.AddBypassRule:output_type -> dpi.BypassRule
-	26, // 68: dpi.DpiBypassService.UpdateBypassRule:output_type -> dpi.BypassRule
-	30, // 69: dpi.DpiBypassService.DeleteBypassRule:output_type -> dpi.DeleteBypassRuleResponse
-	32, // 70: dpi.DpiBypassService.ListBypassRules:output_type -> dpi.ListBypassRulesResponse
-	56, // [56:71] is the sub-list for method output_type
-	41, // [41:56] is the sub-list for method input_type
-	41, // [41:41] is the sub-list for extension type_name
-	41, // [41:41] is the sub-list for extension extendee
-	0,  // [0:41] is the sub-list for field type_name
+	63, // 0: dpi.HealthResponse.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 1: dpi.BypassConfig.type:type_name -> dpi.BypassType
+	2,  // 2: dpi.BypassConfig.method:type_name -> dpi.BypassMethod
+	3,  // 3: dpi.BypassConfig.status:type_name -> dpi.BypassStatus
+	56, // 4: dpi.BypassConfig.parameters:type_name -> dpi.BypassConfig.ParametersEntry
+	31, // 5: dpi.BypassConfig.rules:type_name -> dpi.BypassRule
+	63, // 6: dpi.BypassConfig.created_at:type_name -> google.protobuf.Timestamp
+	63, // 7: dpi.BypassConfig.updated_at:type_name -> google.protobuf.Timestamp
+	9,  // 8: dpi.BypassConfig.reloads:type_name -> dpi.SessionReload
+	0,  // 9: dpi.SessionReload.outcome:type_name -> dpi.ReloadOutcome
+	1,  // 10: dpi.CreateBypassConfigRequest.type:type_name -> dpi.BypassType
+	2,  // 11: dpi.CreateBypassConfigRequest.method:type_name -> dpi.BypassMethod
+	57, // 12: dpi.CreateBypassConfigRequest.parameters:type_name -> dpi.CreateBypassConfigRequest.ParametersEntry
+	1,  // 13: dpi.ListBypassConfigsRequest.type:type_name -> dpi.BypassType
+	3,  // 14: dpi.ListBypassConfigsRequest.status:type_name -> dpi.BypassStatus
+	8,  // 15: dpi.ListBypassConfigsResponse.configs:type_name -> dpi.BypassConfig
+	1,  // 16: dpi.UpdateBypassConfigRequest.type:type_name -> dpi.BypassType
+	2,  // 17: dpi.UpdateBypassConfigRequest.method:type_name -> dpi.BypassMethod
+	58, // 18: dpi.UpdateBypassConfigRequest.parameters:type_name -> dpi.UpdateBypassConfigRequest.ParametersEntry
+	59, // 19: dpi.StartBypassRequest.options:type_name -> dpi.StartBypassRequest.OptionsEntry
+	3,  // 20: dpi.GetBypassStatusResponse.status:type_name -> dpi.BypassStatus
+	63, // 21: dpi.GetBypassStatusResponse.started_at:type_name -> google.protobuf.Timestamp
+	63, // 22: dpi.BypassStats.start_time:type_name -> google.protobuf.Timestamp
+	63, // 23: dpi.BypassStats.end_time:type_name -> google.protobuf.Timestamp
+	39, // 24: dpi.BypassStats.users:type_name -> dpi.UserStats
+	63, // 25: dpi.GetBypassHistoryRequest.start_time:type_name -> google.protobuf.Timestamp
+	63, // 26: dpi.GetBypassHistoryRequest.end_time:type_name -> google.protobuf.Timestamp
+	27, // 27: dpi.GetBypassHistoryResponse.entries:type_name -> dpi.BypassHistoryEntry
+	3,  // 28: dpi.BypassHistoryEntry.status:type_name -> dpi.BypassStatus
+	63, // 29: dpi.BypassHistoryEntry.started_at:type_name -> google.protobuf.Timestamp
+	63, // 30: dpi.BypassHistoryEntry.ended_at:type_name -> google.protobuf.Timestamp
+	63, // 31: dpi.CensorshipSignalStats.window_start:type_name -> google.protobuf.Timestamp
+	63, // 32: dpi.CensorshipSignalStats.window_end:type_name -> google.protobuf.Timestamp
+	63, // 33: dpi.GetCensorshipSignalsRequest.start_time:type_name -> google.protobuf.Timestamp
+	63, // 34: dpi.GetCensorshipSignalsRequest.end_time:type_name -> google.protobuf.Timestamp
+	28, // 35: dpi.GetCensorshipSignalsResponse.signals:type_name -> dpi.CensorshipSignalStats
+	4,  // 36: dpi.BypassRule.type:type_name -> dpi.RuleType
+	5,  // 37: dpi.BypassRule.action:type_name -> dpi.RuleAction
+	60, // 38: dpi.BypassRule.parameters:type_name -> dpi.BypassRule.ParametersEntry
+	63, // 39: dpi.BypassRule.created_at:type_name -> google.protobuf.Timestamp
+	63, // 40: dpi.BypassRule.updated_at:type_name -> google.protobuf.Timestamp
+	4,  // 41: dpi.AddBypassRuleRequest.type:type_name -> dpi.RuleType
+	5,  // 42: dpi.AddBypassRuleRequest.action:type_name -> dpi.RuleAction
+	61, // 43: dpi.AddBypassRuleRequest.parameters:type_name -> dpi.AddBypassRuleRequest.ParametersEntry
+	4,  // 44: dpi.UpdateBypassRuleRequest.type:type_name -> dpi.RuleType
+	5,  // 45: dpi.UpdateBypassRuleRequest.action:type_name -> dpi.RuleAction
+	62, // 46: dpi.UpdateBypassRuleRequest.parameters:type_name -> dpi.UpdateBypassRuleRequest.ParametersEntry
+	4,  // 47: dpi.ListBypassRulesRequest.type:type_name -> dpi.RuleType
+	31, // 48: dpi.ListBypassRulesResponse.rules:type_name -> dpi.BypassRule
+	63, // 49: dpi.BypassUser.created_at:type_name -> google.protobuf.Timestamp
+	63, // 50: dpi.BypassUser.updated_at:type_name -> google.protobuf.Timestamp
+	63, // 51: dpi.UserStats.last_activity:type_name -> google.protobuf.Timestamp
+	38, // 52: dpi.ListBypassUsersResponse.users:type_name -> dpi.BypassUser
+	63, // 53: dpi.SubscriptionToken.created_at:type_name -> google.protobuf.Timestamp
+	63, // 54: dpi.SubscriptionToken.last_accessed_at:type_name -> google.protobuf.Timestamp
+	47, // 55: dpi.ListSubscriptionTokensResponse.tokens:type_name -> dpi.SubscriptionToken
+	63, // 56: dpi.SubscriptionAccess.accessed_at:type_name -> google.protobuf.Timestamp
+	53, // 57: dpi.ListSubscriptionAccessesResponse.accesses:type_name -> dpi.SubscriptionAccess
+	6,  // 58: dpi.DpiBypassService.Health:input_type -> dpi.HealthRequest
+	10, // 59: dpi.DpiBypassService.CreateBypassConfig:input_type -> dpi.CreateBypassConfigRequest
+	11, // 60: dpi.DpiBypassService.GetBypassConfig:input_type -> dpi.GetBypassConfigRequest
+	12, // 61: dpi.DpiBypassService.ListBypassConfigs:input_type -> dpi.ListBypassConfigsRequest
+	14, // 62: dpi.DpiBypassService.UpdateBypassConfig:input_type -> dpi.UpdateBypassConfigRequest
+	15, // 63: dpi.DpiBypassService.DeleteBypassConfig:input_type -> dpi.DeleteBypassConfigRequest
+	17, // 64: dpi.DpiBypassService.StartBypass:input_type -> dpi.StartBypassRequest
+	19, // 65: dpi.DpiBypassService.StopBypass:input_type -> dpi.StopBypassRequest
+	21, // 66: dpi.DpiBypassService.GetBypassStatus:input_type -> dpi.GetBypassStatusRequest
+	24, // 67: dpi.DpiBypassService.GetBypassStats:input_type -> dpi.GetBypassStatsRequest
+	25, // 68: dpi.DpiBypassService.GetBypassHistory:input_type -> dpi.GetBypassHistoryRequest
+	29, // 69: dpi.DpiBypassService.GetCensorshipSignals:input_type -> dpi.GetCensorshipSignalsRequest
+	32, // 70: dpi.DpiBypassService.AddBypassRule:input_type -> dpi.AddBypassRuleRequest
+	33, // 71: dpi.DpiBypassService.UpdateBypassRule:input_type -> dpi.UpdateBypassRuleRequest
+	34, // 72: dpi.DpiBypassService.DeleteBypassRule:input_type -> dpi.DeleteBypassRuleRequest
+	36, // 73: dpi.DpiBypassService.ListBypassRules:input_type -> dpi.ListBypassRulesRequest
+	40, // 74: dpi.DpiBypassService.AddBypassUser:input_type -> dpi.AddBypassUserRequest
+	41, // 75: dpi.DpiBypassService.RevokeBypassUser:input_type -> dpi.RevokeBypassUserRequest
+	43, // 76: dpi.DpiBypassService.ListBypassUsers:input_type -> dpi.ListBypassUsersRequest
+	46, // 77: dpi.DpiBypassService.GetShareLink:input_type -> dpi.GetShareLinkRequest
+	48, // 78: dpi.DpiBypassService.CreateSubscriptionToken:input_type -> dpi.CreateSubscriptionTokenRequest
+	49, // 79: dpi.DpiBypassService.RevokeSubscriptionToken:input_type -> dpi.RevokeSubscriptionTokenRequest
+	51, // 80: dpi.DpiBypassService.ListSubscriptionTokens:input_type -> dpi.ListSubscriptionTokensRequest
+	54, // 81: dpi.DpiBypassService.ListSubscriptionAccesses:input_type -> dpi.ListSubscriptionAccessesRequest
+	7,  // 82: dpi.DpiBypassService.Health:output_type -> dpi.HealthResponse
+	8,  // 83: dpi.DpiBypassService.CreateBypassConfig:output_type -> dpi.BypassConfig
+	8,  // 84: dpi.DpiBypassService.GetBypassConfig:output_type -> dpi.BypassConfig
+	13, // 85: dpi.DpiBypassService.ListBypassConfigs:output_type -> dpi.ListBypassConfigsResponse
+	8,  // 86: dpi.DpiBypassService.UpdateBypassConfig:output_type -> dpi.BypassConfig
+	16, // 87: dpi.DpiBypassService.DeleteBypassConfig:output_type -> dpi.DeleteBypassConfigResponse
+	18, // 88: dpi.DpiBypassService.StartBypass:output_type -> dpi.StartBypassResponse
+	20, // 89: dpi.DpiBypassService.StopBypass:output_type -> dpi.StopBypassResponse
+	22, // 90: dpi.DpiBypassService.GetBypassStatus:output_type -> dpi.GetBypassStatusResponse
+	23, // 91: dpi.DpiBypassService.GetBypassStats:output_type -> dpi.BypassStats
+	26, // 92: dpi.DpiBypassService.GetBypassHistory:output_type -> dpi.GetBypassHistoryResponse
+	30, // 93: dpi.DpiBypassService.GetCensorshipSignals:output_type -> dpi.GetCensorshipSignalsResponse
+	31, // 94: dpi.DpiBypassService.AddBypassRule:output_type -> dpi.BypassRule
+	31, // 95: dpi.DpiBypassService.UpdateBypassRule:output_type -> dpi.BypassRule
+	35, // 96: dpi.DpiBypassService.DeleteBypassRule:output_type -> dpi.DeleteBypassRuleResponse
+	37, // 97: dpi.DpiBypassService.ListBypassRules:output_type -> dpi.ListBypassRulesResponse
+	38, // 98: dpi.DpiBypassService.AddBypassUser:output_type -> dpi.BypassUser
+	42, // 99: dpi.DpiBypassService.RevokeBypassUser:output_type -> dpi.RevokeBypassUserResponse
+	44, // 100: dpi.DpiBypassService.ListBypassUsers:output_type -> dpi.ListBypassUsersResponse
+	45, // 101: dpi.DpiBypassService.GetShareLink:output_type -> dpi.ShareLink
+	47, // 102: dpi.DpiBypassService.CreateSubscriptionToken:output_type -> dpi.SubscriptionToken
+	50, // 103: dpi.DpiBypassService.RevokeSubscriptionToken:output_type -> dpi.RevokeSubscriptionTokenResponse
+	52, // 104: dpi.DpiBypassService.ListSubscriptionTokens:output_type -> dpi.ListSubscriptionTokensResponse
+	55, // 105: dpi.DpiBypassService.ListSubscriptionAccesses:output_type -> dpi.ListSubscriptionAccessesResponse
+	82, // [82:106] is the sub-list for method output_type
+	58, // [58:82] is the sub-list for method input_type
+	58, // [58:58] is the sub-list for extension type_name
+	58, // [58:58] is the sub-list for extension extendee
+	0,  // [0:58] is the sub-list for field type_name
 }
 
 func init() { file_dpi_proto_init() }
@@ -2695,8 +4462,8 @@ func file_dpi_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dpi_proto_rawDesc), len(file_dpi_proto_rawDesc)),
-			NumEnums:      5,
-			NumMessages:   35,
+			NumEnums:      6,
+			NumMessages:   57,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,5 @@
+package main
+
+import "github.com/par1ram/silence/rpc/dpi-bypass/internal/pt"
+
+func main() { pt.Run() }
@@ -0,0 +1,138 @@
+package bypass
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// aeadChunkSize размер длины блока потока AEAD
+const aeadChunkLengthSize = 2
+
+// aeadWriter шифрует поток блоками: зашифрованная длина (uint16 big-endian),
+// затем зашифрованные данные. Nonce - счетчик little-endian, который
+// увеличивается после каждого шифрования (SIP022, SIP004).
+type aeadWriter struct {
+	w          io.Writer
+	aead       cipher.AEAD
+	nonce      []byte
+	maxPayload int
+}
+
+func newAEADWriter(w io.Writer, aead cipher.AEAD, maxPayload int) *aeadWriter {
+	return &aeadWriter{w: w, aead: aead, nonce: make([]byte, aead.NonceSize()), maxPayload: maxPayload}
+}
+
+// seal добавляет к dst зашифрованный блок и увеличивает nonce
+func (w *aeadWriter) seal(dst, plaintext []byte) []byte {
+	dst = w.aead.Seal(dst, w.nonce, plaintext, nil)
+	incrementNonce(w.nonce)
+	return dst
+}
+
+// appendChunks добавляет к dst блоки длины и данных для p
+func (w *aeadWriter) appendChunks(dst, p []byte) []byte {
+	for len(p) > 0 {
+		size := min(len(p), w.maxPayload)
+		dst = w.seal(dst, binary.BigEndian.AppendUint16(nil, uint16(size)))
+		dst = w.seal(dst, p[:size])
+		p = p[size:]
+	}
+	return dst
+}
+
+func (w *aeadWriter) Write(p []byte) (int, error) {
+	if _, err := w.w.Write(w.appendChunks(nil, p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// aeadReader расшифровывает поток, записанный aeadWriter
+type aeadReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	nonce   []byte
+	pending []byte
+}
+
+func newAEADReader(r io.Reader, aead cipher.AEAD) *aeadReader {
+	return &aeadReader{r: r, aead: aead, nonce: make([]byte, aead.NonceSize())}
+}
+
+// open читает и расшифровывает блок открытого размера size
+func (r *aeadReader) open(size int) ([]byte, error) {
+	buffer := make([]byte, size+r.aead.Overhead())
+	if _, err := io.ReadFull(r.r, buffer); err != nil {
+		return nil, err
+	}
+	plaintext, err := r.aead.Open(buffer[:0], r.nonce, buffer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt chunk: %w", err)
+	}
+	incrementNonce(r.nonce)
+	return plaintext, nil
+}
+
+func (r *aeadReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		length, err := r.open(aeadChunkLengthSize)
+		if err != nil {
+			return 0, err
+		}
+		if r.pending, err = r.open(int(binary.BigEndian.Uint16(length))); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// incrementNonce увеличивает nonce как число little-endian
+func incrementNonce(nonce []byte) {
+	for i := range nonce {
+		nonce[i]++
+		if nonce[i] != 0 {
+			return
+		}
+	}
+}
+
+// replayCache запоминает соли и nonce рукопожатий на время ttl, чтобы
+// сервер отклонял повторно отправленные запросы
+type replayCache struct {
+	ttl     time.Duration
+	seen    map[string]time.Time
+	cleaned time.Time
+	mutex   sync.Mutex
+}
+
+func newReplayCache(ttl time.Duration) *replayCache {
+	return &replayCache{ttl: ttl, seen: make(map[string]time.Time), cleaned: time.Now()}
+}
+
+// Add запоминает value; false - значение уже встречалось в пределах ttl
+func (c *replayCache) Add(value []byte) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.cleaned) > c.ttl {
+		for key, expires := range c.seen {
+			if now.After(expires) {
+				delete(c.seen, key)
+			}
+		}
+		c.cleaned = now
+	}
+
+	if expires, ok := c.seen[string(value)]; ok && now.Before(expires) {
+		return false
+	}
+	c.seen[string(value)] = now.Add(c.ttl)
+	return true
+}
@@ -0,0 +1,547 @@
+package bypass
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
+	"go.uber.org/zap"
+)
+
+// defaultAutoCandidates методы, проверяемые по умолчанию
+var defaultAutoCandidates = []domain.BypassMethod{
+	domain.BypassMethodTLSHandshake,
+	domain.BypassMethodShadowsocks,
+	domain.BypassMethodV2Ray,
+	domain.BypassMethodObfs4,
+}
+
+// AutoAdapter проверяет методы-кандидаты на цели, запускает лучший
+// и переключается на следующий при повторяющихся сбоях
+type AutoAdapter struct {
+	running  map[string]*autoConnection
+	mutex    sync.RWMutex
+	logger   *zap.Logger
+	factory  *AdapterFactory
+	outcomes OutcomeStore
+}
+
+type autoConnection struct {
+	config  *domain.BypassConfig
+	options *autoOptions
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	// Состояние выбора, защищено mutex
+	mutex    sync.Mutex
+	ranking  []ProbeResult
+	current  int
+	adapter  ports.BypassAdapter
+	failures int
+}
+
+// autoOptions параметры автоматического выбора
+type autoOptions struct {
+	candidates        []domain.BypassMethod
+	localPort         string
+	target            probeTarget
+	failoverThreshold int
+	monitorInterval   time.Duration
+}
+
+// NewAutoAdapter создает адаптер автоматического выбора метода
+func NewAutoAdapter(logger *zap.Logger, outcomes OutcomeStore) *AutoAdapter {
+	return &AutoAdapter{
+		running:  make(map[string]*autoConnection),
+		logger:   logger,
+		factory:  NewAdapterFactory(logger),
+		outcomes: outcomes,
+	}
+}
+
+// observeSignals подключает монитор сигналов цензуры к адаптерам кандидатов
+func (a *AutoAdapter) observeSignals(monitor *SignalMonitor) {
+	a.factory.signals = monitor
+}
+
+// Start проверяет кандидатов и запускает лучший рабочий метод
+func (a *AutoAdapter) Start(config *domain.BypassConfig) error {
+	options, err := parseAutoOptions(config.Parameters)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &autoConnection{
+		config:  config,
+		options: options,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	// Резервируем ID, проверка кандидатов идет без блокировки адаптера
+	a.mutex.Lock()
+	if _, exists := a.running[config.ID]; exists {
+		a.mutex.Unlock()
+		cancel()
+		return fmt.Errorf("auto connection already running: %s", config.ID)
+	}
+	a.running[config.ID] = conn
+	a.mutex.Unlock()
+
+	ranking := a.probeCandidates(conn)
+	conn.mutex.Lock()
+	conn.ranking = ranking
+	conn.mutex.Unlock()
+
+	err = ctx.Err()
+	if err == nil {
+		err = a.startFrom(conn, 0)
+	}
+	if err != nil {
+		cancel()
+		a.mutex.Lock()
+		if a.running[config.ID] == conn {
+			delete(a.running, config.ID)
+		}
+		a.mutex.Unlock()
+		close(conn.done)
+		return err
+	}
+
+	if options.monitorInterval > 0 {
+		go a.monitor(conn)
+	} else {
+		close(conn.done)
+	}
+
+	return nil
+}
+
+// parseAutoOptions разбирает параметры автоматического выбора
+func parseAutoOptions(params map[string]string) (*autoOptions, error) {
+	options := &autoOptions{
+		candidates:        defaultAutoCandidates,
+		localPort:         params["local_port"],
+		failoverThreshold: 3,
+		monitorInterval:   30 * time.Second,
+		target: probeTarget{
+			Host:     params["target_host"],
+			Port:     params["target_port"],
+			Path:     params["probe_path"],
+			Timeout:  5 * time.Second,
+			MaxBytes: 64 * 1024,
+		},
+	}
+	if options.localPort == "" {
+		options.localPort = "1080"
+	}
+
+	if value := params["candidates"]; value != "" {
+		options.candidates = nil
+		for _, item := range splitList(value) {
+			method := domain.BypassMethod(item)
+			if method == domain.BypassMethodAuto {
+				return nil, fmt.Errorf("auto cannot be a candidate of itself")
+			}
+			options.candidates = append(options.candidates, method)
+		}
+	}
+	if len(options.candidates) == 0 {
+		return nil, fmt.Errorf("no candidate methods")
+	}
+
+	// Цель по умолчанию совпадает с удаленным сервером
+	if options.target.Host == "" {
+		options.target.Host = params["remote_host"]
+	}
+	if options.target.Port == "" {
+		options.target.Port = params["remote_port"]
+	}
+	if options.target.Host == "" {
+		return nil, fmt.Errorf("target_host is required")
+	}
+	if options.target.Port == "" {
+		options.target.Port = "443"
+	}
+	if options.target.Path == "" {
+		options.target.Path = "/"
+	}
+
+	// Кандидаты наследуют inbound, поэтому проверка идет тем же протоколом
+	inbound, err := parseInboundConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	options.target.Inbound = inbound
+
+	options.target.TLS = options.target.Port == "443"
+	if value := params["probe_tls"]; value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe_tls: %s", value)
+		}
+		options.target.TLS = enabled
+	}
+	if value := params["probe_insecure_skip_verify"]; value != "" {
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe_insecure_skip_verify: %s", value)
+		}
+		options.target.InsecureSkipVerify = skip
+	}
+
+	intParams := []struct {
+		name  string
+		apply func(int)
+	}{
+		{"probe_timeout_ms", func(v int) { options.target.Timeout = time.Duration(v) * time.Millisecond }},
+		{"probe_max_bytes", func(v int) { options.target.MaxBytes = int64(v) }},
+		{"failover_threshold", func(v int) { options.failoverThreshold = v }},
+		{"monitor_interval_ms", func(v int) { options.monitorInterval = time.Duration(v) * time.Millisecond }},
+	}
+	for _, param := range intParams {
+		value := params[param.name]
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || (parsed == 0 && param.name != "monitor_interval_ms") {
+			return nil, fmt.Errorf("invalid %s: %s", param.name, value)
+		}
+		param.apply(parsed)
+	}
+
+	return options, nil
+}
+
+// candidateConfig собирает конфигурацию кандидата: общие параметры
+// переопределяются параметрами с префиксом метода ("shadowsocks.remote_port")
+func candidateConfig(config *domain.BypassConfig, method domain.BypassMethod, id, localPort string) *domain.BypassConfig {
+	prefix := string(method) + "."
+	params := make(map[string]string, len(config.Parameters))
+	for key, value := range config.Parameters {
+		if !strings.Contains(key, ".") {
+			params[key] = value
+		}
+	}
+	for key, value := range config.Parameters {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			params[name] = value
+		}
+	}
+	params["local_port"] = localPort
+
+	candidate := *config
+	candidate.ID = id
+	candidate.Method = method
+	candidate.Parameters = params
+	return &candidate
+}
+
+// probeCandidates параллельно проверяет кандидатов на временных портах и ранжирует их
+func (a *AutoAdapter) probeCandidates(conn *autoConnection) []ProbeResult {
+	results := make([]ProbeResult, len(conn.options.candidates))
+
+	var wg sync.WaitGroup
+	for i, method := range conn.options.candidates {
+		wg.Add(1)
+		go func(i int, method domain.BypassMethod) {
+			defer wg.Done()
+			results[i] = a.probeCandidate(conn, method)
+		}(i, method)
+	}
+	wg.Wait()
+
+	ranked := rankProbes(results)
+	for _, result := range ranked {
+		a.logger.Info("bypass method probed",
+			zap.String("id", conn.config.ID),
+			zap.String("method", string(result.Method)),
+			zap.Bool("success", result.Success),
+			zap.String("failure", string(result.Failure)),
+			zap.Duration("handshake", result.HandshakeTime),
+			zap.Float64("throughput", result.Throughput),
+			zap.Float64("score", result.Score))
+	}
+	return ranked
+}
+
+// probeCandidate запускает кандидата на свободном порту и проверяет путь до цели
+func (a *AutoAdapter) probeCandidate(conn *autoConnection, method domain.BypassMethod) ProbeResult {
+	target := conn.options.target
+	result := ProbeResult{Method: method}
+
+	port, err := freeLocalPort()
+	if err != nil {
+		result = result.fail(ProbeFailureStart, err)
+	} else if adapter, err := a.factory.CreateAdapter(method); err != nil {
+		result = result.fail(ProbeFailureStart, err)
+	} else {
+		probeID := fmt.Sprintf("%s/probe/%s", conn.config.ID, method)
+		if err := adapter.Start(candidateConfig(conn.config, method, probeID, port)); err != nil {
+			result = result.fail(ProbeFailureStart, err)
+		} else {
+			result = probeThrough(conn.ctx, net.JoinHostPort("127.0.0.1", port), target)
+			result.Method = method
+			_ = adapter.Stop(probeID)
+		}
+	}
+
+	a.recordOutcome(target.Key(), result)
+	result.Score = scoreProbe(result, a.outcomes.Get(target.Key(), method))
+	return result
+}
+
+// recordOutcome сохраняет результат проверки для следующих ранжирований
+func (a *AutoAdapter) recordOutcome(target string, result ProbeResult) {
+	a.outcomes.Record(target, result.Method, MethodOutcome{
+		Success:    result.Success,
+		Failure:    result.Failure,
+		Latency:    result.HandshakeTime,
+		Throughput: result.Throughput,
+		Time:       time.Now(),
+	})
+}
+
+// freeLocalPort возвращает свободный локальный порт
+func freeLocalPort() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer listener.Close()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	return port, err
+}
+
+// startFrom запускает первый рабочий метод рейтинга, начиная с позиции from
+func (a *AutoAdapter) startFrom(conn *autoConnection, from int) error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	var lastErr error
+	for i := from; i < len(conn.ranking); i++ {
+		result := conn.ranking[i]
+		if !result.Success {
+			continue
+		}
+
+		adapter, err := a.factory.CreateAdapter(result.Method)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := adapter.Start(candidateConfig(conn.config, result.Method, conn.config.ID, conn.options.localPort)); err != nil {
+			lastErr = err
+			continue
+		}
+
+		conn.adapter = adapter
+		conn.current = i
+		conn.failures = 0
+
+		a.logger.Info("bypass method selected",
+			zap.String("id", conn.config.ID),
+			zap.String("method", string(result.Method)),
+			zap.Float64("score", result.Score))
+		return nil
+	}
+
+	if lastErr != nil {
+		return fmt.Errorf("no working bypass method: %w", lastErr)
+	}
+	return fmt.Errorf("no working bypass method for %s", conn.options.target.Key())
+}
+
+// monitor периодически проверяет активный метод и переключается при сбоях
+func (a *AutoAdapter) monitor(conn *autoConnection) {
+	defer close(conn.done)
+
+	ticker := time.NewTicker(conn.options.monitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-conn.ctx.Done():
+			return
+		case <-ticker.C:
+			a.checkActive(conn)
+		}
+	}
+}
+
+// checkActive проверяет активный метод через его локальный порт
+func (a *AutoAdapter) checkActive(conn *autoConnection) {
+	conn.mutex.Lock()
+	active := conn.adapter
+	var method domain.BypassMethod
+	if active != nil {
+		method = conn.ranking[conn.current].Method
+	}
+	conn.mutex.Unlock()
+
+	if active == nil {
+		// Все методы отказали ранее: проверяем кандидатов заново
+		a.reprobe(conn)
+		return
+	}
+
+	target := conn.options.target
+	result := probeThrough(conn.ctx, net.JoinHostPort("127.0.0.1", conn.options.localPort), target)
+	result.Method = method
+	if conn.ctx.Err() != nil {
+		return
+	}
+	a.recordOutcome(target.Key(), result)
+
+	conn.mutex.Lock()
+	if result.Success {
+		conn.failures = 0
+		conn.mutex.Unlock()
+		return
+	}
+	conn.failures++
+	failures := conn.failures
+	conn.mutex.Unlock()
+
+	a.logger.Warn("active bypass method failed",
+		zap.String("id", conn.config.ID),
+		zap.String("method", string(method)),
+		zap.String("failure", string(result.Failure)),
+		zap.Int("consecutive_failures", failures))
+
+	if failures >= conn.options.failoverThreshold {
+		a.failover(conn)
+	}
+}
+
+// failover останавливает активный метод и запускает следующий по рейтингу
+func (a *AutoAdapter) failover(conn *autoConnection) {
+	conn.mutex.Lock()
+	previous := conn.ranking[conn.current].Method
+	if conn.adapter != nil {
+		_ = conn.adapter.Stop(conn.config.ID)
+		conn.adapter = nil
+	}
+	next := conn.current + 1
+	conn.mutex.Unlock()
+
+	if err := a.startFrom(conn, next); err == nil {
+		a.logger.Warn("bypass method failed over",
+			zap.String("id", conn.config.ID),
+			zap.String("from", string(previous)))
+		return
+	}
+
+	// Рейтинг исчерпан: проверяем кандидатов заново с учетом новой истории
+	a.reprobe(conn)
+}
+
+// reprobe заново ранжирует кандидатов и запускает лучший
+func (a *AutoAdapter) reprobe(conn *autoConnection) {
+	ranking := a.probeCandidates(conn)
+	if conn.ctx.Err() != nil {
+		return
+	}
+
+	conn.mutex.Lock()
+	conn.ranking = ranking
+	conn.mutex.Unlock()
+
+	if err := a.startFrom(conn, 0); err != nil {
+		a.logger.Error("no working bypass method", zap.Error(err), zap.String("id", conn.config.ID))
+	}
+}
+
+// Stop останавливает мониторинг и активный метод
+func (a *AutoAdapter) Stop(id string) error {
+	a.mutex.Lock()
+	conn, exists := a.running[id]
+	if !exists {
+		a.mutex.Unlock()
+		return fmt.Errorf("auto connection not found: %s", id)
+	}
+	delete(a.running, id)
+	a.mutex.Unlock()
+
+	conn.cancel()
+	<-conn.done
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	if conn.adapter != nil {
+		if err := conn.adapter.Stop(id); err != nil {
+			a.logger.Error("failed to stop active method", zap.Error(err), zap.String("id", id))
+		}
+		conn.adapter = nil
+	}
+
+	a.logger.Info("auto bypass stopped", zap.String("id", id))
+	return nil
+}
+
+// GetStats возвращает статистику активного метода
+func (a *AutoAdapter) GetStats(id string) (*domain.BypassStats, error) {
+	a.mutex.RLock()
+	conn, exists := a.running[id]
+	a.mutex.RUnlock()
+	if !exists {
+		return nil, nil
+	}
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	if conn.adapter == nil {
+		return nil, nil
+	}
+	return conn.adapter.GetStats(id)
+}
+
+// IsRunning проверяет, запущен ли автоматический выбор
+func (a *AutoAdapter) IsRunning(id string) bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	_, exists := a.running[id]
+	return exists
+}
+
+// ActiveMethod возвращает текущий активный метод
+func (a *AutoAdapter) ActiveMethod(id string) (domain.BypassMethod, error) {
+	a.mutex.RLock()
+	conn, exists := a.running[id]
+	a.mutex.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("auto connection not found: %s", id)
+	}
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	if conn.adapter == nil {
+		return "", nil
+	}
+	return conn.ranking[conn.current].Method, nil
+}
+
+// Ranking возвращает последний рейтинг методов
+func (a *AutoAdapter) Ranking(id string) ([]ProbeResult, error) {
+	a.mutex.RLock()
+	conn, exists := a.running[id]
+	a.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("auto connection not found: %s", id)
+	}
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	return append([]ProbeResult(nil), conn.ranking...), nil
+}
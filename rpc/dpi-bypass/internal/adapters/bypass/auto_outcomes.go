@@ -0,0 +1,99 @@
+package bypass
+
+import (
+	"sync"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+)
+
+// MethodOutcome результат использования метода для цели
+type MethodOutcome struct {
+	Success    bool          `json:"success"`
+	Failure    ProbeFailure  `json:"failure"`
+	Latency    time.Duration `json:"latency"`
+	Throughput float64       `json:"throughput"` // байт в секунду
+	Time       time.Time     `json:"time"`
+}
+
+// MethodHistory накопленная история метода для цели
+type MethodHistory struct {
+	Successes     int          `json:"successes"`
+	Failures      int          `json:"failures"`
+	LastFailure   ProbeFailure `json:"last_failure"`
+	LastSuccess   time.Time    `json:"last_success"`
+	AvgThroughput float64      `json:"avg_throughput"`
+}
+
+// SuccessRate доля успехов со сглаживанием Лапласа: без истории 0.5
+func (h MethodHistory) SuccessRate() float64 {
+	return float64(h.Successes+1) / float64(h.Successes+h.Failures+2)
+}
+
+// OutcomeStore хранит результаты методов, чтобы улучшать следующее ранжирование
+type OutcomeStore interface {
+	Record(target string, method domain.BypassMethod, outcome MethodOutcome)
+	Get(target string, method domain.BypassMethod) MethodHistory
+}
+
+// outcomeWindow количество последних результатов, учитываемых в истории
+const outcomeWindow = 20
+
+// MemoryOutcomeStore хранит последние результаты в памяти
+type MemoryOutcomeStore struct {
+	outcomes map[outcomeKey][]MethodOutcome
+	mutex    sync.RWMutex
+}
+
+type outcomeKey struct {
+	target string
+	method domain.BypassMethod
+}
+
+// NewMemoryOutcomeStore создает хранилище результатов в памяти
+func NewMemoryOutcomeStore() *MemoryOutcomeStore {
+	return &MemoryOutcomeStore{
+		outcomes: make(map[outcomeKey][]MethodOutcome),
+	}
+}
+
+// Record добавляет результат, старые результаты за пределами окна отбрасываются
+func (s *MemoryOutcomeStore) Record(target string, method domain.BypassMethod, outcome MethodOutcome) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if outcome.Time.IsZero() {
+		outcome.Time = time.Now()
+	}
+
+	key := outcomeKey{target: target, method: method}
+	outcomes := append(s.outcomes[key], outcome)
+	if len(outcomes) > outcomeWindow {
+		outcomes = outcomes[len(outcomes)-outcomeWindow:]
+	}
+	s.outcomes[key] = outcomes
+}
+
+// Get возвращает историю метода для цели
+func (s *MemoryOutcomeStore) Get(target string, method domain.BypassMethod) MethodHistory {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var history MethodHistory
+	var throughput float64
+	for _, outcome := range s.outcomes[outcomeKey{target: target, method: method}] {
+		if outcome.Success {
+			history.Successes++
+			history.LastSuccess = outcome.Time
+			throughput += outcome.Throughput
+		} else {
+			history.Failures++
+			history.LastFailure = outcome.Failure
+		}
+	}
+	if history.Successes > 0 {
+		history.AvgThroughput = throughput / float64(history.Successes)
+	}
+
+	return history
+}
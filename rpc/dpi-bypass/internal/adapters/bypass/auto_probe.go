@@ -0,0 +1,196 @@
+package bypass
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+)
+
+// ProbeFailure причина неудачной проверки метода
+type ProbeFailure string
+
+const (
+	ProbeFailureNone     ProbeFailure = ""
+	ProbeFailureStart    ProbeFailure = "start"    // адаптер не запустился
+	ProbeFailureConnect  ProbeFailure = "connect"  // нет соединения с локальным прокси
+	ProbeFailureReset    ProbeFailure = "reset"    // соединение сброшено или закрыто до ответа
+	ProbeFailureTimeout  ProbeFailure = "timeout"  // нет ответа за отведенное время
+	ProbeFailureTLS      ProbeFailure = "tls"      // рукопожатие TLS не завершилось
+	ProbeFailureResponse ProbeFailure = "response" // ответ не похож на HTTP
+)
+
+// ProbeResult результат проверки одного метода
+type ProbeResult struct {
+	Method        domain.BypassMethod `json:"method"`
+	Success       bool                `json:"success"`
+	Failure       ProbeFailure        `json:"failure"`
+	Error         string              `json:"error"`
+	ConnectTime   time.Duration       `json:"connect_time"`
+	HandshakeTime time.Duration       `json:"handshake_time"`
+	Throughput    float64             `json:"throughput"` // байт в секунду
+	BytesRead     int64               `json:"bytes_read"`
+	Score         float64             `json:"score"`
+}
+
+// probeTarget цель, на которой проверяются методы
+type probeTarget struct {
+	Host               string
+	Port               string
+	TLS                bool
+	Path               string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+	MaxBytes           int64
+	// Inbound протокол локального прокси, через который идет проверка
+	Inbound inboundConfig
+}
+
+// Key идентификатор цели в истории результатов
+func (t probeTarget) Key() string {
+	return net.JoinHostPort(t.Host, t.Port)
+}
+
+// probeThrough проверяет путь через локальный прокси: соединение, рукопожатие
+// TLS и HTTP запрос к цели с измерением скорости чтения ответа
+func probeThrough(ctx context.Context, proxyAddr string, target probeTarget) ProbeResult {
+	var result ProbeResult
+
+	ctx, cancel := context.WithTimeout(ctx, target.Timeout)
+	defer cancel()
+
+	started := time.Now()
+	rawConn, err := (&net.Dialer{}).DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return result.fail(ProbeFailureConnect, err)
+	}
+	defer rawConn.Close()
+	result.ConnectTime = time.Since(started)
+
+	deadline, _ := ctx.Deadline()
+	if err := rawConn.SetDeadline(deadline); err != nil {
+		return result.fail(ProbeFailureConnect, err)
+	}
+
+	var conn net.Conn = rawConn
+	if target.Inbound.Enabled() {
+		destination := proxyDestination{Host: target.Host, Port: parsePort(target.Port)}
+		conn, err = connectThroughInbound(rawConn, target.Inbound, destination)
+		if err != nil {
+			return result.fail(classifyProbeError(err, ProbeFailureConnect), err)
+		}
+	}
+
+	if target.TLS {
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName:         target.Host,
+			InsecureSkipVerify: target.InsecureSkipVerify,
+			NextProtos:         []string{"http/1.1"},
+		})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return result.fail(classifyProbeError(err, ProbeFailureTLS), err)
+		}
+		conn = tlsConn
+	}
+	result.HandshakeTime = time.Since(started)
+
+	request := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\nUser-Agent: Mozilla/5.0\r\nAccept: */*\r\nConnection: close\r\n\r\n",
+		target.Path, target.Host)
+	if _, err := io.WriteString(conn, request); err != nil {
+		return result.fail(classifyProbeError(err, ProbeFailureReset), err)
+	}
+
+	readStarted := time.Now()
+	buffer := make([]byte, 4096)
+	var firstChunk []byte
+	for result.BytesRead < target.MaxBytes {
+		n, err := conn.Read(buffer)
+		if n > 0 {
+			if firstChunk == nil {
+				firstChunk = append([]byte(nil), buffer[:n]...)
+			}
+			result.BytesRead += int64(n)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && result.BytesRead > 0 {
+				break
+			}
+			return result.fail(classifyProbeError(err, ProbeFailureReset), err)
+		}
+	}
+
+	if !strings.HasPrefix(string(firstChunk), "HTTP/") {
+		return result.fail(ProbeFailureResponse, fmt.Errorf("unexpected response"))
+	}
+
+	if elapsed := time.Since(readStarted).Seconds(); elapsed > 0 {
+		result.Throughput = float64(result.BytesRead) / elapsed
+	}
+	result.Success = true
+	return result
+}
+
+func (r ProbeResult) fail(failure ProbeFailure, err error) ProbeResult {
+	r.Success = false
+	r.Failure = failure
+	r.Error = err.Error()
+	return r
+}
+
+// classifyProbeError отличает сброс соединения и таймаут от прочих ошибок.
+// Прозрачный прокси закрывает клиентское соединение, когда сервер сбросил свое,
+// поэтому EOF до ответа тоже считается сбросом.
+func classifyProbeError(err error, fallback ProbeFailure) ProbeFailure {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ProbeFailureReset
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ProbeFailureTimeout
+	default:
+		return fallback
+	}
+}
+
+// referenceThroughput скорость, при которой метод получает полный балл за скорость
+const referenceThroughput = 1 << 20
+
+// scoreProbe оценивает метод по задержке, скорости и истории результатов.
+// Неудачная проверка получает 0.
+func scoreProbe(result ProbeResult, history MethodHistory) float64 {
+	if !result.Success {
+		return 0
+	}
+
+	latency := 1 / (1 + result.HandshakeTime.Seconds())
+	throughput := result.Throughput / referenceThroughput
+	if throughput > 1 {
+		throughput = 1
+	}
+
+	return latency * (0.5 + 0.5*throughput) * history.SuccessRate()
+}
+
+// rankProbes сортирует результаты: рабочие методы по убыванию оценки,
+// затем нерабочие. При равенстве сохраняется порядок кандидатов.
+func rankProbes(results []ProbeResult) []ProbeResult {
+	ranked := append([]ProbeResult(nil), results...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Success != ranked[j].Success {
+			return ranked[i].Success
+		}
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
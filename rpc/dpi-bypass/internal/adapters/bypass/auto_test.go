@@ -0,0 +1,324 @@
+package bypass
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startHTTPSTarget запускает HTTPS цель, отдающую 32 КБ
+func startHTTPSTarget(t *testing.T) int {
+	t.Helper()
+
+	cert, err := generateSelfSignedCert()
+	require.NoError(t, err)
+
+	body := strings.Repeat("x", 32*1024)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server.Listener.Addr().(*net.TCPAddr).Port
+}
+
+// resetConn сбрасывает соединение (RST вместо FIN), как делает DPI
+func resetConn(conn net.Conn) {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		_ = tcpConn.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// testForwarder пересылает соединения на цель, в режиме broken сбрасывает их
+type testForwarder struct {
+	broken atomic.Bool
+	port   int
+}
+
+func startTestForwarder(t *testing.T, targetPort int) *testForwarder {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	forwarder := &testForwarder{port: listener.Addr().(*net.TCPAddr).Port}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if forwarder.broken.Load() {
+				resetConn(conn)
+				continue
+			}
+			go func() {
+				defer conn.Close()
+				upstream, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", targetPort))
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				go func() { _, _ = io.Copy(upstream, conn) }()
+				_, _ = io.Copy(conn, upstream)
+			}()
+		}
+	}()
+
+	return forwarder
+}
+
+func autoTestConfig(t *testing.T, id string, targetPort int, params map[string]string) *domain.BypassConfig {
+	t.Helper()
+
+	localPort, err := freeLocalPort()
+	require.NoError(t, err)
+
+	parameters := map[string]string{
+		"local_port":                 localPort,
+		"target_host":                "localhost",
+		"target_port":                fmt.Sprintf("%d", targetPort),
+		"probe_tls":                  "true",
+		"probe_insecure_skip_verify": "true",
+		"probe_timeout_ms":           "2000",
+		"monitor_interval_ms":        "0",
+	}
+	for key, value := range params {
+		parameters[key] = value
+	}
+
+	return &domain.BypassConfig{ID: id, Method: domain.BypassMethodAuto, Parameters: parameters}
+}
+
+func startAutoAdapter(t *testing.T, adapter *AutoAdapter, config *domain.BypassConfig) {
+	t.Helper()
+
+	require.NoError(t, adapter.Start(config))
+	t.Cleanup(func() { _ = adapter.Stop(config.ID) })
+}
+
+func TestAutoAdapter_ProbesAndStartsBest(t *testing.T) {
+	targetPort := startHTTPSTarget(t)
+	resetPort := startTestForwarder(t, targetPort)
+	resetPort.broken.Store(true)
+	closedPort, err := freeLocalPort()
+	require.NoError(t, err)
+
+	adapter := NewAutoAdapter(zap.NewNop(), NewMemoryOutcomeStore())
+	config := autoTestConfig(t, "auto-1", targetPort, map[string]string{
+		"candidates":                "tcp_fragment,tls_handshake,custom",
+		"remote_host":               "127.0.0.1",
+		"tcp_fragment.remote_port":  fmt.Sprintf("%d", resetPort.port),
+		"tls_handshake.remote_port": fmt.Sprintf("%d", targetPort),
+		"custom.role":               "client",
+		"custom.remote_port":        closedPort,
+		"custom.password":           "secret",
+	})
+	startAutoAdapter(t, adapter, config)
+
+	method, err := adapter.ActiveMethod("auto-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.BypassMethodTLSHandshake, method)
+
+	ranking, err := adapter.Ranking("auto-1")
+	require.NoError(t, err)
+	require.Len(t, ranking, 3)
+	assert.Equal(t, domain.BypassMethodTLSHandshake, ranking[0].Method)
+	assert.True(t, ranking[0].Success)
+	assert.Greater(t, ranking[0].Score, 0.0)
+	assert.Greater(t, ranking[0].Throughput, 0.0)
+	for _, result := range ranking[1:] {
+		assert.False(t, result.Success, result.Method)
+		assert.Equal(t, ProbeFailureReset, result.Failure, result.Method)
+	}
+
+	// Выбранный метод обслуживает реальные запросы
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "tcp", "127.0.0.1:"+config.Parameters["local_port"])
+			},
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	resp, err := client.Get(fmt.Sprintf("https://localhost:%d/", targetPort))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	stats, err := adapter.GetStats("auto-1")
+	require.NoError(t, err)
+	require.NotNil(t, stats)
+	assert.Equal(t, int64(1), stats.ConnectionsEstablished)
+}
+
+func TestAutoAdapter_FailsOverOnRepeatedFailures(t *testing.T) {
+	targetPort := startHTTPSTarget(t)
+	primary := startTestForwarder(t, targetPort)
+	secondary := startTestForwarder(t, targetPort)
+
+	// История отказов резервного метода фиксирует начальный выбор
+	outcomes := NewMemoryOutcomeStore()
+	for i := 0; i < 6; i++ {
+		outcomes.Record(fmt.Sprintf("localhost:%d", targetPort), domain.BypassMethodTCPFragment, MethodOutcome{Failure: ProbeFailureTimeout})
+	}
+
+	adapter := NewAutoAdapter(zap.NewNop(), outcomes)
+	config := autoTestConfig(t, "auto-failover", targetPort, map[string]string{
+		"candidates":                "tls_handshake,tcp_fragment",
+		"remote_host":               "127.0.0.1",
+		"tls_handshake.remote_port": fmt.Sprintf("%d", primary.port),
+		"tcp_fragment.remote_port":  fmt.Sprintf("%d", secondary.port),
+		"monitor_interval_ms":       "50",
+		"failover_threshold":        "2",
+	})
+	startAutoAdapter(t, adapter, config)
+
+	method, err := adapter.ActiveMethod("auto-failover")
+	require.NoError(t, err)
+	assert.Equal(t, domain.BypassMethodTLSHandshake, method)
+
+	// DPI начинает сбрасывать соединения основного метода
+	primary.broken.Store(true)
+
+	require.Eventually(t, func() bool {
+		method, err := adapter.ActiveMethod("auto-failover")
+		return err == nil && method == domain.BypassMethodTCPFragment
+	}, 5*time.Second, 20*time.Millisecond)
+
+	history := outcomes.Get(fmt.Sprintf("localhost:%d", targetPort), domain.BypassMethodTLSHandshake)
+	assert.GreaterOrEqual(t, history.Failures, 2)
+	assert.Equal(t, ProbeFailureReset, history.LastFailure)
+}
+
+func TestAutoAdapter_OutcomesImproveRanking(t *testing.T) {
+	targetPort := startHTTPSTarget(t)
+	target := fmt.Sprintf("localhost:%d", targetPort)
+
+	// Метод, часто отказывавший на этой цели, должен уступить первенство
+	outcomes := NewMemoryOutcomeStore()
+	for i := 0; i < 6; i++ {
+		outcomes.Record(target, domain.BypassMethodTLSHandshake, MethodOutcome{Failure: ProbeFailureReset})
+	}
+
+	adapter := NewAutoAdapter(zap.NewNop(), outcomes)
+	startAutoAdapter(t, adapter, autoTestConfig(t, "auto-history", targetPort, map[string]string{
+		"candidates":  "tls_handshake,tcp_fragment",
+		"remote_host": "127.0.0.1",
+		"remote_port": fmt.Sprintf("%d", targetPort),
+	}))
+
+	method, err := adapter.ActiveMethod("auto-history")
+	require.NoError(t, err)
+	assert.Equal(t, domain.BypassMethodTCPFragment, method)
+}
+
+func TestAutoAdapter_NoWorkingMethod(t *testing.T) {
+	targetPort := startHTTPSTarget(t)
+	forwarder := startTestForwarder(t, targetPort)
+	forwarder.broken.Store(true)
+
+	adapter := NewAutoAdapter(zap.NewNop(), NewMemoryOutcomeStore())
+	err := adapter.Start(autoTestConfig(t, "auto-none", targetPort, map[string]string{
+		"candidates":  "tls_handshake",
+		"remote_host": "127.0.0.1",
+		"remote_port": fmt.Sprintf("%d", forwarder.port),
+	}))
+	assert.ErrorContains(t, err, "no working bypass method")
+	assert.False(t, adapter.IsRunning("auto-none"))
+}
+
+func TestParseAutoOptions(t *testing.T) {
+	options, err := parseAutoOptions(map[string]string{"remote_host": "example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultAutoCandidates, options.candidates)
+	assert.Equal(t, "example.com:443", options.target.Key())
+	assert.True(t, options.target.TLS)
+
+	invalid := []map[string]string{
+		{},
+		{"target_host": "example.com", "candidates": "tls_handshake,auto"},
+		{"target_host": "example.com", "failover_threshold": "0"},
+		{"target_host": "example.com", "probe_tls": "sometimes"},
+	}
+	for _, params := range invalid {
+		_, err := parseAutoOptions(params)
+		assert.Error(t, err, params)
+	}
+}
+
+func TestCandidateConfig(t *testing.T) {
+	config := &domain.BypassConfig{
+		ID: "auto",
+		Parameters: map[string]string{
+			"remote_host":             "edge.example",
+			"remote_port":             "443",
+			"shadowsocks.remote_port": "8388",
+			"v2ray.encryption":        "aes-128-gcm",
+		},
+	}
+
+	candidate := candidateConfig(config, domain.BypassMethodShadowsocks, "auto/probe", "2000")
+	assert.Equal(t, "auto/probe", candidate.ID)
+	assert.Equal(t, domain.BypassMethodShadowsocks, candidate.Method)
+	assert.Equal(t, map[string]string{
+		"remote_host": "edge.example",
+		"remote_port": "8388",
+		"local_port":  "2000",
+	}, candidate.Parameters)
+	assert.Equal(t, "443", config.Parameters["remote_port"])
+}
+
+func TestRankProbes(t *testing.T) {
+	history := MethodHistory{}
+	fast := ProbeResult{Method: "fast", Success: true, HandshakeTime: 10 * time.Millisecond, Throughput: referenceThroughput}
+	slow := ProbeResult{Method: "slow", Success: true, HandshakeTime: 2 * time.Second, Throughput: referenceThroughput / 10}
+	failed := ProbeResult{Method: "failed", Failure: ProbeFailureReset}
+
+	fast.Score = scoreProbe(fast, history)
+	slow.Score = scoreProbe(slow, history)
+	assert.Zero(t, scoreProbe(failed, history))
+
+	ranked := rankProbes([]ProbeResult{failed, slow, fast})
+	assert.Equal(t, []domain.BypassMethod{"fast", "slow", "failed"},
+		[]domain.BypassMethod{ranked[0].Method, ranked[1].Method, ranked[2].Method})
+
+	// История отказов снижает оценку при одинаковой проверке
+	unreliable := MethodHistory{Successes: 1, Failures: 8}
+	assert.Less(t, scoreProbe(fast, unreliable), fast.Score)
+}
+
+func TestMemoryOutcomeStore(t *testing.T) {
+	store := NewMemoryOutcomeStore()
+	assert.Equal(t, 0.5, store.Get("target", "m").SuccessRate())
+
+	for i := 0; i < outcomeWindow; i++ {
+		store.Record("target", "m", MethodOutcome{Failure: ProbeFailureTimeout})
+	}
+	store.Record("target", "m", MethodOutcome{Success: true, Throughput: 100})
+
+	history := store.Get("target", "m")
+	assert.Equal(t, 1, history.Successes)
+	assert.Equal(t, outcomeWindow-1, history.Failures)
+	assert.Equal(t, ProbeFailureTimeout, history.LastFailure)
+	assert.Equal(t, 100.0, history.AvgThroughput)
+	assert.False(t, history.LastSuccess.IsZero())
+}
@@ -7,11 +7,30 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"math/big"
 	"net"
 	"time"
 )
 
+// loadServerCertificate загружает сертификат TLS сервера из tls_cert_file
+// и tls_key_file или создает самоподписанный
+func loadServerCertificate(parameters map[string]string) (tls.Certificate, error) {
+	certFile, keyFile := parameters["tls_cert_file"], parameters["tls_key_file"]
+	if certFile == "" && keyFile == "" {
+		cert, err := generateSelfSignedCert()
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to generate certificate: %w", err)
+		}
+		return cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	return cert, nil
+}
+
 // generateSelfSignedCert создает самоподписанный сертификат для тестирования
 func generateSelfSignedCert() (tls.Certificate, error) {
 	// Генерируем приватный ключ
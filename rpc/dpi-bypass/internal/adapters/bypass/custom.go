@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
@@ -16,24 +18,55 @@ type CustomAdapter struct {
 	running map[string]*customConnection
 	mutex   sync.RWMutex
 	logger  *zap.Logger
+	signals *SignalMonitor
 }
 
 type customConnection struct {
-	config     *domain.BypassConfig
+	// config заменяется при перезагрузке без перезапуска
+	config     atomic.Pointer[domain.BypassConfig]
 	listener   net.Listener
 	ctx        context.Context
 	cancel     context.CancelFunc
 	stats      *domain.BypassStats
 	statsMutex sync.RWMutex
+	// tracker учитывает клиентские соединения для плавной остановки
+	tracker *connectionTracker
+	// Роль и адрес удаленной стороны
+	role       string // "server" декодирует кадры, "client" кодирует
+	remoteAddr string
+	// destinationHeader сервер читает назначение из первого сообщения клиента
+	destinationHeader bool
+	rules             *ruleMatcher
+	// inbound входящий SOCKS5/HTTP прокси клиента; nil в режиме raw
+	inbound *inboundFrontend
+	// UDP: поток датаграмм к серверу и listener клиента, таймаут сессий сервера
+	udpPool        *udpStreamPool
+	udpRelay       *udpRelay
+	udpIdleTimeout time.Duration
 	// Кастомные параметры обфускации
 	obfuscationMode string  // "chaff", "fragment", "timing", "hybrid"
 	chaffRatio      float64 // соотношение мусорного трафика
 	fragmentSize    int     // размер фрагментов
 	timingJitter    int     // джиттер в миллисекундах
 	encryptionKey   []byte  // ключ шифрования
-	nonceCounter    uint64  // счетчик для nonce
+	// shaping профиль формы трафика к удаленной стороне; shaper дополняет
+	// кадры мусором до размеров профиля вместо chaff_ratio
+	shaping *shapingConfig
+	shaper  *trafficShaper
 }
 
+// Роли кастомного адаптера
+const (
+	customRoleServer = "server"
+	customRoleClient = "client"
+)
+
+// Значения параметров по умолчанию
+const (
+	defaultCustomChaffRatio   = 0.1
+	defaultCustomFragmentSize = 512
+)
+
 // NewCustomAdapter создает новый кастомный адаптер
 func NewCustomAdapter(logger *zap.Logger) *CustomAdapter {
 	return &CustomAdapter{
@@ -42,6 +75,11 @@ func NewCustomAdapter(logger *zap.Logger) *CustomAdapter {
 	}
 }
 
+// observeSignals подключает монитор сигналов цензуры к исходящим соединениям
+func (c *CustomAdapter) observeSignals(monitor *SignalMonitor) {
+	c.signals = monitor
+}
+
 // Start запускает кастомный обфускатор
 func (c *CustomAdapter) Start(config *domain.BypassConfig) error {
 	c.mutex.Lock()
@@ -60,18 +98,63 @@ func (c *CustomAdapter) Start(config *domain.BypassConfig) error {
 		localPort = "1080"
 	}
 
+	params, err := c.parseParameters(config.Parameters)
+	if err != nil {
+		cancel()
+		return err
+	}
+	inbound, err := parseInboundConfig(config.Parameters)
+	if err != nil {
+		cancel()
+		return err
+	}
+	// Прокси принимает клиентские приложения, поэтому доступен только клиенту
+	if inbound.Enabled() && params.role != customRoleClient {
+		cancel()
+		return fmt.Errorf("inbound %s requires custom client role", inbound.Mode)
+	}
+	udp, err := parseUDPConfig(config.Parameters)
+	if err != nil {
+		cancel()
+		return err
+	}
+	if udp.Mode == udpModeNative {
+		cancel()
+		return fmt.Errorf("udp_mode native is not supported by custom")
+	}
+	if udp.Listen && params.role != customRoleClient {
+		cancel()
+		return fmt.Errorf("udp requires custom client role")
+	}
+	shaping, err := parseShaping(config.Parameters, config.Parameters["password"])
+	if err != nil {
+		cancel()
+		return err
+	}
+
 	// Создаем listener
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", localPort))
+	listener, err := inbound.Listen(localPort)
 	if err != nil {
 		cancel()
 		return fmt.Errorf("failed to create listener: %w", err)
 	}
 
 	conn := &customConnection{
-		config:   config,
-		listener: listener,
-		ctx:      ctx,
-		cancel:   cancel,
+		listener:          listener,
+		ctx:               ctx,
+		cancel:            cancel,
+		tracker:           newConnectionTracker(),
+		role:              params.role,
+		remoteAddr:        params.remoteAddr,
+		destinationHeader: params.destinationHeader,
+		rules:             newRuleMatcher(config.Rules),
+		udpIdleTimeout:    udp.IdleTimeout,
+		obfuscationMode:   params.obfuscationMode,
+		chaffRatio:        params.chaffRatio,
+		fragmentSize:      params.fragmentSize,
+		timingJitter:      params.timingJitter,
+		encryptionKey:     params.encryptionKey,
+		shaping:           shaping,
 		stats: &domain.BypassStats{
 			ID:                     config.ID,
 			ConfigID:               config.ID,
@@ -88,6 +171,36 @@ func (c *CustomAdapter) Start(config *domain.BypassConfig) error {
 			EndTime:                time.Now(),
 		},
 	}
+	conn.config.Store(config)
+	if shaping != nil {
+		conn.shaper = newTrafficShaper(shaping.Profile)
+	}
+	conn.udpPool = newUDPStreamPool(func() (net.Conn, error) {
+		remote, err := c.dialTunnel(conn, udpOverStreamDestination())
+		if err != nil {
+			return nil, err
+		}
+		return newCustomStreamConn(remote, c, conn), nil
+	})
+
+	conn.inbound = newInboundFrontend(inbound, conn.rules, config.ID, c.logger)
+	if conn.inbound != nil {
+		conn.inbound.packets = conn.udpPool.Session
+		conn.inbound.onTraffic = func(rx, tx int64) { c.updateStats(conn, rx, tx) }
+		conn.inbound.onError = func() { c.incrementErrorCount(conn) }
+	}
+
+	conn.udpRelay, err = newUDPRelay(udp, listener, conn.rules, conn.udpPool.Session, config.ID, c.logger)
+	if err != nil {
+		listener.Close()
+		cancel()
+		return err
+	}
+	if conn.udpRelay != nil {
+		conn.udpRelay.onTraffic = func(rx, tx int64) { c.updateStats(conn, rx, tx) }
+		conn.udpRelay.onError = func() { c.incrementErrorCount(conn) }
+		conn.udpRelay.start()
+	}
 
 	c.running[config.ID] = conn
 
@@ -97,34 +210,110 @@ func (c *CustomAdapter) Start(config *domain.BypassConfig) error {
 	c.logger.Info("custom obfuscator started",
 		zap.String("id", config.ID),
 		zap.String("name", config.Name),
-		zap.String("local_port", localPort))
+		zap.String("local_port", localPort),
+		zap.String("role", params.role),
+		zap.String("remote", params.remoteAddr),
+		zap.String("obfuscation_mode", params.obfuscationMode))
 
 	return nil
 }
 
-// Stop останавливает кастомный обфускатор
-func (c *CustomAdapter) Stop(id string) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// customParams разобранные параметры кастомного протокола
+type customParams struct {
+	role              string
+	remoteAddr        string
+	destinationHeader bool
+	obfuscationMode   string
+	chaffRatio        float64
+	fragmentSize      int
+	timingJitter      int
+	encryptionKey     []byte
+}
 
-	conn, exists := c.running[id]
-	if !exists {
-		return fmt.Errorf("custom connection not found: %s", id)
+// parseParameters разбирает параметры кастомного протокола
+func (c *CustomAdapter) parseParameters(parameters map[string]string) (*customParams, error) {
+	params := &customParams{
+		role:            parameters["role"],
+		obfuscationMode: parameters["obfuscation_mode"],
+		chaffRatio:      defaultCustomChaffRatio,
+		fragmentSize:    defaultCustomFragmentSize,
+	}
+
+	switch params.role {
+	case "":
+		params.role = customRoleServer
+	case customRoleServer, customRoleClient:
+	default:
+		return nil, fmt.Errorf("unsupported custom role: %s", params.role)
+	}
+
+	if params.obfuscationMode == "" {
+		params.obfuscationMode = "hybrid"
+	}
+
+	if value := parameters["destination_header"]; value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination_header: %s", value)
+		}
+		if enabled && params.role != customRoleServer {
+			return nil, fmt.Errorf("destination_header requires custom server role")
+		}
+		params.destinationHeader = enabled
 	}
 
-	// Отменяем контекст
-	if conn.cancel != nil {
-		conn.cancel()
+	remoteHost := parameters["remote_host"]
+	if remoteHost == "" {
+		remoteHost = "127.0.0.1"
+	}
+	remotePort := parameters["remote_port"]
+	if remotePort == "" {
+		remotePort = "8080"
 	}
+	params.remoteAddr = net.JoinHostPort(remoteHost, remotePort)
 
-	// Закрываем listener
-	if conn.listener != nil {
-		if err := conn.listener.Close(); err != nil {
-			c.logger.Error("failed to close listener", zap.Error(err), zap.String("id", id))
+	if value := parameters["chaff_ratio"]; value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 {
+			return nil, fmt.Errorf("invalid chaff_ratio: %s", value)
 		}
+		params.chaffRatio = ratio
 	}
 
-	delete(c.running, id)
+	if value := parameters["fragment_size"]; value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 || size > customMaxPayloadSize {
+			return nil, fmt.Errorf("invalid fragment_size: %s", value)
+		}
+		params.fragmentSize = size
+	}
+
+	if value := parameters["timing_jitter"]; value != "" {
+		jitter, err := strconv.Atoi(value)
+		if err != nil || jitter < 0 {
+			return nil, fmt.Errorf("invalid timing_jitter: %s", value)
+		}
+		params.timingJitter = jitter
+	}
+
+	// Без пароля ключ был бы известен всем, поэтому пароль обязателен
+	password := parameters["password"]
+	if password == "" {
+		return nil, fmt.Errorf("custom protocol requires password")
+	}
+	params.encryptionKey = c.generateKey(password)
+
+	return params, nil
+}
+
+// Stop останавливает кастомный обфускатор: закрывает listener, прерывает
+// соединения и ждет завершения их горутин
+func (c *CustomAdapter) Stop(id string) error {
+	detached, err := c.Detach(id)
+	if err != nil {
+		return err
+	}
+	detached.Drain(0)
 
 	c.logger.Info("custom obfuscator stopped", zap.String("id", id))
 	return nil
@@ -140,12 +329,17 @@ func (c *CustomAdapter) GetStats(id string) (*domain.BypassStats, error) {
 		return nil, nil
 	}
 
+	return c.snapshotStats(conn), nil
+}
+
+// snapshotStats возвращает копию статистики соединения
+func (c *CustomAdapter) snapshotStats(conn *customConnection) *domain.BypassStats {
 	conn.statsMutex.RLock()
 	defer conn.statsMutex.RUnlock()
 
 	// Возвращаем копию статистики
 	stats := *conn.stats
-	return &stats, nil
+	return &stats
 }
 
 // IsRunning проверяет, запущен ли кастомный обфускатор
@@ -156,3 +350,53 @@ func (c *CustomAdapter) IsRunning(id string) bool {
 	_, exists := c.running[id]
 	return exists
 }
+
+// Reload применяет правила к запущенному соединению. Изменения параметров
+// требуют перезапуска.
+func (c *CustomAdapter) Reload(config *domain.BypassConfig) error {
+	c.mutex.RLock()
+	conn, exists := c.running[config.ID]
+	c.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("custom connection not found: %s", config.ID)
+	}
+	if !hotSwappable(conn.config.Load(), config) {
+		return errRestartRequired
+	}
+
+	conn.rules.Replace(config.Rules)
+	conn.config.Store(config)
+
+	c.logger.Info("custom configuration reloaded", zap.String("id", config.ID))
+	return nil
+}
+
+// Detach убирает соединение из запущенных для плавной замены новым
+func (c *CustomAdapter) Detach(id string) (*detachedConnection, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	conn, exists := c.running[id]
+	if !exists {
+		return nil, fmt.Errorf("custom connection not found: %s", id)
+	}
+	delete(c.running, id)
+
+	return &detachedConnection{
+		config: conn.config.Load(),
+		closeListeners: func() {
+			conn.listener.Close()
+			if conn.udpRelay != nil {
+				conn.udpRelay.Close()
+			}
+		},
+		wait: conn.tracker.wait,
+		stop: func() {
+			conn.cancel()
+			conn.tracker.abort()
+			conn.udpPool.Close()
+			conn.tracker.done()
+		},
+		stats: func() *domain.BypassStats { return c.snapshotStats(conn) },
+	}, nil
+}
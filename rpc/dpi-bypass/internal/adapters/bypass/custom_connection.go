@@ -1,14 +1,20 @@
 package bypass
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net"
 	"time"
 
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
 	"go.uber.org/zap"
 )
 
 // handleConnections обрабатывает входящие соединения
 func (c *CustomAdapter) handleConnections(conn *customConnection) {
+	defer conn.tracker.acceptStopped()
+
 	for {
 		select {
 		case <-conn.ctx.Done():
@@ -16,17 +22,17 @@ func (c *CustomAdapter) handleConnections(conn *customConnection) {
 		default:
 			clientConn, err := conn.listener.Accept()
 			if err != nil {
-				if conn.ctx.Err() != nil {
-					// Контекст отменен, выходим
+				if conn.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+					// Контекст отменен или listener закрыт, выходим
 					return
 				}
-				c.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.ID))
+				c.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.Load().ID))
 				c.incrementErrorCount(conn)
 				continue
 			}
 
 			// Обрабатываем соединение в отдельной горутине
-			go c.handleClientConnection(conn, clientConn)
+			conn.tracker.handle(clientConn, func() { c.handleClientConnection(conn, clientConn) })
 		}
 	}
 }
@@ -38,50 +44,134 @@ func (c *CustomAdapter) handleClientConnection(conn *customConnection, clientCon
 	// Увеличиваем счетчик соединений
 	c.incrementConnections(conn)
 
-	// Подключаемся к удаленному серверу
-	// TODO: Временно закомментировано - нужно адаптировать под новую структуру
-	remoteAddr := "example.com:443" // conn.config.RemoteHost, conn.config.RemotePort
-	remoteConn, err := net.DialTimeout("tcp", remoteAddr, 10*time.Second)
+	// У сервера обфусцированная сторона - клиент, у клиента - удаленный сервер
+	if conn.role == customRoleServer {
+		clientConn = shapeConn(clientConn, conn.shaping)
+	}
+
+	var remoteConn net.Conn
+	var err error
+	switch {
+	case conn.inbound != nil:
+		var ok bool
+		clientConn, remoteConn, ok = conn.inbound.serve(conn.ctx, clientConn, func(destination proxyDestination) (net.Conn, error) {
+			return c.dialTunnel(conn, destination)
+		})
+		if !ok {
+			return
+		}
+	case conn.destinationHeader:
+		var destination proxyDestination
+		destination, err = c.readDestination(conn, clientConn)
+		if err == nil && destination.Host == udpOverStreamHost {
+			c.serveUDPStream(conn, clientConn)
+			return
+		}
+		if err == nil {
+			remoteConn, err = c.dialDestination(conn, destination)
+		}
+	default:
+		remoteConn, err = c.signals.Dial(context.Background(), conn.config.Load(), conn.remoteAddr, 10*time.Second)
+		if err == nil && conn.role == customRoleClient {
+			remoteConn = shapeConn(remoteConn, conn.shaping)
+		}
+	}
 	if err != nil {
 		c.logger.Error("failed to connect to remote server",
 			zap.Error(err),
-			zap.String("id", conn.config.ID),
-			zap.String("remote", "example.com")) // conn.config.RemoteHost
+			zap.String("id", conn.config.Load().ID),
+			zap.String("remote", conn.remoteAddr))
 		c.incrementErrorCount(conn)
 		return
 	}
 	defer remoteConn.Close()
 
-	// Создаем каналы для передачи данных
-	errChan := make(chan error, 2)
+	// Клиент кодирует исходящий поток и декодирует входящий, сервер - наоборот
+	upstream, downstream := c.copyDataWithCustomDeobfs, c.copyDataWithCustomObfs
+	if conn.role == customRoleClient {
+		upstream, downstream = c.copyDataWithCustomObfs, c.copyDataWithCustomDeobfs
+	}
 
-	// Копируем данные от клиента к серверу с кастомной обфускацией
-	go func() {
-		bytes, err := c.copyDataWithCustomObfs(clientConn, remoteConn, conn, true)
-		if err != nil {
-			errChan <- err
-		}
-		c.updateStats(conn, bytes, 0)
-	}()
+	// Обе горутины завершаются до выхода: соединения закрываются, как только
+	// останавливается одно из направлений
+	err = relayConns(conn.ctx, clientConn, remoteConn,
+		func() error {
+			// Копируем данные от клиента к удаленной стороне
+			bytes, err := upstream(clientConn, remoteConn, conn, true)
+			c.updateStats(conn, bytes, 0)
+			return err
+		},
+		func() error {
+			// Копируем данные от удаленной стороны к клиенту
+			bytes, err := downstream(remoteConn, clientConn, conn, false)
+			c.updateStats(conn, 0, bytes)
+			return err
+		})
+	if err != nil {
+		c.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.Load().ID))
+	}
+}
 
-	// Копируем данные от сервера к клиенту с кастомной обфускацией
-	go func() {
-		bytes, err := c.copyDataWithCustomObfs(remoteConn, clientConn, conn, false)
-		if err != nil {
-			errChan <- err
-		}
-		c.updateStats(conn, 0, bytes)
-	}()
+// dialTunnel открывает поток к назначению через сервер: назначение
+// отправляется первым сообщением протокола
+func (c *CustomAdapter) dialTunnel(conn *customConnection, destination proxyDestination) (net.Conn, error) {
+	remote, err := c.signals.Dial(context.Background(), conn.config.Load(), conn.remoteAddr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	remote = shapeConn(remote, conn.shaping)
+	header, err := c.applyCustomObfuscation(appendSocksAddr(nil, destination), conn)
+	if err == nil {
+		_, err = remote.Write(header)
+	}
+	if err != nil {
+		remote.Close()
+		return nil, err
+	}
+	return remote, nil
+}
+
+// readDestination читает заголовок назначения из первого сообщения клиента
+func (c *CustomAdapter) readDestination(conn *customConnection, clientConn net.Conn) (proxyDestination, error) {
+	if err := clientConn.SetReadDeadline(time.Now().Add(defaultInboundHandshakeTimeout)); err != nil {
+		return proxyDestination{}, err
+	}
+	// Читатель кадров не буферизует данные, поэтому следующие сообщения
+	// читаются из того же соединения при пересылке
+	message, err := newCustomFrameReader(clientConn, conn.encryptionKey).ReadMessage()
+	if err != nil {
+		return proxyDestination{}, fmt.Errorf("failed to read destination header: %w", err)
+	}
+	destination, size, err := parseSocksAddr(message)
+	if err != nil {
+		return proxyDestination{}, fmt.Errorf("invalid destination header: %w", err)
+	}
+	if size != len(message) {
+		return proxyDestination{}, fmt.Errorf("invalid destination header: unexpected %d trailing bytes", len(message)-size)
+	}
+	return destination, nil
+}
 
-	// Ждем завершения или ошибки
-	select {
-	case <-conn.ctx.Done():
+// dialDestination проверяет правила и подключается к назначению вместо remote
+func (c *CustomAdapter) dialDestination(conn *customConnection, destination proxyDestination) (net.Conn, error) {
+	if rule := conn.rules.Match(destination.ruleTarget("tcp")); rule != nil && rule.Action == domain.RuleActionBlock {
+		return nil, fmt.Errorf("%w: %s", ErrInboundBlocked, destination.Address())
+	}
+	return net.DialTimeout("tcp", destination.Address(), inboundDialTimeout)
+}
+
+// serveUDPStream обслуживает поток датаграмм UDP-over-stream от клиента
+func (c *CustomAdapter) serveUDPStream(conn *customConnection, clientConn net.Conn) {
+	if err := clientConn.SetReadDeadline(time.Time{}); err != nil {
 		return
-	case err := <-errChan:
-		if err != nil {
-			c.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.ID))
-		}
 	}
+	serveUDPOverStream(conn.ctx, newCustomStreamConn(clientConn, c, conn), udpStreamServerOptions{
+		ID:          conn.config.Load().ID,
+		Rules:       conn.rules,
+		IdleTimeout: conn.udpIdleTimeout,
+		Logger:      c.logger,
+		OnTraffic:   func(rx, tx int64) { c.updateStats(conn, rx, tx) },
+	})
 }
 
 // copyDataWithCustomObfs копирует данные с кастомной обфускацией
@@ -90,43 +180,62 @@ func (c *CustomAdapter) copyDataWithCustomObfs(src, dst net.Conn, conn *customCo
 	var totalBytes int64
 
 	for {
-		select {
-		case <-conn.ctx.Done():
-			return totalBytes, nil
-		default:
-			// Устанавливаем таймаут для чтения
-			if err := src.SetReadDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		n, err := src.Read(buffer)
+		if err != nil {
+			return totalBytes, err
+		}
+
+		if n > 0 {
+			// Применяем кастомную обфускацию
+			obfuscatedData, err := c.applyCustomObfuscation(buffer[:n], conn)
+			if err != nil {
+				return totalBytes, err
+			}
+
+			// Устанавливаем таймаут для записи
+			if err := dst.SetWriteDeadline(time.Now().Add(relayWriteTimeout)); err != nil {
 				return totalBytes, err
 			}
 
-			n, err := src.Read(buffer)
+			_, err = dst.Write(obfuscatedData)
 			if err != nil {
 				return totalBytes, err
 			}
 
-			if n > 0 {
-				// Применяем кастомную обфускацию
-				obfuscatedData, err := c.applyCustomObfuscation(buffer[:n], conn)
-				if err != nil {
-					return totalBytes, err
-				}
+			totalBytes += int64(n)
+			c.updateLastActivity(conn)
 
-				// Устанавливаем таймаут для записи
-				if err := dst.SetWriteDeadline(time.Now().Add(30 * time.Second)); err != nil {
-					return totalBytes, err
-				}
+			// Применяем кастомные задержки; с профилем паузы выдерживает shaper
+			if conn.shaping == nil {
+				c.applyCustomTiming(conn)
+			}
+		}
+	}
+}
 
-				_, err = dst.Write(obfuscatedData)
-				if err != nil {
-					return totalBytes, err
-				}
+// copyDataWithCustomDeobfs декодирует кадры кастомного протокола и пишет открытые данные
+func (c *CustomAdapter) copyDataWithCustomDeobfs(src, dst net.Conn, conn *customConnection, isTx bool) (int64, error) {
+	reader := newCustomFrameReader(src, conn.encryptionKey)
+	var totalBytes int64
+
+	for {
+		data, err := reader.ReadMessage()
+		if err != nil {
+			return totalBytes, err
+		}
 
-				totalBytes += int64(n)
-				c.updateLastActivity(conn)
+		if len(data) > 0 {
+			// Устанавливаем таймаут для записи
+			if err := dst.SetWriteDeadline(time.Now().Add(relayWriteTimeout)); err != nil {
+				return totalBytes, err
+			}
 
-				// Применяем кастомные задержки
-				c.applyCustomTiming(conn)
+			if _, err := dst.Write(data); err != nil {
+				return totalBytes, err
 			}
+
+			totalBytes += int64(len(data))
+			c.updateLastActivity(conn)
 		}
 	}
 }
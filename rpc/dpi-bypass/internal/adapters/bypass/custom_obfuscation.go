@@ -5,7 +5,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
-	"encoding/binary"
+	"fmt"
 	"io"
 	"math"
 	"time"
@@ -25,7 +25,7 @@ func (c *CustomAdapter) applyCustomObfuscation(data []byte, conn *customConnecti
 	}
 }
 
-// applyChaffObfuscation добавляет мусорный трафик
+// applyChaffObfuscation шифрует данные и дописывает мусор в один кадр
 func (c *CustomAdapter) applyChaffObfuscation(data []byte, conn *customConnection) ([]byte, error) {
 	// Шифруем данные
 	encryptedData, err := c.encryptData(data, conn)
@@ -36,26 +36,22 @@ func (c *CustomAdapter) applyChaffObfuscation(data []byte, conn *customConnectio
 	// Добавляем мусорный трафик
 	chaffData := c.generateChaffData(len(encryptedData), conn)
 
-	// Перемешиваем реальные данные с мусором
-	result := make([]byte, 0, len(encryptedData)+len(chaffData))
-
-	// Добавляем заголовок с информацией о размерах
-	header := make([]byte, 8)
-	binary.BigEndian.PutUint32(header[0:4], uint32(len(encryptedData)))
-	binary.BigEndian.PutUint32(header[4:8], uint32(len(chaffData)))
-	result = append(result, header...)
-
-	// Добавляем реальные данные
-	result = append(result, encryptedData...)
+	result := make([]byte, 0, customFrameHeaderSize+len(encryptedData)+len(chaffData))
+	return appendCustomFrame(result, 0, true, encryptedData, chaffData), nil
+}
 
-	// Добавляем мусорные данные
-	result = append(result, chaffData...)
+// applyFragmentObfuscation шифрует данные и разбивает их на кадры-фрагменты
+func (c *CustomAdapter) applyFragmentObfuscation(data []byte, conn *customConnection) ([]byte, error) {
+	return c.encodeFragments(data, conn, false)
+}
 
-	return result, nil
+// applyHybridObfuscation комбинирует фрагментацию и мусорный трафик в каждом фрагменте
+func (c *CustomAdapter) applyHybridObfuscation(data []byte, conn *customConnection) ([]byte, error) {
+	return c.encodeFragments(data, conn, true)
 }
 
-// applyFragmentObfuscation разбивает данные на фрагменты
-func (c *CustomAdapter) applyFragmentObfuscation(data []byte, conn *customConnection) ([]byte, error) {
+// encodeFragments шифрует данные и упаковывает фрагменты в кадры
+func (c *CustomAdapter) encodeFragments(data []byte, conn *customConnection, withChaff bool) ([]byte, error) {
 	// Шифруем данные
 	encryptedData, err := c.encryptData(data, conn)
 	if err != nil {
@@ -64,34 +60,24 @@ func (c *CustomAdapter) applyFragmentObfuscation(data []byte, conn *customConnec
 
 	// Разбиваем на фрагменты
 	fragments := c.fragmentData(encryptedData, conn.fragmentSize)
+	if len(fragments) > customMaxFragments {
+		return nil, fmt.Errorf("%w: %d", ErrCustomTooManyFragments, len(fragments))
+	}
 
 	// Собираем результат с заголовками фрагментов
-	result := make([]byte, 0, len(encryptedData)+len(fragments)*8)
+	result := make([]byte, 0, len(encryptedData)+len(fragments)*customFrameHeaderSize)
 
 	for i, fragment := range fragments {
-		// Заголовок фрагмента: [номер][размер][данные]
-		header := make([]byte, 8)
-		binary.BigEndian.PutUint32(header[0:4], uint32(i))
-		binary.BigEndian.PutUint32(header[4:8], uint32(len(fragment)))
-		result = append(result, header...)
-		result = append(result, fragment...)
+		var chaffData []byte
+		if withChaff {
+			chaffData = c.generateChaffData(len(fragment), conn)
+		}
+		result = appendCustomFrame(result, i, i == len(fragments)-1, fragment, chaffData)
 	}
 
 	return result, nil
 }
 
-// applyHybridObfuscation комбинирует несколько методов
-func (c *CustomAdapter) applyHybridObfuscation(data []byte, conn *customConnection) ([]byte, error) {
-	// Сначала фрагментируем
-	fragmented, err := c.applyFragmentObfuscation(data, conn)
-	if err != nil {
-		return nil, err
-	}
-
-	// Затем добавляем мусорный трафик
-	return c.applyChaffObfuscation(fragmented, conn)
-}
-
 // generateChaffData генерирует мусорные данные
 func (c *CustomAdapter) generateChaffData(realDataSize int, conn *customConnection) []byte {
 	// Вычисляем размер мусорных данных на основе соотношения
@@ -99,13 +85,19 @@ func (c *CustomAdapter) generateChaffData(realDataSize int, conn *customConnecti
 	if chaffSize < 64 {
 		chaffSize = 64 // Минимальный размер
 	}
+	// С профилем кадр дополняется до размера записи профиля
+	if conn.shaper != nil {
+		chaffSize = conn.shaper.padSize(customFrameHeaderSize + realDataSize)
+	}
 
-	chaffData := make([]byte, chaffSize)
+	if chaffSize > customMaxChaffSize {
+		chaffSize = customMaxChaffSize
+	}
 
-	// Генерируем псевдослучайные данные
-	for i := 0; i < chaffSize; i++ {
-		chaffData[i] = byte(conn.nonceCounter % 256)
-		conn.nonceCounter++
+	// Мусор неотличим от шифротекста
+	chaffData := make([]byte, chaffSize)
+	if _, err := io.ReadFull(rand.Reader, chaffData); err != nil {
+		panic("failed to generate chaff data: " + err.Error())
 	}
 
 	return chaffData
@@ -113,6 +105,10 @@ func (c *CustomAdapter) generateChaffData(realDataSize int, conn *customConnecti
 
 // fragmentData разбивает данные на фрагменты
 func (c *CustomAdapter) fragmentData(data []byte, fragmentSize int) [][]byte {
+	if fragmentSize <= 0 || fragmentSize > customMaxPayloadSize {
+		fragmentSize = customMaxPayloadSize
+	}
+
 	var fragments [][]byte
 
 	for i := 0; i < len(data); i += fragmentSize {
@@ -128,13 +124,7 @@ func (c *CustomAdapter) fragmentData(data []byte, fragmentSize int) [][]byte {
 
 // encryptData шифрует данные
 func (c *CustomAdapter) encryptData(data []byte, conn *customConnection) ([]byte, error) {
-	block, err := aes.NewCipher(conn.encryptionKey)
-	if err != nil {
-		return nil, err
-	}
-
-	// Создаем GCM режим
-	gcm, err := cipher.NewGCM(block)
+	gcm, err := newCustomAEAD(conn.encryptionKey)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +140,37 @@ func (c *CustomAdapter) encryptData(data []byte, conn *customConnection) ([]byte
 	return ciphertext, nil
 }
 
+// decryptCustomMessage расшифровывает сообщение вида nonce || ciphertext
+func decryptCustomMessage(message, key []byte) ([]byte, error) {
+	gcm, err := newCustomAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(message) < gcm.NonceSize() {
+		return nil, fmt.Errorf("custom protocol: message too short: %d bytes", len(message))
+	}
+
+	nonce, ciphertext := message[:gcm.NonceSize()], message[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("custom protocol: failed to decrypt message: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// newCustomAEAD создает AES-GCM для ключа соединения
+func newCustomAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Создаем GCM режим
+	return cipher.NewGCM(block)
+}
+
 // generateKey генерирует ключ из пароля
 func (c *CustomAdapter) generateKey(password string) []byte {
 	hash := sha256.Sum256([]byte(password))
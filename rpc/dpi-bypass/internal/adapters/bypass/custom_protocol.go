@@ -0,0 +1,215 @@
+package bypass
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+// Формат кадров кастомного протокола (версия 1).
+// Подробное описание: docs/DPI_CUSTOM_PROTOCOL.md
+//
+//	0         1         2                   4                   8                   12
+//	+---------+---------+-------------------+-------------------+-------------------+
+//	| version |  flags  |  fragment index   |  payload length   |   chaff length    |
+//	+---------+---------+-------------------+-------------------+-------------------+
+//	|                    payload (часть зашифрованного сообщения)                    |
+//	+--------------------------------------------------------------------------------+
+//	|                        chaff (мусор, отбрасывается)                            |
+//	+--------------------------------------------------------------------------------+
+const (
+	customProtocolVersion = 1
+	customFrameHeaderSize = 12
+
+	// customFlagFin отмечает последний фрагмент сообщения
+	customFlagFin = 0x01
+
+	customMaxPayloadSize = 64 * 1024
+	customMaxChaffSize   = 64 * 1024
+	customMaxMessageSize = 1024 * 1024
+
+	// customMaxFragments число фрагментов, которое различает 16-битный индекс
+	customMaxFragments = 1 << 16
+)
+
+// Ошибки декодирования кадров
+var (
+	ErrCustomUnsupportedVersion = errors.New("custom protocol: unsupported version")
+	ErrCustomFrameTooLarge      = errors.New("custom protocol: frame too large")
+	ErrCustomFragmentOrder      = errors.New("custom protocol: unexpected fragment index")
+	ErrCustomUnknownFlags       = errors.New("custom protocol: unknown flags")
+	ErrCustomTooManyFragments   = errors.New("custom protocol: too many fragments")
+)
+
+// customFrameHeader заголовок кадра
+type customFrameHeader struct {
+	version       byte
+	flags         byte
+	fragmentIndex uint16
+	payloadLength uint32
+	chaffLength   uint32
+}
+
+// marshal сериализует заголовок
+func (h customFrameHeader) marshal() []byte {
+	buf := make([]byte, customFrameHeaderSize)
+	buf[0] = h.version
+	buf[1] = h.flags
+	binary.BigEndian.PutUint16(buf[2:4], h.fragmentIndex)
+	binary.BigEndian.PutUint32(buf[4:8], h.payloadLength)
+	binary.BigEndian.PutUint32(buf[8:12], h.chaffLength)
+	return buf
+}
+
+// parseCustomFrameHeader разбирает и валидирует заголовок
+func parseCustomFrameHeader(buf []byte) (customFrameHeader, error) {
+	h := customFrameHeader{
+		version:       buf[0],
+		flags:         buf[1],
+		fragmentIndex: binary.BigEndian.Uint16(buf[2:4]),
+		payloadLength: binary.BigEndian.Uint32(buf[4:8]),
+		chaffLength:   binary.BigEndian.Uint32(buf[8:12]),
+	}
+
+	if h.version != customProtocolVersion {
+		return h, fmt.Errorf("%w: %d", ErrCustomUnsupportedVersion, h.version)
+	}
+	if h.flags&^customFlagFin != 0 {
+		return h, fmt.Errorf("%w: 0x%02x", ErrCustomUnknownFlags, h.flags)
+	}
+	if h.payloadLength > customMaxPayloadSize || h.chaffLength > customMaxChaffSize {
+		return h, ErrCustomFrameTooLarge
+	}
+
+	return h, nil
+}
+
+// appendCustomFrame добавляет кадр к буферу
+func appendCustomFrame(dst []byte, index int, fin bool, payload, chaff []byte) []byte {
+	header := customFrameHeader{
+		version:       customProtocolVersion,
+		fragmentIndex: uint16(index),
+		payloadLength: uint32(len(payload)),
+		chaffLength:   uint32(len(chaff)),
+	}
+	if fin {
+		header.flags |= customFlagFin
+	}
+
+	dst = append(dst, header.marshal()...)
+	dst = append(dst, payload...)
+	dst = append(dst, chaff...)
+	return dst
+}
+
+// customFrameReader собирает сообщения из потока кадров и расшифровывает их
+type customFrameReader struct {
+	src     io.Reader
+	key     []byte
+	header  []byte
+	message []byte
+}
+
+// newCustomFrameReader создает новый декодер кадров
+func newCustomFrameReader(src io.Reader, key []byte) *customFrameReader {
+	return &customFrameReader{
+		src:    src,
+		key:    key,
+		header: make([]byte, customFrameHeaderSize),
+	}
+}
+
+// ReadMessage читает кадры до флага FIN и возвращает расшифрованное сообщение
+func (r *customFrameReader) ReadMessage() ([]byte, error) {
+	r.message = r.message[:0]
+	expectedIndex := 0
+
+	for {
+		if _, err := io.ReadFull(r.src, r.header); err != nil {
+			if err == io.ErrUnexpectedEOF || (err == io.EOF && expectedIndex > 0) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		header, err := parseCustomFrameHeader(r.header)
+		if err != nil {
+			return nil, err
+		}
+		if int(header.fragmentIndex) != expectedIndex {
+			return nil, fmt.Errorf("%w: got %d, want %d", ErrCustomFragmentOrder, header.fragmentIndex, expectedIndex)
+		}
+		if len(r.message)+int(header.payloadLength) > customMaxMessageSize {
+			return nil, ErrCustomFrameTooLarge
+		}
+
+		offset := len(r.message)
+		r.message = append(r.message, make([]byte, header.payloadLength)...)
+		if _, err := io.ReadFull(r.src, r.message[offset:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		// Мусор читается и отбрасывается
+		if _, err := io.CopyN(io.Discard, r.src, int64(header.chaffLength)); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		if header.flags&customFlagFin != 0 {
+			return decryptCustomMessage(r.message, r.key)
+		}
+		expectedIndex++
+	}
+}
+
+// unexpectedEOF превращает EOF посреди кадра в io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// customStreamConn соединение, которое кодирует каждую запись в сообщение
+// протокола и читает расшифрованные сообщения
+type customStreamConn struct {
+	net.Conn
+	adapter *CustomAdapter
+	conn    *customConnection
+	reader  *customFrameReader
+	pending []byte
+}
+
+func newCustomStreamConn(stream net.Conn, adapter *CustomAdapter, conn *customConnection) *customStreamConn {
+	return &customStreamConn{
+		Conn:    stream,
+		adapter: adapter,
+		conn:    conn,
+		reader:  newCustomFrameReader(stream, conn.encryptionKey),
+	}
+}
+
+func (c *customStreamConn) Write(p []byte) (int, error) {
+	data, err := c.adapter.applyCustomObfuscation(p, c.conn)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.Conn.Write(data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *customStreamConn) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		message, err := c.reader.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+		c.pending = message
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
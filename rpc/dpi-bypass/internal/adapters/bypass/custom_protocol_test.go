@@ -0,0 +1,168 @@
+package bypass
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCustomConnection(mode string, fragmentSize int, chaffRatio float64) *customConnection {
+	adapter := &CustomAdapter{}
+	return &customConnection{
+		obfuscationMode: mode,
+		fragmentSize:    fragmentSize,
+		chaffRatio:      chaffRatio,
+		encryptionKey:   adapter.generateKey("roundtrip"),
+	}
+}
+
+func TestCustomProtocol_RoundTrip(t *testing.T) {
+	adapter := &CustomAdapter{}
+	data := bytes.Repeat([]byte("silence-custom-protocol "), 200)
+
+	for _, mode := range []string{"chaff", "fragment", "hybrid", "timing"} {
+		t.Run(mode, func(t *testing.T) {
+			conn := newTestCustomConnection(mode, 100, 0.5)
+
+			encoded, err := adapter.applyCustomObfuscation(data, conn)
+			require.NoError(t, err)
+
+			reader := newCustomFrameReader(bytes.NewReader(encoded), conn.encryptionKey)
+			decoded, err := reader.ReadMessage()
+			require.NoError(t, err)
+			assert.Equal(t, data, decoded)
+
+			_, err = reader.ReadMessage()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestCustomProtocol_RoundTripStream(t *testing.T) {
+	adapter := &CustomAdapter{}
+	conn := newTestCustomConnection("hybrid", 7, 0.3)
+	messages := [][]byte{[]byte("first"), {}, []byte("third message"), bytes.Repeat([]byte{0xff}, 4096)}
+
+	var stream bytes.Buffer
+	for _, message := range messages {
+		encoded, err := adapter.applyCustomObfuscation(message, conn)
+		require.NoError(t, err)
+		stream.Write(encoded)
+	}
+
+	reader := newCustomFrameReader(&stream, conn.encryptionKey)
+	for _, message := range messages {
+		decoded, err := reader.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, len(message), len(decoded))
+		assert.True(t, bytes.Equal(message, decoded))
+	}
+}
+
+func TestCustomProtocol_RoundTripProperty(t *testing.T) {
+	adapter := &CustomAdapter{}
+	modes := []string{"chaff", "fragment", "hybrid"}
+
+	property := func(data []byte, modeIndex uint8, fragmentSize uint16, chaffRatio uint8) bool {
+		conn := newTestCustomConnection(modes[int(modeIndex)%len(modes)], int(fragmentSize%2048)+1, float64(chaffRatio)/100)
+
+		encoded, err := adapter.applyCustomObfuscation(data, conn)
+		if err != nil {
+			return false
+		}
+
+		decoded, err := newCustomFrameReader(bytes.NewReader(encoded), conn.encryptionKey).ReadMessage()
+		return err == nil && bytes.Equal(data, decoded)
+	}
+
+	assert.NoError(t, quick.Check(property, &quick.Config{MaxCount: 200}))
+}
+
+func TestCustomProtocol_FrameLayout(t *testing.T) {
+	adapter := &CustomAdapter{}
+	conn := newTestCustomConnection("fragment", 16, 0)
+
+	encoded, err := adapter.applyCustomObfuscation([]byte("0123456789abcdef0123456789"), conn)
+	require.NoError(t, err)
+
+	// Первый кадр: версия, без FIN, индекс 0, полезная нагрузка 16 байт, без мусора
+	header, err := parseCustomFrameHeader(encoded[:customFrameHeaderSize])
+	require.NoError(t, err)
+	assert.Equal(t, byte(customProtocolVersion), header.version)
+	assert.Equal(t, byte(0), header.flags&customFlagFin)
+	assert.Equal(t, uint16(0), header.fragmentIndex)
+	assert.Equal(t, uint32(16), header.payloadLength)
+	assert.Equal(t, uint32(0), header.chaffLength)
+}
+
+func TestCustomProtocol_DecodeErrors(t *testing.T) {
+	adapter := &CustomAdapter{}
+	conn := newTestCustomConnection("fragment", 8, 0)
+
+	encoded, err := adapter.applyCustomObfuscation([]byte("payload for error cases"), conn)
+	require.NoError(t, err)
+
+	t.Run("неподдерживаемая версия", func(t *testing.T) {
+		corrupted := append([]byte(nil), encoded...)
+		corrupted[0] = 2
+		_, err := newCustomFrameReader(bytes.NewReader(corrupted), conn.encryptionKey).ReadMessage()
+		assert.ErrorIs(t, err, ErrCustomUnsupportedVersion)
+	})
+
+	t.Run("неизвестные флаги", func(t *testing.T) {
+		corrupted := append([]byte(nil), encoded...)
+		corrupted[1] = 0x80
+		_, err := newCustomFrameReader(bytes.NewReader(corrupted), conn.encryptionKey).ReadMessage()
+		assert.ErrorIs(t, err, ErrCustomUnknownFlags)
+	})
+
+	t.Run("нарушен порядок фрагментов", func(t *testing.T) {
+		corrupted := append([]byte(nil), encoded...)
+		corrupted[3] = 5
+		_, err := newCustomFrameReader(bytes.NewReader(corrupted), conn.encryptionKey).ReadMessage()
+		assert.ErrorIs(t, err, ErrCustomFragmentOrder)
+	})
+
+	t.Run("слишком большой кадр", func(t *testing.T) {
+		header := customFrameHeader{version: customProtocolVersion, payloadLength: customMaxPayloadSize + 1}
+		_, err := newCustomFrameReader(bytes.NewReader(header.marshal()), conn.encryptionKey).ReadMessage()
+		assert.ErrorIs(t, err, ErrCustomFrameTooLarge)
+	})
+
+	t.Run("обрыв посреди сообщения", func(t *testing.T) {
+		_, err := newCustomFrameReader(bytes.NewReader(encoded[:len(encoded)-3]), conn.encryptionKey).ReadMessage()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("неверный ключ", func(t *testing.T) {
+		_, err := newCustomFrameReader(bytes.NewReader(encoded), adapter.generateKey("other")).ReadMessage()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to decrypt")
+	})
+}
+
+func TestCustomProtocol_FragmentLimit(t *testing.T) {
+	adapter := &CustomAdapter{}
+	conn := newTestCustomConnection("fragment", 1, 0)
+
+	// Накладные расходы шифрования не зависят от размера данных
+	encrypted, err := adapter.encryptData(nil, conn)
+	require.NoError(t, err)
+	limit := customMaxFragments - len(encrypted)
+
+	// Последний фрагмент получает индекс 65535
+	data := bytes.Repeat([]byte{0x42}, limit)
+	encoded, err := adapter.applyCustomObfuscation(data, conn)
+	require.NoError(t, err)
+	decoded, err := newCustomFrameReader(bytes.NewReader(encoded), conn.encryptionKey).ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	// Следующий индекс не помещается в заголовок
+	_, err = adapter.applyCustomObfuscation(append(data, 0x42), conn)
+	assert.ErrorIs(t, err, ErrCustomTooManyFragments)
+}
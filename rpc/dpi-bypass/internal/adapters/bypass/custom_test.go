@@ -1,9 +1,12 @@
 package bypass
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
 	"github.com/stretchr/testify/assert"
@@ -89,3 +92,127 @@ func TestCustomAdapter_Start_PortBusy(t *testing.T) {
 	err = adapter1.Stop("busy-1")
 	assert.NoError(t, err)
 }
+
+// startEchoServer запускает локальный echo-сервер и возвращает его порт
+func startEchoServer(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// customListenerPort возвращает порт, выбранный адаптером
+func customListenerPort(t *testing.T, adapter *CustomAdapter, id string) int {
+	t.Helper()
+
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+
+	conn := adapter.running[id]
+	if conn == nil || conn.listener == nil {
+		t.Fatal("connection or listener is nil")
+	}
+	return conn.listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestCustomAdapter_ClientServerRoundTrip(t *testing.T) {
+	logger := zap.NewNop()
+	echoPort := startEchoServer(t)
+
+	for _, mode := range []string{"chaff", "fragment", "hybrid"} {
+		t.Run(mode, func(t *testing.T) {
+			server := NewCustomAdapter(logger)
+			err := server.Start(&domain.BypassConfig{
+				ID:     "custom-server-" + mode,
+				Method: domain.BypassMethodCustom,
+				Parameters: map[string]string{
+					"local_port":       "0",
+					"role":             "server",
+					"remote_host":      "127.0.0.1",
+					"remote_port":      fmt.Sprintf("%d", echoPort),
+					"password":         "shared-secret",
+					"obfuscation_mode": mode,
+					"fragment_size":    "64",
+				},
+			})
+			assert.NoError(t, err)
+			defer server.Stop("custom-server-" + mode)
+
+			client := NewCustomAdapter(logger)
+			err = client.Start(&domain.BypassConfig{
+				ID:     "custom-client-" + mode,
+				Method: domain.BypassMethodCustom,
+				Parameters: map[string]string{
+					"local_port":       "0",
+					"role":             "client",
+					"remote_host":      "127.0.0.1",
+					"remote_port":      fmt.Sprintf("%d", customListenerPort(t, server, "custom-server-"+mode)),
+					"password":         "shared-secret",
+					"obfuscation_mode": mode,
+					"fragment_size":    "64",
+				},
+			})
+			assert.NoError(t, err)
+			defer client.Stop("custom-client-" + mode)
+
+			conn, err := net.DialTimeout("tcp",
+				fmt.Sprintf("127.0.0.1:%d", customListenerPort(t, client, "custom-client-"+mode)), time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			payload := bytes.Repeat([]byte("ping "), 500)
+			_, err = conn.Write(payload)
+			assert.NoError(t, err)
+
+			assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			received := make([]byte, len(payload))
+			_, err = io.ReadFull(conn, received)
+			assert.NoError(t, err)
+			assert.Equal(t, payload, received)
+		})
+	}
+}
+
+func TestCustomAdapter_InvalidParameters(t *testing.T) {
+	adapter := NewCustomAdapter(zap.NewNop())
+
+	for name, params := range map[string]map[string]string{
+		"роль":         {"role": "relay"},
+		"фрагмент":     {"fragment_size": "0"},
+		"мусор":        {"chaff_ratio": "-1"},
+		"джиттер":      {"timing_jitter": "abc"},
+		"размер кадра": {"fragment_size": "100000"},
+		"без пароля":   {"password": ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			params["local_port"] = "0"
+			// Пароль задан везде, кроме проверки его отсутствия
+			if _, ok := params["password"]; !ok {
+				params["password"] = "secret"
+			}
+			err := adapter.Start(&domain.BypassConfig{ID: "invalid", Parameters: params})
+			assert.Error(t, err)
+			assert.False(t, adapter.IsRunning("invalid"))
+		})
+	}
+}
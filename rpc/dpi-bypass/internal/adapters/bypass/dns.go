@@ -0,0 +1,109 @@
+package bypass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/dns"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+)
+
+// Значения параметра dns_resolver: каким резолвером адаптер разрешает
+// remote_host
+const (
+	dnsResolverSystem    = "system"
+	dnsResolverEncrypted = "encrypted"
+)
+
+// validateDNS проверяет выбор резолвера и серверы DNS правил конфигурации
+func validateDNS(config *domain.BypassConfig, resolver *dns.Resolver) error {
+	switch value := config.Parameters["dns_resolver"]; value {
+	case "", dnsResolverSystem:
+	case dnsResolverEncrypted:
+		if resolver == nil {
+			return fmt.Errorf("dns_resolver %s requires dns upstreams", value)
+		}
+	default:
+		return fmt.Errorf("unsupported dns_resolver: %s", value)
+	}
+
+	for _, rule := range config.Rules {
+		upstream := rule.Parameters["dns_upstream"]
+		if upstream == "" {
+			continue
+		}
+		if rule.Type != domain.RuleTypeDomain {
+			return fmt.Errorf("dns_upstream requires domain rule: %s", rule.ID)
+		}
+		if resolver == nil || !resolver.HasUpstream(upstream) {
+			return fmt.Errorf("dns upstream is not configured: %s", upstream)
+		}
+	}
+	return nil
+}
+
+// dnsRouter выбирает сервер DNS по правилам доменов запущенных соединений
+type dnsRouter struct {
+	rules map[string]*ruleMatcher
+	mutex sync.RWMutex
+}
+
+func newDNSRouter() *dnsRouter {
+	return &dnsRouter{rules: make(map[string]*ruleMatcher)}
+}
+
+// Set заменяет правила соединения
+func (r *dnsRouter) Set(config *domain.BypassConfig) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.rules[config.ID] = newRuleMatcher(config.Rules)
+}
+
+// Delete убирает правила остановленного соединения
+func (r *dnsRouter) Delete(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.rules, id)
+}
+
+// Upstream возвращает сервер первого подходящего правила. Соединения
+// проверяются в порядке идентификаторов, чтобы выбор не зависел от
+// порядка обхода.
+func (r *dnsRouter) Upstream(name string) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	ids := make([]string, 0, len(r.rules))
+	for id := range r.rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if upstream := r.rules[id].DNSUpstream(name); upstream != "" {
+			return upstream
+		}
+	}
+	return ""
+}
+
+// dialResolved разрешает host зашифрованным резолвером и подключается к
+// адресам по очереди
+func dialResolved(ctx context.Context, dialer *net.Dialer, resolver *dns.Resolver, host, port string) (net.Conn, error) {
+	ips, err := resolver.LookupIP(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, ip := range ips {
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,158 @@
+package bypass
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/dns"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// staticUpstream отвечает на вопросы A одним адресом
+type staticUpstream struct {
+	address string
+	ip      [4]byte
+}
+
+func (u *staticUpstream) Address() string {
+	return u.address
+}
+
+func (u *staticUpstream) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	var request dnsmessage.Message
+	if err := request.Unpack(query); err != nil {
+		return nil, err
+	}
+	response := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: request.ID, Response: true},
+		Questions: request.Questions,
+	}
+	if question := request.Questions[0]; question.Type == dnsmessage.TypeA {
+		response.Answers = []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+			Body:   &dnsmessage.AResource{A: u.ip},
+		}}
+	}
+	return response.Pack()
+}
+
+func TestValidateDNS(t *testing.T) {
+	resolver := dns.NewResolver([]dns.Upstream{&staticUpstream{address: "tls://9.9.9.9:853"}}, zap.NewNop())
+	domainRule := func(upstream string) *domain.BypassRule {
+		return &domain.BypassRule{
+			ID:         "rule",
+			Type:       domain.RuleTypeDomain,
+			Pattern:    "*.example.com",
+			Parameters: map[string]string{"dns_upstream": upstream},
+			Enabled:    true,
+		}
+	}
+
+	assert.NoError(t, validateDNS(&domain.BypassConfig{}, nil))
+	assert.NoError(t, validateDNS(&domain.BypassConfig{
+		Parameters: map[string]string{"dns_resolver": "encrypted"},
+		Rules:      []*domain.BypassRule{domainRule("tls://9.9.9.9:853")},
+	}, resolver))
+
+	for name, config := range map[string]*domain.BypassConfig{
+		"неизвестный резолвер": {Parameters: map[string]string{"dns_resolver": "doh"}},
+		"неизвестный сервер":   {Rules: []*domain.BypassRule{domainRule("tls://1.1.1.1:853")}},
+		"правило не домена": {Rules: []*domain.BypassRule{{
+			ID:         "ip",
+			Type:       domain.RuleTypeIP,
+			Pattern:    "10.0.0.0/8",
+			Parameters: map[string]string{"dns_upstream": "tls://9.9.9.9:853"},
+		}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, validateDNS(config, resolver))
+		})
+	}
+
+	err := validateDNS(&domain.BypassConfig{Parameters: map[string]string{"dns_resolver": "encrypted"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestDNSRouter_Upstream(t *testing.T) {
+	router := newDNSRouter()
+	router.Set(&domain.BypassConfig{ID: "b", Rules: []*domain.BypassRule{{
+		ID: "low", Type: domain.RuleTypeDomain, Pattern: ".example.com", Priority: 1, Enabled: true,
+		Parameters: map[string]string{"dns_upstream": "tls://second"},
+	}}})
+	router.Set(&domain.BypassConfig{ID: "a", Rules: []*domain.BypassRule{
+		{
+			ID: "regex", Type: domain.RuleTypeRegex, Pattern: ".*", Priority: 10, Enabled: true,
+			Parameters: map[string]string{"dns_upstream": "tls://ignored"},
+		},
+		{
+			ID: "video", Type: domain.RuleTypeDomain, Pattern: "*.video.example.com", Priority: 5, Enabled: true,
+			Parameters: map[string]string{"dns_upstream": "tls://first"},
+		},
+	}})
+
+	assert.Equal(t, "tls://first", router.Upstream("cdn.video.example.com"))
+	assert.Equal(t, "tls://second", router.Upstream("example.com"))
+	assert.Empty(t, router.Upstream("other.org"))
+
+	router.Delete("b")
+	assert.Empty(t, router.Upstream("example.com"))
+}
+
+func TestSignalMonitor_DialEncryptedDNS(t *testing.T) {
+	monitor := NewSignalMonitor(zap.NewNop())
+	monitor.resolver = dns.NewResolver([]dns.Upstream{
+		&staticUpstream{address: "tls://poisoned", ip: [4]byte{10, 10, 34, 36}},
+	}, zap.NewNop())
+	monitor.lookup = func(ctx context.Context, reference, host string) ([]net.IP, error) {
+		t.Fatal("system resolver must not be checked")
+		return nil, nil
+	}
+
+	config := &domain.BypassConfig{
+		Method:     domain.BypassMethodShadowsocks,
+		Parameters: map[string]string{"dns_resolver": "encrypted"},
+	}
+	_, err := monitor.Dial(context.Background(), config, "blocked.example:443", time.Second)
+	assert.ErrorIs(t, err, dns.ErrPoisoned)
+
+	stats, err := monitor.Stats(nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(1), stats[0].Connections)
+	assert.Zero(t, stats[0].DNSPoisoning)
+}
+
+func TestMultiBypassAdapter_DNSRoutes(t *testing.T) {
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	resolver := dns.NewResolver([]dns.Upstream{&staticUpstream{address: "tls://9.9.9.9:853"}}, zap.NewNop())
+	adapter.SetResolver(resolver)
+
+	config := &domain.BypassConfig{
+		ID:     "dns-routes",
+		Method: domain.BypassMethodTLSHandshake,
+		Parameters: map[string]string{
+			"local_port":   "0",
+			"dns_resolver": "encrypted",
+		},
+		Rules: []*domain.BypassRule{{
+			ID: "rule", Type: domain.RuleTypeDomain, Pattern: "blocked.example", Enabled: true,
+			Parameters: map[string]string{"dns_upstream": "tls://9.9.9.9:853"},
+		}},
+	}
+	require.NoError(t, adapter.Start(config))
+	assert.Equal(t, "tls://9.9.9.9:853", adapter.dnsRoutes.Upstream("blocked.example"))
+
+	require.NoError(t, adapter.Stop(config.ID))
+	assert.Empty(t, adapter.dnsRoutes.Upstream("blocked.example"))
+
+	config.ID = "dns-routes-invalid"
+	config.Rules[0].Parameters["dns_upstream"] = "https://8.8.8.8/dns-query"
+	assert.Error(t, adapter.Start(config))
+	assert.False(t, adapter.IsRunning(config.ID))
+}
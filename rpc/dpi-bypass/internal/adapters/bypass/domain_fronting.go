@@ -0,0 +1,395 @@
+package bypass
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// DomainFrontingAdapter локальный HTTP прокси, отправляющий запросы через
+// фронт-домен (внешний SNI) с реальным Host внутри TLS, либо через ECH
+type DomainFrontingAdapter struct {
+	running map[string]*domainFrontingConnection
+	mutex   sync.RWMutex
+	logger  *zap.Logger
+	signals *SignalMonitor
+}
+
+type domainFrontingConnection struct {
+	// config заменяется при перезагрузке без перезапуска
+	config     atomic.Pointer[domain.BypassConfig]
+	listener   net.Listener
+	server     *http.Server
+	proxy      *httputil.ReverseProxy
+	ctx        context.Context
+	cancel     context.CancelFunc
+	stats      *domain.BypassStats
+	statsMutex sync.RWMutex
+	// active число открытых клиентских соединений для плавной остановки
+	active atomic.Int64
+	// workers горутины сервера и проверки фронтов
+	workers sync.WaitGroup
+	// Параметры фронтинга
+	remoteAddr         string // адрес edge-сервера, пусто - адрес фронта
+	targetHost         string // реальный Host, пусто - Host входящего запроса
+	fronts             *frontPool
+	rules              *ruleMatcher
+	echConfigList      []byte
+	healthInterval     time.Duration
+	healthPath         string
+	insecureSkipVerify bool
+	inbound            inboundConfig
+}
+
+// frontingRequestKey ключ контекста с выбранным фронтом
+type frontingRequestKey struct{}
+
+type frontingRequest struct {
+	front    string
+	realHost string
+}
+
+// NewDomainFrontingAdapter создает новый адаптер domain fronting
+func NewDomainFrontingAdapter(logger *zap.Logger) *DomainFrontingAdapter {
+	return &DomainFrontingAdapter{
+		running: make(map[string]*domainFrontingConnection),
+		logger:  logger,
+	}
+}
+
+// observeSignals подключает монитор сигналов цензуры к исходящим соединениям
+func (d *DomainFrontingAdapter) observeSignals(monitor *SignalMonitor) {
+	d.signals = monitor
+}
+
+// Start запускает прокси domain fronting
+func (d *DomainFrontingAdapter) Start(config *domain.BypassConfig) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, exists := d.running[config.ID]; exists {
+		return fmt.Errorf("domain fronting connection already running: %s", config.ID)
+	}
+
+	// Получаем параметры из конфигурации
+	localPort := config.Parameters["local_port"]
+	if localPort == "" {
+		localPort = "1080"
+	}
+
+	conn, err := d.parseParameters(config)
+	if err != nil {
+		return err
+	}
+
+	// Создаем контекст для управления жизненным циклом
+	conn.ctx, conn.cancel = context.WithCancel(context.Background())
+
+	// Создаем listener
+	listener, err := conn.inbound.Listen(localPort)
+	if err != nil {
+		conn.cancel()
+		return fmt.Errorf("failed to create listener: %w", err)
+	}
+
+	conn.listener = listener
+	conn.stats = &domain.BypassStats{
+		ID:                     config.ID,
+		ConfigID:               config.ID,
+		SessionID:              config.ID,
+		BytesReceived:          0,
+		BytesSent:              0,
+		ConnectionsEstablished: 0,
+		StartTime:              time.Now(),
+		EndTime:                time.Now(),
+	}
+	conn.proxy = &httputil.ReverseProxy{
+		Rewrite:        d.rewriteRequest(conn),
+		Transport:      d.newTransport(conn, true),
+		ModifyResponse: d.inspectResponse(conn),
+		ErrorHandler:   d.handleProxyError(conn),
+	}
+	conn.server = &http.Server{
+		Handler:           d.handleRequest(conn),
+		ReadHeaderTimeout: 30 * time.Second,
+		ConnState:         conn.trackState,
+	}
+
+	d.running[config.ID] = conn
+
+	// Запускаем обработку соединений
+	conn.workers.Add(1)
+	go func() {
+		defer conn.workers.Done()
+		err := conn.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+			d.logger.Error("domain fronting server failed", zap.Error(err), zap.String("id", config.ID))
+		}
+	}()
+
+	if conn.healthInterval > 0 {
+		conn.workers.Add(1)
+		go func() {
+			defer conn.workers.Done()
+			d.runHealthChecks(conn)
+		}()
+	}
+
+	d.logger.Info("domain fronting proxy started",
+		zap.String("id", config.ID),
+		zap.String("local_port", localPort),
+		zap.Strings("fronts", conn.fronts.Domains()),
+		zap.String("target_host", conn.targetHost),
+		zap.Bool("ech", len(conn.echConfigList) > 0))
+
+	return nil
+}
+
+// parseParameters разбирает параметры domain fronting
+func (d *DomainFrontingAdapter) parseParameters(config *domain.BypassConfig) (*domainFrontingConnection, error) {
+	params := config.Parameters
+	conn := &domainFrontingConnection{
+		targetHost:     params["target_host"],
+		rules:          newRuleMatcher(config.Rules),
+		healthInterval: 30 * time.Second,
+		healthPath:     params["health_check_path"],
+	}
+	conn.config.Store(config)
+
+	if remoteHost := params["remote_host"]; remoteHost != "" {
+		remotePort := params["remote_port"]
+		if remotePort == "" {
+			remotePort = "443"
+		}
+		conn.remoteAddr = net.JoinHostPort(remoteHost, remotePort)
+	}
+
+	if value := params["ech_config_list"]; value != "" {
+		echConfigList, err := parseECHConfigList(value)
+		if err != nil {
+			return nil, err
+		}
+		conn.echConfigList = echConfigList
+	}
+
+	fronts := splitList(params["front_domain"])
+	if len(conn.echConfigList) > 0 {
+		// С ECH внешний SNI берется из public_name конфигурации
+		if conn.targetHost == "" {
+			return nil, fmt.Errorf("target_host is required with ech_config_list")
+		}
+		fronts = []string{conn.targetHost}
+	}
+	if len(fronts) == 0 {
+		return nil, fmt.Errorf("front_domain is required")
+	}
+
+	threshold := 3
+	if value := params["health_failure_threshold"]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid health_failure_threshold: %s", value)
+		}
+		threshold = parsed
+	}
+	conn.fronts = newFrontPool(fronts, threshold)
+
+	if value := params["health_check_interval"]; value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid health_check_interval: %s", value)
+		}
+		conn.healthInterval = time.Duration(seconds) * time.Second
+	}
+	if conn.healthPath == "" {
+		conn.healthPath = "/"
+	}
+
+	// Адаптер сам является HTTP прокси: туннели CONNECT и SOCKS5 не
+	// проходят через фронт, поэтому допустим только режим http
+	inbound, err := parseInboundConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	if inbound.Mode != inboundRaw && inbound.Mode != inboundHTTP {
+		return nil, fmt.Errorf("unsupported inbound for domain fronting: %s", inbound.Mode)
+	}
+	conn.inbound = inbound
+
+	if value := params["insecure_skip_verify"]; value != "" {
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid insecure_skip_verify: %s", value)
+		}
+		conn.insecureSkipVerify = skip
+	}
+
+	return conn, nil
+}
+
+// parseECHConfigList декодирует base64 ECHConfigList и проверяет его длину
+func parseECHConfigList(value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ech_config_list: %w", err)
+	}
+	if len(data) <= 2 || int(binary.BigEndian.Uint16(data))+2 != len(data) {
+		return nil, fmt.Errorf("invalid ech_config_list: length mismatch")
+	}
+	return data, nil
+}
+
+// splitList разбирает список через запятую
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// upstreamHost имя, используемое в SNI и как адрес соединения
+func (c *domainFrontingConnection) upstreamHost(front string) string {
+	if len(c.echConfigList) > 0 {
+		return c.targetHost
+	}
+	return front
+}
+
+// Stop останавливает прокси domain fronting: закрывает listener и
+// соединения и ждет завершения горутин сервера
+func (d *DomainFrontingAdapter) Stop(id string) error {
+	detached, err := d.Detach(id)
+	if err != nil {
+		return err
+	}
+	detached.Drain(0)
+
+	d.logger.Info("domain fronting proxy stopped", zap.String("id", id))
+	return nil
+}
+
+// GetStats возвращает статистику прокси domain fronting
+func (d *DomainFrontingAdapter) GetStats(id string) (*domain.BypassStats, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	conn, exists := d.running[id]
+	if !exists {
+		return nil, nil
+	}
+
+	return d.snapshotStats(conn), nil
+}
+
+// snapshotStats возвращает копию статистики прокси
+func (d *DomainFrontingAdapter) snapshotStats(conn *domainFrontingConnection) *domain.BypassStats {
+	conn.statsMutex.RLock()
+	defer conn.statsMutex.RUnlock()
+
+	// Возвращаем копию статистики
+	stats := *conn.stats
+	return &stats
+}
+
+// IsRunning проверяет, запущен ли прокси domain fronting
+func (d *DomainFrontingAdapter) IsRunning(id string) bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	_, exists := d.running[id]
+	return exists
+}
+
+// Reload применяет правила к запущенному прокси. Изменения параметров
+// требуют перезапуска.
+func (d *DomainFrontingAdapter) Reload(config *domain.BypassConfig) error {
+	d.mutex.RLock()
+	conn, exists := d.running[config.ID]
+	d.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("domain fronting connection not found: %s", config.ID)
+	}
+	if !hotSwappable(conn.config.Load(), config) {
+		return errRestartRequired
+	}
+
+	conn.rules.Replace(config.Rules)
+	conn.config.Store(config)
+
+	d.logger.Info("domain fronting configuration reloaded", zap.String("id", config.ID))
+	return nil
+}
+
+// Detach убирает прокси из запущенных для плавной замены новым
+func (d *DomainFrontingAdapter) Detach(id string) (*detachedConnection, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	conn, exists := d.running[id]
+	if !exists {
+		return nil, fmt.Errorf("domain fronting connection not found: %s", id)
+	}
+	delete(d.running, id)
+
+	return &detachedConnection{
+		config: conn.config.Load(),
+		closeListeners: func() {
+			conn.listener.Close()
+		},
+		wait: func(timeout time.Duration) int64 {
+			// Shutdown закрывает простаивающие keep-alive соединения и ждет активные
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := conn.server.Shutdown(ctx); err != nil {
+				return conn.active.Load()
+			}
+			return 0
+		},
+		stop: func() {
+			conn.cancel()
+			conn.server.Close()
+			conn.workers.Wait()
+			conn.proxy.Transport.(*http.Transport).CloseIdleConnections()
+		},
+		stats: func() *domain.BypassStats { return d.snapshotStats(conn) },
+	}, nil
+}
+
+// trackState считает открытые клиентские соединения
+func (c *domainFrontingConnection) trackState(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		c.active.Add(1)
+	case http.StateHijacked, http.StateClosed:
+		c.active.Add(-1)
+	}
+}
+
+// FrontHealth возвращает состояние фронт-доменов соединения
+func (d *DomainFrontingAdapter) FrontHealth(id string) ([]FrontStatus, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	conn, exists := d.running[id]
+	if !exists {
+		return nil, fmt.Errorf("domain fronting connection not found: %s", id)
+	}
+
+	return conn.fronts.Statuses(), nil
+}
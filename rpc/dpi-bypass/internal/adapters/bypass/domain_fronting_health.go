@@ -0,0 +1,198 @@
+package bypass
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FrontStatus состояние фронт-домена
+type FrontStatus struct {
+	Domain              string    `json:"domain"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastCheck           time.Time `json:"last_check"`
+	LastError           string    `json:"last_error"`
+}
+
+// frontPool набор фронт-доменов с учетом их доступности
+type frontPool struct {
+	fronts           []*FrontStatus
+	failureThreshold int
+	mutex            sync.RWMutex
+}
+
+// newFrontPool создает пул, все фронты изначально считаются рабочими
+func newFrontPool(domains []string, failureThreshold int) *frontPool {
+	pool := &frontPool{failureThreshold: failureThreshold}
+	for _, domain := range domains {
+		pool.fronts = append(pool.fronts, &FrontStatus{Domain: domain, Healthy: true})
+	}
+	return pool
+}
+
+// Select выбирает первый рабочий фронт из предпочтительных, затем из всего пула.
+// Если рабочих фронтов нет, возвращается первый предпочтительный.
+func (p *frontPool) Select(preferred []string) string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, domain := range preferred {
+		if front := p.find(domain); front == nil || front.Healthy {
+			// Фронт из правила, отсутствующий в пуле, не проверяется
+			return domain
+		}
+	}
+	for _, front := range p.fronts {
+		if front.Healthy {
+			return front.Domain
+		}
+	}
+	if len(preferred) > 0 {
+		return preferred[0]
+	}
+	if len(p.fronts) > 0 {
+		return p.fronts[0].Domain
+	}
+	return ""
+}
+
+// Record учитывает результат проверки или реального запроса
+func (p *frontPool) Record(domain string, err error) (changed bool, healthy bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	front := p.find(domain)
+	if front == nil {
+		return false, err == nil
+	}
+
+	wasHealthy := front.Healthy
+	front.LastCheck = time.Now()
+	if err == nil {
+		front.ConsecutiveFailures = 0
+		front.LastError = ""
+		front.Healthy = true
+	} else {
+		front.ConsecutiveFailures++
+		front.LastError = err.Error()
+		if front.ConsecutiveFailures >= p.failureThreshold {
+			front.Healthy = false
+		}
+	}
+
+	return wasHealthy != front.Healthy, front.Healthy
+}
+
+// Statuses возвращает копию состояния фронтов
+func (p *frontPool) Statuses() []FrontStatus {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	statuses := make([]FrontStatus, len(p.fronts))
+	for i, front := range p.fronts {
+		statuses[i] = *front
+	}
+	return statuses
+}
+
+// Domains возвращает список фронтов пула
+func (p *frontPool) Domains() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	domains := make([]string, len(p.fronts))
+	for i, front := range p.fronts {
+		domains[i] = front.Domain
+	}
+	return domains
+}
+
+func (p *frontPool) find(domain string) *FrontStatus {
+	for _, front := range p.fronts {
+		if front.Domain == domain {
+			return front
+		}
+	}
+	return nil
+}
+
+// runHealthChecks периодически проверяет фронты до отмены контекста
+func (d *DomainFrontingAdapter) runHealthChecks(conn *domainFrontingConnection) {
+	ticker := time.NewTicker(conn.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		d.checkFronts(conn)
+
+		select {
+		case <-conn.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkFronts проверяет все фронты пула
+func (d *DomainFrontingAdapter) checkFronts(conn *domainFrontingConnection) {
+	for _, front := range conn.fronts.Domains() {
+		err := d.checkFront(conn, front)
+		d.recordFrontResult(conn, front, err)
+	}
+}
+
+// checkFront выполняет HEAD запрос через фронт с реальным Host.
+// Фронт считается нерабочим при ошибке TLS/соединения, 5xx, а также
+// 403 и 421, которыми CDN отвечают на запрет domain fronting.
+func (d *DomainFrontingAdapter) checkFront(conn *domainFrontingConnection, front string) error {
+	ctx, cancel := context.WithTimeout(conn.ctx, 10*time.Second)
+	defer cancel()
+
+	client := &http.Client{
+		Transport: d.newTransport(conn, false),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+conn.upstreamHost(front)+conn.healthPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Host = conn.targetHost
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusMisdirectedRequest:
+		return fmt.Errorf("front rejected: %s", resp.Status)
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("front error: %s", resp.Status)
+	}
+	return nil
+}
+
+// recordFrontResult учитывает результат и логирует смену состояния
+func (d *DomainFrontingAdapter) recordFrontResult(conn *domainFrontingConnection, front string, err error) {
+	changed, healthy := conn.fronts.Record(front, err)
+	if !changed {
+		return
+	}
+
+	if healthy {
+		d.logger.Info("front domain recovered", zap.String("id", conn.config.Load().ID), zap.String("front", front))
+	} else {
+		d.logger.Warn("front domain stopped working",
+			zap.String("id", conn.config.Load().ID),
+			zap.String("front", front),
+			zap.Error(err))
+	}
+}
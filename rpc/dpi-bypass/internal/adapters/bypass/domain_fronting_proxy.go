@@ -0,0 +1,211 @@
+package bypass
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// handleRequest применяет правила и передает запрос в reverse proxy
+func (d *DomainFrontingAdapter) handleRequest(conn *domainFrontingConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Увеличиваем счетчик соединений
+		d.incrementConnections(conn)
+
+		if conn.inbound.Mode == inboundHTTP {
+			if !authorizeProxyHeader(conn.inbound, r.Header) {
+				w.Header().Set("Proxy-Authenticate", `Basic realm="dpi-bypass"`)
+				http.Error(w, "proxy authentication required", http.StatusProxyAuthRequired)
+				return
+			}
+			if r.Method == http.MethodConnect {
+				http.Error(w, "connect is not supported by domain fronting", http.StatusMethodNotAllowed)
+				return
+			}
+		}
+
+		realHost := conn.targetHost
+		if realHost == "" {
+			realHost = r.Host
+			if host, _, err := net.SplitHostPort(r.Host); err == nil {
+				realHost = host
+			}
+		}
+
+		rule := conn.rules.Match(ruleTarget{Host: realHost, Protocol: "http"})
+		if rule != nil && rule.Action == domain.RuleActionBlock {
+			http.Error(w, "blocked by rule", http.StatusForbidden)
+			return
+		}
+
+		// Фронт из правила имеет приоритет над фронтами конфигурации
+		var preferred []string
+		if rule != nil {
+			preferred = splitList(rule.Parameters["front_domain"])
+		}
+		front := conn.fronts.Select(preferred)
+
+		ctx := context.WithValue(r.Context(), frontingRequestKey{}, frontingRequest{front: front, realHost: realHost})
+		conn.proxy.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// rewriteRequest направляет запрос на фронт, сохраняя реальный Host
+func (d *DomainFrontingAdapter) rewriteRequest(conn *domainFrontingConnection) func(*httputil.ProxyRequest) {
+	return func(pr *httputil.ProxyRequest) {
+		request, _ := pr.In.Context().Value(frontingRequestKey{}).(frontingRequest)
+
+		pr.Out.URL.Scheme = "https"
+		pr.Out.URL.Host = conn.upstreamHost(request.front)
+		pr.Out.Host = request.realHost
+	}
+}
+
+// inspectResponse пассивно отслеживает отказ фронта по ответам CDN
+func (d *DomainFrontingAdapter) inspectResponse(conn *domainFrontingConnection) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		request, _ := resp.Request.Context().Value(frontingRequestKey{}).(frontingRequest)
+
+		var err error
+		switch {
+		case resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusMisdirectedRequest:
+			err = fmt.Errorf("front rejected: %s", resp.Status)
+		case resp.StatusCode >= 500:
+			err = fmt.Errorf("front error: %s", resp.Status)
+		}
+		d.recordFrontResult(conn, request.front, err)
+		return nil
+	}
+}
+
+// handleProxyError учитывает ошибку соединения с фронтом
+func (d *DomainFrontingAdapter) handleProxyError(conn *domainFrontingConnection) func(http.ResponseWriter, *http.Request, error) {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		request, _ := r.Context().Value(frontingRequestKey{}).(frontingRequest)
+
+		d.logger.Debug("fronted request failed",
+			zap.Error(err),
+			zap.String("id", conn.config.Load().ID),
+			zap.String("front", request.front))
+		d.incrementErrorCount(conn)
+		if r.Context().Err() == nil {
+			d.recordFrontResult(conn, request.front, err)
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}
+}
+
+// newTransport создает HTTP транспорт, устанавливающий TLS с SNI фронта
+func (d *DomainFrontingAdapter) newTransport(conn *domainFrontingConnection, keepAlive bool) *http.Transport {
+	return &http.Transport{
+		DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return d.dialFront(ctx, conn, addr)
+		},
+		DisableKeepAlives:     !keepAlive,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	}
+}
+
+// dialFront устанавливает TLS соединение. SNI равен хосту из addr: фронту
+// при domain fronting или реальному хосту при ECH (внешний SNI подставит ECH).
+func (d *DomainFrontingAdapter) dialFront(ctx context.Context, conn *domainFrontingConnection, addr string) (net.Conn, error) {
+	serverName, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	dialAddr := addr
+	if conn.remoteAddr != "" {
+		dialAddr = conn.remoteAddr
+	}
+
+	rawConn, err := d.signals.Dial(ctx, conn.config.Load(), dialAddr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: conn.insecureSkipVerify,
+		NextProtos:         []string{"http/1.1"},
+	}
+	if len(conn.echConfigList) > 0 {
+		tlsConfig.EncryptedClientHelloConfigList = conn.echConfigList
+		tlsConfig.MinVersion = tls.VersionTLS13
+	}
+
+	tlsConn := tls.Client(&countingConn{Conn: rawConn, adapter: d, conn: conn}, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// countingConn учитывает трафик до фронта в статистике
+type countingConn struct {
+	net.Conn
+	adapter *DomainFrontingAdapter
+	conn    *domainFrontingConnection
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.adapter.updateStats(c.conn, 0, int64(n))
+		c.adapter.updateLastActivity(c.conn)
+	}
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.adapter.updateStats(c.conn, int64(n), 0)
+		c.adapter.updateLastActivity(c.conn)
+	}
+	return n, err
+}
+
+// updateStats обновляет статистику
+func (d *DomainFrontingAdapter) updateStats(conn *domainFrontingConnection, rx, tx int64) {
+	conn.statsMutex.Lock()
+	defer conn.statsMutex.Unlock()
+
+	conn.stats.BytesReceived += rx
+	conn.stats.BytesSent += tx
+}
+
+// updateLastActivity обновляет время последней активности
+func (d *DomainFrontingAdapter) updateLastActivity(conn *domainFrontingConnection) {
+	conn.statsMutex.Lock()
+	defer conn.statsMutex.Unlock()
+
+	conn.stats.EndTime = time.Now()
+}
+
+// incrementConnections увеличивает счетчик соединений
+func (d *DomainFrontingAdapter) incrementConnections(conn *domainFrontingConnection) {
+	conn.statsMutex.Lock()
+	defer conn.statsMutex.Unlock()
+
+	conn.stats.ConnectionsEstablished++
+}
+
+// incrementErrorCount увеличивает счетчик ошибок
+func (d *DomainFrontingAdapter) incrementErrorCount(conn *domainFrontingConnection) {
+	conn.statsMutex.Lock()
+	defer conn.statsMutex.Unlock()
+
+	conn.stats.ConnectionsFailed++
+}
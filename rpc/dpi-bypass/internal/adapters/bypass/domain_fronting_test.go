@@ -0,0 +1,277 @@
+package bypass
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// frontedRequest запрос, принятый CDN-сервером
+type frontedRequest struct {
+	SNI  string
+	Host string
+}
+
+// startFrontingServer запускает TLS сервер, имитирующий CDN: записывает SNI
+// и Host, а фронтам из rejected отвечает 421
+func startFrontingServer(t *testing.T, rejected ...string) (int, func() []frontedRequest) {
+	t.Helper()
+
+	cert, err := generateSelfSignedCert()
+	require.NoError(t, err)
+
+	var (
+		mutex    sync.Mutex
+		requests []frontedRequest
+	)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, frontedRequest{SNI: r.TLS.ServerName, Host: r.Host})
+		mutex.Unlock()
+
+		for _, front := range rejected {
+			if r.TLS.ServerName == front {
+				w.WriteHeader(http.StatusMisdirectedRequest)
+				return
+			}
+		}
+		_, _ = io.WriteString(w, "fronted")
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server.Listener.Addr().(*net.TCPAddr).Port, func() []frontedRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]frontedRequest(nil), requests...)
+	}
+}
+
+func startDomainFrontingAdapter(t *testing.T, config *domain.BypassConfig) (*DomainFrontingAdapter, string) {
+	t.Helper()
+
+	adapter := NewDomainFrontingAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(config))
+	t.Cleanup(func() { _ = adapter.Stop(config.ID) })
+
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+	return adapter, adapter.running[config.ID].listener.Addr().String()
+}
+
+func frontingGet(t *testing.T, addr, host string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+addr+"/resource", nil)
+	require.NoError(t, err)
+	req.Host = host
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestDomainFrontingAdapter_FrontAndHost(t *testing.T) {
+	serverPort, requests := startFrontingServer(t)
+
+	adapter, addr := startDomainFrontingAdapter(t, &domain.BypassConfig{
+		ID:     "fronting-1",
+		Type:   domain.BypassTypeDomainFronting,
+		Method: domain.BypassMethodHTTPHeader,
+		Parameters: map[string]string{
+			"local_port":            "0",
+			"remote_host":           "127.0.0.1",
+			"remote_port":           fmt.Sprintf("%d", serverPort),
+			"front_domain":          "allowed.cdn.example",
+			"target_host":           "hidden.example",
+			"insecure_skip_verify":  "true",
+			"health_check_interval": "0",
+		},
+	})
+
+	resp := frontingGet(t, addr, "ignored.example")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "fronted", string(body))
+
+	// SNI виден цензору, Host передается только внутри TLS
+	require.Len(t, requests(), 1)
+	assert.Equal(t, frontedRequest{SNI: "allowed.cdn.example", Host: "hidden.example"}, requests()[0])
+
+	stats, err := adapter.GetStats("fronting-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.ConnectionsEstablished)
+	assert.Greater(t, stats.BytesReceived, int64(0))
+	assert.Greater(t, stats.BytesSent, int64(0))
+}
+
+func TestDomainFrontingAdapter_Rules(t *testing.T) {
+	serverPort, requests := startFrontingServer(t)
+
+	_, addr := startDomainFrontingAdapter(t, &domain.BypassConfig{
+		ID: "fronting-rules",
+		Parameters: map[string]string{
+			"local_port":            "0",
+			"remote_host":           "127.0.0.1",
+			"remote_port":           fmt.Sprintf("%d", serverPort),
+			"front_domain":          "default.cdn.example",
+			"insecure_skip_verify":  "true",
+			"health_check_interval": "0",
+		},
+		Rules: []*domain.BypassRule{
+			{ID: "block", Type: domain.RuleTypeDomain, Pattern: "blocked.example", Action: domain.RuleActionBlock, Enabled: true},
+			{ID: "front", Type: domain.RuleTypeDomain, Pattern: "*.video.example", Action: domain.RuleActionBypass, Enabled: true,
+				Parameters: map[string]string{"front_domain": "media.cdn.example"}},
+		},
+	})
+
+	assert.Equal(t, http.StatusForbidden, frontingGet(t, addr, "blocked.example").StatusCode)
+	assert.Equal(t, http.StatusOK, frontingGet(t, addr, "www.video.example").StatusCode)
+	assert.Equal(t, http.StatusOK, frontingGet(t, addr, "news.example:80").StatusCode)
+
+	assert.Equal(t, []frontedRequest{
+		{SNI: "media.cdn.example", Host: "www.video.example"},
+		{SNI: "default.cdn.example", Host: "news.example"},
+	}, requests())
+}
+
+func TestDomainFrontingAdapter_HealthCheckFailover(t *testing.T) {
+	serverPort, requests := startFrontingServer(t, "banned.cdn.example")
+
+	adapter, addr := startDomainFrontingAdapter(t, &domain.BypassConfig{
+		ID: "fronting-health",
+		Parameters: map[string]string{
+			"local_port":               "0",
+			"remote_host":              "127.0.0.1",
+			"remote_port":              fmt.Sprintf("%d", serverPort),
+			"front_domain":             "banned.cdn.example, working.cdn.example",
+			"target_host":              "hidden.example",
+			"insecure_skip_verify":     "true",
+			"health_check_interval":    "60",
+			"health_failure_threshold": "1",
+		},
+	})
+
+	// Первая проверка выполняется сразу после запуска
+	require.Eventually(t, func() bool {
+		statuses, err := adapter.FrontHealth("fronting-health")
+		return err == nil && !statuses[0].Healthy && statuses[1].Healthy && !statuses[1].LastCheck.IsZero()
+	}, 5*time.Second, 20*time.Millisecond)
+
+	statuses, err := adapter.FrontHealth("fronting-health")
+	require.NoError(t, err)
+	assert.Contains(t, statuses[0].LastError, "421")
+
+	assert.Equal(t, http.StatusOK, frontingGet(t, addr, "").StatusCode)
+	all := requests()
+	assert.Equal(t, frontedRequest{SNI: "working.cdn.example", Host: "hidden.example"}, all[len(all)-1])
+}
+
+func TestDomainFrontingAdapter_InvalidParameters(t *testing.T) {
+	adapter := NewDomainFrontingAdapter(zap.NewNop())
+
+	cases := map[string]map[string]string{
+		"без фронта":          {"local_port": "0"},
+		"ECH без target_host": {"local_port": "0", "ech_config_list": base64.StdEncoding.EncodeToString(testECHConfigList(t, "public.example"))},
+		"битый ECH":           {"local_port": "0", "target_host": "hidden.example", "ech_config_list": "AAA="},
+		"неверный порог":      {"local_port": "0", "front_domain": "cdn.example", "health_failure_threshold": "0"},
+		"неверный интервал":   {"local_port": "0", "front_domain": "cdn.example", "health_check_interval": "soon"},
+	}
+	for name, params := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, adapter.Start(&domain.BypassConfig{ID: "fronting-invalid", Parameters: params}))
+			assert.False(t, adapter.IsRunning("fronting-invalid"))
+		})
+	}
+}
+
+// testECHConfigList собирает ECHConfigList (draft-ietf-tls-esni-18) с ключом X25519
+func testECHConfigList(t *testing.T, publicName string) []byte {
+	t.Helper()
+
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKey := key.PublicKey().Bytes()
+
+	var contents []byte
+	contents = append(contents, 1)                             // config_id
+	contents = binary.BigEndian.AppendUint16(contents, 0x0020) // DHKEM(X25519, HKDF-SHA256)
+	contents = binary.BigEndian.AppendUint16(contents, uint16(len(publicKey)))
+	contents = append(contents, publicKey...)
+	contents = binary.BigEndian.AppendUint16(contents, 4)      // cipher_suites
+	contents = binary.BigEndian.AppendUint16(contents, 0x0001) // HKDF-SHA256
+	contents = binary.BigEndian.AppendUint16(contents, 0x0001) // AES-128-GCM
+	contents = append(contents, 0)                             // maximum_name_length
+	contents = append(contents, byte(len(publicName)))
+	contents = append(contents, publicName...)
+	contents = binary.BigEndian.AppendUint16(contents, 0) // extensions
+
+	var config []byte
+	config = binary.BigEndian.AppendUint16(config, tlsExtensionECH)
+	config = binary.BigEndian.AppendUint16(config, uint16(len(contents)))
+	config = append(config, contents...)
+
+	list := binary.BigEndian.AppendUint16(nil, uint16(len(config)))
+	return append(list, config...)
+}
+
+func TestDomainFrontingAdapter_ECHHidesTargetSNI(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	hellos := make(chan *clientHello, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, hello, err := readClientHello(conn); err == nil {
+			hellos <- hello
+		}
+	}()
+
+	_, addr := startDomainFrontingAdapter(t, &domain.BypassConfig{
+		ID: "fronting-ech",
+		Parameters: map[string]string{
+			"local_port":            "0",
+			"remote_host":           "127.0.0.1",
+			"remote_port":           fmt.Sprintf("%d", listener.Addr().(*net.TCPAddr).Port),
+			"target_host":           "hidden.example",
+			"ech_config_list":       base64.StdEncoding.EncodeToString(testECHConfigList(t, "public.example")),
+			"health_check_interval": "0",
+		},
+	})
+
+	// Сервер не завершает рукопожатие, прокси вернет 502
+	assert.Equal(t, http.StatusBadGateway, frontingGet(t, addr, "").StatusCode)
+
+	select {
+	case hello := <-hellos:
+		assert.Equal(t, "public.example", hello.serverName)
+		assert.True(t, hello.hasExtension(tlsExtensionECH))
+	case <-time.After(5 * time.Second):
+		t.Fatal("client hello was not received")
+	}
+}
@@ -0,0 +1,135 @@
+package bypass
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// relayWriteTimeout ограничивает запись в соединение, которое перестало
+// читать данные. Чтение не ограничено: простаивающее соединение живет до
+// закрытия одной из сторон или остановки сессии.
+const relayWriteTimeout = 30 * time.Second
+
+// connectionTracker учитывает обрабатываемые клиентские соединения, чтобы
+// плавная остановка могла дождаться их завершения, а по таймауту - закрыть
+type connectionTracker struct {
+	active     sync.WaitGroup
+	count      atomic.Int64
+	acceptDone chan struct{}
+
+	mutex   sync.Mutex
+	conns   map[net.Conn]struct{}
+	aborted bool
+}
+
+func newConnectionTracker() *connectionTracker {
+	return &connectionTracker{
+		acceptDone: make(chan struct{}),
+		conns:      make(map[net.Conn]struct{}),
+	}
+}
+
+// handle обрабатывает клиентское соединение в отдельной горутине и
+// закрывает его по завершении. Вызывается только из цикла accept.
+func (t *connectionTracker) handle(clientConn net.Conn, serve func()) {
+	t.mutex.Lock()
+	if t.aborted {
+		t.mutex.Unlock()
+		clientConn.Close()
+		return
+	}
+	t.conns[clientConn] = struct{}{}
+	t.mutex.Unlock()
+
+	t.active.Add(1)
+	t.count.Add(1)
+	go func() {
+		defer t.active.Done()
+		defer t.count.Add(-1)
+		defer t.release(clientConn)
+		serve()
+	}()
+}
+
+func (t *connectionTracker) release(clientConn net.Conn) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	clientConn.Close()
+	delete(t.conns, clientConn)
+}
+
+// acceptStopped отмечает выход из цикла accept
+func (t *connectionTracker) acceptStopped() {
+	close(t.acceptDone)
+}
+
+// wait ждет выхода из цикла accept и завершения соединений не дольше
+// timeout. Возвращает число незавершенных соединений.
+func (t *connectionTracker) wait(timeout time.Duration) int64 {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	select {
+	case <-t.acceptDone:
+	case <-deadline.C:
+		return t.count.Load()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		t.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return 0
+	case <-deadline.C:
+		return t.count.Load()
+	}
+}
+
+// abort закрывает оставшиеся клиентские соединения, их обработчики
+// завершаются на ошибке чтения. Соединения, принятые позже, сразу закрываются.
+func (t *connectionTracker) abort() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.aborted = true
+	for clientConn := range t.conns {
+		clientConn.Close()
+	}
+}
+
+// done ждет выхода из цикла accept и завершения всех обработчиков.
+// Вызывается после закрытия listener.
+func (t *connectionTracker) done() {
+	<-t.acceptDone
+	t.active.Wait()
+}
+
+// relayConns копирует данные в обе стороны и ждет обе горутины. Когда одно
+// направление останавливается или отменяется ctx, оба соединения
+// закрываются, чтобы второе направление не зависло на чтении. Возвращает
+// ошибку направления, остановившегося первым.
+func relayConns(ctx context.Context, client, remote net.Conn, upstream, downstream func() error) error {
+	closeBoth := func() {
+		client.Close()
+		remote.Close()
+	}
+	stop := context.AfterFunc(ctx, closeBoth)
+	defer stop()
+
+	errChan := make(chan error, 2)
+	go func() { errChan <- upstream() }()
+	go func() { errChan <- downstream() }()
+
+	err := <-errChan
+	closeBoth()
+	<-errChan
+	return err
+}
@@ -0,0 +1,281 @@
+package bypass
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/zap"
+)
+
+// assertClosed проверяет, что соединение закрыто другой стороной
+func assertClosed(t *testing.T, conn net.Conn) {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err := conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	assert.False(t, isTimeout(err))
+}
+
+func TestConnectionTracker_AbortClosesConnections(t *testing.T) {
+	tracker := newConnectionTracker()
+	client, server := net.Pipe()
+	defer client.Close()
+
+	served := make(chan struct{})
+	tracker.handle(server, func() {
+		_, _ = io.Copy(io.Discard, server)
+		close(served)
+	})
+	tracker.acceptStopped()
+	assert.Equal(t, int64(1), tracker.wait(10*time.Millisecond))
+
+	tracker.abort()
+	tracker.done()
+	<-served
+	assert.Equal(t, int64(0), tracker.wait(0))
+
+	// Соединения после прерывания закрываются без обработчика
+	late, other := net.Pipe()
+	defer other.Close()
+	tracker.handle(late, func() { t.Error("handler after abort") })
+	_, err := other.Read(make([]byte, 1))
+	assert.Error(t, err)
+}
+
+func TestRelayConns(t *testing.T) {
+	copyConn := func(dst, src net.Conn) func() error {
+		return func() error {
+			_, err := io.Copy(dst, src)
+			return err
+		}
+	}
+
+	t.Run("одна сторона закрылась", func(t *testing.T) {
+		client, clientPeer := net.Pipe()
+		remote, remotePeer := net.Pipe()
+		defer remotePeer.Close()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- relayConns(context.Background(), client, remote, copyConn(remote, client), copyConn(client, remote))
+		}()
+		clientPeer.Close()
+
+		assert.NoError(t, <-done)
+		_, err := remotePeer.Read(make([]byte, 1))
+		assert.Error(t, err)
+	})
+
+	t.Run("отмена контекста", func(t *testing.T) {
+		client, clientPeer := net.Pipe()
+		remote, remotePeer := net.Pipe()
+		defer clientPeer.Close()
+		defer remotePeer.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- relayConns(ctx, client, remote, copyConn(remote, client), copyConn(client, remote))
+		}()
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.True(t, err == nil || errors.Is(err, io.ErrClosedPipe), err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("relay did not stop")
+		}
+	})
+}
+
+func TestMultiBypassAdapter_DrainLeavesNoGoroutines(t *testing.T) {
+	secret := hex.EncodeToString([]byte("drain obfs4 secret"))
+	vlessID := uuid.NewString()
+	serverPSK := testKey(1, 16)
+	userPSK := base64.StdEncoding.EncodeToString([]byte("drain-psk-16byte"))
+
+	for _, tc := range []struct {
+		method domain.BypassMethod
+		server map[string]string
+		client map[string]string
+		users  []*domain.BypassUser
+		socks  bool
+	}{
+		{
+			method: domain.BypassMethodShadowsocks,
+			server: map[string]string{"password": serverPSK, "encryption": "2022-blake3-aes-128-gcm"},
+			client: map[string]string{"password": serverPSK, "encryption": "2022-blake3-aes-128-gcm", "user_psk": userPSK, "inbound": "socks5"},
+			users:  []*domain.BypassUser{{ID: "alice", Credential: userPSK}},
+			socks:  true,
+		},
+		{
+			method: domain.BypassMethodV2Ray,
+			client: map[string]string{"uuid": vlessID, "inbound": "socks5"},
+			users:  []*domain.BypassUser{{ID: "alice", Credential: vlessID}},
+			socks:  true,
+		},
+		{
+			method: domain.BypassMethodObfs4,
+			client: map[string]string{"user_secret": secret, "inbound": "socks5"},
+			users:  []*domain.BypassUser{{ID: "alice", Credential: secret}},
+			socks:  true,
+		},
+		{
+			method: domain.BypassMethodCustom,
+			server: map[string]string{"password": "shared-secret", "obfuscation_mode": "hybrid"},
+			client: map[string]string{"role": "client", "password": "shared-secret", "obfuscation_mode": "hybrid"},
+		},
+	} {
+		t.Run(string(tc.method), func(t *testing.T) {
+			echoPort := startEchoServer(t)
+			ignore := goleak.IgnoreCurrent()
+
+			server := NewMultiBypassAdapter(zap.NewNop())
+			serverPort := freeTestPort(t)
+			serverParams := map[string]string{"local_port": serverPort, "role": roleServer}
+			if !tc.socks {
+				serverParams["remote_host"] = "127.0.0.1"
+				serverParams["remote_port"] = fmt.Sprintf("%d", echoPort)
+			}
+			for key, value := range tc.server {
+				serverParams[key] = value
+			}
+			require.NoError(t, server.Start(&domain.BypassConfig{ID: "server", Method: tc.method, Parameters: serverParams, Users: tc.users}))
+
+			client := NewMultiBypassAdapter(zap.NewNop())
+			clientPort := freeTestPort(t)
+			clientParams := map[string]string{"local_port": clientPort, "remote_host": "127.0.0.1", "remote_port": serverPort}
+			for key, value := range tc.client {
+				clientParams[key] = value
+			}
+			require.NoError(t, client.Start(&domain.BypassConfig{ID: "client", Method: tc.method, Parameters: clientParams}))
+
+			var conn net.Conn
+			if tc.socks {
+				conn = connectThrough(t, clientPort, localDestination(echoPort))
+			} else {
+				conn = dialProxy(t, net.JoinHostPort("127.0.0.1", clientPort))
+			}
+			defer conn.Close()
+			assertEcho(t, conn, "in flight")
+
+			// Активное соединение прерывается, статистика учитывает его байты
+			stats, err := client.Drain("client", 0)
+			require.NoError(t, err)
+			assert.False(t, stats.EndTime.IsZero())
+			assert.Equal(t, int64(len("in flight")), stats.BytesSent)
+			assert.Equal(t, int64(len("in flight")), stats.BytesReceived)
+			assertClosed(t, conn)
+			assert.False(t, client.IsRunning("client"))
+
+			stats, err = server.Drain("server", 0)
+			require.NoError(t, err)
+			assert.False(t, stats.EndTime.IsZero())
+
+			goleak.VerifyNone(t, ignore)
+		})
+	}
+}
+
+func TestMultiBypassAdapter_DrainWaitsForConnections(t *testing.T) {
+	echoPort := startEchoServer(t)
+	port := freeTestPort(t)
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(reloadTestConfig(t, port, startTunnelServer(t))))
+
+	conn := connectThrough(t, port, localDestination(echoPort))
+	assertEcho(t, conn, "before drain")
+
+	done := make(chan *domain.BypassStats, 1)
+	go func() {
+		stats, err := adapter.Drain("reload", 5*time.Second)
+		assert.NoError(t, err)
+		done <- stats
+	}()
+
+	// Соединение обслуживается, пока клиент его не закроет
+	require.Eventually(t, func() bool { return !adapter.IsRunning("reload") }, time.Second, 10*time.Millisecond)
+	assertEcho(t, conn, "during drain")
+	conn.Close()
+
+	select {
+	case stats := <-done:
+		assert.Equal(t, int64(len("before drain")+len("during drain")), stats.BytesSent)
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain did not finish")
+	}
+
+	_, err := adapter.Drain("reload", 0)
+	assert.Error(t, err)
+}
+
+func TestTLSFragmentAdapter_StopLeavesNoGoroutines(t *testing.T) {
+	echoPort := startEchoServer(t)
+	ignore := goleak.IgnoreCurrent()
+
+	adapter := NewTLSFragmentAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(&domain.BypassConfig{
+		ID:     "tls-fragment-drain",
+		Method: domain.BypassMethodTLSHandshake,
+		Parameters: map[string]string{
+			"local_port":  "0",
+			"remote_host": "127.0.0.1",
+			"remote_port": fmt.Sprintf("%d", echoPort),
+		},
+	}))
+	adapter.mutex.RLock()
+	addr := adapter.running["tls-fragment-drain"].listener.Addr().String()
+	adapter.mutex.RUnlock()
+
+	conn := dialProxy(t, addr)
+	defer conn.Close()
+	assertEcho(t, conn, "not a client hello")
+
+	require.NoError(t, adapter.Stop("tls-fragment-drain"))
+	assertClosed(t, conn)
+	goleak.VerifyNone(t, ignore)
+}
+
+func TestDomainFrontingAdapter_StopLeavesNoGoroutines(t *testing.T) {
+	serverPort, _ := startFrontingServer(t)
+	ignore := goleak.IgnoreCurrent()
+
+	adapter := NewDomainFrontingAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(&domain.BypassConfig{
+		ID:     "fronting-drain",
+		Type:   domain.BypassTypeDomainFronting,
+		Method: domain.BypassMethodHTTPHeader,
+		Parameters: map[string]string{
+			"local_port":            "0",
+			"remote_host":           "127.0.0.1",
+			"remote_port":           fmt.Sprintf("%d", serverPort),
+			"front_domain":          "allowed.cdn.example",
+			"target_host":           "hidden.example",
+			"insecure_skip_verify":  "true",
+			"health_check_interval": "1",
+		},
+	}))
+	adapter.mutex.RLock()
+	addr := adapter.running["fronting-drain"].listener.Addr().String()
+	adapter.mutex.RUnlock()
+
+	resp := frontingGet(t, addr, "ignored.example")
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	require.NoError(t, adapter.Stop("fronting-drain"))
+	goleak.VerifyNone(t, ignore)
+}
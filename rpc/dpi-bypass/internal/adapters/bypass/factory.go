@@ -1,8 +1,12 @@
 package bypass
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/dns"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
 	"go.uber.org/zap"
@@ -10,25 +14,40 @@ import (
 
 // AdapterFactory фабрика для создания адаптеров обфускации
 type AdapterFactory struct {
-	logger *zap.Logger
+	logger   *zap.Logger
+	outcomes OutcomeStore
+	signals  *SignalMonitor
 }
 
 // NewAdapterFactory создает новую фабрику адаптеров
 func NewAdapterFactory(logger *zap.Logger) *AdapterFactory {
 	return &AdapterFactory{
-		logger: logger,
+		logger:   logger,
+		outcomes: NewMemoryOutcomeStore(),
 	}
 }
 
 // CreateAdapter создает адаптер для указанного метода обфускации
 func (f *AdapterFactory) CreateAdapter(method domain.BypassMethod) (ports.BypassAdapter, error) {
+	adapter, err := f.newAdapter(method)
+	if err != nil {
+		return nil, err
+	}
+	if observer, ok := adapter.(signalObserver); ok {
+		observer.observeSignals(f.signals)
+	}
+	return adapter, nil
+}
+
+// newAdapter создает адаптер метода без подключения монитора сигналов
+func (f *AdapterFactory) newAdapter(method domain.BypassMethod) (ports.BypassAdapter, error) {
 	switch method {
 	case domain.BypassMethodHTTPHeader:
-		return NewCustomAdapter(f.logger), nil
+		return NewDomainFrontingAdapter(f.logger), nil
 	case domain.BypassMethodTLSHandshake:
-		return NewCustomAdapter(f.logger), nil
+		return NewTLSFragmentAdapter(f.logger), nil
 	case domain.BypassMethodTCPFragment:
-		return NewCustomAdapter(f.logger), nil
+		return NewTLSFragmentAdapter(f.logger), nil
 	case domain.BypassMethodUDPFragment:
 		return NewCustomAdapter(f.logger), nil
 	case domain.BypassMethodProxyChain:
@@ -41,6 +60,8 @@ func (f *AdapterFactory) CreateAdapter(method domain.BypassMethod) (ports.Bypass
 		return NewObfs4Adapter(f.logger), nil
 	case domain.BypassMethodCustom:
 		return NewCustomAdapter(f.logger), nil
+	case domain.BypassMethodAuto:
+		return NewAutoAdapter(f.logger, f.outcomes), nil
 	default:
 		return nil, fmt.Errorf("unsupported bypass method: %s", method)
 	}
@@ -54,53 +75,241 @@ func (f *AdapterFactory) CreateMultiAdapter() *MultiBypassAdapter {
 // MultiBypassAdapter адаптер для управления несколькими методами обфускации
 type MultiBypassAdapter struct {
 	adapters map[domain.BypassMethod]ports.BypassAdapter
-	logger   *zap.Logger
+	// mutex защищает adapters: соединения запускаются параллельно
+	mutex   sync.RWMutex
+	signals *SignalMonitor
+	// resolver зашифрованный резолвер, dnsRoutes выбор его сервера по
+	// правилам запущенных соединений
+	resolver  *dns.Resolver
+	dnsRoutes *dnsRouter
+	logger    *zap.Logger
 }
 
 // NewMultiBypassAdapter создает новый мульти-адаптер
 func NewMultiBypassAdapter(logger *zap.Logger) *MultiBypassAdapter {
 	return &MultiBypassAdapter{
-		adapters: make(map[domain.BypassMethod]ports.BypassAdapter),
-		logger:   logger,
+		adapters:  make(map[domain.BypassMethod]ports.BypassAdapter),
+		signals:   NewSignalMonitor(logger),
+		dnsRoutes: newDNSRouter(),
+		logger:    logger,
 	}
 }
 
+// SetResolver подключает зашифрованный резолвер. Вызывается до запуска
+// соединений; правила доменов с dns_upstream выбирают его сервер.
+func (m *MultiBypassAdapter) SetResolver(resolver *dns.Resolver) {
+	m.resolver = resolver
+	m.signals.resolver = resolver
+	resolver.SetRouter(m.dnsRoutes.Upstream)
+}
+
 // Start запускает bypass соединение с автоматическим выбором адаптера
 func (m *MultiBypassAdapter) Start(config *domain.BypassConfig) error {
+	if err := validateDNS(config, m.resolver); err != nil {
+		return err
+	}
+	adapter, err := m.adapterFor(config)
+	if err != nil {
+		return err
+	}
+
+	if err := adapter.Start(config); err != nil {
+		return err
+	}
+	m.dnsRoutes.Set(config)
+	return nil
+}
+
+// adapterFor возвращает адаптер метода конфигурации, создавая его при
+// первом обращении
+func (m *MultiBypassAdapter) adapterFor(config *domain.BypassConfig) (ports.BypassAdapter, error) {
+	// Domain fronting всегда обслуживается HTTP адаптером
+	method := config.Method
+	if config.Type == domain.BypassTypeDomainFronting {
+		method = domain.BypassMethodHTTPHeader
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	// Получаем или создаем адаптер для данного метода
-	adapter, exists := m.adapters[config.Method]
+	adapter, exists := m.adapters[method]
 	if !exists {
 		factory := NewAdapterFactory(m.logger)
+		factory.signals = m.signals
 		var err error
-		adapter, err = factory.CreateAdapter(config.Method)
+		adapter, err = factory.CreateAdapter(method)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		m.adapters[config.Method] = adapter
+		m.adapters[method] = adapter
 	}
 
-	return adapter.Start(config)
+	return adapter, nil
+}
+
+// runningAdapter возвращает адаптер, который управляет соединением
+func (m *MultiBypassAdapter) runningAdapter(id string) ports.BypassAdapter {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, adapter := range m.adapters {
+		if adapter.IsRunning(id) {
+			return adapter
+		}
+	}
+	return nil
+}
+
+// Reload применяет обновленную конфигурацию к запущенному соединению: на
+// месте, если адаптер это поддерживает, иначе запуском нового listener с
+// плавным завершением соединений старого не дольше drainTimeout
+func (m *MultiBypassAdapter) Reload(config *domain.BypassConfig, drainTimeout time.Duration) (domain.ReloadOutcome, error) {
+	current := m.runningAdapter(config.ID)
+	if current == nil {
+		return domain.ReloadOutcomeFailed, fmt.Errorf("bypass connection not found: %s", config.ID)
+	}
+	if err := validateDNS(config, m.resolver); err != nil {
+		return domain.ReloadOutcomeFailed, err
+	}
+	next, err := m.adapterFor(config)
+	if err != nil {
+		return domain.ReloadOutcomeFailed, err
+	}
+
+	if reloader, ok := current.(hotReloader); ok && next == current {
+		err := reloader.Reload(config)
+		if err == nil {
+			m.dnsRoutes.Set(config)
+			return domain.ReloadOutcomeApplied, nil
+		}
+		if !errors.Is(err, errRestartRequired) {
+			return domain.ReloadOutcomeFailed, err
+		}
+	}
+
+	outcome, err := m.restart(current, next, config, drainTimeout)
+	if err == nil {
+		m.dnsRoutes.Set(config)
+	}
+	return outcome, err
+}
+
+// restart заменяет соединение новым. Если порт не меняется, старый listener
+// закрывается перед запуском нового. При ошибке запуска восстанавливается
+// прежняя конфигурация.
+func (m *MultiBypassAdapter) restart(current, next ports.BypassAdapter, config *domain.BypassConfig, drainTimeout time.Duration) (domain.ReloadOutcome, error) {
+	d, ok := current.(detacher)
+	if !ok {
+		// Адаптер без плавной замены перезапускается с разрывом соединений
+		if err := current.Stop(config.ID); err != nil {
+			return domain.ReloadOutcomeFailed, err
+		}
+		if err := next.Start(config); err != nil {
+			return domain.ReloadOutcomeFailed, fmt.Errorf("failed to restart bypass: %w", err)
+		}
+		return domain.ReloadOutcomeRestarted, nil
+	}
+
+	old, err := d.Detach(config.ID)
+	if err != nil {
+		return domain.ReloadOutcomeFailed, err
+	}
+	if sameListener(old.Config(), config) {
+		old.StopAccepting()
+	}
+
+	if err := next.Start(config); err != nil {
+		old.StopAccepting()
+		restoreErr := current.Start(old.Config())
+		go m.drain(config.ID, old, drainTimeout)
+		if restoreErr != nil {
+			return domain.ReloadOutcomeFailed, fmt.Errorf("failed to restart bypass: %w (previous configuration not restored: %v)", err, restoreErr)
+		}
+		return domain.ReloadOutcomeFailed, fmt.Errorf("failed to restart bypass: %w", err)
+	}
+
+	go m.drain(config.ID, old, drainTimeout)
+	return domain.ReloadOutcomeRestarted, nil
+}
+
+// sameListener проверяет, что новая конфигурация слушает тот же порт
+func sameListener(current, next *domain.BypassConfig) bool {
+	port := next.Parameters["local_port"]
+	return port != "0" && current.Parameters["local_port"] == port
+}
+
+// drain плавно завершает замененное соединение
+func (m *MultiBypassAdapter) drain(id string, old *detachedConnection, timeout time.Duration) {
+	if aborted := old.Drain(timeout); aborted > 0 {
+		m.logger.Warn("connections aborted after drain timeout",
+			zap.String("id", id),
+			zap.Int64("aborted", aborted),
+			zap.Duration("timeout", timeout))
+		return
+	}
+	m.logger.Info("previous listener drained", zap.String("id", id))
 }
 
 // Stop останавливает bypass соединение
 func (m *MultiBypassAdapter) Stop(id string) error {
 	// Находим адаптер, который управляет данным соединением
-	for _, adapter := range m.adapters {
-		if adapter.IsRunning(id) {
-			return adapter.Stop(id)
+	if adapter := m.runningAdapter(id); adapter != nil {
+		if err := adapter.Stop(id); err != nil {
+			return err
 		}
+		m.dnsRoutes.Delete(id)
+		return nil
 	}
 
 	return fmt.Errorf("bypass connection not found: %s", id)
 }
 
+// Drain плавно останавливает bypass соединение: прекращает прием, ждет
+// текущие соединения не дольше timeout, прерывает оставшиеся и возвращает
+// итоговую статистику
+func (m *MultiBypassAdapter) Drain(id string, timeout time.Duration) (*domain.BypassStats, error) {
+	adapter := m.runningAdapter(id)
+	if adapter == nil {
+		return nil, fmt.Errorf("bypass connection not found: %s", id)
+	}
+
+	d, ok := adapter.(detacher)
+	if !ok {
+		// Адаптер без плавной остановки останавливается с разрывом соединений
+		stats, err := adapter.GetStats(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := adapter.Stop(id); err != nil {
+			return stats, err
+		}
+		if stats != nil {
+			stats.EndTime = time.Now()
+		}
+		m.dnsRoutes.Delete(id)
+		return stats, nil
+	}
+
+	old, err := d.Detach(id)
+	if err != nil {
+		return nil, err
+	}
+	m.dnsRoutes.Delete(id)
+	if aborted := old.Drain(timeout); aborted > 0 {
+		m.logger.Warn("connections aborted after drain timeout",
+			zap.String("id", id),
+			zap.Int64("aborted", aborted),
+			zap.Duration("timeout", timeout))
+	}
+	return old.Stats(), nil
+}
+
 // GetStats возвращает статистику bypass соединения
 func (m *MultiBypassAdapter) GetStats(id string) (*domain.BypassStats, error) {
 	// Находим адаптер, который управляет данным соединением
-	for _, adapter := range m.adapters {
-		if adapter.IsRunning(id) {
-			return adapter.GetStats(id)
-		}
+	if adapter := m.runningAdapter(id); adapter != nil {
+		return adapter.GetStats(id)
 	}
 
 	return nil, nil
@@ -108,12 +317,22 @@ func (m *MultiBypassAdapter) GetStats(id string) (*domain.BypassStats, error) {
 
 // IsRunning проверяет, запущено ли bypass соединение
 func (m *MultiBypassAdapter) IsRunning(id string) bool {
-	// Проверяем все адаптеры
-	for _, adapter := range m.adapters {
-		if adapter.IsRunning(id) {
-			return true
-		}
-	}
+	return m.runningAdapter(id) != nil
+}
+
+// CensorshipSignals возвращает агрегаты сигналов цензуры исходящих соединений
+func (m *MultiBypassAdapter) CensorshipSignals(filters *domain.CensorshipSignalFilters) ([]*domain.CensorshipSignalStats, error) {
+	return m.signals.Stats(filters)
+}
+
+// ExportCensorshipSignals возвращает завершенные к until минутные агрегаты,
+// не подтвержденные ранее
+func (m *MultiBypassAdapter) ExportCensorshipSignals(until time.Time) []*domain.CensorshipSignalStats {
+	return m.signals.Export(until)
+}
 
-	return false
+// AcknowledgeCensorshipSignals отмечает агрегаты, завершенные к until,
+// доставленными
+func (m *MultiBypassAdapter) AcknowledgeCensorshipSignals(until time.Time) {
+	m.signals.Acknowledge(until)
 }
@@ -1,10 +1,13 @@
 package bypass
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -45,6 +48,20 @@ func TestAdapterFactory(t *testing.T) {
 		assert.IsType(t, &CustomAdapter{}, adapter)
 	})
 
+	t.Run("создание адаптера фрагментации TLS", func(t *testing.T) {
+		for _, method := range []domain.BypassMethod{domain.BypassMethodTLSHandshake, domain.BypassMethodTCPFragment} {
+			adapter, err := factory.CreateAdapter(method)
+			assert.NoError(t, err)
+			assert.IsType(t, &TLSFragmentAdapter{}, adapter)
+		}
+	})
+
+	t.Run("создание адаптера domain fronting", func(t *testing.T) {
+		adapter, err := factory.CreateAdapter(domain.BypassMethodHTTPHeader)
+		assert.NoError(t, err)
+		assert.IsType(t, &DomainFrontingAdapter{}, adapter)
+	})
+
 	t.Run("неподдерживаемый метод", func(t *testing.T) {
 		adapter, err := factory.CreateAdapter("unsupported")
 		assert.Error(t, err)
@@ -81,3 +98,39 @@ func TestMultiBypassAdapter(t *testing.T) {
 		assert.Contains(t, err.Error(), "bypass connection not found")
 	})
 }
+
+func TestMultiBypassAdapter_ConcurrentStart(t *testing.T) {
+	echoPort := startEchoServer(t)
+	multiAdapter := NewMultiBypassAdapter(zap.NewNop())
+	methods := []domain.BypassMethod{domain.BypassMethodTLSHandshake, domain.BypassMethodTCPFragment}
+
+	// Соединения разных методов запускаются, проверяются и
+	// останавливаются параллельно
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		config := &domain.BypassConfig{
+			ID:     fmt.Sprintf("concurrent-%d", i),
+			Method: methods[i%len(methods)],
+			Parameters: map[string]string{
+				"local_port":  "0",
+				"remote_host": "127.0.0.1",
+				"remote_port": fmt.Sprintf("%d", echoPort),
+			},
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, multiAdapter.Start(config))
+			assert.True(t, multiAdapter.IsRunning(config.ID))
+			_, err := multiAdapter.GetStats(config.ID)
+			assert.NoError(t, err)
+			assert.NoError(t, multiAdapter.Stop(config.ID))
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, multiAdapter.adapters, len(methods))
+	for i := 0; i < 8; i++ {
+		assert.False(t, multiAdapter.IsRunning(fmt.Sprintf("concurrent-%d", i)))
+	}
+}
@@ -0,0 +1,387 @@
+package bypass
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// inboundMode протокол входящих соединений адаптера
+type inboundMode string
+
+const (
+	inboundRaw    inboundMode = "raw"    // прозрачная пересылка на фиксированный remote
+	inboundSOCKS5 inboundMode = "socks5" // SOCKS5 с CONNECT и UDP ASSOCIATE
+	inboundHTTP   inboundMode = "http"   // HTTP CONNECT и прокси для http:// запросов
+	inboundMixed  inboundMode = "mixed"  // SOCKS5 или HTTP по первому байту
+)
+
+// Значения параметров по умолчанию
+const (
+	defaultInboundHandshakeTimeout = 10 * time.Second
+	defaultInboundListenAddress    = "127.0.0.1"
+	defaultUDPIdleTimeout          = 60 * time.Second
+	inboundDialTimeout             = 10 * time.Second
+)
+
+var (
+	// ErrInboundAuth неверные учетные данные клиента прокси
+	ErrInboundAuth = errors.New("inbound authentication failed")
+	// ErrInboundBlocked назначение заблокировано правилом
+	ErrInboundBlocked = errors.New("destination blocked by rule")
+	// ErrInboundUnsupported команда или тип адреса не поддерживается
+	ErrInboundUnsupported = errors.New("unsupported inbound request")
+)
+
+// inboundConfig параметры входящего прокси
+type inboundConfig struct {
+	Mode inboundMode
+	// ListenAddress адрес listener; пустой у raw - все интерфейсы
+	ListenAddress    string
+	Username         string
+	Password         string
+	HandshakeTimeout time.Duration
+	UDPIdleTimeout   time.Duration
+}
+
+// parseInboundConfig разбирает параметры inbound, listen_address,
+// inbound_username, inbound_password и udp_idle_timeout_ms
+func parseInboundConfig(parameters map[string]string) (inboundConfig, error) {
+	config := inboundConfig{
+		Mode:             inboundMode(parameters["inbound"]),
+		ListenAddress:    parameters["listen_address"],
+		Username:         parameters["inbound_username"],
+		Password:         parameters["inbound_password"],
+		HandshakeTimeout: defaultInboundHandshakeTimeout,
+	}
+
+	switch config.Mode {
+	case "":
+		config.Mode = inboundRaw
+	case inboundRaw, inboundSOCKS5, inboundHTTP, inboundMixed:
+	default:
+		return config, fmt.Errorf("unsupported inbound: %s", config.Mode)
+	}
+
+	if config.Password != "" && config.Username == "" {
+		return config, fmt.Errorf("inbound_password requires inbound_username")
+	}
+	// RFC 1929 ограничивает длину имени и пароля одним байтом
+	if len(config.Username) > 255 || len(config.Password) > 255 {
+		return config, fmt.Errorf("inbound credentials are too long")
+	}
+
+	// Прокси без учетных данных доступен только локально: иначе любой хост
+	// получает открытый прокси во внутренние сети
+	if config.Enabled() {
+		if config.ListenAddress == "" {
+			config.ListenAddress = defaultInboundListenAddress
+		}
+		if !isLoopbackHost(config.ListenAddress) && (config.Username == "" || config.Password == "") {
+			return config, fmt.Errorf("inbound %s on %q requires inbound_username and inbound_password", config.Mode, config.ListenAddress)
+		}
+	}
+
+	timeout, err := parseUDPIdleTimeout(parameters)
+	if err != nil {
+		return config, err
+	}
+	config.UDPIdleTimeout = timeout
+
+	return config, nil
+}
+
+// Enabled сообщает, что адаптер работает как прокси с выбором назначения
+func (c inboundConfig) Enabled() bool {
+	return c.Mode != inboundRaw
+}
+
+// Listen открывает TCP listener адаптера на port
+func (c inboundConfig) Listen(port string) (net.Listener, error) {
+	return net.Listen("tcp", c.Address(port))
+}
+
+// Address возвращает адрес listener на port
+func (c inboundConfig) Address(port string) string {
+	return net.JoinHostPort(c.ListenAddress, port)
+}
+
+// authenticate проверяет учетные данные, если они заданы. Сравнение не
+// зависит от позиции первого несовпадающего байта
+func (c inboundConfig) authenticate(username, password string) bool {
+	if c.Username == "" {
+		return true
+	}
+	usernameOK := subtle.ConstantTimeCompare([]byte(username), []byte(c.Username))
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(c.Password))
+	return usernameOK&passwordOK == 1
+}
+
+// isLoopbackHost проверяет, что адрес доступен только с этого хоста
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// proxyDestination назначение, запрошенное клиентом прокси
+type proxyDestination struct {
+	Host string // доменное имя или IP адрес
+	Port int
+}
+
+// Address возвращает host:port
+func (d proxyDestination) Address() string {
+	return net.JoinHostPort(d.Host, strconv.Itoa(d.Port))
+}
+
+// ruleTarget описывает назначение для движка правил
+func (d proxyDestination) ruleTarget(protocol string) ruleTarget {
+	target := ruleTarget{Port: d.Port, Protocol: protocol}
+	if ip := net.ParseIP(d.Host); ip != nil {
+		target.IP = ip
+	} else {
+		target.Host = d.Host
+	}
+	return target
+}
+
+// inboundRequest запрос клиента после рукопожатия
+type inboundRequest struct {
+	// Conn клиентское соединение; буферизованные после рукопожатия данные
+	// (например, http:// запрос) читаются из него первыми
+	Conn        net.Conn
+	Destination proxyDestination
+	UDP         bool
+	Rule        *domain.BypassRule
+	reply       func(err error) error
+}
+
+// Reply сообщает клиенту результат подключения к назначению
+func (r *inboundRequest) Reply(err error) error {
+	if r.reply == nil {
+		return nil
+	}
+	reply := r.reply
+	r.reply = nil
+	return reply(err)
+}
+
+// inboundFrontend общий входящий слой адаптеров: рукопожатие SOCKS5/HTTP,
+// правила для запрошенного назначения, прямые соединения и UDP ASSOCIATE
+type inboundFrontend struct {
+	config inboundConfig
+	rules  *ruleMatcher
+	logger *zap.Logger
+	id     string
+	// packets создает исходящий канал для UDP ASSOCIATE
+	packets func() (packetOutbound, error)
+	// Счетчики адаптера
+	onTraffic func(rx, tx int64)
+	onError   func()
+}
+
+// newInboundFrontend создает входящий слой или nil для режима raw
+func newInboundFrontend(config inboundConfig, rules *ruleMatcher, id string, logger *zap.Logger) *inboundFrontend {
+	if !config.Enabled() {
+		return nil
+	}
+	return &inboundFrontend{
+		config:    config,
+		rules:     rules,
+		logger:    logger,
+		id:        id,
+		packets:   newDirectPacketOutbound,
+		onTraffic: func(rx, tx int64) {},
+		onError:   func() {},
+	}
+}
+
+// accept выполняет рукопожатие и применяет правила. Возвращает запрос,
+// который должен обслужить метод обхода; ok=false, если запрос уже обслужен
+// (UDP ASSOCIATE, блокировка, прямое соединение) или рукопожатие не удалось.
+// Ответ клиенту отправляет вызывающий через Reply.
+func (f *inboundFrontend) accept(ctx context.Context, clientConn net.Conn) (*inboundRequest, bool) {
+	request, err := f.handshake(clientConn)
+	if err != nil {
+		f.logger.Debug("inbound handshake failed", zap.Error(err), zap.String("id", f.id))
+		f.onError()
+		return nil, false
+	}
+
+	if request.UDP {
+		f.serveUDPAssociate(ctx, request)
+		return nil, false
+	}
+
+	request.Rule = f.rules.Match(request.Destination.ruleTarget("tcp"))
+	if request.Rule != nil {
+		switch request.Rule.Action {
+		case domain.RuleActionBlock:
+			f.logger.Debug("destination blocked by rule",
+				zap.String("id", f.id),
+				zap.String("rule", request.Rule.ID),
+				zap.String("destination", request.Destination.Address()))
+			_ = request.Reply(ErrInboundBlocked)
+			return nil, false
+		case domain.RuleActionAllow:
+			// Разрешенные назначения идут напрямую, минуя метод обхода
+			f.relayDirect(ctx, request)
+			return nil, false
+		}
+	}
+
+	return request, true
+}
+
+// serve выполняет accept, подключается к назначению через dial и отвечает
+// клиенту. Возвращает клиентское соединение и соединение для пересылки.
+func (f *inboundFrontend) serve(ctx context.Context, clientConn net.Conn, dial func(destination proxyDestination) (net.Conn, error)) (net.Conn, net.Conn, bool) {
+	request, ok := f.accept(ctx, clientConn)
+	if !ok {
+		return nil, nil, false
+	}
+
+	remoteConn, err := dial(request.Destination)
+	if err != nil {
+		f.logger.Debug("failed to connect to destination",
+			zap.Error(err),
+			zap.String("id", f.id),
+			zap.String("destination", request.Destination.Address()))
+		f.onError()
+		_ = request.Reply(err)
+		return nil, nil, false
+	}
+
+	if err := request.Reply(nil); err != nil {
+		remoteConn.Close()
+		return nil, nil, false
+	}
+	return request.Conn, remoteConn, true
+}
+
+// handshake определяет протокол и выполняет рукопожатие
+func (f *inboundFrontend) handshake(clientConn net.Conn) (*inboundRequest, error) {
+	if err := clientConn.SetDeadline(time.Now().Add(f.config.HandshakeTimeout)); err != nil {
+		return nil, err
+	}
+
+	conn := newBufferedConn(clientConn)
+
+	mode := f.config.Mode
+	if mode == inboundMixed {
+		first, err := conn.reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		mode = inboundHTTP
+		if first[0] == socks5Version {
+			mode = inboundSOCKS5
+		}
+	}
+
+	var request *inboundRequest
+	var err error
+	if mode == inboundSOCKS5 {
+		request, err = f.handshakeSOCKS5(conn)
+	} else {
+		request, err = f.handshakeHTTP(conn)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := clientConn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// relayDirect подключается к назначению без метода обхода и пересылает данные
+func (f *inboundFrontend) relayDirect(ctx context.Context, request *inboundRequest) {
+	remoteConn, err := (&net.Dialer{Timeout: inboundDialTimeout}).DialContext(ctx, "tcp", request.Destination.Address())
+	if err != nil {
+		f.onError()
+		_ = request.Reply(err)
+		return
+	}
+	defer remoteConn.Close()
+
+	if err := request.Reply(nil); err != nil {
+		return
+	}
+
+	_ = relayConns(ctx, request.Conn, remoteConn,
+		func() error {
+			n, err := io.Copy(remoteConn, request.Conn)
+			f.onTraffic(n, 0)
+			return err
+		},
+		func() error {
+			n, err := io.Copy(request.Conn, remoteConn)
+			f.onTraffic(0, n)
+			return err
+		})
+}
+
+// bufferedConn соединение, чтение из которого идет через bufio.Reader
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func newBufferedConn(conn net.Conn) *bufferedConn {
+	return &bufferedConn{Conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// prefixConn соединение, которое сначала отдает prefix, затем данные Conn
+type prefixConn struct {
+	net.Conn
+	prefix []byte
+}
+
+func (c *prefixConn) Read(p []byte) (int, error) {
+	if len(c.prefix) > 0 {
+		n := copy(p, c.prefix)
+		c.prefix = c.prefix[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
+
+// inboundErrorKind классифицирует ошибку подключения для ответа клиенту
+func inboundErrorKind(err error) byte {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	switch {
+	case err == nil:
+		return socks5ReplySucceeded
+	case errors.Is(err, ErrInboundBlocked):
+		return socks5ReplyNotAllowed
+	case errors.Is(err, ErrInboundUnsupported):
+		return socks5ReplyCommandNotSupported
+	case errors.As(err, &dnsErr):
+		return socks5ReplyHostUnreachable
+	case isConnectionRefused(err):
+		return socks5ReplyConnectionRefused
+	case errors.As(err, &opErr) && opErr.Timeout():
+		return socks5ReplyTTLExpired
+	default:
+		return socks5ReplyGeneralFailure
+	}
+}
@@ -0,0 +1,102 @@
+package bypass
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// connectThroughInbound запрашивает у локального прокси соединение с
+// назначением по протоколу, которым адаптер принимает клиентов
+func connectThroughInbound(conn net.Conn, config inboundConfig, destination proxyDestination) (net.Conn, error) {
+	switch config.Mode {
+	case inboundSOCKS5, inboundMixed:
+		if err := socks5Connect(conn, destination, config.Username, config.Password); err != nil {
+			return nil, err
+		}
+		return conn, nil
+	case inboundHTTP:
+		return httpConnect(conn, destination, config.Username, config.Password)
+	default:
+		return conn, nil
+	}
+}
+
+// socks5Connect выполняет рукопожатие SOCKS5 клиента и команду CONNECT
+func socks5Connect(conn net.Conn, destination proxyDestination, username, password string) error {
+	method := byte(socks5AuthNone)
+	if username != "" {
+		method = socks5AuthPassword
+	}
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return err
+	}
+
+	selected := make([]byte, 2)
+	if _, err := io.ReadFull(conn, selected); err != nil {
+		return err
+	}
+	if selected[1] != method {
+		return fmt.Errorf("%w: socks method rejected", ErrInboundAuth)
+	}
+
+	if method == socks5AuthPassword {
+		auth := []byte{socks5PasswordVersion, byte(len(username))}
+		auth = append(auth, username...)
+		auth = append(auth, byte(len(password)))
+		auth = append(auth, password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		status := make([]byte, 2)
+		if _, err := io.ReadFull(conn, status); err != nil {
+			return err
+		}
+		if status[1] != 0 {
+			return ErrInboundAuth
+		}
+	}
+
+	request := appendSocksAddr([]byte{socks5Version, socks5CommandConnect, 0x00}, destination)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 3)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if _, err := readSocksAddr(conn); err != nil {
+		return err
+	}
+	if reply[1] != socks5ReplySucceeded {
+		return fmt.Errorf("socks connect failed: reply %d", reply[1])
+	}
+	return nil
+}
+
+// httpConnect выполняет HTTP CONNECT. Возвращает соединение, из которого
+// читаются данные назначения, включая прочитанные вместе с ответом.
+func httpConnect(conn net.Conn, destination proxyDestination, username, password string) (net.Conn, error) {
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", destination.Address(), destination.Address())
+	if username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		request += "Proxy-Authorization: Basic " + credentials + "\r\n"
+	}
+	request += "\r\n"
+	if _, err := io.WriteString(conn, request); err != nil {
+		return nil, err
+	}
+
+	buffered := newBufferedConn(conn)
+	resp, err := http.ReadResponse(buffered.reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http connect failed: %s", resp.Status)
+	}
+	return buffered, nil
+}
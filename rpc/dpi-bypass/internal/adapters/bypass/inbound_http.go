@@ -0,0 +1,148 @@
+package bypass
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// handshakeHTTP читает запрос HTTP прокси: CONNECT или запрос с абсолютным
+// http:// URL
+func (f *inboundFrontend) handshakeHTTP(conn *bufferedConn) (*inboundRequest, error) {
+	req, err := http.ReadRequest(conn.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if !f.authorizeHTTP(req.Header) {
+		_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n"+
+			"Proxy-Authenticate: Basic realm=\"dpi-bypass\"\r\nContent-Length: 0\r\n\r\n")
+		return nil, ErrInboundAuth
+	}
+
+	if req.Method == http.MethodConnect {
+		destination, err := parseHTTPDestination(req.Host, 443)
+		if err != nil {
+			writeHTTPError(conn, http.StatusBadRequest)
+			return nil, err
+		}
+
+		request := &inboundRequest{Conn: conn, Destination: destination}
+		request.reply = func(err error) error {
+			if err != nil {
+				writeHTTPError(conn, httpStatusForError(err))
+				return nil
+			}
+			_, err = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+			return err
+		}
+		return request, nil
+	}
+
+	if req.URL.Scheme != "http" || req.URL.Host == "" {
+		writeHTTPError(conn, http.StatusBadRequest)
+		return nil, fmt.Errorf("%w: http proxy request without absolute url", ErrInboundUnsupported)
+	}
+
+	destination, err := parseHTTPDestination(req.URL.Host, 80)
+	if err != nil {
+		writeHTTPError(conn, http.StatusBadRequest)
+		return nil, err
+	}
+
+	// Запрос пересылается назначению в origin-form; тело остается в потоке
+	// клиента и пересылается как есть
+	request := &inboundRequest{
+		Conn:        &prefixConn{Conn: conn, prefix: originRequestHeader(req)},
+		Destination: destination,
+	}
+	request.reply = func(err error) error {
+		if err != nil {
+			writeHTTPError(conn, httpStatusForError(err))
+		}
+		return nil
+	}
+	return request, nil
+}
+
+// authorizeHTTP проверяет заголовок Proxy-Authorization, если заданы учетные данные
+func (f *inboundFrontend) authorizeHTTP(header http.Header) bool {
+	return authorizeProxyHeader(f.config, header)
+}
+
+// authorizeProxyHeader проверяет Basic авторизацию прокси
+func authorizeProxyHeader(config inboundConfig, header http.Header) bool {
+	if config.Username == "" {
+		return true
+	}
+
+	scheme, encoded, ok := strings.Cut(header.Get("Proxy-Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Basic") {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return false
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	return ok && config.authenticate(username, password)
+}
+
+// originRequestHeader собирает строку запроса и заголовки для назначения.
+// Заголовки прокси удаляются, соединение закрывается после ответа, чтобы
+// следующий запрос клиента снова прошел через прокси.
+func originRequestHeader(req *http.Request) []byte {
+	header := req.Header.Clone()
+	header.Del("Proxy-Authorization")
+	header.Del("Proxy-Connection")
+	header.Set("Connection", "close")
+	if len(req.TransferEncoding) > 0 {
+		header.Set("Transfer-Encoding", strings.Join(req.TransferEncoding, ", "))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\nHost: %s\r\n", req.Method, req.URL.RequestURI(), req.Host)
+	_ = header.Write(&buf)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// parseHTTPDestination разбирает host[:port] с портом по умолчанию
+func parseHTTPDestination(hostport string, defaultPort int) (proxyDestination, error) {
+	host, portValue, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, portValue = strings.Trim(hostport, "[]"), strconv.Itoa(defaultPort)
+	}
+	port, err := strconv.Atoi(portValue)
+	if host == "" || err != nil || port <= 0 || port > 65535 {
+		return proxyDestination{}, fmt.Errorf("invalid proxy destination: %s", hostport)
+	}
+	return proxyDestination{Host: host, Port: port}, nil
+}
+
+// httpStatusForError выбирает код ответа HTTP прокси
+func httpStatusForError(err error) int {
+	switch inboundErrorKind(err) {
+	case socks5ReplyNotAllowed:
+		return http.StatusForbidden
+	case socks5ReplyTTLExpired:
+		return http.StatusGatewayTimeout
+	default:
+		if errors.Is(err, ErrInboundUnsupported) {
+			return http.StatusNotImplemented
+		}
+		return http.StatusBadGateway
+	}
+}
+
+// writeHTTPError отправляет короткий ответ с ошибкой
+func writeHTTPError(w io.Writer, status int) {
+	_, _ = fmt.Fprintf(w, "HTTP/1.1 %d %s\r\nContent-Length: 0\r\nConnection: close\r\n\r\n",
+		status, http.StatusText(status))
+}
@@ -0,0 +1,262 @@
+package bypass
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// Константы протокола SOCKS5 (RFC 1928, RFC 1929)
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone         = 0x00
+	socks5AuthPassword     = 0x02
+	socks5AuthNoAcceptable = 0xff
+
+	socks5PasswordVersion = 0x01
+
+	socks5CommandConnect      = 0x01
+	socks5CommandUDPAssociate = 0x03
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5ReplySucceeded           = 0x00
+	socks5ReplyGeneralFailure      = 0x01
+	socks5ReplyNotAllowed          = 0x02
+	socks5ReplyHostUnreachable     = 0x04
+	socks5ReplyConnectionRefused   = 0x05
+	socks5ReplyTTLExpired          = 0x06
+	socks5ReplyCommandNotSupported = 0x07
+	socks5ReplyAddressNotSupported = 0x08
+)
+
+// handshakeSOCKS5 выполняет выбор метода, аутентификацию и читает запрос
+func (f *inboundFrontend) handshakeSOCKS5(conn *bufferedConn) (*inboundRequest, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	if header[0] != socks5Version {
+		return nil, fmt.Errorf("unsupported socks version: %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return nil, err
+	}
+
+	method := byte(socks5AuthNone)
+	if f.config.Username != "" {
+		method = socks5AuthPassword
+	}
+	offered := false
+	for _, m := range methods {
+		if m == method {
+			offered = true
+			break
+		}
+	}
+	if !offered {
+		_, _ = conn.Write([]byte{socks5Version, socks5AuthNoAcceptable})
+		return nil, fmt.Errorf("%w: no acceptable socks auth method", ErrInboundAuth)
+	}
+	if _, err := conn.Write([]byte{socks5Version, method}); err != nil {
+		return nil, err
+	}
+
+	if method == socks5AuthPassword {
+		if err := f.authenticateSOCKS5(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	request := make([]byte, 3)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return nil, err
+	}
+	if request[0] != socks5Version {
+		return nil, fmt.Errorf("unsupported socks version: %d", request[0])
+	}
+
+	destination, err := readSocksAddr(conn)
+	if err != nil {
+		if errors.Is(err, ErrInboundUnsupported) {
+			_ = writeSOCKS5Reply(conn, socks5ReplyAddressNotSupported, nil)
+		}
+		return nil, err
+	}
+
+	switch request[1] {
+	case socks5CommandConnect:
+	case socks5CommandUDPAssociate:
+	default:
+		_ = writeSOCKS5Reply(conn, socks5ReplyCommandNotSupported, nil)
+		return nil, fmt.Errorf("%w: socks command %d", ErrInboundUnsupported, request[1])
+	}
+
+	inbound := &inboundRequest{
+		Conn:        conn,
+		Destination: destination,
+		UDP:         request[1] == socks5CommandUDPAssociate,
+	}
+	inbound.reply = func(err error) error {
+		// В ответе указывается адрес прокси, а не исходящего соединения
+		return writeSOCKS5Reply(conn, inboundErrorKind(err), conn.LocalAddr())
+	}
+	return inbound, nil
+}
+
+// authenticateSOCKS5 проверяет имя и пароль по RFC 1929
+func (f *inboundFrontend) authenticateSOCKS5(conn *bufferedConn) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[0] != socks5PasswordVersion {
+		return fmt.Errorf("unsupported socks auth version: %d", header[0])
+	}
+	username := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, username); err != nil {
+		return err
+	}
+	length := make([]byte, 1)
+	if _, err := io.ReadFull(conn, length); err != nil {
+		return err
+	}
+	password := make([]byte, length[0])
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return err
+	}
+
+	if !f.config.authenticate(string(username), string(password)) {
+		_, _ = conn.Write([]byte{socks5PasswordVersion, 0x01})
+		return ErrInboundAuth
+	}
+	_, err := conn.Write([]byte{socks5PasswordVersion, 0x00})
+	return err
+}
+
+// writeSOCKS5Reply отправляет ответ на запрос с адресом bound
+func writeSOCKS5Reply(w io.Writer, code byte, bound net.Addr) error {
+	destination := proxyDestination{Host: "0.0.0.0"}
+	if host, port, err := net.SplitHostPort(addrString(bound)); err == nil {
+		destination.Host = host
+		destination.Port, _ = strconv.Atoi(port)
+	}
+
+	reply := []byte{socks5Version, code, 0x00}
+	reply = appendSocksAddr(reply, destination)
+	_, err := w.Write(reply)
+	return err
+}
+
+// readSocksAddr читает адрес в формате SOCKS5: ATYP, адрес, порт
+func readSocksAddr(r io.Reader) (proxyDestination, error) {
+	var destination proxyDestination
+
+	atyp := make([]byte, 1)
+	if _, err := io.ReadFull(r, atyp); err != nil {
+		return destination, err
+	}
+
+	switch atyp[0] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		size := net.IPv4len
+		if atyp[0] == socks5AddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return destination, err
+		}
+		destination.Host = net.IP(ip).String()
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(r, length); err != nil {
+			return destination, err
+		}
+		host := make([]byte, length[0])
+		if _, err := io.ReadFull(r, host); err != nil {
+			return destination, err
+		}
+		destination.Host = string(host)
+	default:
+		return destination, fmt.Errorf("%w: socks address type %d", ErrInboundUnsupported, atyp[0])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(r, port); err != nil {
+		return destination, err
+	}
+	destination.Port = int(binary.BigEndian.Uint16(port))
+	return destination, nil
+}
+
+// parseSocksAddr разбирает адрес SOCKS5 из начала буфера и возвращает его длину
+func parseSocksAddr(buf []byte) (proxyDestination, int, error) {
+	reader := &countingReader{data: buf}
+	destination, err := readSocksAddr(reader)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return destination, 0, fmt.Errorf("truncated socks address")
+		}
+		return destination, 0, err
+	}
+	return destination, reader.offset, nil
+}
+
+// appendSocksAddr добавляет адрес в формате SOCKS5. Тот же формат используется
+// заголовком назначения, который туннельные методы передают удаленной стороне.
+func appendSocksAddr(dst []byte, destination proxyDestination) []byte {
+	if ip := net.ParseIP(destination.Host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			dst = append(dst, socks5AddrIPv4)
+			dst = append(dst, ip4...)
+		} else {
+			dst = append(dst, socks5AddrIPv6)
+			dst = append(dst, ip.To16()...)
+		}
+	} else {
+		host := destination.Host
+		if len(host) > 255 {
+			host = host[:255]
+		}
+		dst = append(dst, socks5AddrDomain, byte(len(host)))
+		dst = append(dst, host...)
+	}
+	return binary.BigEndian.AppendUint16(dst, uint16(destination.Port))
+}
+
+// countingReader читает из буфера и считает прочитанные байты
+type countingReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	if r.offset >= len(r.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[r.offset:])
+	r.offset += n
+	return n, nil
+}
+
+// isConnectionRefused проверяет отказ в соединении
+func isConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// addrString возвращает строку адреса или пустую строку для nil
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
@@ -0,0 +1,548 @@
+package bypass
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// tunnelTestPSK PSK сервера туннеля startTunnelServer
+var tunnelTestPSK = testKey(9, 32)
+
+// startTunnelServer запускает удаленную сторону туннеля: принимает сессию
+// SIP022 с PSK tunnelTestPSK и пересылает данные назначению или обслуживает
+// поток UDP-over-stream
+func startTunnelServer(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	psk, err := base64.StdEncoding.DecodeString(tunnelTestPSK)
+	require.NoError(t, err)
+	salts := newReplayCache(shadowsocks2022SaltTTL)
+
+	go func() {
+		for {
+			accepted, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer accepted.Close()
+				conn, destination, err := acceptShadowsocks2022(accepted, psk, salts, nil)
+				if err != nil {
+					return
+				}
+				if destination.Host == udpOverStreamHost {
+					serveUDPOverStream(context.Background(), conn, udpStreamServerOptions{
+						Rules:       newRuleMatcher(nil),
+						IdleTimeout: time.Second,
+						Logger:      zap.NewNop(),
+						OnTraffic:   func(rx, tx int64) {},
+					})
+					return
+				}
+				remote, err := net.Dial("tcp", destination.Address())
+				if err != nil {
+					return
+				}
+				defer remote.Close()
+				go func() { _, _ = io.Copy(remote, conn) }()
+				_, _ = io.Copy(conn, remote)
+			}()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// startUDPEchoServer запускает UDP echo-сервер и возвращает его порт
+func startUDPEchoServer(t *testing.T) int {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, maxUDPPacketSize)
+		for {
+			n, addr, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteToUDP(buffer[:n], addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// startShadowsocksInbound запускает Shadowsocks адаптер с входящим прокси
+func startShadowsocksInbound(t *testing.T, id string, remotePort int, params map[string]string, rules ...*domain.BypassRule) string {
+	t.Helper()
+
+	parameters := map[string]string{
+		"local_port":  "0",
+		"remote_host": "127.0.0.1",
+		"remote_port": strconv.Itoa(remotePort),
+		"password":    tunnelTestPSK,
+	}
+	for key, value := range params {
+		parameters[key] = value
+	}
+
+	adapter := NewShadowsocksAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(&domain.BypassConfig{
+		ID:         id,
+		Method:     domain.BypassMethodShadowsocks,
+		Parameters: parameters,
+		Rules:      rules,
+	}))
+	t.Cleanup(func() { _ = adapter.Stop(id) })
+
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+	return adapter.running[id].listener.Addr().String()
+}
+
+// dialProxy подключается к локальному прокси адаптера
+func dialProxy(t *testing.T, addr string) net.Conn {
+	t.Helper()
+
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	return conn
+}
+
+// assertEcho проверяет, что данные возвращаются без изменений
+func assertEcho(t *testing.T, conn net.Conn, payload string) {
+	t.Helper()
+
+	_, err := io.WriteString(conn, payload)
+	require.NoError(t, err)
+	reply := make([]byte, len(payload))
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(reply))
+}
+
+func localDestination(port int) proxyDestination {
+	return proxyDestination{Host: "127.0.0.1", Port: port}
+}
+
+func TestParseInboundConfig(t *testing.T) {
+	config, err := parseInboundConfig(map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, inboundRaw, config.Mode)
+	assert.False(t, config.Enabled())
+	assert.Equal(t, defaultUDPIdleTimeout, config.UDPIdleTimeout)
+
+	config, err = parseInboundConfig(map[string]string{
+		"inbound":             "mixed",
+		"inbound_username":    "user",
+		"inbound_password":    "secret",
+		"udp_idle_timeout_ms": "1500",
+	})
+	require.NoError(t, err)
+	assert.True(t, config.Enabled())
+	assert.Equal(t, 1500*time.Millisecond, config.UDPIdleTimeout)
+	assert.True(t, config.authenticate("user", "secret"))
+	assert.False(t, config.authenticate("user", "wrong"))
+	assert.False(t, config.authenticate("use", "secret"))
+
+	// Прокси по умолчанию слушает только loopback, raw - все интерфейсы
+	assert.Equal(t, "127.0.0.1", config.ListenAddress)
+	config, err = parseInboundConfig(map[string]string{"inbound": "socks5"})
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:1080", config.Address("1080"))
+	config, err = parseInboundConfig(map[string]string{"inbound": "http", "listen_address": "::1"})
+	require.NoError(t, err)
+	assert.Equal(t, "[::1]:1080", config.Address("1080"))
+	config, err = parseInboundConfig(map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, ":1080", config.Address("1080"))
+
+	// Внешний адрес допустим только с учетными данными
+	config, err = parseInboundConfig(map[string]string{
+		"inbound":          "mixed",
+		"listen_address":   "0.0.0.0",
+		"inbound_username": "user",
+		"inbound_password": "secret",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:1080", config.Address("1080"))
+
+	for name, params := range map[string]map[string]string{
+		"протокол":                  {"inbound": "ftp"},
+		"пароль без имени":          {"inbound": "socks5", "inbound_password": "secret"},
+		"таймаут":                   {"inbound": "socks5", "udp_idle_timeout_ms": "0"},
+		"внешний адрес без пароля":  {"inbound": "socks5", "listen_address": "0.0.0.0", "inbound_username": "user"},
+		"все интерфейсы без данных": {"inbound": "http", "listen_address": "::"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseInboundConfig(params)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestInbound_ListensOnLoopback(t *testing.T) {
+	addr := startShadowsocksInbound(t, "inbound-loopback", startTunnelServer(t), map[string]string{"inbound": "socks5"})
+
+	host, _, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+}
+
+func TestSocksAddr_RoundTrip(t *testing.T) {
+	for _, destination := range []proxyDestination{
+		{Host: "127.0.0.1", Port: 80},
+		{Host: "2001:db8::1", Port: 443},
+		{Host: "example.com", Port: 8443},
+	} {
+		encoded := appendSocksAddr(nil, destination)
+		decoded, size, err := parseSocksAddr(append(encoded, "payload"...))
+		require.NoError(t, err)
+		assert.Equal(t, destination, decoded)
+		assert.Equal(t, len(encoded), size)
+	}
+
+	_, _, err := parseSocksAddr([]byte{socks5AddrDomain, 10, 'a'})
+	assert.Error(t, err)
+	_, _, err = parseSocksAddr([]byte{0x09, 0, 0})
+	assert.ErrorIs(t, err, ErrInboundUnsupported)
+}
+
+func TestInbound_SOCKS5ConnectWithAuth(t *testing.T) {
+	echoPort := startEchoServer(t)
+	addr := startShadowsocksInbound(t, "inbound-socks", startTunnelServer(t), map[string]string{
+		"inbound":          "socks5",
+		"inbound_username": "user",
+		"inbound_password": "secret",
+	})
+
+	conn := dialProxy(t, addr)
+	require.NoError(t, socks5Connect(conn, localDestination(echoPort), "user", "secret"))
+	assertEcho(t, conn, "hello through socks")
+
+	conn = dialProxy(t, addr)
+	assert.ErrorIs(t, socks5Connect(conn, localDestination(echoPort), "user", "wrong"), ErrInboundAuth)
+
+	conn = dialProxy(t, addr)
+	assert.ErrorIs(t, socks5Connect(conn, localDestination(echoPort), "", ""), ErrInboundAuth)
+}
+
+func TestInbound_HTTPConnect(t *testing.T) {
+	echoPort := startEchoServer(t)
+
+	for _, mode := range []string{"http", "mixed"} {
+		t.Run(mode, func(t *testing.T) {
+			addr := startShadowsocksInbound(t, "inbound-http-"+mode, startTunnelServer(t), map[string]string{
+				"inbound":          mode,
+				"inbound_username": "user",
+				"inbound_password": "secret",
+			})
+
+			conn := dialProxy(t, addr)
+			tunnel, err := httpConnect(conn, localDestination(echoPort), "user", "secret")
+			require.NoError(t, err)
+			assertEcho(t, tunnel, "hello through connect")
+
+			conn = dialProxy(t, addr)
+			_, err = httpConnect(conn, localDestination(echoPort), "user", "wrong")
+			assert.ErrorContains(t, err, "407")
+		})
+	}
+
+	t.Run("mixed socks5", func(t *testing.T) {
+		addr := startShadowsocksInbound(t, "inbound-mixed-socks", startTunnelServer(t), map[string]string{
+			"inbound": "mixed",
+		})
+		conn := dialProxy(t, addr)
+		require.NoError(t, socks5Connect(conn, localDestination(echoPort), "", ""))
+		assertEcho(t, conn, "mixed socks")
+	})
+}
+
+func TestInbound_HTTPAbsoluteForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Proxy-Authorization"))
+		fmt.Fprintf(w, "%s %s", r.Method, r.URL.RequestURI())
+	}))
+	t.Cleanup(server.Close)
+
+	addr := startShadowsocksInbound(t, "inbound-http-absolute", startTunnelServer(t), map[string]string{
+		"inbound":          "http",
+		"inbound_username": "user",
+		"inbound_password": "secret",
+	})
+
+	proxyURL, err := url.Parse("http://user:secret@" + addr)
+	require.NoError(t, err)
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+		Timeout:   5 * time.Second,
+	}
+
+	resp, err := client.Get(server.URL + "/path?query=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "GET /path?query=1", string(body))
+}
+
+func TestInbound_Rules(t *testing.T) {
+	echoPort := startEchoServer(t)
+
+	// Удаленный сервер недоступен: allow идет напрямую, остальное через метод
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	addr := startShadowsocksInbound(t, "inbound-rules", closedPort, map[string]string{"inbound": "socks5"},
+		&domain.BypassRule{ID: "block", Type: domain.RuleTypeDomain, Action: domain.RuleActionBlock, Pattern: "blocked.test", Priority: 2, Enabled: true},
+		&domain.BypassRule{ID: "allow", Type: domain.RuleTypePort, Action: domain.RuleActionAllow, Pattern: strconv.Itoa(echoPort), Priority: 1, Enabled: true},
+	)
+
+	conn := dialProxy(t, addr)
+	err = socks5Connect(conn, proxyDestination{Host: "blocked.test", Port: echoPort}, "", "")
+	assert.ErrorContains(t, err, fmt.Sprintf("reply %d", socks5ReplyNotAllowed))
+
+	conn = dialProxy(t, addr)
+	require.NoError(t, socks5Connect(conn, localDestination(echoPort), "", ""))
+	assertEcho(t, conn, "direct")
+
+	conn = dialProxy(t, addr)
+	err = socks5Connect(conn, localDestination(echoPort+1), "", "")
+	assert.ErrorContains(t, err, fmt.Sprintf("reply %d", socks5ReplyConnectionRefused))
+}
+
+func TestInbound_SOCKS5UDPAssociate(t *testing.T) {
+	udpPort := startUDPEchoServer(t)
+	addr := startShadowsocksInbound(t, "inbound-udp", startTunnelServer(t), map[string]string{
+		"inbound":             "socks5",
+		"udp_idle_timeout_ms": "2000",
+	}, &domain.BypassRule{ID: "block", Type: domain.RuleTypePort, Action: domain.RuleActionBlock, Pattern: "9", Enabled: true})
+
+	control := dialProxy(t, addr)
+	_, err := control.Write([]byte{socks5Version, 1, socks5AuthNone})
+	require.NoError(t, err)
+	selected := make([]byte, 2)
+	_, err = io.ReadFull(control, selected)
+	require.NoError(t, err)
+
+	request := appendSocksAddr([]byte{socks5Version, socks5CommandUDPAssociate, 0x00}, proxyDestination{Host: "0.0.0.0"})
+	_, err = control.Write(request)
+	require.NoError(t, err)
+	reply := make([]byte, 3)
+	_, err = io.ReadFull(control, reply)
+	require.NoError(t, err)
+	require.Equal(t, byte(socks5ReplySucceeded), reply[1])
+	relay, err := readSocksAddr(control)
+	require.NoError(t, err)
+
+	client, err := net.Dial("udp", relay.Address())
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.SetDeadline(time.Now().Add(5*time.Second)))
+
+	// Заблокированное назначение отбрасывается, ответ приходит только от echo
+	blocked := appendSOCKS5UDPHeader(nil, localDestination(9))
+	_, err = client.Write(append(blocked, "dropped"...))
+	require.NoError(t, err)
+
+	packet := appendSOCKS5UDPHeader(nil, localDestination(udpPort))
+	_, err = client.Write(append(packet, "datagram"...))
+	require.NoError(t, err)
+
+	buffer := make([]byte, maxUDPPacketSize)
+	n, err := client.Read(buffer)
+	require.NoError(t, err)
+	source, payload, err := parseSOCKS5UDPPacket(buffer[:n])
+	require.NoError(t, err)
+	assert.Equal(t, localDestination(udpPort), source)
+	assert.Equal(t, "datagram", string(payload))
+
+	// Закрытие управляющего соединения завершает ассоциацию
+	control.Close()
+	require.Eventually(t, func() bool {
+		_, _ = client.Write(append(packet, "late"...))
+		_ = client.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		_, err := client.Read(buffer)
+		return err != nil && !isTimeout(err)
+	}, 3*time.Second, 100*time.Millisecond)
+}
+
+// isTimeout проверяет, что ошибка вызвана таймаутом
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+func TestInbound_CustomClientToDestinationHeaderServer(t *testing.T) {
+	logger := zap.NewNop()
+	echoPort := startEchoServer(t)
+
+	server := NewCustomAdapter(logger)
+	require.NoError(t, server.Start(&domain.BypassConfig{
+		ID:     "custom-dest-server",
+		Method: domain.BypassMethodCustom,
+		Parameters: map[string]string{
+			"local_port":         "0",
+			"role":               "server",
+			"destination_header": "true",
+			"password":           "shared-secret",
+		},
+		Rules: []*domain.BypassRule{
+			{ID: "block", Type: domain.RuleTypeDomain, Action: domain.RuleActionBlock, Pattern: "blocked.test", Enabled: true},
+		},
+	}))
+	t.Cleanup(func() { _ = server.Stop("custom-dest-server") })
+
+	client := NewCustomAdapter(logger)
+	require.NoError(t, client.Start(&domain.BypassConfig{
+		ID:     "custom-dest-client",
+		Method: domain.BypassMethodCustom,
+		Parameters: map[string]string{
+			"local_port":  "0",
+			"role":        "client",
+			"remote_host": "127.0.0.1",
+			"remote_port": strconv.Itoa(customListenerPort(t, server, "custom-dest-server")),
+			"password":    "shared-secret",
+			"inbound":     "socks5",
+		},
+	}))
+	t.Cleanup(func() { _ = client.Stop("custom-dest-client") })
+
+	addr := fmt.Sprintf("127.0.0.1:%d", customListenerPort(t, client, "custom-dest-client"))
+	conn := dialProxy(t, addr)
+	require.NoError(t, socks5Connect(conn, localDestination(echoPort), "", ""))
+	assertEcho(t, conn, "hello through custom protocol")
+
+	// Сервер закрывает соединение с заблокированным назначением
+	conn = dialProxy(t, addr)
+	require.NoError(t, socks5Connect(conn, proxyDestination{Host: "blocked.test", Port: echoPort}, "", ""))
+	_, _ = conn.Write([]byte("data"))
+	_, err := conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+
+	for name, params := range map[string]map[string]string{
+		"inbound на сервере":      {"role": "server", "inbound": "socks5"},
+		"заголовок на клиенте":    {"role": "client", "destination_header": "true"},
+		"неверный флаг заголовка": {"destination_header": "maybe"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			params["local_port"] = "0"
+			assert.Error(t, NewCustomAdapter(logger).Start(&domain.BypassConfig{ID: "invalid", Parameters: params}))
+		})
+	}
+}
+
+func TestInbound_TLSFragmentSOCKS5(t *testing.T) {
+	serverPort := startTLSEchoServer(t)
+
+	adapter, addr := startTLSFragmentAdapter(t, &domain.BypassConfig{
+		ID:     "tls-fragment-inbound",
+		Method: domain.BypassMethodTLSHandshake,
+		Parameters: map[string]string{
+			"local_port":  "0",
+			"remote_host": "127.0.0.1",
+			"remote_port": "1", // не используется: назначение задает клиент
+			"split_mode":  "sni_middle",
+			"inbound":     "socks5",
+		},
+	})
+
+	conn := dialProxy(t, addr)
+	require.NoError(t, socks5Connect(conn, localDestination(serverPort), "", ""))
+
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: "localhost", InsecureSkipVerify: true})
+	assertEcho(t, tlsConn, "hello through fragments")
+
+	stats, err := adapter.GetStats("tls-fragment-inbound")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.ConnectionsEstablished)
+}
+
+func TestInbound_DomainFrontingHTTPOnly(t *testing.T) {
+	adapter := NewDomainFrontingAdapter(zap.NewNop())
+	err := adapter.Start(&domain.BypassConfig{
+		ID: "fronting-socks",
+		Parameters: map[string]string{
+			"local_port":   "0",
+			"front_domain": "front.test",
+			"inbound":      "socks5",
+		},
+	})
+	assert.Error(t, err)
+
+	_, addr := startDomainFrontingAdapter(t, &domain.BypassConfig{
+		ID:   "fronting-http",
+		Type: domain.BypassTypeDomainFronting,
+		Parameters: map[string]string{
+			"local_port":            "0",
+			"front_domain":          "front.test",
+			"health_check_interval": "0",
+			"inbound":               "http",
+			"inbound_username":      "user",
+			"inbound_password":      "secret",
+		},
+	})
+
+	conn := dialProxy(t, addr)
+	_, err = httpConnect(conn, proxyDestination{Host: "target.test", Port: 443}, "user", "secret")
+	assert.ErrorContains(t, err, "405")
+
+	conn = dialProxy(t, addr)
+	_, err = io.WriteString(conn, "GET http://target.test/ HTTP/1.1\r\nHost: target.test\r\n\r\n")
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusProxyAuthRequired, resp.StatusCode)
+}
+
+func TestProbeThrough_Inbound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+	serverPort := server.Listener.Addr().(*net.TCPAddr).Port
+
+	inbound, err := parseInboundConfig(map[string]string{"inbound": "socks5"})
+	require.NoError(t, err)
+	addr := startShadowsocksInbound(t, "inbound-probe", startTunnelServer(t), map[string]string{"inbound": "socks5"})
+
+	result := probeThrough(context.Background(), addr, probeTarget{
+		Host:     "127.0.0.1",
+		Port:     strconv.Itoa(serverPort),
+		Path:     "/",
+		Timeout:  5 * time.Second,
+		MaxBytes: 1024,
+		Inbound:  inbound,
+	})
+	assert.True(t, result.Success, result.Error)
+}
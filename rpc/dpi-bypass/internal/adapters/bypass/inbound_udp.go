@@ -0,0 +1,310 @@
+package bypass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// maxUDPPacketSize максимальный размер UDP датаграммы
+const maxUDPPacketSize = 65535
+
+// packetOutbound исходящий канал для UDP датаграмм клиента
+type packetOutbound interface {
+	// WritePacket отправляет датаграмму назначению
+	WritePacket(payload []byte, destination proxyDestination) error
+	// ReadPacket возвращает ответную датаграмму и ее источник
+	ReadPacket(buffer []byte) (int, proxyDestination, error)
+	Close() error
+}
+
+// directPacketOutbound отправляет датаграммы напрямую с одного UDP сокета
+type directPacketOutbound struct {
+	conn     *net.UDPConn
+	resolved map[string]*net.UDPAddr
+	mutex    sync.Mutex
+}
+
+// newDirectPacketOutbound создает прямой UDP канал
+func newDirectPacketOutbound() (packetOutbound, error) {
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create udp socket: %w", err)
+	}
+	return &directPacketOutbound{
+		conn:     conn,
+		resolved: make(map[string]*net.UDPAddr),
+	}, nil
+}
+
+func (d *directPacketOutbound) WritePacket(payload []byte, destination proxyDestination) error {
+	addr, err := d.resolve(destination)
+	if err != nil {
+		return err
+	}
+	_, err = d.conn.WriteToUDP(payload, addr)
+	return err
+}
+
+func (d *directPacketOutbound) ReadPacket(buffer []byte) (int, proxyDestination, error) {
+	n, addr, err := d.conn.ReadFromUDP(buffer)
+	if err != nil {
+		return 0, proxyDestination{}, err
+	}
+	return n, proxyDestination{Host: addr.IP.String(), Port: addr.Port}, nil
+}
+
+func (d *directPacketOutbound) Close() error {
+	return d.conn.Close()
+}
+
+// resolve кэширует разрешение имен назначений на время ассоциации
+func (d *directPacketOutbound) resolve(destination proxyDestination) (*net.UDPAddr, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	key := destination.Address()
+	if addr, ok := d.resolved[key]; ok {
+		return addr, nil
+	}
+	addr, err := net.ResolveUDPAddr("udp", key)
+	if err != nil {
+		return nil, err
+	}
+	d.resolved[key] = addr
+	return addr, nil
+}
+
+// serveUDPAssociate обслуживает UDP ASSOCIATE: открывает UDP порт для клиента
+// и пересылает датаграммы, пока открыто управляющее TCP соединение
+func (f *inboundFrontend) serveUDPAssociate(ctx context.Context, request *inboundRequest) {
+	control := request.Conn
+	defer control.Close()
+
+	localIP := net.IPv4zero
+	if addr, ok := control.LocalAddr().(*net.TCPAddr); ok {
+		localIP = addr.IP
+	}
+	relay, err := net.ListenUDP("udp", &net.UDPAddr{IP: localIP})
+	if err != nil {
+		f.onError()
+		_ = writeSOCKS5Reply(control, socks5ReplyGeneralFailure, nil)
+		return
+	}
+	defer relay.Close()
+
+	outbound, err := f.packets()
+	if err != nil {
+		f.onError()
+		_ = writeSOCKS5Reply(control, socks5ReplyGeneralFailure, nil)
+		return
+	}
+	defer outbound.Close()
+
+	if err := writeSOCKS5Reply(control, socks5ReplySucceeded, relay.LocalAddr()); err != nil {
+		return
+	}
+
+	association := &udpAssociation{
+		frontend: f,
+		relay:    relay,
+		outbound: outbound,
+		done:     make(chan struct{}),
+	}
+	if addr, ok := control.RemoteAddr().(*net.TCPAddr); ok {
+		association.clientIP = addr.IP
+	}
+	association.touch()
+
+	// Ассоциация живет, пока открыто управляющее соединение
+	controlClosed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, control)
+		close(controlClosed)
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-controlClosed:
+		}
+		association.close()
+	}()
+
+	go association.forwardReplies(outbound)
+	association.forwardRequests()
+}
+
+// udpAssociation состояние одной UDP ассоциации SOCKS5
+type udpAssociation struct {
+	frontend *inboundFrontend
+	relay    *net.UDPConn
+	outbound packetOutbound
+	// direct канал для назначений с правилом allow, создается по требованию
+	direct       packetOutbound
+	directMutex  sync.Mutex
+	clientIP     net.IP
+	clientAddr   atomic.Pointer[net.UDPAddr]
+	lastActivity atomic.Int64
+	done         chan struct{}
+}
+
+// close завершает ассоциацию
+func (a *udpAssociation) close() {
+	a.directMutex.Lock()
+	defer a.directMutex.Unlock()
+
+	close(a.done)
+	a.relay.Close()
+	a.outbound.Close()
+	if a.direct != nil {
+		a.direct.Close()
+	}
+}
+
+// outboundFor выбирает исходящий канал по правилу назначения: allow идет
+// напрямую, минуя метод обхода
+func (a *udpAssociation) outboundFor(rule *domain.BypassRule) (packetOutbound, error) {
+	if rule == nil || rule.Action != domain.RuleActionAllow {
+		return a.outbound, nil
+	}
+
+	a.directMutex.Lock()
+	defer a.directMutex.Unlock()
+
+	select {
+	case <-a.done:
+		return nil, ErrUDPStreamClosed
+	default:
+	}
+	if a.direct == nil {
+		direct, err := newDirectPacketOutbound()
+		if err != nil {
+			return nil, err
+		}
+		a.direct = direct
+		go a.forwardReplies(direct)
+	}
+	return a.direct, nil
+}
+
+func (a *udpAssociation) touch() {
+	a.lastActivity.Store(time.Now().UnixNano())
+}
+
+// idle сообщает, что ассоциация простаивает дольше таймаута
+func (a *udpAssociation) idle() bool {
+	last := time.Unix(0, a.lastActivity.Load())
+	return time.Since(last) >= a.frontend.config.UDPIdleTimeout
+}
+
+// forwardRequests читает датаграммы клиента и отправляет назначениям
+func (a *udpAssociation) forwardRequests() {
+	f := a.frontend
+	buffer := make([]byte, maxUDPPacketSize)
+
+	for {
+		if err := a.relay.SetReadDeadline(time.Now().Add(f.config.UDPIdleTimeout)); err != nil {
+			return
+		}
+		n, source, err := a.relay.ReadFromUDP(buffer)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) && !a.idle() {
+				continue
+			}
+			return
+		}
+
+		// Датаграммы принимаются только с адреса клиента ассоциации
+		if a.clientIP != nil && !a.clientIP.IsUnspecified() && !source.IP.Equal(a.clientIP) {
+			continue
+		}
+
+		destination, payload, err := parseSOCKS5UDPPacket(buffer[:n])
+		if err != nil {
+			f.logger.Debug("invalid socks udp packet", zap.Error(err), zap.String("id", f.id))
+			continue
+		}
+
+		rule := f.rules.Match(destination.ruleTarget("udp"))
+		if rule != nil && rule.Action == domain.RuleActionBlock {
+			continue
+		}
+
+		a.clientAddr.Store(source)
+		a.touch()
+
+		outbound, err := a.outboundFor(rule)
+		if err != nil {
+			f.onError()
+			continue
+		}
+		if err := outbound.WritePacket(payload, destination); err != nil {
+			f.logger.Debug("failed to send udp packet",
+				zap.Error(err),
+				zap.String("id", f.id),
+				zap.String("destination", destination.Address()))
+			f.onError()
+			continue
+		}
+		f.onTraffic(int64(len(payload)), 0)
+	}
+}
+
+// forwardReplies возвращает ответы назначений клиенту
+func (a *udpAssociation) forwardReplies(outbound packetOutbound) {
+	f := a.frontend
+	buffer := make([]byte, maxUDPPacketSize)
+
+	for {
+		n, source, err := outbound.ReadPacket(buffer)
+		if err != nil {
+			return
+		}
+
+		client := a.clientAddr.Load()
+		if client == nil {
+			continue
+		}
+		a.touch()
+
+		packet := appendSOCKS5UDPHeader(make([]byte, 0, n+22), source)
+		packet = append(packet, buffer[:n]...)
+		if _, err := a.relay.WriteToUDP(packet, client); err != nil {
+			return
+		}
+		f.onTraffic(0, int64(n))
+	}
+}
+
+// parseSOCKS5UDPPacket разбирает заголовок UDP датаграммы SOCKS5:
+// RSV(2), FRAG(1), адрес назначения и данные
+func parseSOCKS5UDPPacket(packet []byte) (proxyDestination, []byte, error) {
+	if len(packet) < 4 {
+		return proxyDestination{}, nil, fmt.Errorf("socks udp packet too short")
+	}
+	if packet[2] != 0 {
+		// Фрагментация SOCKS5 UDP не поддерживается, такие датаграммы отбрасываются
+		return proxyDestination{}, nil, fmt.Errorf("%w: socks udp fragment", ErrInboundUnsupported)
+	}
+
+	destination, size, err := parseSocksAddr(packet[3:])
+	if err != nil {
+		return proxyDestination{}, nil, err
+	}
+	return destination, packet[3+size:], nil
+}
+
+// appendSOCKS5UDPHeader добавляет заголовок UDP датаграммы SOCKS5
+func appendSOCKS5UDPHeader(dst []byte, source proxyDestination) []byte {
+	dst = append(dst, 0x00, 0x00, 0x00)
+	return appendSocksAddr(dst, source)
+}
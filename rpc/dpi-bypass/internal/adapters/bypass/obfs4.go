@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
@@ -16,21 +17,41 @@ type Obfs4Adapter struct {
 	running map[string]*obfs4Connection
 	mutex   sync.RWMutex
 	logger  *zap.Logger
+	signals *SignalMonitor
 }
 
 type obfs4Connection struct {
-	config     *domain.BypassConfig
+	// config заменяется при перезагрузке без перезапуска
+	config     atomic.Pointer[domain.BypassConfig]
 	listener   net.Listener
 	ctx        context.Context
 	cancel     context.CancelFunc
 	stats      *domain.BypassStats
 	statsMutex sync.RWMutex
+	// rules правила, заменяемые при перезагрузке
+	rules *ruleMatcher
+	// tracker учитывает клиентские соединения для плавной остановки
+	tracker *connectionTracker
 	// Obfs4 специфичные поля
 	iatMode    bool // Inter-Arrival Time mode
 	iatDist    string
 	iatDistLen int
 	iatDistMin int
 	iatDistMax int
+	// inbound входящий SOCKS5/HTTP прокси; nil в режиме raw
+	inbound *inboundFrontend
+	// UDP: поток датаграмм к серверу и listener на local_port
+	udpPool  *udpStreamPool
+	udpRelay *udpRelay
+	// server серверная роль с пользователями; nil в роли клиента
+	server *userServer
+	// userSecret секрет пользователя клиента: поток шифруется ключами
+	// пользователя вместо обфускации паролем
+	userSecret []byte
+	// nonces nonce принятых рукопожатий пользователей для защиты от повтора
+	nonces *replayCache
+	// shaping профиль формы трафика к удаленной стороне; заменяет задержки IAT
+	shaping *shapingConfig
 }
 
 // NewObfs4Adapter создает новый Obfs4 адаптер
@@ -41,6 +62,11 @@ func NewObfs4Adapter(logger *zap.Logger) *Obfs4Adapter {
 	}
 }
 
+// observeSignals подключает монитор сигналов цензуры к исходящим соединениям
+func (o *Obfs4Adapter) observeSignals(monitor *SignalMonitor) {
+	o.signals = monitor
+}
+
 // Start запускает Obfs4 сервер
 func (o *Obfs4Adapter) Start(config *domain.BypassConfig) error {
 	o.mutex.Lock()
@@ -50,6 +76,41 @@ func (o *Obfs4Adapter) Start(config *domain.BypassConfig) error {
 		return fmt.Errorf("obfs4 connection already running: %s", config.ID)
 	}
 
+	inbound, err := parseInboundConfig(config.Parameters)
+	if err != nil {
+		return err
+	}
+	udp, err := parseUDPConfig(config.Parameters)
+	if err != nil {
+		return err
+	}
+	if udp.Mode == udpModeNative {
+		return fmt.Errorf("udp_mode native is not supported by obfs4")
+	}
+	shaping, err := parseShaping(config.Parameters, config.Parameters["password"])
+	if err != nil {
+		return err
+	}
+
+	rules := newRuleMatcher(config.Rules)
+	server, err := newUserServer(config, rules, parseObfs4Secret, o.logger)
+	if err != nil {
+		return err
+	}
+	var userSecret []byte
+	if value := config.Parameters["user_secret"]; value != "" {
+		if server != nil {
+			return fmt.Errorf("user_secret is not supported in server role")
+		}
+		if !inbound.Enabled() && !udp.Listen {
+			return fmt.Errorf("user_secret requires inbound or udp")
+		}
+		userSecret, err = parseObfs4Secret(value)
+		if err != nil {
+			return fmt.Errorf("invalid user_secret: %w", err)
+		}
+	}
+
 	// Создаем контекст для управления жизненным циклом
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -60,7 +121,7 @@ func (o *Obfs4Adapter) Start(config *domain.BypassConfig) error {
 	}
 
 	// Создаем listener
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", localPort))
+	listener, err := inbound.Listen(localPort)
 	if err != nil {
 		cancel()
 		return fmt.Errorf("failed to create listener: %w", err)
@@ -74,10 +135,11 @@ func (o *Obfs4Adapter) Start(config *domain.BypassConfig) error {
 	iatDistMax := 100
 
 	conn := &obfs4Connection{
-		config:   config,
 		listener: listener,
 		ctx:      ctx,
 		cancel:   cancel,
+		rules:    rules,
+		tracker:  newConnectionTracker(),
 		stats: &domain.BypassStats{
 			ID:                     config.ID,
 			ConfigID:               config.ID,
@@ -93,6 +155,41 @@ func (o *Obfs4Adapter) Start(config *domain.BypassConfig) error {
 		iatDistLen: iatDistLen,
 		iatDistMin: iatDistMin,
 		iatDistMax: iatDistMax,
+		server:     server,
+		userSecret: userSecret,
+		nonces:     newReplayCache(obfs4NonceTTL),
+		shaping:    shaping,
+	}
+	conn.config.Store(config)
+	conn.udpPool = newUDPStreamPool(func() (net.Conn, error) {
+		remote, err := o.dialTunnel(conn, udpOverStreamDestination())
+		if err != nil || conn.userSecret != nil {
+			return remote, err
+		}
+		return &obfs4StreamConn{Conn: remote, adapter: o, conn: conn}, nil
+	})
+	if server != nil {
+		server.onTraffic = func(rx, tx int64) { o.updateStats(conn, rx, tx) }
+		server.onError = func() { o.incrementErrorCount(conn) }
+	}
+
+	conn.inbound = newInboundFrontend(inbound, conn.rules, config.ID, o.logger)
+	if conn.inbound != nil {
+		conn.inbound.packets = conn.udpPool.Session
+		conn.inbound.onTraffic = func(rx, tx int64) { o.updateStats(conn, rx, tx) }
+		conn.inbound.onError = func() { o.incrementErrorCount(conn) }
+	}
+
+	conn.udpRelay, err = newUDPRelay(udp, listener, conn.rules, conn.udpPool.Session, config.ID, o.logger)
+	if err != nil {
+		listener.Close()
+		cancel()
+		return err
+	}
+	if conn.udpRelay != nil {
+		conn.udpRelay.onTraffic = func(rx, tx int64) { o.updateStats(conn, rx, tx) }
+		conn.udpRelay.onError = func() { o.incrementErrorCount(conn) }
+		conn.udpRelay.start()
 	}
 
 	o.running[config.ID] = conn
@@ -113,25 +210,14 @@ func (o *Obfs4Adapter) Start(config *domain.BypassConfig) error {
 	return nil
 }
 
-// Stop останавливает Obfs4 сервер
+// Stop останавливает obfs4 сервер: закрывает listener, прерывает
+// соединения и ждет завершения их горутин
 func (o *Obfs4Adapter) Stop(id string) error {
-	o.mutex.Lock()
-	defer o.mutex.Unlock()
-
-	conn, exists := o.running[id]
-	if !exists {
-		return fmt.Errorf("obfs4 connection not found: %s", id)
-	}
-
-	// Отменяем контекст
-	conn.cancel()
-
-	// Закрываем listener
-	if err := conn.listener.Close(); err != nil {
-		o.logger.Error("failed to close listener", zap.Error(err), zap.String("id", id))
+	detached, err := o.Detach(id)
+	if err != nil {
+		return err
 	}
-
-	delete(o.running, id)
+	detached.Drain(0)
 
 	o.logger.Info("obfs4 server stopped", zap.String("id", id))
 	return nil
@@ -147,12 +233,20 @@ func (o *Obfs4Adapter) GetStats(id string) (*domain.BypassStats, error) {
 		return nil, nil
 	}
 
+	return o.snapshotStats(conn), nil
+}
+
+// snapshotStats возвращает копию статистики соединения
+func (o *Obfs4Adapter) snapshotStats(conn *obfs4Connection) *domain.BypassStats {
 	conn.statsMutex.RLock()
 	defer conn.statsMutex.RUnlock()
 
 	// Возвращаем копию статистики
 	stats := *conn.stats
-	return &stats, nil
+	if conn.server != nil {
+		stats.Users = conn.server.users.Stats()
+	}
+	return &stats
 }
 
 // IsRunning проверяет, запущен ли Obfs4 сервер
@@ -163,3 +257,58 @@ func (o *Obfs4Adapter) IsRunning(id string) bool {
 	_, exists := o.running[id]
 	return exists
 }
+
+// Reload применяет правила, адрес сервера и пользователей к запущенному
+// соединению. Остальные изменения требуют перезапуска.
+func (o *Obfs4Adapter) Reload(config *domain.BypassConfig) error {
+	o.mutex.RLock()
+	conn, exists := o.running[config.ID]
+	o.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("obfs4 connection not found: %s", config.ID)
+	}
+	if !hotSwappable(conn.config.Load(), config, "remote_host", "remote_port") {
+		return errRestartRequired
+	}
+	if conn.server != nil {
+		if err := conn.server.users.Replace(config.Users); err != nil {
+			return err
+		}
+	}
+
+	conn.rules.Replace(config.Rules)
+	conn.config.Store(config)
+
+	o.logger.Info("obfs4 configuration reloaded", zap.String("id", config.ID))
+	return nil
+}
+
+// Detach убирает соединение из запущенных для плавной замены новым
+func (o *Obfs4Adapter) Detach(id string) (*detachedConnection, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	conn, exists := o.running[id]
+	if !exists {
+		return nil, fmt.Errorf("obfs4 connection not found: %s", id)
+	}
+	delete(o.running, id)
+
+	return &detachedConnection{
+		config: conn.config.Load(),
+		closeListeners: func() {
+			conn.listener.Close()
+			if conn.udpRelay != nil {
+				conn.udpRelay.Close()
+			}
+		},
+		wait: conn.tracker.wait,
+		stop: func() {
+			conn.cancel()
+			conn.tracker.abort()
+			conn.udpPool.Close()
+			conn.tracker.done()
+		},
+		stats: func() *domain.BypassStats { return o.snapshotStats(conn) },
+	}, nil
+}
@@ -1,7 +1,8 @@
 package bypass
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"net"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 
 // handleConnections обрабатывает входящие соединения
 func (o *Obfs4Adapter) handleConnections(conn *obfs4Connection) {
+	defer conn.tracker.acceptStopped()
+
 	for {
 		select {
 		case <-conn.ctx.Done():
@@ -17,17 +20,17 @@ func (o *Obfs4Adapter) handleConnections(conn *obfs4Connection) {
 		default:
 			clientConn, err := conn.listener.Accept()
 			if err != nil {
-				if conn.ctx.Err() != nil {
-					// Контекст отменен, выходим
+				if conn.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+					// Контекст отменен или listener закрыт, выходим
 					return
 				}
-				o.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.ID))
+				o.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.Load().ID))
 				o.incrementErrorCount(conn)
 				continue
 			}
 
 			// Обрабатываем соединение в отдельной горутине
-			go o.handleClientConnection(conn, clientConn)
+			conn.tracker.handle(clientConn, func() { o.handleClientConnection(conn, clientConn) })
 		}
 	}
 }
@@ -39,9 +42,57 @@ func (o *Obfs4Adapter) handleClientConnection(conn *obfs4Connection, clientConn
 	// Увеличиваем счетчик соединений
 	o.incrementConnections(conn)
 
-	// Получаем параметры удаленного сервера
-	remoteHost := conn.config.Parameters["remote_host"]
-	remotePort := conn.config.Parameters["remote_port"]
+	if conn.server != nil {
+		o.serveUser(conn, shapeConn(clientConn, conn.shaping))
+		return
+	}
+
+	var remoteConn net.Conn
+	if conn.inbound != nil {
+		var ok bool
+		clientConn, remoteConn, ok = conn.inbound.serve(conn.ctx, clientConn, func(destination proxyDestination) (net.Conn, error) {
+			return o.dialTunnel(conn, destination)
+		})
+		if !ok {
+			return
+		}
+	} else {
+		var err error
+		remoteConn, err = o.dialRemote(conn)
+		if err != nil {
+			o.logger.Error("failed to connect to remote server",
+				zap.Error(err),
+				zap.String("id", conn.config.Load().ID))
+			o.incrementErrorCount(conn)
+			return
+		}
+	}
+	defer remoteConn.Close()
+
+	// Обе горутины завершаются до выхода: соединения закрываются, как только
+	// останавливается одно из направлений
+	err := relayConns(conn.ctx, clientConn, remoteConn,
+		func() error {
+			// Копируем данные от клиента к серверу с обфускацией
+			bytes, err := o.copyDataWithObfs(clientConn, remoteConn, conn, true)
+			o.updateStats(conn, bytes, 0)
+			return err
+		},
+		func() error {
+			// Копируем данные от сервера к клиенту с обфускацией
+			bytes, err := o.copyDataWithObfs(remoteConn, clientConn, conn, false)
+			o.updateStats(conn, 0, bytes)
+			return err
+		})
+	if err != nil {
+		o.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.Load().ID))
+	}
+}
+
+// dialRemote подключается к удаленному серверу
+func (o *Obfs4Adapter) dialRemote(conn *obfs4Connection) (net.Conn, error) {
+	remoteHost := conn.config.Load().Parameters["remote_host"]
+	remotePort := conn.config.Load().Parameters["remote_port"]
 	if remoteHost == "" {
 		remoteHost = "127.0.0.1"
 	}
@@ -49,50 +100,60 @@ func (o *Obfs4Adapter) handleClientConnection(conn *obfs4Connection, clientConn
 		remotePort = "8080"
 	}
 
-	// Подключаемся к удаленному серверу
-	remoteConn, err := net.DialTimeout("tcp",
-		fmt.Sprintf("%s:%s", remoteHost, remotePort),
-		10*time.Second)
+	remote, err := o.signals.Dial(context.Background(), conn.config.Load(), net.JoinHostPort(remoteHost, remotePort), 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return shapeConn(remote, conn.shaping), nil
+}
+
+// serveUser обслуживает поток клиента в роли сервера: рукопожатие
+// пользователя, затем адрес назначения SOCKS5 в зашифрованном потоке
+func (o *Obfs4Adapter) serveUser(conn *obfs4Connection, clientConn net.Conn) {
+	if err := clientConn.SetReadDeadline(time.Now().Add(defaultInboundHandshakeTimeout)); err != nil {
+		return
+	}
+	userConn, account, revoked, err := acceptObfs4User(clientConn, conn.server.users, conn.nonces)
+	var destination proxyDestination
+	if err == nil {
+		destination, err = readSocksAddr(userConn)
+	}
+	if err == nil {
+		err = clientConn.SetReadDeadline(time.Time{})
+	}
 	if err != nil {
-		o.logger.Error("failed to connect to remote server",
-			zap.Error(err),
-			zap.String("id", conn.config.ID),
-			zap.String("remote", remoteHost))
+		o.logger.Debug("failed to authenticate obfs4 user", zap.Error(err), zap.String("id", conn.config.Load().ID))
 		o.incrementErrorCount(conn)
 		return
 	}
-	defer remoteConn.Close()
 
-	// Создаем каналы для передачи данных
-	errChan := make(chan error, 2)
-
-	// Копируем данные от клиента к серверу с обфускацией
-	go func() {
-		bytes, err := o.copyDataWithObfs(clientConn, remoteConn, conn, true)
-		if err != nil {
-			errChan <- err
-		}
-		o.updateStats(conn, bytes, 0)
-	}()
+	conn.server.serve(conn.ctx, revoked, account, userConn, destination, "tcp")
+}
 
-	// Копируем данные от сервера к клиенту с обфускацией
-	go func() {
-		bytes, err := o.copyDataWithObfs(remoteConn, clientConn, conn, false)
-		if err != nil {
-			errChan <- err
+// dialTunnel открывает поток к назначению через сервер. Заголовок
+// назначения обфусцируется так же, как данные, а с секретом пользователя
+// передается в зашифрованном потоке после рукопожатия.
+func (o *Obfs4Adapter) dialTunnel(conn *obfs4Connection, destination proxyDestination) (net.Conn, error) {
+	remote, err := o.dialRemote(conn)
+	if err != nil {
+		return nil, err
+	}
+	if conn.userSecret != nil {
+		userConn, err := dialObfs4User(remote, conn.userSecret)
+		if err == nil {
+			_, err = userConn.Write(appendSocksAddr(nil, destination))
 		}
-		o.updateStats(conn, 0, bytes)
-	}()
-
-	// Ждем завершения или ошибки
-	select {
-	case <-conn.ctx.Done():
-		return
-	case err := <-errChan:
 		if err != nil {
-			o.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.ID))
+			remote.Close()
+			return nil, err
 		}
+		return userConn, nil
 	}
+	if _, err := remote.Write(o.obfuscateData(appendSocksAddr(nil, destination), conn)); err != nil {
+		remote.Close()
+		return nil, err
+	}
+	return remote, nil
 }
 
 // copyDataWithObfs копирует данные с обфускацией
@@ -101,41 +162,35 @@ func (o *Obfs4Adapter) copyDataWithObfs(src, dst net.Conn, conn *obfs4Connection
 	var totalBytes int64
 
 	for {
-		select {
-		case <-conn.ctx.Done():
-			return totalBytes, nil
-		default:
-			// Устанавливаем таймаут для чтения
-			if err := src.SetReadDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		n, err := src.Read(buffer)
+		if err != nil {
+			return totalBytes, err
+		}
+
+		if n > 0 {
+			// Применяем обфускацию к данным; поток пользователя уже
+			// зашифрован
+			obfuscatedData := buffer[:n]
+			if conn.userSecret == nil {
+				obfuscatedData = o.obfuscateData(buffer[:n], conn)
+			}
+
+			// Устанавливаем таймаут для записи
+			if err := dst.SetWriteDeadline(time.Now().Add(relayWriteTimeout)); err != nil {
 				return totalBytes, err
 			}
 
-			n, err := src.Read(buffer)
+			_, err = dst.Write(obfuscatedData)
 			if err != nil {
 				return totalBytes, err
 			}
 
-			if n > 0 {
-				// Применяем обфускацию к данным
-				obfuscatedData := o.obfuscateData(buffer[:n], conn)
+			totalBytes += int64(n)
+			o.updateLastActivity(conn)
 
-				// Устанавливаем таймаут для записи
-				if err := dst.SetWriteDeadline(time.Now().Add(30 * time.Second)); err != nil {
-					return totalBytes, err
-				}
-
-				_, err = dst.Write(obfuscatedData)
-				if err != nil {
-					return totalBytes, err
-				}
-
-				totalBytes += int64(n)
-				o.updateLastActivity(conn)
-
-				// Добавляем задержку IAT если включен
-				if conn.iatMode {
-					o.applyIATDelay(conn)
-				}
+			// Добавляем задержку IAT если включен; с профилем паузы выдерживает shaper
+			if conn.iatMode && conn.shaping == nil {
+				o.applyIATDelay(conn)
 			}
 		}
 	}
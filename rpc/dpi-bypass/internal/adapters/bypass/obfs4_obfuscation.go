@@ -3,13 +3,14 @@ package bypass
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"net"
 	"time"
 )
 
 // obfuscateData применяет обфускацию к данным
 func (o *Obfs4Adapter) obfuscateData(data []byte, conn *obfs4Connection) []byte {
 	// Простая обфускация: XOR с ключом
-	password := conn.config.Parameters["password"]
+	password := conn.config.Load().Parameters["password"]
 	if password == "" {
 		password = "default_password"
 	}
@@ -23,6 +24,29 @@ func (o *Obfs4Adapter) obfuscateData(data []byte, conn *obfs4Connection) []byte
 	return obfuscated
 }
 
+// obfs4StreamConn соединение, которое обфусцирует записи и читаемые данные
+// так же, как copyDataWithObfs
+type obfs4StreamConn struct {
+	net.Conn
+	adapter *Obfs4Adapter
+	conn    *obfs4Connection
+}
+
+func (c *obfs4StreamConn) Write(p []byte) (int, error) {
+	if _, err := c.Conn.Write(c.adapter.obfuscateData(p, c.conn)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *obfs4StreamConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		copy(p, c.adapter.obfuscateData(p[:n], c.conn))
+	}
+	return n, err
+}
+
 // generateKey генерирует ключ из пароля
 func (o *Obfs4Adapter) generateKey(password string) []byte {
 	hash := sha256.Sum256([]byte(password))
@@ -20,7 +20,8 @@ func TestObfs4Adapter_generateKey(t *testing.T) {
 
 func TestObfs4Adapter_obfuscateData(t *testing.T) {
 	o := &Obfs4Adapter{}
-	conn := &obfs4Connection{config: &domain.BypassConfig{Parameters: map[string]string{"password": "test"}}}
+	conn := &obfs4Connection{}
+	conn.config.Store(&domain.BypassConfig{Parameters: map[string]string{"password": "test"}})
 	plain := []byte("hello world")
 	obf := o.obfuscateData(plain, conn)
 	assert.NotEqual(t, plain, obf)
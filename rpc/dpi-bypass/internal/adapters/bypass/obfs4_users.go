@@ -0,0 +1,195 @@
+package bypass
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Рукопожатие пользователя obfs4: случайный nonce клиента, время и тег HMAC
+// секрета пользователя от них, в ответ случайный nonce сервера. Поток в
+// каждую сторону делится на блоки AES-256-GCM (зашифрованная длина, затем
+// данные) на ключах, выведенных из секрета и nonce: ключ к клиенту зависит
+// от обоих nonce.
+const (
+	obfs4AuthNonceSize = 16
+	obfs4AuthTimeSize  = 8
+	obfs4AuthTagSize   = 16
+	obfs4MinSecretSize = 16
+	// obfs4MaxPayloadSize максимальный размер данных блока
+	obfs4MaxPayloadSize = 16 * 1024
+	// obfs4MaxTimeDiff допустимое расхождение времени рукопожатия
+	obfs4MaxTimeDiff = time.Minute
+	// obfs4NonceTTL время хранения nonce клиента для защиты от повтора:
+	// вдвое больше окна времени
+	obfs4NonceTTL = 2 * obfs4MaxTimeDiff
+)
+
+var (
+	// ErrObfs4Replay сервер уже принимал рукопожатие с этим nonce
+	ErrObfs4Replay = errors.New("obfs4 handshake replayed")
+	// ErrObfs4Timestamp время рукопожатия вне допустимого окна
+	ErrObfs4Timestamp = errors.New("obfs4 handshake timestamp out of window")
+)
+
+// parseObfs4Secret разбирает секрет пользователя в hex
+func parseObfs4Secret(value string) ([]byte, error) {
+	secret, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid obfs4 secret: %w", err)
+	}
+	if len(secret) < obfs4MinSecretSize {
+		return nil, fmt.Errorf("invalid obfs4 secret: %d bytes, want at least %d", len(secret), obfs4MinSecretSize)
+	}
+	return secret, nil
+}
+
+// obfs4Derive выводит значение из секрета пользователя для назначения label
+func obfs4Derive(secret, nonce []byte, label string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(label))
+	mac.Write(nonce)
+	return mac.Sum(nil)
+}
+
+// obfs4AuthTag тег рукопожатия, по которому сервер находит пользователя
+func obfs4AuthTag(secret, nonce []byte) []byte {
+	return obfs4Derive(secret, nonce, "obfs4 user auth")[:obfs4AuthTagSize]
+}
+
+// dialObfs4User отправляет рукопожатие пользователя и возвращает
+// зашифрованное соединение клиента. Nonce сервера читается при первом
+// чтении.
+func dialObfs4User(conn net.Conn, secret []byte) (net.Conn, error) {
+	nonce := make([]byte, obfs4AuthNonceSize, obfs4AuthNonceSize+obfs4AuthTimeSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	nonce = binary.BigEndian.AppendUint64(nonce, uint64(time.Now().Unix()))
+	writer, err := obfs4Writer(conn, secret, nonce, "obfs4 upstream")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(append(nonce, obfs4AuthTag(secret, nonce)...)); err != nil {
+		return nil, err
+	}
+	return &obfs4UserConn{Conn: conn, secret: secret, clientNonce: nonce, writer: writer}, nil
+}
+
+// acceptObfs4User читает рукопожатие и находит пользователя по тегу. Nonce
+// клиента запоминается в nonces, nonce сервера отправляется с первыми
+// данными. Возвращает
+// зашифрованное соединение сервера и контекст отзыва пользователя.
+func acceptObfs4User(conn net.Conn, users *userRegistry, nonces *replayCache) (net.Conn, *userAccount, context.Context, error) {
+	handshake := make([]byte, obfs4AuthNonceSize+obfs4AuthTimeSize+obfs4AuthTagSize)
+	if _, err := io.ReadFull(conn, handshake); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read obfs4 handshake: %w", err)
+	}
+	nonce, tag := handshake[:obfs4AuthNonceSize+obfs4AuthTimeSize], handshake[obfs4AuthNonceSize+obfs4AuthTimeSize:]
+
+	var secret []byte
+	account, revoked, err := users.Find(func(key []byte) bool {
+		if hmac.Equal(obfs4AuthTag(key, nonce), tag) {
+			secret = key
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	diff := time.Since(time.Unix(int64(binary.BigEndian.Uint64(nonce[obfs4AuthNonceSize:])), 0))
+	if diff > obfs4MaxTimeDiff || diff < -obfs4MaxTimeDiff {
+		return nil, nil, nil, ErrObfs4Timestamp
+	}
+	if !nonces.Add(nonce) {
+		return nil, nil, nil, ErrObfs4Replay
+	}
+
+	serverNonce := make([]byte, obfs4AuthNonceSize)
+	if _, err := rand.Read(serverNonce); err != nil {
+		return nil, nil, nil, err
+	}
+	reader, err := obfs4Reader(conn, secret, nonce, "obfs4 upstream")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	writer, err := obfs4Writer(conn, secret, append(append([]byte(nil), nonce...), serverNonce...), "obfs4 downstream")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &obfs4UserConn{Conn: conn, reader: reader, writer: writer, serverNonce: serverNonce}, account, revoked, nil
+}
+
+// obfs4UserConn соединение пользователя с потоком блоков AES-GCM
+type obfs4UserConn struct {
+	net.Conn
+	// secret и clientNonce нужны клиенту до получения nonce сервера
+	secret      []byte
+	clientNonce []byte
+	// serverNonce nonce сервера, еще не отправленный клиенту
+	serverNonce []byte
+	reader      *aeadReader
+	writer      *aeadWriter
+}
+
+// obfs4AEAD шифр направления потока
+func obfs4AEAD(secret, nonce []byte, label string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(obfs4Derive(secret, nonce, label))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func obfs4Writer(conn net.Conn, secret, nonce []byte, label string) (*aeadWriter, error) {
+	aead, err := obfs4AEAD(secret, nonce, label)
+	if err != nil {
+		return nil, err
+	}
+	return newAEADWriter(conn, aead, obfs4MaxPayloadSize), nil
+}
+
+func obfs4Reader(conn net.Conn, secret, nonce []byte, label string) (*aeadReader, error) {
+	aead, err := obfs4AEAD(secret, nonce, label)
+	if err != nil {
+		return nil, err
+	}
+	return newAEADReader(conn, aead), nil
+}
+
+func (c *obfs4UserConn) Write(p []byte) (int, error) {
+	if c.serverNonce == nil {
+		return c.writer.Write(p)
+	}
+	if _, err := c.Conn.Write(c.writer.appendChunks(c.serverNonce, p)); err != nil {
+		return 0, err
+	}
+	c.serverNonce = nil
+	return len(p), nil
+}
+
+func (c *obfs4UserConn) Read(p []byte) (int, error) {
+	if c.reader == nil {
+		serverNonce := make([]byte, obfs4AuthNonceSize)
+		if _, err := io.ReadFull(c.Conn, serverNonce); err != nil {
+			return 0, err
+		}
+		reader, err := obfs4Reader(c.Conn, c.secret, append(append([]byte(nil), c.clientNonce...), serverNonce...), "obfs4 downstream")
+		if err != nil {
+			return 0, err
+		}
+		c.reader = reader
+	}
+	return c.reader.Read(p)
+}
@@ -0,0 +1,187 @@
+package bypass
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Транспорты, доступные в режиме Tor Pluggable Transport. Соединение с
+// мостом оборачивается тем же кодом, что и в адаптерах: obfs4 - рукопожатием
+// пользователя с секретом, custom - кадрами кастомного протокола.
+const (
+	PTTransportObfs4  = "obfs4"
+	PTTransportCustom = "custom"
+)
+
+// ptSecretSize размер секрета, который сервер генерирует при отсутствии
+const ptSecretSize = 32
+
+// ptArgs параметры транспорта, которые передаются клиенту в строке моста.
+// Первый параметр - секрет, без которого клиент не подключится.
+var ptArgs = map[string][]string{
+	PTTransportObfs4:  {"secret", "shaping_profile"},
+	PTTransportCustom: {"password", "obfuscation_mode", "chaff_ratio", "fragment_size", "shaping_profile"},
+}
+
+// PTTransports возвращает имена поддерживаемых транспортов
+func PTTransports() []string {
+	names := make([]string, 0, len(ptArgs))
+	for name := range ptArgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PTTransport сторона транспорта для управляемого прокси Tor: оборачивает
+// соединение с мостом в клиентской или серверной роли
+type PTTransport struct {
+	name string
+	args map[string]string
+	wrap func(conn net.Conn) (net.Conn, error)
+}
+
+// NewPTClient создает клиентскую сторону транспорта по параметрам моста из
+// SOCKS запроса Tor
+func NewPTClient(name string, args map[string]string) (*PTTransport, error) {
+	transport, err := newPTTransport(name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case PTTransportObfs4:
+		secret, err := parseObfs4Secret(args["secret"])
+		if err != nil {
+			return nil, err
+		}
+		profile, err := parseShaping(args, args["secret"])
+		if err != nil {
+			return nil, err
+		}
+		transport.wrap = func(conn net.Conn) (net.Conn, error) {
+			return dialObfs4User(shapeConn(conn, profile), secret)
+		}
+	case PTTransportCustom:
+		if args["password"] == "" {
+			return nil, fmt.Errorf("custom transport requires password")
+		}
+		if transport.wrap, err = newPTCustomWrap(args); err != nil {
+			return nil, err
+		}
+	}
+	return transport, nil
+}
+
+// NewPTServer создает серверную сторону транспорта по параметрам
+// TOR_PT_SERVER_TRANSPORT_OPTIONS. Отсутствующий секрет генерируется,
+// Args возвращает его вместе с остальными параметрами моста.
+func NewPTServer(name string, options map[string]string) (*PTTransport, error) {
+	transport, err := newPTTransport(name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	secretKey := ptArgs[name][0]
+	if transport.args[secretKey] == "" {
+		secret := make([]byte, ptSecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		transport.args[secretKey] = hex.EncodeToString(secret)
+	}
+
+	switch name {
+	case PTTransportObfs4:
+		users := newUserRegistry(parseObfs4Secret)
+		err := users.Replace([]*domain.BypassUser{{ID: "pt", Credential: transport.args["secret"]}})
+		if err != nil {
+			return nil, err
+		}
+		profile, err := parseShaping(transport.args, transport.args["secret"])
+		if err != nil {
+			return nil, err
+		}
+		nonces := newReplayCache(obfs4NonceTTL)
+		transport.wrap = func(conn net.Conn) (net.Conn, error) {
+			userConn, _, _, err := acceptObfs4User(shapeConn(conn, profile), users, nonces)
+			return userConn, err
+		}
+	case PTTransportCustom:
+		if transport.wrap, err = newPTCustomWrap(transport.args); err != nil {
+			return nil, err
+		}
+	}
+	return transport, nil
+}
+
+// newPTTransport проверяет имя транспорта и оставляет только его параметры
+func newPTTransport(name string, args map[string]string) (*PTTransport, error) {
+	keys, ok := ptArgs[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported pluggable transport: %s", name)
+	}
+	transport := &PTTransport{name: name, args: make(map[string]string)}
+	for _, key := range keys {
+		if value := args[key]; value != "" {
+			transport.args[key] = value
+		}
+	}
+	return transport, nil
+}
+
+// newPTCustomWrap собирает соединение кастомного протокола без адаптера:
+// кадры кодируются так же, как у клиента и сервера адаптера custom
+func newPTCustomWrap(args map[string]string) (func(conn net.Conn) (net.Conn, error), error) {
+	adapter := NewCustomAdapter(zap.NewNop())
+	params, err := adapter.parseParameters(args)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := parseShaping(args, args["password"])
+	if err != nil {
+		return nil, err
+	}
+
+	return func(conn net.Conn) (net.Conn, error) {
+		custom := &customConnection{
+			ctx:             context.Background(),
+			obfuscationMode: params.obfuscationMode,
+			chaffRatio:      params.chaffRatio,
+			fragmentSize:    params.fragmentSize,
+			encryptionKey:   params.encryptionKey,
+			shaping:         profile,
+		}
+		if profile != nil {
+			custom.shaper = newTrafficShaper(profile.Profile)
+		}
+		return newCustomStreamConn(shapeConn(conn, profile), adapter, custom), nil
+	}, nil
+}
+
+// Name возвращает имя транспорта
+func (t *PTTransport) Name() string {
+	return t.name
+}
+
+// Args возвращает параметры моста для строки SMETHOD
+func (t *PTTransport) Args() map[string]string {
+	args := make(map[string]string, len(t.args))
+	for key, value := range t.args {
+		args[key] = value
+	}
+	return args
+}
+
+// Wrap оборачивает соединение с мостом: клиент отправляет рукопожатие,
+// сервер проверяет его
+func (t *PTTransport) Wrap(conn net.Conn) (net.Conn, error) {
+	return t.wrap(conn)
+}
@@ -0,0 +1,116 @@
+package bypass
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ptRoundTrip передает данные через клиентскую и серверную стороны
+// транспорта в обе стороны
+func ptRoundTrip(t *testing.T, client, server *PTTransport) {
+	t.Helper()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := server.Wrap(serverConn)
+		assert.NoError(t, err)
+		accepted <- conn
+	}()
+
+	wrapped, err := client.Wrap(clientConn)
+	require.NoError(t, err)
+
+	request := []byte("tor cell from client")
+	go func() {
+		_, _ = wrapped.Write(request)
+	}()
+	bridge := <-accepted
+	require.NotNil(t, bridge)
+
+	buffer := make([]byte, len(request))
+	_, err = io.ReadFull(bridge, buffer)
+	require.NoError(t, err)
+	assert.Equal(t, request, buffer)
+
+	response := []byte("tor cell from bridge")
+	go func() {
+		_, _ = bridge.Write(response)
+	}()
+	buffer = make([]byte, len(response))
+	_, err = io.ReadFull(wrapped, buffer)
+	require.NoError(t, err)
+	assert.Equal(t, response, buffer)
+}
+
+func TestPTTransport_Obfs4(t *testing.T) {
+	server, err := NewPTServer(PTTransportObfs4, map[string]string{"iat_mode": "1"})
+	require.NoError(t, err)
+
+	// Секрет сгенерирован и передается клиенту, лишние параметры отброшены
+	args := server.Args()
+	assert.Len(t, args["secret"], 2*ptSecretSize)
+	assert.NotContains(t, args, "iat_mode")
+
+	client, err := NewPTClient(PTTransportObfs4, args)
+	require.NoError(t, err)
+	ptRoundTrip(t, client, server)
+}
+
+func TestPTTransport_Obfs4WrongSecret(t *testing.T) {
+	server, err := NewPTServer(PTTransportObfs4, nil)
+	require.NoError(t, err)
+	other, err := NewPTServer(PTTransportObfs4, nil)
+	require.NoError(t, err)
+	client, err := NewPTClient(PTTransportObfs4, other.Args())
+	require.NoError(t, err)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go func() {
+		_, _ = client.Wrap(clientConn)
+	}()
+	_, err = server.Wrap(serverConn)
+	assert.Error(t, err)
+}
+
+func TestPTTransport_Custom(t *testing.T) {
+	server, err := NewPTServer(PTTransportCustom, map[string]string{
+		"password":         "bridge-password",
+		"obfuscation_mode": "chaff",
+		"shaping_profile":  shapingProfileWebBrowsing,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"password":         "bridge-password",
+		"obfuscation_mode": "chaff",
+		"shaping_profile":  shapingProfileWebBrowsing,
+	}, server.Args())
+
+	client, err := NewPTClient(PTTransportCustom, server.Args())
+	require.NoError(t, err)
+	ptRoundTrip(t, client, server)
+}
+
+func TestPTTransport_InvalidArgs(t *testing.T) {
+	for name, args := range map[string]map[string]string{
+		PTTransportObfs4:  {"secret": "short"},
+		PTTransportCustom: {},
+	} {
+		_, err := NewPTClient(name, args)
+		assert.Error(t, err, name)
+	}
+
+	_, err := NewPTClient("meek", nil)
+	assert.Error(t, err)
+	_, err = NewPTServer(PTTransportCustom, map[string]string{"shaping_profile": "unknown"})
+	assert.Error(t, err)
+	assert.Equal(t, []string{PTTransportCustom, PTTransportObfs4}, PTTransports())
+}
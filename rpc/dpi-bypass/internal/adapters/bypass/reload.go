@@ -0,0 +1,96 @@
+package bypass
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+)
+
+// errRestartRequired изменения конфигурации нельзя применить без нового listener
+var errRestartRequired = errors.New("configuration change requires restart")
+
+// hotReloader адаптер, применяющий часть изменений конфигурации к
+// запущенному соединению без перезапуска listener
+type hotReloader interface {
+	// Reload возвращает errRestartRequired, если изменения требуют перезапуска
+	Reload(config *domain.BypassConfig) error
+}
+
+// detacher адаптер, поддерживающий плавную замену соединения
+type detacher interface {
+	// Detach убирает соединение из запущенных, не останавливая его.
+	// После этого ID свободен для нового соединения.
+	Detach(id string) (*detachedConnection, error)
+}
+
+// hotSwappable проверяет, что конфигурации отличаются только правилами и
+// параметрами из hot
+func hotSwappable(current, next *domain.BypassConfig, hot ...string) bool {
+	if current.Method != next.Method || current.Type != next.Type {
+		return false
+	}
+
+	skip := make(map[string]bool, len(hot))
+	for _, key := range hot {
+		skip[key] = true
+	}
+	for key, value := range current.Parameters {
+		if !skip[key] && next.Parameters[key] != value {
+			return false
+		}
+	}
+	for key, value := range next.Parameters {
+		if !skip[key] && current.Parameters[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// detachedConnection соединение адаптера, замененное новым: новые клиенты
+// не принимаются, текущие обслуживаются до таймаута
+type detachedConnection struct {
+	config *domain.BypassConfig
+	// closeListeners закрывает TCP и UDP listener
+	closeListeners func()
+	// wait ждет завершения клиентских соединений, возвращает число
+	// незавершенных
+	wait func(timeout time.Duration) int64
+	// stop прерывает оставшиеся соединения, ждет завершения их горутин и
+	// освобождает ресурсы
+	stop func()
+	// stats возвращает копию статистики соединения
+	stats func() *domain.BypassStats
+	once  sync.Once
+	final *domain.BypassStats
+}
+
+// Config возвращает конфигурацию, с которой работало соединение
+func (d *detachedConnection) Config() *domain.BypassConfig {
+	return d.config
+}
+
+// StopAccepting закрывает listener, порт освобождается для нового соединения
+func (d *detachedConnection) StopAccepting() {
+	d.once.Do(d.closeListeners)
+}
+
+// Drain прекращает прием, ждет текущие соединения не дольше timeout и
+// прерывает оставшиеся. После возврата горутины соединения завершены, а
+// статистика окончательна. Возвращает число прерванных соединений.
+func (d *detachedConnection) Drain(timeout time.Duration) int64 {
+	d.StopAccepting()
+	aborted := d.wait(timeout)
+	d.stop()
+
+	d.final = d.stats()
+	d.final.EndTime = time.Now()
+	return aborted
+}
+
+// Stats возвращает итоговую статистику после Drain
+func (d *detachedConnection) Stats() *domain.BypassStats {
+	return d.final
+}
@@ -0,0 +1,172 @@
+package bypass
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// reloadTestConfig конфигурация Shadowsocks с SOCKS5 входом на порту port
+func reloadTestConfig(t *testing.T, port string, tunnelPort int, rules ...*domain.BypassRule) *domain.BypassConfig {
+	t.Helper()
+
+	return &domain.BypassConfig{
+		ID:     "reload",
+		Method: domain.BypassMethodShadowsocks,
+		Parameters: map[string]string{
+			"local_port":  port,
+			"remote_host": "127.0.0.1",
+			"remote_port": strconv.Itoa(tunnelPort),
+			"password":    tunnelTestPSK,
+			"inbound":     "socks5",
+		},
+		Rules: rules,
+	}
+}
+
+// connectThrough открывает SOCKS5 соединение к назначению через прокси
+func connectThrough(t *testing.T, port string, destination proxyDestination) net.Conn {
+	t.Helper()
+
+	conn := dialProxy(t, net.JoinHostPort("127.0.0.1", port))
+	require.NoError(t, socks5Connect(conn, destination, "", ""))
+	return conn
+}
+
+func freeTestPort(t *testing.T) string {
+	t.Helper()
+
+	port, err := freeLocalPort()
+	require.NoError(t, err)
+	return port
+}
+
+func TestHotSwappable(t *testing.T) {
+	current := &domain.BypassConfig{
+		Method:     domain.BypassMethodShadowsocks,
+		Parameters: map[string]string{"local_port": "1080", "remote_host": "a"},
+	}
+
+	next := &domain.BypassConfig{
+		Method:     domain.BypassMethodShadowsocks,
+		Parameters: map[string]string{"local_port": "1080", "remote_host": "b"},
+	}
+	assert.True(t, hotSwappable(current, next, "remote_host"))
+	assert.False(t, hotSwappable(current, next))
+
+	next.Parameters = map[string]string{"local_port": "1081", "remote_host": "a"}
+	assert.False(t, hotSwappable(current, next, "remote_host"))
+
+	next.Parameters = map[string]string{"local_port": "1080", "remote_host": "a", "udp": "stream"}
+	assert.False(t, hotSwappable(current, next, "remote_host"))
+
+	next.Parameters = current.Parameters
+	next.Method = domain.BypassMethodV2Ray
+	assert.False(t, hotSwappable(current, next, "remote_host"))
+}
+
+func TestMultiBypassAdapter_ReloadRulesInPlace(t *testing.T) {
+	echoPort := startEchoServer(t)
+	port := freeTestPort(t)
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(reloadTestConfig(t, port, startTunnelServer(t))))
+	t.Cleanup(func() { _ = adapter.Stop("reload") })
+
+	established := connectThrough(t, port, localDestination(echoPort))
+	assertEcho(t, established, "before")
+
+	outcome, err := adapter.Reload(reloadTestConfig(t, port, startTunnelServer(t),
+		&domain.BypassRule{ID: "block", Type: domain.RuleTypePort, Action: domain.RuleActionBlock, Pattern: strconv.Itoa(echoPort), Enabled: true},
+	), time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReloadOutcomeApplied, outcome)
+
+	// Установленное соединение не разрывается, новые подчиняются правилам
+	assertEcho(t, established, "after")
+	conn := dialProxy(t, net.JoinHostPort("127.0.0.1", port))
+	err = socks5Connect(conn, localDestination(echoPort), "", "")
+	assert.ErrorContains(t, err, fmt.Sprintf("reply %d", socks5ReplyNotAllowed))
+}
+
+func TestMultiBypassAdapter_ReloadRestartDrains(t *testing.T) {
+	echoPort := startEchoServer(t)
+	tunnelPort := startTunnelServer(t)
+	oldPort, newPort := freeTestPort(t), freeTestPort(t)
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(reloadTestConfig(t, oldPort, tunnelPort)))
+	t.Cleanup(func() { _ = adapter.Stop("reload") })
+
+	inFlight := connectThrough(t, oldPort, localDestination(echoPort))
+
+	outcome, err := adapter.Reload(reloadTestConfig(t, newPort, tunnelPort), 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReloadOutcomeRestarted, outcome)
+	assert.True(t, adapter.IsRunning("reload"))
+
+	// Соединение старого listener обслуживается до завершения
+	assertEcho(t, inFlight, "in flight")
+	assertEcho(t, connectThrough(t, newPort, localDestination(echoPort)), "new port")
+
+	require.Eventually(t, func() bool {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", oldPort), 100*time.Millisecond)
+		if err != nil {
+			return true
+		}
+		conn.Close()
+		return false
+	}, time.Second, 20*time.Millisecond)
+}
+
+func TestMultiBypassAdapter_ReloadSamePortRestart(t *testing.T) {
+	echoPort := startEchoServer(t)
+	tunnelPort := startTunnelServer(t)
+	port := freeTestPort(t)
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(reloadTestConfig(t, port, tunnelPort)))
+	t.Cleanup(func() { _ = adapter.Stop("reload") })
+
+	inFlight := connectThrough(t, port, localDestination(echoPort))
+
+	next := reloadTestConfig(t, port, tunnelPort)
+	next.Parameters["udp_idle_timeout_ms"] = "2000"
+	outcome, err := adapter.Reload(next, 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReloadOutcomeRestarted, outcome)
+
+	assertEcho(t, inFlight, "in flight")
+	assertEcho(t, connectThrough(t, port, localDestination(echoPort)), "same port")
+}
+
+func TestMultiBypassAdapter_ReloadFailureRestoresPrevious(t *testing.T) {
+	echoPort := startEchoServer(t)
+	tunnelPort := startTunnelServer(t)
+	port := freeTestPort(t)
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(reloadTestConfig(t, port, tunnelPort)))
+	t.Cleanup(func() { _ = adapter.Stop("reload") })
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { busy.Close() })
+	busyPort := strconv.Itoa(busy.Addr().(*net.TCPAddr).Port)
+
+	outcome, err := adapter.Reload(reloadTestConfig(t, busyPort, tunnelPort), time.Second)
+	assert.Error(t, err)
+	assert.Equal(t, domain.ReloadOutcomeFailed, outcome)
+
+	// Прежняя конфигурация снова принимает соединения на своем порту
+	assert.True(t, adapter.IsRunning("reload"))
+	assertEcho(t, connectThrough(t, port, localDestination(echoPort)), "restored")
+
+	_, err = adapter.Reload(reloadTestConfig(t, port, tunnelPort), time.Second)
+	assert.NoError(t, err)
+	_, err = adapter.Reload(&domain.BypassConfig{ID: "unknown", Method: domain.BypassMethodShadowsocks}, time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package bypass
+
+import (
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+)
+
+// ruleTarget описание соединения, к которому применяются правила
+type ruleTarget struct {
+	Host     string // доменное имя (SNI, Host, адрес из SOCKS)
+	IP       net.IP
+	Port     int
+	Protocol string // "tls", "http", "tcp", "udp"
+}
+
+// ruleMatcher сопоставляет соединения с правилами конфигурации
+type ruleMatcher struct {
+	set atomic.Pointer[ruleSet]
+}
+
+// ruleSet включенные правила, отсортированные по приоритету
+type ruleSet struct {
+	rules   []*domain.BypassRule
+	regexps map[string]*regexp.Regexp
+}
+
+// newRuleMatcher создает матчер из включенных правил, отсортированных по приоритету
+func newRuleMatcher(rules []*domain.BypassRule) *ruleMatcher {
+	m := &ruleMatcher{}
+	m.Replace(rules)
+	return m
+}
+
+// Replace атомарно заменяет правила. Соединения, уже сопоставленные со
+// старыми правилами, их сохраняют.
+func (m *ruleMatcher) Replace(rules []*domain.BypassRule) {
+	m.set.Store(newRuleSet(rules))
+}
+
+func newRuleSet(rules []*domain.BypassRule) *ruleSet {
+	set := &ruleSet{
+		regexps: make(map[string]*regexp.Regexp),
+	}
+
+	for _, rule := range rules {
+		if rule == nil || !rule.Enabled {
+			continue
+		}
+		if rule.Type == domain.RuleTypeRegex {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				continue
+			}
+			set.regexps[rule.ID] = re
+		}
+		set.rules = append(set.rules, rule)
+	}
+
+	// Больший приоритет проверяется первым
+	sort.SliceStable(set.rules, func(i, j int) bool {
+		return set.rules[i].Priority > set.rules[j].Priority
+	})
+
+	return set
+}
+
+// Match возвращает первое подходящее правило или nil
+func (m *ruleMatcher) Match(target ruleTarget) *domain.BypassRule {
+	set := m.set.Load()
+	for _, rule := range set.rules {
+		if set.matches(rule, target) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// DNSUpstream возвращает сервер DNS первого подходящего правила домена
+// с параметром dns_upstream или пустую строку
+func (m *ruleMatcher) DNSUpstream(host string) string {
+	for _, rule := range m.set.Load().rules {
+		upstream := rule.Parameters["dns_upstream"]
+		if rule.Type == domain.RuleTypeDomain && upstream != "" && matchDomain(rule.Pattern, host) {
+			return upstream
+		}
+	}
+	return ""
+}
+
+// matches проверяет одно правило
+func (s *ruleSet) matches(rule *domain.BypassRule, target ruleTarget) bool {
+	switch rule.Type {
+	case domain.RuleTypeDomain:
+		return matchDomain(rule.Pattern, target.Host)
+	case domain.RuleTypeIP:
+		return matchIP(rule.Pattern, target.IP)
+	case domain.RuleTypePort:
+		return matchPort(rule.Pattern, target.Port)
+	case domain.RuleTypeProtocol:
+		return target.Protocol != "" && strings.EqualFold(rule.Pattern, target.Protocol)
+	case domain.RuleTypeRegex:
+		re := s.regexps[rule.ID]
+		return re != nil && target.Host != "" && re.MatchString(target.Host)
+	default:
+		return false
+	}
+}
+
+// matchDomain поддерживает точное совпадение, "*.example.com" и ".example.com"
+func matchDomain(pattern, host string) bool {
+	if host == "" || pattern == "" {
+		return false
+	}
+
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	if strings.HasPrefix(pattern, ".") {
+		return host == pattern[1:] || strings.HasSuffix(host, pattern)
+	}
+	return host == pattern
+}
+
+// matchIP поддерживает отдельный адрес и CIDR
+func matchIP(pattern string, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if _, network, err := net.ParseCIDR(pattern); err == nil {
+		return network.Contains(ip)
+	}
+	if patternIP := net.ParseIP(pattern); patternIP != nil {
+		return patternIP.Equal(ip)
+	}
+	return false
+}
+
+// matchPort поддерживает отдельный порт и диапазон "1000-2000"
+func matchPort(pattern string, port int) bool {
+	if port == 0 {
+		return false
+	}
+	if from, to, ok := strings.Cut(pattern, "-"); ok {
+		low, err1 := strconv.Atoi(strings.TrimSpace(from))
+		high, err2 := strconv.Atoi(strings.TrimSpace(to))
+		return err1 == nil && err2 == nil && port >= low && port <= high
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(pattern))
+	return err == nil && value == port
+}
+
+// parsePort разбирает номер порта, 0 при ошибке
+func parsePort(value string) int {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 0 || port > 65535 {
+		return 0
+	}
+	return port
+}
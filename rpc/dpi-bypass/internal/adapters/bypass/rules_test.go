@@ -0,0 +1,75 @@
+package bypass
+
+import (
+	"net"
+	"testing"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleMatcher(t *testing.T) {
+	rules := []*domain.BypassRule{
+		{ID: "low", Type: domain.RuleTypeDomain, Pattern: "*.example.com", Priority: 1, Enabled: true},
+		{ID: "high", Type: domain.RuleTypeDomain, Pattern: "video.example.com", Priority: 10, Enabled: true},
+		{ID: "disabled", Type: domain.RuleTypeDomain, Pattern: "off.example.com", Priority: 100, Enabled: false},
+		{ID: "subnet", Type: domain.RuleTypeIP, Pattern: "10.0.0.0/8", Enabled: true},
+		{ID: "ports", Type: domain.RuleTypePort, Pattern: "8000-8100", Enabled: true},
+		{ID: "regex", Type: domain.RuleTypeRegex, Pattern: `^cdn\d+\.`, Enabled: true},
+		{ID: "broken", Type: domain.RuleTypeRegex, Pattern: `(`, Enabled: true},
+		{ID: "quic", Type: domain.RuleTypeProtocol, Pattern: "UDP", Enabled: true},
+	}
+	matcher := newRuleMatcher(rules)
+
+	cases := []struct {
+		name   string
+		target ruleTarget
+		want   string
+	}{
+		{"приоритет", ruleTarget{Host: "video.example.com"}, "high"},
+		{"wildcard", ruleTarget{Host: "WWW.Example.com."}, "low"},
+		{"выключенное правило", ruleTarget{Host: "off.example.com"}, "low"},
+		{"CIDR", ruleTarget{IP: net.ParseIP("10.1.2.3")}, "subnet"},
+		{"диапазон портов", ruleTarget{Port: 8080}, "ports"},
+		{"regex", ruleTarget{Host: "cdn42.other.net"}, "regex"},
+		{"протокол", ruleTarget{Protocol: "udp"}, "quic"},
+		{"нет совпадений", ruleTarget{Host: "example.org", Port: 443}, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := matcher.Match(tc.target)
+			if tc.want == "" {
+				assert.Nil(t, rule)
+				return
+			}
+			if assert.NotNil(t, rule) {
+				assert.Equal(t, tc.want, rule.ID)
+			}
+		})
+	}
+}
+
+func TestMatchDomain(t *testing.T) {
+	assert.True(t, matchDomain(".example.com", "example.com"))
+	assert.True(t, matchDomain(".example.com", "a.example.com"))
+	assert.False(t, matchDomain("*.example.com", "example.com"))
+	assert.False(t, matchDomain("example.com", "badexample.com"))
+	assert.False(t, matchDomain("*.example.com", "badexample.com"))
+}
+
+func TestRuleMatcher_Replace(t *testing.T) {
+	matcher := newRuleMatcher([]*domain.BypassRule{
+		{ID: "old", Type: domain.RuleTypeDomain, Pattern: "example.com", Enabled: true},
+	})
+	target := ruleTarget{Host: "example.com"}
+	assert.Equal(t, "old", matcher.Match(target).ID)
+
+	matcher.Replace([]*domain.BypassRule{
+		{ID: "new", Type: domain.RuleTypeRegex, Pattern: `^example\.`, Enabled: true},
+	})
+	assert.Equal(t, "new", matcher.Match(target).ID)
+
+	matcher.Replace(nil)
+	assert.Nil(t, matcher.Match(target))
+}
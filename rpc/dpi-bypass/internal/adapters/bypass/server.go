@@ -0,0 +1,164 @@
+package bypass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Роли адаптеров с пользователями
+const (
+	roleClient = "client"
+	roleServer = "server"
+)
+
+// parseServerRole разбирает параметр role: сервер принимает потоки клиентов
+// метода и аутентифицирует пользователей, клиент работает как раньше
+func parseServerRole(parameters map[string]string) (bool, error) {
+	switch role := parameters["role"]; role {
+	case "", roleClient:
+		return false, nil
+	case roleServer:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported role: %s", role)
+	}
+}
+
+// userServer серверная сторона методов с пользователями: соединяет поток
+// аутентифицированного пользователя с назначением и учитывает его трафик
+type userServer struct {
+	id             string
+	users          *userRegistry
+	rules          *ruleMatcher
+	udpIdleTimeout time.Duration
+	logger         *zap.Logger
+	// onTraffic учитывает трафик в статистике соединения, onError ошибки
+	onTraffic func(rx, tx int64)
+	onError   func()
+}
+
+// newUserServer создает сервер пользователей для role=server; в роли
+// клиента возвращает nil. parse преобразует ключ пользователя в ключ поиска.
+func newUserServer(config *domain.BypassConfig, rules *ruleMatcher, parse func(credential string) ([]byte, error), logger *zap.Logger) (*userServer, error) {
+	isServer, err := parseServerRole(config.Parameters)
+	if err != nil || !isServer {
+		return nil, err
+	}
+
+	inbound, err := parseInboundConfig(config.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	if inbound.Enabled() {
+		return nil, fmt.Errorf("inbound is not supported in server role")
+	}
+	udp, err := parseUDPConfig(config.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	if udp.Listen {
+		return nil, fmt.Errorf("udp listener is not supported in server role")
+	}
+
+	users := newUserRegistry(parse)
+	if err := users.Replace(config.Users); err != nil {
+		return nil, err
+	}
+
+	return &userServer{
+		id:             config.ID,
+		users:          users,
+		rules:          rules,
+		udpIdleTimeout: udp.IdleTimeout,
+		logger:         logger,
+	}, nil
+}
+
+// serve обслуживает поток пользователя до его закрытия, остановки сервера
+// (ctx) или отзыва пользователя (revoked). network "udp" означает поток
+// датаграмм с префиксом длины к одному назначению (команда UDP VLESS).
+func (s *userServer) serve(ctx, revoked context.Context, account *userAccount, stream net.Conn, destination proxyDestination, network string) {
+	account.connected()
+	defer account.disconnected()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(revoked, cancel)
+	defer stop()
+
+	traffic := func(rx, tx int64) {
+		account.addTraffic(rx, tx)
+		s.onTraffic(rx, tx)
+	}
+
+	if network == "tcp" && destination.Host == udpOverStreamHost {
+		serveUDPOverStream(ctx, stream, udpStreamServerOptions{
+			ID:          s.id,
+			Rules:       s.rules,
+			IdleTimeout: s.udpIdleTimeout,
+			Logger:      s.logger,
+			OnTraffic:   traffic,
+		})
+		return
+	}
+
+	remote, err := s.dial(ctx, destination, network)
+	if err != nil {
+		s.logger.Debug("failed to connect user to destination",
+			zap.Error(err),
+			zap.String("id", s.id),
+			zap.String("user_id", account.id),
+			zap.String("destination", destination.Address()))
+		s.onError()
+		return
+	}
+	defer remote.Close()
+
+	if network == "udp" {
+		relayVLESSPackets(ctx, stream, remote, s.udpIdleTimeout, traffic)
+		return
+	}
+	relayCounting(ctx, stream, remote, traffic)
+}
+
+// dial проверяет правила и подключается к назначению
+func (s *userServer) dial(ctx context.Context, destination proxyDestination, network string) (net.Conn, error) {
+	if rule := s.rules.Match(destination.ruleTarget(network)); rule != nil && rule.Action == domain.RuleActionBlock {
+		return nil, fmt.Errorf("%w: %s", ErrInboundBlocked, destination.Address())
+	}
+	return (&net.Dialer{Timeout: inboundDialTimeout}).DialContext(ctx, network, destination.Address())
+}
+
+// relayCounting пересылает данные в обе стороны до закрытия одной из них
+// или отмены ctx и учитывает трафик по мере передачи
+func relayCounting(ctx context.Context, client, remote net.Conn, traffic func(rx, tx int64)) {
+	_ = relayConns(ctx, client, remote,
+		func() error {
+			_, err := io.Copy(remote, &meteredReader{Reader: client, count: func(n int64) { traffic(n, 0) }})
+			return err
+		},
+		func() error {
+			_, err := io.Copy(client, &meteredReader{Reader: remote, count: func(n int64) { traffic(0, n) }})
+			return err
+		})
+}
+
+// meteredReader сообщает о каждой прочитанной порции данных
+type meteredReader struct {
+	io.Reader
+	count func(n int64)
+}
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.count(int64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,232 @@
+package bypass
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startUserServerAdapter запускает адаптер метода в роли сервера с
+// пользователями и возвращает адаптер и порт сервера
+func startUserServerAdapter(t *testing.T, id string, method domain.BypassMethod, params map[string]string, users ...*domain.BypassUser) (*MultiBypassAdapter, *domain.BypassConfig, string) {
+	t.Helper()
+
+	port := freeTestPort(t)
+	parameters := map[string]string{"local_port": port, "role": roleServer}
+	for key, value := range params {
+		parameters[key] = value
+	}
+	config := &domain.BypassConfig{ID: id, Method: method, Parameters: parameters, Users: users}
+
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(config))
+	t.Cleanup(func() { _ = adapter.Stop(id) })
+	return adapter, config, port
+}
+
+// startUserClient запускает клиент метода с SOCKS5 входом к серверу на
+// serverPort и возвращает порт входа
+func startUserClient(t *testing.T, id string, method domain.BypassMethod, serverPort string, params map[string]string) string {
+	t.Helper()
+
+	port := freeTestPort(t)
+	parameters := map[string]string{
+		"local_port":  port,
+		"remote_host": "127.0.0.1",
+		"remote_port": serverPort,
+		"inbound":     "socks5",
+	}
+	for key, value := range params {
+		parameters[key] = value
+	}
+
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(&domain.BypassConfig{ID: id, Method: method, Parameters: parameters}))
+	t.Cleanup(func() { _ = adapter.Stop(id) })
+	return port
+}
+
+// assertRejected проверяет, что сервер закрыл поток неизвестного пользователя
+func assertRejected(t *testing.T, port string, destination proxyDestination) {
+	t.Helper()
+
+	conn := connectThrough(t, port, destination)
+	_, _ = conn.Write([]byte("ping"))
+	_, err := conn.Read(make([]byte, 4))
+	require.Error(t, err)
+	assert.False(t, isTimeout(err))
+}
+
+// userStats возвращает статистику пользователя сервера
+func userStats(t *testing.T, adapter *MultiBypassAdapter, id, userID string) *domain.UserStats {
+	t.Helper()
+
+	stats, err := adapter.GetStats(id)
+	require.NoError(t, err)
+	require.NotNil(t, stats)
+	for _, user := range stats.Users {
+		if user.UserID == userID {
+			return user
+		}
+	}
+	t.Fatalf("no stats for user %s", userID)
+	return nil
+}
+
+// testKey ключ Shadowsocks 2022 в base64, различный для разных seed
+func testKey(seed byte, size int) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{seed}, size))
+}
+
+func TestShadowsocksServer_MultiUserRevoke(t *testing.T) {
+	echo := localDestination(startEchoServer(t))
+	serverPSK := testKey(1, 16)
+	alice := &domain.BypassUser{ID: "alice", Credential: base64.StdEncoding.EncodeToString([]byte("alice-psk-16byte"))}
+	bob := &domain.BypassUser{ID: "bob", Credential: base64.StdEncoding.EncodeToString([]byte("bob-psk-16-bytes"))}
+
+	params := map[string]string{"password": serverPSK, "encryption": "2022-blake3-aes-128-gcm"}
+	server, config, serverPort := startUserServerAdapter(t, "ss-server", domain.BypassMethodShadowsocks, params, alice, bob)
+
+	clientParams := func(userPSK string) map[string]string {
+		return map[string]string{"password": serverPSK, "encryption": "2022-blake3-aes-128-gcm", "user_psk": userPSK}
+	}
+	alicePort := startUserClient(t, "ss-alice", domain.BypassMethodShadowsocks, serverPort, clientParams(alice.Credential))
+	bobPort := startUserClient(t, "ss-bob", domain.BypassMethodShadowsocks, serverPort, clientParams(bob.Credential))
+	strangerPort := startUserClient(t, "ss-stranger", domain.BypassMethodShadowsocks, serverPort, clientParams(testKey(2, 16)))
+
+	aliceConn := connectThrough(t, alicePort, echo)
+	assertEcho(t, aliceConn, "hello from alice")
+	assertEcho(t, connectThrough(t, bobPort, echo), "bob")
+	assertRejected(t, strangerPort, echo)
+
+	stats := userStats(t, server, "ss-server", "alice")
+	assert.Equal(t, int64(len("hello from alice")), stats.BytesReceived)
+	assert.Equal(t, int64(len("hello from alice")), stats.BytesSent)
+	assert.Equal(t, int64(1), stats.Connections)
+	assert.Equal(t, int64(1), stats.ActiveConnections)
+	assert.False(t, stats.Revoked)
+	assert.False(t, stats.LastActivity.IsZero())
+
+	// Отзыв применяется на месте и разрывает установленное соединение
+	revoked := *config
+	revoked.Users = []*domain.BypassUser{{ID: "alice", Credential: alice.Credential, Revoked: true}, bob}
+	outcome, err := server.Reload(&revoked, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReloadOutcomeApplied, outcome)
+
+	_, err = aliceConn.Read(make([]byte, 1))
+	require.Error(t, err)
+	assert.False(t, isTimeout(err))
+	assertRejected(t, alicePort, echo)
+	assertEcho(t, connectThrough(t, bobPort, echo), "bob still works")
+
+	assert.Eventually(t, func() bool {
+		return userStats(t, server, "ss-server", "alice").ActiveConnections == 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, userStats(t, server, "ss-server", "alice").Revoked)
+	assert.Equal(t, int64(1), userStats(t, server, "ss-server", "alice").Connections)
+}
+
+func TestV2RayServer_VLESSUsers(t *testing.T) {
+	echo := localDestination(startEchoServer(t))
+	udpEchoPort := startUDPEchoServer(t)
+	alice := &domain.BypassUser{ID: "alice", Credential: uuid.NewString()}
+
+	server, _, serverPort := startUserServerAdapter(t, "vless-server", domain.BypassMethodV2Ray, nil, alice)
+
+	alicePort := startUserClient(t, "vless-alice", domain.BypassMethodV2Ray, serverPort, map[string]string{"uuid": alice.Credential})
+	strangerPort := startUserClient(t, "vless-stranger", domain.BypassMethodV2Ray, serverPort, map[string]string{"uuid": uuid.NewString()})
+
+	assertEcho(t, connectThrough(t, alicePort, echo), "vless tcp")
+	assertRejected(t, strangerPort, echo)
+
+	// Нативный UDP VLESS: датаграммы с префиксом длины в потоке команды UDP
+	udpPort := freeTestPort(t)
+	startUserClient(t, "vless-udp", domain.BypassMethodV2Ray, serverPort, map[string]string{
+		"local_port": udpPort,
+		"inbound":    "",
+		"uuid":       alice.Credential,
+		"udp":        "true",
+		"udp_target": "127.0.0.1:" + strconv.Itoa(udpEchoPort),
+		"udp_mode":   "native",
+	})
+	port, err := strconv.Atoi(udpPort)
+	require.NoError(t, err)
+	assert.Equal(t, "vless udp", udpRelayExchange(t, dialUDPRelay(t, port), "vless udp"))
+
+	stats := userStats(t, server, "vless-server", "alice")
+	assert.Equal(t, int64(2), stats.Connections)
+	assert.Equal(t, int64(len("vless tcp")+len("vless udp")), stats.BytesReceived)
+}
+
+func TestObfs4Server_UserSecrets(t *testing.T) {
+	echo := localDestination(startEchoServer(t))
+	secret := hex.EncodeToString([]byte("alice obfs4 secret"))
+	alice := &domain.BypassUser{ID: "alice", Credential: secret}
+
+	server, _, serverPort := startUserServerAdapter(t, "obfs4-server", domain.BypassMethodObfs4, nil, alice)
+
+	alicePort := startUserClient(t, "obfs4-alice", domain.BypassMethodObfs4, serverPort, map[string]string{"user_secret": secret})
+	strangerPort := startUserClient(t, "obfs4-stranger", domain.BypassMethodObfs4, serverPort, map[string]string{
+		"user_secret": hex.EncodeToString([]byte("someone else's secret")),
+	})
+
+	assertEcho(t, connectThrough(t, alicePort, echo), "obfs4 user stream")
+	assertRejected(t, strangerPort, echo)
+
+	stats := userStats(t, server, "obfs4-server", "alice")
+	assert.Equal(t, int64(len("obfs4 user stream")), stats.BytesSent)
+}
+
+func TestServerRole_InvalidConfig(t *testing.T) {
+	valid := &domain.BypassUser{ID: "alice", Credential: uuid.NewString()}
+
+	for name, tc := range map[string]struct {
+		params  map[string]string
+		users   []*domain.BypassUser
+		wantErr bool
+	}{
+		"неизвестная роль":     {params: map[string]string{"role": "relay"}, wantErr: true},
+		"вход в роли сервера":  {params: map[string]string{"inbound": "socks5"}, wantErr: true},
+		"UDP listener":         {params: map[string]string{"udp": "true", "udp_target": "127.0.0.1:53"}, wantErr: true},
+		"uuid в роли сервера":  {params: map[string]string{"uuid": uuid.NewString()}, wantErr: true},
+		"неверный ключ":        {users: []*domain.BypassUser{valid, {ID: "bob", Credential: "not-a-uuid"}}, wantErr: true},
+		"отозванный не мешает": {users: []*domain.BypassUser{valid, {ID: "bob", Credential: "not-a-uuid", Revoked: true}}},
+		"без пользователей":    {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			params := map[string]string{"local_port": "0", "role": roleServer}
+			for key, value := range tc.params {
+				params[key] = value
+			}
+			adapter := NewV2RayAdapter(zap.NewNop())
+			err := adapter.Start(&domain.BypassConfig{ID: "server", Method: domain.BypassMethodV2Ray, Parameters: params, Users: tc.users})
+			t.Cleanup(func() { _ = adapter.Stop("server") })
+
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("user_psk без входа", func(t *testing.T) {
+		adapter := NewShadowsocksAdapter(zap.NewNop())
+		err := adapter.Start(&domain.BypassConfig{ID: "client", Parameters: map[string]string{
+			"local_port": "0",
+			"password":   testKey(1, 32),
+			"user_psk":   testKey(2, 32),
+		}})
+		assert.Error(t, err)
+	})
+}
@@ -2,9 +2,11 @@ package bypass
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
@@ -16,15 +18,38 @@ type ShadowsocksAdapter struct {
 	running map[string]*shadowsocksConnection
 	mutex   sync.RWMutex
 	logger  *zap.Logger
+	signals *SignalMonitor
 }
 
 type shadowsocksConnection struct {
-	config     *domain.BypassConfig
+	// config заменяется при перезагрузке без перезапуска
+	config     atomic.Pointer[domain.BypassConfig]
 	listener   net.Listener
 	ctx        context.Context
 	cancel     context.CancelFunc
 	stats      *domain.BypassStats
 	statsMutex sync.RWMutex
+	// rules правила, заменяемые при перезагрузке
+	rules *ruleMatcher
+	// tracker учитывает клиентские соединения для плавной остановки
+	tracker *connectionTracker
+	// inbound входящий SOCKS5/HTTP прокси; nil в режиме raw
+	inbound *inboundFrontend
+	// UDP: режим, поток датаграмм к серверу и listener на local_port
+	udpMode  udpMode
+	udpPool  *udpStreamPool
+	udpRelay *udpRelay
+	// server серверная роль с пользователями; nil в роли клиента
+	server *userServer
+	// identityPSK PSK сервера (password), userPSK ключ пользователя клиента.
+	// Клиент с userPSK передает заголовок идентификации и шифрует сессию
+	// ключом пользователя, без него - PSK сервера (SIP022)
+	identityPSK []byte
+	userPSK     []byte
+	// salts соли принятых сессий для защиты от повтора
+	salts *replayCache
+	// shaping профиль формы трафика к удаленной стороне; nil без профиля
+	shaping *shapingConfig
 }
 
 // NewShadowsocksAdapter создает новый Shadowsocks адаптер
@@ -35,6 +60,11 @@ func NewShadowsocksAdapter(logger *zap.Logger) *ShadowsocksAdapter {
 	}
 }
 
+// observeSignals подключает монитор сигналов цензуры к исходящим соединениям
+func (s *ShadowsocksAdapter) observeSignals(monitor *SignalMonitor) {
+	s.signals = monitor
+}
+
 // Start запускает Shadowsocks сервер
 func (s *ShadowsocksAdapter) Start(config *domain.BypassConfig) error {
 	s.mutex.Lock()
@@ -44,6 +74,41 @@ func (s *ShadowsocksAdapter) Start(config *domain.BypassConfig) error {
 		return fmt.Errorf("shadowsocks connection already running: %s", config.ID)
 	}
 
+	inbound, err := parseInboundConfig(config.Parameters)
+	if err != nil {
+		return err
+	}
+	udp, err := parseUDPConfig(config.Parameters)
+	if err != nil {
+		return err
+	}
+	shaping, err := parseShaping(config.Parameters, config.Parameters["password"])
+	if err != nil {
+		return err
+	}
+
+	rules := newRuleMatcher(config.Rules)
+	keySize := shadowsocks2022KeySize(config.Parameters["encryption"])
+	server, err := newUserServer(config, rules, func(credential string) ([]byte, error) {
+		key, err := parseShadowsocks2022Key(credential, keySize)
+		if err != nil {
+			return nil, err
+		}
+		return shadowsocks2022UserHash(key), nil
+	}, s.logger)
+	if err != nil {
+		return err
+	}
+	// Нативный UDP идет мимо потока к серверу
+	tunnel := server != nil || inbound.Enabled() || (udp.Listen && udp.Mode != udpModeNative)
+	identityPSK, userPSK, err := parseShadowsocksIdentity(config.Parameters, server != nil, tunnel, keySize)
+	if err != nil {
+		return err
+	}
+	if userPSK != nil && udp.Mode == udpModeNative {
+		return fmt.Errorf("udp_mode native is not supported with user_psk")
+	}
+
 	// Создаем контекст для управления жизненным циклом
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -54,17 +119,24 @@ func (s *ShadowsocksAdapter) Start(config *domain.BypassConfig) error {
 	}
 
 	// Создаем listener
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", localPort))
+	listener, err := inbound.Listen(localPort)
 	if err != nil {
 		cancel()
 		return fmt.Errorf("failed to create listener: %w", err)
 	}
 
 	conn := &shadowsocksConnection{
-		config:   config,
-		listener: listener,
-		ctx:      ctx,
-		cancel:   cancel,
+		listener:    listener,
+		ctx:         ctx,
+		cancel:      cancel,
+		rules:       rules,
+		tracker:     newConnectionTracker(),
+		udpMode:     udp.Mode,
+		server:      server,
+		identityPSK: identityPSK,
+		userPSK:     userPSK,
+		salts:       newReplayCache(shadowsocks2022SaltTTL),
+		shaping:     shaping,
 		stats: &domain.BypassStats{
 			ID:                     config.ID,
 			ConfigID:               config.ID,
@@ -76,6 +148,35 @@ func (s *ShadowsocksAdapter) Start(config *domain.BypassConfig) error {
 			EndTime:                time.Now(),
 		},
 	}
+	conn.config.Store(config)
+	conn.udpPool = newUDPStreamPool(func() (net.Conn, error) {
+		return s.dialTunnel(conn, udpOverStreamDestination())
+	})
+	packets := s.packetOutbound(conn)
+
+	if server != nil {
+		server.onTraffic = func(rx, tx int64) { s.updateStats(conn, rx, tx) }
+		server.onError = func() { s.incrementErrorCount(conn) }
+	}
+
+	conn.inbound = newInboundFrontend(inbound, conn.rules, config.ID, s.logger)
+	if conn.inbound != nil {
+		conn.inbound.packets = packets
+		conn.inbound.onTraffic = func(rx, tx int64) { s.updateStats(conn, rx, tx) }
+		conn.inbound.onError = func() { s.incrementErrorCount(conn) }
+	}
+
+	conn.udpRelay, err = newUDPRelay(udp, listener, conn.rules, packets, config.ID, s.logger)
+	if err != nil {
+		listener.Close()
+		cancel()
+		return err
+	}
+	if conn.udpRelay != nil {
+		conn.udpRelay.onTraffic = func(rx, tx int64) { s.updateStats(conn, rx, tx) }
+		conn.udpRelay.onError = func() { s.incrementErrorCount(conn) }
+		conn.udpRelay.start()
+	}
 
 	s.running[config.ID] = conn
 
@@ -94,25 +195,14 @@ func (s *ShadowsocksAdapter) Start(config *domain.BypassConfig) error {
 	return nil
 }
 
-// Stop останавливает Shadowsocks сервер
+// Stop останавливает Shadowsocks сервер: закрывает listener, прерывает
+// соединения и ждет завершения их горутин
 func (s *ShadowsocksAdapter) Stop(id string) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	conn, exists := s.running[id]
-	if !exists {
-		return fmt.Errorf("shadowsocks connection not found: %s", id)
-	}
-
-	// Отменяем контекст
-	conn.cancel()
-
-	// Закрываем listener
-	if err := conn.listener.Close(); err != nil {
-		s.logger.Error("failed to close listener", zap.Error(err), zap.String("id", id))
+	detached, err := s.Detach(id)
+	if err != nil {
+		return err
 	}
-
-	delete(s.running, id)
+	detached.Drain(0)
 
 	s.logger.Info("shadowsocks server stopped", zap.String("id", id))
 	return nil
@@ -128,12 +218,20 @@ func (s *ShadowsocksAdapter) GetStats(id string) (*domain.BypassStats, error) {
 		return nil, nil
 	}
 
+	return s.snapshotStats(conn), nil
+}
+
+// snapshotStats возвращает копию статистики соединения
+func (s *ShadowsocksAdapter) snapshotStats(conn *shadowsocksConnection) *domain.BypassStats {
 	conn.statsMutex.RLock()
 	defer conn.statsMutex.RUnlock()
 
 	// Возвращаем копию статистики
 	stats := *conn.stats
-	return &stats, nil
+	if conn.server != nil {
+		stats.Users = conn.server.users.Stats()
+	}
+	return &stats
 }
 
 // IsRunning проверяет, запущен ли Shadowsocks сервер
@@ -145,8 +243,65 @@ func (s *ShadowsocksAdapter) IsRunning(id string) bool {
 	return exists
 }
 
+// Reload применяет правила, адрес сервера и пользователей к запущенному
+// соединению. Остальные изменения требуют перезапуска.
+func (s *ShadowsocksAdapter) Reload(config *domain.BypassConfig) error {
+	s.mutex.RLock()
+	conn, exists := s.running[config.ID]
+	s.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("shadowsocks connection not found: %s", config.ID)
+	}
+	if !hotSwappable(conn.config.Load(), config, "remote_host", "remote_port") {
+		return errRestartRequired
+	}
+	if conn.server != nil {
+		if err := conn.server.users.Replace(config.Users); err != nil {
+			return err
+		}
+	}
+
+	conn.rules.Replace(config.Rules)
+	conn.config.Store(config)
+
+	s.logger.Info("shadowsocks configuration reloaded", zap.String("id", config.ID))
+	return nil
+}
+
+// Detach убирает соединение из запущенных для плавной замены новым
+func (s *ShadowsocksAdapter) Detach(id string) (*detachedConnection, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	conn, exists := s.running[id]
+	if !exists {
+		return nil, fmt.Errorf("shadowsocks connection not found: %s", id)
+	}
+	delete(s.running, id)
+
+	return &detachedConnection{
+		config: conn.config.Load(),
+		closeListeners: func() {
+			conn.listener.Close()
+			if conn.udpRelay != nil {
+				conn.udpRelay.Close()
+			}
+		},
+		wait: conn.tracker.wait,
+		stop: func() {
+			conn.cancel()
+			conn.tracker.abort()
+			conn.udpPool.Close()
+			conn.tracker.done()
+		},
+		stats: func() *domain.BypassStats { return s.snapshotStats(conn) },
+	}, nil
+}
+
 // handleConnections обрабатывает входящие соединения
 func (s *ShadowsocksAdapter) handleConnections(conn *shadowsocksConnection) {
+	defer conn.tracker.acceptStopped()
+
 	for {
 		select {
 		case <-conn.ctx.Done():
@@ -154,17 +309,17 @@ func (s *ShadowsocksAdapter) handleConnections(conn *shadowsocksConnection) {
 		default:
 			clientConn, err := conn.listener.Accept()
 			if err != nil {
-				if conn.ctx.Err() != nil {
-					// Контекст отменен, выходим
+				if conn.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+					// Контекст отменен или listener закрыт, выходим
 					return
 				}
-				s.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.ID))
+				s.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.Load().ID))
 				s.incrementErrorCount(conn)
 				continue
 			}
 
 			// Обрабатываем соединение в отдельной горутине
-			go s.handleClientConnection(conn, clientConn)
+			conn.tracker.handle(clientConn, func() { s.handleClientConnection(conn, clientConn) })
 		}
 	}
 }
@@ -176,60 +331,162 @@ func (s *ShadowsocksAdapter) handleClientConnection(conn *shadowsocksConnection,
 	// Увеличиваем счетчик соединений
 	s.incrementConnections(conn)
 
-	// Получаем параметры удаленного сервера
-	remoteHost := conn.config.Parameters["remote_host"]
-	remotePort := conn.config.Parameters["remote_port"]
+	if conn.server != nil {
+		s.serveUser(conn, shapeConn(clientConn, conn.shaping))
+		return
+	}
+
+	var remoteConn net.Conn
+	if conn.inbound != nil {
+		// Назначение передается серверу заголовком адреса в формате SOCKS5
+		var ok bool
+		clientConn, remoteConn, ok = conn.inbound.serve(conn.ctx, clientConn, func(destination proxyDestination) (net.Conn, error) {
+			return s.dialTunnel(conn, destination)
+		})
+		if !ok {
+			return
+		}
+	} else {
+		var err error
+		remoteConn, err = s.dialRemote(conn)
+		if err != nil {
+			s.logger.Error("failed to connect to remote server",
+				zap.Error(err),
+				zap.String("id", conn.config.Load().ID))
+			s.incrementErrorCount(conn)
+			return
+		}
+	}
+	defer remoteConn.Close()
+
+	// Обе горутины завершаются до выхода: соединения закрываются, как только
+	// останавливается одно из направлений
+	err := relayConns(conn.ctx, clientConn, remoteConn,
+		func() error {
+			// Копируем данные от клиента к серверу
+			bytes, err := s.copyData(clientConn, remoteConn, conn, true)
+			s.updateStats(conn, bytes, 0)
+			return err
+		},
+		func() error {
+			// Копируем данные от сервера к клиенту
+			bytes, err := s.copyData(remoteConn, clientConn, conn, false)
+			s.updateStats(conn, 0, bytes)
+			return err
+		})
+	if err != nil {
+		s.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.Load().ID))
+	}
+}
+
+// remoteAddr возвращает адрес удаленного сервера
+func (s *ShadowsocksAdapter) remoteAddr(conn *shadowsocksConnection) string {
+	remoteHost := conn.config.Load().Parameters["remote_host"]
+	remotePort := conn.config.Load().Parameters["remote_port"]
 	if remoteHost == "" {
 		remoteHost = "127.0.0.1"
 	}
 	if remotePort == "" {
 		remotePort = "8080"
 	}
+	return net.JoinHostPort(remoteHost, remotePort)
+}
 
-	// Подключаемся к удаленному серверу
-	remoteConn, err := net.DialTimeout("tcp",
-		fmt.Sprintf("%s:%s", remoteHost, remotePort),
-		10*time.Second)
+// dialRemote подключается к удаленному серверу
+func (s *ShadowsocksAdapter) dialRemote(conn *shadowsocksConnection) (net.Conn, error) {
+	remote, err := s.signals.Dial(context.Background(), conn.config.Load(), s.remoteAddr(conn), 10*time.Second)
 	if err != nil {
-		s.logger.Error("failed to connect to remote server",
-			zap.Error(err),
-			zap.String("id", conn.config.ID),
-			zap.String("remote", remoteHost))
-		s.incrementErrorCount(conn)
-		return
+		return nil, err
 	}
-	defer remoteConn.Close()
+	return shapeConn(remote, conn.shaping), nil
+}
 
-	// Создаем каналы для передачи данных
-	errChan := make(chan error, 2)
+// dialTunnel открывает сессию SIP022 к назначению через сервер
+func (s *ShadowsocksAdapter) dialTunnel(conn *shadowsocksConnection, destination proxyDestination) (net.Conn, error) {
+	remote, err := s.dialRemote(conn)
+	if err != nil {
+		return nil, err
+	}
+	psk, identityPSK := conn.identityPSK, []byte(nil)
+	if conn.userPSK != nil {
+		psk, identityPSK = conn.userPSK, conn.identityPSK
+	}
+	session, err := dialShadowsocks2022(remote, psk, identityPSK, destination)
+	if err != nil {
+		remote.Close()
+		return nil, err
+	}
+	return session, nil
+}
 
-	// Копируем данные от клиента к серверу
-	go func() {
-		bytes, err := s.copyData(clientConn, remoteConn, conn, true)
+// serveUser обслуживает сессию SIP022 клиента в роли сервера: пользователь
+// определяется по заголовку идентификации, поток шифруется его PSK
+func (s *ShadowsocksAdapter) serveUser(conn *shadowsocksConnection, clientConn net.Conn) {
+	if err := clientConn.SetReadDeadline(time.Now().Add(defaultInboundHandshakeTimeout)); err != nil {
+		return
+	}
+	var account *userAccount
+	var revoked context.Context
+	session, destination, err := acceptShadowsocks2022(clientConn, conn.identityPSK, conn.salts, func(userHash []byte) ([]byte, error) {
+		var credential string
+		var err error
+		account, credential, revoked, err = conn.server.users.LookupCredential(userHash)
 		if err != nil {
-			errChan <- err
+			return nil, err
 		}
-		s.updateStats(conn, bytes, 0)
-	}()
+		return parseShadowsocks2022Key(credential, len(conn.identityPSK))
+	})
+	if err == nil {
+		err = clientConn.SetReadDeadline(time.Time{})
+	}
+	if err != nil {
+		s.logger.Debug("failed to authenticate shadowsocks user", zap.Error(err), zap.String("id", conn.config.Load().ID))
+		s.incrementErrorCount(conn)
+		return
+	}
 
-	// Копируем данные от сервера к клиенту
-	go func() {
-		bytes, err := s.copyData(remoteConn, clientConn, conn, false)
-		if err != nil {
-			errChan <- err
+	conn.server.serve(conn.ctx, revoked, account, session, destination, "tcp")
+}
+
+// parseShadowsocksIdentity разбирает PSK сервера (password) и ключ
+// пользователя (user_psk). Ключи нужны серверу и клиенту с входящим прокси
+// или UDP (tunnel): поток к серверу шифруется SIP022. В режиме raw клиент
+// пересылает поток без изменений.
+func parseShadowsocksIdentity(parameters map[string]string, isServer, tunnel bool, keySize int) ([]byte, []byte, error) {
+	value := parameters["user_psk"]
+	if isServer && value != "" {
+		return nil, nil, fmt.Errorf("user_psk is not supported in server role")
+	}
+	if !tunnel {
+		if value != "" {
+			return nil, nil, fmt.Errorf("user_psk requires inbound or udp")
 		}
-		s.updateStats(conn, 0, bytes)
-	}()
+		return nil, nil, nil
+	}
 
-	// Ждем завершения или ошибки
-	select {
-	case <-conn.ctx.Done():
-		return
-	case err := <-errChan:
-		if err != nil {
-			s.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.ID))
+	identityPSK, err := parseShadowsocks2022Key(parameters["password"], keySize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid password: %w", err)
+	}
+	if value == "" {
+		return identityPSK, nil, nil
+	}
+	userPSK, err := parseShadowsocks2022Key(value, keySize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid user_psk: %w", err)
+	}
+	return identityPSK, userPSK, nil
+}
+
+// packetOutbound возвращает фабрику UDP каналов: нативный UDP Shadowsocks
+// или датаграммы внутри TCP потока
+func (s *ShadowsocksAdapter) packetOutbound(conn *shadowsocksConnection) func() (packetOutbound, error) {
+	if conn.udpMode == udpModeNative {
+		return func() (packetOutbound, error) {
+			return newShadowsocksPacketOutbound(s.remoteAddr(conn))
 		}
 	}
+	return conn.udpPool.Session
 }
 
 // copyData копирует данные между соединениями
@@ -238,34 +495,24 @@ func (s *ShadowsocksAdapter) copyData(src, dst net.Conn, conn *shadowsocksConnec
 	var totalBytes int64
 
 	for {
-		select {
-		case <-conn.ctx.Done():
-			return totalBytes, nil
-		default:
-			// Устанавливаем таймаут для чтения
-			if err := src.SetReadDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		n, err := src.Read(buffer)
+		if err != nil {
+			return totalBytes, err
+		}
+
+		if n > 0 {
+			// Устанавливаем таймаут для записи
+			if err := dst.SetWriteDeadline(time.Now().Add(relayWriteTimeout)); err != nil {
 				return totalBytes, err
 			}
 
-			n, err := src.Read(buffer)
+			_, err = dst.Write(buffer[:n])
 			if err != nil {
 				return totalBytes, err
 			}
 
-			if n > 0 {
-				// Устанавливаем таймаут для записи
-				if err := dst.SetWriteDeadline(time.Now().Add(30 * time.Second)); err != nil {
-					return totalBytes, err
-				}
-
-				_, err = dst.Write(buffer[:n])
-				if err != nil {
-					return totalBytes, err
-				}
-
-				totalBytes += int64(n)
-				s.updateLastActivity(conn)
-			}
+			totalBytes += int64(n)
+			s.updateLastActivity(conn)
 		}
 	}
 }
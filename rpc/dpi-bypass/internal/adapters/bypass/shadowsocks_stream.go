@@ -0,0 +1,285 @@
+package bypass
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"time"
+
+	"lukechampine.com/blake3"
+)
+
+// Поток Shadowsocks 2022 (SIP022) поверх TCP. Запрос клиента: соль,
+// заголовок идентификации пользователя (EIH, только на сервере с
+// пользователями), зашифрованный фиксированный заголовок (тип, время, длина
+// переменного заголовка), зашифрованный переменный заголовок (адрес
+// назначения SOCKS5, padding, начальные данные), затем блоки длины и данных.
+// Ответ сервера: соль, зашифрованный заголовок (тип, время, соль запроса,
+// длина первого блока), первый блок данных, затем обычные блоки.
+const (
+	// Контекст BLAKE3 для ключа сессии
+	shadowsocks2022SessionContext = "shadowsocks 2022 session subkey"
+
+	shadowsocks2022TypeRequest  = 0
+	shadowsocks2022TypeResponse = 1
+
+	// Размер фиксированного заголовка запроса: тип, время, длина
+	shadowsocks2022RequestHeaderSize = 1 + 8 + 2
+	// Максимальный размер данных блока
+	shadowsocks2022MaxPayloadSize = 0xFFFF
+	// Максимальная длина случайного padding переменного заголовка
+	shadowsocks2022MaxPaddingSize = 900
+	// Допустимое расхождение времени заголовка
+	shadowsocks2022MaxTimeDiff = 30 * time.Second
+	// Время хранения соли для защиты от повтора: вдвое больше окна времени
+	shadowsocks2022SaltTTL = 2 * shadowsocks2022MaxTimeDiff
+)
+
+var (
+	// ErrShadowsocksReplay сервер уже принимал сессию с этой солью
+	ErrShadowsocksReplay = errors.New("shadowsocks 2022 salt replayed")
+	// ErrShadowsocksTimestamp время заголовка вне допустимого окна
+	ErrShadowsocksTimestamp = errors.New("shadowsocks 2022 timestamp out of window")
+)
+
+// shadowsocks2022SessionAEAD шифр сессии: AES-GCM на ключе BLAKE3
+// derive_key от PSK и соли
+func shadowsocks2022SessionAEAD(psk, salt []byte) (cipher.AEAD, error) {
+	material := make([]byte, 0, len(psk)+len(salt))
+	material = append(append(material, psk...), salt...)
+	subkey := make([]byte, len(psk))
+	blake3.DeriveKey(subkey, shadowsocks2022SessionContext, material)
+
+	block, err := aes.NewCipher(subkey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// checkShadowsocks2022Time проверяет время заголовка
+func checkShadowsocks2022Time(timestamp uint64) error {
+	diff := time.Since(time.Unix(int64(timestamp), 0))
+	if diff > shadowsocks2022MaxTimeDiff || diff < -shadowsocks2022MaxTimeDiff {
+		return ErrShadowsocksTimestamp
+	}
+	return nil
+}
+
+// shadowsocks2022Conn зашифрованный поток сессии SIP022. Ответный
+// заголовок читается (клиент) или пишется (сервер) при первом обращении.
+type shadowsocks2022Conn struct {
+	net.Conn
+	// psk ключ сессии: PSK пользователя или единственный PSK сервера
+	psk         []byte
+	requestSalt []byte
+	reader      *aeadReader
+	writer      *aeadWriter
+}
+
+// dialShadowsocks2022 начинает сессию к назначению destination. С identityPSK
+// клиент передает заголовок идентификации, а поток шифруется ключом
+// пользователя psk.
+func dialShadowsocks2022(conn net.Conn, psk, identityPSK []byte, destination proxyDestination) (net.Conn, error) {
+	salt := make([]byte, len(psk))
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	request := append([]byte(nil), salt...)
+	if identityPSK != nil {
+		var err error
+		if request, err = appendShadowsocks2022Identity(request, identityPSK, psk, salt); err != nil {
+			return nil, err
+		}
+	}
+
+	aead, err := shadowsocks2022SessionAEAD(psk, salt)
+	if err != nil {
+		return nil, err
+	}
+	writer := newAEADWriter(conn, aead, shadowsocks2022MaxPayloadSize)
+
+	// Начальных данных нет, поэтому padding обязателен
+	paddingSize, err := rand.Int(rand.Reader, big.NewInt(shadowsocks2022MaxPaddingSize))
+	if err != nil {
+		return nil, err
+	}
+	padding := make([]byte, paddingSize.Int64()+1)
+	if _, err := rand.Read(padding); err != nil {
+		return nil, err
+	}
+	variable := appendSocksAddr(nil, destination)
+	variable = binary.BigEndian.AppendUint16(variable, uint16(len(padding)))
+	variable = append(variable, padding...)
+
+	fixed := []byte{shadowsocks2022TypeRequest}
+	fixed = binary.BigEndian.AppendUint64(fixed, uint64(time.Now().Unix()))
+	fixed = binary.BigEndian.AppendUint16(fixed, uint16(len(variable)))
+
+	request = writer.seal(request, fixed)
+	request = writer.seal(request, variable)
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+
+	return &shadowsocks2022Conn{Conn: conn, psk: psk, requestSalt: salt, writer: writer}, nil
+}
+
+// acceptShadowsocks2022 принимает сессию и возвращает поток и адрес
+// назначения. lookup находит PSK пользователя по хешу из заголовка
+// идентификации; без lookup сервер работает с единственным PSK. Соли
+// принятых сессий запоминаются в salts.
+func acceptShadowsocks2022(conn net.Conn, psk []byte, salts *replayCache, lookup func(userHash []byte) ([]byte, error)) (*shadowsocks2022Conn, proxyDestination, error) {
+	salt := make([]byte, len(psk))
+	if _, err := io.ReadFull(conn, salt); err != nil {
+		return nil, proxyDestination{}, fmt.Errorf("failed to read salt: %w", err)
+	}
+	sessionPSK := psk
+	if lookup != nil {
+		userHash, err := readShadowsocks2022Identity(conn, psk, salt)
+		if err != nil {
+			return nil, proxyDestination{}, err
+		}
+		if sessionPSK, err = lookup(userHash); err != nil {
+			return nil, proxyDestination{}, err
+		}
+	}
+
+	aead, err := shadowsocks2022SessionAEAD(sessionPSK, salt)
+	if err != nil {
+		return nil, proxyDestination{}, err
+	}
+	reader := newAEADReader(conn, aead)
+
+	fixed, err := reader.open(shadowsocks2022RequestHeaderSize)
+	if err != nil {
+		return nil, proxyDestination{}, fmt.Errorf("failed to read request header: %w", err)
+	}
+	if fixed[0] != shadowsocks2022TypeRequest {
+		return nil, proxyDestination{}, fmt.Errorf("unexpected shadowsocks 2022 header type: %d", fixed[0])
+	}
+	if err := checkShadowsocks2022Time(binary.BigEndian.Uint64(fixed[1:9])); err != nil {
+		return nil, proxyDestination{}, err
+	}
+	// Соль запоминается после проверки заголовка: мусор не вытесняет
+	// настоящие сессии
+	if !salts.Add(salt) {
+		return nil, proxyDestination{}, ErrShadowsocksReplay
+	}
+
+	variable, err := reader.open(int(binary.BigEndian.Uint16(fixed[9:])))
+	if err != nil {
+		return nil, proxyDestination{}, fmt.Errorf("failed to read request header: %w", err)
+	}
+	destination, n, err := parseSocksAddr(variable)
+	if err != nil {
+		return nil, proxyDestination{}, err
+	}
+	if len(variable) < n+2 {
+		return nil, proxyDestination{}, fmt.Errorf("invalid shadowsocks 2022 request header")
+	}
+	paddingSize := int(binary.BigEndian.Uint16(variable[n:]))
+	payload := variable[n+2:]
+	if len(payload) < paddingSize || paddingSize > shadowsocks2022MaxPaddingSize {
+		return nil, proxyDestination{}, fmt.Errorf("invalid shadowsocks 2022 padding: %d", paddingSize)
+	}
+	payload = payload[paddingSize:]
+	if paddingSize == 0 && len(payload) == 0 {
+		return nil, proxyDestination{}, fmt.Errorf("shadowsocks 2022 request without padding and payload")
+	}
+	reader.pending = payload
+
+	return &shadowsocks2022Conn{Conn: conn, psk: sessionPSK, requestSalt: salt, reader: reader}, destination, nil
+}
+
+// readResponseHeader читает заголовок ответа сервера и первый блок данных
+func (c *shadowsocks2022Conn) readResponseHeader() error {
+	salt := make([]byte, len(c.psk))
+	if _, err := io.ReadFull(c.Conn, salt); err != nil {
+		return err
+	}
+	aead, err := shadowsocks2022SessionAEAD(c.psk, salt)
+	if err != nil {
+		return err
+	}
+	reader := newAEADReader(c.Conn, aead)
+
+	fixed, err := reader.open(1 + 8 + len(c.requestSalt) + 2)
+	if err != nil {
+		return fmt.Errorf("failed to read response header: %w", err)
+	}
+	if fixed[0] != shadowsocks2022TypeResponse {
+		return fmt.Errorf("unexpected shadowsocks 2022 header type: %d", fixed[0])
+	}
+	if err := checkShadowsocks2022Time(binary.BigEndian.Uint64(fixed[1:9])); err != nil {
+		return err
+	}
+	if string(fixed[9:9+len(c.requestSalt)]) != string(c.requestSalt) {
+		return fmt.Errorf("shadowsocks 2022 response to another request")
+	}
+	if reader.pending, err = reader.open(int(binary.BigEndian.Uint16(fixed[9+len(c.requestSalt):]))); err != nil {
+		return err
+	}
+
+	c.reader = reader
+	return nil
+}
+
+// writeResponseHeader добавляет к dst соль и заголовок ответа с первым
+// блоком данных payload
+func (c *shadowsocks2022Conn) writeResponseHeader(dst, payload []byte) ([]byte, error) {
+	salt := make([]byte, len(c.psk))
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := shadowsocks2022SessionAEAD(c.psk, salt)
+	if err != nil {
+		return nil, err
+	}
+	c.writer = newAEADWriter(c.Conn, aead, shadowsocks2022MaxPayloadSize)
+
+	fixed := []byte{shadowsocks2022TypeResponse}
+	fixed = binary.BigEndian.AppendUint64(fixed, uint64(time.Now().Unix()))
+	fixed = append(fixed, c.requestSalt...)
+	fixed = binary.BigEndian.AppendUint16(fixed, uint16(len(payload)))
+
+	dst = append(dst, salt...)
+	dst = c.writer.seal(dst, fixed)
+	return c.writer.seal(dst, payload), nil
+}
+
+func (c *shadowsocks2022Conn) Read(p []byte) (int, error) {
+	if c.reader == nil {
+		if err := c.readResponseHeader(); err != nil {
+			return 0, err
+		}
+	}
+	return c.reader.Read(p)
+}
+
+func (c *shadowsocks2022Conn) Write(p []byte) (int, error) {
+	if c.writer != nil {
+		return c.writer.Write(p)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Первая запись сервера начинается с заголовка ответа
+	first := min(len(p), shadowsocks2022MaxPayloadSize)
+	response, err := c.writeResponseHeader(nil, p[:first])
+	if err != nil {
+		return 0, err
+	}
+	response = c.writer.appendChunks(response, p[first:])
+	if _, err := c.Conn.Write(response); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,354 @@
+package bypass
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"lukechampine.com/blake3"
+)
+
+// bufferConn соединение в памяти: читает из in, пишет в out
+type bufferConn struct {
+	net.Conn
+	in  bytes.Buffer
+	out bytes.Buffer
+}
+
+func (c *bufferConn) Read(b []byte) (int, error)  { return c.in.Read(b) }
+func (c *bufferConn) Write(b []byte) (int, error) { return c.out.Write(b) }
+
+// Эталонная реализация SIP022 по спецификации, независимая от адаптера:
+// только стандартная библиотека и BLAKE3
+
+// refSIP022AEAD шифр сессии: AES-GCM на blake3.derive_key(context, PSK || salt)
+func refSIP022AEAD(t *testing.T, psk, salt []byte) cipher.AEAD {
+	t.Helper()
+	subkey := make([]byte, len(psk))
+	blake3.DeriveKey(subkey, "shadowsocks 2022 session subkey", append(append([]byte{}, psk...), salt...))
+	block, err := aes.NewCipher(subkey)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+// refNonce nonce блока с номером counter: 12 байт little-endian
+func refNonce(counter uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.LittleEndian.PutUint64(nonce, counter)
+	return nonce
+}
+
+// refSIP022Stream эталонный поток блоков одного направления
+type refSIP022Stream struct {
+	t       *testing.T
+	aead    cipher.AEAD
+	counter uint64
+}
+
+func (s *refSIP022Stream) open(r io.Reader, size int) []byte {
+	s.t.Helper()
+	sealed := make([]byte, size+16)
+	_, err := io.ReadFull(r, sealed)
+	require.NoError(s.t, err)
+	plaintext, err := s.aead.Open(nil, refNonce(s.counter), sealed, nil)
+	require.NoError(s.t, err)
+	s.counter++
+	return plaintext
+}
+
+func (s *refSIP022Stream) seal(plaintext []byte) []byte {
+	sealed := s.aead.Seal(nil, refNonce(s.counter), plaintext, nil)
+	s.counter++
+	return sealed
+}
+
+// readChunk читает блок длины и блок данных
+func (s *refSIP022Stream) readChunk(r io.Reader) []byte {
+	return s.open(r, int(binary.BigEndian.Uint16(s.open(r, 2))))
+}
+
+// refSIP022Request эталонный запрос клиента с начальными данными без
+// padding; с identityPSK добавляется заголовок идентификации
+func refSIP022Request(t *testing.T, psk, identityPSK, salt []byte, timestamp time.Time, destination proxyDestination, payload []byte) []byte {
+	t.Helper()
+	request := append([]byte{}, salt...)
+	if identityPSK != nil {
+		subkey := make([]byte, len(identityPSK))
+		blake3.DeriveKey(subkey, "shadowsocks 2022 identity subkey", append(append([]byte{}, identityPSK...), salt...))
+		block, err := aes.NewCipher(subkey)
+		require.NoError(t, err)
+		hash := blake3.Sum512(psk)
+		header := make([]byte, 16)
+		block.Encrypt(header, hash[:16])
+		request = append(request, header...)
+	}
+
+	variable := appendSocksAddr(nil, destination)
+	variable = append(variable, 0, 0)
+	variable = append(variable, payload...)
+
+	fixed := []byte{0}
+	fixed = binary.BigEndian.AppendUint64(fixed, uint64(timestamp.Unix()))
+	fixed = binary.BigEndian.AppendUint16(fixed, uint16(len(variable)))
+
+	stream := &refSIP022Stream{t: t, aead: refSIP022AEAD(t, psk, salt)}
+	request = append(request, stream.seal(fixed)...)
+	return append(request, stream.seal(variable)...)
+}
+
+func TestShadowsocks2022_ClientWire(t *testing.T) {
+	identityPSK := bytes.Repeat([]byte{1}, 32)
+	userPSK := bytes.Repeat([]byte{2}, 32)
+	destination := proxyDestination{Host: "example.com", Port: 443}
+
+	conn := &bufferConn{}
+	session, err := dialShadowsocks2022(conn, userPSK, identityPSK, destination)
+	require.NoError(t, err)
+	_, err = session.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	wire := &conn.out
+	salt := wire.Next(32)
+
+	// Заголовок идентификации: хеш PSK пользователя под ключом PSK сервера
+	subkey := make([]byte, 32)
+	blake3.DeriveKey(subkey, "shadowsocks 2022 identity subkey", append(append([]byte{}, identityPSK...), salt...))
+	block, err := aes.NewCipher(subkey)
+	require.NoError(t, err)
+	userHash := make([]byte, 16)
+	block.Decrypt(userHash, wire.Next(16))
+	expectedHash := blake3.Sum512(userPSK)
+	assert.Equal(t, expectedHash[:16], userHash)
+
+	stream := &refSIP022Stream{t: t, aead: refSIP022AEAD(t, userPSK, salt)}
+	fixed := stream.open(wire, 11)
+	assert.Equal(t, byte(0), fixed[0])
+	assert.InDelta(t, time.Now().Unix(), int64(binary.BigEndian.Uint64(fixed[1:9])), 2)
+
+	variable := stream.open(wire, int(binary.BigEndian.Uint16(fixed[9:])))
+	parsed, n, err := parseSocksAddr(variable)
+	require.NoError(t, err)
+	assert.Equal(t, destination, parsed)
+	padding := int(binary.BigEndian.Uint16(variable[n:]))
+	assert.True(t, padding >= 1 && padding <= 900, padding)
+	assert.Len(t, variable, n+2+padding)
+
+	assert.Equal(t, "hello", string(stream.readChunk(wire)))
+	assert.Zero(t, wire.Len())
+
+	// Ответ сервера: заголовок с солью запроса и первый блок, затем блоки
+	responseSalt := bytes.Repeat([]byte{7}, 32)
+	response := &refSIP022Stream{t: t, aead: refSIP022AEAD(t, userPSK, responseSalt)}
+	header := []byte{1}
+	header = binary.BigEndian.AppendUint64(header, uint64(time.Now().Unix()))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint16(header, 5)
+	conn.in.Write(responseSalt)
+	conn.in.Write(response.seal(header))
+	conn.in.Write(response.seal([]byte("world")))
+	conn.in.Write(response.seal([]byte{0, 1}))
+	conn.in.Write(response.seal([]byte("!")))
+
+	received, err := io.ReadAll(session)
+	require.NoError(t, err)
+	assert.Equal(t, "world!", string(received))
+}
+
+func TestShadowsocks2022_ClientRejectsForeignResponse(t *testing.T) {
+	psk := bytes.Repeat([]byte{1}, 16)
+	conn := &bufferConn{}
+	session, err := dialShadowsocks2022(conn, psk, nil, proxyDestination{Host: "127.0.0.1", Port: 80})
+	require.NoError(t, err)
+
+	// Ответ на другой запрос
+	responseSalt := bytes.Repeat([]byte{7}, 16)
+	response := &refSIP022Stream{t: t, aead: refSIP022AEAD(t, psk, responseSalt)}
+	header := []byte{1}
+	header = binary.BigEndian.AppendUint64(header, uint64(time.Now().Unix()))
+	header = append(header, bytes.Repeat([]byte{9}, 16)...)
+	header = binary.BigEndian.AppendUint16(header, 0)
+	conn.in.Write(responseSalt)
+	conn.in.Write(response.seal(header))
+
+	_, err = session.Read(make([]byte, 16))
+	assert.ErrorContains(t, err, "another request")
+}
+
+func TestShadowsocks2022_ServerWire(t *testing.T) {
+	identityPSK := bytes.Repeat([]byte{1}, 16)
+	userPSK := bytes.Repeat([]byte{2}, 16)
+	salt := bytes.Repeat([]byte{3}, 16)
+	destination := proxyDestination{Host: "10.0.0.1", Port: 8080}
+	lookup := func(userHash []byte) ([]byte, error) {
+		if !bytes.Equal(userHash, shadowsocks2022UserHash(userPSK)) {
+			return nil, ErrUnknownUser
+		}
+		return userPSK, nil
+	}
+
+	conn := &bufferConn{}
+	conn.in.Write(refSIP022Request(t, userPSK, identityPSK, salt, time.Now(), destination, []byte("GET /")))
+	request := &refSIP022Stream{t: t, aead: refSIP022AEAD(t, userPSK, salt)}
+	request.counter = 2
+	conn.in.Write(request.seal([]byte{0, 4}))
+	conn.in.Write(request.seal([]byte(" res")))
+
+	session, parsed, err := acceptShadowsocks2022(conn, identityPSK, newReplayCache(time.Minute), lookup)
+	require.NoError(t, err)
+	assert.Equal(t, destination, parsed)
+	received, err := io.ReadAll(session)
+	require.NoError(t, err)
+	assert.Equal(t, "GET / res", string(received))
+
+	_, err = session.Write([]byte("200 OK"))
+	require.NoError(t, err)
+	_, err = session.Write([]byte("body"))
+	require.NoError(t, err)
+
+	wire := &conn.out
+	responseSalt := wire.Next(16)
+	response := &refSIP022Stream{t: t, aead: refSIP022AEAD(t, userPSK, responseSalt)}
+	header := response.open(wire, 1+8+16+2)
+	assert.Equal(t, byte(1), header[0])
+	assert.InDelta(t, time.Now().Unix(), int64(binary.BigEndian.Uint64(header[1:9])), 2)
+	assert.Equal(t, salt, header[9:25])
+	assert.Equal(t, "200 OK", string(response.open(wire, int(binary.BigEndian.Uint16(header[25:])))))
+	assert.Equal(t, "body", string(response.readChunk(wire)))
+	assert.Zero(t, wire.Len())
+}
+
+func TestShadowsocks2022_ServerRejects(t *testing.T) {
+	psk := bytes.Repeat([]byte{1}, 32)
+	destination := proxyDestination{Host: "10.0.0.1", Port: 8080}
+	accept := func(request []byte, salts *replayCache) error {
+		conn := &bufferConn{}
+		conn.in.Write(request)
+		_, _, err := acceptShadowsocks2022(conn, psk, salts, nil)
+		return err
+	}
+
+	t.Run("повтор соли", func(t *testing.T) {
+		salts := newReplayCache(time.Minute)
+		request := refSIP022Request(t, psk, nil, bytes.Repeat([]byte{2}, 32), time.Now(), destination, []byte("x"))
+		require.NoError(t, accept(request, salts))
+		assert.ErrorIs(t, accept(request, salts), ErrShadowsocksReplay)
+	})
+
+	t.Run("устаревшее время", func(t *testing.T) {
+		request := refSIP022Request(t, psk, nil, bytes.Repeat([]byte{3}, 32), time.Now().Add(-time.Minute), destination, []byte("x"))
+		assert.ErrorIs(t, accept(request, newReplayCache(time.Minute)), ErrShadowsocksTimestamp)
+	})
+
+	t.Run("измененный заголовок", func(t *testing.T) {
+		request := refSIP022Request(t, psk, nil, bytes.Repeat([]byte{4}, 32), time.Now(), destination, []byte("x"))
+		request[40] ^= 1
+		assert.ErrorContains(t, accept(request, newReplayCache(time.Minute)), "failed to read request header")
+	})
+
+	t.Run("чужой PSK", func(t *testing.T) {
+		request := refSIP022Request(t, bytes.Repeat([]byte{5}, 32), nil, bytes.Repeat([]byte{5}, 32), time.Now(), destination, []byte("x"))
+		assert.Error(t, accept(request, newReplayCache(time.Minute)))
+	})
+
+	t.Run("без padding и данных", func(t *testing.T) {
+		request := refSIP022Request(t, psk, nil, bytes.Repeat([]byte{6}, 32), time.Now(), destination, nil)
+		assert.ErrorContains(t, accept(request, newReplayCache(time.Minute)), "without padding")
+	})
+}
+
+// refObfs4AEAD эталонный шифр направления obfs4: AES-256-GCM на
+// HMAC-SHA256(secret, label || nonce)
+func refObfs4AEAD(t *testing.T, secret, nonce []byte, label string) cipher.AEAD {
+	t.Helper()
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(label))
+	mac.Write(nonce)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+func TestObfs4User_Wire(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 32)
+	users := newUserRegistry(parseObfs4Secret)
+	require.NoError(t, users.Replace([]*domain.BypassUser{{ID: "alice", Credential: hex.EncodeToString(secret)}}))
+
+	client := &bufferConn{}
+	session, err := dialObfs4User(client, secret)
+	require.NoError(t, err)
+	_, err = session.Write([]byte("hello"))
+	require.NoError(t, err)
+	handshake := append([]byte{}, client.out.Bytes()...)
+
+	// Рукопожатие: nonce, время и тег HMAC от них, затем блоки AES-GCM
+	wire := bytes.NewReader(handshake)
+	nonce := make([]byte, 24)
+	_, err = io.ReadFull(wire, nonce)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Now().Unix(), int64(binary.BigEndian.Uint64(nonce[16:])), 2)
+	tag := make([]byte, 16)
+	_, err = io.ReadFull(wire, tag)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("obfs4 user auth"))
+	mac.Write(nonce)
+	assert.Equal(t, mac.Sum(nil)[:16], tag)
+
+	upstream := &refSIP022Stream{t: t, aead: refObfs4AEAD(t, secret, nonce, "obfs4 upstream")}
+	assert.Equal(t, "hello", string(upstream.readChunk(wire)))
+
+	// Сервер принимает рукопожатие один раз и отвечает nonce и блоками
+	nonces := newReplayCache(time.Minute)
+	server := &bufferConn{}
+	server.in.Write(handshake)
+	accepted, account, _, err := acceptObfs4User(server, users, nonces)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", account.id)
+	received := make([]byte, 5)
+	_, err = io.ReadFull(accepted, received)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(received))
+	_, err = accepted.Write([]byte("world"))
+	require.NoError(t, err)
+
+	serverNonce := server.out.Next(16)
+	downstream := &refSIP022Stream{t: t, aead: refObfs4AEAD(t, secret, append(append([]byte{}, nonce...), serverNonce...), "obfs4 downstream")}
+	response := append([]byte{}, server.out.Bytes()...)
+	assert.Equal(t, "world", string(downstream.readChunk(&server.out)))
+
+	client.in.Write(serverNonce)
+	client.in.Write(response)
+	_, err = io.ReadFull(session, received)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(received))
+
+	replayed := &bufferConn{}
+	replayed.in.Write(handshake)
+	_, _, _, err = acceptObfs4User(replayed, users, nonces)
+	assert.ErrorIs(t, err, ErrObfs4Replay)
+
+	// Измененный блок не расшифровывается
+	tampered := append([]byte{}, handshake...)
+	tampered[len(tampered)-1] ^= 1
+	server = &bufferConn{}
+	server.in.Write(tampered)
+	accepted, _, _, err = acceptObfs4User(server, users, newReplayCache(time.Minute))
+	require.NoError(t, err)
+	_, err = accepted.Read(received)
+	assert.ErrorContains(t, err, "failed to decrypt chunk")
+}
@@ -0,0 +1,84 @@
+package bypass
+
+import (
+	"crypto/aes"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"lukechampine.com/blake3"
+)
+
+// Контекст BLAKE3 для подключа заголовка идентификации (SIP022)
+const shadowsocks2022IdentityContext = "shadowsocks 2022 identity subkey"
+
+// Размер хеша пользователя в заголовке идентификации: один блок AES
+const shadowsocks2022IdentitySize = aes.BlockSize
+
+// shadowsocks2022KeySize размер PSK метода шифрования 2022
+func shadowsocks2022KeySize(encryption string) int {
+	if encryption == "2022-blake3-aes-128-gcm" {
+		return 16
+	}
+	return 32
+}
+
+// parseShadowsocks2022Key разбирает PSK в base64 и проверяет его размер
+func parseShadowsocks2022Key(value string, size int) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shadowsocks 2022 key: %w", err)
+	}
+	if len(key) != size {
+		return nil, fmt.Errorf("invalid shadowsocks 2022 key: %d bytes, want %d", len(key), size)
+	}
+	return key, nil
+}
+
+// shadowsocks2022UserHash хеш PSK пользователя, по которому сервер находит
+// пользователя в заголовке идентификации
+func shadowsocks2022UserHash(userPSK []byte) []byte {
+	hash := blake3.Sum256(userPSK)
+	return hash[:shadowsocks2022IdentitySize]
+}
+
+// shadowsocks2022IdentitySubkey ключ AES заголовка идентификации:
+// BLAKE3 derive_key от PSK сервера и соли
+func shadowsocks2022IdentitySubkey(identityPSK, salt []byte) []byte {
+	material := make([]byte, 0, len(identityPSK)+len(salt))
+	material = append(append(material, identityPSK...), salt...)
+	subkey := make([]byte, len(identityPSK))
+	blake3.DeriveKey(subkey, shadowsocks2022IdentityContext, material)
+	return subkey
+}
+
+// appendShadowsocks2022Identity добавляет заголовок идентификации
+// пользователя userPSK для сервера с PSK identityPSK и солью сессии
+func appendShadowsocks2022Identity(dst, identityPSK, userPSK, salt []byte) ([]byte, error) {
+	block, err := aes.NewCipher(shadowsocks2022IdentitySubkey(identityPSK, salt))
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, shadowsocks2022IdentitySize)
+	block.Encrypt(header, shadowsocks2022UserHash(userPSK))
+	return append(dst, header...), nil
+}
+
+// readShadowsocks2022Identity читает заголовок идентификации после соли
+// сессии и возвращает хеш PSK пользователя
+func readShadowsocks2022Identity(r io.Reader, identityPSK, salt []byte) ([]byte, error) {
+	header := make([]byte, shadowsocks2022IdentitySize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read identity header: %w", err)
+	}
+
+	block, err := aes.NewCipher(shadowsocks2022IdentitySubkey(identityPSK, salt))
+	if err != nil {
+		return nil, err
+	}
+
+	userHash := make([]byte, shadowsocks2022IdentitySize)
+	block.Decrypt(userHash, header)
+	return userHash, nil
+}
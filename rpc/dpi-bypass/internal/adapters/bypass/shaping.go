@@ -0,0 +1,350 @@
+package bypass
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// shapedRecordHeaderSize заголовок записи shapedConn: длина данных и
+	// длина дополнения, по 2 байта big-endian, замаскированные ключом
+	// соединения
+	shapedRecordHeaderSize = 4
+	// shapedSaltSize случайная соль, с которой начинается поток в каждую
+	// сторону
+	shapedSaltSize = 16
+)
+
+// shapingProfile описывает форму трафика: распределения размеров записей
+// в байтах и интервалов между ними в наносекундах
+type shapingProfile struct {
+	Name      string
+	Sizes     []shapingBin
+	Intervals []shapingBin
+}
+
+// shapingBin корзина гистограммы: значение равномерно в [Min, Max] с весом Weight
+type shapingBin struct {
+	Min    int64
+	Max    int64
+	Weight float64
+}
+
+// Профили формы трафика
+const (
+	shapingProfileWebBrowsing    = "web_browsing"
+	shapingProfileVideoStreaming = "video_streaming"
+	shapingProfileConstantRate   = "constant_rate"
+)
+
+// shapingProfiles встроенные профили. Интервал задает минимальную паузу между
+// началами записей: если приложение молчит дольше, запись уходит без задержки.
+var shapingProfiles = map[string]*shapingProfile{
+	// Короткие запросы и полные сегменты ответов, пачки с паузами на чтение страницы
+	shapingProfileWebBrowsing: {
+		Name: shapingProfileWebBrowsing,
+		Sizes: []shapingBin{
+			{Min: 40, Max: 119, Weight: 0.35},
+			{Min: 120, Max: 599, Weight: 0.15},
+			{Min: 600, Max: 1199, Weight: 0.10},
+			{Min: 1200, Max: 1460, Weight: 0.40},
+		},
+		Intervals: []shapingBin{
+			{Min: 0, Max: int64(time.Millisecond) - 1, Weight: 0.55},
+			{Min: int64(time.Millisecond), Max: int64(10*time.Millisecond) - 1, Weight: 0.25},
+			{Min: int64(10 * time.Millisecond), Max: int64(100*time.Millisecond) - 1, Weight: 0.15},
+			{Min: int64(100 * time.Millisecond), Max: int64(500 * time.Millisecond), Weight: 0.05},
+		},
+	},
+	// Сегменты видео полными пакетами плотными пачками, редкие подтверждения
+	shapingProfileVideoStreaming: {
+		Name: shapingProfileVideoStreaming,
+		Sizes: []shapingBin{
+			{Min: 60, Max: 199, Weight: 0.10},
+			{Min: 200, Max: 1299, Weight: 0.05},
+			{Min: 1300, Max: 1460, Weight: 0.85},
+		},
+		Intervals: []shapingBin{
+			{Min: 0, Max: int64(500*time.Microsecond) - 1, Weight: 0.80},
+			{Min: int64(500 * time.Microsecond), Max: int64(5*time.Millisecond) - 1, Weight: 0.15},
+			{Min: int64(5 * time.Millisecond), Max: int64(50 * time.Millisecond), Weight: 0.05},
+		},
+	},
+	// Записи одного размера через равные интервалы, до 240 КБ/с
+	shapingProfileConstantRate: {
+		Name:      shapingProfileConstantRate,
+		Sizes:     []shapingBin{{Min: 1200, Max: 1200, Weight: 1}},
+		Intervals: []shapingBin{{Min: int64(5 * time.Millisecond), Max: int64(5 * time.Millisecond), Weight: 1}},
+	},
+}
+
+// shapingConfig профиль формы трафика и секрет маскирования заголовков записей
+type shapingConfig struct {
+	Profile *shapingProfile
+	secret  []byte
+}
+
+// parseShaping читает параметры shaping_profile и shaping_key; nil без
+// профиля. Без shaping_key заголовки маскируются секретом транспорта secret
+func parseShaping(parameters map[string]string, secret string) (*shapingConfig, error) {
+	profile, err := parseShapingProfile(parameters)
+	if err != nil || profile == nil {
+		return nil, err
+	}
+	if key := parameters["shaping_key"]; key != "" {
+		secret = key
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("shaping_profile requires shaping_key")
+	}
+	return &shapingConfig{Profile: profile, secret: []byte(secret)}, nil
+}
+
+// headerMask возвращает поток маски заголовков одного направления: ключ
+// AES-256-CTR - HMAC-SHA256 секрета от соли направления
+func (c *shapingConfig) headerMask(salt []byte) cipher.Stream {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte("silence shaping header"))
+	mac.Write(salt)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		panic("failed to create shaping header mask: " + err.Error())
+	}
+	return cipher.NewCTR(block, make([]byte, aes.BlockSize))
+}
+
+// parseShapingProfile читает параметр shaping_profile; nil без профиля
+func parseShapingProfile(parameters map[string]string) (*shapingProfile, error) {
+	name := parameters["shaping_profile"]
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := shapingProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported shaping_profile: %s", name)
+	}
+	return profile, nil
+}
+
+// trafficShaper выбирает размеры и интервалы записей по профилю
+type trafficShaper struct {
+	profile *shapingProfile
+	rng     *rand.Rand
+	mutex   sync.Mutex
+}
+
+// newTrafficShaper создает генератор с независимым случайным зерном
+func newTrafficShaper(profile *shapingProfile) *trafficShaper {
+	var seed [32]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		panic("failed to seed traffic shaper: " + err.Error())
+	}
+	return &trafficShaper{
+		profile: profile,
+		rng:     rand.New(rand.NewChaCha8(seed)),
+	}
+}
+
+// NextSize возвращает размер следующей записи
+func (s *trafficShaper) NextSize() int {
+	size := int(s.sample(s.profile.Sizes))
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
+
+// NextDelay возвращает интервал до следующей записи
+func (s *trafficShaper) NextDelay() time.Duration {
+	return time.Duration(s.sample(s.profile.Intervals))
+}
+
+// padSize возвращает длину дополнения кадра из frameSize байт до размера профиля
+func (s *trafficShaper) padSize(frameSize int) int {
+	if padding := s.NextSize() - frameSize; padding > 0 {
+		return padding
+	}
+	return 0
+}
+
+// fill заполняет дополнение случайными байтами
+func (s *trafficShaper) fill(b []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range b {
+		b[i] = byte(s.rng.Uint32())
+	}
+}
+
+// sample выбирает корзину по весу и значение внутри нее
+func (s *trafficShaper) sample(bins []shapingBin) int64 {
+	if len(bins) == 0 {
+		return 0
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var total float64
+	for _, bin := range bins {
+		total += bin.Weight
+	}
+	point := s.rng.Float64() * total
+	bin := bins[len(bins)-1]
+	for _, candidate := range bins {
+		if point < candidate.Weight {
+			bin = candidate
+			break
+		}
+		point -= candidate.Weight
+	}
+	if bin.Max <= bin.Min {
+		return bin.Min
+	}
+	return bin.Min + s.rng.Int64N(bin.Max-bin.Min+1)
+}
+
+// shapedConn отправляет данные записями размера профиля и выдерживает
+// интервалы между ними. Запись - заголовок, данные и случайное дополнение
+// до размера профиля, поэтому обе стороны соединения должны использовать
+// профиль; чтение отбрасывает дополнение. Первая запись начинается с соли
+// направления, заголовки маскируются ключом от соли и общего секрета, так
+// что без секрета поток остается неотличим от случайных байт.
+type shapedConn struct {
+	net.Conn
+	shaping   *shapingConfig
+	shaper    *trafficShaper
+	mutex     sync.Mutex
+	lastWrite time.Time
+	writeMask cipher.Stream
+	// writeSalt соль, еще не отправленная с первой записью
+	writeSalt []byte
+	// Остаток данных и дополнения текущей читаемой записи
+	readMutex   sync.Mutex
+	readMask    cipher.Stream
+	readData    int
+	readPadding int64
+	// now и sleep подменяются в тестах
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// shapeConn оборачивает соединение профилем; без профиля возвращает его как есть
+func shapeConn(conn net.Conn, shaping *shapingConfig) net.Conn {
+	if shaping == nil {
+		return conn
+	}
+	return &shapedConn{
+		Conn:    conn,
+		shaping: shaping,
+		shaper:  newTrafficShaper(shaping.Profile),
+		now:     time.Now,
+		sleep:   time.Sleep,
+	}
+}
+
+func (c *shapedConn) Write(b []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.writeMask == nil && len(b) > 0 {
+		c.writeSalt = make([]byte, shapedSaltSize)
+		if _, err := crand.Read(c.writeSalt); err != nil {
+			return 0, fmt.Errorf("failed to generate shaping salt: %w", err)
+		}
+		c.writeMask = c.shaping.headerMask(c.writeSalt)
+	}
+
+	written := 0
+	for written < len(b) {
+		// Запись не короче соли, заголовка и одного байта данных
+		prefix := len(c.writeSalt) + shapedRecordHeaderSize
+		size := max(c.shaper.NextSize(), prefix+1)
+		data := min(len(b)-written, size-prefix)
+		padding := size - prefix - data
+
+		record := make([]byte, size)
+		header := record[len(c.writeSalt):prefix]
+		copy(record, c.writeSalt)
+		binary.BigEndian.PutUint16(header[0:2], uint16(data))
+		binary.BigEndian.PutUint16(header[2:4], uint16(padding))
+		c.writeMask.XORKeyStream(header, header)
+		copy(record[prefix:], b[written:written+data])
+		c.shaper.fill(record[prefix+data:])
+
+		if !c.lastWrite.IsZero() {
+			if wait := c.shaper.NextDelay() - c.now().Sub(c.lastWrite); wait > 0 {
+				c.sleep(wait)
+			}
+		}
+
+		n, err := c.Conn.Write(record)
+		written += min(max(n-prefix, 0), data)
+		c.lastWrite = c.now()
+		c.writeSalt = nil
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (c *shapedConn) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	c.readMutex.Lock()
+	defer c.readMutex.Unlock()
+
+	if c.readMask == nil {
+		salt := make([]byte, shapedSaltSize)
+		if _, err := io.ReadFull(c.Conn, salt); err != nil {
+			return 0, err
+		}
+		c.readMask = c.shaping.headerMask(salt)
+	}
+
+	for c.readData == 0 {
+		if c.readPadding > 0 {
+			if _, err := io.CopyN(io.Discard, c.Conn, c.readPadding); err != nil {
+				return 0, unexpectedEOF(err)
+			}
+			c.readPadding = 0
+		}
+
+		var header [shapedRecordHeaderSize]byte
+		if _, err := io.ReadFull(c.Conn, header[:]); err != nil {
+			return 0, err
+		}
+		c.readMask.XORKeyStream(header[:], header[:])
+		c.readData = int(binary.BigEndian.Uint16(header[0:2]))
+		c.readPadding = int64(binary.BigEndian.Uint16(header[2:4]))
+	}
+
+	n, err := c.Conn.Read(b[:min(len(b), c.readData)])
+	c.readData -= n
+	if err != nil && c.readData > 0 {
+		err = unexpectedEOF(err)
+	}
+	return n, err
+}
+
+// SetNoDelay пробрасывает настройку Nagle, чтобы части не склеивались ядром
+func (c *shapedConn) SetNoDelay(noDelay bool) error {
+	if conn, ok := c.Conn.(interface{ SetNoDelay(bool) error }); ok {
+		return conn.SetNoDelay(noDelay)
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package bypass
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingConn запоминает размеры записей и время каждой по часам теста
+type recordingConn struct {
+	net.Conn
+	clock  *time.Time
+	sizes  []int64
+	stamps []time.Time
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	c.sizes = append(c.sizes, int64(len(b)))
+	c.stamps = append(c.stamps, *c.clock)
+	return len(b), nil
+}
+
+// readerConn соединение, читающее из Reader
+type readerConn struct {
+	net.Conn
+	io.Reader
+}
+
+func (c *readerConn) Read(b []byte) (int, error) {
+	return c.Reader.Read(b)
+}
+
+// testShaping профиль с секретом маскирования заголовков
+func testShaping(name string) *shapingConfig {
+	return &shapingConfig{Profile: shapingProfiles[name], secret: []byte("shaping-secret")}
+}
+
+// recordShaping пропускает поток через профиль записями по chunk байт,
+// пока не наберется samples записей, и возвращает размеры записей и
+// интервалы между ними
+func recordShaping(t *testing.T, profile *shapingProfile, samples, chunk int) ([]int64, []int64) {
+	t.Helper()
+
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	recorder := &recordingConn{clock: &clock}
+	conn := shapeConn(recorder, &shapingConfig{Profile: profile, secret: []byte("shaping-secret")}).(*shapedConn)
+	conn.now = func() time.Time { return clock }
+	conn.sleep = func(d time.Duration) { clock = clock.Add(d) }
+
+	for len(recorder.sizes) < samples {
+		n, err := conn.Write(make([]byte, chunk))
+		require.NoError(t, err)
+		require.Equal(t, chunk, n)
+	}
+
+	intervals := make([]int64, 0, len(recorder.stamps)-1)
+	for i := 1; i < len(recorder.stamps); i++ {
+		intervals = append(intervals, int64(recorder.stamps[i].Sub(recorder.stamps[i-1])))
+	}
+	return recorder.sizes, intervals
+}
+
+// assertHistogram проверяет, что каждое значение попадает в корзину профиля,
+// а доли корзин совпадают с весами с точностью tolerance
+func assertHistogram(t *testing.T, bins []shapingBin, values []int64, tolerance float64) {
+	t.Helper()
+
+	var total float64
+	for _, bin := range bins {
+		total += bin.Weight
+	}
+	counts := make([]int, len(bins))
+	for _, value := range values {
+		found := false
+		for i, bin := range bins {
+			if value >= bin.Min && value <= bin.Max {
+				counts[i]++
+				found = true
+				break
+			}
+		}
+		require.True(t, found, "value %d is outside of profile bins", value)
+	}
+	for i, bin := range bins {
+		share := float64(counts[i]) / float64(len(values))
+		assert.InDelta(t, bin.Weight/total, share, tolerance,
+			"bin [%d, %d]: expected share %.3f, got %.3f", bin.Min, bin.Max, bin.Weight/total, share)
+	}
+}
+
+func TestShapedConn_MatchesProfiles(t *testing.T) {
+	// Большие записи режутся на части, маленькие дополняются
+	for writes, chunk := range map[string]int{"large writes": 1 << 20, "small writes": 16} {
+		for name, profile := range shapingProfiles {
+			t.Run(writes+"/"+name, func(t *testing.T) {
+				sizes, intervals := recordShaping(t, profile, 20000, chunk)
+
+				assertHistogram(t, profile.Sizes, sizes, 0.02)
+				assertHistogram(t, profile.Intervals, intervals, 0.02)
+			})
+		}
+	}
+}
+
+func TestShapedConn_RoundTrip(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	writer := shapeConn(client, testShaping(shapingProfileWebBrowsing))
+	reader := shapeConn(server, testShaping(shapingProfileVideoStreaming))
+
+	// Записи от одного байта до нескольких записей профиля
+	var writes [][]byte
+	var expected []byte
+	for i, size := range []int{1, 39, 100, 1456, 1457, 5000} {
+		write := bytes.Repeat([]byte{byte('a' + i)}, size)
+		writes = append(writes, write)
+		expected = append(expected, write...)
+	}
+	go func() {
+		defer writer.Close()
+		for _, write := range writes {
+			if _, err := writer.Write(write); err != nil {
+				return
+			}
+		}
+	}()
+
+	received, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, expected, received)
+}
+
+// shapedStream возвращает байты, которые shapedConn отправляет для writes
+func shapedStream(t *testing.T, shaping *shapingConfig, writes ...[]byte) []byte {
+	t.Helper()
+
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var stream bytes.Buffer
+	conn := shapeConn(&writerConn{Writer: &stream}, shaping).(*shapedConn)
+	conn.now = func() time.Time { return clock }
+	conn.sleep = func(d time.Duration) { clock = clock.Add(d) }
+	for _, write := range writes {
+		_, err := conn.Write(write)
+		require.NoError(t, err)
+	}
+	return stream.Bytes()
+}
+
+// writerConn соединение, пишущее в Writer
+type writerConn struct {
+	net.Conn
+	io.Writer
+}
+
+func (c *writerConn) Write(b []byte) (int, error) {
+	return c.Writer.Write(b)
+}
+
+func TestShapedConn_TruncatedRecord(t *testing.T) {
+	shaping := testShaping(shapingProfileConstantRate)
+	stream := shapedStream(t, shaping, []byte("ping"))
+	conn := shapeConn(&readerConn{Reader: bytes.NewReader(stream[:shapedSaltSize+shapedRecordHeaderSize+2])}, shaping)
+
+	data, err := io.ReadAll(conn)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, []byte("pi"), data)
+}
+
+func TestShapedConn_MasksRecordHeaders(t *testing.T) {
+	shaping := testShaping(shapingProfileConstantRate)
+	payload := bytes.Repeat([]byte{0x42}, 100)
+
+	// Открытые заголовки совпадали бы в каждом соединении: 100 байт данных
+	// и 1080 дополнения после соли, затем 100 и 1096
+	first := shapedStream(t, shaping, payload, payload)
+	second := shapedStream(t, shaping, payload, payload)
+	require.Len(t, first, 2400)
+	for _, stream := range [][]byte{first, second} {
+		assert.NotEqual(t, []byte{0, 100, 0x04, 0x38}, stream[shapedSaltSize:shapedSaltSize+shapedRecordHeaderSize])
+		assert.NotEqual(t, []byte{0, 100, 0x04, 0x48}, stream[1200:1200+shapedRecordHeaderSize])
+	}
+	assert.NotEqual(t, first[:1200+shapedRecordHeaderSize], second[:1200+shapedRecordHeaderSize])
+
+	// Получатель с тем же секретом восстанавливает данные
+	received, err := io.ReadAll(shapeConn(&readerConn{Reader: bytes.NewReader(first)}, shaping))
+	require.NoError(t, err)
+	assert.Equal(t, append(payload, payload...), received)
+
+	// С другим секретом заголовки не разбираются
+	other := &shapingConfig{Profile: shaping.Profile, secret: []byte("other-secret")}
+	received, _ = io.ReadAll(shapeConn(&readerConn{Reader: bytes.NewReader(first)}, other))
+	assert.NotEqual(t, append(payload, payload...), received)
+}
+
+func TestShapedConn_IdleTimeCountsTowardsInterval(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	recorder := &recordingConn{clock: &clock}
+	conn := shapeConn(recorder, testShaping(shapingProfileConstantRate)).(*shapedConn)
+	conn.now = func() time.Time { return clock }
+	var slept []time.Duration
+	conn.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+	}
+
+	_, err := conn.Write(make([]byte, 100))
+	require.NoError(t, err)
+	// Приложение молчало дольше интервала: пауза не нужна
+	clock = clock.Add(time.Second)
+	_, err = conn.Write(make([]byte, 100))
+	require.NoError(t, err)
+	// Прошло 2 мс из 5
+	clock = clock.Add(2 * time.Millisecond)
+	_, err = conn.Write(make([]byte, 100))
+	require.NoError(t, err)
+
+	// Короткие записи дополнены до размера профиля
+	assert.Equal(t, []int64{1200, 1200, 1200}, recorder.sizes)
+	assert.Equal(t, []time.Duration{3 * time.Millisecond}, slept)
+}
+
+func TestShapeConn_WithoutProfile(t *testing.T) {
+	conn := &recordingConn{}
+	assert.Same(t, conn, shapeConn(conn, nil))
+}
+
+func TestParseShapingProfile(t *testing.T) {
+	profile, err := parseShapingProfile(map[string]string{})
+	require.NoError(t, err)
+	assert.Nil(t, profile)
+
+	profile, err = parseShapingProfile(map[string]string{"shaping_profile": "video_streaming"})
+	require.NoError(t, err)
+	assert.Equal(t, shapingProfileVideoStreaming, profile.Name)
+
+	_, err = parseShapingProfile(map[string]string{"shaping_profile": "voip"})
+	assert.Error(t, err)
+}
+
+func TestParseShaping(t *testing.T) {
+	shaping, err := parseShaping(map[string]string{}, "")
+	require.NoError(t, err)
+	assert.Nil(t, shaping)
+
+	// Секрет транспорта, если shaping_key не задан
+	shaping, err = parseShaping(map[string]string{"shaping_profile": "web_browsing"}, "password")
+	require.NoError(t, err)
+	assert.Equal(t, shapingProfileWebBrowsing, shaping.Profile.Name)
+	assert.Equal(t, []byte("password"), shaping.secret)
+
+	shaping, err = parseShaping(map[string]string{"shaping_profile": "web_browsing", "shaping_key": "key"}, "password")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key"), shaping.secret)
+
+	_, err = parseShaping(map[string]string{"shaping_profile": "web_browsing"}, "")
+	assert.Error(t, err)
+}
+
+func TestGenerateChaffData_ShapingProfile(t *testing.T) {
+	c := &CustomAdapter{}
+	conn := &customConnection{
+		chaffRatio: defaultCustomChaffRatio,
+		shaper:     newTrafficShaper(shapingProfiles[shapingProfileConstantRate]),
+	}
+
+	// Кадр дополняется ровно до размера записи профиля
+	chaff := c.generateChaffData(100, conn)
+	assert.Len(t, chaff, 1200-customFrameHeaderSize-100)
+
+	// Кадр больше записи не дополняется
+	assert.Empty(t, c.generateChaffData(2000, conn))
+}
+
+func TestAdapters_RejectUnknownShapingProfile(t *testing.T) {
+	parameters := map[string]string{"local_port": "0", "shaping_profile": "voip"}
+	for name, adapter := range map[string]ports.BypassAdapter{
+		"custom":      NewCustomAdapter(zap.NewNop()),
+		"shadowsocks": NewShadowsocksAdapter(zap.NewNop()),
+		"v2ray":       NewV2RayAdapter(zap.NewNop()),
+		"obfs4":       NewObfs4Adapter(zap.NewNop()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := adapter.Start(&domain.BypassConfig{ID: "shaping-" + name, Parameters: parameters})
+			assert.Error(t, err)
+			assert.False(t, adapter.IsRunning("shaping-"+name))
+		})
+	}
+}
+
+func TestV2RayAdapter_ShapingRequiresKey(t *testing.T) {
+	// У VLESS нет общего секрета сторон для маскирования заголовков
+	adapter := NewV2RayAdapter(zap.NewNop())
+	err := adapter.Start(&domain.BypassConfig{
+		ID:         "shaping-v2ray",
+		Parameters: map[string]string{"local_port": "0", "shaping_profile": shapingProfileWebBrowsing},
+	})
+	assert.ErrorContains(t, err, "shaping_key")
+	assert.False(t, adapter.IsRunning("shaping-v2ray"))
+}
+
+func TestCustomAdapter_ShapedRoundTrip(t *testing.T) {
+	logger := zap.NewNop()
+	echoPort := startEchoServer(t)
+
+	server := NewCustomAdapter(logger)
+	require.NoError(t, server.Start(&domain.BypassConfig{
+		ID:     "custom-shaped-server",
+		Method: domain.BypassMethodCustom,
+		Parameters: map[string]string{
+			"local_port":      "0",
+			"role":            "server",
+			"remote_host":     "127.0.0.1",
+			"remote_port":     fmt.Sprintf("%d", echoPort),
+			"password":        "shared-secret",
+			"shaping_profile": shapingProfileConstantRate,
+		},
+	}))
+	defer server.Stop("custom-shaped-server")
+
+	client := NewCustomAdapter(logger)
+	require.NoError(t, client.Start(&domain.BypassConfig{
+		ID:     "custom-shaped-client",
+		Method: domain.BypassMethodCustom,
+		Parameters: map[string]string{
+			"local_port":      "0",
+			"role":            "client",
+			"remote_host":     "127.0.0.1",
+			"remote_port":     fmt.Sprintf("%d", customListenerPort(t, server, "custom-shaped-server")),
+			"password":        "shared-secret",
+			"shaping_profile": shapingProfileConstantRate,
+		},
+	}))
+	defer client.Stop("custom-shaped-client")
+
+	conn, err := net.DialTimeout("tcp",
+		fmt.Sprintf("127.0.0.1:%d", customListenerPort(t, client, "custom-shaped-client")), time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	payload := bytes.Repeat([]byte("ping "), 500)
+	_, err = conn.Write(payload)
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	received := make([]byte, len(payload))
+	_, err = io.ReadFull(conn, received)
+	require.NoError(t, err)
+	assert.Equal(t, payload, received)
+}
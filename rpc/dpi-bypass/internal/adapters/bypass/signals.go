@@ -0,0 +1,589 @@
+package bypass
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/dns"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// signalBucket шаг агрегации сигналов
+	signalBucket = time.Minute
+	// signalRetention время хранения агрегатов
+	signalRetention = 24 * time.Hour
+	// defaultSignalTimeout ожидание ответа после первой отправки, после
+	// которого соединение без ответа считается заблокированным
+	defaultSignalTimeout = 10 * time.Second
+	// defaultThrottleAfterBytes объем начала ответа, с которым сравнивается
+	// скорость остальной передачи
+	defaultThrottleAfterBytes = 16 * 1024
+	// throttleRatio падение скорости, считающееся замедлением
+	throttleRatio = 0.1
+	// signalIdleGap паузы между чтениями длиннее этой считаются простоем
+	signalIdleGap = time.Second
+	// dnsVerdictTTL время кеширования результата проверки DNS
+	dnsVerdictTTL = 5 * time.Minute
+	// dnsCheckTimeout ограничивает проверку DNS
+	dnsCheckTimeout = 5 * time.Second
+)
+
+// signalObserver адаптер, исходящие соединения которого классифицируются
+// монитором сигналов
+type signalObserver interface {
+	observeSignals(monitor *SignalMonitor)
+}
+
+// SignalMonitor классифицирует сбои исходящих соединений адаптеров
+// (сброс после ClientHello, таймаут после SNI, подмена DNS, замедление)
+// и агрегирует их по методу, цели и минуте
+type SignalMonitor struct {
+	buckets  map[signalKey]*domain.CensorshipSignalStats
+	dns      map[dnsKey]*dnsCheck
+	exported time.Time
+	mutex    sync.Mutex
+	logger   *zap.Logger
+	// resolver зашифрованный резолвер соединений с dns_resolver=encrypted
+	resolver *dns.Resolver
+
+	// Подменяются в тестах
+	now     func() time.Time
+	timeout time.Duration
+	lookup  func(ctx context.Context, reference, host string) ([]net.IP, error)
+}
+
+type signalKey struct {
+	start  time.Time
+	method domain.BypassMethod
+	target string
+}
+
+type dnsKey struct {
+	host      string
+	reference string
+}
+
+// dnsCheck результат проверки ответа DNS, poisoned доступен после закрытия done
+type dnsCheck struct {
+	done     chan struct{}
+	poisoned bool
+	expires  time.Time
+}
+
+// NewSignalMonitor создает монитор сигналов цензуры
+func NewSignalMonitor(logger *zap.Logger) *SignalMonitor {
+	return &SignalMonitor{
+		buckets: make(map[signalKey]*domain.CensorshipSignalStats),
+		dns:     make(map[dnsKey]*dnsCheck),
+		logger:  logger,
+		now:     time.Now,
+		timeout: defaultSignalTimeout,
+		lookup:  lookupHost,
+	}
+}
+
+// Dial подключается к address и наблюдает за соединением до закрытия.
+// С dns_resolver=encrypted хост разрешается зашифрованным резолвером.
+// Без монитора выполняется обычное подключение.
+func (m *SignalMonitor) Dial(ctx context.Context, config *domain.BypassConfig, address string, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	if m == nil {
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := m.resolver != nil && config.Parameters["dns_resolver"] == dnsResolverEncrypted
+	throttleAfter := int64(defaultThrottleAfterBytes)
+	if value := config.Parameters["throttle_after_bytes"]; value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil && parsed > 0 {
+			throttleAfter = parsed
+		}
+	}
+
+	conn := &signalConn{
+		monitor:       m,
+		method:        config.Method,
+		target:        host,
+		throttleAfter: throttleAfter,
+	}
+	// Ответ системного резолвера проверяется, только если соединение от него зависит
+	if net.ParseIP(host) == nil && !encrypted {
+		conn.dns = m.checkDNS(host, config.Parameters["dns_reference"])
+	}
+
+	var raw net.Conn
+	if encrypted {
+		raw, err = dialResolved(ctx, dialer, m.resolver, host, port)
+	} else {
+		raw, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		conn.record()
+		return nil, err
+	}
+	conn.Conn = raw
+	return conn, nil
+}
+
+// checkDNS возвращает проверку ответа DNS для хоста, запуская новую в фоне,
+// если кешированный результат устарел
+func (m *SignalMonitor) checkDNS(host, reference string) *dnsCheck {
+	key := dnsKey{host: host, reference: reference}
+	now := m.now()
+
+	m.mutex.Lock()
+	check, exists := m.dns[key]
+	if exists && (check.expires.IsZero() || now.Before(check.expires)) {
+		m.mutex.Unlock()
+		return check
+	}
+	check = &dnsCheck{done: make(chan struct{})}
+	m.dns[key] = check
+	m.mutex.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsCheckTimeout)
+		defer cancel()
+
+		poisoned, err := m.dnsPoisoned(ctx, host, reference)
+		if err != nil {
+			m.logger.Debug("dns check failed", zap.String("host", host), zap.Error(err))
+		}
+
+		m.mutex.Lock()
+		check.poisoned = poisoned
+		check.expires = m.now().Add(dnsVerdictTTL)
+		m.mutex.Unlock()
+		close(check.done)
+	}()
+	return check
+}
+
+// dnsPoisoned сравнивает ответ системного резолвера с ожидаемым: адреса
+// из служебных диапазонов или ответ, не пересекающийся с ответом
+// эталонного резолвера, считаются подменой
+func (m *SignalMonitor) dnsPoisoned(ctx context.Context, host, reference string) (bool, error) {
+	answer, err := m.lookup(ctx, "", host)
+	if err != nil {
+		return false, err
+	}
+	for _, ip := range answer {
+		if dns.IsBogon(ip) {
+			return true, nil
+		}
+	}
+	if reference == "" || len(answer) == 0 {
+		return false, nil
+	}
+
+	expected, err := m.lookup(ctx, reference, host)
+	if err != nil || len(expected) == 0 {
+		return false, err
+	}
+	for _, ip := range answer {
+		for _, candidate := range expected {
+			if ip.Equal(candidate) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// verdict возвращает результат проверки, если она завершена
+func (c *dnsCheck) verdict(m *SignalMonitor) bool {
+	if c == nil {
+		return false
+	}
+	select {
+	case <-c.done:
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		return c.poisoned
+	default:
+		return false
+	}
+}
+
+// lookupHost разрешает хост системным резолвером или, если задан
+// reference, напрямую через DNS сервер reference по TCP
+func lookupHost(ctx context.Context, reference, host string) ([]net.IP, error) {
+	resolver := net.DefaultResolver
+	if reference != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "tcp", reference)
+			},
+		}
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+// record учитывает соединение в агрегате текущей минуты
+func (m *SignalMonitor) record(method domain.BypassMethod, target string, signal domain.CensorshipSignal) {
+	now := m.now()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := signalKey{start: now.Truncate(signalBucket), method: method, target: target}
+	stats, exists := m.buckets[key]
+	if !exists {
+		m.prune(now)
+		stats = &domain.CensorshipSignalStats{
+			Method:      method,
+			Target:      target,
+			WindowStart: key.start,
+			WindowEnd:   key.start.Add(signalBucket),
+		}
+		m.buckets[key] = stats
+	}
+	stats.Connections++
+	addSignal(stats, signal, 1)
+
+	if signal != "" {
+		m.logger.Debug("censorship signal detected",
+			zap.String("method", string(method)),
+			zap.String("target", target),
+			zap.String("signal", string(signal)))
+	}
+}
+
+// prune удаляет агрегаты старше срока хранения и устаревшие проверки DNS
+func (m *SignalMonitor) prune(now time.Time) {
+	cutoff := now.Add(-signalRetention)
+	for key := range m.buckets {
+		if key.start.Before(cutoff) {
+			delete(m.buckets, key)
+		}
+	}
+	for key, check := range m.dns {
+		if !check.expires.IsZero() && now.After(check.expires) {
+			delete(m.dns, key)
+		}
+	}
+}
+
+// addSignal увеличивает счетчик сигнала
+func addSignal(stats *domain.CensorshipSignalStats, signal domain.CensorshipSignal, count int64) {
+	switch signal {
+	case domain.CensorshipSignalRSTAfterClientHello:
+		stats.RSTAfterClientHello += count
+	case domain.CensorshipSignalTimeoutAfterSNI:
+		stats.TimeoutAfterSNI += count
+	case domain.CensorshipSignalDNSPoisoning:
+		stats.DNSPoisoning += count
+	case domain.CensorshipSignalThrottling:
+		stats.Throttling += count
+	}
+}
+
+// Stats возвращает агрегаты, попавшие в фильтры, объединенные по окнам
+func (m *SignalMonitor) Stats(filters *domain.CensorshipSignalFilters) ([]*domain.CensorshipSignalStats, error) {
+	if filters == nil {
+		filters = &domain.CensorshipSignalFilters{}
+	}
+	if filters.Window < 0 || filters.Window%signalBucket != 0 {
+		return nil, fmt.Errorf("window must be a multiple of %s", signalBucket)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	merged := make(map[signalKey]*domain.CensorshipSignalStats)
+	for key, stats := range m.buckets {
+		if filters.Method != "" && key.method != filters.Method {
+			continue
+		}
+		if filters.Target != "" && key.target != filters.Target {
+			continue
+		}
+		if !filters.StartTime.IsZero() && !stats.WindowEnd.After(filters.StartTime) {
+			continue
+		}
+		if !filters.EndTime.IsZero() && !stats.WindowStart.Before(filters.EndTime) {
+			continue
+		}
+
+		window := key
+		if filters.Window > 0 {
+			window.start = key.start.Truncate(filters.Window)
+		} else {
+			window.start = time.Time{}
+		}
+		mergeStats(merged, window, stats, filters.Window)
+	}
+
+	return sortedStats(merged), nil
+}
+
+// Export возвращает завершенные до until минутные агрегаты, не
+// подтвержденные Acknowledge. Курсор не сдвигается: минуты, которые не
+// дошли до потребителя, попадут в следующую выгрузку. Рассчитан на одного
+// потребителя.
+func (m *SignalMonitor) Export(until time.Time) []*domain.CensorshipSignalStats {
+	until = until.Truncate(signalBucket)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	exported := make(map[signalKey]*domain.CensorshipSignalStats)
+	for key, stats := range m.buckets {
+		if key.start.Before(m.exported) || stats.WindowEnd.After(until) {
+			continue
+		}
+		mergeStats(exported, key, stats, signalBucket)
+	}
+
+	return sortedStats(exported)
+}
+
+// Acknowledge сдвигает курсор выгрузки на until: минуты, завершенные к
+// нему, больше не выдаются
+func (m *SignalMonitor) Acknowledge(until time.Time) {
+	until = until.Truncate(signalBucket)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if until.After(m.exported) {
+		m.exported = until
+	}
+}
+
+// mergeStats добавляет минутный агрегат к агрегату окна key
+func mergeStats(merged map[signalKey]*domain.CensorshipSignalStats, key signalKey, stats *domain.CensorshipSignalStats, window time.Duration) {
+	target, exists := merged[key]
+	if !exists {
+		target = &domain.CensorshipSignalStats{
+			Method:      stats.Method,
+			Target:      stats.Target,
+			WindowStart: stats.WindowStart,
+			WindowEnd:   stats.WindowEnd,
+		}
+		if window > 0 {
+			target.WindowStart = key.start
+			target.WindowEnd = key.start.Add(window)
+		}
+		merged[key] = target
+	}
+	if stats.WindowStart.Before(target.WindowStart) {
+		target.WindowStart = stats.WindowStart
+	}
+	if stats.WindowEnd.After(target.WindowEnd) {
+		target.WindowEnd = stats.WindowEnd
+	}
+
+	target.Connections += stats.Connections
+	target.RSTAfterClientHello += stats.RSTAfterClientHello
+	target.TimeoutAfterSNI += stats.TimeoutAfterSNI
+	target.DNSPoisoning += stats.DNSPoisoning
+	target.Throttling += stats.Throttling
+}
+
+// sortedStats упорядочивает агрегаты по окну, методу и цели
+func sortedStats(merged map[signalKey]*domain.CensorshipSignalStats) []*domain.CensorshipSignalStats {
+	result := make([]*domain.CensorshipSignalStats, 0, len(merged))
+	for _, stats := range merged {
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if !a.WindowStart.Equal(b.WindowStart) {
+			return a.WindowStart.Before(b.WindowStart)
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Target < b.Target
+	})
+	return result
+}
+
+// signalConn соединение, классифицируемое при закрытии
+type signalConn struct {
+	net.Conn
+	monitor       *SignalMonitor
+	method        domain.BypassMethod
+	target        string
+	throttleAfter int64
+	dns           *dnsCheck
+	once          sync.Once
+
+	// Состояние передачи, защищено mutex
+	mutex       sync.Mutex
+	firstFlight []byte
+	flightDone  bool
+	serverName  string
+	firstWrite  time.Time
+	err         error
+	rx          int64
+	lastRead    time.Time
+	headBytes   int64
+	headActive  time.Duration
+	tailBytes   int64
+	tailActive  time.Duration
+}
+
+// Write запоминает первую отправку клиента для определения SNI
+func (c *signalConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+
+	// Учитывается вся попытка отправки: сброс мог прервать запись
+	// фрагментированного ClientHello после первого сегмента
+	c.mutex.Lock()
+	if len(b) > 0 {
+		if c.firstWrite.IsZero() {
+			c.firstWrite = c.monitor.now()
+		}
+		c.observeFlight(b)
+	}
+	c.observeError(err)
+	c.mutex.Unlock()
+
+	return n, err
+}
+
+// observeFlight накапливает первую отправку, пока из нее не разобран
+// ClientHello или не стало ясно, что это не TLS
+func (c *signalConn) observeFlight(b []byte) {
+	if c.flightDone || c.rx > 0 {
+		c.flightDone = true
+		c.firstFlight = nil
+		return
+	}
+	c.firstFlight = append(c.firstFlight, b...)
+	_, hello, err := readClientHello(bytes.NewReader(c.firstFlight))
+	if hello != nil {
+		c.serverName = hello.serverName
+	}
+	if err == nil || (!errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF)) {
+		c.flightDone = true
+		c.firstFlight = nil
+	}
+}
+
+// Read учитывает скорость получения ответа
+func (c *signalConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+
+	c.mutex.Lock()
+	if n > 0 {
+		now := c.monitor.now()
+		gap := time.Duration(0)
+		if c.rx > 0 {
+			gap = now.Sub(c.lastRead)
+			if gap > signalIdleGap {
+				gap = signalIdleGap
+			}
+		}
+		if c.rx < c.throttleAfter {
+			c.headBytes += int64(n)
+			c.headActive += gap
+		} else {
+			c.tailBytes += int64(n)
+			c.tailActive += gap
+		}
+		c.rx += int64(n)
+		c.lastRead = now
+	}
+	c.observeError(err)
+	c.mutex.Unlock()
+
+	return n, err
+}
+
+// observeError запоминает первую ошибку передачи
+func (c *signalConn) observeError(err error) {
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+}
+
+// SetNoDelay передает настройку TCP соединению
+func (c *signalConn) SetNoDelay(noDelay bool) error {
+	if tcpConn, ok := c.Conn.(*net.TCPConn); ok {
+		return tcpConn.SetNoDelay(noDelay)
+	}
+	return nil
+}
+
+// Close закрывает соединение и учитывает его в агрегатах
+func (c *signalConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.record)
+	return err
+}
+
+// record классифицирует соединение и учитывает его монитором
+func (c *signalConn) record() {
+	c.mutex.Lock()
+	signal := c.classify()
+	target := c.target
+	if c.serverName != "" {
+		target = c.serverName
+	}
+	c.mutex.Unlock()
+
+	c.monitor.record(c.method, target, signal)
+}
+
+// classify определяет сигнал цензуры по состоянию соединения
+func (c *signalConn) classify() domain.CensorshipSignal {
+	switch {
+	case c.dns.verdict(c.monitor):
+		return domain.CensorshipSignalDNSPoisoning
+	case c.firstWrite.IsZero():
+		return ""
+	case c.rx == 0 && connectionReset(c.err):
+		return domain.CensorshipSignalRSTAfterClientHello
+	case c.rx == 0 && c.monitor.now().Sub(c.firstWrite) >= c.monitor.timeout:
+		return domain.CensorshipSignalTimeoutAfterSNI
+	case c.throttled():
+		return domain.CensorshipSignalThrottling
+	}
+	return ""
+}
+
+// throttled сравнивает скорость после throttleAfter байт со скоростью начала
+// ответа, паузы простоя не учитываются
+func (c *signalConn) throttled() bool {
+	if c.headBytes < c.throttleAfter || c.tailBytes < c.throttleAfter || c.tailActive <= 0 {
+		return false
+	}
+	headActive := c.headActive
+	if headActive < time.Millisecond {
+		headActive = time.Millisecond
+	}
+	headRate := float64(c.headBytes) / headActive.Seconds()
+	tailRate := float64(c.tailBytes) / c.tailActive.Seconds()
+	return tailRate < headRate*throttleRatio
+}
+
+// connectionReset проверяет, что соединение сброшено удаленной стороной
+func connectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
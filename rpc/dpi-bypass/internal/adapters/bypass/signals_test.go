@@ -0,0 +1,309 @@
+package bypass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startResetServer принимает соединения и сбрасывает их после первой
+// порции данных, как DPI после ClientHello
+func startResetServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buffer := make([]byte, 4096)
+			_, _ = conn.Read(buffer)
+			_ = conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// startSilentServer принимает соединения и читает данные, ничего не отвечая
+func startSilentServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// scriptedConn отдает заранее заданные порции, сдвигая часы монитора
+type scriptedConn struct {
+	net.Conn
+	clock  *time.Time
+	chunks []int
+	delays []time.Duration
+}
+
+func (c *scriptedConn) Read(b []byte) (int, error) {
+	if len(c.chunks) == 0 {
+		return 0, io.EOF
+	}
+	*c.clock = c.clock.Add(c.delays[0])
+	n := c.chunks[0]
+	c.chunks, c.delays = c.chunks[1:], c.delays[1:]
+	return n, nil
+}
+
+func (c *scriptedConn) Write(b []byte) (int, error) { return len(b), nil }
+
+func (c *scriptedConn) Close() error { return nil }
+
+func TestSignalMonitor_RSTAfterClientHello(t *testing.T) {
+	monitor := NewSignalMonitor(zap.NewNop())
+	address := startResetServer(t)
+
+	conn, err := monitor.Dial(context.Background(), &domain.BypassConfig{Method: domain.BypassMethodTLSHandshake}, address, time.Second)
+	require.NoError(t, err)
+	_, err = conn.Write(captureGoClientHello(t, "blocked.example.com"))
+	require.NoError(t, err)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1024))
+	require.Error(t, err)
+	require.NoError(t, conn.Close())
+
+	stats, err := monitor.Stats(nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, domain.BypassMethodTLSHandshake, stats[0].Method)
+	assert.Equal(t, "blocked.example.com", stats[0].Target)
+	assert.Equal(t, int64(1), stats[0].Connections)
+	assert.Equal(t, int64(1), stats[0].RSTAfterClientHello)
+}
+
+func TestSignalMonitor_TimeoutAfterSNI(t *testing.T) {
+	monitor := NewSignalMonitor(zap.NewNop())
+	monitor.timeout = 50 * time.Millisecond
+	address := startSilentServer(t)
+
+	conn, err := monitor.Dial(context.Background(), &domain.BypassConfig{Method: domain.BypassMethodV2Ray}, address, time.Second)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("request without answer"))
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, conn.Close())
+
+	host, _, _ := net.SplitHostPort(address)
+	stats, err := monitor.Stats(&domain.CensorshipSignalFilters{Target: host})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(1), stats[0].TimeoutAfterSNI)
+	assert.Zero(t, stats[0].RSTAfterClientHello)
+}
+
+func TestSignalMonitor_Throttling(t *testing.T) {
+	monitor := NewSignalMonitor(zap.NewNop())
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	monitor.now = func() time.Time { return clock }
+
+	newConn := func(tailDelay time.Duration) *signalConn {
+		return &signalConn{
+			Conn: &scriptedConn{
+				clock:  &clock,
+				chunks: []int{512, 512, 512, 512, 512, 512, 512, 512},
+				delays: []time.Duration{
+					0, time.Millisecond, time.Millisecond, time.Millisecond,
+					tailDelay, tailDelay, tailDelay, tailDelay,
+				},
+			},
+			monitor:       monitor,
+			method:        domain.BypassMethodShadowsocks,
+			target:        "cdn.example.com",
+			throttleAfter: 2048,
+		}
+	}
+
+	// Скорость после первых 2 KiB падает в сотни раз
+	throttled := newConn(500 * time.Millisecond)
+	_, _ = throttled.Write([]byte("request"))
+	for {
+		if _, err := throttled.Read(make([]byte, 512)); err != nil {
+			break
+		}
+	}
+	require.NoError(t, throttled.Close())
+
+	// Ровная скорость не считается замедлением
+	steady := newConn(time.Millisecond)
+	_, _ = steady.Write([]byte("request"))
+	for {
+		if _, err := steady.Read(make([]byte, 512)); err != nil {
+			break
+		}
+	}
+	require.NoError(t, steady.Close())
+
+	stats, err := monitor.Stats(nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(2), stats[0].Connections)
+	assert.Equal(t, int64(1), stats[0].Throttling)
+	assert.Zero(t, stats[0].TimeoutAfterSNI)
+}
+
+func TestSignalMonitor_DNSPoisoning(t *testing.T) {
+	monitor := NewSignalMonitor(zap.NewNop())
+	monitor.lookup = func(ctx context.Context, reference, host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("10.10.34.36")}, nil
+	}
+
+	config := &domain.BypassConfig{Method: domain.BypassMethodObfs4}
+	<-monitor.checkDNS("blocked.invalid", "").done
+
+	_, err := monitor.Dial(context.Background(), config, "blocked.invalid:443", time.Second)
+	require.Error(t, err)
+
+	stats, err := monitor.Stats(&domain.CensorshipSignalFilters{Method: domain.BypassMethodObfs4})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "blocked.invalid", stats[0].Target)
+	assert.Equal(t, int64(1), stats[0].DNSPoisoning)
+}
+
+func TestSignalMonitor_DNSReference(t *testing.T) {
+	monitor := NewSignalMonitor(zap.NewNop())
+	answers := map[string][]net.IP{
+		"":           {net.ParseIP("203.0.113.10")},
+		"1.1.1.1:53": {net.ParseIP("198.51.100.20")},
+		"8.8.8.8:53": {net.ParseIP("198.51.100.20"), net.ParseIP("203.0.113.10")},
+	}
+	monitor.lookup = func(ctx context.Context, reference, host string) ([]net.IP, error) {
+		return answers[reference], nil
+	}
+
+	poisoned, err := monitor.dnsPoisoned(context.Background(), "example.com", "1.1.1.1:53")
+	require.NoError(t, err)
+	assert.True(t, poisoned)
+
+	poisoned, err = monitor.dnsPoisoned(context.Background(), "example.com", "8.8.8.8:53")
+	require.NoError(t, err)
+	assert.False(t, poisoned)
+
+	poisoned, err = monitor.dnsPoisoned(context.Background(), "example.com", "")
+	require.NoError(t, err)
+	assert.False(t, poisoned)
+}
+
+func TestSignalMonitor_WindowsAndExport(t *testing.T) {
+	monitor := NewSignalMonitor(zap.NewNop())
+	clock := time.Date(2026, 1, 1, 12, 0, 30, 0, time.UTC)
+	monitor.now = func() time.Time { return clock }
+
+	monitor.record(domain.BypassMethodTLSHandshake, "example.com", domain.CensorshipSignalRSTAfterClientHello)
+	clock = clock.Add(time.Minute)
+	monitor.record(domain.BypassMethodTLSHandshake, "example.com", "")
+	monitor.record(domain.BypassMethodV2Ray, "example.com", domain.CensorshipSignalThrottling)
+	clock = clock.Add(5 * time.Minute)
+	monitor.record(domain.BypassMethodTLSHandshake, "example.com", domain.CensorshipSignalTimeoutAfterSNI)
+
+	stats, err := monitor.Stats(&domain.CensorshipSignalFilters{
+		Method: domain.BypassMethodTLSHandshake,
+		Window: 5 * time.Minute,
+	})
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), stats[0].WindowStart)
+	assert.Equal(t, time.Date(2026, 1, 1, 12, 5, 0, 0, time.UTC), stats[0].WindowEnd)
+	assert.Equal(t, int64(2), stats[0].Connections)
+	assert.Equal(t, int64(1), stats[0].RSTAfterClientHello)
+	assert.Equal(t, int64(1), stats[1].TimeoutAfterSNI)
+
+	_, err = monitor.Stats(&domain.CensorshipSignalFilters{Window: 90 * time.Second})
+	assert.Error(t, err)
+
+	// Выдаются только завершенные минуты
+	exported := monitor.Export(clock)
+	require.Len(t, exported, 3)
+	assert.Equal(t, domain.BypassMethodTLSHandshake, exported[0].Method)
+	assert.Equal(t, domain.BypassMethodTLSHandshake, exported[1].Method)
+	assert.Equal(t, domain.BypassMethodV2Ray, exported[2].Method)
+
+	// До подтверждения выгрузка повторяется, после — минуты не выдаются
+	assert.Equal(t, exported, monitor.Export(clock))
+	monitor.Acknowledge(clock)
+	assert.Empty(t, monitor.Export(clock))
+
+	exported = monitor.Export(clock.Add(time.Minute))
+	require.Len(t, exported, 1)
+	assert.Equal(t, int64(1), exported[0].TimeoutAfterSNI)
+}
+
+func TestSignalMonitor_NilDial(t *testing.T) {
+	var monitor *SignalMonitor
+	address := startSilentServer(t)
+
+	conn, err := monitor.Dial(context.Background(), &domain.BypassConfig{}, address, time.Second)
+	require.NoError(t, err)
+	assert.IsType(t, &net.TCPConn{}, conn)
+	conn.Close()
+}
+
+func TestMultiBypassAdapter_CensorshipSignals(t *testing.T) {
+	adapter := NewMultiBypassAdapter(zap.NewNop())
+	_, port, err := net.SplitHostPort(startResetServer(t))
+	require.NoError(t, err)
+
+	config := &domain.BypassConfig{
+		ID:     "signals-tls",
+		Method: domain.BypassMethodTLSHandshake,
+		Parameters: map[string]string{
+			"local_port":  "0",
+			"remote_host": "127.0.0.1",
+			"remote_port": port,
+		},
+	}
+	require.NoError(t, adapter.Start(config))
+	defer adapter.Stop(config.ID)
+
+	tlsAdapter := adapter.runningAdapter(config.ID).(*TLSFragmentAdapter)
+	tlsAdapter.mutex.RLock()
+	listenAddr := tlsAdapter.running[config.ID].listener.Addr().String()
+	tlsAdapter.mutex.RUnlock()
+
+	client, err := net.Dial("tcp", listenAddr)
+	require.NoError(t, err)
+	_, err = client.Write(captureGoClientHello(t, "blocked.example.com"))
+	require.NoError(t, err)
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _ = client.Read(make([]byte, 1024))
+	client.Close()
+
+	require.Eventually(t, func() bool {
+		stats, err := adapter.CensorshipSignals(&domain.CensorshipSignalFilters{Target: "blocked.example.com"})
+		return err == nil && len(stats) == 1 && stats[0].RSTAfterClientHello == 1
+	}, 5*time.Second, 20*time.Millisecond, fmt.Sprintf("no reset recorded for %s", config.ID))
+}
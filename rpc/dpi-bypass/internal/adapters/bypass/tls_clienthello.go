@@ -0,0 +1,245 @@
+package bypass
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Константы TLS, необходимые для разбора ClientHello
+const (
+	tlsRecordHeaderSize     = 5
+	tlsRecordTypeHandshake  = 0x16
+	tlsHandshakeClientHello = 0x01
+	tlsMaxRecordPayload     = 16384
+	tlsMaxClientHelloSize   = 64 * 1024
+
+	tlsExtensionServerName = 0x0000
+	tlsExtensionPadding    = 0x0015
+	tlsExtensionECH        = 0xfe0d
+	tlsServerNameTypeHost  = 0x00
+)
+
+// Ошибки разбора ClientHello
+var (
+	ErrNotTLSHandshake    = errors.New("tls: not a handshake record")
+	ErrNotClientHello     = errors.New("tls: not a client hello")
+	ErrMalformedHello     = errors.New("tls: malformed client hello")
+	ErrClientHelloTooLong = errors.New("tls: client hello too long")
+)
+
+// clientHello разобранный ClientHello.
+// Все смещения отсчитываются от начала handshake сообщения (байт типа).
+type clientHello struct {
+	recordVersion uint16
+	handshake     []byte
+
+	serverName   string
+	sniOffset    int // начало имени хоста в SNI, -1 если SNI нет
+	sniLength    int
+	extLenOffset int // поле длины блока расширений, -1 если расширений нет
+	paddingStart int // начало данных расширения padding, -1 если его нет
+	paddingLen   int
+
+	extensionTypes []uint16 // типы расширений в порядке следования
+}
+
+// readClientHello читает TLS записи до получения полного ClientHello.
+// Возвращает исходные байты (для прозрачной пересылки) и разобранное сообщение.
+// Если первая запись не является handshake, возвращаются прочитанные байты и ErrNotTLSHandshake.
+func readClientHello(r io.Reader) ([]byte, *clientHello, error) {
+	var raw []byte
+	var handshake []byte
+	var recordVersion uint16
+
+	header := make([]byte, tlsRecordHeaderSize)
+	for {
+		n, err := io.ReadFull(r, header)
+		raw = append(raw, header[:n]...)
+		if err != nil {
+			return raw, nil, err
+		}
+		if header[0] != tlsRecordTypeHandshake {
+			return raw, nil, ErrNotTLSHandshake
+		}
+
+		if recordVersion == 0 {
+			recordVersion = binary.BigEndian.Uint16(header[1:3])
+		}
+		length := int(binary.BigEndian.Uint16(header[3:5]))
+		if length == 0 || length > tlsMaxRecordPayload {
+			return raw, nil, ErrMalformedHello
+		}
+
+		offset := len(raw)
+		raw = append(raw, make([]byte, length)...)
+		if n, err := io.ReadFull(r, raw[offset:]); err != nil {
+			return raw[:offset+n], nil, err
+		}
+		handshake = append(handshake, raw[offset:]...)
+
+		if len(handshake) >= 4 {
+			if handshake[0] != tlsHandshakeClientHello {
+				return raw, nil, ErrNotClientHello
+			}
+			total := 4 + (int(handshake[1])<<16 | int(handshake[2])<<8 | int(handshake[3]))
+			if total > tlsMaxClientHelloSize {
+				return raw, nil, ErrClientHelloTooLong
+			}
+			if len(handshake) >= total {
+				if len(handshake) > total {
+					// Следующее handshake сообщение в той же записи клиентом не отправляется
+					return raw, nil, ErrMalformedHello
+				}
+				hello, err := parseClientHello(recordVersion, handshake)
+				return raw, hello, err
+			}
+		}
+	}
+}
+
+// parseClientHello разбирает handshake сообщение ClientHello
+func parseClientHello(recordVersion uint16, handshake []byte) (*clientHello, error) {
+	if len(handshake) < 4 || handshake[0] != tlsHandshakeClientHello {
+		return nil, ErrNotClientHello
+	}
+	length := int(handshake[1])<<16 | int(handshake[2])<<8 | int(handshake[3])
+	if len(handshake) != 4+length {
+		return nil, ErrMalformedHello
+	}
+
+	hello := &clientHello{
+		recordVersion: recordVersion,
+		handshake:     handshake,
+		sniOffset:     -1,
+		extLenOffset:  -1,
+		paddingStart:  -1,
+	}
+
+	p := &helloParser{data: handshake, pos: 4}
+	p.skip(2 + 32)  // legacy_version + random
+	p.skipVector(1) // session_id
+	p.skipVector(2) // cipher_suites
+	p.skipVector(1) // compression_methods
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	// Расширения необязательны
+	if p.pos == len(handshake) {
+		return hello, nil
+	}
+
+	hello.extLenOffset = p.pos
+	extLen := p.uint16()
+	extEnd := p.pos + extLen
+	if p.err != nil || extEnd != len(handshake) {
+		return nil, ErrMalformedHello
+	}
+
+	for p.pos < extEnd {
+		extType := p.uint16()
+		extLen := p.uint16()
+		extStart := p.pos
+		p.skip(extLen)
+		if p.err != nil {
+			return nil, p.err
+		}
+		hello.extensionTypes = append(hello.extensionTypes, uint16(extType))
+
+		switch extType {
+		case tlsExtensionServerName:
+			if err := hello.parseServerName(handshake[extStart:extStart+extLen], extStart); err != nil {
+				return nil, err
+			}
+		case tlsExtensionPadding:
+			hello.paddingStart = extStart
+			hello.paddingLen = extLen
+		}
+	}
+
+	return hello, nil
+}
+
+// hasExtension проверяет наличие расширения
+func (h *clientHello) hasExtension(extType uint16) bool {
+	for _, t := range h.extensionTypes {
+		if t == extType {
+			return true
+		}
+	}
+	return false
+}
+
+// parseServerName находит host_name в расширении server_name
+func (h *clientHello) parseServerName(ext []byte, base int) error {
+	p := &helloParser{data: ext}
+	listEnd := 2 + p.uint16()
+	if p.err != nil || listEnd != len(ext) {
+		return ErrMalformedHello
+	}
+
+	for p.pos < listEnd {
+		nameType := p.uint8()
+		nameLen := p.uint16()
+		nameStart := p.pos
+		p.skip(nameLen)
+		if p.err != nil {
+			return p.err
+		}
+		if nameType == tlsServerNameTypeHost && h.sniOffset < 0 {
+			h.serverName = string(ext[nameStart : nameStart+nameLen])
+			h.sniOffset = base + nameStart
+			h.sniLength = nameLen
+		}
+	}
+
+	return nil
+}
+
+// helloParser последовательное чтение полей с проверкой границ
+type helloParser struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (p *helloParser) skip(n int) {
+	if p.err != nil {
+		return
+	}
+	if n < 0 || p.pos+n > len(p.data) {
+		p.err = fmt.Errorf("%w: unexpected end at offset %d", ErrMalformedHello, p.pos)
+		return
+	}
+	p.pos += n
+}
+
+func (p *helloParser) uint8() int {
+	start := p.pos
+	p.skip(1)
+	if p.err != nil {
+		return 0
+	}
+	return int(p.data[start])
+}
+
+func (p *helloParser) uint16() int {
+	start := p.pos
+	p.skip(2)
+	if p.err != nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(p.data[start:]))
+}
+
+func (p *helloParser) skipVector(lengthSize int) {
+	var n int
+	if lengthSize == 1 {
+		n = p.uint8()
+	} else {
+		n = p.uint16()
+	}
+	p.skip(n)
+}
@@ -0,0 +1,125 @@
+package bypass
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadClientHelloFixture загружает ClientHello, снятый с реального клиента
+func loadClientHelloFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	raw, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	require.NoError(t, err)
+	return raw
+}
+
+// captureGoClientHello возвращает ClientHello, отправленный crypto/tls
+func captureGoClientHello(t *testing.T, serverName string) []byte {
+	t.Helper()
+
+	client, server := net.Pipe()
+	defer server.Close()
+
+	go func() {
+		tlsConn := tls.Client(client, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+		_ = tlsConn.SetDeadline(time.Now().Add(2 * time.Second))
+		_ = tlsConn.Handshake()
+		client.Close()
+	}()
+
+	raw, _, err := readClientHello(server)
+	require.NoError(t, err)
+	return raw
+}
+
+func TestReadClientHello_Fixtures(t *testing.T) {
+	cases := []struct {
+		name       string
+		raw        []byte
+		serverName string
+		hasPadding bool
+	}{
+		{"openssl", loadClientHelloFixture(t, "clienthello_openssl.hex"), "www.example.com", false},
+		{"curl", loadClientHelloFixture(t, "clienthello_curl.hex"), "blocked.example.org", true},
+		{"crypto/tls", captureGoClientHello(t, "go.example.net"), "go.example.net", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, hello, err := readClientHello(bytes.NewReader(tc.raw))
+			require.NoError(t, err)
+			assert.Equal(t, tc.raw, raw)
+			assert.Equal(t, tc.serverName, hello.serverName)
+			assert.Equal(t, len(tc.serverName), hello.sniLength)
+			assert.Equal(t, tc.serverName, string(hello.handshake[hello.sniOffset:hello.sniOffset+hello.sniLength]))
+			assert.Equal(t, tc.hasPadding, hello.paddingStart >= 0)
+			assert.Greater(t, hello.extLenOffset, 0)
+		})
+	}
+}
+
+func TestReadClientHello_MultipleRecords(t *testing.T) {
+	raw := loadClientHelloFixture(t, "clienthello_curl.hex")
+	_, hello, err := readClientHello(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	// Тот же ClientHello, разбитый на записи по 100 байт
+	fragmented := buildTLSRecords(hello.recordVersion, hello.handshake, 100)
+	_, reassembled, err := readClientHello(bytes.NewReader(fragmented))
+	require.NoError(t, err)
+	assert.Equal(t, hello.handshake, reassembled.handshake)
+	assert.Equal(t, hello.serverName, reassembled.serverName)
+}
+
+func TestReadClientHello_Errors(t *testing.T) {
+	raw := loadClientHelloFixture(t, "clienthello_openssl.hex")
+
+	t.Run("не TLS", func(t *testing.T) {
+		data, hello, err := readClientHello(strings.NewReader("GET / HTTP/1.1\r\n\r\n"))
+		assert.ErrorIs(t, err, ErrNotTLSHandshake)
+		assert.Nil(t, hello)
+		assert.Equal(t, []byte("GET /"), data)
+	})
+
+	t.Run("не ClientHello", func(t *testing.T) {
+		corrupted := append([]byte(nil), raw...)
+		corrupted[5] = 0x02
+		_, _, err := readClientHello(bytes.NewReader(corrupted))
+		assert.ErrorIs(t, err, ErrNotClientHello)
+	})
+
+	t.Run("обрыв", func(t *testing.T) {
+		_, _, err := readClientHello(bytes.NewReader(raw[:len(raw)-10]))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("неверная длина расширений", func(t *testing.T) {
+		_, hello, err := readClientHello(bytes.NewReader(raw))
+		require.NoError(t, err)
+		corrupted := append([]byte(nil), hello.handshake...)
+		corrupted[hello.extLenOffset+1]++
+		_, err = parseClientHello(hello.recordVersion, corrupted)
+		assert.ErrorIs(t, err, ErrMalformedHello)
+	})
+
+	t.Run("без SNI", func(t *testing.T) {
+		noSNI := captureGoClientHello(t, "")
+		_, hello, err := readClientHello(bytes.NewReader(noSNI))
+		require.NoError(t, err)
+		assert.Equal(t, -1, hello.sniOffset)
+		assert.Empty(t, hello.serverName)
+	})
+}
@@ -0,0 +1,258 @@
+package bypass
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TLSFragmentAdapter прозрачный прокси, фрагментирующий TLS ClientHello
+type TLSFragmentAdapter struct {
+	running map[string]*tlsFragmentConnection
+	mutex   sync.RWMutex
+	logger  *zap.Logger
+	signals *SignalMonitor
+}
+
+type tlsFragmentConnection struct {
+	// config заменяется при перезагрузке без перезапуска
+	config     atomic.Pointer[domain.BypassConfig]
+	listener   net.Listener
+	ctx        context.Context
+	cancel     context.CancelFunc
+	stats      *domain.BypassStats
+	statsMutex sync.RWMutex
+	// tracker учитывает клиентские соединения для плавной остановки
+	tracker *connectionTracker
+	// Параметры фрагментации
+	remoteAddr string
+	options    tlsHelloOptions
+	rules      *ruleMatcher
+	// inbound входящий SOCKS5/HTTP прокси; nil в режиме raw
+	inbound *inboundFrontend
+}
+
+// NewTLSFragmentAdapter создает новый адаптер фрагментации TLS
+func NewTLSFragmentAdapter(logger *zap.Logger) *TLSFragmentAdapter {
+	return &TLSFragmentAdapter{
+		running: make(map[string]*tlsFragmentConnection),
+		logger:  logger,
+	}
+}
+
+// observeSignals подключает монитор сигналов цензуры к исходящим соединениям
+func (t *TLSFragmentAdapter) observeSignals(monitor *SignalMonitor) {
+	t.signals = monitor
+}
+
+// Start запускает прокси фрагментации
+func (t *TLSFragmentAdapter) Start(config *domain.BypassConfig) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, exists := t.running[config.ID]; exists {
+		return fmt.Errorf("tls fragment connection already running: %s", config.ID)
+	}
+
+	// Получаем параметры из конфигурации
+	localPort := config.Parameters["local_port"]
+	if localPort == "" {
+		localPort = "1080"
+	}
+	remoteHost := config.Parameters["remote_host"]
+	if remoteHost == "" {
+		remoteHost = "127.0.0.1"
+	}
+	remotePort := config.Parameters["remote_port"]
+	if remotePort == "" {
+		remotePort = "443"
+	}
+
+	options, err := parseTransparentHelloOptions(config.Parameters, defaultTLSHelloOptions())
+	if err != nil {
+		return err
+	}
+
+	// Проверяем параметры правил заранее, чтобы не падать на соединениях
+	if err := checkTLSFragmentRules(config.Rules, options); err != nil {
+		return err
+	}
+
+	inbound, err := parseInboundConfig(config.Parameters)
+	if err != nil {
+		return err
+	}
+
+	// Создаем контекст для управления жизненным циклом
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Создаем listener
+	listener, err := inbound.Listen(localPort)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create listener: %w", err)
+	}
+
+	conn := &tlsFragmentConnection{
+		listener:   listener,
+		ctx:        ctx,
+		cancel:     cancel,
+		tracker:    newConnectionTracker(),
+		remoteAddr: net.JoinHostPort(remoteHost, remotePort),
+		options:    options,
+		rules:      newRuleMatcher(config.Rules),
+		stats: &domain.BypassStats{
+			ID:                     config.ID,
+			ConfigID:               config.ID,
+			SessionID:              config.ID,
+			BytesReceived:          0,
+			BytesSent:              0,
+			ConnectionsEstablished: 0,
+			StartTime:              time.Now(),
+			EndTime:                time.Now(),
+		},
+	}
+	conn.config.Store(config)
+	conn.inbound = newInboundFrontend(inbound, conn.rules, config.ID, t.logger)
+	if conn.inbound != nil {
+		conn.inbound.onTraffic = func(rx, tx int64) { t.updateStats(conn, rx, tx) }
+		conn.inbound.onError = func() { t.incrementErrorCount(conn) }
+	}
+
+	t.running[config.ID] = conn
+
+	// Запускаем обработку соединений
+	go t.handleConnections(conn)
+
+	t.logger.Info("tls fragment proxy started",
+		zap.String("id", config.ID),
+		zap.String("local_port", localPort),
+		zap.String("remote", conn.remoteAddr),
+		zap.String("split_mode", options.SplitMode),
+		zap.Int("record_fragment_size", options.RecordFragmentSize))
+
+	return nil
+}
+
+// Stop останавливает TLS fragment прокси: закрывает listener, прерывает
+// соединения и ждет завершения их горутин
+func (t *TLSFragmentAdapter) Stop(id string) error {
+	detached, err := t.Detach(id)
+	if err != nil {
+		return err
+	}
+	detached.Drain(0)
+
+	t.logger.Info("tls fragment proxy stopped", zap.String("id", id))
+	return nil
+}
+
+// GetStats возвращает статистику прокси фрагментации
+func (t *TLSFragmentAdapter) GetStats(id string) (*domain.BypassStats, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	conn, exists := t.running[id]
+	if !exists {
+		return nil, nil
+	}
+
+	return t.snapshotStats(conn), nil
+}
+
+// snapshotStats возвращает копию статистики соединения
+func (t *TLSFragmentAdapter) snapshotStats(conn *tlsFragmentConnection) *domain.BypassStats {
+	conn.statsMutex.RLock()
+	defer conn.statsMutex.RUnlock()
+
+	// Возвращаем копию статистики
+	stats := *conn.stats
+	return &stats
+}
+
+// IsRunning проверяет, запущен ли прокси фрагментации
+func (t *TLSFragmentAdapter) IsRunning(id string) bool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	_, exists := t.running[id]
+	return exists
+}
+
+// Reload применяет правила к запущенному соединению. Изменения параметров
+// требуют перезапуска.
+func (t *TLSFragmentAdapter) Reload(config *domain.BypassConfig) error {
+	t.mutex.RLock()
+	conn, exists := t.running[config.ID]
+	t.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("tls fragment connection not found: %s", config.ID)
+	}
+	if !hotSwappable(conn.config.Load(), config) {
+		return errRestartRequired
+	}
+	if err := checkTLSFragmentRules(config.Rules, conn.options); err != nil {
+		return err
+	}
+
+	conn.rules.Replace(config.Rules)
+	conn.config.Store(config)
+
+	t.logger.Info("tls fragment configuration reloaded", zap.String("id", config.ID))
+	return nil
+}
+
+// parseTransparentHelloOptions разбирает параметры ClientHello прозрачного
+// прокси. Прокси не завершает TLS, поэтому смешивание регистра SNI и padding
+// не поддерживаются: они меняют transcript, и handshake клиента с сервером не
+// завершится. Такие параметры отклоняются, а не игнорируются.
+func parseTransparentHelloOptions(parameters map[string]string, defaults tlsHelloOptions) (tlsHelloOptions, error) {
+	for _, key := range []string{"sni_case_mixing", "padding_length"} {
+		if parameters[key] != "" {
+			return defaults, fmt.Errorf("%s is not supported without TLS termination", key)
+		}
+	}
+	return parseTLSHelloOptions(parameters, defaults)
+}
+
+// checkTLSFragmentRules проверяет параметры правил
+func checkTLSFragmentRules(rules []*domain.BypassRule, options tlsHelloOptions) error {
+	for _, rule := range rules {
+		if _, err := parseTransparentHelloOptions(rule.Parameters, options); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// Detach убирает соединение из запущенных для плавной замены новым
+func (t *TLSFragmentAdapter) Detach(id string) (*detachedConnection, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	conn, exists := t.running[id]
+	if !exists {
+		return nil, fmt.Errorf("tls fragment connection not found: %s", id)
+	}
+	delete(t.running, id)
+
+	return &detachedConnection{
+		config: conn.config.Load(),
+		closeListeners: func() {
+			conn.listener.Close()
+		},
+		wait: conn.tracker.wait,
+		stop: func() {
+			conn.cancel()
+			conn.tracker.abort()
+			conn.tracker.done()
+		},
+		stats: func() *domain.BypassStats { return t.snapshotStats(conn) },
+	}, nil
+}
@@ -0,0 +1,230 @@
+package bypass
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// handleConnections обрабатывает входящие соединения
+func (t *TLSFragmentAdapter) handleConnections(conn *tlsFragmentConnection) {
+	defer conn.tracker.acceptStopped()
+
+	for {
+		select {
+		case <-conn.ctx.Done():
+			return
+		default:
+			clientConn, err := conn.listener.Accept()
+			if err != nil {
+				if conn.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+					// Контекст отменен или listener закрыт, выходим
+					return
+				}
+				t.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.Load().ID))
+				t.incrementErrorCount(conn)
+				continue
+			}
+
+			// Обрабатываем соединение в отдельной горутине
+			conn.tracker.handle(clientConn, func() { t.handleClientConnection(conn, clientConn) })
+		}
+	}
+}
+
+// handleClientConnection читает ClientHello, применяет правила и пересылает его по частям
+func (t *TLSFragmentAdapter) handleClientConnection(conn *tlsFragmentConnection, clientConn net.Conn) {
+	defer clientConn.Close()
+
+	// Увеличиваем счетчик соединений
+	t.incrementConnections(conn)
+
+	remoteAddr := conn.remoteAddr
+	target := ruleTarget{Protocol: "tcp"}
+	if remoteHost, remotePort, err := net.SplitHostPort(conn.remoteAddr); err == nil {
+		target.IP = net.ParseIP(remoteHost)
+		target.Port = parsePort(remotePort)
+	}
+
+	if conn.inbound != nil {
+		request, ok := conn.inbound.accept(conn.ctx, clientConn)
+		if !ok {
+			return
+		}
+		// Клиент отправит ClientHello только после ответа прокси, поэтому
+		// ответ отправляется до подключения к назначению
+		if err := request.Reply(nil); err != nil {
+			return
+		}
+		clientConn = request.Conn
+		remoteAddr = request.Destination.Address()
+		target = request.Destination.ruleTarget("tcp")
+	}
+
+	if err := clientConn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.incrementErrorCount(conn)
+		return
+	}
+	raw, hello, helloErr := readClientHello(clientConn)
+	if helloErr != nil && !errors.Is(helloErr, ErrNotTLSHandshake) && !errors.Is(helloErr, ErrNotClientHello) {
+		t.logger.Debug("failed to read client hello", zap.Error(helloErr), zap.String("id", conn.config.Load().ID))
+		t.incrementErrorCount(conn)
+		return
+	}
+
+	// Сопоставляем соединение с правилами
+	if hello != nil {
+		target.Host = hello.serverName
+		target.Protocol = "tls"
+	}
+	rule := conn.rules.Match(target)
+
+	if rule != nil && rule.Action == domain.RuleActionBlock {
+		t.logger.Debug("connection blocked by rule",
+			zap.String("id", conn.config.Load().ID),
+			zap.String("rule", rule.ID),
+			zap.String("sni", target.Host))
+		return
+	}
+
+	// Подключаемся к удаленному серверу
+	remoteConn, err := t.signals.Dial(context.Background(), conn.config.Load(), remoteAddr, 10*time.Second)
+	if err != nil {
+		t.logger.Error("failed to connect to remote server",
+			zap.Error(err),
+			zap.String("id", conn.config.Load().ID),
+			zap.String("remote", remoteAddr))
+		t.incrementErrorCount(conn)
+		return
+	}
+	defer remoteConn.Close()
+
+	// Отправляем ClientHello: без изменений для allow и не-TLS трафика
+	var segments [][]byte
+	if hello != nil && (rule == nil || rule.Action != domain.RuleActionAllow) {
+		options := conn.options
+		if rule != nil {
+			// Параметры уже проверены при запуске
+			options, _ = parseTLSHelloOptions(rule.Parameters, conn.options)
+		}
+		segments = applyTLSHelloOptions(hello, options)
+		if err := t.writeSegments(remoteConn, segments, options.SplitDelay); err != nil {
+			t.incrementErrorCount(conn)
+			return
+		}
+	}
+	if segments == nil {
+		if _, err := remoteConn.Write(raw); err != nil {
+			t.incrementErrorCount(conn)
+			return
+		}
+	}
+	t.updateStats(conn, int64(len(raw)), 0)
+
+	if err := clientConn.SetReadDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	// Обе горутины завершаются до выхода: соединения закрываются, как только
+	// останавливается одно из направлений
+	err = relayConns(conn.ctx, clientConn, remoteConn,
+		func() error {
+			// Копируем данные от клиента к серверу
+			bytes, err := t.copyData(clientConn, remoteConn, conn, true)
+			t.updateStats(conn, bytes, 0)
+			return err
+		},
+		func() error {
+			// Копируем данные от сервера к клиенту
+			bytes, err := t.copyData(remoteConn, clientConn, conn, false)
+			t.updateStats(conn, 0, bytes)
+			return err
+		})
+	if err != nil {
+		t.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.Load().ID))
+	}
+}
+
+// writeSegments пишет каждый сегмент отдельным вызовом с паузой, чтобы ядро
+// отправило их разными TCP сегментами
+func (t *TLSFragmentAdapter) writeSegments(dst net.Conn, segments [][]byte, delay time.Duration) error {
+	// Соединение может быть обернуто монитором сигналов
+	if tcpConn, ok := dst.(interface{ SetNoDelay(bool) error }); ok {
+		_ = tcpConn.SetNoDelay(true)
+	}
+
+	for i, segment := range segments {
+		if i > 0 && delay > 0 {
+			time.Sleep(delay)
+		}
+		if _, err := dst.Write(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyData копирует данные между соединениями
+func (t *TLSFragmentAdapter) copyData(src, dst net.Conn, conn *tlsFragmentConnection, isTx bool) (int64, error) {
+	buffer := make([]byte, 4096)
+	var totalBytes int64
+
+	for {
+		n, err := src.Read(buffer)
+		if err != nil {
+			return totalBytes, err
+		}
+
+		if n > 0 {
+			// Устанавливаем таймаут для записи
+			if err := dst.SetWriteDeadline(time.Now().Add(relayWriteTimeout)); err != nil {
+				return totalBytes, err
+			}
+
+			_, err = dst.Write(buffer[:n])
+			if err != nil {
+				return totalBytes, err
+			}
+
+			totalBytes += int64(n)
+			t.updateLastActivity(conn)
+		}
+	}
+}
+
+// updateStats обновляет статистику
+func (t *TLSFragmentAdapter) updateStats(conn *tlsFragmentConnection, rx, tx int64) {
+	conn.statsMutex.Lock()
+	defer conn.statsMutex.Unlock()
+
+	conn.stats.BytesReceived += rx
+	conn.stats.BytesSent += tx
+}
+
+// updateLastActivity обновляет время последней активности
+func (t *TLSFragmentAdapter) updateLastActivity(conn *tlsFragmentConnection) {
+	conn.statsMutex.Lock()
+	defer conn.statsMutex.Unlock()
+
+	conn.stats.EndTime = time.Now()
+}
+
+// incrementConnections увеличивает счетчик соединений
+func (t *TLSFragmentAdapter) incrementConnections(conn *tlsFragmentConnection) {
+	conn.statsMutex.Lock()
+	defer conn.statsMutex.Unlock()
+
+	conn.stats.ConnectionsEstablished++
+}
+
+// incrementErrorCount увеличивает счетчик ошибок
+func (t *TLSFragmentAdapter) incrementErrorCount(conn *tlsFragmentConnection) {
+	conn.statsMutex.Lock()
+	defer conn.statsMutex.Unlock()
+
+	conn.stats.ConnectionsFailed++
+}
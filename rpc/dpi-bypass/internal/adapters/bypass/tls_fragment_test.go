@@ -0,0 +1,256 @@
+package bypass
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startTLSEchoServer запускает TLS echo-сервер с самоподписанным сертификатом
+func startTLSEchoServer(t *testing.T) int {
+	t.Helper()
+
+	cert, err := generateSelfSignedCert()
+	require.NoError(t, err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func startTLSFragmentAdapter(t *testing.T, config *domain.BypassConfig) (*TLSFragmentAdapter, string) {
+	t.Helper()
+
+	adapter := NewTLSFragmentAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(config))
+	t.Cleanup(func() { _ = adapter.Stop(config.ID) })
+
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+	return adapter, adapter.running[config.ID].listener.Addr().String()
+}
+
+func TestTLSFragmentAdapter_HandshakeThroughProxy(t *testing.T) {
+	serverPort := startTLSEchoServer(t)
+
+	adapter, addr := startTLSFragmentAdapter(t, &domain.BypassConfig{
+		ID:     "tls-fragment-1",
+		Method: domain.BypassMethodTLSHandshake,
+		Parameters: map[string]string{
+			"local_port":           "0",
+			"remote_host":          "127.0.0.1",
+			"remote_port":          fmt.Sprintf("%d", serverPort),
+			"split_mode":           "sni_middle",
+			"split_delay_ms":       "5",
+			"record_fragment_size": "32",
+		},
+	})
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", addr,
+		&tls.Config{ServerName: "localhost", InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello through fragments"))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	reply := make([]byte, len("hello through fragments"))
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "hello through fragments", string(reply))
+
+	stats, err := adapter.GetStats("tls-fragment-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.ConnectionsEstablished)
+}
+
+func TestTLSFragmentAdapter_SplitsBeforeSNI(t *testing.T) {
+	// Сервер фиксирует первую порцию данных, пришедшую по TCP
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	firstRead := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buffer := make([]byte, 4096)
+		n, _ := conn.Read(buffer)
+		firstRead <- buffer[:n]
+	}()
+
+	_, addr := startTLSFragmentAdapter(t, &domain.BypassConfig{
+		ID: "tls-fragment-split",
+		Parameters: map[string]string{
+			"local_port":     "0",
+			"remote_host":    "127.0.0.1",
+			"remote_port":    fmt.Sprintf("%d", listener.Addr().(*net.TCPAddr).Port),
+			"split_delay_ms": "100",
+		},
+	})
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write(loadClientHelloFixture(t, "clienthello_openssl.hex"))
+	require.NoError(t, err)
+
+	select {
+	case data := <-firstRead:
+		assert.NotContains(t, string(data), "www")
+		assert.Equal(t, byte(tlsRecordTypeHandshake), data[0])
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not receive data")
+	}
+}
+
+func TestTLSFragmentAdapter_Rules(t *testing.T) {
+	serverPort := startTLSEchoServer(t)
+
+	_, addr := startTLSFragmentAdapter(t, &domain.BypassConfig{
+		ID: "tls-fragment-rules",
+		Parameters: map[string]string{
+			"local_port":  "0",
+			"remote_host": "127.0.0.1",
+			"remote_port": fmt.Sprintf("%d", serverPort),
+		},
+		Rules: []*domain.BypassRule{
+			{ID: "block", Type: domain.RuleTypeDomain, Pattern: "blocked.example", Action: domain.RuleActionBlock, Enabled: true},
+			{ID: "tune", Type: domain.RuleTypeDomain, Pattern: "*.tuned.example", Action: domain.RuleActionFragment, Enabled: true,
+				Parameters: map[string]string{"record_fragment_size": "8", "split_mode": "sni_middle"}},
+		},
+	})
+
+	dial := func(serverName string) error {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", addr,
+			&tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	assert.Error(t, dial("blocked.example"))
+	assert.NoError(t, dial("www.tuned.example"))
+	assert.NoError(t, dial("other.example"))
+}
+
+func TestTLSFragmentAdapter_InvalidRuleParameters(t *testing.T) {
+	adapter := NewTLSFragmentAdapter(zap.NewNop())
+	err := adapter.Start(&domain.BypassConfig{
+		ID:         "tls-fragment-invalid",
+		Parameters: map[string]string{"local_port": "0"},
+		Rules: []*domain.BypassRule{
+			{ID: "bad", Parameters: map[string]string{"split_mode": "everywhere"}},
+		},
+	})
+	assert.Error(t, err)
+	assert.False(t, adapter.IsRunning("tls-fragment-invalid"))
+}
+
+func TestTLSFragmentAdapter_SNIMaskingKeepsHandshake(t *testing.T) {
+	cert, err := generateSelfSignedCert()
+	require.NoError(t, err)
+
+	// Сервер запоминает SNI, который получил
+	serverNames := make(chan string, 1)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- hello.ServerName
+			return nil, nil
+		},
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	_, addr := startTLSFragmentAdapter(t, &domain.BypassConfig{
+		ID:   "tls-fragment-sni-masking",
+		Type: domain.BypassTypeSNIMasking,
+		Parameters: map[string]string{
+			"local_port":  "0",
+			"remote_host": "127.0.0.1",
+			"remote_port": fmt.Sprintf("%d", listener.Addr().(*net.TCPAddr).Port),
+		},
+	})
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", addr,
+		&tls.Config{ServerName: "www.example.com", InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.Handshake())
+
+	_, err = conn.Write([]byte("masked"))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	reply := make([]byte, len("masked"))
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "masked", string(reply))
+	assert.Equal(t, "www.example.com", <-serverNames)
+}
+
+func TestTLSFragmentAdapter_RejectsTranscriptChanges(t *testing.T) {
+	for _, parameters := range []map[string]string{
+		{"sni_case_mixing": "true"},
+		{"padding_length": "64"},
+	} {
+		adapter := NewTLSFragmentAdapter(zap.NewNop())
+		config := &domain.BypassConfig{ID: "tls-fragment-transcript", Parameters: map[string]string{"local_port": "0"}}
+		for key, value := range parameters {
+			config.Parameters[key] = value
+		}
+		assert.ErrorContains(t, adapter.Start(config), "without TLS termination")
+		assert.False(t, adapter.IsRunning(config.ID))
+
+		// Те же параметры в правиле
+		config = &domain.BypassConfig{
+			ID:         "tls-fragment-transcript",
+			Parameters: map[string]string{"local_port": "0"},
+			Rules:      []*domain.BypassRule{{ID: "mix", Parameters: parameters}},
+		}
+		assert.ErrorContains(t, adapter.Start(config), "without TLS termination")
+	}
+
+	// Перезагрузка не добавляет такие правила к запущенному прокси
+	config := &domain.BypassConfig{ID: "tls-fragment-reload", Parameters: map[string]string{"local_port": "0"}}
+	adapter, _ := startTLSFragmentAdapter(t, config)
+	reloaded := *config
+	reloaded.Rules = []*domain.BypassRule{{ID: "mix", Parameters: map[string]string{"sni_case_mixing": "true"}}}
+	assert.ErrorContains(t, adapter.Reload(&reloaded), "without TLS termination")
+}
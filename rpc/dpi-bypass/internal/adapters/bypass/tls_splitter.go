@@ -0,0 +1,132 @@
+package bypass
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Режимы разбиения ClientHello на TCP сегменты
+const (
+	tlsSplitNone      = "none"
+	tlsSplitSNI       = "sni"        // разрез перед именем хоста
+	tlsSplitSNIMiddle = "sni_middle" // разрез перед именем хоста и посередине имени
+)
+
+// tlsHelloOptions параметры модификации ClientHello
+type tlsHelloOptions struct {
+	SplitMode          string
+	SplitDelay         time.Duration
+	RecordFragmentSize int // 0 - без фрагментации записей
+}
+
+// defaultTLSHelloOptions значения по умолчанию
+func defaultTLSHelloOptions() tlsHelloOptions {
+	return tlsHelloOptions{
+		SplitMode:  tlsSplitSNI,
+		SplitDelay: 10 * time.Millisecond,
+	}
+}
+
+// parseTLSHelloOptions разбирает параметры конфигурации или правила
+func parseTLSHelloOptions(parameters map[string]string, defaults tlsHelloOptions) (tlsHelloOptions, error) {
+	opts := defaults
+
+	if value := parameters["split_mode"]; value != "" {
+		switch value {
+		case tlsSplitNone, tlsSplitSNI, tlsSplitSNIMiddle:
+			opts.SplitMode = value
+		default:
+			return opts, fmt.Errorf("invalid split_mode: %s", value)
+		}
+	}
+
+	if value := parameters["split_delay_ms"]; value != "" {
+		delay, err := strconv.Atoi(value)
+		if err != nil || delay < 0 {
+			return opts, fmt.Errorf("invalid split_delay_ms: %s", value)
+		}
+		opts.SplitDelay = time.Duration(delay) * time.Millisecond
+	}
+
+	if value := parameters["record_fragment_size"]; value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 || size > tlsMaxRecordPayload {
+			return opts, fmt.Errorf("invalid record_fragment_size: %s", value)
+		}
+		opts.RecordFragmentSize = size
+	}
+
+	return opts, nil
+}
+
+// applyTLSHelloOptions строит TCP сегменты для отправки ClientHello.
+// Фрагментация записей и разбиение на сегменты не меняют handshake
+// сообщение, поэтому transcript клиента и сервера совпадает.
+func applyTLSHelloOptions(hello *clientHello, opts tlsHelloOptions) [][]byte {
+	fragmentSize := opts.RecordFragmentSize
+	if fragmentSize <= 0 || fragmentSize > tlsMaxRecordPayload {
+		fragmentSize = tlsMaxRecordPayload
+	}
+	stream := buildTLSRecords(hello.recordVersion, hello.handshake, fragmentSize)
+
+	// Точки разреза в handshake переводим в смещения потока записей
+	var cuts []int
+	if hello.sniOffset >= 0 {
+		switch opts.SplitMode {
+		case tlsSplitSNI:
+			cuts = append(cuts, hello.sniOffset)
+		case tlsSplitSNIMiddle:
+			cuts = append(cuts, hello.sniOffset, hello.sniOffset+hello.sniLength/2)
+		}
+	}
+	for i, cut := range cuts {
+		cuts[i] = tlsStreamOffset(cut, fragmentSize)
+	}
+
+	return splitAt(stream, cuts)
+}
+
+// buildTLSRecords упаковывает handshake в записи размером не более fragmentSize
+func buildTLSRecords(version uint16, handshake []byte, fragmentSize int) []byte {
+	records := (len(handshake) + fragmentSize - 1) / fragmentSize
+	stream := make([]byte, 0, len(handshake)+records*tlsRecordHeaderSize)
+
+	for start := 0; start < len(handshake); start += fragmentSize {
+		end := start + fragmentSize
+		if end > len(handshake) {
+			end = len(handshake)
+		}
+		header := [tlsRecordHeaderSize]byte{tlsRecordTypeHandshake}
+		binary.BigEndian.PutUint16(header[1:3], version)
+		binary.BigEndian.PutUint16(header[3:5], uint16(end-start))
+		stream = append(stream, header[:]...)
+		stream = append(stream, handshake[start:end]...)
+	}
+
+	return stream
+}
+
+// tlsStreamOffset переводит смещение в handshake в смещение в потоке записей
+func tlsStreamOffset(offset, fragmentSize int) int {
+	record := offset / fragmentSize
+	return offset + (record+1)*tlsRecordHeaderSize
+}
+
+// splitAt режет данные по возрастающим точкам, пропуская пустые сегменты
+func splitAt(data []byte, cuts []int) [][]byte {
+	sort.Ints(cuts)
+
+	segments := make([][]byte, 0, len(cuts)+1)
+	prev := 0
+	for _, cut := range cuts {
+		if cut <= prev || cut >= len(data) {
+			continue
+		}
+		segments = append(segments, data[prev:cut])
+		prev = cut
+	}
+	return append(segments, data[prev:])
+}
@@ -0,0 +1,106 @@
+package bypass
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseFixtureHello(t *testing.T, name string) *clientHello {
+	t.Helper()
+
+	_, hello, err := readClientHello(bytes.NewReader(loadClientHelloFixture(t, name)))
+	require.NoError(t, err)
+	return hello
+}
+
+// reassemble склеивает сегменты и снова разбирает ClientHello
+func reassemble(t *testing.T, segments [][]byte) *clientHello {
+	t.Helper()
+
+	_, hello, err := readClientHello(bytes.NewReader(bytes.Join(segments, nil)))
+	require.NoError(t, err)
+	return hello
+}
+
+func TestApplyTLSHelloOptions_SplitAtSNI(t *testing.T) {
+	for _, fixture := range []string{"clienthello_openssl.hex", "clienthello_curl.hex"} {
+		t.Run(fixture, func(t *testing.T) {
+			hello := parseFixtureHello(t, fixture)
+
+			segments := applyTLSHelloOptions(hello, tlsHelloOptions{SplitMode: tlsSplitSNI})
+			require.Len(t, segments, 2)
+
+			// Имя хоста начинается ровно со второго сегмента
+			assert.True(t, bytes.HasPrefix(segments[1], []byte(hello.serverName)))
+			assert.NotContains(t, string(segments[0]), hello.serverName)
+			assert.Equal(t, hello.handshake, reassemble(t, segments).handshake)
+		})
+	}
+}
+
+func TestApplyTLSHelloOptions_SplitInsideSNI(t *testing.T) {
+	hello := parseFixtureHello(t, "clienthello_openssl.hex")
+
+	segments := applyTLSHelloOptions(hello, tlsHelloOptions{SplitMode: tlsSplitSNIMiddle})
+	require.Len(t, segments, 3)
+
+	half := hello.sniLength / 2
+	assert.Equal(t, hello.serverName[:half], string(segments[1]))
+	assert.True(t, strings.HasPrefix(string(segments[2]), hello.serverName[half:]))
+	for _, segment := range segments {
+		assert.NotContains(t, string(segment), hello.serverName)
+	}
+}
+
+func TestApplyTLSHelloOptions_RecordFragmentation(t *testing.T) {
+	hello := parseFixtureHello(t, "clienthello_curl.hex")
+
+	for _, size := range []int{1, 16, 100, 517} {
+		segments := applyTLSHelloOptions(hello, tlsHelloOptions{SplitMode: tlsSplitSNIMiddle, RecordFragmentSize: size})
+
+		stream := bytes.Join(segments, nil)
+		records := (len(hello.handshake) + size - 1) / size
+		assert.Equal(t, len(hello.handshake)+records*tlsRecordHeaderSize, len(stream))
+
+		// Записи несут исходное handshake сообщение без изменений
+		assert.Equal(t, hello.handshake, reassemble(t, segments).handshake)
+	}
+}
+
+func TestApplyTLSHelloOptions_NoSplit(t *testing.T) {
+	raw := loadClientHelloFixture(t, "clienthello_openssl.hex")
+	_, hello, err := readClientHello(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	segments := applyTLSHelloOptions(hello, tlsHelloOptions{SplitMode: tlsSplitNone})
+	require.Len(t, segments, 1)
+	assert.Equal(t, raw, segments[0])
+}
+
+func TestParseTLSHelloOptions(t *testing.T) {
+	opts, err := parseTLSHelloOptions(map[string]string{
+		"split_mode":           "sni_middle",
+		"split_delay_ms":       "25",
+		"record_fragment_size": "64",
+	}, defaultTLSHelloOptions())
+	require.NoError(t, err)
+	assert.Equal(t, tlsHelloOptions{
+		SplitMode:          tlsSplitSNIMiddle,
+		SplitDelay:         25 * time.Millisecond,
+		RecordFragmentSize: 64,
+	}, opts)
+
+	for key, value := range map[string]string{
+		"split_mode":           "random",
+		"split_delay_ms":       "-1",
+		"record_fragment_size": "20000",
+	} {
+		_, err := parseTLSHelloOptions(map[string]string{key: value}, defaultTLSHelloOptions())
+		assert.Error(t, err, key)
+	}
+}
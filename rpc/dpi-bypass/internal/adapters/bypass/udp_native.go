@@ -0,0 +1,359 @@
+package bypass
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// shadowsocksPacketOutbound нативный UDP Shadowsocks: каждая датаграмма
+// отправляется на UDP порт сервера с адресом назначения в начале
+type shadowsocksPacketOutbound struct {
+	conn net.Conn
+}
+
+// newShadowsocksPacketOutbound открывает UDP сокет к серверу Shadowsocks
+func newShadowsocksPacketOutbound(remoteAddr string) (packetOutbound, error) {
+	conn, err := net.DialTimeout("udp", remoteAddr, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shadowsocks udp socket: %w", err)
+	}
+	return &shadowsocksPacketOutbound{conn: conn}, nil
+}
+
+func (s *shadowsocksPacketOutbound) WritePacket(payload []byte, destination proxyDestination) error {
+	packet := appendSocksAddr(make([]byte, 0, len(destination.Host)+7+len(payload)), destination)
+	_, err := s.conn.Write(append(packet, payload...))
+	return err
+}
+
+func (s *shadowsocksPacketOutbound) ReadPacket(buffer []byte) (int, proxyDestination, error) {
+	for {
+		n, err := s.conn.Read(buffer)
+		if err != nil {
+			return 0, proxyDestination{}, err
+		}
+		source, size, err := parseSocksAddr(buffer[:n])
+		if err != nil {
+			// Поврежденная датаграмма отбрасывается
+			continue
+		}
+		return copy(buffer, buffer[size:n]), source, nil
+	}
+}
+
+func (s *shadowsocksPacketOutbound) Close() error {
+	return s.conn.Close()
+}
+
+// Константы протокола VLESS
+const (
+	vlessVersion = 0x00
+
+	vlessCommandTCP = 0x01
+	vlessCommandUDP = 0x02
+
+	vlessAddrIPv4   = 0x01
+	vlessAddrDomain = 0x02
+	vlessAddrIPv6   = 0x03
+)
+
+// parseVLESSID разбирает UUID пользователя VLESS
+func parseVLESSID(value string) ([16]byte, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return [16]byte{}, fmt.Errorf("invalid uuid: %s", value)
+	}
+	return id, nil
+}
+
+// appendVLESSRequest добавляет заголовок запроса VLESS: версия, UUID,
+// дополнения (пусто), команда, порт и адрес назначения
+func appendVLESSRequest(dst []byte, id [16]byte, command byte, destination proxyDestination) []byte {
+	dst = append(dst, vlessVersion)
+	dst = append(dst, id[:]...)
+	dst = append(dst, 0x00, command)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(destination.Port))
+
+	if ip := net.ParseIP(destination.Host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			dst = append(dst, vlessAddrIPv4)
+			return append(dst, ip4...)
+		}
+		dst = append(dst, vlessAddrIPv6)
+		return append(dst, ip.To16()...)
+	}
+
+	host := destination.Host
+	if len(host) > 255 {
+		host = host[:255]
+	}
+	dst = append(dst, vlessAddrDomain, byte(len(host)))
+	return append(dst, host...)
+}
+
+// readVLESSRequest читает заголовок запроса VLESS на стороне сервера
+func readVLESSRequest(r io.Reader) ([16]byte, byte, proxyDestination, error) {
+	var id [16]byte
+	header := make([]byte, 1+len(id)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return id, 0, proxyDestination{}, err
+	}
+	if header[0] != vlessVersion {
+		return id, 0, proxyDestination{}, fmt.Errorf("unsupported vless version: %d", header[0])
+	}
+	copy(id[:], header[1:17])
+	if addons := int64(header[17]); addons > 0 {
+		if _, err := io.CopyN(io.Discard, r, addons); err != nil {
+			return id, 0, proxyDestination{}, err
+		}
+	}
+
+	// Команда, порт и тип адреса
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(r, request); err != nil {
+		return id, 0, proxyDestination{}, err
+	}
+	port := int(binary.BigEndian.Uint16(request[1:3]))
+
+	var size int
+	switch request[3] {
+	case vlessAddrIPv4:
+		size = net.IPv4len
+	case vlessAddrIPv6:
+		size = net.IPv6len
+	case vlessAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(r, length); err != nil {
+			return id, 0, proxyDestination{}, err
+		}
+		size = int(length[0])
+	default:
+		return id, 0, proxyDestination{}, fmt.Errorf("unsupported vless address type: %d", request[3])
+	}
+
+	address := make([]byte, size)
+	if _, err := io.ReadFull(r, address); err != nil {
+		return id, 0, proxyDestination{}, err
+	}
+	destination := proxyDestination{Host: string(address), Port: port}
+	if request[3] != vlessAddrDomain {
+		destination.Host = net.IP(address).String()
+	}
+	return id, request[0], destination, nil
+}
+
+// readVLESSResponse читает заголовок ответа: версия и дополнения
+func readVLESSResponse(r io.Reader) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if header[0] != vlessVersion {
+		return fmt.Errorf("unsupported vless version: %d", header[0])
+	}
+	_, err := io.CopyN(io.Discard, r, int64(header[1]))
+	return err
+}
+
+// vlessConn соединение VLESS, заголовок ответа которого пропускается при
+// первом чтении
+type vlessConn struct {
+	net.Conn
+	responseOnce sync.Once
+	responseErr  error
+}
+
+func (c *vlessConn) Read(p []byte) (int, error) {
+	c.responseOnce.Do(func() {
+		c.responseErr = readVLESSResponse(c.Conn)
+	})
+	if c.responseErr != nil {
+		return 0, c.responseErr
+	}
+	return c.Conn.Read(p)
+}
+
+// vlessPacketOutbound нативный UDP VLESS: для каждого назначения отдельный
+// поток с командой UDP, датаграммы в нем передаются с префиксом длины
+type vlessPacketOutbound struct {
+	dial    func(destination proxyDestination) (net.Conn, error)
+	streams map[string]*vlessPacketStream
+	mutex   sync.Mutex
+	packets chan udpStreamPacket
+	closed  chan struct{}
+	once    sync.Once
+}
+
+type vlessPacketStream struct {
+	conn       net.Conn
+	writeMutex sync.Mutex
+}
+
+// newVLESSPacketOutbound создает UDP канал VLESS. dial открывает поток к
+// серверу и отправляет заголовок запроса с командой UDP.
+func newVLESSPacketOutbound(dial func(destination proxyDestination) (net.Conn, error)) packetOutbound {
+	return &vlessPacketOutbound{
+		dial:    dial,
+		streams: make(map[string]*vlessPacketStream),
+		packets: make(chan udpStreamPacket, udpStreamQueueSize),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (v *vlessPacketOutbound) WritePacket(payload []byte, destination proxyDestination) error {
+	if len(payload) > 0xffff {
+		return fmt.Errorf("udp datagram too large: %d bytes", len(payload))
+	}
+	stream, err := v.stream(destination)
+	if err != nil {
+		return err
+	}
+
+	packet := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(payload)), uint16(len(payload)))
+	packet = append(packet, payload...)
+
+	stream.writeMutex.Lock()
+	defer stream.writeMutex.Unlock()
+	if _, err := stream.conn.Write(packet); err != nil {
+		v.drop(destination.Address(), stream)
+		return err
+	}
+	return nil
+}
+
+func (v *vlessPacketOutbound) ReadPacket(buffer []byte) (int, proxyDestination, error) {
+	select {
+	case packet := <-v.packets:
+		return copy(buffer, packet.payload), packet.source, nil
+	case <-v.closed:
+		return 0, proxyDestination{}, ErrUDPStreamClosed
+	}
+}
+
+func (v *vlessPacketOutbound) Close() error {
+	v.once.Do(func() {
+		close(v.closed)
+
+		v.mutex.Lock()
+		defer v.mutex.Unlock()
+		for key, stream := range v.streams {
+			stream.conn.Close()
+			delete(v.streams, key)
+		}
+	})
+	return nil
+}
+
+// stream возвращает поток назначения, открывая его при первой датаграмме
+func (v *vlessPacketOutbound) stream(destination proxyDestination) (*vlessPacketStream, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	select {
+	case <-v.closed:
+		return nil, ErrUDPStreamClosed
+	default:
+	}
+
+	key := destination.Address()
+	if stream, ok := v.streams[key]; ok {
+		return stream, nil
+	}
+
+	conn, err := v.dial(destination)
+	if err != nil {
+		return nil, err
+	}
+	stream := &vlessPacketStream{conn: conn}
+	v.streams[key] = stream
+	go v.readStream(destination, stream)
+	return stream, nil
+}
+
+// readStream читает ответные датаграммы потока
+func (v *vlessPacketOutbound) readStream(destination proxyDestination, stream *vlessPacketStream) {
+	defer v.drop(destination.Address(), stream)
+
+	length := make([]byte, 2)
+	for {
+		if _, err := io.ReadFull(stream.conn, length); err != nil {
+			return
+		}
+		payload := make([]byte, binary.BigEndian.Uint16(length))
+		if _, err := io.ReadFull(stream.conn, payload); err != nil {
+			return
+		}
+
+		select {
+		case v.packets <- udpStreamPacket{source: destination, payload: payload}:
+		case <-v.closed:
+			return
+		default:
+		}
+	}
+}
+
+// drop закрывает поток; следующая датаграмма откроет новый
+func (v *vlessPacketOutbound) drop(key string, stream *vlessPacketStream) {
+	stream.conn.Close()
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.streams[key] == stream {
+		delete(v.streams, key)
+	}
+}
+
+// relayVLESSPackets пересылает датаграммы с префиксом длины из потока VLESS
+// в UDP сокет назначения и обратно, пока назначение отвечает чаще
+// idleTimeout
+func relayVLESSPackets(ctx context.Context, stream, remote net.Conn, idleTimeout time.Duration, traffic func(rx, tx int64)) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() {
+		stream.Close()
+		remote.Close()
+	})
+	defer stop()
+
+	go func() {
+		defer cancel()
+		buffer := make([]byte, maxUDPPacketSize)
+		for {
+			if err := remote.SetReadDeadline(time.Now().Add(idleTimeout)); err != nil {
+				return
+			}
+			n, err := remote.Read(buffer)
+			if err != nil {
+				return
+			}
+			packet := binary.BigEndian.AppendUint16(make([]byte, 0, 2+n), uint16(n))
+			if _, err := stream.Write(append(packet, buffer[:n]...)); err != nil {
+				return
+			}
+			traffic(0, int64(n))
+		}
+	}()
+
+	length := make([]byte, 2)
+	for {
+		if _, err := io.ReadFull(stream, length); err != nil {
+			return
+		}
+		payload := make([]byte, binary.BigEndian.Uint16(length))
+		if _, err := io.ReadFull(stream, payload); err != nil {
+			return
+		}
+		if _, err := remote.Write(payload); err != nil {
+			return
+		}
+		traffic(int64(len(payload)), 0)
+	}
+}
@@ -0,0 +1,284 @@
+package bypass
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// udpMode способ передачи датаграмм методом обхода
+type udpMode string
+
+const (
+	udpModeStream udpMode = "stream" // UDP-over-stream внутри потока метода
+	udpModeNative udpMode = "native" // собственный UDP протокола (Shadowsocks, VLESS)
+)
+
+// udpConfig параметры UDP адаптера
+type udpConfig struct {
+	// Listen включает UDP listener на local_port, датаграммы с него идут на Target
+	Listen      bool
+	Target      proxyDestination
+	Mode        udpMode
+	IdleTimeout time.Duration
+}
+
+// parseUDPConfig разбирает параметры udp, udp_target, udp_mode и
+// udp_idle_timeout_ms
+func parseUDPConfig(parameters map[string]string) (udpConfig, error) {
+	config := udpConfig{Mode: udpMode(parameters["udp_mode"])}
+
+	switch config.Mode {
+	case "":
+		config.Mode = udpModeStream
+	case udpModeStream, udpModeNative:
+	default:
+		return config, fmt.Errorf("unsupported udp_mode: %s", config.Mode)
+	}
+
+	if value := parameters["udp"]; value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid udp: %s", value)
+		}
+		config.Listen = enabled
+	}
+
+	if value := parameters["udp_target"]; value != "" {
+		host, port, err := net.SplitHostPort(value)
+		if err != nil || host == "" || parsePort(port) == 0 {
+			return config, fmt.Errorf("invalid udp_target: %s", value)
+		}
+		config.Target = proxyDestination{Host: host, Port: parsePort(port)}
+	}
+	if config.Listen && config.Target.Host == "" {
+		return config, fmt.Errorf("udp_target is required with udp")
+	}
+
+	timeout, err := parseUDPIdleTimeout(parameters)
+	if err != nil {
+		return config, err
+	}
+	config.IdleTimeout = timeout
+
+	return config, nil
+}
+
+// parseUDPIdleTimeout разбирает udp_idle_timeout_ms
+func parseUDPIdleTimeout(parameters map[string]string) (time.Duration, error) {
+	value := parameters["udp_idle_timeout_ms"]
+	if value == "" {
+		return defaultUDPIdleTimeout, nil
+	}
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms <= 0 {
+		return 0, fmt.Errorf("invalid udp_idle_timeout_ms: %s", value)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// udpRelay UDP listener адаптера: у каждого источника своя сессия в
+// исходящем канале, датаграммы идут на фиксированную цель (например,
+// endpoint WireGuard)
+type udpRelay struct {
+	conn        *net.UDPConn
+	target      proxyDestination
+	rules       *ruleMatcher
+	packets     func() (packetOutbound, error)
+	idleTimeout time.Duration
+	logger      *zap.Logger
+	id          string
+	sessions    map[string]*udpRelaySession
+	mutex       sync.Mutex
+	done        chan struct{}
+	// Счетчики адаптера
+	onTraffic func(rx, tx int64)
+	onError   func()
+}
+
+type udpRelaySession struct {
+	client       *net.UDPAddr
+	outbound     packetOutbound
+	lastActivity atomic.Int64
+}
+
+func (s *udpRelaySession) touch() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+// newUDPRelay открывает UDP listener на порту TCP listener адаптера.
+// Возвращает nil, если UDP listener не включен. Обработка датаграмм
+// начинается после start, чтобы адаптер успел задать счетчики.
+func newUDPRelay(config udpConfig, listener net.Listener, rules *ruleMatcher, packets func() (packetOutbound, error), id string, logger *zap.Logger) (*udpRelay, error) {
+	if !config.Listen {
+		return nil, nil
+	}
+
+	addr := &net.UDPAddr{}
+	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok {
+		addr.IP, addr.Port = tcpAddr.IP, tcpAddr.Port
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create udp listener: %w", err)
+	}
+
+	relay := &udpRelay{
+		conn:        conn,
+		target:      config.Target,
+		rules:       rules,
+		packets:     packets,
+		idleTimeout: config.IdleTimeout,
+		logger:      logger,
+		id:          id,
+		sessions:    make(map[string]*udpRelaySession),
+		done:        make(chan struct{}),
+		onTraffic:   func(rx, tx int64) {},
+		onError:     func() {},
+	}
+	return relay, nil
+}
+
+// start запускает обработку датаграмм
+func (r *udpRelay) start() {
+	go r.serve()
+	go r.expireIdle()
+}
+
+// Close останавливает listener и закрывает все сессии
+func (r *udpRelay) Close() error {
+	close(r.done)
+	err := r.conn.Close()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for key, session := range r.sessions {
+		session.outbound.Close()
+		delete(r.sessions, key)
+	}
+	return err
+}
+
+// serve читает датаграммы клиентов и отправляет их на цель
+func (r *udpRelay) serve() {
+	buffer := make([]byte, maxUDPPacketSize)
+	for {
+		n, source, err := r.conn.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+
+		session, err := r.session(source)
+		if err != nil {
+			r.logger.Debug("failed to open udp session",
+				zap.Error(err),
+				zap.String("id", r.id),
+				zap.String("client", source.String()))
+			r.onError()
+			continue
+		}
+		if session == nil {
+			continue
+		}
+
+		session.touch()
+		if err := session.outbound.WritePacket(buffer[:n], r.target); err != nil {
+			r.logger.Debug("failed to send udp packet", zap.Error(err), zap.String("id", r.id))
+			r.onError()
+			r.remove(source.String(), session)
+			continue
+		}
+		r.onTraffic(int64(n), 0)
+	}
+}
+
+// session возвращает сессию источника, создавая ее при первой датаграмме.
+// Возвращает nil, если цель заблокирована правилом.
+func (r *udpRelay) session(source *net.UDPAddr) (*udpRelaySession, error) {
+	key := source.String()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if session, ok := r.sessions[key]; ok {
+		return session, nil
+	}
+
+	packets := r.packets
+	if rule := r.rules.Match(r.target.ruleTarget("udp")); rule != nil {
+		switch rule.Action {
+		case domain.RuleActionBlock:
+			return nil, nil
+		case domain.RuleActionAllow:
+			packets = newDirectPacketOutbound
+		}
+	}
+
+	outbound, err := packets()
+	if err != nil {
+		return nil, err
+	}
+	session := &udpRelaySession{client: source, outbound: outbound}
+	session.touch()
+	r.sessions[key] = session
+	go r.forwardReplies(key, session)
+	return session, nil
+}
+
+// forwardReplies возвращает ответы цели клиенту сессии
+func (r *udpRelay) forwardReplies(key string, session *udpRelaySession) {
+	defer r.remove(key, session)
+
+	buffer := make([]byte, maxUDPPacketSize)
+	for {
+		n, _, err := session.outbound.ReadPacket(buffer)
+		if err != nil {
+			return
+		}
+		session.touch()
+		if _, err := r.conn.WriteToUDP(buffer[:n], session.client); err != nil {
+			return
+		}
+		r.onTraffic(0, int64(n))
+	}
+}
+
+// expireIdle закрывает сессии без трафика дольше таймаута
+func (r *udpRelay) expireIdle() {
+	ticker := time.NewTicker(r.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			deadline := time.Now().Add(-r.idleTimeout).UnixNano()
+			r.mutex.Lock()
+			for key, session := range r.sessions {
+				if session.lastActivity.Load() <= deadline {
+					session.outbound.Close()
+					delete(r.sessions, key)
+				}
+			}
+			r.mutex.Unlock()
+		}
+	}
+}
+
+// remove закрывает сессию, если она еще актуальна
+func (r *udpRelay) remove(key string, session *udpRelaySession) {
+	session.outbound.Close()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.sessions[key] == session {
+		delete(r.sessions, key)
+	}
+}
@@ -0,0 +1,334 @@
+package bypass
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// udpRelayExchange отправляет датаграмму на UDP listener адаптера и ждет ответ
+func udpRelayExchange(t *testing.T, client net.Conn, payload string) string {
+	t.Helper()
+
+	require.NoError(t, client.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err := client.Write([]byte(payload))
+	require.NoError(t, err)
+	buffer := make([]byte, maxUDPPacketSize)
+	n, err := client.Read(buffer)
+	require.NoError(t, err)
+	return string(buffer[:n])
+}
+
+// dialUDPRelay открывает UDP сокет клиента к listener адаптера
+func dialUDPRelay(t *testing.T, port int) net.Conn {
+	t.Helper()
+
+	client, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestParseUDPConfig(t *testing.T) {
+	config, err := parseUDPConfig(map[string]string{})
+	require.NoError(t, err)
+	assert.False(t, config.Listen)
+	assert.Equal(t, udpModeStream, config.Mode)
+	assert.Equal(t, defaultUDPIdleTimeout, config.IdleTimeout)
+
+	config, err = parseUDPConfig(map[string]string{
+		"udp":                 "true",
+		"udp_target":          "10.0.0.1:51820",
+		"udp_mode":            "native",
+		"udp_idle_timeout_ms": "1500",
+	})
+	require.NoError(t, err)
+	assert.True(t, config.Listen)
+	assert.Equal(t, proxyDestination{Host: "10.0.0.1", Port: 51820}, config.Target)
+	assert.Equal(t, udpModeNative, config.Mode)
+	assert.Equal(t, 1500*time.Millisecond, config.IdleTimeout)
+
+	for name, params := range map[string]map[string]string{
+		"неизвестный режим":  {"udp_mode": "quic"},
+		"неверный флаг":      {"udp": "maybe"},
+		"без цели":           {"udp": "true"},
+		"цель без порта":     {"udp": "true", "udp_target": "10.0.0.1"},
+		"неверный таймаут":   {"udp_idle_timeout_ms": "0"},
+		"цель с нулем порта": {"udp_target": "10.0.0.1:0"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseUDPConfig(params)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUDPRelay_CustomClientToDestinationHeaderServer(t *testing.T) {
+	logger := zap.NewNop()
+	// Сервер отвечает адресом отправителя: так видно, сколько сокетов открыто
+	// на стороне выхода
+	endpointPort := startUDPWhoAmIServer(t)
+	blockedPort := startUDPWhoAmIServer(t)
+
+	server := NewCustomAdapter(logger)
+	require.NoError(t, server.Start(&domain.BypassConfig{
+		ID:     "udp-custom-server",
+		Method: domain.BypassMethodCustom,
+		Parameters: map[string]string{
+			"local_port":         "0",
+			"role":               "server",
+			"destination_header": "true",
+			"password":           "shared-secret",
+		},
+	}))
+	t.Cleanup(func() { _ = server.Stop("udp-custom-server") })
+
+	startClient := func(id string, target int, rules ...*domain.BypassRule) int {
+		client := NewCustomAdapter(logger)
+		require.NoError(t, client.Start(&domain.BypassConfig{
+			ID:     id,
+			Method: domain.BypassMethodCustom,
+			Parameters: map[string]string{
+				"local_port":  "0",
+				"role":        "client",
+				"remote_host": "127.0.0.1",
+				"remote_port": strconv.Itoa(customListenerPort(t, server, "udp-custom-server")),
+				"password":    "shared-secret",
+				"udp":         "true",
+				"udp_target":  fmt.Sprintf("127.0.0.1:%d", target),
+			},
+			Rules: rules,
+		}))
+		t.Cleanup(func() { _ = client.Stop(id) })
+		return customListenerPort(t, client, id)
+	}
+
+	// Туннель WireGuard: у каждого источника своя сессия в потоке
+	port := startClient("udp-custom-client", endpointPort)
+	first := dialUDPRelay(t, port)
+	second := dialUDPRelay(t, port)
+
+	firstExit := udpRelayExchange(t, first, "handshake")
+	secondExit := udpRelayExchange(t, second, "handshake")
+	assert.NotEqual(t, firstExit, secondExit)
+	assert.Equal(t, firstExit, udpRelayExchange(t, first, "keepalive"))
+
+	// Цель, заблокированная правилом клиента, не получает датаграмм
+	port = startClient("udp-custom-blocked", blockedPort, &domain.BypassRule{
+		ID: "block", Type: domain.RuleTypePort, Action: domain.RuleActionBlock, Pattern: strconv.Itoa(blockedPort), Enabled: true,
+	})
+	blocked := dialUDPRelay(t, port)
+	_, err := blocked.Write([]byte("dropped"))
+	require.NoError(t, err)
+	require.NoError(t, blocked.SetReadDeadline(time.Now().Add(300*time.Millisecond)))
+	_, err = blocked.Read(make([]byte, 16))
+	assert.True(t, isTimeout(err))
+
+	for name, params := range map[string]map[string]string{
+		"udp на сервере":  {"role": "server", "destination_header": "true", "udp": "true", "udp_target": "127.0.0.1:51820"},
+		"нативный режим":  {"role": "client", "udp_mode": "native"},
+		"udp без адресов": {"role": "client", "udp": "true"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			params["local_port"] = "0"
+			params["password"] = "shared-secret"
+			assert.Error(t, NewCustomAdapter(logger).Start(&domain.BypassConfig{ID: "invalid", Parameters: params}))
+		})
+	}
+}
+
+// startShadowsocksUDPServer принимает датаграммы Shadowsocks UDP и
+// возвращает payload с адресом назначения в качестве источника
+func startShadowsocksUDPServer(t *testing.T) int {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, maxUDPPacketSize)
+		for {
+			n, addr, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			destination, size, err := parseSocksAddr(buffer[:n])
+			if err != nil {
+				continue
+			}
+			reply := appendSocksAddr(nil, destination)
+			_, _ = conn.WriteToUDP(append(reply, buffer[size:n]...), addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestUDPRelay_ShadowsocksNative(t *testing.T) {
+	remotePort := startShadowsocksUDPServer(t)
+	adapter := NewShadowsocksAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(&domain.BypassConfig{
+		ID:     "udp-ss-native",
+		Method: domain.BypassMethodShadowsocks,
+		Parameters: map[string]string{
+			"local_port":  "0",
+			"remote_host": "127.0.0.1",
+			"remote_port": strconv.Itoa(remotePort),
+			"udp":         "true",
+			"udp_target":  "10.0.0.1:51820",
+			"udp_mode":    "native",
+		},
+	}))
+	t.Cleanup(func() { _ = adapter.Stop("udp-ss-native") })
+
+	adapter.mutex.RLock()
+	port := adapter.running["udp-ss-native"].listener.Addr().(*net.TCPAddr).Port
+	adapter.mutex.RUnlock()
+
+	client := dialUDPRelay(t, port)
+	assert.Equal(t, "datagram", udpRelayExchange(t, client, "datagram"))
+
+	// Ответ учитывается после отправки клиенту
+	require.Eventually(t, func() bool {
+		stats, err := adapter.GetStats("udp-ss-native")
+		return err == nil && stats.BytesReceived == int64(len("datagram")) && stats.BytesSent == int64(len("datagram"))
+	}, time.Second, 10*time.Millisecond)
+}
+
+// startVLESSServer принимает запросы VLESS: команда TCP соединяет с
+// назначением, команда UDP пересылает датаграммы с префиксом длины
+func startVLESSServer(t *testing.T, id [16]byte) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				requestID, command, destination, err := readVLESSRequest(conn)
+				if err != nil || requestID != id {
+					return
+				}
+				if _, err := conn.Write([]byte{vlessVersion, 0}); err != nil {
+					return
+				}
+
+				switch command {
+				case vlessCommandTCP:
+					remote, err := net.Dial("tcp", destination.Address())
+					if err != nil {
+						return
+					}
+					defer remote.Close()
+					go func() { _, _ = io.Copy(remote, conn) }()
+					_, _ = io.Copy(conn, remote)
+				case vlessCommandUDP:
+					remote, err := net.Dial("udp", destination.Address())
+					if err != nil {
+						return
+					}
+					defer remote.Close()
+					go func() {
+						buffer := make([]byte, maxUDPPacketSize)
+						for {
+							n, err := remote.Read(buffer)
+							if err != nil {
+								return
+							}
+							packet := binary.BigEndian.AppendUint16(nil, uint16(n))
+							if _, err := conn.Write(append(packet, buffer[:n]...)); err != nil {
+								return
+							}
+						}
+					}()
+					length := make([]byte, 2)
+					for {
+						if _, err := io.ReadFull(conn, length); err != nil {
+							return
+						}
+						payload := make([]byte, binary.BigEndian.Uint16(length))
+						if _, err := io.ReadFull(conn, payload); err != nil {
+							return
+						}
+						_, _ = remote.Write(payload)
+					}
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestUDPRelay_V2RayVLESS(t *testing.T) {
+	const userID = "8d2c4f1e-6b3a-4c5d-9e7f-0a1b2c3d4e5f"
+	id, err := parseVLESSID(userID)
+	require.NoError(t, err)
+
+	echoPort := startEchoServer(t)
+	udpPort := startUDPEchoServer(t)
+	remotePort := startVLESSServer(t, id)
+
+	adapter := NewV2RayAdapter(zap.NewNop())
+	require.NoError(t, adapter.Start(&domain.BypassConfig{
+		ID:     "udp-vless",
+		Method: domain.BypassMethodV2Ray,
+		Parameters: map[string]string{
+			"local_port":  "0",
+			"remote_host": "127.0.0.1",
+			"remote_port": strconv.Itoa(remotePort),
+			"uuid":        userID,
+			"inbound":     "socks5",
+			"udp":         "true",
+			"udp_target":  fmt.Sprintf("127.0.0.1:%d", udpPort),
+			"udp_mode":    "native",
+		},
+	}))
+	t.Cleanup(func() { _ = adapter.Stop("udp-vless") })
+
+	adapter.mutex.RLock()
+	addr := adapter.running["udp-vless"].listener.Addr().(*net.TCPAddr)
+	adapter.mutex.RUnlock()
+
+	// TCP через запрос VLESS с командой TCP
+	conn := dialProxy(t, fmt.Sprintf("127.0.0.1:%d", addr.Port))
+	require.NoError(t, socks5Connect(conn, localDestination(echoPort), "", ""))
+	assertEcho(t, conn, "vless tcp")
+
+	// UDP через поток VLESS с командой UDP
+	client := dialUDPRelay(t, addr.Port)
+	assert.Equal(t, "vless udp", udpRelayExchange(t, client, "vless udp"))
+	assert.Equal(t, "again", udpRelayExchange(t, client, "again"))
+
+	for name, params := range map[string]map[string]string{
+		"нативный режим без uuid": {"udp_mode": "native"},
+		"неверный uuid":           {"uuid": "not-a-uuid"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			params["local_port"] = "0"
+			assert.Error(t, NewV2RayAdapter(zap.NewNop()).Start(&domain.BypassConfig{ID: "invalid", Parameters: params}))
+		})
+	}
+	assert.Error(t, NewObfs4Adapter(zap.NewNop()).Start(&domain.BypassConfig{
+		ID:         "invalid",
+		Parameters: map[string]string{"local_port": "0", "udp_mode": "native"},
+	}))
+}
@@ -0,0 +1,437 @@
+package bypass
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Формат UDP-over-stream: датаграммы внутри потока метода обхода.
+// Поток открывается заголовком назначения udpOverStreamHost:0, затем в обе
+// стороны идут кадры:
+//
+//	+----------------+--------------------+------------------------+---------+
+//	| length (2, BE) | session id (4, BE) | адрес (ATYP, адрес, порт) | payload |
+//	+----------------+--------------------+------------------------+---------+
+//
+// length покрывает все после себя. Клиент указывает назначение датаграммы,
+// сервер в ответах - ее источник. Сессия на сервере закрывается по таймауту
+// простоя.
+const (
+	udpOverStreamHost = "udp-over-stream.arpa"
+
+	udpStreamSessionIDSize = 4
+	udpStreamMaxFrameSize  = 0xffff
+	udpStreamQueueSize     = 64
+)
+
+// ErrUDPStreamClosed поток UDP-over-stream закрыт
+var ErrUDPStreamClosed = errors.New("udp stream closed")
+
+// udpOverStreamDestination назначение, которым открывается поток датаграмм
+func udpOverStreamDestination() proxyDestination {
+	return proxyDestination{Host: udpOverStreamHost}
+}
+
+// writeUDPStreamFrame пишет один кадр
+func writeUDPStreamFrame(w io.Writer, sessionID uint32, address proxyDestination, payload []byte) error {
+	frame := make([]byte, 2, 2+udpStreamSessionIDSize+len(address.Host)+4+len(payload))
+	frame = binary.BigEndian.AppendUint32(frame, sessionID)
+	frame = appendSocksAddr(frame, address)
+	frame = append(frame, payload...)
+	if len(frame)-2 > udpStreamMaxFrameSize {
+		return fmt.Errorf("udp datagram too large: %d bytes", len(payload))
+	}
+	binary.BigEndian.PutUint16(frame, uint16(len(frame)-2))
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// readUDPStreamFrame читает один кадр. payload указывает в buffer.
+func readUDPStreamFrame(r io.Reader, buffer []byte) (uint32, proxyDestination, []byte, error) {
+	if _, err := io.ReadFull(r, buffer[:2]); err != nil {
+		return 0, proxyDestination{}, nil, err
+	}
+	length := int(binary.BigEndian.Uint16(buffer[:2]))
+	if length < udpStreamSessionIDSize+1 || length > len(buffer) {
+		return 0, proxyDestination{}, nil, fmt.Errorf("invalid udp stream frame length: %d", length)
+	}
+
+	frame := buffer[:length]
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return 0, proxyDestination{}, nil, unexpectedEOF(err)
+	}
+
+	sessionID := binary.BigEndian.Uint32(frame)
+	address, size, err := parseSocksAddr(frame[udpStreamSessionIDSize:])
+	if err != nil {
+		return 0, proxyDestination{}, nil, err
+	}
+	return sessionID, address, frame[udpStreamSessionIDSize+size:], nil
+}
+
+// udpStreamPacket датаграмма, полученная из потока
+type udpStreamPacket struct {
+	source  proxyDestination
+	payload []byte
+}
+
+// udpStreamMux клиентская сторона потока: несколько сессий в одном потоке
+type udpStreamMux struct {
+	stream     net.Conn
+	writeMutex sync.Mutex
+	sessions   map[uint32]*udpStreamSession
+	nextID     uint32
+	mutex      sync.Mutex
+	closed     chan struct{}
+	closeOnce  sync.Once
+}
+
+// newUDPStreamMux запускает чтение ответов из потока
+func newUDPStreamMux(stream net.Conn) *udpStreamMux {
+	m := &udpStreamMux{
+		stream:   stream,
+		sessions: make(map[uint32]*udpStreamSession),
+		closed:   make(chan struct{}),
+	}
+	go m.readLoop()
+	return m
+}
+
+// Session открывает новую сессию в потоке
+func (m *udpStreamMux) Session() (*udpStreamSession, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	select {
+	case <-m.closed:
+		return nil, ErrUDPStreamClosed
+	default:
+	}
+
+	m.nextID++
+	session := &udpStreamSession{
+		mux:     m,
+		id:      m.nextID,
+		packets: make(chan udpStreamPacket, udpStreamQueueSize),
+		closed:  make(chan struct{}),
+	}
+	m.sessions[session.id] = session
+	return session, nil
+}
+
+// Closed сообщает, что поток закрыт
+func (m *udpStreamMux) Closed() bool {
+	select {
+	case <-m.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close закрывает поток и все сессии
+func (m *udpStreamMux) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.closed)
+		err = m.stream.Close()
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		for id, session := range m.sessions {
+			session.closeOnce.Do(func() { close(session.closed) })
+			delete(m.sessions, id)
+		}
+	})
+	return err
+}
+
+func (m *udpStreamMux) readLoop() {
+	defer m.Close()
+
+	buffer := make([]byte, udpStreamMaxFrameSize)
+	for {
+		sessionID, source, payload, err := readUDPStreamFrame(m.stream, buffer)
+		if err != nil {
+			return
+		}
+
+		m.mutex.Lock()
+		session := m.sessions[sessionID]
+		m.mutex.Unlock()
+		if session == nil {
+			continue
+		}
+
+		// Переполненная очередь означает медленного читателя: датаграмма
+		// отбрасывается, как это сделала бы сеть
+		select {
+		case session.packets <- udpStreamPacket{source: source, payload: append([]byte(nil), payload...)}:
+		default:
+		}
+	}
+}
+
+func (m *udpStreamMux) write(sessionID uint32, destination proxyDestination, payload []byte) error {
+	m.writeMutex.Lock()
+	defer m.writeMutex.Unlock()
+
+	if err := m.stream.SetWriteDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		return err
+	}
+	return writeUDPStreamFrame(m.stream, sessionID, destination, payload)
+}
+
+func (m *udpStreamMux) remove(id uint32) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.sessions, id)
+}
+
+// udpStreamSession сессия потока, реализует packetOutbound
+type udpStreamSession struct {
+	mux       *udpStreamMux
+	id        uint32
+	packets   chan udpStreamPacket
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *udpStreamSession) WritePacket(payload []byte, destination proxyDestination) error {
+	select {
+	case <-s.closed:
+		return ErrUDPStreamClosed
+	default:
+	}
+	if err := s.mux.write(s.id, destination, payload); err != nil {
+		// Сломанный поток закрывается, следующая сессия откроет новый
+		s.mux.Close()
+		return err
+	}
+	return nil
+}
+
+func (s *udpStreamSession) ReadPacket(buffer []byte) (int, proxyDestination, error) {
+	select {
+	case packet := <-s.packets:
+		return copy(buffer, packet.payload), packet.source, nil
+	case <-s.closed:
+		return 0, proxyDestination{}, ErrUDPStreamClosed
+	}
+}
+
+func (s *udpStreamSession) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.mux.remove(s.id)
+	})
+	return nil
+}
+
+// udpStreamPool держит один поток датаграмм к удаленной стороне и
+// переоткрывает его, если он закрылся
+type udpStreamPool struct {
+	dial  func() (net.Conn, error)
+	mux   *udpStreamMux
+	mutex sync.Mutex
+}
+
+// newUDPStreamPool создает пул; dial открывает поток метода обхода с
+// заголовком udpOverStreamDestination
+func newUDPStreamPool(dial func() (net.Conn, error)) *udpStreamPool {
+	return &udpStreamPool{dial: dial}
+}
+
+// Session открывает сессию в текущем потоке или в новом
+func (p *udpStreamPool) Session() (packetOutbound, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.mux == nil || p.mux.Closed() {
+		stream, err := p.dial()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open udp stream: %w", err)
+		}
+		p.mux = newUDPStreamMux(stream)
+	}
+	return p.mux.Session()
+}
+
+// Close закрывает текущий поток
+func (p *udpStreamPool) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.mux == nil {
+		return nil
+	}
+	return p.mux.Close()
+}
+
+// udpStreamServerOptions параметры серверной стороны UDP-over-stream
+type udpStreamServerOptions struct {
+	ID          string
+	Rules       *ruleMatcher
+	IdleTimeout time.Duration
+	Logger      *zap.Logger
+	OnTraffic   func(rx, tx int64)
+}
+
+// serveUDPOverStream декодирует датаграммы из потока, отправляет их
+// назначениям и возвращает ответы. Каждой сессии соответствует свой UDP
+// сокет, простаивающие сессии закрываются.
+func serveUDPOverStream(ctx context.Context, stream net.Conn, options udpStreamServerOptions) {
+	server := &udpStreamServer{
+		stream:   stream,
+		options:  options,
+		sessions: make(map[uint32]*udpStreamServerSession),
+	}
+	defer server.closeAll()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		stream.Close()
+	}()
+	go server.expireIdle(ctx)
+
+	buffer := make([]byte, udpStreamMaxFrameSize)
+	for {
+		sessionID, destination, payload, err := readUDPStreamFrame(stream, buffer)
+		if err != nil {
+			return
+		}
+
+		if rule := options.Rules.Match(destination.ruleTarget("udp")); rule != nil && rule.Action == domain.RuleActionBlock {
+			continue
+		}
+
+		session, err := server.session(sessionID)
+		if err != nil {
+			options.Logger.Debug("failed to open udp session", zap.Error(err), zap.String("id", options.ID))
+			continue
+		}
+		if err := session.outbound.WritePacket(payload, destination); err != nil {
+			options.Logger.Debug("failed to send udp packet",
+				zap.Error(err),
+				zap.String("id", options.ID),
+				zap.String("destination", destination.Address()))
+			continue
+		}
+		session.touch()
+		options.OnTraffic(int64(len(payload)), 0)
+	}
+}
+
+// udpStreamServer состояние серверной стороны одного потока
+type udpStreamServer struct {
+	stream     net.Conn
+	options    udpStreamServerOptions
+	writeMutex sync.Mutex
+	sessions   map[uint32]*udpStreamServerSession
+	mutex      sync.Mutex
+}
+
+type udpStreamServerSession struct {
+	outbound     packetOutbound
+	lastActivity time.Time
+	mutex        sync.Mutex
+}
+
+func (s *udpStreamServerSession) touch() {
+	s.mutex.Lock()
+	s.lastActivity = time.Now()
+	s.mutex.Unlock()
+}
+
+func (s *udpStreamServerSession) idleFor() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return time.Since(s.lastActivity)
+}
+
+// session возвращает сессию по ID, создавая ее при первой датаграмме
+func (s *udpStreamServer) session(id uint32) (*udpStreamServerSession, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if session, ok := s.sessions[id]; ok {
+		return session, nil
+	}
+
+	outbound, err := newDirectPacketOutbound()
+	if err != nil {
+		return nil, err
+	}
+	session := &udpStreamServerSession{outbound: outbound, lastActivity: time.Now()}
+	s.sessions[id] = session
+	go s.forwardReplies(id, session)
+	return session, nil
+}
+
+// forwardReplies пишет ответы назначений в поток с ID сессии
+func (s *udpStreamServer) forwardReplies(id uint32, session *udpStreamServerSession) {
+	buffer := make([]byte, maxUDPPacketSize)
+	for {
+		n, source, err := session.outbound.ReadPacket(buffer)
+		if err != nil {
+			return
+		}
+		session.touch()
+
+		s.writeMutex.Lock()
+		err = s.stream.SetWriteDeadline(time.Now().Add(30 * time.Second))
+		if err == nil {
+			err = writeUDPStreamFrame(s.stream, id, source, buffer[:n])
+		}
+		s.writeMutex.Unlock()
+		if err != nil {
+			s.stream.Close()
+			return
+		}
+		s.options.OnTraffic(0, int64(n))
+	}
+}
+
+// expireIdle закрывает сессии без трафика дольше таймаута
+func (s *udpStreamServer) expireIdle(ctx context.Context) {
+	ticker := time.NewTicker(s.options.IdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mutex.Lock()
+			for id, session := range s.sessions {
+				if session.idleFor() >= s.options.IdleTimeout {
+					session.outbound.Close()
+					delete(s.sessions, id)
+				}
+			}
+			s.mutex.Unlock()
+		}
+	}
+}
+
+func (s *udpStreamServer) closeAll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, session := range s.sessions {
+		session.outbound.Close()
+		delete(s.sessions, id)
+	}
+}
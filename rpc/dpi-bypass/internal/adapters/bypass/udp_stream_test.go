@@ -0,0 +1,201 @@
+package bypass
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startUDPWhoAmIServer отвечает на датаграмму адресом ее отправителя
+func startUDPWhoAmIServer(t *testing.T) int {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, maxUDPPacketSize)
+		for {
+			_, addr, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteToUDP([]byte(addr.String()), addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// startUDPStreamServer запускает серверную сторону UDP-over-stream и
+// возвращает ее адрес
+func startUDPStreamServer(t *testing.T, idleTimeout time.Duration, rules ...*domain.BypassRule) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveUDPOverStream(ctx, conn, udpStreamServerOptions{
+				Rules:       newRuleMatcher(rules),
+				IdleTimeout: idleTimeout,
+				Logger:      zap.NewNop(),
+				OnTraffic:   func(rx, tx int64) {},
+			})
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// startUDPStreamPair соединяет клиентский mux с серверной стороной потока
+func startUDPStreamPair(t *testing.T, idleTimeout time.Duration, rules ...*domain.BypassRule) *udpStreamMux {
+	t.Helper()
+
+	stream, err := net.Dial("tcp", startUDPStreamServer(t, idleTimeout, rules...))
+	require.NoError(t, err)
+	mux := newUDPStreamMux(stream)
+	t.Cleanup(func() { mux.Close() })
+	return mux
+}
+
+// exchange отправляет датаграмму через сессию и ждет ответ
+func exchange(t *testing.T, session packetOutbound, destination proxyDestination, payload string) (string, proxyDestination) {
+	t.Helper()
+
+	require.NoError(t, session.WritePacket([]byte(payload), destination))
+
+	type result struct {
+		payload string
+		source  proxyDestination
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		buffer := make([]byte, maxUDPPacketSize)
+		n, source, err := session.ReadPacket(buffer)
+		done <- result{string(buffer[:n]), source, err}
+	}()
+
+	select {
+	case r := <-done:
+		require.NoError(t, r.err)
+		return r.payload, r.source
+	case <-time.After(5 * time.Second):
+		t.Fatal("no udp reply")
+		return "", proxyDestination{}
+	}
+}
+
+func TestUDPStreamFrame_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	destination := proxyDestination{Host: "example.com", Port: 53}
+	require.NoError(t, writeUDPStreamFrame(&buf, 7, destination, []byte("query")))
+	require.NoError(t, writeUDPStreamFrame(&buf, 8, localDestination(51820), nil))
+
+	buffer := make([]byte, udpStreamMaxFrameSize)
+	sessionID, address, payload, err := readUDPStreamFrame(&buf, buffer)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), sessionID)
+	assert.Equal(t, destination, address)
+	assert.Equal(t, "query", string(payload))
+
+	sessionID, address, payload, err = readUDPStreamFrame(&buf, buffer)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(8), sessionID)
+	assert.Equal(t, localDestination(51820), address)
+	assert.Empty(t, payload)
+
+	assert.Error(t, writeUDPStreamFrame(&buf, 1, destination, make([]byte, udpStreamMaxFrameSize)))
+
+	// Длина меньше заголовка и обрыв посреди кадра
+	_, _, _, err = readUDPStreamFrame(bytes.NewReader([]byte{0, 2, 0, 0}), buffer)
+	assert.Error(t, err)
+	_, _, _, err = readUDPStreamFrame(bytes.NewReader([]byte{0, 20, 0, 0, 0, 1}), buffer)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestUDPOverStream_SessionsAndIdleTimeout(t *testing.T) {
+	serverPort := startUDPWhoAmIServer(t)
+	mux := startUDPStreamPair(t, 300*time.Millisecond)
+	destination := localDestination(serverPort)
+
+	first, err := mux.Session()
+	require.NoError(t, err)
+	second, err := mux.Session()
+	require.NoError(t, err)
+
+	// У каждой сессии свой сокет на сервере
+	firstAddr, source := exchange(t, first, destination, "a")
+	assert.Equal(t, destination, source)
+	secondAddr, _ := exchange(t, second, destination, "b")
+	assert.NotEqual(t, firstAddr, secondAddr)
+
+	again, _ := exchange(t, first, destination, "c")
+	assert.Equal(t, firstAddr, again)
+
+	// После простоя сервер закрывает сокет сессии и открывает новый
+	time.Sleep(time.Second)
+	expired, _ := exchange(t, first, destination, "d")
+	assert.NotEqual(t, firstAddr, expired)
+}
+
+func TestUDPOverStream_BlockRule(t *testing.T) {
+	serverPort := startUDPWhoAmIServer(t)
+	blockedPort := startUDPWhoAmIServer(t)
+	mux := startUDPStreamPair(t, time.Second, &domain.BypassRule{
+		ID: "block", Type: domain.RuleTypePort, Action: domain.RuleActionBlock, Pattern: strconv.Itoa(blockedPort), Enabled: true,
+	})
+
+	session, err := mux.Session()
+	require.NoError(t, err)
+	require.NoError(t, session.WritePacket([]byte("dropped"), localDestination(blockedPort)))
+
+	_, source := exchange(t, session, localDestination(serverPort), "allowed")
+	assert.Equal(t, localDestination(serverPort), source)
+}
+
+func TestUDPStreamPool_Redial(t *testing.T) {
+	serverPort := startUDPWhoAmIServer(t)
+	addr := startUDPStreamServer(t, time.Second)
+
+	dials := 0
+	pool := newUDPStreamPool(func() (net.Conn, error) {
+		dials++
+		return net.Dial("tcp", addr)
+	})
+	t.Cleanup(func() { pool.Close() })
+
+	session, err := pool.Session()
+	require.NoError(t, err)
+	exchange(t, session, localDestination(serverPort), "first")
+
+	// Обрыв потока закрывает сессии, следующая сессия открывает новый поток
+	require.NoError(t, pool.mux.stream.Close())
+	require.Eventually(t, pool.mux.Closed, time.Second, 10*time.Millisecond)
+	_, _, err = session.ReadPacket(make([]byte, 16))
+	assert.ErrorIs(t, err, ErrUDPStreamClosed)
+
+	session, err = pool.Session()
+	require.NoError(t, err)
+	exchange(t, session, localDestination(serverPort), "second")
+	assert.Equal(t, 2, dials)
+}
@@ -0,0 +1,204 @@
+package bypass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+)
+
+// ErrUnknownUser клиент предъявил ключ, которого нет среди действующих пользователей
+var ErrUnknownUser = errors.New("unknown bypass user")
+
+// userAccount учетная запись пользователя сервера. Счетчики сохраняются
+// при перезагрузке пользователей, пока сервер запущен.
+type userAccount struct {
+	id            string
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+	connections   atomic.Int64
+	active        atomic.Int64
+	lastActivity  atomic.Int64
+}
+
+// connected учитывает новое соединение пользователя
+func (a *userAccount) connected() {
+	a.connections.Add(1)
+	a.active.Add(1)
+	a.lastActivity.Store(time.Now().UnixNano())
+}
+
+// disconnected учитывает закрытие соединения пользователя
+func (a *userAccount) disconnected() {
+	a.active.Add(-1)
+}
+
+// addTraffic учитывает трафик: rx от пользователя, tx к пользователю
+func (a *userAccount) addTraffic(rx, tx int64) {
+	a.bytesReceived.Add(rx)
+	a.bytesSent.Add(tx)
+	a.lastActivity.Store(time.Now().UnixNano())
+}
+
+// userEntry действующий пользователь: ключ протокола, исходный ключ
+// пользователя и контекст, который отменяется при отзыве и разрывает
+// соединения пользователя
+type userEntry struct {
+	account    *userAccount
+	key        []byte
+	credential string
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// userRegistry пользователи сервера с заменой набора без перезапуска
+// listener
+type userRegistry struct {
+	// parse преобразует ключ пользователя в ключ поиска протокола
+	parse    func(credential string) ([]byte, error)
+	accounts map[string]*userAccount
+	entries  map[string]*userEntry // по ID пользователя
+	byKey    map[string]*userEntry
+	mutex    sync.RWMutex
+}
+
+func newUserRegistry(parse func(credential string) ([]byte, error)) *userRegistry {
+	return &userRegistry{
+		parse:    parse,
+		accounts: make(map[string]*userAccount),
+		entries:  make(map[string]*userEntry),
+		byKey:    make(map[string]*userEntry),
+	}
+}
+
+// Replace заменяет действующих пользователей. Соединения отозванных
+// пользователей и пользователей со смененным ключом разрываются. При
+// ошибке в ключе набор не меняется.
+func (r *userRegistry) Replace(users []*domain.BypassUser) error {
+	keys := make(map[string][]byte, len(users))
+	credentials := make(map[string]string, len(users))
+	for _, user := range users {
+		if user.Revoked {
+			continue
+		}
+		key, err := r.parse(user.Credential)
+		if err != nil {
+			return fmt.Errorf("invalid credential of user %s: %w", user.ID, err)
+		}
+		keys[user.ID] = key
+		credentials[user.ID] = user.Credential
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entries := make(map[string]*userEntry, len(keys))
+	byKey := make(map[string]*userEntry, len(keys))
+	for id, key := range keys {
+		entry, ok := r.entries[id]
+		if !ok || string(entry.key) != string(key) {
+			account, exists := r.accounts[id]
+			if !exists {
+				account = &userAccount{id: id}
+				r.accounts[id] = account
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			entry = &userEntry{account: account, key: key, credential: credentials[id], ctx: ctx, cancel: cancel}
+		}
+		entries[id] = entry
+		byKey[string(key)] = entry
+	}
+	for id, entry := range r.entries {
+		if entries[id] != entry {
+			entry.cancel()
+		}
+	}
+
+	r.entries = entries
+	r.byKey = byKey
+	return nil
+}
+
+// Lookup находит пользователя по ключу поиска. Возвращенный контекст
+// отменяется при отзыве пользователя.
+func (r *userRegistry) Lookup(key []byte) (*userAccount, context.Context, error) {
+	account, _, ctx, err := r.LookupCredential(key)
+	return account, ctx, err
+}
+
+// LookupCredential находит пользователя по ключу поиска и возвращает также
+// его исходный ключ, когда ключ поиска - только хеш (SIP022)
+func (r *userRegistry) LookupCredential(key []byte) (*userAccount, string, context.Context, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entry, ok := r.byKey[string(key)]
+	if !ok {
+		return nil, "", nil, ErrUnknownUser
+	}
+	return entry.account, entry.credential, entry.ctx, nil
+}
+
+// Find находит пользователя, ключ которого подходит match. Используется,
+// когда ключ нельзя получить из рукопожатия напрямую.
+func (r *userRegistry) Find(match func(key []byte) bool) (*userAccount, context.Context, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, entry := range r.entries {
+		if match(entry.key) {
+			return entry.account, entry.ctx, nil
+		}
+	}
+	return nil, nil, ErrUnknownUser
+}
+
+// Len возвращает число действующих пользователей
+func (r *userRegistry) Len() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.entries)
+}
+
+// Stats возвращает статистику всех пользователей, обслуженных сервером,
+// включая отозванных
+func (r *userRegistry) Stats() []*domain.UserStats {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stats := make([]*domain.UserStats, 0, len(r.accounts))
+	for id, account := range r.accounts {
+		var lastActivity time.Time
+		if nanos := account.lastActivity.Load(); nanos != 0 {
+			lastActivity = time.Unix(0, nanos)
+		}
+		_, active := r.entries[id]
+		stats = append(stats, &domain.UserStats{
+			UserID:            id,
+			BytesSent:         account.bytesSent.Load(),
+			BytesReceived:     account.bytesReceived.Load(),
+			Connections:       account.connections.Load(),
+			ActiveConnections: account.active.Load(),
+			LastActivity:      lastActivity,
+			Revoked:           !active,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].UserID < stats[j].UserID })
+	return stats
+}
+
+// Close разрывает соединения всех пользователей
+func (r *userRegistry) Close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, entry := range r.entries {
+		entry.cancel()
+	}
+}
@@ -0,0 +1,107 @@
+package bypass
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func plainKey(credential string) ([]byte, error) {
+	if credential == "" {
+		return nil, fmt.Errorf("empty credential")
+	}
+	return []byte(credential), nil
+}
+
+func TestUserRegistry_Replace(t *testing.T) {
+	registry := newUserRegistry(plainKey)
+	require.NoError(t, registry.Replace([]*domain.BypassUser{
+		{ID: "alice", Credential: "a1"},
+		{ID: "bob", Credential: "b1"},
+		{ID: "carol", Credential: "c1", Revoked: true},
+	}))
+	assert.Equal(t, 2, registry.Len())
+
+	alice, aliceRevoked, err := registry.Lookup([]byte("a1"))
+	require.NoError(t, err)
+	alice.connected()
+	alice.addTraffic(10, 20)
+	_, bobRevoked, err := registry.Lookup([]byte("b1"))
+	require.NoError(t, err)
+	_, _, err = registry.Lookup([]byte("c1"))
+	assert.ErrorIs(t, err, ErrUnknownUser)
+
+	// Ошибка в ключе не меняет набор
+	err = registry.Replace([]*domain.BypassUser{{ID: "alice", Credential: ""}})
+	assert.Error(t, err)
+	assert.Equal(t, 2, registry.Len())
+	assert.NoError(t, aliceRevoked.Err())
+
+	// Смена ключа bob и отзыв alice разрывают их соединения, счетчики сохраняются
+	require.NoError(t, registry.Replace([]*domain.BypassUser{
+		{ID: "alice", Credential: "a1", Revoked: true},
+		{ID: "bob", Credential: "b2"},
+	}))
+	assert.Error(t, aliceRevoked.Err())
+	assert.Error(t, bobRevoked.Err())
+	_, _, err = registry.Lookup([]byte("a1"))
+	assert.ErrorIs(t, err, ErrUnknownUser)
+	_, _, err = registry.Lookup([]byte("b1"))
+	assert.ErrorIs(t, err, ErrUnknownUser)
+
+	account, found, err := registry.Find(func(key []byte) bool { return bytes.Equal(key, []byte("b2")) })
+	require.NoError(t, err)
+	assert.Equal(t, "bob", account.id)
+	assert.NoError(t, found.Err())
+
+	// Ключ без изменений сохраняет соединения
+	require.NoError(t, registry.Replace([]*domain.BypassUser{{ID: "bob", Credential: "b2"}}))
+	assert.NoError(t, found.Err())
+
+	stats := registry.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "alice", stats[0].UserID)
+	assert.True(t, stats[0].Revoked)
+	assert.Equal(t, int64(10), stats[0].BytesReceived)
+	assert.Equal(t, int64(20), stats[0].BytesSent)
+	assert.Equal(t, int64(1), stats[0].ActiveConnections)
+	assert.Equal(t, "bob", stats[1].UserID)
+	assert.False(t, stats[1].Revoked)
+	assert.True(t, stats[1].LastActivity.IsZero())
+
+	registry.Close()
+	assert.Error(t, found.Err())
+}
+
+func TestShadowsocks2022Identity(t *testing.T) {
+	serverPSK := bytes.Repeat([]byte{1}, 32)
+	userPSK := bytes.Repeat([]byte{2}, 32)
+
+	salt := bytes.Repeat([]byte{4}, 32)
+
+	header, err := appendShadowsocks2022Identity(nil, serverPSK, userPSK, salt)
+	require.NoError(t, err)
+	require.Len(t, header, shadowsocks2022IdentitySize)
+
+	userHash, err := readShadowsocks2022Identity(bytes.NewReader(header), serverPSK, salt)
+	require.NoError(t, err)
+	assert.Equal(t, shadowsocks2022UserHash(userPSK), userHash)
+
+	// Заголовок зависит от соли сессии
+	other, err := appendShadowsocks2022Identity(nil, serverPSK, userPSK, bytes.Repeat([]byte{5}, 32))
+	require.NoError(t, err)
+	assert.NotEqual(t, header, other)
+
+	// Чужой PSK сервера дает другой хеш
+	userHash, err = readShadowsocks2022Identity(bytes.NewReader(header), bytes.Repeat([]byte{3}, 32), salt)
+	require.NoError(t, err)
+	assert.NotEqual(t, shadowsocks2022UserHash(userPSK), userHash)
+
+	_, err = parseShadowsocks2022Key(base64.StdEncoding.EncodeToString(userPSK), 16)
+	assert.Error(t, err)
+}
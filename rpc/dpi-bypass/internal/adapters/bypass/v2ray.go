@@ -3,9 +3,11 @@ package bypass
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
@@ -17,15 +19,33 @@ type V2RayAdapter struct {
 	running map[string]*v2rayConnection
 	mutex   sync.RWMutex
 	logger  *zap.Logger
+	signals *SignalMonitor
 }
 
 type v2rayConnection struct {
-	config     *domain.BypassConfig
+	// config заменяется при перезагрузке без перезапуска
+	config     atomic.Pointer[domain.BypassConfig]
 	listener   net.Listener
 	ctx        context.Context
 	cancel     context.CancelFunc
 	stats      *domain.BypassStats
 	statsMutex sync.RWMutex
+	// rules правила, заменяемые при перезагрузке
+	rules *ruleMatcher
+	// tracker учитывает клиентские соединения для плавной остановки
+	tracker *connectionTracker
+	// inbound входящий SOCKS5/HTTP прокси; nil в режиме raw
+	inbound *inboundFrontend
+	// vlessID UUID пользователя; если задан, назначение передается запросом VLESS
+	vlessID *[16]byte
+	// UDP: режим, поток датаграмм к серверу и listener на local_port
+	udpMode  udpMode
+	udpPool  *udpStreamPool
+	udpRelay *udpRelay
+	// server серверная роль: пользователи VLESS по UUID; nil в роли клиента
+	server *userServer
+	// shaping профиль формы трафика к удаленной стороне; nil без профиля
+	shaping *shapingConfig
 }
 
 // NewV2RayAdapter создает новый V2Ray адаптер
@@ -36,6 +56,11 @@ func NewV2RayAdapter(logger *zap.Logger) *V2RayAdapter {
 	}
 }
 
+// observeSignals подключает монитор сигналов цензуры к исходящим соединениям
+func (v *V2RayAdapter) observeSignals(monitor *SignalMonitor) {
+	v.signals = monitor
+}
+
 // Start запускает V2Ray сервер
 func (v *V2RayAdapter) Start(config *domain.BypassConfig) error {
 	v.mutex.Lock()
@@ -45,6 +70,45 @@ func (v *V2RayAdapter) Start(config *domain.BypassConfig) error {
 		return fmt.Errorf("v2ray connection already running: %s", config.ID)
 	}
 
+	inbound, err := parseInboundConfig(config.Parameters)
+	if err != nil {
+		return err
+	}
+	udp, err := parseUDPConfig(config.Parameters)
+	if err != nil {
+		return err
+	}
+	shaping, err := parseShaping(config.Parameters, "")
+	if err != nil {
+		return err
+	}
+	var vlessID *[16]byte
+	if value := config.Parameters["uuid"]; value != "" {
+		id, err := parseVLESSID(value)
+		if err != nil {
+			return err
+		}
+		vlessID = &id
+	}
+
+	rules := newRuleMatcher(config.Rules)
+	server, err := newUserServer(config, rules, func(credential string) ([]byte, error) {
+		id, err := parseVLESSID(credential)
+		if err != nil {
+			return nil, err
+		}
+		return id[:], nil
+	}, v.logger)
+	if err != nil {
+		return err
+	}
+	if server != nil && vlessID != nil {
+		return fmt.Errorf("uuid is not supported in server role")
+	}
+	if server == nil && udp.Mode == udpModeNative && vlessID == nil {
+		return fmt.Errorf("udp_mode native requires uuid")
+	}
+
 	// Создаем контекст для управления жизненным циклом
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -68,27 +132,27 @@ func (v *V2RayAdapter) Start(config *domain.BypassConfig) error {
 
 	// Создаем listener с TLS для WebSocket
 	var listener net.Listener
-	var err error
 
 	if encryption == "tls" {
-		// Создаем самоподписанный сертификат для тестирования
-		cert, err := generateSelfSignedCert()
+		// Сертификат из tls_cert_file и tls_key_file; без них
+		// самоподписанный, который клиенты примут только без проверки
+		cert, err := loadServerCertificate(config.Parameters)
 		if err != nil {
 			cancel()
-			return fmt.Errorf("failed to generate certificate: %w", err)
+			return err
 		}
 
 		tlsConfig := &tls.Config{
 			Certificates: []tls.Certificate{cert},
 		}
 
-		listener, err = tls.Listen("tcp", fmt.Sprintf(":%s", localPort), tlsConfig)
+		listener, err = tls.Listen("tcp", inbound.Address(localPort), tlsConfig)
 		if err != nil {
 			cancel()
 			return fmt.Errorf("failed to create TLS listener: %w", err)
 		}
 	} else {
-		listener, err = net.Listen("tcp", fmt.Sprintf(":%s", localPort))
+		listener, err = inbound.Listen(localPort)
 		if err != nil {
 			cancel()
 			return fmt.Errorf("failed to create listener: %w", err)
@@ -96,10 +160,15 @@ func (v *V2RayAdapter) Start(config *domain.BypassConfig) error {
 	}
 
 	conn := &v2rayConnection{
-		config:   config,
 		listener: listener,
 		ctx:      ctx,
 		cancel:   cancel,
+		rules:    rules,
+		tracker:  newConnectionTracker(),
+		vlessID:  vlessID,
+		udpMode:  udp.Mode,
+		server:   server,
+		shaping:  shaping,
 		stats: &domain.BypassStats{
 			ID:                     config.ID,
 			ConfigID:               config.ID,
@@ -111,6 +180,35 @@ func (v *V2RayAdapter) Start(config *domain.BypassConfig) error {
 			EndTime:                time.Now(),
 		},
 	}
+	conn.config.Store(config)
+	conn.udpPool = newUDPStreamPool(func() (net.Conn, error) {
+		return v.dialTunnel(conn, udpOverStreamDestination())
+	})
+	packets := v.packetOutbound(conn)
+
+	if server != nil {
+		server.onTraffic = func(rx, tx int64) { v.updateStats(conn, rx, tx) }
+		server.onError = func() { v.incrementErrorCount(conn) }
+	}
+
+	conn.inbound = newInboundFrontend(inbound, conn.rules, config.ID, v.logger)
+	if conn.inbound != nil {
+		conn.inbound.packets = packets
+		conn.inbound.onTraffic = func(rx, tx int64) { v.updateStats(conn, rx, tx) }
+		conn.inbound.onError = func() { v.incrementErrorCount(conn) }
+	}
+
+	conn.udpRelay, err = newUDPRelay(udp, listener, conn.rules, packets, config.ID, v.logger)
+	if err != nil {
+		listener.Close()
+		cancel()
+		return err
+	}
+	if conn.udpRelay != nil {
+		conn.udpRelay.onTraffic = func(rx, tx int64) { v.updateStats(conn, rx, tx) }
+		conn.udpRelay.onError = func() { v.incrementErrorCount(conn) }
+		conn.udpRelay.start()
+	}
 
 	v.running[config.ID] = conn
 
@@ -127,25 +225,14 @@ func (v *V2RayAdapter) Start(config *domain.BypassConfig) error {
 	return nil
 }
 
-// Stop останавливает V2Ray сервер
+// Stop останавливает V2Ray сервер: закрывает listener, прерывает
+// соединения и ждет завершения их горутин
 func (v *V2RayAdapter) Stop(id string) error {
-	v.mutex.Lock()
-	defer v.mutex.Unlock()
-
-	conn, exists := v.running[id]
-	if !exists {
-		return fmt.Errorf("v2ray connection not found: %s", id)
-	}
-
-	// Отменяем контекст
-	conn.cancel()
-
-	// Закрываем listener
-	if err := conn.listener.Close(); err != nil {
-		v.logger.Error("failed to close listener", zap.Error(err), zap.String("id", id))
+	detached, err := v.Detach(id)
+	if err != nil {
+		return err
 	}
-
-	delete(v.running, id)
+	detached.Drain(0)
 
 	v.logger.Info("v2ray server stopped", zap.String("id", id))
 	return nil
@@ -161,12 +248,20 @@ func (v *V2RayAdapter) GetStats(id string) (*domain.BypassStats, error) {
 		return nil, nil
 	}
 
+	return v.snapshotStats(conn), nil
+}
+
+// snapshotStats возвращает копию статистики соединения
+func (v *V2RayAdapter) snapshotStats(conn *v2rayConnection) *domain.BypassStats {
 	conn.statsMutex.RLock()
 	defer conn.statsMutex.RUnlock()
 
 	// Возвращаем копию статистики
 	stats := *conn.stats
-	return &stats, nil
+	if conn.server != nil {
+		stats.Users = conn.server.users.Stats()
+	}
+	return &stats
 }
 
 // IsRunning проверяет, запущен ли V2Ray сервер
@@ -178,8 +273,65 @@ func (v *V2RayAdapter) IsRunning(id string) bool {
 	return exists
 }
 
+// Reload применяет правила, адрес сервера и пользователей к запущенному
+// соединению. Остальные изменения требуют перезапуска.
+func (v *V2RayAdapter) Reload(config *domain.BypassConfig) error {
+	v.mutex.RLock()
+	conn, exists := v.running[config.ID]
+	v.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("v2ray connection not found: %s", config.ID)
+	}
+	if !hotSwappable(conn.config.Load(), config, "remote_host", "remote_port") {
+		return errRestartRequired
+	}
+	if conn.server != nil {
+		if err := conn.server.users.Replace(config.Users); err != nil {
+			return err
+		}
+	}
+
+	conn.rules.Replace(config.Rules)
+	conn.config.Store(config)
+
+	v.logger.Info("v2ray configuration reloaded", zap.String("id", config.ID))
+	return nil
+}
+
+// Detach убирает соединение из запущенных для плавной замены новым
+func (v *V2RayAdapter) Detach(id string) (*detachedConnection, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	conn, exists := v.running[id]
+	if !exists {
+		return nil, fmt.Errorf("v2ray connection not found: %s", id)
+	}
+	delete(v.running, id)
+
+	return &detachedConnection{
+		config: conn.config.Load(),
+		closeListeners: func() {
+			conn.listener.Close()
+			if conn.udpRelay != nil {
+				conn.udpRelay.Close()
+			}
+		},
+		wait: conn.tracker.wait,
+		stop: func() {
+			conn.cancel()
+			conn.tracker.abort()
+			conn.udpPool.Close()
+			conn.tracker.done()
+		},
+		stats: func() *domain.BypassStats { return v.snapshotStats(conn) },
+	}, nil
+}
+
 // handleConnections обрабатывает входящие соединения
 func (v *V2RayAdapter) handleConnections(conn *v2rayConnection) {
+	defer conn.tracker.acceptStopped()
+
 	for {
 		select {
 		case <-conn.ctx.Done():
@@ -187,17 +339,17 @@ func (v *V2RayAdapter) handleConnections(conn *v2rayConnection) {
 		default:
 			clientConn, err := conn.listener.Accept()
 			if err != nil {
-				if conn.ctx.Err() != nil {
-					// Контекст отменен, выходим
+				if conn.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+					// Контекст отменен или listener закрыт, выходим
 					return
 				}
-				v.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.ID))
+				v.logger.Error("failed to accept connection", zap.Error(err), zap.String("id", conn.config.Load().ID))
 				v.incrementErrorCount(conn)
 				continue
 			}
 
 			// Обрабатываем соединение в отдельной горутине
-			go v.handleClientConnection(conn, clientConn)
+			conn.tracker.handle(clientConn, func() { v.handleClientConnection(conn, clientConn) })
 		}
 	}
 }
@@ -209,60 +361,147 @@ func (v *V2RayAdapter) handleClientConnection(conn *v2rayConnection, clientConn
 	// Увеличиваем счетчик соединений
 	v.incrementConnections(conn)
 
-	// Получаем параметры удаленного сервера
-	remoteHost := conn.config.Parameters["remote_host"]
-	remotePort := conn.config.Parameters["remote_port"]
-	if remoteHost == "" {
-		remoteHost = "127.0.0.1"
+	if conn.server != nil {
+		v.serveUser(conn, shapeConn(clientConn, conn.shaping))
+		return
 	}
-	if remotePort == "" {
-		remotePort = "8080"
+
+	var remoteConn net.Conn
+	if conn.inbound != nil {
+		// Назначение передается серверу заголовком адреса в формате SOCKS5
+		var ok bool
+		clientConn, remoteConn, ok = conn.inbound.serve(conn.ctx, clientConn, func(destination proxyDestination) (net.Conn, error) {
+			return v.dialTunnel(conn, destination)
+		})
+		if !ok {
+			return
+		}
+	} else {
+		var err error
+		remoteConn, err = v.dialRemote(conn)
+		if err != nil {
+			v.logger.Error("failed to connect to remote server",
+				zap.Error(err),
+				zap.String("id", conn.config.Load().ID))
+			v.incrementErrorCount(conn)
+			return
+		}
+	}
+	defer remoteConn.Close()
+
+	// Обе горутины завершаются до выхода: соединения закрываются, как только
+	// останавливается одно из направлений
+	err := relayConns(conn.ctx, clientConn, remoteConn,
+		func() error {
+			// Копируем данные от клиента к серверу
+			bytes, err := v.copyData(clientConn, remoteConn, conn, true)
+			v.updateStats(conn, bytes, 0)
+			return err
+		},
+		func() error {
+			// Копируем данные от сервера к клиенту
+			bytes, err := v.copyData(remoteConn, clientConn, conn, false)
+			v.updateStats(conn, 0, bytes)
+			return err
+		})
+	if err != nil {
+		v.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.Load().ID))
+	}
+}
+
+// dialTunnel открывает поток к назначению через сервер: запросом VLESS,
+// если задан uuid, иначе заголовком адреса в формате SOCKS5
+func (v *V2RayAdapter) dialTunnel(conn *v2rayConnection, destination proxyDestination) (net.Conn, error) {
+	if conn.vlessID != nil {
+		return v.dialVLESS(conn, vlessCommandTCP, destination)
+	}
+
+	remote, err := v.dialRemote(conn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := remote.Write(appendSocksAddr(nil, destination)); err != nil {
+		remote.Close()
+		return nil, err
 	}
+	return remote, nil
+}
 
-	// Подключаемся к удаленному серверу
-	remoteConn, err := net.DialTimeout("tcp",
-		fmt.Sprintf("%s:%s", remoteHost, remotePort),
-		10*time.Second)
+// serveUser обслуживает запрос VLESS в роли сервера: пользователь
+// определяется по UUID запроса, команда UDP передает датаграммы
+func (v *V2RayAdapter) serveUser(conn *v2rayConnection, clientConn net.Conn) {
+	if err := clientConn.SetReadDeadline(time.Now().Add(defaultInboundHandshakeTimeout)); err != nil {
+		return
+	}
+	id, command, destination, err := readVLESSRequest(clientConn)
+	var account *userAccount
+	var revoked context.Context
+	if err == nil {
+		account, revoked, err = conn.server.users.Lookup(id[:])
+	}
+	network := "tcp"
+	if err == nil && command == vlessCommandUDP {
+		network = "udp"
+	} else if err == nil && command != vlessCommandTCP {
+		err = fmt.Errorf("unsupported vless command: %d", command)
+	}
+	if err == nil {
+		err = clientConn.SetReadDeadline(time.Time{})
+	}
+	if err == nil {
+		_, err = clientConn.Write([]byte{vlessVersion, 0})
+	}
 	if err != nil {
-		v.logger.Error("failed to connect to remote server",
-			zap.Error(err),
-			zap.String("id", conn.config.ID),
-			zap.String("remote", remoteHost))
+		v.logger.Debug("failed to authenticate vless user", zap.Error(err), zap.String("id", conn.config.Load().ID))
 		v.incrementErrorCount(conn)
 		return
 	}
-	defer remoteConn.Close()
 
-	// Создаем каналы для передачи данных
-	errChan := make(chan error, 2)
+	conn.server.serve(conn.ctx, revoked, account, clientConn, destination, network)
+}
 
-	// Копируем данные от клиента к серверу
-	go func() {
-		bytes, err := v.copyData(clientConn, remoteConn, conn, true)
-		if err != nil {
-			errChan <- err
-		}
-		v.updateStats(conn, bytes, 0)
-	}()
+// dialVLESS отправляет запрос VLESS с командой command
+func (v *V2RayAdapter) dialVLESS(conn *v2rayConnection, command byte, destination proxyDestination) (net.Conn, error) {
+	remote, err := v.dialRemote(conn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := remote.Write(appendVLESSRequest(nil, *conn.vlessID, command, destination)); err != nil {
+		remote.Close()
+		return nil, err
+	}
+	return &vlessConn{Conn: remote}, nil
+}
 
-	// Копируем данные от сервера к клиенту
-	go func() {
-		bytes, err := v.copyData(remoteConn, clientConn, conn, false)
-		if err != nil {
-			errChan <- err
+// packetOutbound возвращает фабрику UDP каналов: нативный UDP VLESS или
+// датаграммы внутри TCP потока
+func (v *V2RayAdapter) packetOutbound(conn *v2rayConnection) func() (packetOutbound, error) {
+	if conn.udpMode == udpModeNative {
+		return func() (packetOutbound, error) {
+			return newVLESSPacketOutbound(func(destination proxyDestination) (net.Conn, error) {
+				return v.dialVLESS(conn, vlessCommandUDP, destination)
+			}), nil
 		}
-		v.updateStats(conn, 0, bytes)
-	}()
+	}
+	return conn.udpPool.Session
+}
 
-	// Ждем завершения или ошибки
-	select {
-	case <-conn.ctx.Done():
-		return
-	case err := <-errChan:
-		if err != nil {
-			v.logger.Debug("connection error", zap.Error(err), zap.String("id", conn.config.ID))
-		}
+// dialRemote подключается к удаленному серверу
+func (v *V2RayAdapter) dialRemote(conn *v2rayConnection) (net.Conn, error) {
+	remoteHost := conn.config.Load().Parameters["remote_host"]
+	remotePort := conn.config.Load().Parameters["remote_port"]
+	if remoteHost == "" {
+		remoteHost = "127.0.0.1"
+	}
+	if remotePort == "" {
+		remotePort = "8080"
+	}
+
+	remote, err := v.signals.Dial(context.Background(), conn.config.Load(), net.JoinHostPort(remoteHost, remotePort), 10*time.Second)
+	if err != nil {
+		return nil, err
 	}
+	return shapeConn(remote, conn.shaping), nil
 }
 
 // copyData копирует данные между соединениями
@@ -271,34 +510,24 @@ func (v *V2RayAdapter) copyData(src, dst net.Conn, conn *v2rayConnection, isTx b
 	var totalBytes int64
 
 	for {
-		select {
-		case <-conn.ctx.Done():
-			return totalBytes, nil
-		default:
-			// Устанавливаем таймаут для чтения
-			if err := src.SetReadDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		n, err := src.Read(buffer)
+		if err != nil {
+			return totalBytes, err
+		}
+
+		if n > 0 {
+			// Устанавливаем таймаут для записи
+			if err := dst.SetWriteDeadline(time.Now().Add(relayWriteTimeout)); err != nil {
 				return totalBytes, err
 			}
 
-			n, err := src.Read(buffer)
+			_, err = dst.Write(buffer[:n])
 			if err != nil {
 				return totalBytes, err
 			}
 
-			if n > 0 {
-				// Устанавливаем таймаут для записи
-				if err := dst.SetWriteDeadline(time.Now().Add(30 * time.Second)); err != nil {
-					return totalBytes, err
-				}
-
-				_, err = dst.Write(buffer[:n])
-				if err != nil {
-					return totalBytes, err
-				}
-
-				totalBytes += int64(n)
-				v.updateLastActivity(conn)
-			}
+			totalBytes += int64(n)
+			v.updateLastActivity(conn)
 		}
 	}
 }
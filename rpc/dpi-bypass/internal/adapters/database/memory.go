@@ -0,0 +1,528 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+)
+
+// MemoryRepository репозиторий в памяти процесса. Используется, когда база
+// данных не настроена; данные теряются при перезапуске.
+type MemoryRepository struct {
+	configs map[string]*domain.BypassConfig
+	rules   map[string]*domain.BypassRule
+	users   map[string]*domain.BypassUser // по config_id/user_id
+	tokens  map[string]*domain.SubscriptionToken
+	access  []*domain.SubscriptionAccess
+	history []*domain.BypassHistoryEntry
+	mutex   sync.RWMutex
+}
+
+// NewMemoryRepository создает новый репозиторий в памяти
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		configs: make(map[string]*domain.BypassConfig),
+		rules:   make(map[string]*domain.BypassRule),
+		users:   make(map[string]*domain.BypassUser),
+		tokens:  make(map[string]*domain.SubscriptionToken),
+	}
+}
+
+// CreateConfig создает новую конфигурацию обхода
+func (r *MemoryRepository) CreateConfig(ctx context.Context, config *domain.BypassConfig) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	config.ID = uuid.New().String()
+	config.CreatedAt = time.Now()
+	config.UpdatedAt = config.CreatedAt
+
+	r.configs[config.ID] = copyConfig(config)
+	return nil
+}
+
+// GetConfig получает конфигурацию обхода по ID
+func (r *MemoryRepository) GetConfig(ctx context.Context, id string) (*domain.BypassConfig, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	config, exists := r.configs[id]
+	if !exists {
+		return nil, fmt.Errorf("bypass configuration not found: %s", id)
+	}
+	return copyConfig(config), nil
+}
+
+// ListConfigs получает список конфигураций обхода с фильтрами
+func (r *MemoryRepository) ListConfigs(ctx context.Context, filters *domain.BypassConfigFilters) ([]*domain.BypassConfig, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	configs := []*domain.BypassConfig{}
+	for _, config := range r.configs {
+		if filters != nil && filters.Type != "" && config.Type != filters.Type {
+			continue
+		}
+		if filters != nil && filters.Status != "" && config.Status != filters.Status {
+			continue
+		}
+		configs = append(configs, copyConfig(config))
+	}
+
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].CreatedAt.After(configs[j].CreatedAt)
+	})
+
+	total := len(configs)
+	if filters != nil {
+		configs = page(configs, filters.Limit, filters.Offset)
+	}
+	return configs, total, nil
+}
+
+// UpdateConfig обновляет конфигурацию обхода, кроме статуса
+func (r *MemoryRepository) UpdateConfig(ctx context.Context, config *domain.BypassConfig) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.configs[config.ID]
+	if !exists {
+		return fmt.Errorf("bypass configuration not found: %s", config.ID)
+	}
+
+	// Статус меняет только UpdateConfigStatus
+	config.Status = stored.Status
+	config.UpdatedAt = time.Now()
+	r.configs[config.ID] = copyConfig(config)
+	return nil
+}
+
+// UpdateConfigStatus сохраняет только статус конфигурации
+func (r *MemoryRepository) UpdateConfigStatus(ctx context.Context, id string, status domain.BypassStatus) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	config, exists := r.configs[id]
+	if !exists {
+		return fmt.Errorf("bypass configuration not found: %s", id)
+	}
+
+	config.Status = status
+	config.UpdatedAt = time.Now()
+	return nil
+}
+
+// DeleteConfig удаляет конфигурацию обхода вместе с ее правилами и
+// пользователями
+func (r *MemoryRepository) DeleteConfig(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.configs[id]; !exists {
+		return fmt.Errorf("bypass configuration not found: %s", id)
+	}
+
+	delete(r.configs, id)
+	for ruleID, rule := range r.rules {
+		if rule.ConfigID == id {
+			delete(r.rules, ruleID)
+		}
+	}
+	for key, user := range r.users {
+		if user.ConfigID == id {
+			delete(r.users, key)
+		}
+	}
+	return nil
+}
+
+// CreateRule создает новое правило обхода
+func (r *MemoryRepository) CreateRule(ctx context.Context, rule *domain.BypassRule) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.configs[rule.ConfigID]; !exists {
+		return fmt.Errorf("bypass configuration not found: %s", rule.ConfigID)
+	}
+
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+
+	r.rules[rule.ID] = copyRule(rule)
+	return nil
+}
+
+// GetRule получает правило обхода по ID
+func (r *MemoryRepository) GetRule(ctx context.Context, id string) (*domain.BypassRule, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	rule, exists := r.rules[id]
+	if !exists {
+		return nil, fmt.Errorf("bypass rule not found: %s", id)
+	}
+	return copyRule(rule), nil
+}
+
+// ListRules получает список правил обхода с фильтрами, по убыванию приоритета
+func (r *MemoryRepository) ListRules(ctx context.Context, filters *domain.BypassRuleFilters) ([]*domain.BypassRule, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	rules := []*domain.BypassRule{}
+	for _, rule := range r.rules {
+		if filters != nil {
+			if filters.ConfigID != "" && rule.ConfigID != filters.ConfigID {
+				continue
+			}
+			if filters.Type != "" && rule.Type != filters.Type {
+				continue
+			}
+			if filters.Enabled && !rule.Enabled {
+				continue
+			}
+		}
+		rules = append(rules, copyRule(rule))
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority > rules[j].Priority
+		}
+		return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+	})
+
+	total := len(rules)
+	if filters != nil {
+		rules = page(rules, filters.Limit, filters.Offset)
+	}
+	return rules, total, nil
+}
+
+// UpdateRule обновляет правило обхода
+func (r *MemoryRepository) UpdateRule(ctx context.Context, rule *domain.BypassRule) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.rules[rule.ID]; !exists {
+		return fmt.Errorf("bypass rule not found: %s", rule.ID)
+	}
+
+	rule.UpdatedAt = time.Now()
+	r.rules[rule.ID] = copyRule(rule)
+	return nil
+}
+
+// DeleteRule удаляет правило обхода
+func (r *MemoryRepository) DeleteRule(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.rules[id]; !exists {
+		return fmt.Errorf("bypass rule not found: %s", id)
+	}
+	delete(r.rules, id)
+	return nil
+}
+
+// CreateUser добавляет пользователя сервера обхода
+func (r *MemoryRepository) CreateUser(ctx context.Context, user *domain.BypassUser) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.configs[user.ConfigID]; !exists {
+		return fmt.Errorf("bypass configuration not found: %s", user.ConfigID)
+	}
+	key := userKey(user.ConfigID, user.ID)
+	if _, exists := r.users[key]; exists {
+		return fmt.Errorf("bypass user already exists: %s", user.ID)
+	}
+
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	stored := *user
+	r.users[key] = &stored
+	return nil
+}
+
+// GetUser получает пользователя сервера обхода
+func (r *MemoryRepository) GetUser(ctx context.Context, configID, userID string) (*domain.BypassUser, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	user, exists := r.users[userKey(configID, userID)]
+	if !exists {
+		return nil, fmt.Errorf("bypass user not found: %s", userID)
+	}
+	stored := *user
+	return &stored, nil
+}
+
+// ListUsers получает список пользователей сервера обхода по дате добавления
+func (r *MemoryRepository) ListUsers(ctx context.Context, filters *domain.BypassUserFilters) ([]*domain.BypassUser, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	users := []*domain.BypassUser{}
+	for _, user := range r.users {
+		if filters != nil {
+			if filters.ConfigID != "" && user.ConfigID != filters.ConfigID {
+				continue
+			}
+			if filters.UserID != "" && user.ID != filters.UserID {
+				continue
+			}
+			if !filters.IncludeRevoked && user.Revoked {
+				continue
+			}
+		}
+		stored := *user
+		users = append(users, &stored)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	total := len(users)
+	if filters != nil {
+		users = page(users, filters.Limit, filters.Offset)
+	}
+	return users, total, nil
+}
+
+// UpdateUser обновляет ключ и отзыв пользователя сервера обхода
+func (r *MemoryRepository) UpdateUser(ctx context.Context, user *domain.BypassUser) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := userKey(user.ConfigID, user.ID)
+	if _, exists := r.users[key]; !exists {
+		return fmt.Errorf("bypass user not found: %s", user.ID)
+	}
+
+	user.UpdatedAt = time.Now()
+	stored := *user
+	r.users[key] = &stored
+	return nil
+}
+
+// CreateSubscriptionToken сохраняет токен подписки
+func (r *MemoryRepository) CreateSubscriptionToken(ctx context.Context, token *domain.SubscriptionToken) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token.ID = uuid.New().String()
+	token.CreatedAt = time.Now()
+
+	stored := *token
+	stored.Token = ""
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+// GetSubscriptionToken получает токен подписки по ID
+func (r *MemoryRepository) GetSubscriptionToken(ctx context.Context, id string) (*domain.SubscriptionToken, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	token, exists := r.tokens[id]
+	if !exists {
+		return nil, fmt.Errorf("subscription token not found: %s", id)
+	}
+	stored := *token
+	return &stored, nil
+}
+
+// GetSubscriptionTokenByHash получает токен подписки по хешу значения из URL
+func (r *MemoryRepository) GetSubscriptionTokenByHash(ctx context.Context, tokenHash string) (*domain.SubscriptionToken, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			stored := *token
+			return &stored, nil
+		}
+	}
+	return nil, fmt.Errorf("subscription token not found")
+}
+
+// ListSubscriptionTokens получает список токенов подписки по дате выпуска
+func (r *MemoryRepository) ListSubscriptionTokens(ctx context.Context, filters *domain.SubscriptionTokenFilters) ([]*domain.SubscriptionToken, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tokens := []*domain.SubscriptionToken{}
+	for _, token := range r.tokens {
+		if filters != nil {
+			if filters.UserID != "" && token.UserID != filters.UserID {
+				continue
+			}
+			if !filters.IncludeRevoked && token.Revoked {
+				continue
+			}
+		}
+		stored := *token
+		tokens = append(tokens, &stored)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+
+	total := len(tokens)
+	if filters != nil {
+		tokens = page(tokens, filters.Limit, filters.Offset)
+	}
+	return tokens, total, nil
+}
+
+// UpdateSubscriptionToken обновляет отзыв токена подписки
+func (r *MemoryRepository) UpdateSubscriptionToken(ctx context.Context, token *domain.SubscriptionToken) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.tokens[token.ID]
+	if !exists {
+		return fmt.Errorf("subscription token not found: %s", token.ID)
+	}
+	stored.Revoked = token.Revoked
+	return nil
+}
+
+// CreateSubscriptionAccess записывает обращение к подписке и время
+// последнего обращения токена
+func (r *MemoryRepository) CreateSubscriptionAccess(ctx context.Context, access *domain.SubscriptionAccess) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token, exists := r.tokens[access.TokenID]
+	if !exists {
+		return fmt.Errorf("subscription token not found: %s", access.TokenID)
+	}
+	token.LastAccessedAt = access.AccessedAt
+
+	access.ID = uuid.New().String()
+	stored := *access
+	r.access = append(r.access, &stored)
+	return nil
+}
+
+// ListSubscriptionAccesses получает журнал обращений к подписке, новые первыми
+func (r *MemoryRepository) ListSubscriptionAccesses(ctx context.Context, filters *domain.SubscriptionAccessFilters) ([]*domain.SubscriptionAccess, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	accesses := []*domain.SubscriptionAccess{}
+	for i := len(r.access) - 1; i >= 0; i-- {
+		access := r.access[i]
+		if filters != nil {
+			if filters.TokenID != "" && access.TokenID != filters.TokenID {
+				continue
+			}
+			if filters.UserID != "" && access.UserID != filters.UserID {
+				continue
+			}
+		}
+		stored := *access
+		accesses = append(accesses, &stored)
+	}
+
+	total := len(accesses)
+	if filters != nil {
+		accesses = page(accesses, filters.Limit, filters.Offset)
+	}
+	return accesses, total, nil
+}
+
+// CreateHistoryEntry сохраняет запись о завершенной сессии
+func (r *MemoryRepository) CreateHistoryEntry(ctx context.Context, entry *domain.BypassHistoryEntry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry.ID = uuid.New().String()
+	stored := *entry
+	r.history = append(r.history, &stored)
+	return nil
+}
+
+// ListHistory получает историю сессий по конфигурации и диапазону времени начала
+func (r *MemoryRepository) ListHistory(ctx context.Context, req *domain.BypassHistoryRequest) ([]*domain.BypassHistoryEntry, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := []*domain.BypassHistoryEntry{}
+	for _, entry := range r.history {
+		if req.ConfigID != "" && entry.ConfigID != req.ConfigID {
+			continue
+		}
+		if !req.StartTime.IsZero() && entry.StartedAt.Before(req.StartTime) {
+			continue
+		}
+		if !req.EndTime.IsZero() && entry.StartedAt.After(req.EndTime) {
+			continue
+		}
+		stored := *entry
+		entries = append(entries, &stored)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedAt.After(entries[j].StartedAt)
+	})
+
+	total := len(entries)
+	return page(entries, req.Limit, req.Offset), total, nil
+}
+
+// page возвращает страницу списка
+func page[T any](items []T, limit, offset int) []T {
+	if offset > 0 {
+		if offset >= len(items) {
+			return items[:0]
+		}
+		items = items[offset:]
+	}
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func userKey(configID, userID string) string {
+	return configID + "/" + userID
+}
+
+func copyConfig(config *domain.BypassConfig) *domain.BypassConfig {
+	stored := *config
+	stored.Parameters = copyParameters(config.Parameters)
+	stored.Rules = []*domain.BypassRule{}
+	return &stored
+}
+
+func copyRule(rule *domain.BypassRule) *domain.BypassRule {
+	stored := *rule
+	stored.Parameters = copyParameters(rule.Parameters)
+	return &stored
+}
+
+func copyParameters(parameters map[string]string) map[string]string {
+	copied := make(map[string]string, len(parameters))
+	for key, value := range parameters {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,17 @@
+-- Создание таблицы конфигураций обхода
+CREATE TABLE IF NOT EXISTS bypass_configs (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    type VARCHAR(50) NOT NULL,
+    method VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'inactive',
+    parameters JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Индексы для оптимизации запросов
+CREATE INDEX IF NOT EXISTS idx_bypass_configs_type ON bypass_configs(type);
+CREATE INDEX IF NOT EXISTS idx_bypass_configs_status ON bypass_configs(status);
+CREATE INDEX IF NOT EXISTS idx_bypass_configs_created_at ON bypass_configs(created_at);
@@ -0,0 +1,21 @@
+-- Создание таблицы правил обхода
+CREATE TABLE IF NOT EXISTS bypass_rules (
+    id VARCHAR(36) PRIMARY KEY,
+    config_id VARCHAR(36) NOT NULL REFERENCES bypass_configs(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    pattern TEXT NOT NULL,
+    parameters JSONB NOT NULL DEFAULT '{}',
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Индексы для оптимизации запросов
+CREATE INDEX IF NOT EXISTS idx_bypass_rules_config_id ON bypass_rules(config_id);
+CREATE INDEX IF NOT EXISTS idx_bypass_rules_type ON bypass_rules(type);
+
+-- Композитный индекс для выборки правил конфигурации по приоритету
+CREATE INDEX IF NOT EXISTS idx_bypass_rules_config_priority ON bypass_rules(config_id, priority DESC);
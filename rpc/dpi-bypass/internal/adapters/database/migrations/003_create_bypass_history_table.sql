@@ -0,0 +1,22 @@
+-- Создание таблицы истории сессий обхода
+-- config_id без внешнего ключа: история сохраняется после удаления конфигурации
+CREATE TABLE IF NOT EXISTS bypass_history (
+    id VARCHAR(36) PRIMARY KEY,
+    config_id VARCHAR(36) NOT NULL,
+    session_id VARCHAR(64) NOT NULL,
+    target_host VARCHAR(255) NOT NULL DEFAULT '',
+    target_port INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_seconds BIGINT NOT NULL DEFAULT 0,
+    bytes_transferred BIGINT NOT NULL DEFAULT 0,
+    error_message TEXT
+);
+
+-- Индексы для оптимизации запросов
+CREATE INDEX IF NOT EXISTS idx_bypass_history_session_id ON bypass_history(session_id);
+CREATE INDEX IF NOT EXISTS idx_bypass_history_started_at ON bypass_history(started_at);
+
+-- Композитный индекс для фильтрации по конфигурации и времени
+CREATE INDEX IF NOT EXISTS idx_bypass_history_config_started ON bypass_history(config_id, started_at DESC);
@@ -0,0 +1,14 @@
+-- Создание таблицы пользователей серверов обхода
+-- user_id совпадает с ID пользователя auth-service
+CREATE TABLE IF NOT EXISTS bypass_users (
+    config_id VARCHAR(36) NOT NULL REFERENCES bypass_configs(id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL,
+    credential TEXT NOT NULL,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (config_id, user_id)
+);
+
+-- Индекс для выборки конфигураций пользователя
+CREATE INDEX IF NOT EXISTS idx_bypass_users_user_id ON bypass_users(user_id);
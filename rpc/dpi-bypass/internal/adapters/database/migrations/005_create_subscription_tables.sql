@@ -0,0 +1,29 @@
+-- Создание таблицы токенов подписки
+-- user_id совпадает с ID пользователя auth-service
+-- token_hash - SHA-256 токена в hex, сам токен не хранится
+CREATE TABLE IF NOT EXISTS subscription_tokens (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_accessed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Индекс для выборки токенов пользователя
+CREATE INDEX IF NOT EXISTS idx_subscription_tokens_user_id ON subscription_tokens(user_id);
+
+-- Создание журнала обращений к подпискам
+CREATE TABLE IF NOT EXISTS subscription_access_log (
+    id VARCHAR(36) PRIMARY KEY,
+    token_id VARCHAR(36) NOT NULL REFERENCES subscription_tokens(id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL,
+    format VARCHAR(16) NOT NULL,
+    remote_addr VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    accessed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Индексы для выборки журнала по токену и пользователю
+CREATE INDEX IF NOT EXISTS idx_subscription_access_log_token_id ON subscription_access_log(token_id, accessed_at);
+CREATE INDEX IF NOT EXISTS idx_subscription_access_log_user_id ON subscription_access_log(user_id, accessed_at);
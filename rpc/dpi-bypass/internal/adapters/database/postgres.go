@@ -0,0 +1,864 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// PostgresRepository реализация репозитория для PostgreSQL
+type PostgresRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPostgresRepository создает новый PostgreSQL репозиторий
+func NewPostgresRepository(db *sql.DB, logger *zap.Logger) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// rowScanner общий интерфейс sql.Row и sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// CreateConfig создает новую конфигурацию обхода
+func (r *PostgresRepository) CreateConfig(ctx context.Context, config *domain.BypassConfig) error {
+	query := `
+		INSERT INTO bypass_configs (id, name, description, type, method, status, parameters, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	parameters, err := encodeParameters(config.Parameters)
+	if err != nil {
+		return err
+	}
+
+	config.ID = uuid.New().String()
+	config.CreatedAt = time.Now()
+	config.UpdatedAt = config.CreatedAt
+
+	_, err = r.db.ExecContext(ctx, query,
+		config.ID, config.Name, config.Description, config.Type, config.Method, config.Status,
+		parameters, config.CreatedAt, config.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bypass configuration: %w", err)
+	}
+
+	r.logger.Info("bypass configuration created", zap.String("id", config.ID), zap.String("name", config.Name))
+	return nil
+}
+
+// GetConfig получает конфигурацию обхода по ID
+func (r *PostgresRepository) GetConfig(ctx context.Context, id string) (*domain.BypassConfig, error) {
+	query := `
+		SELECT id, name, description, type, method, status, parameters, created_at, updated_at
+		FROM bypass_configs WHERE id = $1
+	`
+
+	config, err := scanConfig(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("bypass configuration not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get bypass configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// ListConfigs получает список конфигураций обхода с фильтрами
+func (r *PostgresRepository) ListConfigs(ctx context.Context, filters *domain.BypassConfigFilters) ([]*domain.BypassConfig, int, error) {
+	where := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters != nil {
+		if filters.Type != "" {
+			where = append(where, fmt.Sprintf("type = $%d", argIndex))
+			args = append(args, filters.Type)
+			argIndex++
+		}
+		if filters.Status != "" {
+			where = append(where, fmt.Sprintf("status = $%d", argIndex))
+			args = append(args, filters.Status)
+			argIndex++
+		}
+	}
+
+	conditions := whereClause(where)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bypass_configs"+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bypass configurations: %w", err)
+	}
+
+	query := `
+		SELECT id, name, description, type, method, status, parameters, created_at, updated_at
+		FROM bypass_configs` + conditions + " ORDER BY created_at DESC"
+
+	if filters != nil {
+		query, args = paginate(query, args, argIndex, filters.Limit, filters.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bypass configurations: %w", err)
+	}
+	defer rows.Close()
+
+	configs := []*domain.BypassConfig{}
+	for rows.Next() {
+		config, err := scanConfig(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan bypass configuration: %w", err)
+		}
+		configs = append(configs, config)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list bypass configurations: %w", err)
+	}
+
+	return configs, total, nil
+}
+
+// UpdateConfig обновляет конфигурацию обхода, кроме статуса
+func (r *PostgresRepository) UpdateConfig(ctx context.Context, config *domain.BypassConfig) error {
+	query := `
+		UPDATE bypass_configs
+		SET name = $2, description = $3, type = $4, method = $5, parameters = $6, updated_at = $7
+		WHERE id = $1
+	`
+
+	parameters, err := encodeParameters(config.Parameters)
+	if err != nil {
+		return err
+	}
+
+	config.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		config.ID, config.Name, config.Description, config.Type, config.Method,
+		parameters, config.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update bypass configuration: %w", err)
+	}
+
+	return checkAffected(result, "bypass configuration", config.ID)
+}
+
+// UpdateConfigStatus сохраняет только статус конфигурации
+func (r *PostgresRepository) UpdateConfigStatus(ctx context.Context, id string, status domain.BypassStatus) error {
+	query := `UPDATE bypass_configs SET status = $2, updated_at = $3 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, status, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update bypass configuration status: %w", err)
+	}
+
+	return checkAffected(result, "bypass configuration", id)
+}
+
+// DeleteConfig удаляет конфигурацию обхода вместе с ее правилами и
+// пользователями
+func (r *PostgresRepository) DeleteConfig(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM bypass_configs WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete bypass configuration: %w", err)
+	}
+
+	if err := checkAffected(result, "bypass configuration", id); err != nil {
+		return err
+	}
+
+	r.logger.Info("bypass configuration deleted", zap.String("id", id))
+	return nil
+}
+
+// CreateRule создает новое правило обхода
+func (r *PostgresRepository) CreateRule(ctx context.Context, rule *domain.BypassRule) error {
+	query := `
+		INSERT INTO bypass_rules (id, config_id, name, type, action, pattern, parameters, priority, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	parameters, err := encodeParameters(rule.Parameters)
+	if err != nil {
+		return err
+	}
+
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+
+	_, err = r.db.ExecContext(ctx, query,
+		rule.ID, rule.ConfigID, rule.Name, rule.Type, rule.Action, rule.Pattern,
+		parameters, rule.Priority, rule.Enabled, rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bypass rule: %w", err)
+	}
+
+	r.logger.Info("bypass rule created", zap.String("id", rule.ID), zap.String("config_id", rule.ConfigID))
+	return nil
+}
+
+// GetRule получает правило обхода по ID
+func (r *PostgresRepository) GetRule(ctx context.Context, id string) (*domain.BypassRule, error) {
+	query := `
+		SELECT id, config_id, name, type, action, pattern, parameters, priority, enabled, created_at, updated_at
+		FROM bypass_rules WHERE id = $1
+	`
+
+	rule, err := scanRule(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("bypass rule not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get bypass rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// ListRules получает список правил обхода с фильтрами, по убыванию приоритета
+func (r *PostgresRepository) ListRules(ctx context.Context, filters *domain.BypassRuleFilters) ([]*domain.BypassRule, int, error) {
+	where := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters != nil {
+		if filters.ConfigID != "" {
+			where = append(where, fmt.Sprintf("config_id = $%d", argIndex))
+			args = append(args, filters.ConfigID)
+			argIndex++
+		}
+		if filters.Type != "" {
+			where = append(where, fmt.Sprintf("type = $%d", argIndex))
+			args = append(args, filters.Type)
+			argIndex++
+		}
+		if filters.Enabled {
+			where = append(where, "enabled = TRUE")
+		}
+	}
+
+	conditions := whereClause(where)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bypass_rules"+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bypass rules: %w", err)
+	}
+
+	query := `
+		SELECT id, config_id, name, type, action, pattern, parameters, priority, enabled, created_at, updated_at
+		FROM bypass_rules` + conditions + " ORDER BY priority DESC, created_at"
+
+	if filters != nil {
+		query, args = paginate(query, args, argIndex, filters.Limit, filters.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bypass rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*domain.BypassRule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan bypass rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list bypass rules: %w", err)
+	}
+
+	return rules, total, nil
+}
+
+// UpdateRule обновляет правило обхода
+func (r *PostgresRepository) UpdateRule(ctx context.Context, rule *domain.BypassRule) error {
+	query := `
+		UPDATE bypass_rules
+		SET name = $2, type = $3, action = $4, pattern = $5, parameters = $6, priority = $7, enabled = $8, updated_at = $9
+		WHERE id = $1
+	`
+
+	parameters, err := encodeParameters(rule.Parameters)
+	if err != nil {
+		return err
+	}
+
+	rule.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		rule.ID, rule.Name, rule.Type, rule.Action, rule.Pattern,
+		parameters, rule.Priority, rule.Enabled, rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update bypass rule: %w", err)
+	}
+
+	return checkAffected(result, "bypass rule", rule.ID)
+}
+
+// DeleteRule удаляет правило обхода
+func (r *PostgresRepository) DeleteRule(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM bypass_rules WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete bypass rule: %w", err)
+	}
+
+	if err := checkAffected(result, "bypass rule", id); err != nil {
+		return err
+	}
+
+	r.logger.Info("bypass rule deleted", zap.String("id", id))
+	return nil
+}
+
+// CreateUser добавляет пользователя сервера обхода
+func (r *PostgresRepository) CreateUser(ctx context.Context, user *domain.BypassUser) error {
+	query := `
+		INSERT INTO bypass_users (config_id, user_id, credential, revoked, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	_, err := r.db.ExecContext(ctx, query,
+		user.ConfigID, user.ID, user.Credential, user.Revoked, user.CreatedAt, user.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bypass user: %w", err)
+	}
+
+	r.logger.Info("bypass user created", zap.String("user_id", user.ID), zap.String("config_id", user.ConfigID))
+	return nil
+}
+
+// GetUser получает пользователя сервера обхода
+func (r *PostgresRepository) GetUser(ctx context.Context, configID, userID string) (*domain.BypassUser, error) {
+	query := `
+		SELECT config_id, user_id, credential, revoked, created_at, updated_at
+		FROM bypass_users WHERE config_id = $1 AND user_id = $2
+	`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, configID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("bypass user not found: %s", userID)
+		}
+		return nil, fmt.Errorf("failed to get bypass user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ListUsers получает список пользователей сервера обхода по дате добавления
+func (r *PostgresRepository) ListUsers(ctx context.Context, filters *domain.BypassUserFilters) ([]*domain.BypassUser, int, error) {
+	where := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters != nil {
+		if filters.ConfigID != "" {
+			where = append(where, fmt.Sprintf("config_id = $%d", argIndex))
+			args = append(args, filters.ConfigID)
+			argIndex++
+		}
+		if filters.UserID != "" {
+			where = append(where, fmt.Sprintf("user_id = $%d", argIndex))
+			args = append(args, filters.UserID)
+			argIndex++
+		}
+		if !filters.IncludeRevoked {
+			where = append(where, "revoked = FALSE")
+		}
+	}
+
+	conditions := whereClause(where)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bypass_users"+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bypass users: %w", err)
+	}
+
+	query := `
+		SELECT config_id, user_id, credential, revoked, created_at, updated_at
+		FROM bypass_users` + conditions + " ORDER BY created_at, user_id"
+
+	if filters != nil {
+		query, args = paginate(query, args, argIndex, filters.Limit, filters.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bypass users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*domain.BypassUser{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan bypass user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list bypass users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// UpdateUser обновляет ключ и отзыв пользователя сервера обхода
+func (r *PostgresRepository) UpdateUser(ctx context.Context, user *domain.BypassUser) error {
+	query := `
+		UPDATE bypass_users
+		SET credential = $3, revoked = $4, updated_at = $5
+		WHERE config_id = $1 AND user_id = $2
+	`
+
+	user.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		user.ConfigID, user.ID, user.Credential, user.Revoked, user.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update bypass user: %w", err)
+	}
+
+	return checkAffected(result, "bypass user", user.ID)
+}
+
+// CreateSubscriptionToken сохраняет токен подписки
+func (r *PostgresRepository) CreateSubscriptionToken(ctx context.Context, token *domain.SubscriptionToken) error {
+	query := `
+		INSERT INTO subscription_tokens (id, user_id, token_hash, revoked, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	token.ID = uuid.New().String()
+	token.CreatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, query, token.ID, token.UserID, token.TokenHash, token.Revoked, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription token: %w", err)
+	}
+
+	r.logger.Info("subscription token created", zap.String("id", token.ID), zap.String("user_id", token.UserID))
+	return nil
+}
+
+// GetSubscriptionToken получает токен подписки по ID
+func (r *PostgresRepository) GetSubscriptionToken(ctx context.Context, id string) (*domain.SubscriptionToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, revoked, created_at, last_accessed_at
+		FROM subscription_tokens WHERE id = $1
+	`
+
+	token, err := scanSubscriptionToken(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription token not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get subscription token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetSubscriptionTokenByHash получает токен подписки по хешу значения из URL
+func (r *PostgresRepository) GetSubscriptionTokenByHash(ctx context.Context, tokenHash string) (*domain.SubscriptionToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, revoked, created_at, last_accessed_at
+		FROM subscription_tokens WHERE token_hash = $1
+	`
+
+	token, err := scanSubscriptionToken(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription token not found")
+		}
+		return nil, fmt.Errorf("failed to get subscription token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ListSubscriptionTokens получает список токенов подписки по дате выпуска
+func (r *PostgresRepository) ListSubscriptionTokens(ctx context.Context, filters *domain.SubscriptionTokenFilters) ([]*domain.SubscriptionToken, int, error) {
+	where := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters != nil {
+		if filters.UserID != "" {
+			where = append(where, fmt.Sprintf("user_id = $%d", argIndex))
+			args = append(args, filters.UserID)
+			argIndex++
+		}
+		if !filters.IncludeRevoked {
+			where = append(where, "revoked = FALSE")
+		}
+	}
+
+	conditions := whereClause(where)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM subscription_tokens"+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count subscription tokens: %w", err)
+	}
+
+	query := `
+		SELECT id, user_id, token_hash, revoked, created_at, last_accessed_at
+		FROM subscription_tokens` + conditions + " ORDER BY created_at, id"
+
+	if filters != nil {
+		query, args = paginate(query, args, argIndex, filters.Limit, filters.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscription tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*domain.SubscriptionToken{}
+	for rows.Next() {
+		token, err := scanSubscriptionToken(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan subscription token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscription tokens: %w", err)
+	}
+
+	return tokens, total, nil
+}
+
+// UpdateSubscriptionToken обновляет отзыв токена подписки
+func (r *PostgresRepository) UpdateSubscriptionToken(ctx context.Context, token *domain.SubscriptionToken) error {
+	query := `UPDATE subscription_tokens SET revoked = $2 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, token.ID, token.Revoked)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription token: %w", err)
+	}
+
+	return checkAffected(result, "subscription token", token.ID)
+}
+
+// CreateSubscriptionAccess записывает обращение к подписке и время
+// последнего обращения токена
+func (r *PostgresRepository) CreateSubscriptionAccess(ctx context.Context, access *domain.SubscriptionAccess) error {
+	query := `
+		WITH access AS (
+			INSERT INTO subscription_access_log (id, token_id, user_id, format, remote_addr, user_agent, accessed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		)
+		UPDATE subscription_tokens SET last_accessed_at = $7 WHERE id = $2
+	`
+
+	access.ID = uuid.New().String()
+
+	_, err := r.db.ExecContext(ctx, query,
+		access.ID, access.TokenID, access.UserID, access.Format, access.RemoteAddr, access.UserAgent, access.AccessedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription access: %w", err)
+	}
+
+	return nil
+}
+
+// ListSubscriptionAccesses получает журнал обращений к подписке, новые первыми
+func (r *PostgresRepository) ListSubscriptionAccesses(ctx context.Context, filters *domain.SubscriptionAccessFilters) ([]*domain.SubscriptionAccess, int, error) {
+	where := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filters != nil {
+		if filters.TokenID != "" {
+			where = append(where, fmt.Sprintf("token_id = $%d", argIndex))
+			args = append(args, filters.TokenID)
+			argIndex++
+		}
+		if filters.UserID != "" {
+			where = append(where, fmt.Sprintf("user_id = $%d", argIndex))
+			args = append(args, filters.UserID)
+			argIndex++
+		}
+	}
+
+	conditions := whereClause(where)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM subscription_access_log"+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count subscription accesses: %w", err)
+	}
+
+	query := `
+		SELECT id, token_id, user_id, format, remote_addr, user_agent, accessed_at
+		FROM subscription_access_log` + conditions + " ORDER BY accessed_at DESC, id"
+
+	if filters != nil {
+		query, args = paginate(query, args, argIndex, filters.Limit, filters.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscription accesses: %w", err)
+	}
+	defer rows.Close()
+
+	accesses := []*domain.SubscriptionAccess{}
+	for rows.Next() {
+		access := &domain.SubscriptionAccess{}
+		err := rows.Scan(&access.ID, &access.TokenID, &access.UserID, &access.Format,
+			&access.RemoteAddr, &access.UserAgent, &access.AccessedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan subscription access: %w", err)
+		}
+		accesses = append(accesses, access)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscription accesses: %w", err)
+	}
+
+	return accesses, total, nil
+}
+
+// CreateHistoryEntry сохраняет запись о завершенной сессии
+func (r *PostgresRepository) CreateHistoryEntry(ctx context.Context, entry *domain.BypassHistoryEntry) error {
+	query := `
+		INSERT INTO bypass_history (id, config_id, session_id, target_host, target_port, status,
+			started_at, ended_at, duration_seconds, bytes_transferred, error_message)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	entry.ID = uuid.New().String()
+
+	var errorMessage sql.NullString
+	if entry.ErrorMessage != "" {
+		errorMessage = sql.NullString{String: entry.ErrorMessage, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.ID, entry.ConfigID, entry.SessionID, entry.TargetHost, entry.TargetPort, entry.Status,
+		entry.StartedAt, entry.EndedAt, entry.DurationSeconds, entry.BytesTransferred, errorMessage,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bypass history entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListHistory получает историю сессий по конфигурации и диапазону времени начала
+func (r *PostgresRepository) ListHistory(ctx context.Context, req *domain.BypassHistoryRequest) ([]*domain.BypassHistoryEntry, int, error) {
+	where := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.ConfigID != "" {
+		where = append(where, fmt.Sprintf("config_id = $%d", argIndex))
+		args = append(args, req.ConfigID)
+		argIndex++
+	}
+	if !req.StartTime.IsZero() {
+		where = append(where, fmt.Sprintf("started_at >= $%d", argIndex))
+		args = append(args, req.StartTime)
+		argIndex++
+	}
+	if !req.EndTime.IsZero() {
+		where = append(where, fmt.Sprintf("started_at <= $%d", argIndex))
+		args = append(args, req.EndTime)
+		argIndex++
+	}
+
+	conditions := whereClause(where)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bypass_history"+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bypass history: %w", err)
+	}
+
+	query := `
+		SELECT id, config_id, session_id, target_host, target_port, status,
+			started_at, ended_at, duration_seconds, bytes_transferred, error_message
+		FROM bypass_history` + conditions + " ORDER BY started_at DESC"
+
+	query, args = paginate(query, args, argIndex, req.Limit, req.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bypass history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*domain.BypassHistoryEntry{}
+	for rows.Next() {
+		entry := &domain.BypassHistoryEntry{}
+		var errorMessage sql.NullString
+
+		err := rows.Scan(
+			&entry.ID, &entry.ConfigID, &entry.SessionID, &entry.TargetHost, &entry.TargetPort, &entry.Status,
+			&entry.StartedAt, &entry.EndedAt, &entry.DurationSeconds, &entry.BytesTransferred, &errorMessage,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan bypass history entry: %w", err)
+		}
+
+		// Обрабатываем NULL значения
+		if errorMessage.Valid {
+			entry.ErrorMessage = errorMessage.String
+		}
+
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list bypass history: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// scanConfig читает конфигурацию из строки результата
+func scanConfig(row rowScanner) (*domain.BypassConfig, error) {
+	config := &domain.BypassConfig{Rules: []*domain.BypassRule{}}
+	var parameters []byte
+
+	err := row.Scan(
+		&config.ID, &config.Name, &config.Description, &config.Type, &config.Method, &config.Status,
+		&parameters, &config.CreatedAt, &config.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Parameters, err = decodeParameters(parameters); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// scanRule читает правило из строки результата
+func scanRule(row rowScanner) (*domain.BypassRule, error) {
+	rule := &domain.BypassRule{}
+	var parameters []byte
+
+	err := row.Scan(
+		&rule.ID, &rule.ConfigID, &rule.Name, &rule.Type, &rule.Action, &rule.Pattern,
+		&parameters, &rule.Priority, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.Parameters, err = decodeParameters(parameters); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// scanUser читает пользователя из строки результата
+func scanUser(row rowScanner) (*domain.BypassUser, error) {
+	user := &domain.BypassUser{}
+	err := row.Scan(&user.ConfigID, &user.ID, &user.Credential, &user.Revoked, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func scanSubscriptionToken(row rowScanner) (*domain.SubscriptionToken, error) {
+	token := &domain.SubscriptionToken{}
+	var lastAccessedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.TokenHash, &token.Revoked, &token.CreatedAt, &lastAccessedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastAccessedAt.Valid {
+		token.LastAccessedAt = lastAccessedAt.Time
+	}
+	return token, nil
+}
+
+// encodeParameters сериализует параметры в JSONB
+func encodeParameters(parameters map[string]string) ([]byte, error) {
+	if parameters == nil {
+		parameters = map[string]string{}
+	}
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parameters: %w", err)
+	}
+	return data, nil
+}
+
+// decodeParameters разбирает параметры из JSONB
+func decodeParameters(data []byte) (map[string]string, error) {
+	parameters := map[string]string{}
+	if len(data) == 0 {
+		return parameters, nil
+	}
+	if err := json.Unmarshal(data, &parameters); err != nil {
+		return nil, fmt.Errorf("failed to decode parameters: %w", err)
+	}
+	return parameters, nil
+}
+
+// whereClause собирает условия фильтрации
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// paginate добавляет LIMIT и OFFSET, если они заданы
+func paginate(query string, args []interface{}, argIndex, limit, offset int) (string, []interface{}) {
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, limit)
+		argIndex++
+	}
+	if offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, offset)
+	}
+	return query, args
+}
+
+// checkAffected возвращает ошибку "not found", если запрос не затронул строк
+func checkAffected(result sql.Result, entity, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s not found: %s", entity, id)
+	}
+	return nil
+}
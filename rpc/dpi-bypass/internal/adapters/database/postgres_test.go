@@ -0,0 +1,345 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var configColumns = []string{"id", "name", "description", "type", "method", "status", "parameters", "created_at", "updated_at"}
+
+func TestPostgresRepository_CreateConfig(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	config := &domain.BypassConfig{
+		Name:       "test-bypass",
+		Type:       domain.BypassTypeTunnelObfuscation,
+		Method:     domain.BypassMethodShadowsocks,
+		Status:     domain.BypassStatusInactive,
+		Parameters: map[string]string{"remote_host": "example.com"},
+	}
+
+	mock.ExpectExec("INSERT INTO bypass_configs").
+		WithArgs(sqlmock.AnyArg(), config.Name, config.Description, config.Type, config.Method, config.Status,
+			[]byte(`{"remote_host":"example.com"}`), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.CreateConfig(context.Background(), config)
+	assert.NoError(t, err)
+	_, err = uuid.Parse(config.ID)
+	assert.NoError(t, err)
+	assert.False(t, config.CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_GetConfig(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	configID := uuid.New().String()
+	now := time.Now()
+	rows := sqlmock.NewRows(configColumns).
+		AddRow(configID, "test-bypass", "", domain.BypassTypeTunnelObfuscation, domain.BypassMethodV2Ray,
+			domain.BypassStatusActive, []byte(`{"local_port":"1080"}`), now, now)
+
+	mock.ExpectQuery(`SELECT .+ FROM bypass_configs WHERE id = \$1`).
+		WithArgs(configID).
+		WillReturnRows(rows)
+
+	config, err := repo.GetConfig(context.Background(), configID)
+	assert.NoError(t, err)
+	assert.Equal(t, configID, config.ID)
+	assert.Equal(t, domain.BypassStatusActive, config.Status)
+	assert.Equal(t, map[string]string{"local_port": "1080"}, config.Parameters)
+	assert.NotNil(t, config.Rules)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_GetConfig_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	mock.ExpectQuery(`SELECT .+ FROM bypass_configs WHERE id = \$1`).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	config, err := repo.GetConfig(context.Background(), "missing")
+	assert.Nil(t, config)
+	assert.EqualError(t, err, "bypass configuration not found: missing")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_ListConfigs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM bypass_configs WHERE status = \$1`).
+		WithArgs(domain.BypassStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT .+ FROM bypass_configs WHERE status = \$1 ORDER BY created_at DESC LIMIT \$2 OFFSET \$3`).
+		WithArgs(domain.BypassStatusActive, 1, 2).
+		WillReturnRows(sqlmock.NewRows(configColumns).
+			AddRow(uuid.New().String(), "third", "", domain.BypassTypeTunnelObfuscation, domain.BypassMethodObfs4,
+				domain.BypassStatusActive, []byte(`{}`), now, now))
+
+	configs, total, err := repo.ListConfigs(context.Background(), &domain.BypassConfigFilters{
+		Status: domain.BypassStatusActive,
+		Limit:  1,
+		Offset: 2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, configs, 1)
+	assert.Equal(t, "third", configs[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_UpdateConfig_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	mock.ExpectExec("UPDATE bypass_configs").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.UpdateConfig(context.Background(), &domain.BypassConfig{ID: "missing"})
+	assert.EqualError(t, err, "bypass configuration not found: missing")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_UpdateConfigStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	// Параметры, измененные во время запуска, не перезаписываются
+	mock.ExpectExec(`UPDATE bypass_configs SET status = \$2, updated_at = \$3 WHERE id = \$1`).
+		WithArgs("config-1", domain.BypassStatusActive, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdateConfigStatus(context.Background(), "config-1", domain.BypassStatusActive)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_ListRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	configID := uuid.New().String()
+	now := time.Now()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM bypass_rules WHERE config_id = \$1 AND enabled = TRUE`).
+		WithArgs(configID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT .+ FROM bypass_rules WHERE config_id = \$1 AND enabled = TRUE ORDER BY priority DESC`).
+		WithArgs(configID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "config_id", "name", "type", "action", "pattern", "parameters", "priority", "enabled", "created_at", "updated_at"}).
+			AddRow(uuid.New().String(), configID, "block-ads", domain.RuleTypeDomain, domain.RuleActionBlock,
+				"*.ads.example", []byte(`{}`), 10, true, now, now))
+
+	rules, total, err := repo.ListRules(context.Background(), &domain.BypassRuleFilters{ConfigID: configID, Enabled: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, domain.RuleActionBlock, rules[0].Action)
+	assert.Equal(t, 10, rules[0].Priority)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_ListUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	configID := uuid.New().String()
+	now := time.Now()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM bypass_users WHERE config_id = \$1 AND revoked = FALSE`).
+		WithArgs(configID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT .+ FROM bypass_users WHERE config_id = \$1 AND revoked = FALSE ORDER BY created_at, user_id LIMIT \$2`).
+		WithArgs(configID, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"config_id", "user_id", "credential", "revoked", "created_at", "updated_at"}).
+			AddRow(configID, "user-1", "c2VjcmV0", false, now, now))
+
+	users, total, err := repo.ListUsers(context.Background(), &domain.BypassUserFilters{ConfigID: configID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "user-1", users[0].ID)
+	assert.Equal(t, configID, users[0].ConfigID)
+	assert.Equal(t, "c2VjcmV0", users[0].Credential)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_UpdateUser_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	mock.ExpectExec("UPDATE bypass_users").
+		WithArgs("config", "missing", "secret", true, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.UpdateUser(context.Background(), &domain.BypassUser{ID: "missing", ConfigID: "config", Credential: "secret", Revoked: true})
+	assert.EqualError(t, err, "bypass user not found: missing")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_GetSubscriptionTokenByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT .+ FROM subscription_tokens WHERE token_hash = \$1`).
+		WithArgs("token-hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash", "revoked", "created_at", "last_accessed_at"}).
+			AddRow("token-1", "user-1", "token-hash", false, now, nil))
+
+	token, err := repo.GetSubscriptionTokenByHash(context.Background(), "token-hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", token.ID)
+	assert.Equal(t, "user-1", token.UserID)
+	assert.Equal(t, "token-hash", token.TokenHash)
+	assert.Empty(t, token.Token)
+	assert.True(t, token.LastAccessedAt.IsZero())
+
+	mock.ExpectQuery(`SELECT .+ FROM subscription_tokens WHERE token_hash = \$1`).
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetSubscriptionTokenByHash(context.Background(), "unknown")
+	assert.EqualError(t, err, "subscription token not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_ListSubscriptionAccesses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM subscription_access_log WHERE token_id = \$1`).
+		WithArgs("token-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT .+ FROM subscription_access_log WHERE token_id = \$1 ORDER BY accessed_at DESC, id LIMIT \$2`).
+		WithArgs("token-1", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token_id", "user_id", "format", "remote_addr", "user_agent", "accessed_at"}).
+			AddRow("access-1", "token-1", "user-1", "clash", "203.0.113.7", "clash.meta", now))
+
+	accesses, total, err := repo.ListSubscriptionAccesses(context.Background(), &domain.SubscriptionAccessFilters{TokenID: "token-1", Limit: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, accesses, 1)
+	assert.Equal(t, domain.SubscriptionFormatClash, accesses[0].Format)
+	assert.Equal(t, "203.0.113.7", accesses[0].RemoteAddr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_CreateHistoryEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	startedAt := time.Now().Add(-time.Minute)
+	entry := &domain.BypassHistoryEntry{
+		ConfigID:         uuid.New().String(),
+		SessionID:        uuid.New().String(),
+		TargetHost:       "example.com",
+		TargetPort:       443,
+		Status:           domain.BypassStatusError,
+		StartedAt:        startedAt,
+		EndedAt:          startedAt.Add(time.Minute),
+		DurationSeconds:  60,
+		BytesTransferred: 4096,
+		ErrorMessage:     "connection reset",
+	}
+
+	mock.ExpectExec("INSERT INTO bypass_history").
+		WithArgs(sqlmock.AnyArg(), entry.ConfigID, entry.SessionID, entry.TargetHost, entry.TargetPort, entry.Status,
+			entry.StartedAt, entry.EndedAt, entry.DurationSeconds, entry.BytesTransferred,
+			sql.NullString{String: "connection reset", Valid: true}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.CreateHistoryEntry(context.Background(), entry)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, entry.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_ListHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	configID := uuid.New().String()
+	from := time.Now().Add(-24 * time.Hour)
+	to := time.Now()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM bypass_history WHERE config_id = \$1 AND started_at >= \$2 AND started_at <= \$3`).
+		WithArgs(configID, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(`SELECT .+ FROM bypass_history WHERE .+ ORDER BY started_at DESC LIMIT \$4 OFFSET \$5`).
+		WithArgs(configID, from, to, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "config_id", "session_id", "target_host", "target_port", "status",
+			"started_at", "ended_at", "duration_seconds", "bytes_transferred", "error_message"}).
+			AddRow(uuid.New().String(), configID, "s1", "example.com", 443, domain.BypassStatusInactive,
+				to.Add(-time.Hour), to, 3600, 1024, nil).
+			AddRow(uuid.New().String(), configID, "s2", "example.com", 443, domain.BypassStatusError,
+				to.Add(-2*time.Hour), to.Add(-2*time.Hour), 0, 0, "no working bypass method"))
+
+	entries, total, err := repo.ListHistory(context.Background(), &domain.BypassHistoryRequest{
+		ConfigID:  configID,
+		StartTime: from,
+		EndTime:   to,
+		Limit:     2,
+		Offset:    2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Len(t, entries, 2)
+	assert.Empty(t, entries[0].ErrorMessage)
+	assert.Equal(t, int64(1024), entries[0].BytesTransferred)
+	assert.Equal(t, "no working bypass method", entries[1].ErrorMessage)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,467 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// resolveTimeout ограничивает разрешение одного вопроса
+	resolveTimeout = 5 * time.Second
+	// comparisonWindow ожидание ответов остальных серверов после первого
+	comparisonWindow = 300 * time.Millisecond
+	// maxCacheTTL верхняя граница времени кеширования ответа
+	maxCacheTTL = time.Hour
+	// negativeCacheTTL время кеширования ответа без записей и SOA
+	negativeCacheTTL = time.Minute
+	// maxCacheEntries размер кеша, при переполнении он очищается
+	maxCacheEntries = 10000
+)
+
+// ErrPoisoned все ответы серверов отброшены как подмененные
+var ErrPoisoned = errors.New("all dns answers rejected as poisoned")
+
+// Router возвращает адрес сервера, выбранного для имени, или пустую строку
+type Router func(name string) string
+
+// Resolver разрешает имена через зашифрованные серверы DNS, кеширует
+// ответы и отбрасывает подмененные: ответы с адресами из bogon диапазонов
+// и ответы, расходящиеся с остальными серверами
+type Resolver struct {
+	upstreams []Upstream
+	logger    *zap.Logger
+	cache     map[cacheKey]*cacheEntry
+	router    Router
+	mutex     sync.Mutex
+
+	// Подменяются в тестах
+	now    func() time.Time
+	window time.Duration
+}
+
+type cacheKey struct {
+	name     string
+	qtype    dnsmessage.Type
+	upstream string
+}
+
+type cacheEntry struct {
+	message dnsmessage.Message
+	expires time.Time
+}
+
+// NewResolver создает резолвер с серверами в порядке приоритета
+func NewResolver(upstreams []Upstream, logger *zap.Logger) *Resolver {
+	return &Resolver{
+		upstreams: upstreams,
+		logger:    logger,
+		cache:     make(map[cacheKey]*cacheEntry),
+		now:       time.Now,
+		window:    comparisonWindow,
+	}
+}
+
+// SetRouter задает выбор сервера по имени. Имя, для которого сервер не
+// выбран, разрешается всеми серверами со сравнением ответов.
+func (r *Resolver) SetRouter(router Router) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.router = router
+}
+
+// HasUpstream проверяет, что сервер с адресом настроен
+func (r *Resolver) HasUpstream(address string) bool {
+	for _, upstream := range r.upstreams {
+		if upstream.Address() == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Exchange отвечает на запрос в формате DNS. Ошибка возвращается только для
+// запроса, который не удалось разобрать; сбой разрешения дает SERVFAIL.
+func (r *Resolver) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	var request dnsmessage.Message
+	if err := request.Unpack(query); err != nil {
+		return nil, fmt.Errorf("invalid dns query: %w", err)
+	}
+
+	response := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 request.ID,
+			Response:           true,
+			OpCode:             request.OpCode,
+			RecursionDesired:   request.RecursionDesired,
+			RecursionAvailable: true,
+		},
+		Questions: request.Questions,
+	}
+	switch {
+	case request.Response || len(request.Questions) != 1:
+		response.RCode = dnsmessage.RCodeFormatError
+		return response.Pack()
+	case request.OpCode != 0:
+		response.RCode = dnsmessage.RCodeNotImplemented
+		return response.Pack()
+	}
+
+	answer, err := r.resolve(ctx, request.Questions[0])
+	if err != nil {
+		r.logger.Debug("dns resolution failed",
+			zap.String("name", request.Questions[0].Name.String()),
+			zap.Error(err))
+		response.RCode = dnsmessage.RCodeServerFailure
+		return response.Pack()
+	}
+
+	// Записи копируются: Pack меняет их заголовки, а ответ разделяется с кешем
+	response.RCode = answer.RCode
+	response.Answers = append([]dnsmessage.Resource(nil), answer.Answers...)
+	response.Authorities = append([]dnsmessage.Resource(nil), answer.Authorities...)
+	return response.Pack()
+}
+
+// LookupIP возвращает адреса хоста: сначала IPv4, затем IPv6
+func (r *Resolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	name, err := dnsmessage.NewName(strings.TrimSuffix(host, ".") + ".")
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q: %w", host, err)
+	}
+
+	types := []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA}
+	answers := make([]*dnsmessage.Message, len(types))
+	errs := make([]error, len(types))
+	var wg sync.WaitGroup
+	for i, qtype := range types {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			answers[i], errs[i] = r.resolve(ctx, dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET})
+		}()
+	}
+	wg.Wait()
+
+	var ips []net.IP
+	for _, answer := range answers {
+		if answer != nil {
+			ips = append(ips, answerIPs(answer)...)
+		}
+	}
+	if len(ips) > 0 {
+		return ips, nil
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// resolve отвечает на вопрос из кеша или опросом серверов
+func (r *Resolver) resolve(ctx context.Context, question dnsmessage.Question) (*dnsmessage.Message, error) {
+	name := strings.ToLower(question.Name.String())
+	upstreams, route := r.upstreamsFor(name)
+	key := cacheKey{name: name, qtype: question.Type, upstream: route}
+
+	if answer, ok := r.cached(key); ok {
+		return answer, nil
+	}
+	if len(upstreams) == 0 {
+		return nil, errors.New("no dns upstreams configured")
+	}
+
+	answer, err := r.query(ctx, upstreams, question)
+	if err != nil {
+		return nil, err
+	}
+	r.store(key, answer)
+	return answer, nil
+}
+
+// upstreamsFor возвращает серверы для имени и адрес выбранного правилом
+func (r *Resolver) upstreamsFor(name string) ([]Upstream, string) {
+	r.mutex.Lock()
+	router := r.router
+	r.mutex.Unlock()
+	if router == nil {
+		return r.upstreams, ""
+	}
+
+	route := router(strings.TrimSuffix(name, "."))
+	if route == "" {
+		return r.upstreams, ""
+	}
+	for _, upstream := range r.upstreams {
+		if upstream.Address() == route {
+			return []Upstream{upstream}, route
+		}
+	}
+	r.logger.Warn("dns upstream selected by rule is not configured",
+		zap.String("name", name),
+		zap.String("upstream", route))
+	return r.upstreams, ""
+}
+
+// query опрашивает серверы параллельно. После первого ответа остальные
+// ожидаются не дольше окна сравнения.
+func (r *Resolver) query(ctx context.Context, upstreams []Upstream, question dnsmessage.Question) (*dnsmessage.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{question},
+	}).Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		index    int
+		answer   *dnsmessage.Message
+		poisoned bool
+	}
+	results := make(chan result, len(upstreams))
+	for i, upstream := range upstreams {
+		go func() {
+			answer, err := exchangeQuestion(ctx, upstream, query, question)
+			if err != nil {
+				r.logger.Debug("dns upstream failed", zap.String("upstream", upstream.Address()), zap.Error(err))
+				results <- result{index: i}
+				return
+			}
+			if poisoned(answer) {
+				r.logger.Warn("poisoned dns answer rejected",
+					zap.String("upstream", upstream.Address()),
+					zap.String("name", question.Name.String()))
+				results <- result{index: i, poisoned: true}
+				return
+			}
+			results <- result{index: i, answer: answer}
+		}()
+	}
+
+	answers := make([]*dnsmessage.Message, len(upstreams))
+	rejected := 0
+	var window <-chan time.Time
+	for pending := len(upstreams); pending > 0; {
+		select {
+		case res := <-results:
+			pending--
+			if res.poisoned {
+				rejected++
+			}
+			if res.answer != nil {
+				answers[res.index] = res.answer
+				if window == nil {
+					window = time.After(r.window)
+				}
+			}
+		case <-window:
+			pending = 0
+		case <-ctx.Done():
+			pending = 0
+		}
+	}
+
+	if answer := consensus(answers); answer != nil {
+		return answer, nil
+	}
+	if rejected > 0 {
+		return nil, ErrPoisoned
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("no dns upstream answered")
+}
+
+// exchangeQuestion выполняет запрос и проверяет, что ответ относится к вопросу
+func exchangeQuestion(ctx context.Context, upstream Upstream, query []byte, question dnsmessage.Question) (*dnsmessage.Message, error) {
+	raw, err := upstream.Exchange(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var answer dnsmessage.Message
+	if err := answer.Unpack(raw); err != nil {
+		return nil, fmt.Errorf("invalid dns response: %w", err)
+	}
+	if !answer.Response || len(answer.Questions) != 1 ||
+		!strings.EqualFold(answer.Questions[0].Name.String(), question.Name.String()) ||
+		answer.Questions[0].Type != question.Type {
+		return nil, errors.New("dns response does not match query")
+	}
+	if answer.RCode != dnsmessage.RCodeSuccess && answer.RCode != dnsmessage.RCodeNameError {
+		return nil, fmt.Errorf("dns upstream returned %s", answer.RCode)
+	}
+	return &answer, nil
+}
+
+// consensus выбирает ответ, совпадающий с наибольшим числом остальных;
+// при равенстве побеждает сервер с большим приоритетом
+func consensus(answers []*dnsmessage.Message) *dnsmessage.Message {
+	var best *dnsmessage.Message
+	bestScore := -1
+	for i, answer := range answers {
+		if answer == nil {
+			continue
+		}
+		score := 0
+		for j, other := range answers {
+			if i != j && other != nil && agree(answer, other) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = answer, score
+		}
+	}
+	return best
+}
+
+// agree проверяет, что ответы имеют общий адрес, а ответы без адресов
+// одинаковый код
+func agree(a, b *dnsmessage.Message) bool {
+	left, right := answerIPs(a), answerIPs(b)
+	if len(left) == 0 && len(right) == 0 {
+		return a.RCode == b.RCode
+	}
+	for _, ip := range left {
+		for _, other := range right {
+			if ip.Equal(other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// poisoned проверяет, что ответ содержит адрес, который не может
+// принадлежать публичному хосту
+func poisoned(answer *dnsmessage.Message) bool {
+	for _, ip := range answerIPs(answer) {
+		if IsBogon(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBogon проверяет, что адрес не может принадлежать публичному хосту:
+// loopback, частные, link-local, multicast и нулевые диапазоны
+func IsBogon(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsMulticast()
+}
+
+// answerIPs возвращает адреса записей A и AAAA ответа
+func answerIPs(answer *dnsmessage.Message) []net.IP {
+	var ips []net.IP
+	for _, resource := range answer.Answers {
+		switch body := resource.Body.(type) {
+		case *dnsmessage.AResource:
+			ips = append(ips, net.IP(body.A[:]))
+		case *dnsmessage.AAAAResource:
+			ips = append(ips, net.IP(body.AAAA[:]))
+		}
+	}
+	return ips
+}
+
+// cached возвращает ответ из кеша с TTL, уменьшенными на прошедшее время
+func (r *Resolver) cached(key cacheKey) (*dnsmessage.Message, bool) {
+	now := r.now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, ok := r.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expires) {
+		delete(r.cache, key)
+		return nil, false
+	}
+
+	remaining := uint32(entry.expires.Sub(now) / time.Second)
+	answer := entry.message
+	answer.Answers = withTTL(answer.Answers, remaining)
+	answer.Authorities = withTTL(answer.Authorities, remaining)
+	answer.Additionals = withTTL(answer.Additionals, remaining)
+	return &answer, true
+}
+
+// store кеширует ответ на минимальный TTL его записей
+func (r *Resolver) store(key cacheKey, answer *dnsmessage.Message) {
+	ttl := cacheTTL(answer)
+	if ttl <= 0 {
+		return
+	}
+	now := r.now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.cache) >= maxCacheEntries {
+		for key, entry := range r.cache {
+			if !now.Before(entry.expires) {
+				delete(r.cache, key)
+			}
+		}
+		if len(r.cache) >= maxCacheEntries {
+			r.cache = make(map[cacheKey]*cacheEntry)
+		}
+	}
+	r.cache[key] = &cacheEntry{message: *answer, expires: now.Add(ttl)}
+}
+
+// cacheTTL возвращает время кеширования: минимальный TTL записей ответа,
+// для ответа без записей - TTL из SOA (RFC 2308)
+func cacheTTL(answer *dnsmessage.Message) time.Duration {
+	var ttl uint32
+	found := false
+	for _, resource := range answer.Answers {
+		if !found || resource.Header.TTL < ttl {
+			ttl, found = resource.Header.TTL, true
+		}
+	}
+	if !found {
+		for _, resource := range answer.Authorities {
+			if soa, ok := resource.Body.(*dnsmessage.SOAResource); ok {
+				ttl, found = min(resource.Header.TTL, soa.MinTTL), true
+				break
+			}
+		}
+	}
+	if !found {
+		return negativeCacheTTL
+	}
+	return min(time.Duration(ttl)*time.Second, maxCacheTTL)
+}
+
+// withTTL копирует записи, ограничивая TTL оставшимся временем кеширования
+func withTTL(resources []dnsmessage.Resource, remaining uint32) []dnsmessage.Resource {
+	if len(resources) == 0 {
+		return resources
+	}
+	result := make([]dnsmessage.Resource, len(resources))
+	copy(result, resources)
+	for i := range result {
+		if result[i].Header.Type != dnsmessage.TypeOPT && result[i].Header.TTL > remaining {
+			result[i].Header.TTL = remaining
+		}
+	}
+	return result
+}
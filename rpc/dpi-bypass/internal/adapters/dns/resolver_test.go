@@ -0,0 +1,275 @@
+package dns
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeUpstream отвечает заданными адресами на вопросы A и AAAA
+type fakeUpstream struct {
+	address string
+	ips     []string
+	ttl     uint32
+	err     error
+	calls   atomic.Int32
+}
+
+func (u *fakeUpstream) Address() string {
+	return u.address
+}
+
+func (u *fakeUpstream) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	u.calls.Add(1)
+	if u.err != nil {
+		return nil, u.err
+	}
+	return buildAnswer(query, u.ttl, u.ips)
+}
+
+// buildAnswer собирает ответ на запрос с адресами подходящего семейства
+func buildAnswer(query []byte, ttl uint32, ips []string) ([]byte, error) {
+	var request dnsmessage.Message
+	if err := request.Unpack(query); err != nil {
+		return nil, err
+	}
+	question := request.Questions[0]
+	response := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: request.ID, Response: true, RecursionAvailable: true},
+		Questions: request.Questions,
+	}
+	for _, value := range ips {
+		ip := net.ParseIP(value)
+		header := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: ttl}
+		switch {
+		case ip.To4() != nil && question.Type == dnsmessage.TypeA:
+			header.Type = dnsmessage.TypeA
+			response.Answers = append(response.Answers, dnsmessage.Resource{
+				Header: header,
+				Body:   &dnsmessage.AResource{A: [4]byte(ip.To4())},
+			})
+		case ip.To4() == nil && question.Type == dnsmessage.TypeAAAA:
+			header.Type = dnsmessage.TypeAAAA
+			response.Answers = append(response.Answers, dnsmessage.Resource{
+				Header: header,
+				Body:   &dnsmessage.AAAAResource{AAAA: [16]byte(ip.To16())},
+			})
+		}
+	}
+	return response.Pack()
+}
+
+// newQuery собирает запрос с одним вопросом
+func newQuery(t *testing.T, id uint16, name string, qtype dnsmessage.Type) []byte {
+	t.Helper()
+
+	query, err := (&dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}).Pack()
+	require.NoError(t, err)
+	return query
+}
+
+func TestParseUpstream(t *testing.T) {
+	upstream, err := ParseUpstream("https://1.1.1.1/dns-query", nil)
+	require.NoError(t, err)
+	assert.IsType(t, &dohUpstream{}, upstream)
+
+	upstream, err = ParseUpstream("tls://dns.example", nil)
+	require.NoError(t, err)
+	require.IsType(t, &dotUpstream{}, upstream)
+	assert.Equal(t, "dns.example:853", upstream.(*dotUpstream).host)
+	assert.Equal(t, "dns.example", upstream.(*dotUpstream).tlsConfig.ServerName)
+
+	for _, address := range []string{"udp://1.1.1.1", "https:///dns-query", "1.1.1.1"} {
+		_, err := ParseUpstream(address, nil)
+		assert.Error(t, err, address)
+	}
+}
+
+func TestDoHUpstream_Exchange(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/dns-message", r.Header.Get("Content-Type"))
+		query, _ := io.ReadAll(r.Body)
+		answer, err := buildAnswer(query, 60, []string{"203.0.113.10"})
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(answer)
+	}))
+	defer server.Close()
+
+	upstream, err := ParseUpstream(server.URL+"/dns-query", server.Client().Transport.(*http.Transport).TLSClientConfig)
+	require.NoError(t, err)
+
+	resolver := NewResolver([]Upstream{upstream}, zap.NewNop())
+	ips, err := resolver.LookupIP(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.Len(t, ips, 1)
+	assert.Equal(t, "203.0.113.10", ips[0].String())
+}
+
+func TestDoTUpstream_Exchange(t *testing.T) {
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+	defer server.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server.TLS)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			// Сервер отвечает на один запрос и закрывает соединение, как
+			// после таймаута простоя
+			go func() {
+				defer conn.Close()
+				query, err := readStreamMessage(conn)
+				if err != nil {
+					return
+				}
+				answer, _ := buildAnswer(query, 60, []string{"198.51.100.7"})
+				_ = writeStreamMessage(conn, answer)
+			}()
+		}
+	}()
+
+	upstream, err := ParseUpstream("tls://"+listener.Addr().String(), server.Client().Transport.(*http.Transport).TLSClientConfig)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		raw, err := upstream.Exchange(context.Background(), newQuery(t, 0, "example.com.", dnsmessage.TypeA))
+		require.NoError(t, err)
+		var answer dnsmessage.Message
+		require.NoError(t, answer.Unpack(raw))
+		require.Len(t, answer.Answers, 1)
+	}
+	assert.Equal(t, int32(2), accepted.Load())
+}
+
+func TestResolver_RejectsBogonAnswers(t *testing.T) {
+	poisoned := &fakeUpstream{address: "tls://poisoned", ips: []string{"10.10.34.36"}, ttl: 60}
+	clean := &fakeUpstream{address: "tls://clean", ips: []string{"203.0.113.10"}, ttl: 60}
+	resolver := NewResolver([]Upstream{poisoned, clean}, zap.NewNop())
+
+	ips, err := resolver.LookupIP(context.Background(), "blocked.example")
+	require.NoError(t, err)
+	require.Len(t, ips, 1)
+	assert.Equal(t, "203.0.113.10", ips[0].String())
+
+	resolver = NewResolver([]Upstream{poisoned}, zap.NewNop())
+	_, err = resolver.LookupIP(context.Background(), "blocked.example")
+	assert.ErrorIs(t, err, ErrPoisoned)
+}
+
+func TestResolver_Consensus(t *testing.T) {
+	// Первый по приоритету сервер расходится с двумя остальными
+	liar := &fakeUpstream{address: "tls://liar", ips: []string{"203.0.113.66"}, ttl: 60}
+	first := &fakeUpstream{address: "tls://first", ips: []string{"198.51.100.1", "198.51.100.2"}, ttl: 60}
+	second := &fakeUpstream{address: "tls://second", ips: []string{"198.51.100.2"}, ttl: 60}
+	resolver := NewResolver([]Upstream{liar, first, second}, zap.NewNop())
+
+	ips, err := resolver.LookupIP(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.Len(t, ips, 2)
+	assert.Equal(t, "198.51.100.1", ips[0].String())
+
+	// Без совпадений побеждает сервер с большим приоритетом
+	resolver = NewResolver([]Upstream{liar, second}, zap.NewNop())
+	second.ips = []string{"198.51.100.9"}
+	ips, err = resolver.LookupIP(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.66", ips[0].String())
+}
+
+func TestResolver_Cache(t *testing.T) {
+	upstream := &fakeUpstream{address: "tls://upstream", ips: []string{"203.0.113.10"}, ttl: 120}
+	resolver := NewResolver([]Upstream{upstream}, zap.NewNop())
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return clock }
+
+	query := newQuery(t, 7, "example.com.", dnsmessage.TypeA)
+	_, err := resolver.Exchange(context.Background(), query)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), upstream.calls.Load())
+
+	// Ответ из кеша с уменьшенным TTL и идентификатором запроса
+	clock = clock.Add(100 * time.Second)
+	raw, err := resolver.Exchange(context.Background(), newQuery(t, 8, "EXAMPLE.com.", dnsmessage.TypeA))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), upstream.calls.Load())
+	var answer dnsmessage.Message
+	require.NoError(t, answer.Unpack(raw))
+	assert.Equal(t, uint16(8), answer.ID)
+	require.Len(t, answer.Answers, 1)
+	assert.Equal(t, uint32(20), answer.Answers[0].Header.TTL)
+
+	clock = clock.Add(20 * time.Second)
+	_, err = resolver.Exchange(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), upstream.calls.Load())
+}
+
+func TestResolver_Router(t *testing.T) {
+	first := &fakeUpstream{address: "tls://first", ips: []string{"203.0.113.1"}, ttl: 60}
+	second := &fakeUpstream{address: "https://second/dns-query", ips: []string{"203.0.113.2"}, ttl: 60}
+	resolver := NewResolver([]Upstream{first, second}, zap.NewNop())
+	resolver.SetRouter(func(name string) string {
+		if name == "routed.example" {
+			return second.Address()
+		}
+		return ""
+	})
+
+	ips, err := resolver.LookupIP(context.Background(), "routed.example")
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.2", ips[0].String())
+	assert.Zero(t, first.calls.Load())
+	assert.True(t, resolver.HasUpstream(second.Address()))
+	assert.False(t, resolver.HasUpstream("tls://unknown"))
+}
+
+func TestResolver_ExchangeErrors(t *testing.T) {
+	failing := &fakeUpstream{address: "tls://failing", err: errors.New("connection refused")}
+	resolver := NewResolver([]Upstream{failing}, zap.NewNop())
+
+	raw, err := resolver.Exchange(context.Background(), newQuery(t, 42, "example.com.", dnsmessage.TypeA))
+	require.NoError(t, err)
+	var answer dnsmessage.Message
+	require.NoError(t, answer.Unpack(raw))
+	assert.Equal(t, uint16(42), answer.ID)
+	assert.Equal(t, dnsmessage.RCodeServerFailure, answer.RCode)
+
+	_, err = resolver.Exchange(context.Background(), []byte{0x01})
+	assert.Error(t, err)
+
+	_, err = resolver.LookupIP(context.Background(), "example.com")
+	assert.Error(t, err)
+
+	ips, err := resolver.LookupIP(context.Background(), "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", ips[0].String())
+}
@@ -0,0 +1,226 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// tcpIdleTimeout закрывает простаивающие TCP соединения клиентов
+	tcpIdleTimeout = 10 * time.Second
+	// minUDPSize размер ответа UDP без EDNS (RFC 1035)
+	minUDPSize = 512
+	// maxUDPSize верхняя граница размера ответа UDP с EDNS
+	maxUDPSize = 4096
+)
+
+// Server локальный DNS сервер для клиентов VPN: принимает запросы по UDP и
+// TCP и разрешает их через резолвер. Без адреса не запускается.
+type Server struct {
+	resolver *Resolver
+	logger   *zap.Logger
+	config   *config.Config
+
+	mutex    sync.Mutex
+	packet   net.PacketConn
+	listener net.Listener
+	handlers sync.WaitGroup
+}
+
+// NewServer создает локальный DNS сервер
+func NewServer(resolver *Resolver, logger *zap.Logger, cfg *config.Config) *Server {
+	return &Server{
+		resolver: resolver,
+		logger:   logger,
+		config:   cfg,
+	}
+}
+
+// Start запускает DNS сервер
+func (s *Server) Start(ctx context.Context) error {
+	address := s.config.DNS.Address
+	if address == "" || s.resolver == nil {
+		s.logger.Info("dns server is disabled")
+		return nil
+	}
+
+	packet, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		packet.Close()
+		return err
+	}
+
+	s.logger.Info("dns server starting", zap.String("address", address))
+
+	go func() {
+		<-ctx.Done()
+		s.logger.Info("dns server shutting down")
+		s.close()
+	}()
+
+	s.serve(ctx, packet, listener)
+	return nil
+}
+
+// serve обслуживает запросы до закрытия сокетов
+func (s *Server) serve(ctx context.Context, packet net.PacketConn, listener net.Listener) {
+	s.mutex.Lock()
+	s.packet, s.listener = packet, listener
+	s.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.serveUDP(ctx, packet)
+	}()
+	go func() {
+		defer wg.Done()
+		s.serveTCP(ctx, listener)
+	}()
+	wg.Wait()
+}
+
+// Stop останавливает DNS сервер и ждет обработки принятых запросов
+func (s *Server) Stop(ctx context.Context) error {
+	s.close()
+
+	done := make(chan struct{})
+	go func() {
+		s.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Name возвращает имя сервиса
+func (s *Server) Name() string {
+	return "dns-server"
+}
+
+func (s *Server) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.packet != nil {
+		s.packet.Close()
+	}
+	if s.listener != nil {
+		s.listener.Close()
+	}
+}
+
+// serveUDP отвечает на датаграммы, ответ больше допустимого клиентом
+// урезается с флагом TC, чтобы клиент повторил запрос по TCP
+func (s *Server) serveUDP(ctx context.Context, packet net.PacketConn) {
+	buffer := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := packet.ReadFrom(buffer)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Error("failed to read dns query", zap.Error(err))
+			}
+			return
+		}
+		query := append([]byte(nil), buffer[:n]...)
+
+		s.handlers.Add(1)
+		go func() {
+			defer s.handlers.Done()
+
+			response, err := s.resolver.Exchange(ctx, query)
+			if err != nil {
+				s.logger.Debug("invalid dns query", zap.String("client", addr.String()), zap.Error(err))
+				return
+			}
+			if len(response) > udpSize(query) {
+				if response, err = truncate(response); err != nil {
+					return
+				}
+			}
+			if _, err := packet.WriteTo(response, addr); err != nil {
+				s.logger.Debug("failed to write dns response", zap.String("client", addr.String()), zap.Error(err))
+			}
+		}()
+	}
+}
+
+// serveTCP обслуживает соединения с сообщениями, разделенными префиксом длины
+func (s *Server) serveTCP(ctx context.Context, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Error("failed to accept dns connection", zap.Error(err))
+			}
+			return
+		}
+
+		s.handlers.Add(1)
+		go func() {
+			defer s.handlers.Done()
+			defer conn.Close()
+
+			for {
+				if err := conn.SetDeadline(time.Now().Add(tcpIdleTimeout)); err != nil {
+					return
+				}
+				query, err := readStreamMessage(conn)
+				if err != nil {
+					return
+				}
+				response, err := s.resolver.Exchange(ctx, query)
+				if err != nil {
+					s.logger.Debug("invalid dns query", zap.String("client", conn.RemoteAddr().String()), zap.Error(err))
+					return
+				}
+				if err := writeStreamMessage(conn, response); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// udpSize возвращает допустимый размер ответа по записи OPT запроса
+func udpSize(query []byte) int {
+	var message dnsmessage.Message
+	if err := message.Unpack(query); err != nil {
+		return minUDPSize
+	}
+	for _, resource := range message.Additionals {
+		if resource.Header.Type == dnsmessage.TypeOPT {
+			return min(max(int(resource.Header.Class), minUDPSize), maxUDPSize)
+		}
+	}
+	return minUDPSize
+}
+
+// truncate оставляет от ответа заголовок с флагом TC и вопрос
+func truncate(response []byte) ([]byte, error) {
+	var message dnsmessage.Message
+	if err := message.Unpack(response); err != nil {
+		return nil, err
+	}
+	message.Truncated = true
+	message.Answers = nil
+	message.Authorities = nil
+	message.Additionals = nil
+	return message.Pack()
+}
@@ -0,0 +1,100 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startTestServer запускает сервер на случайных портах UDP и TCP
+func startTestServer(t *testing.T, upstream Upstream) (*Server, string, string) {
+	t.Helper()
+
+	packet, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewServer(NewResolver([]Upstream{upstream}, zap.NewNop()), zap.NewNop(), &config.Config{})
+	go server.serve(context.Background(), packet, listener)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Stop(ctx)
+	})
+	return server, packet.LocalAddr().String(), listener.Addr().String()
+}
+
+func TestServer_UDP(t *testing.T) {
+	upstream := &fakeUpstream{address: "tls://upstream", ips: []string{"203.0.113.10"}, ttl: 60}
+	_, udpAddr, _ := startTestServer(t, upstream)
+
+	conn, err := net.Dial("udp", udpAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err = conn.Write(newQuery(t, 5, "example.com.", dnsmessage.TypeA))
+	require.NoError(t, err)
+	buffer := make([]byte, maxMessageSize)
+	n, err := conn.Read(buffer)
+	require.NoError(t, err)
+
+	var answer dnsmessage.Message
+	require.NoError(t, answer.Unpack(buffer[:n]))
+	assert.Equal(t, uint16(5), answer.ID)
+	require.Len(t, answer.Answers, 1)
+	assert.Equal(t, [4]byte{203, 0, 113, 10}, answer.Answers[0].Body.(*dnsmessage.AResource).A)
+}
+
+func TestServer_UDPTruncatesLargeAnswers(t *testing.T) {
+	ips := make([]string, 0, 40)
+	for i := 1; i <= 40; i++ {
+		ips = append(ips, fmt.Sprintf("203.0.113.%d", i))
+	}
+	upstream := &fakeUpstream{address: "tls://upstream", ips: ips, ttl: 60}
+	_, udpAddr, tcpAddr := startTestServer(t, upstream)
+
+	conn, err := net.Dial("udp", udpAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err = conn.Write(newQuery(t, 6, "large.example.", dnsmessage.TypeA))
+	require.NoError(t, err)
+	buffer := make([]byte, maxMessageSize)
+	n, err := conn.Read(buffer)
+	require.NoError(t, err)
+
+	var answer dnsmessage.Message
+	require.NoError(t, answer.Unpack(buffer[:n]))
+	assert.True(t, answer.Truncated)
+	assert.Empty(t, answer.Answers)
+
+	// Клиент повторяет запрос по TCP и получает ответ целиком
+	stream, err := net.Dial("tcp", tcpAddr)
+	require.NoError(t, err)
+	defer stream.Close()
+	require.NoError(t, stream.SetDeadline(time.Now().Add(5*time.Second)))
+
+	raw, err := exchangeStream(stream, newQuery(t, 7, "large.example.", dnsmessage.TypeA))
+	require.NoError(t, err)
+	require.NoError(t, answer.Unpack(raw))
+	assert.False(t, answer.Truncated)
+	assert.Len(t, answer.Answers, 40)
+}
+
+func TestServer_StartDisabled(t *testing.T) {
+	server := NewServer(nil, zap.NewNop(), &config.Config{})
+	assert.NoError(t, server.Start(context.Background()))
+	assert.NoError(t, server.Stop(context.Background()))
+	assert.Equal(t, "dns-server", server.Name())
+}
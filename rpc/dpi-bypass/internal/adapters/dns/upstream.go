@@ -0,0 +1,233 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// upstreamTimeout ограничивает подключение и обмен с сервером DNS
+	upstreamTimeout = 5 * time.Second
+	// maxMessageSize максимальный размер сообщения DNS
+	maxMessageSize = 65535
+	// defaultDoTPort порт DNS over TLS по умолчанию
+	defaultDoTPort = "853"
+)
+
+// Upstream зашифрованный сервер DNS
+type Upstream interface {
+	// Address адрес сервера: https://... для DoH, tls://host:port для DoT
+	Address() string
+	// Exchange отправляет запрос в формате DNS и возвращает ответ
+	Exchange(ctx context.Context, query []byte) ([]byte, error)
+}
+
+// ParseUpstream разбирает адрес сервера. tlsConfig задает доверенные
+// сертификаты; nil означает системные.
+func ParseUpstream(address string, tlsConfig *tls.Config) (Upstream, error) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dns upstream %q: %w", address, err)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("invalid dns upstream %q: missing host", address)
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return newDoHUpstream(address, tlsConfig), nil
+	case "tls":
+		host := parsed.Host
+		if parsed.Port() == "" {
+			host = net.JoinHostPort(parsed.Hostname(), defaultDoTPort)
+		}
+		return newDoTUpstream(address, host, parsed.Hostname(), tlsConfig), nil
+	default:
+		return nil, fmt.Errorf("unsupported dns upstream scheme: %s", parsed.Scheme)
+	}
+}
+
+// ParseUpstreams разбирает список адресов серверов
+func ParseUpstreams(addresses []string, tlsConfig *tls.Config) ([]Upstream, error) {
+	upstreams := make([]Upstream, 0, len(addresses))
+	for _, address := range addresses {
+		upstream, err := ParseUpstream(address, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		upstreams = append(upstreams, upstream)
+	}
+	return upstreams, nil
+}
+
+// dohUpstream сервер DNS over HTTPS (RFC 8484)
+type dohUpstream struct {
+	address string
+	client  *http.Client
+}
+
+func newDoHUpstream(address string, tlsConfig *tls.Config) *dohUpstream {
+	transport := &http.Transport{
+		TLSClientConfig:     tlsConfig.Clone(),
+		TLSHandshakeTimeout: upstreamTimeout,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        4,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &dohUpstream{
+		address: address,
+		client:  &http.Client{Transport: transport, Timeout: upstreamTimeout},
+	}
+}
+
+func (u *dohUpstream) Address() string {
+	return u.address
+}
+
+func (u *dohUpstream) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, u.address, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/dns-message")
+	request.Header.Set("Accept", "application/dns-message")
+
+	response, err := u.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("doh upstream %s: unexpected status %d", u.address, response.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxMessageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxMessageSize {
+		return nil, fmt.Errorf("doh upstream %s: response too large", u.address)
+	}
+	return body, nil
+}
+
+// dotUpstream сервер DNS over TLS (RFC 7858). Запросы выполняются по
+// очереди в одном соединении, которое открывается заново после ошибки.
+type dotUpstream struct {
+	address   string
+	host      string
+	tlsConfig *tls.Config
+	mutex     sync.Mutex
+	conn      net.Conn
+}
+
+func newDoTUpstream(address, host, serverName string, tlsConfig *tls.Config) *dotUpstream {
+	config := tlsConfig.Clone()
+	if config == nil {
+		config = &tls.Config{}
+	}
+	config.ServerName = serverName
+	return &dotUpstream{
+		address:   address,
+		host:      host,
+		tlsConfig: config,
+	}
+}
+
+func (u *dotUpstream) Address() string {
+	return u.address
+}
+
+func (u *dotUpstream) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	// Простаивающее соединение могло быть закрыто сервером: повторяем запрос
+	// в новом
+	if u.conn != nil {
+		if response, err := u.roundTrip(ctx, query); err == nil {
+			return response, nil
+		}
+		u.closeConn()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: upstreamTimeout},
+		Config:    u.tlsConfig,
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", u.host)
+	if err != nil {
+		return nil, err
+	}
+	u.conn = conn
+
+	response, err := u.roundTrip(ctx, query)
+	if err != nil {
+		u.closeConn()
+		return nil, err
+	}
+	return response, nil
+}
+
+// roundTrip отправляет запрос с префиксом длины и читает ответ
+func (u *dotUpstream) roundTrip(ctx context.Context, query []byte) ([]byte, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(upstreamTimeout)
+	}
+	if err := u.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	return exchangeStream(u.conn, query)
+}
+
+func (u *dotUpstream) closeConn() {
+	u.conn.Close()
+	u.conn = nil
+}
+
+// exchangeStream отправляет сообщение по потоку и читает ответ, оба с
+// префиксом длины в 2 байта
+func exchangeStream(conn io.ReadWriter, query []byte) ([]byte, error) {
+	if err := writeStreamMessage(conn, query); err != nil {
+		return nil, err
+	}
+	return readStreamMessage(conn)
+}
+
+// writeStreamMessage пишет сообщение DNS с префиксом длины одной записью
+func writeStreamMessage(w io.Writer, message []byte) error {
+	if len(message) > maxMessageSize {
+		return fmt.Errorf("dns message too large: %d bytes", len(message))
+	}
+	frame := make([]byte, 2+len(message))
+	binary.BigEndian.PutUint16(frame, uint16(len(message)))
+	copy(frame[2:], message)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readStreamMessage читает сообщение DNS с префиксом длины
+func readStreamMessage(r io.Reader) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	message := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
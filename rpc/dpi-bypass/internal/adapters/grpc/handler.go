@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"time"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/api/proto"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
@@ -209,6 +210,11 @@ func (h *DPIBypassHandler) GetBypassStats(ctx context.Context, req *proto.GetByp
 		return nil, status.Errorf(codes.Internal, "failed to get bypass stats: %v", err)
 	}
 
+	users := make([]*proto.UserStats, len(stats.Users))
+	for i, user := range stats.Users {
+		users[i] = h.domainUserStatsToProto(user)
+	}
+
 	return &proto.BypassStats{
 		Id:                     stats.ID,
 		ConfigId:               stats.ConfigID,
@@ -223,6 +229,7 @@ func (h *DPIBypassHandler) GetBypassStats(ctx context.Context, req *proto.GetByp
 		AverageLatency:         stats.AverageLatency,
 		StartTime:              timestamppb.New(stats.StartTime),
 		EndTime:                timestamppb.New(stats.EndTime),
+		Users:                  users,
 	}, nil
 }
 
@@ -231,11 +238,16 @@ func (h *DPIBypassHandler) GetBypassHistory(ctx context.Context, req *proto.GetB
 	h.logger.Debug("get bypass history requested", zap.String("config_id", req.ConfigId))
 
 	historyReq := &domain.BypassHistoryRequest{
-		ConfigID:  req.ConfigId,
-		StartTime: req.StartTime.AsTime(),
-		EndTime:   req.EndTime.AsTime(),
-		Limit:     int(req.Limit),
-		Offset:    int(req.Offset),
+		ConfigID: req.ConfigId,
+		Limit:    int(req.Limit),
+		Offset:   int(req.Offset),
+	}
+	// Незаданные границы не ограничивают выборку
+	if req.StartTime != nil {
+		historyReq.StartTime = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		historyReq.EndTime = req.EndTime.AsTime()
 	}
 
 	entries, total, err := h.dpiService.GetBypassHistory(ctx, historyReq)
@@ -267,6 +279,37 @@ func (h *DPIBypassHandler) GetBypassHistory(ctx context.Context, req *proto.GetB
 	}, nil
 }
 
+// GetCensorshipSignals возвращает сигналы цензуры по методам, целям и окнам
+func (h *DPIBypassHandler) GetCensorshipSignals(ctx context.Context, req *proto.GetCensorshipSignalsRequest) (*proto.GetCensorshipSignalsResponse, error) {
+	h.logger.Debug("get censorship signals requested",
+		zap.String("method", req.Method), zap.String("target", req.Target))
+
+	filters := &domain.CensorshipSignalFilters{
+		Method: domain.BypassMethod(req.Method),
+		Target: req.Target,
+		Window: time.Duration(req.WindowSeconds) * time.Second,
+	}
+	if req.StartTime != nil {
+		filters.StartTime = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		filters.EndTime = req.EndTime.AsTime()
+	}
+
+	signals, err := h.dpiService.GetCensorshipSignals(ctx, filters)
+	if err != nil {
+		h.logger.Error("failed to get censorship signals", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to get censorship signals: %v", err)
+	}
+
+	protoSignals := make([]*proto.CensorshipSignalStats, len(signals))
+	for i, stats := range signals {
+		protoSignals[i] = h.domainCensorshipSignalsToProto(stats)
+	}
+
+	return &proto.GetCensorshipSignalsResponse{Signals: protoSignals}, nil
+}
+
 // AddBypassRule добавляет правило обхода
 func (h *DPIBypassHandler) AddBypassRule(ctx context.Context, req *proto.AddBypassRuleRequest) (*proto.BypassRule, error) {
 	h.logger.Debug("add bypass rule requested", zap.String("config_id", req.ConfigId))
@@ -358,6 +401,168 @@ func (h *DPIBypassHandler) ListBypassRules(ctx context.Context, req *proto.ListB
 	}, nil
 }
 
+// AddBypassUser добавляет пользователя сервера обхода
+func (h *DPIBypassHandler) AddBypassUser(ctx context.Context, req *proto.AddBypassUserRequest) (*proto.BypassUser, error) {
+	h.logger.Debug("add bypass user requested", zap.String("config_id", req.ConfigId), zap.String("user_id", req.UserId))
+
+	user, err := h.dpiService.AddBypassUser(ctx, &domain.AddBypassUserRequest{
+		ConfigID:   req.ConfigId,
+		UserID:     req.UserId,
+		Credential: req.Credential,
+	})
+	if err != nil {
+		h.logger.Error("failed to add bypass user", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to add bypass user: %v", err)
+	}
+
+	return h.domainUserToProto(user), nil
+}
+
+// RevokeBypassUser отзывает пользователя сервера обхода
+func (h *DPIBypassHandler) RevokeBypassUser(ctx context.Context, req *proto.RevokeBypassUserRequest) (*proto.RevokeBypassUserResponse, error) {
+	h.logger.Debug("revoke bypass user requested", zap.String("config_id", req.ConfigId), zap.String("user_id", req.UserId))
+
+	if err := h.dpiService.RevokeBypassUser(ctx, req.ConfigId, req.UserId); err != nil {
+		h.logger.Error("failed to revoke bypass user", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to revoke bypass user: %v", err)
+	}
+
+	return &proto.RevokeBypassUserResponse{
+		Success: true,
+	}, nil
+}
+
+// ListBypassUsers получает список пользователей сервера обхода
+func (h *DPIBypassHandler) ListBypassUsers(ctx context.Context, req *proto.ListBypassUsersRequest) (*proto.ListBypassUsersResponse, error) {
+	h.logger.Debug("list bypass users requested", zap.String("config_id", req.ConfigId))
+
+	users, total, err := h.dpiService.ListBypassUsers(ctx, &domain.BypassUserFilters{
+		ConfigID:       req.ConfigId,
+		IncludeRevoked: req.IncludeRevoked,
+		Limit:          int(req.Limit),
+		Offset:         int(req.Offset),
+	})
+	if err != nil {
+		h.logger.Error("failed to list bypass users", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to list bypass users: %v", err)
+	}
+
+	protoUsers := make([]*proto.BypassUser, len(users))
+	for i, user := range users {
+		protoUsers[i] = h.domainUserToProto(user)
+	}
+
+	return &proto.ListBypassUsersResponse{
+		Users: protoUsers,
+		Total: int32(total),
+	}, nil
+}
+
+// GetShareLink возвращает ссылку для подключения пользователя к серверу
+func (h *DPIBypassHandler) GetShareLink(ctx context.Context, req *proto.GetShareLinkRequest) (*proto.ShareLink, error) {
+	h.logger.Debug("share link requested", zap.String("config_id", req.ConfigId), zap.String("user_id", req.UserId))
+
+	link, err := h.dpiService.GetShareLink(ctx, req.ConfigId, req.UserId)
+	if err != nil {
+		h.logger.Error("failed to get share link", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to get share link: %v", err)
+	}
+
+	return h.domainShareLinkToProto(link), nil
+}
+
+// CreateSubscriptionToken выпускает токен подписки пользователя
+func (h *DPIBypassHandler) CreateSubscriptionToken(ctx context.Context, req *proto.CreateSubscriptionTokenRequest) (*proto.SubscriptionToken, error) {
+	h.logger.Debug("create subscription token requested", zap.String("user_id", req.UserId))
+
+	token, err := h.dpiService.CreateSubscriptionToken(ctx, &domain.CreateSubscriptionTokenRequest{
+		UserID: req.UserId,
+	})
+	if err != nil {
+		h.logger.Error("failed to create subscription token", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to create subscription token: %v", err)
+	}
+
+	// Значение токена отдается один раз, при выпуске
+	protoToken := h.domainSubscriptionTokenToProto(token)
+	protoToken.Token = token.Token
+	return protoToken, nil
+}
+
+// RevokeSubscriptionToken отзывает токен подписки
+func (h *DPIBypassHandler) RevokeSubscriptionToken(ctx context.Context, req *proto.RevokeSubscriptionTokenRequest) (*proto.RevokeSubscriptionTokenResponse, error) {
+	h.logger.Debug("revoke subscription token requested", zap.String("id", req.Id))
+
+	if err := h.dpiService.RevokeSubscriptionToken(ctx, req.Id); err != nil {
+		h.logger.Error("failed to revoke subscription token", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to revoke subscription token: %v", err)
+	}
+
+	return &proto.RevokeSubscriptionTokenResponse{
+		Success: true,
+	}, nil
+}
+
+// ListSubscriptionTokens получает список токенов подписки
+func (h *DPIBypassHandler) ListSubscriptionTokens(ctx context.Context, req *proto.ListSubscriptionTokensRequest) (*proto.ListSubscriptionTokensResponse, error) {
+	h.logger.Debug("list subscription tokens requested", zap.String("user_id", req.UserId))
+
+	tokens, total, err := h.dpiService.ListSubscriptionTokens(ctx, &domain.SubscriptionTokenFilters{
+		UserID:         req.UserId,
+		IncludeRevoked: req.IncludeRevoked,
+		Limit:          int(req.Limit),
+		Offset:         int(req.Offset),
+	})
+	if err != nil {
+		h.logger.Error("failed to list subscription tokens", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to list subscription tokens: %v", err)
+	}
+
+	protoTokens := make([]*proto.SubscriptionToken, len(tokens))
+	for i, token := range tokens {
+		protoTokens[i] = h.domainSubscriptionTokenToProto(token)
+	}
+
+	return &proto.ListSubscriptionTokensResponse{
+		Tokens: protoTokens,
+		Total:  int32(total),
+	}, nil
+}
+
+// ListSubscriptionAccesses получает журнал обращений к подпискам
+func (h *DPIBypassHandler) ListSubscriptionAccesses(ctx context.Context, req *proto.ListSubscriptionAccessesRequest) (*proto.ListSubscriptionAccessesResponse, error) {
+	h.logger.Debug("list subscription accesses requested", zap.String("token_id", req.TokenId), zap.String("user_id", req.UserId))
+
+	accesses, total, err := h.dpiService.ListSubscriptionAccesses(ctx, &domain.SubscriptionAccessFilters{
+		TokenID: req.TokenId,
+		UserID:  req.UserId,
+		Limit:   int(req.Limit),
+		Offset:  int(req.Offset),
+	})
+	if err != nil {
+		h.logger.Error("failed to list subscription accesses", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to list subscription accesses: %v", err)
+	}
+
+	protoAccesses := make([]*proto.SubscriptionAccess, len(accesses))
+	for i, access := range accesses {
+		protoAccesses[i] = &proto.SubscriptionAccess{
+			Id:         access.ID,
+			TokenId:    access.TokenID,
+			UserId:     access.UserID,
+			Format:     string(access.Format),
+			RemoteAddr: access.RemoteAddr,
+			UserAgent:  access.UserAgent,
+			AccessedAt: timestamppb.New(access.AccessedAt),
+		}
+	}
+
+	return &proto.ListSubscriptionAccessesResponse{
+		Accesses: protoAccesses,
+		Total:    int32(total),
+	}, nil
+}
+
 // Helper methods for conversions
 
 func (h *DPIBypassHandler) convertBypassType(protoType proto.BypassType) domain.BypassType {
@@ -389,6 +594,8 @@ func (h *DPIBypassHandler) convertBypassMethod(protoMethod proto.BypassMethod) d
 		return domain.BypassMethodUDPFragment
 	case proto.BypassMethod_BYPASS_METHOD_PROXY_CHAIN:
 		return domain.BypassMethodProxyChain
+	case proto.BypassMethod_BYPASS_METHOD_AUTO:
+		return domain.BypassMethodAuto
 	default:
 		return domain.BypassMethod("")
 	}
@@ -464,6 +671,15 @@ func (h *DPIBypassHandler) domainConfigToProto(config *domain.BypassConfig) *pro
 		protoRules[i] = h.domainRuleToProto(rule)
 	}
 
+	protoReloads := make([]*proto.SessionReload, len(config.Reloads))
+	for i, reload := range config.Reloads {
+		protoReloads[i] = &proto.SessionReload{
+			SessionId: reload.SessionID,
+			Outcome:   h.convertReloadOutcomeToProto(reload.Outcome),
+			Message:   reload.Message,
+		}
+	}
+
 	return &proto.BypassConfig{
 		Id:          config.ID,
 		Name:        config.Name,
@@ -475,6 +691,20 @@ func (h *DPIBypassHandler) domainConfigToProto(config *domain.BypassConfig) *pro
 		Rules:       protoRules,
 		CreatedAt:   timestamppb.New(config.CreatedAt),
 		UpdatedAt:   timestamppb.New(config.UpdatedAt),
+		Reloads:     protoReloads,
+	}
+}
+
+func (h *DPIBypassHandler) convertReloadOutcomeToProto(outcome domain.ReloadOutcome) proto.ReloadOutcome {
+	switch outcome {
+	case domain.ReloadOutcomeApplied:
+		return proto.ReloadOutcome_RELOAD_OUTCOME_APPLIED
+	case domain.ReloadOutcomeRestarted:
+		return proto.ReloadOutcome_RELOAD_OUTCOME_RESTARTED
+	case domain.ReloadOutcomeFailed:
+		return proto.ReloadOutcome_RELOAD_OUTCOME_FAILED
+	default:
+		return proto.ReloadOutcome_RELOAD_OUTCOME_UNSPECIFIED
 	}
 }
 
@@ -494,6 +724,79 @@ func (h *DPIBypassHandler) domainRuleToProto(rule *domain.BypassRule) *proto.Byp
 	}
 }
 
+func (h *DPIBypassHandler) domainUserToProto(user *domain.BypassUser) *proto.BypassUser {
+	return &proto.BypassUser{
+		UserId:     user.ID,
+		ConfigId:   user.ConfigID,
+		Credential: user.Credential,
+		Revoked:    user.Revoked,
+		CreatedAt:  timestamppb.New(user.CreatedAt),
+		UpdatedAt:  timestamppb.New(user.UpdatedAt),
+	}
+}
+
+func (h *DPIBypassHandler) domainUserStatsToProto(stats *domain.UserStats) *proto.UserStats {
+	user := &proto.UserStats{
+		UserId:            stats.UserID,
+		BytesSent:         stats.BytesSent,
+		BytesReceived:     stats.BytesReceived,
+		Connections:       stats.Connections,
+		ActiveConnections: stats.ActiveConnections,
+		Revoked:           stats.Revoked,
+	}
+	// Пользователь без соединений не имеет времени активности
+	if !stats.LastActivity.IsZero() {
+		user.LastActivity = timestamppb.New(stats.LastActivity)
+	}
+	return user
+}
+
+func (h *DPIBypassHandler) domainShareLinkToProto(link *domain.ShareLink) *proto.ShareLink {
+	return &proto.ShareLink{
+		ConfigId:   link.ConfigID,
+		UserId:     link.UserID,
+		Method:     string(link.Method),
+		Name:       link.Name,
+		Server:     link.Server,
+		Port:       int32(link.Port),
+		Uri:        link.URI,
+		Cipher:     link.Cipher,
+		Password:   link.Password,
+		Uuid:       link.UUID,
+		Tls:        link.TLS,
+		ServerName: link.ServerName,
+	}
+}
+
+func (h *DPIBypassHandler) domainSubscriptionTokenToProto(token *domain.SubscriptionToken) *proto.SubscriptionToken {
+	protoToken := &proto.SubscriptionToken{
+		Id:        token.ID,
+		UserId:    token.UserID,
+		Revoked:   token.Revoked,
+		CreatedAt: timestamppb.New(token.CreatedAt),
+	}
+	// Токен без обращений не имеет времени последнего обращения
+	if !token.LastAccessedAt.IsZero() {
+		protoToken.LastAccessedAt = timestamppb.New(token.LastAccessedAt)
+	}
+	return protoToken
+}
+
+func (h *DPIBypassHandler) domainCensorshipSignalsToProto(stats *domain.CensorshipSignalStats) *proto.CensorshipSignalStats {
+	return &proto.CensorshipSignalStats{
+		Method:              string(stats.Method),
+		Target:              stats.Target,
+		WindowStart:         timestamppb.New(stats.WindowStart),
+		WindowEnd:           timestamppb.New(stats.WindowEnd),
+		Connections:         stats.Connections,
+		RstAfterClientHello: stats.RSTAfterClientHello,
+		TimeoutAfterSni:     stats.TimeoutAfterSNI,
+		DnsPoisoning:        stats.DNSPoisoning,
+		Throttling:          stats.Throttling,
+		Blocked:             stats.RSTAfterClientHello + stats.TimeoutAfterSNI + stats.DNSPoisoning,
+	}
+}
+
 func (h *DPIBypassHandler) convertBypassTypeToProto(domainType domain.BypassType) proto.BypassType {
 	switch domainType {
 	case domain.BypassTypeDomainFronting:
@@ -523,6 +826,8 @@ func (h *DPIBypassHandler) convertBypassMethodToProto(domainMethod domain.Bypass
 		return proto.BypassMethod_BYPASS_METHOD_UDP_FRAGMENT
 	case domain.BypassMethodProxyChain:
 		return proto.BypassMethod_BYPASS_METHOD_PROXY_CHAIN
+	case domain.BypassMethodAuto:
+		return proto.BypassMethod_BYPASS_METHOD_AUTO
 	default:
 		return proto.BypassMethod_BYPASS_METHOD_UNSPECIFIED
 	}
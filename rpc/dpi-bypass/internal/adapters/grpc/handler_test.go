@@ -13,6 +13,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestDPIBypassHandler_Health(t *testing.T) {
@@ -372,6 +373,195 @@ func TestDPIBypassHandler_GetBypassStats(t *testing.T) {
 	assert.Equal(t, expectedStats.SuccessRate, resp.SuccessRate)
 }
 
+func TestDPIBypassHandler_GetBypassStats_Users(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	handler := NewDPIBypassHandler(mockService, zap.NewNop())
+
+	lastActivity := time.Now()
+	mockService.EXPECT().
+		GetBypassStats(gomock.Any(), "session-123").
+		Return(&domain.BypassStats{
+			SessionID: "session-123",
+			Users: []*domain.UserStats{
+				{UserID: "user-1", BytesSent: 300, BytesReceived: 100, Connections: 2, ActiveConnections: 1, LastActivity: lastActivity},
+				{UserID: "user-2", Revoked: true},
+			},
+		}, nil)
+
+	resp, err := handler.GetBypassStats(context.Background(), &proto.GetBypassStatsRequest{SessionId: "session-123"})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Users, 2)
+	assert.Equal(t, "user-1", resp.Users[0].UserId)
+	assert.Equal(t, int64(300), resp.Users[0].BytesSent)
+	assert.Equal(t, int64(1), resp.Users[0].ActiveConnections)
+	assert.True(t, resp.Users[0].LastActivity.AsTime().Equal(lastActivity))
+	assert.True(t, resp.Users[1].Revoked)
+	assert.Nil(t, resp.Users[1].LastActivity)
+}
+
+func TestDPIBypassHandler_AddBypassUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	handler := NewDPIBypassHandler(mockService, zap.NewNop())
+
+	mockService.EXPECT().
+		AddBypassUser(gomock.Any(), &domain.AddBypassUserRequest{ConfigID: "config-123", UserID: "user-1"}).
+		Return(&domain.BypassUser{ID: "user-1", ConfigID: "config-123", Credential: "generated"}, nil)
+
+	resp, err := handler.AddBypassUser(context.Background(), &proto.AddBypassUserRequest{ConfigId: "config-123", UserId: "user-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", resp.UserId)
+	assert.Equal(t, "generated", resp.Credential)
+}
+
+func TestDPIBypassHandler_RevokeBypassUser_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	handler := NewDPIBypassHandler(mockService, zap.NewNop())
+
+	mockService.EXPECT().
+		RevokeBypassUser(gomock.Any(), "config-123", "missing").
+		Return(assert.AnError)
+
+	resp, err := handler.RevokeBypassUser(context.Background(), &proto.RevokeBypassUserRequest{ConfigId: "config-123", UserId: "missing"})
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestDPIBypassHandler_GetShareLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	handler := NewDPIBypassHandler(mockService, zap.NewNop())
+
+	mockService.EXPECT().
+		GetShareLink(gomock.Any(), "config-123", "user-1").
+		Return(&domain.ShareLink{
+			ConfigID: "config-123",
+			UserID:   "user-1",
+			Method:   domain.BypassMethodV2Ray,
+			Server:   "de.example.com",
+			Port:     443,
+			URI:      "vless://id@de.example.com:443?encryption=none",
+			UUID:     "id",
+			TLS:      true,
+		}, nil)
+
+	resp, err := handler.GetShareLink(context.Background(), &proto.GetShareLinkRequest{ConfigId: "config-123", UserId: "user-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v2ray", resp.Method)
+	assert.Equal(t, int32(443), resp.Port)
+	assert.Equal(t, "vless://id@de.example.com:443?encryption=none", resp.Uri)
+	assert.True(t, resp.Tls)
+}
+
+func TestDPIBypassHandler_CreateSubscriptionToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	handler := NewDPIBypassHandler(mockService, zap.NewNop())
+
+	mockService.EXPECT().
+		CreateSubscriptionToken(gomock.Any(), &domain.CreateSubscriptionTokenRequest{UserID: "user-1"}).
+		Return(&domain.SubscriptionToken{ID: "token-1", UserID: "user-1", Token: "secret", CreatedAt: time.Now()}, nil)
+
+	resp, err := handler.CreateSubscriptionToken(context.Background(), &proto.CreateSubscriptionTokenRequest{UserId: "user-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", resp.Id)
+	assert.Equal(t, "secret", resp.Token)
+	assert.Nil(t, resp.LastAccessedAt)
+}
+
+func TestDPIBypassHandler_ListSubscriptionTokens_OmitsToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	handler := NewDPIBypassHandler(mockService, zap.NewNop())
+
+	mockService.EXPECT().
+		ListSubscriptionTokens(gomock.Any(), &domain.SubscriptionTokenFilters{UserID: "user-1"}).
+		Return([]*domain.SubscriptionToken{{ID: "token-1", UserID: "user-1", Token: "secret", CreatedAt: time.Now()}}, 1, nil)
+
+	resp, err := handler.ListSubscriptionTokens(context.Background(), &proto.ListSubscriptionTokensRequest{UserId: "user-1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Tokens, 1)
+	assert.Equal(t, "token-1", resp.Tokens[0].Id)
+	assert.Empty(t, resp.Tokens[0].Token)
+}
+
+func TestDPIBypassHandler_RevokeSubscriptionToken_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	handler := NewDPIBypassHandler(mockService, zap.NewNop())
+
+	mockService.EXPECT().
+		RevokeSubscriptionToken(gomock.Any(), "missing").
+		Return(assert.AnError)
+
+	resp, err := handler.RevokeSubscriptionToken(context.Background(), &proto.RevokeSubscriptionTokenRequest{Id: "missing"})
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestDPIBypassHandler_GetCensorshipSignals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	handler := NewDPIBypassHandler(mockService, zap.NewNop())
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockService.EXPECT().
+		GetCensorshipSignals(gomock.Any(), &domain.CensorshipSignalFilters{
+			Method:    domain.BypassMethodTLSHandshake,
+			StartTime: start,
+			Window:    5 * time.Minute,
+		}).
+		Return([]*domain.CensorshipSignalStats{{
+			Method:              domain.BypassMethodTLSHandshake,
+			Target:              "example.com",
+			WindowStart:         start,
+			WindowEnd:           start.Add(5 * time.Minute),
+			Connections:         10,
+			RSTAfterClientHello: 3,
+			TimeoutAfterSNI:     2,
+			Throttling:          1,
+		}}, nil)
+
+	resp, err := handler.GetCensorshipSignals(context.Background(), &proto.GetCensorshipSignalsRequest{
+		Method:        "tls_handshake",
+		StartTime:     timestamppb.New(start),
+		WindowSeconds: 300,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Signals, 1)
+	assert.Equal(t, "example.com", resp.Signals[0].Target)
+	assert.Equal(t, int64(5), resp.Signals[0].Blocked)
+	assert.Equal(t, int64(1), resp.Signals[0].Throttling)
+}
+
 func TestDPIBypassHandler_DeleteBypassConfig(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -417,3 +607,35 @@ func TestDPIBypassHandler_DeleteBypassConfig_Error(t *testing.T) {
 	assert.Nil(t, resp)
 	assert.Equal(t, codes.Internal, status.Code(err))
 }
+
+func TestDPIBypassHandler_UpdateBypassConfig_Reloads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	logger := zap.NewNop()
+	handler := NewDPIBypassHandler(mockService, logger)
+
+	mockService.EXPECT().
+		UpdateBypassConfig(gomock.Any(), gomock.Any()).
+		Return(&domain.BypassConfig{
+			ID:     "test-id",
+			Method: domain.BypassMethodShadowsocks,
+			Reloads: []*domain.SessionReload{
+				{SessionID: "first", Outcome: domain.ReloadOutcomeApplied, Message: "applied"},
+				{SessionID: "second", Outcome: domain.ReloadOutcomeFailed, Message: "address already in use"},
+			},
+		}, nil)
+
+	resp, err := handler.UpdateBypassConfig(context.Background(), &proto.UpdateBypassConfigRequest{
+		Id:     "test-id",
+		Method: proto.BypassMethod_BYPASS_METHOD_PROXY_CHAIN,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Reloads, 2)
+	assert.Equal(t, "first", resp.Reloads[0].SessionId)
+	assert.Equal(t, proto.ReloadOutcome_RELOAD_OUTCOME_APPLIED, resp.Reloads[0].Outcome)
+	assert.Equal(t, proto.ReloadOutcome_RELOAD_OUTCOME_FAILED, resp.Reloads[1].Outcome)
+	assert.Equal(t, "address already in use", resp.Reloads[1].Message)
+}
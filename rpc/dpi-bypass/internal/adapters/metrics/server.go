@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
+	"go.uber.org/zap"
+)
+
+// readHeaderTimeout ограничивает чтение заголовков запроса метрик
+const readHeaderTimeout = 10 * time.Second
+
+// Server HTTP сервер метрик, которые забирает сервис аналитики
+type Server struct {
+	server     *http.Server
+	dpiService ports.DPIBypassService
+	logger     *zap.Logger
+	config     *config.Config
+}
+
+// bypassEffectivenessMetric метрика эффективности обхода в формате сервиса
+// аналитики
+type bypassEffectivenessMetric struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	Value         float64           `json:"value"`
+	Unit          string            `json:"unit"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Timestamp     time.Time         `json:"timestamp"`
+	BypassType    string            `json:"bypass_type"`
+	SuccessRate   float64           `json:"success_rate"`
+	Latency       int64             `json:"latency_ms"`
+	Throughput    float64           `json:"throughput_mbps"`
+	BlockedCount  int64             `json:"blocked_count"`
+	TotalAttempts int64             `json:"total_attempts"`
+}
+
+// NewServer создает новый HTTP сервер метрик
+func NewServer(
+	dpiService ports.DPIBypassService,
+	logger *zap.Logger,
+	cfg *config.Config,
+) *Server {
+	s := &Server{
+		dpiService: dpiService,
+		logger:     logger,
+		config:     cfg,
+	}
+	s.server = &http.Server{
+		Addr:              cfg.Metrics.Address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	return s
+}
+
+// Handler возвращает обработчик запросов метрик
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics/bypass-effectiveness", s.handleBypassEffectiveness)
+	return mux
+}
+
+// Start запускает HTTP сервер метрик
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Metrics.Address)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("metrics server starting", zap.String("address", s.config.Metrics.Address))
+
+	go func() {
+		<-ctx.Done()
+		s.logger.Info("metrics server shutting down")
+		_ = s.server.Close()
+	}()
+
+	if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop останавливает HTTP сервер метрик
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Name возвращает имя сервиса
+func (s *Server) Name() string {
+	return "metrics-server"
+}
+
+// handleBypassEffectiveness отдает сигналы цензуры за минуты, завершенные
+// с последнего доставленного ответа. Минуты подтверждаются только после
+// успешной отправки ответа, поэтому сорвавшийся опрос их не теряет.
+func (s *Server) handleBypassEffectiveness(w http.ResponseWriter, r *http.Request) {
+	until := time.Now()
+	signals, err := s.dpiService.ExportCensorshipSignals(r.Context(), until)
+	if err != nil {
+		s.logger.Error("failed to export censorship signals", zap.Error(err))
+		http.Error(w, "failed to export censorship signals", http.StatusInternalServerError)
+		return
+	}
+
+	metrics := make([]bypassEffectivenessMetric, 0, len(signals))
+	for _, stats := range signals {
+		metrics = append(metrics, effectivenessMetric(stats))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(metrics); err != nil {
+		s.logger.Error("failed to encode bypass effectiveness metrics", zap.Error(err))
+		return
+	}
+	if err := http.NewResponseController(w).Flush(); err != nil {
+		s.logger.Warn("failed to send bypass effectiveness metrics", zap.Error(err))
+		return
+	}
+
+	if err := s.dpiService.AcknowledgeCensorshipSignals(r.Context(), until); err != nil {
+		s.logger.Error("failed to acknowledge censorship signals", zap.Error(err))
+	}
+}
+
+// effectivenessMetric переводит агрегат сигналов в метрику аналитики.
+// Сброс, таймаут и подмена DNS считаются блокировками, замедление
+// передается только в тегах.
+func effectivenessMetric(stats *domain.CensorshipSignalStats) bypassEffectivenessMetric {
+	blocked := stats.RSTAfterClientHello + stats.TimeoutAfterSNI + stats.DNSPoisoning
+	successRate := 0.0
+	if stats.Connections > 0 {
+		successRate = float64(stats.Connections-blocked) / float64(stats.Connections)
+	}
+
+	return bypassEffectivenessMetric{
+		ID:          fmt.Sprintf("%s:%s:%d", stats.Method, stats.Target, stats.WindowStart.Unix()),
+		Name:        "bypass_effectiveness",
+		Type:        "gauge",
+		Value:       successRate,
+		Unit:        "ratio",
+		Timestamp:   stats.WindowEnd,
+		BypassType:  string(stats.Method),
+		SuccessRate: successRate,
+		Tags: map[string]string{
+			"target": stats.Target,
+			string(domain.CensorshipSignalRSTAfterClientHello): strconv.FormatInt(stats.RSTAfterClientHello, 10),
+			string(domain.CensorshipSignalTimeoutAfterSNI):     strconv.FormatInt(stats.TimeoutAfterSNI, 10),
+			string(domain.CensorshipSignalDNSPoisoning):        strconv.FormatInt(stats.DNSPoisoning, 10),
+			string(domain.CensorshipSignalThrottling):          strconv.FormatInt(stats.Throttling, 10),
+		},
+		BlockedCount:  blocked,
+		TotalAttempts: stats.Connections,
+	}
+}
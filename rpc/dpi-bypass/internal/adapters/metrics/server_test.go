@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/services/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestServer(t *testing.T) (*mocks.MockDPIBypassService, http.Handler) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	server := NewServer(mockService, zap.NewNop(), &config.Config{Metrics: config.MetricsConfig{Address: ":0"}})
+	return mockService, server.Handler()
+}
+
+func TestServer_BypassEffectiveness(t *testing.T) {
+	mockService, handler := newTestServer(t)
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var until time.Time
+	mockService.EXPECT().
+		ExportCensorshipSignals(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, value time.Time) ([]*domain.CensorshipSignalStats, error) {
+			until = value
+			return []*domain.CensorshipSignalStats{{
+				Method:              domain.BypassMethodTLSHandshake,
+				Target:              "example.com",
+				WindowStart:         start,
+				WindowEnd:           start.Add(time.Minute),
+				Connections:         10,
+				RSTAfterClientHello: 2,
+				TimeoutAfterSNI:     1,
+				DNSPoisoning:        1,
+				Throttling:          3,
+			}}, nil
+		})
+	// Подтверждается тот же момент, до которого выгружены минуты
+	mockService.EXPECT().
+		AcknowledgeCensorshipSignals(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, value time.Time) { assert.Equal(t, until, value) })
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/bypass-effectiveness", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var metrics []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &metrics))
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "tls_handshake", metrics[0]["bypass_type"])
+	assert.Equal(t, 0.6, metrics[0]["success_rate"])
+	assert.Equal(t, float64(4), metrics[0]["blocked_count"])
+	assert.Equal(t, float64(10), metrics[0]["total_attempts"])
+	assert.Equal(t, "2026-01-01T12:01:00Z", metrics[0]["timestamp"])
+
+	tags := metrics[0]["tags"].(map[string]interface{})
+	assert.Equal(t, "example.com", tags["target"])
+	assert.Equal(t, "3", tags["throttling"])
+}
+
+func TestServer_BypassEffectivenessEmpty(t *testing.T) {
+	mockService, handler := newTestServer(t)
+
+	mockService.EXPECT().ExportCensorshipSignals(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockService.EXPECT().AcknowledgeCensorshipSignals(gomock.Any(), gomock.Any()).Return(nil)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/bypass-effectiveness", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+}
+
+func TestServer_BypassEffectivenessError(t *testing.T) {
+	mockService, handler := newTestServer(t)
+
+	mockService.EXPECT().ExportCensorshipSignals(gomock.Any(), gomock.Any()).Return(nil, assert.AnError)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/bypass-effectiveness", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+// failingWriter ResponseWriter, запись в который не доходит до клиента
+type failingWriter struct {
+	header http.Header
+}
+
+func (w *failingWriter) Header() http.Header        { return w.header }
+func (w *failingWriter) Write([]byte) (int, error)  { return 0, assert.AnError }
+func (w *failingWriter) WriteHeader(statusCode int) {}
+func (w *failingWriter) Flush()                     {}
+
+func TestServer_BypassEffectivenessNotAcknowledgedOnWriteError(t *testing.T) {
+	mockService, handler := newTestServer(t)
+
+	// AcknowledgeCensorshipSignals не ожидается: минуты уйдут в следующий опрос
+	mockService.EXPECT().
+		ExportCensorshipSignals(gomock.Any(), gomock.Any()).
+		Return([]*domain.CensorshipSignalStats{{Method: domain.BypassMethodTLSHandshake, Connections: 1}}, nil)
+
+	handler.ServeHTTP(&failingWriter{header: http.Header{}}, httptest.NewRequest(http.MethodGet, "/metrics/bypass-effectiveness", nil))
+}
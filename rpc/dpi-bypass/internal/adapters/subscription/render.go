@@ -0,0 +1,179 @@
+package subscription
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// proxyGroup группа выбора сервера в профилях
+	proxyGroup = "Silence"
+	// localProxyPort порт локального прокси клиента в профилях
+	localProxyPort = 7890
+)
+
+// renderBase64 список ссылок в base64, по ссылке в строке
+func renderBase64(links []*domain.ShareLink) []byte {
+	uris := make([]string, len(links))
+	for i, link := range links {
+		uris[i] = link.URI
+	}
+	return []byte(base64.StdEncoding.EncodeToString([]byte(strings.Join(uris, "\n"))))
+}
+
+type clashProfile struct {
+	MixedPort   int          `yaml:"mixed-port"`
+	Mode        string       `yaml:"mode"`
+	Proxies     []clashProxy `yaml:"proxies"`
+	ProxyGroups []clashGroup `yaml:"proxy-groups"`
+	Rules       []string     `yaml:"rules"`
+}
+
+type clashProxy struct {
+	Name       string `yaml:"name"`
+	Type       string `yaml:"type"`
+	Server     string `yaml:"server"`
+	Port       int    `yaml:"port"`
+	Cipher     string `yaml:"cipher,omitempty"`
+	Password   string `yaml:"password,omitempty"`
+	UUID       string `yaml:"uuid,omitempty"`
+	Network    string `yaml:"network,omitempty"`
+	TLS        bool   `yaml:"tls,omitempty"`
+	ServerName string `yaml:"servername,omitempty"`
+}
+
+type clashGroup struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	Proxies []string `yaml:"proxies"`
+}
+
+// renderClash профиль Clash (Mihomo)
+func renderClash(links []*domain.ShareLink) ([]byte, error) {
+	profile := clashProfile{
+		MixedPort: localProxyPort,
+		Mode:      "rule",
+		Proxies:   []clashProxy{},
+		Rules:     []string{"MATCH," + proxyGroup},
+	}
+	names := []string{}
+	for _, link := range supportedLinks(links) {
+		proxy := clashProxy{Name: link.Name, Server: link.Server, Port: link.Port}
+		switch link.Method {
+		case domain.BypassMethodShadowsocks:
+			proxy.Type = "ss"
+			proxy.Cipher = link.Cipher
+			proxy.Password = link.Password
+		case domain.BypassMethodV2Ray:
+			proxy.Type = "vless"
+			proxy.UUID = link.UUID
+			proxy.Network = "tcp"
+			proxy.TLS = link.TLS
+			proxy.ServerName = link.ServerName
+		}
+		profile.Proxies = append(profile.Proxies, proxy)
+		names = append(names, proxy.Name)
+	}
+	if len(names) == 0 {
+		names = []string{"DIRECT"}
+	}
+	profile.ProxyGroups = []clashGroup{{Name: proxyGroup, Type: "select", Proxies: names}}
+
+	return yaml.Marshal(profile)
+}
+
+type singBoxProfile struct {
+	Inbounds  []singBoxInbound  `json:"inbounds"`
+	Outbounds []singBoxOutbound `json:"outbounds"`
+	Route     singBoxRoute      `json:"route"`
+}
+
+type singBoxInbound struct {
+	Type       string `json:"type"`
+	Tag        string `json:"tag"`
+	Listen     string `json:"listen"`
+	ListenPort int    `json:"listen_port"`
+}
+
+type singBoxOutbound struct {
+	Type       string      `json:"type"`
+	Tag        string      `json:"tag"`
+	Server     string      `json:"server,omitempty"`
+	ServerPort int         `json:"server_port,omitempty"`
+	Method     string      `json:"method,omitempty"`
+	Password   string      `json:"password,omitempty"`
+	Network    string      `json:"network,omitempty"`
+	UUID       string      `json:"uuid,omitempty"`
+	TLS        *singBoxTLS `json:"tls,omitempty"`
+	Outbounds  []string    `json:"outbounds,omitempty"`
+}
+
+type singBoxTLS struct {
+	Enabled    bool   `json:"enabled"`
+	ServerName string `json:"server_name,omitempty"`
+}
+
+type singBoxRoute struct {
+	Final string `json:"final"`
+}
+
+// renderSingBox профиль sing-box. UDP Shadowsocks сервер не принимает,
+// поэтому outbound ограничен TCP.
+func renderSingBox(links []*domain.ShareLink) ([]byte, error) {
+	profile := singBoxProfile{
+		Inbounds: []singBoxInbound{{Type: "mixed", Tag: "mixed-in", Listen: "127.0.0.1", ListenPort: localProxyPort}},
+		Route:    singBoxRoute{Final: proxyGroup},
+	}
+	tags := []string{}
+	for _, link := range supportedLinks(links) {
+		outbound := singBoxOutbound{Tag: link.Name, Server: link.Server, ServerPort: link.Port}
+		switch link.Method {
+		case domain.BypassMethodShadowsocks:
+			outbound.Type = "shadowsocks"
+			outbound.Method = link.Cipher
+			outbound.Password = link.Password
+			outbound.Network = "tcp"
+		case domain.BypassMethodV2Ray:
+			outbound.Type = "vless"
+			outbound.UUID = link.UUID
+			if link.TLS {
+				outbound.TLS = &singBoxTLS{Enabled: true, ServerName: link.ServerName}
+			}
+		}
+		profile.Outbounds = append(profile.Outbounds, outbound)
+		tags = append(tags, outbound.Tag)
+	}
+	if len(tags) == 0 {
+		tags = []string{"direct"}
+	}
+	profile.Outbounds = append(profile.Outbounds,
+		singBoxOutbound{Type: "selector", Tag: proxyGroup, Outbounds: tags},
+		singBoxOutbound{Type: "direct", Tag: "direct"},
+	)
+
+	return json.MarshalIndent(profile, "", "  ")
+}
+
+// supportedLinks ссылки Shadowsocks и VLESS с уникальными именами: имя
+// прокси в профилях служит ключом
+func supportedLinks(links []*domain.ShareLink) []*domain.ShareLink {
+	taken := map[string]bool{}
+	supported := []*domain.ShareLink{}
+	for _, link := range links {
+		if link.Method != domain.BypassMethodShadowsocks && link.Method != domain.BypassMethodV2Ray {
+			continue
+		}
+		unique := *link
+		for i := 2; taken[unique.Name]; i++ {
+			unique.Name = link.Name + " " + strconv.Itoa(i)
+		}
+		taken[unique.Name] = true
+		supported = append(supported, &unique)
+	}
+	return supported
+}
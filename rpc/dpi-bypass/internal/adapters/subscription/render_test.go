@@ -0,0 +1,297 @@
+package subscription
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	testPassword = "AAECAwQFBgcICQoLDA0ODw==:EBESExQVFhcYGRobHB0eHw=="
+	testUUID     = "b831381d-6324-4d53-ad4f-8cda48b30811"
+)
+
+func testLinks() []*domain.ShareLink {
+	return []*domain.ShareLink{
+		{
+			Method:   domain.BypassMethodShadowsocks,
+			Name:     "Frankfurt",
+			Server:   "de.example.com",
+			Port:     8388,
+			URI:      "ss://2022-blake3-aes-128-gcm:AAECAwQFBgcICQoLDA0ODw==%3AEBESExQVFhcYGRobHB0eHw==@de.example.com:8388#Frankfurt",
+			Cipher:   "2022-blake3-aes-128-gcm",
+			Password: testPassword,
+		},
+		{
+			Method:     domain.BypassMethodV2Ray,
+			Name:       "Frankfurt",
+			Server:     "de.example.com",
+			Port:       443,
+			URI:        "vless://" + testUUID + "@de.example.com:443?encryption=none&security=tls&sni=de.example.com&type=tcp#Frankfurt",
+			UUID:       testUUID,
+			TLS:        true,
+			ServerName: "de.example.com",
+		},
+	}
+}
+
+// Схемы клиентов: методы Shadowsocks 2022 с размером PSK, которые
+// принимают Mihomo и sing-box
+var (
+	clientShadowsocksCiphers = map[string]int{
+		"2022-blake3-aes-128-gcm":       16,
+		"2022-blake3-aes-256-gcm":       32,
+		"2022-blake3-chacha20-poly1305": 32,
+	}
+	uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// checkClientPassword проверяет пароль Shadowsocks 2022: PSK в base64
+// размера метода, через двоеточие для нескольких ключей
+func checkClientPassword(t *testing.T, cipher, password string) {
+	t.Helper()
+
+	size, ok := clientShadowsocksCiphers[cipher]
+	require.True(t, ok, "unsupported cipher %q", cipher)
+	for _, part := range strings.Split(password, ":") {
+		key, err := base64.StdEncoding.DecodeString(part)
+		require.NoError(t, err, "password part %q", part)
+		assert.Len(t, key, size, "password part %q", part)
+	}
+}
+
+func checkPort(t *testing.T, port int) {
+	t.Helper()
+	assert.True(t, port > 0 && port < 65536, "invalid port %d", port)
+}
+
+// validateClashProfile проверяет профиль по схеме конфигурации Mihomo
+func validateClashProfile(t *testing.T, body []byte) {
+	t.Helper()
+
+	var profile struct {
+		MixedPort   int              `yaml:"mixed-port"`
+		Mode        string           `yaml:"mode"`
+		Proxies     []map[string]any `yaml:"proxies"`
+		ProxyGroups []map[string]any `yaml:"proxy-groups"`
+		Rules       []string         `yaml:"rules"`
+		Extra       map[string]any   `yaml:",inline"`
+	}
+	require.NoError(t, yaml.Unmarshal(body, &profile))
+	assert.Empty(t, profile.Extra, "unknown top-level keys")
+	checkPort(t, profile.MixedPort)
+	assert.Contains(t, []string{"rule", "global", "direct"}, profile.Mode)
+
+	allowed := map[string][]string{
+		"ss":    {"name", "type", "server", "port", "cipher", "password", "udp"},
+		"vless": {"name", "type", "server", "port", "uuid", "network", "tls", "servername", "flow", "udp"},
+	}
+	names := map[string]bool{"DIRECT": true, "REJECT": true}
+	for _, proxy := range profile.Proxies {
+		proxyType, _ := proxy["type"].(string)
+		keys, ok := allowed[proxyType]
+		require.True(t, ok, "unsupported proxy type %q", proxyType)
+		for key := range proxy {
+			assert.Contains(t, keys, key, "unknown key of %s proxy", proxyType)
+		}
+
+		name, _ := proxy["name"].(string)
+		require.NotEmpty(t, name)
+		assert.False(t, names[name], "duplicate proxy name %q", name)
+		names[name] = true
+		server, _ := proxy["server"].(string)
+		assert.NotEmpty(t, server)
+		port, _ := proxy["port"].(int)
+		checkPort(t, port)
+
+		switch proxyType {
+		case "ss":
+			cipher, _ := proxy["cipher"].(string)
+			password, _ := proxy["password"].(string)
+			checkClientPassword(t, cipher, password)
+		case "vless":
+			uuid, _ := proxy["uuid"].(string)
+			assert.Regexp(t, uuidPattern, uuid)
+			assert.Contains(t, []any{nil, "tcp", "ws", "http", "h2", "grpc"}, proxy["network"])
+			if proxy["tls"] == true {
+				assert.NotEmpty(t, proxy["servername"])
+			}
+		}
+	}
+
+	groups := map[string]bool{}
+	for _, group := range profile.ProxyGroups {
+		name, _ := group["name"].(string)
+		require.NotEmpty(t, name)
+		groups[name] = true
+		assert.Contains(t, []any{"select", "url-test", "fallback", "load-balance"}, group["type"])
+		proxies, _ := group["proxies"].([]any)
+		require.NotEmpty(t, proxies)
+		for _, proxy := range proxies {
+			assert.True(t, names[proxy.(string)], "group %s references unknown proxy %v", name, proxy)
+		}
+	}
+
+	require.NotEmpty(t, profile.Rules)
+	last := strings.Split(profile.Rules[len(profile.Rules)-1], ",")
+	require.Len(t, last, 2)
+	assert.Equal(t, "MATCH", last[0])
+	assert.True(t, groups[last[1]] || names[last[1]], "MATCH references unknown target %s", last[1])
+}
+
+// validateSingBoxProfile проверяет профиль по схеме конфигурации sing-box
+func validateSingBoxProfile(t *testing.T, body []byte) {
+	t.Helper()
+
+	var profile struct {
+		Inbounds  []map[string]any `json:"inbounds"`
+		Outbounds []map[string]any `json:"outbounds"`
+		Route     struct {
+			Final string `json:"final"`
+		} `json:"route"`
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(body)))
+	decoder.DisallowUnknownFields()
+	require.NoError(t, decoder.Decode(&profile))
+
+	require.NotEmpty(t, profile.Inbounds)
+	for _, inbound := range profile.Inbounds {
+		assert.Equal(t, "mixed", inbound["type"])
+		assert.NotEmpty(t, inbound["listen"])
+		port, _ := inbound["listen_port"].(float64)
+		checkPort(t, int(port))
+	}
+
+	allowed := map[string][]string{
+		"shadowsocks": {"type", "tag", "server", "server_port", "method", "password", "network"},
+		"vless":       {"type", "tag", "server", "server_port", "uuid", "tls", "network"},
+		"selector":    {"type", "tag", "outbounds", "default"},
+		"direct":      {"type", "tag"},
+	}
+	tags := map[string]bool{}
+	for _, outbound := range profile.Outbounds {
+		outboundType, _ := outbound["type"].(string)
+		keys, ok := allowed[outboundType]
+		require.True(t, ok, "unsupported outbound type %q", outboundType)
+		for key := range outbound {
+			assert.Contains(t, keys, key, "unknown key of %s outbound", outboundType)
+		}
+		tag, _ := outbound["tag"].(string)
+		require.NotEmpty(t, tag)
+		assert.False(t, tags[tag], "duplicate outbound tag %q", tag)
+		tags[tag] = true
+
+		switch outboundType {
+		case "shadowsocks", "vless":
+			assert.NotEmpty(t, outbound["server"])
+			port, _ := outbound["server_port"].(float64)
+			checkPort(t, int(port))
+			assert.Contains(t, []any{nil, "tcp", "udp"}, outbound["network"])
+		}
+		switch outboundType {
+		case "shadowsocks":
+			method, _ := outbound["method"].(string)
+			password, _ := outbound["password"].(string)
+			checkClientPassword(t, method, password)
+		case "vless":
+			uuid, _ := outbound["uuid"].(string)
+			assert.Regexp(t, uuidPattern, uuid)
+			if tls, ok := outbound["tls"].(map[string]any); ok {
+				for key := range tls {
+					assert.Contains(t, []string{"enabled", "server_name", "insecure"}, key)
+				}
+				assert.NotEmpty(t, tls["server_name"])
+			}
+		}
+	}
+
+	for _, outbound := range profile.Outbounds {
+		if outbound["type"] != "selector" {
+			continue
+		}
+		members, _ := outbound["outbounds"].([]any)
+		require.NotEmpty(t, members)
+		for _, member := range members {
+			assert.True(t, tags[member.(string)], "selector references unknown outbound %v", member)
+		}
+	}
+	assert.True(t, tags[profile.Route.Final], "route.final references unknown outbound %s", profile.Route.Final)
+}
+
+func TestRenderBase64(t *testing.T) {
+	body, err := base64.StdEncoding.DecodeString(string(renderBase64(testLinks())))
+	require.NoError(t, err)
+
+	lines := strings.Split(string(body), "\n")
+	require.Len(t, lines, 2)
+
+	// SIP002: метод и пароль 2022 в userinfo без base64
+	ss, err := url.Parse(lines[0])
+	require.NoError(t, err)
+	assert.Equal(t, "ss", ss.Scheme)
+	password, _ := ss.User.Password()
+	checkClientPassword(t, ss.User.Username(), password)
+	assert.Equal(t, "de.example.com:8388", ss.Host)
+
+	vless, err := url.Parse(lines[1])
+	require.NoError(t, err)
+	assert.Equal(t, "vless", vless.Scheme)
+	assert.Regexp(t, uuidPattern, vless.User.Username())
+	assert.Equal(t, "none", vless.Query().Get("encryption"))
+	assert.Equal(t, "tls", vless.Query().Get("security"))
+}
+
+func TestRenderClash(t *testing.T) {
+	body, err := renderClash(testLinks())
+	require.NoError(t, err)
+	validateClashProfile(t, body)
+
+	var profile clashProfile
+	require.NoError(t, yaml.Unmarshal(body, &profile))
+	require.Len(t, profile.Proxies, 2)
+	assert.Equal(t, clashProxy{
+		Name: "Frankfurt", Type: "ss", Server: "de.example.com", Port: 8388,
+		Cipher: "2022-blake3-aes-128-gcm", Password: testPassword,
+	}, profile.Proxies[0])
+	assert.Equal(t, "Frankfurt 2", profile.Proxies[1].Name)
+	assert.Equal(t, "vless", profile.Proxies[1].Type)
+	assert.True(t, profile.Proxies[1].TLS)
+	assert.Equal(t, []string{"Frankfurt", "Frankfurt 2"}, profile.ProxyGroups[0].Proxies)
+	assert.Equal(t, []string{"MATCH,Silence"}, profile.Rules)
+
+	// Пустая подписка остается валидным профилем
+	body, err = renderClash(nil)
+	require.NoError(t, err)
+	validateClashProfile(t, body)
+	require.NoError(t, yaml.Unmarshal(body, &profile))
+	assert.Equal(t, []string{"DIRECT"}, profile.ProxyGroups[0].Proxies)
+}
+
+func TestRenderSingBox(t *testing.T) {
+	body, err := renderSingBox(testLinks())
+	require.NoError(t, err)
+	validateSingBoxProfile(t, body)
+
+	var profile singBoxProfile
+	require.NoError(t, json.Unmarshal(body, &profile))
+	require.Len(t, profile.Outbounds, 4)
+	assert.Equal(t, "shadowsocks", profile.Outbounds[0].Type)
+	assert.Equal(t, "2022-blake3-aes-128-gcm", profile.Outbounds[0].Method)
+	assert.Equal(t, "tcp", profile.Outbounds[0].Network)
+	assert.Equal(t, "vless", profile.Outbounds[1].Type)
+	assert.Equal(t, &singBoxTLS{Enabled: true, ServerName: "de.example.com"}, profile.Outbounds[1].TLS)
+	assert.Equal(t, singBoxOutbound{Type: "selector", Tag: "Silence", Outbounds: []string{"Frankfurt", "Frankfurt 2"}}, profile.Outbounds[2])
+	assert.Equal(t, "Silence", profile.Route.Final)
+
+	body, err = renderSingBox(nil)
+	require.NoError(t, err)
+	validateSingBoxProfile(t, body)
+}
@@ -0,0 +1,140 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
+	"go.uber.org/zap"
+)
+
+// readHeaderTimeout ограничивает чтение заголовков запроса подписки
+const readHeaderTimeout = 10 * time.Second
+
+// Server HTTP сервер подписок: выдает ссылки пользователя по токену
+type Server struct {
+	server     *http.Server
+	dpiService ports.DPIBypassService
+	logger     *zap.Logger
+	config     *config.Config
+}
+
+// NewServer создает новый HTTP сервер подписок
+func NewServer(
+	dpiService ports.DPIBypassService,
+	logger *zap.Logger,
+	cfg *config.Config,
+) *Server {
+	s := &Server{
+		dpiService: dpiService,
+		logger:     logger,
+		config:     cfg,
+	}
+	s.server = &http.Server{
+		Addr:              cfg.Subscription.Address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	return s
+}
+
+// Handler возвращает обработчик запросов подписки
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sub/{token}", s.handleSubscription)
+	return mux
+}
+
+// Start запускает HTTP сервер подписок
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Subscription.Address)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("subscription server starting", zap.String("address", s.config.Subscription.Address))
+
+	go func() {
+		<-ctx.Done()
+		s.logger.Info("subscription server shutting down")
+		_ = s.server.Close()
+	}()
+
+	if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop останавливает HTTP сервер подписок
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Name возвращает имя сервиса
+func (s *Server) Name() string {
+	return "subscription-server"
+}
+
+// handleSubscription отдает подписку в формате из параметра format:
+// base64 (по умолчанию), clash или singbox
+func (s *Server) handleSubscription(w http.ResponseWriter, r *http.Request) {
+	format := domain.SubscriptionFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = domain.SubscriptionFormatBase64
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		http.Error(w, "unknown subscription format", http.StatusBadRequest)
+		return
+	}
+
+	remoteAddr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteAddr = r.RemoteAddr
+	}
+	subscription, err := s.dpiService.GetSubscription(r.Context(), r.PathValue("token"), &domain.SubscriptionAccess{
+		Format:     format,
+		RemoteAddr: remoteAddr,
+		UserAgent:  r.UserAgent(),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		s.logger.Error("failed to get subscription", zap.Error(err))
+		http.Error(w, "failed to get subscription", http.StatusInternalServerError)
+		return
+	}
+
+	var body []byte
+	switch format {
+	case domain.SubscriptionFormatClash:
+		body, err = renderClash(subscription.Links)
+	case domain.SubscriptionFormatSingBox:
+		body, err = renderSingBox(subscription.Links)
+	default:
+		body = renderBase64(subscription.Links)
+	}
+	if err != nil {
+		s.logger.Error("failed to render subscription", zap.String("format", string(format)), zap.Error(err))
+		http.Error(w, "failed to render subscription", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(body)
+}
+
+var contentTypes = map[domain.SubscriptionFormat]string{
+	domain.SubscriptionFormatBase64:  "text/plain; charset=utf-8",
+	domain.SubscriptionFormatClash:   "text/yaml; charset=utf-8",
+	domain.SubscriptionFormatSingBox: "application/json",
+}
@@ -0,0 +1,95 @@
+package subscription
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/services/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestServer(t *testing.T) (*mocks.MockDPIBypassService, http.Handler) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockDPIBypassService(ctrl)
+	server := NewServer(mockService, zap.NewNop(), &config.Config{Subscription: config.SubscriptionConfig{Address: ":0"}})
+	return mockService, server.Handler()
+}
+
+func TestServer_Subscription(t *testing.T) {
+	mockService, handler := newTestServer(t)
+
+	mockService.EXPECT().
+		GetSubscription(gomock.Any(), "secret", &domain.SubscriptionAccess{
+			Format:     domain.SubscriptionFormatBase64,
+			RemoteAddr: "203.0.113.7",
+			UserAgent:  "v2rayN/6.0",
+		}).
+		Return(&domain.Subscription{UserID: "user-1", Links: testLinks()}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/sub/secret", nil)
+	req.RemoteAddr = "203.0.113.7:50000"
+	req.Header.Set("User-Agent", "v2rayN/6.0")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	body, err := base64.StdEncoding.DecodeString(rec.Body.String())
+	require.NoError(t, err)
+	assert.Contains(t, string(body), testLinks()[0].URI)
+}
+
+func TestServer_SubscriptionFormats(t *testing.T) {
+	mockService, handler := newTestServer(t)
+
+	mockService.EXPECT().
+		GetSubscription(gomock.Any(), "secret", gomock.Any()).
+		Return(&domain.Subscription{UserID: "user-1", Links: testLinks()}, nil).
+		Times(2)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sub/secret?format=clash", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/yaml; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sub/secret?format=singbox", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
+
+func TestServer_SubscriptionErrors(t *testing.T) {
+	mockService, handler := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sub/secret?format=surge", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	mockService.EXPECT().
+		GetSubscription(gomock.Any(), "revoked", gomock.Any()).
+		Return(nil, domain.ErrSubscriptionNotFound)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sub/revoked", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	mockService.EXPECT().
+		GetSubscription(gomock.Any(), "secret", gomock.Any()).
+		Return(nil, assert.AnError)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sub/secret", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sub/secret", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
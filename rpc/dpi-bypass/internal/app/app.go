@@ -5,8 +5,10 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/svc"
 	"github.com/par1ram/silence/shared/logger"
 	"go.uber.org/zap"
@@ -40,7 +42,25 @@ func (a *App) AddService(service Service) {
 	a.services = append(a.services, service)
 }
 
-const defaultShutdownTimeout = 30 // секунд
+const defaultShutdownTimeout = 30 * time.Second
+
+// sessionsService останавливает сессии обхода при завершении приложения,
+// чтобы соединения завершились, а сессии попали в историю
+type sessionsService struct {
+	bypass ports.DPIBypassService
+}
+
+func (s *sessionsService) Start(ctx context.Context) error {
+	return nil
+}
+
+func (s *sessionsService) Stop(ctx context.Context) error {
+	return s.bypass.StopAllBypass(ctx)
+}
+
+func (s *sessionsService) Name() string {
+	return "bypass-sessions"
+}
 
 // Run запускает приложение DPI Bypass
 func Run() {
@@ -55,11 +75,31 @@ func Run() {
 	app := NewApp(cfg, logger)
 
 	// Создаем контекст сервиса
-	svcCtx := svc.NewServiceContext(cfg, logger)
+	svcCtx, err := svc.NewServiceContext(cfg, logger)
+	if err != nil {
+		logger.Fatal("failed to create service context", zap.Error(err))
+	}
+	defer func() {
+		if err := svcCtx.Close(); err != nil {
+			logger.Error("failed to close database", zap.Error(err))
+		}
+	}()
 
 	// Добавляем gRPC сервер
 	app.AddService(svcCtx.GRPCServer)
 
+	// Останавливаем сессии после gRPC сервера, чтобы не запускались новые
+	app.AddService(&sessionsService{bypass: svcCtx.BypassService})
+
+	// Добавляем HTTP сервер подписок
+	app.AddService(svcCtx.SubscriptionServer)
+
+	// Добавляем HTTP сервер метрик для аналитики
+	app.AddService(svcCtx.MetricsServer)
+
+	// Добавляем локальный DNS сервер для клиентов VPN
+	app.AddService(svcCtx.DNSServer)
+
 	// Запускаем приложение
 	app.run()
 }
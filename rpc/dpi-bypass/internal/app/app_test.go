@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/services/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -90,3 +92,17 @@ func TestRun(t *testing.T) {
 	assert.True(t, service1.Started)
 	assert.True(t, service2.Started)
 }
+
+func TestSessionsService_Stop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	bypass := mocks.NewMockDPIBypassService(ctrl)
+	service := &sessionsService{bypass: bypass}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	bypass.EXPECT().StopAllBypass(ctx).Return(nil)
+
+	assert.NoError(t, service.Start(ctx))
+	assert.NoError(t, service.Stop(ctx))
+	assert.Equal(t, "bypass-sessions", service.Name())
+}
@@ -2,18 +2,61 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 )
 
+// defaultDNSUpstreams серверы DoH и DoT, заданные адресами, чтобы их не
+// нужно было разрешать системным резолвером
+var defaultDNSUpstreams = []string{
+	"https://1.1.1.1/dns-query",
+	"https://8.8.8.8/dns-query",
+	"tls://9.9.9.9:853",
+}
+
 type Config struct {
-	LogLevel string
-	Version  string
-	GRPC     GRPCConfig
+	LogLevel     string
+	Version      string
+	GRPC         GRPCConfig
+	Subscription SubscriptionConfig
+	Metrics      MetricsConfig
+	DNS          DNSConfig
+	Database     DatabaseConfig
 }
 
 type GRPCConfig struct {
 	Address string
 }
 
+// SubscriptionConfig конфигурация HTTP сервера подписок
+type SubscriptionConfig struct {
+	Address string
+}
+
+// MetricsConfig конфигурация HTTP сервера метрик для сервиса аналитики
+type MetricsConfig struct {
+	Address string
+}
+
+// DNSConfig конфигурация зашифрованного резолвера. Пустой Address
+// отключает локальный DNS сервер для клиентов VPN.
+type DNSConfig struct {
+	Address   string
+	Upstreams []string
+}
+
+// DatabaseConfig конфигурация базы данных. Пустой Host означает хранение
+// в памяти процесса.
+type DatabaseConfig struct {
+	Host          string
+	Port          int
+	User          string
+	Password      string
+	DBName        string
+	SSLMode       string
+	MigrationsDir string
+}
+
 func Load() *Config {
 	return &Config{
 		LogLevel: getEnv("LOG_LEVEL", "info"),
@@ -21,6 +64,25 @@ func Load() *Config {
 		GRPC: GRPCConfig{
 			Address: getEnv("GRPC_ADDRESS", ":9091"),
 		},
+		Subscription: SubscriptionConfig{
+			Address: getEnv("SUBSCRIPTION_ADDRESS", ":8080"),
+		},
+		Metrics: MetricsConfig{
+			Address: getEnv("METRICS_ADDRESS", ":9103"),
+		},
+		DNS: DNSConfig{
+			Address:   getEnv("DNS_ADDRESS", ""),
+			Upstreams: getEnvList("DNS_UPSTREAMS", defaultDNSUpstreams),
+		},
+		Database: DatabaseConfig{
+			Host:          getEnv("DB_HOST", ""),
+			Port:          getEnvInt("DB_PORT", 5432),
+			User:          getEnv("DB_USER", "postgres"),
+			Password:      getEnv("DB_PASSWORD", "password"),
+			DBName:        getEnv("DB_NAME", "silence_dpi_bypass"),
+			SSLMode:       getEnv("DB_SSLMODE", "disable"),
+			MigrationsDir: getEnv("MIGRATIONS_DIR", ""),
+		},
 	}
 }
 
@@ -30,3 +92,26 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -13,6 +13,12 @@ func TestLoad(t *testing.T) {
 	assert.Equal(t, "info", cfg.LogLevel)
 	assert.Equal(t, "1.0.0", cfg.Version)
 	assert.Equal(t, ":9091", cfg.GRPC.Address)
+	assert.Equal(t, ":8080", cfg.Subscription.Address)
+	assert.Equal(t, ":9103", cfg.Metrics.Address)
+	assert.Empty(t, cfg.DNS.Address)
+	assert.Equal(t, defaultDNSUpstreams, cfg.DNS.Upstreams)
+	assert.Empty(t, cfg.Database.Host)
+	assert.Equal(t, 5432, cfg.Database.Port)
 
 	// Test case 2: Environment variables
 	logLevel := "debug"
@@ -22,14 +28,32 @@ func TestLoad(t *testing.T) {
 	os.Setenv("LOG_LEVEL", logLevel)
 	os.Setenv("VERSION", version)
 	os.Setenv("GRPC_ADDRESS", grpcAddress)
+	os.Setenv("SUBSCRIPTION_ADDRESS", ":8090")
+	os.Setenv("METRICS_ADDRESS", ":9104")
+	os.Setenv("DNS_ADDRESS", ":5353")
+	os.Setenv("DNS_UPSTREAMS", "https://dns.example/dns-query, tls://dns.example")
+	os.Setenv("DB_HOST", "postgres")
+	os.Setenv("DB_PORT", "6543")
 
 	cfg = Load()
 	assert.Equal(t, logLevel, cfg.LogLevel)
 	assert.Equal(t, version, cfg.Version)
 	assert.Equal(t, grpcAddress, cfg.GRPC.Address)
+	assert.Equal(t, ":8090", cfg.Subscription.Address)
+	assert.Equal(t, ":9104", cfg.Metrics.Address)
+	assert.Equal(t, ":5353", cfg.DNS.Address)
+	assert.Equal(t, []string{"https://dns.example/dns-query", "tls://dns.example"}, cfg.DNS.Upstreams)
+	assert.Equal(t, "postgres", cfg.Database.Host)
+	assert.Equal(t, 6543, cfg.Database.Port)
 
 	// Clean up environment variables
 	os.Unsetenv("LOG_LEVEL")
 	os.Unsetenv("VERSION")
 	os.Unsetenv("GRPC_ADDRESS")
+	os.Unsetenv("SUBSCRIPTION_ADDRESS")
+	os.Unsetenv("METRICS_ADDRESS")
+	os.Unsetenv("DNS_ADDRESS")
+	os.Unsetenv("DNS_UPSTREAMS")
+	os.Unsetenv("DB_HOST")
+	os.Unsetenv("DB_PORT")
 }
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 // BypassType тип обхода DPI
 type BypassType string
@@ -26,6 +29,7 @@ const (
 	BypassMethodV2Ray        BypassMethod = "v2ray"
 	BypassMethodObfs4        BypassMethod = "obfs4"
 	BypassMethodCustom       BypassMethod = "custom"
+	BypassMethodAuto         BypassMethod = "auto"
 )
 
 // BypassStatus статус обхода
@@ -72,6 +76,27 @@ type BypassConfig struct {
 	Rules       []*BypassRule     `json:"rules"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
+	// Reloads результаты применения изменений к запущенным сессиям,
+	// заполняется при обновлении конфигурации
+	Reloads []*SessionReload `json:"reloads,omitempty"`
+	// Users действующие пользователи сервера, передаются адаптеру при запуске
+	Users []*BypassUser `json:"users,omitempty"`
+}
+
+// ReloadOutcome результат применения обновленной конфигурации к сессии
+type ReloadOutcome string
+
+const (
+	ReloadOutcomeApplied   ReloadOutcome = "applied"   // изменения применены на месте
+	ReloadOutcomeRestarted ReloadOutcome = "restarted" // новый listener, старые соединения завершаются плавно
+	ReloadOutcomeFailed    ReloadOutcome = "failed"    // изменения не применены, причина в Message
+)
+
+// SessionReload результат перезагрузки сессии
+type SessionReload struct {
+	SessionID string        `json:"session_id"`
+	Outcome   ReloadOutcome `json:"outcome"`
+	Message   string        `json:"message,omitempty"`
 }
 
 // CreateBypassConfigRequest запрос на создание bypass конфигурации
@@ -118,6 +143,8 @@ type BypassSession struct {
 	Status     BypassStatus `json:"status"`
 	StartedAt  time.Time    `json:"started_at"`
 	Message    string       `json:"message"`
+	// Options опции запроса запуска, сохраняются для перезагрузки
+	Options map[string]string `json:"options,omitempty"`
 }
 
 // BypassSessionStatus статус сессии обхода
@@ -147,6 +174,19 @@ type BypassStats struct {
 	AverageLatency         float64   `json:"average_latency"`
 	StartTime              time.Time `json:"start_time"`
 	EndTime                time.Time `json:"end_time"`
+	// Users статистика пользователей сервера
+	Users []*UserStats `json:"users,omitempty"`
+}
+
+// UserStats статистика пользователя сервера обхода
+type UserStats struct {
+	UserID            string    `json:"user_id"`
+	BytesSent         int64     `json:"bytes_sent"`
+	BytesReceived     int64     `json:"bytes_received"`
+	Connections       int64     `json:"connections"`
+	ActiveConnections int64     `json:"active_connections"`
+	LastActivity      time.Time `json:"last_activity"`
+	Revoked           bool      `json:"revoked"`
 }
 
 // BypassHistoryRequest запрос истории обхода
@@ -219,3 +259,153 @@ type BypassRuleFilters struct {
 	Limit    int      `json:"limit"`
 	Offset   int      `json:"offset"`
 }
+
+// BypassUser пользователь сервера обхода. ID совпадает с ID пользователя
+// auth-service.
+type BypassUser struct {
+	ID       string `json:"id"`
+	ConfigID string `json:"config_id"`
+	// Credential ключ пользователя в формате метода: PSK Shadowsocks 2022
+	// в base64, UUID VLESS или секрет obfs4
+	Credential string    `json:"credential"`
+	Revoked    bool      `json:"revoked"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AddBypassUserRequest запрос на добавление пользователя сервера.
+// Пустой Credential генерируется под метод конфигурации.
+type AddBypassUserRequest struct {
+	ConfigID   string `json:"config_id"`
+	UserID     string `json:"user_id"`
+	Credential string `json:"credential"`
+}
+
+// BypassUserFilters фильтры для пользователей сервера
+type BypassUserFilters struct {
+	ConfigID       string `json:"config_id"`
+	UserID         string `json:"user_id"`
+	IncludeRevoked bool   `json:"include_revoked"`
+	Limit          int    `json:"limit"`
+	Offset         int    `json:"offset"`
+}
+
+// ShareLink параметры подключения пользователя к серверу обхода для
+// клиентских приложений
+type ShareLink struct {
+	ConfigID string       `json:"config_id"`
+	UserID   string       `json:"user_id"`
+	Method   BypassMethod `json:"method"`
+	Name     string       `json:"name"`
+	Server   string       `json:"server"`
+	Port     int          `json:"port"`
+	// URI ссылка ss:// (SIP002) или vless://
+	URI string `json:"uri"`
+	// Cipher и Password метод и пароль Shadowsocks; для 2022 пароль
+	// состоит из PSK сервера и PSK пользователя через двоеточие
+	Cipher   string `json:"cipher,omitempty"`
+	Password string `json:"password,omitempty"`
+	// UUID, TLS и ServerName параметры VLESS
+	UUID       string `json:"uuid,omitempty"`
+	TLS        bool   `json:"tls,omitempty"`
+	ServerName string `json:"server_name,omitempty"`
+}
+
+// ErrSubscriptionNotFound токен подписки не найден или отозван
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// SubscriptionFormat формат подписки
+type SubscriptionFormat string
+
+const (
+	SubscriptionFormatBase64  SubscriptionFormat = "base64"
+	SubscriptionFormatClash   SubscriptionFormat = "clash"
+	SubscriptionFormatSingBox SubscriptionFormat = "singbox"
+)
+
+// SubscriptionToken токен подписки пользователя. Token входит в URL
+// подписки и действует до отзыва. Хранится только TokenHash (SHA-256),
+// Token заполнен лишь в ответе на выпуск.
+type SubscriptionToken struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	Token          string    `json:"token,omitempty"`
+	TokenHash      string    `json:"-"`
+	Revoked        bool      `json:"revoked"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastAccessedAt time.Time `json:"last_accessed_at"`
+}
+
+// CreateSubscriptionTokenRequest запрос на выпуск токена подписки
+type CreateSubscriptionTokenRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// SubscriptionTokenFilters фильтры для токенов подписки
+type SubscriptionTokenFilters struct {
+	UserID         string `json:"user_id"`
+	IncludeRevoked bool   `json:"include_revoked"`
+	Limit          int    `json:"limit"`
+	Offset         int    `json:"offset"`
+}
+
+// SubscriptionAccess запись журнала обращений к подписке
+type SubscriptionAccess struct {
+	ID         string             `json:"id"`
+	TokenID    string             `json:"token_id"`
+	UserID     string             `json:"user_id"`
+	Format     SubscriptionFormat `json:"format"`
+	RemoteAddr string             `json:"remote_addr"`
+	UserAgent  string             `json:"user_agent"`
+	AccessedAt time.Time          `json:"accessed_at"`
+}
+
+// SubscriptionAccessFilters фильтры журнала обращений к подписке
+type SubscriptionAccessFilters struct {
+	TokenID string `json:"token_id"`
+	UserID  string `json:"user_id"`
+	Limit   int    `json:"limit"`
+	Offset  int    `json:"offset"`
+}
+
+// Subscription подписка пользователя: ссылки всех серверов, к которым у
+// него есть доступ
+type Subscription struct {
+	UserID string       `json:"user_id"`
+	Links  []*ShareLink `json:"links"`
+}
+
+// CensorshipSignal признак вмешательства в соединение обхода. Для протоколов
+// без TLS роль ClientHello играет первая отправка клиента.
+type CensorshipSignal string
+
+const (
+	CensorshipSignalRSTAfterClientHello CensorshipSignal = "rst_after_client_hello"
+	CensorshipSignalTimeoutAfterSNI     CensorshipSignal = "timeout_after_sni"
+	CensorshipSignalDNSPoisoning        CensorshipSignal = "dns_poisoning"
+	CensorshipSignalThrottling          CensorshipSignal = "throttling"
+)
+
+// CensorshipSignalFilters фильтры агрегатов сигналов цензуры. Нулевое окно
+// объединяет весь интервал в один агрегат.
+type CensorshipSignalFilters struct {
+	Method    BypassMethod  `json:"method"`
+	Target    string        `json:"target"`
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Window    time.Duration `json:"window"`
+}
+
+// CensorshipSignalStats сигналы цензуры метода и цели за окно времени.
+// Сброс, таймаут и подмена DNS считаются блокировками, замедление нет.
+type CensorshipSignalStats struct {
+	Method              BypassMethod `json:"method"`
+	Target              string       `json:"target"`
+	WindowStart         time.Time    `json:"window_start"`
+	WindowEnd           time.Time    `json:"window_end"`
+	Connections         int64        `json:"connections"`
+	RSTAfterClientHello int64        `json:"rst_after_client_hello"`
+	TimeoutAfterSNI     int64        `json:"timeout_after_sni"`
+	DNSPoisoning        int64        `json:"dns_poisoning"`
+	Throttling          int64        `json:"throttling"`
+}
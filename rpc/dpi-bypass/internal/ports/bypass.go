@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
 )
@@ -18,17 +19,41 @@ type DPIBypassService interface {
 	// Bypass operations
 	StartBypass(ctx context.Context, req *domain.StartBypassRequest) (*domain.BypassSession, error)
 	StopBypass(ctx context.Context, sessionID string) error
+	// StopAllBypass останавливает все сессии при завершении приложения,
+	// завершая соединения не позже дедлайна ctx
+	StopAllBypass(ctx context.Context) error
 	GetBypassStatus(ctx context.Context, sessionID string) (*domain.BypassSessionStatus, error)
 
 	// Statistics and monitoring
 	GetBypassStats(ctx context.Context, sessionID string) (*domain.BypassStats, error)
 	GetBypassHistory(ctx context.Context, req *domain.BypassHistoryRequest) ([]*domain.BypassHistoryEntry, int, error)
+	GetCensorshipSignals(ctx context.Context, filters *domain.CensorshipSignalFilters) ([]*domain.CensorshipSignalStats, error)
+	// ExportCensorshipSignals возвращает завершенные к until минутные
+	// агрегаты сигналов, не подтвержденные AcknowledgeCensorshipSignals
+	ExportCensorshipSignals(ctx context.Context, until time.Time) ([]*domain.CensorshipSignalStats, error)
+	// AcknowledgeCensorshipSignals отмечает агрегаты, завершенные к until,
+	// доставленными: следующие выгрузки их не содержат
+	AcknowledgeCensorshipSignals(ctx context.Context, until time.Time) error
 
 	// Rule management
 	AddBypassRule(ctx context.Context, req *domain.AddBypassRuleRequest) (*domain.BypassRule, error)
 	UpdateBypassRule(ctx context.Context, req *domain.UpdateBypassRuleRequest) (*domain.BypassRule, error)
 	DeleteBypassRule(ctx context.Context, id string) error
 	ListBypassRules(ctx context.Context, filters *domain.BypassRuleFilters) ([]*domain.BypassRule, int, error)
+
+	// User management
+	AddBypassUser(ctx context.Context, req *domain.AddBypassUserRequest) (*domain.BypassUser, error)
+	RevokeBypassUser(ctx context.Context, configID, userID string) error
+	ListBypassUsers(ctx context.Context, filters *domain.BypassUserFilters) ([]*domain.BypassUser, int, error)
+
+	// Share links and subscriptions
+	GetShareLink(ctx context.Context, configID, userID string) (*domain.ShareLink, error)
+	CreateSubscriptionToken(ctx context.Context, req *domain.CreateSubscriptionTokenRequest) (*domain.SubscriptionToken, error)
+	RevokeSubscriptionToken(ctx context.Context, id string) error
+	ListSubscriptionTokens(ctx context.Context, filters *domain.SubscriptionTokenFilters) ([]*domain.SubscriptionToken, int, error)
+	ListSubscriptionAccesses(ctx context.Context, filters *domain.SubscriptionAccessFilters) ([]*domain.SubscriptionAccess, int, error)
+	// GetSubscription возвращает подписку по токену и записывает обращение в журнал
+	GetSubscription(ctx context.Context, token string, access *domain.SubscriptionAccess) (*domain.Subscription, error)
 }
 
 // BypassAdapter интерфейс для адаптеров обфускации
@@ -38,3 +63,70 @@ type BypassAdapter interface {
 	GetStats(id string) (*domain.BypassStats, error)
 	IsRunning(id string) bool
 }
+
+// BypassRepository интерфейс хранилища конфигураций, правил и истории обхода
+type BypassRepository interface {
+	// Configurations
+	CreateConfig(ctx context.Context, config *domain.BypassConfig) error
+	GetConfig(ctx context.Context, id string) (*domain.BypassConfig, error)
+	ListConfigs(ctx context.Context, filters *domain.BypassConfigFilters) ([]*domain.BypassConfig, int, error)
+	UpdateConfig(ctx context.Context, config *domain.BypassConfig) error
+	UpdateConfigStatus(ctx context.Context, id string, status domain.BypassStatus) error
+	DeleteConfig(ctx context.Context, id string) error
+
+	// Rules
+	CreateRule(ctx context.Context, rule *domain.BypassRule) error
+	GetRule(ctx context.Context, id string) (*domain.BypassRule, error)
+	ListRules(ctx context.Context, filters *domain.BypassRuleFilters) ([]*domain.BypassRule, int, error)
+	UpdateRule(ctx context.Context, rule *domain.BypassRule) error
+	DeleteRule(ctx context.Context, id string) error
+
+	// Users
+	CreateUser(ctx context.Context, user *domain.BypassUser) error
+	GetUser(ctx context.Context, configID, userID string) (*domain.BypassUser, error)
+	ListUsers(ctx context.Context, filters *domain.BypassUserFilters) ([]*domain.BypassUser, int, error)
+	UpdateUser(ctx context.Context, user *domain.BypassUser) error
+
+	// Subscriptions
+	CreateSubscriptionToken(ctx context.Context, token *domain.SubscriptionToken) error
+	GetSubscriptionToken(ctx context.Context, id string) (*domain.SubscriptionToken, error)
+	GetSubscriptionTokenByHash(ctx context.Context, tokenHash string) (*domain.SubscriptionToken, error)
+	ListSubscriptionTokens(ctx context.Context, filters *domain.SubscriptionTokenFilters) ([]*domain.SubscriptionToken, int, error)
+	UpdateSubscriptionToken(ctx context.Context, token *domain.SubscriptionToken) error
+	CreateSubscriptionAccess(ctx context.Context, access *domain.SubscriptionAccess) error
+	ListSubscriptionAccesses(ctx context.Context, filters *domain.SubscriptionAccessFilters) ([]*domain.SubscriptionAccess, int, error)
+
+	// History
+	CreateHistoryEntry(ctx context.Context, entry *domain.BypassHistoryEntry) error
+	ListHistory(ctx context.Context, req *domain.BypassHistoryRequest) ([]*domain.BypassHistoryEntry, int, error)
+}
+
+// ReloadableAdapter адаптер, применяющий обновленную конфигурацию к
+// запущенной сессии
+type ReloadableAdapter interface {
+	BypassAdapter
+	// Reload применяет изменения на месте или перезапускает сессию,
+	// завершая текущие соединения не дольше drainTimeout
+	Reload(config *domain.BypassConfig, drainTimeout time.Duration) (domain.ReloadOutcome, error)
+}
+
+// DrainableAdapter адаптер, плавно завершающий соединения сессии при
+// остановке
+type DrainableAdapter interface {
+	BypassAdapter
+	// Drain останавливает сессию: ждет текущие соединения не дольше
+	// timeout, прерывает оставшиеся и возвращает итоговую статистику
+	Drain(id string, timeout time.Duration) (*domain.BypassStats, error)
+}
+
+// SignalAdapter адаптер, классифицирующий сбои исходящих соединений
+type SignalAdapter interface {
+	BypassAdapter
+	CensorshipSignals(filters *domain.CensorshipSignalFilters) ([]*domain.CensorshipSignalStats, error)
+	// ExportCensorshipSignals возвращает завершенные к until агрегаты, не
+	// подтвержденные ранее
+	ExportCensorshipSignals(until time.Time) []*domain.CensorshipSignalStats
+	// AcknowledgeCensorshipSignals отмечает агрегаты, завершенные к until,
+	// доставленными
+	AcknowledgeCensorshipSignals(until time.Time)
+}
@@ -0,0 +1,115 @@
+package pt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// splitEscaped делит строку по неэкранированному разделителю, экранирование
+// обратной косой чертой сохраняется в частях
+func splitEscaped(value string, sep byte) ([]string, error) {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+			if i == len(value) {
+				return nil, fmt.Errorf("trailing backslash in %q", value)
+			}
+		case sep:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:]), nil
+}
+
+// unescape убирает экранирование обратной косой чертой
+func unescape(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		builder.WriteByte(value[i])
+	}
+	return builder.String()
+}
+
+// parsePair разбирает экранированную пару key=value
+func parsePair(pair string) (string, string, error) {
+	parts, err := splitEscaped(pair, '=')
+	if err != nil {
+		return "", "", err
+	}
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("invalid argument %q", pair)
+	}
+	return unescape(parts[0]), unescape(parts[1]), nil
+}
+
+// parseClientArgs разбирает параметры моста "k=v;k=v" из SOCKS запроса
+func parseClientArgs(value string) (map[string]string, error) {
+	args := make(map[string]string)
+	if value == "" {
+		return args, nil
+	}
+	pairs, err := splitEscaped(value, ';')
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		key, value, err := parsePair(pair)
+		if err != nil {
+			return nil, err
+		}
+		args[key] = value
+	}
+	return args, nil
+}
+
+// parseServerOptions разбирает параметры транспортов
+// "transport:k=v;transport:k=v"
+func parseServerOptions(value string) (map[string]map[string]string, error) {
+	options := make(map[string]map[string]string)
+	if value == "" {
+		return options, nil
+	}
+	entries, err := splitEscaped(value, ';')
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name, pair, ok := strings.Cut(entry, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid option %q", entry)
+		}
+		key, value, err := parsePair(pair)
+		if err != nil {
+			return nil, err
+		}
+		if options[name] == nil {
+			options[name] = make(map[string]string)
+		}
+		options[name][key] = value
+	}
+	return options, nil
+}
+
+// encodeArgs кодирует параметры моста для строки SMETHOD "k=v,k=v"
+func encodeArgs(args map[string]string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `=`, `\=`, `,`, `\,`)
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, escaper.Replace(key)+"="+escaper.Replace(args[key]))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,39 @@
+package pt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClientArgs(t *testing.T) {
+	args, err := parseClientArgs(`secret=00ff;password=a\;b\=c\\d`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"secret": "00ff", "password": `a;b=c\d`}, args)
+
+	args, err = parseClientArgs("")
+	require.NoError(t, err)
+	assert.Empty(t, args)
+
+	for _, value := range []string{"secret", "=value", `secret=a\`, "a=b=c"} {
+		_, err := parseClientArgs(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseServerOptions(t *testing.T) {
+	options, err := parseServerOptions(`obfs4:secret=00ff;custom:password=p\;w;custom:obfuscation_mode=chaff`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"obfs4":  {"secret": "00ff"},
+		"custom": {"password": "p;w", "obfuscation_mode": "chaff"},
+	}, options)
+
+	_, err = parseServerOptions("secret=00ff")
+	assert.Error(t, err)
+}
+
+func TestEncodeArgs(t *testing.T) {
+	assert.Equal(t, `a=1,password=p\,w\=\\`, encodeArgs(map[string]string{"password": `p,w=\`, "a": "1"}))
+}
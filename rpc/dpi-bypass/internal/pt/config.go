@@ -0,0 +1,104 @@
+package pt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/bypass"
+)
+
+// ptVersion версия протокола управляемого прокси
+const ptVersion = "1"
+
+// errNoVersion Tor не поддерживает ни одной нашей версии протокола
+var errNoVersion = errors.New("no-version")
+
+// Config параметры управляемого прокси из переменных окружения TOR_PT_*.
+// Заполнена клиентская или серверная часть, в зависимости от роли.
+type Config struct {
+	StateLocation    string
+	ExitOnStdinClose bool
+
+	// Клиент: транспорты и внешний прокси, через который ходить к мостам
+	ClientTransports []string
+	Proxy            string
+
+	// Сервер: транспорты, адреса прослушивания, ORPort моста и параметры
+	// транспортов
+	ServerTransports []string
+	BindAddrs        map[string]string
+	ORPort           string
+	ServerOptions    map[string]map[string]string
+}
+
+// IsClient возвращает true в клиентской роли
+func (c *Config) IsClient() bool {
+	return len(c.ClientTransports) > 0
+}
+
+// parseConfig разбирает окружение управляемого прокси
+func parseConfig(getenv func(string) string) (*Config, error) {
+	versions := strings.Split(getenv("TOR_PT_MANAGED_TRANSPORT_VER"), ",")
+	supported := false
+	for _, version := range versions {
+		if version == ptVersion {
+			supported = true
+		}
+	}
+	if !supported {
+		return nil, errNoVersion
+	}
+
+	cfg := &Config{
+		StateLocation:    getenv("TOR_PT_STATE_LOCATION"),
+		ExitOnStdinClose: getenv("TOR_PT_EXIT_ON_STDIN_CLOSE") == "1",
+	}
+	if cfg.StateLocation == "" {
+		return nil, fmt.Errorf("no TOR_PT_STATE_LOCATION environment variable")
+	}
+
+	if transports := getenv("TOR_PT_CLIENT_TRANSPORTS"); transports != "" {
+		cfg.ClientTransports = parseTransports(transports)
+		cfg.Proxy = getenv("TOR_PT_PROXY")
+		return cfg, nil
+	}
+
+	transports := getenv("TOR_PT_SERVER_TRANSPORTS")
+	if transports == "" {
+		return nil, fmt.Errorf("no TOR_PT_CLIENT_TRANSPORTS or TOR_PT_SERVER_TRANSPORTS environment variable")
+	}
+	cfg.ServerTransports = parseTransports(transports)
+
+	cfg.ORPort = getenv("TOR_PT_ORPORT")
+	if cfg.ORPort == "" {
+		return nil, fmt.Errorf("no TOR_PT_ORPORT environment variable")
+	}
+
+	cfg.BindAddrs = make(map[string]string)
+	if bindAddrs := getenv("TOR_PT_SERVER_BINDADDR"); bindAddrs != "" {
+		for _, entry := range strings.Split(bindAddrs, ",") {
+			name, addr, ok := strings.Cut(entry, "-")
+			if !ok || name == "" || addr == "" {
+				return nil, fmt.Errorf("invalid TOR_PT_SERVER_BINDADDR entry: %s", entry)
+			}
+			cfg.BindAddrs[name] = addr
+		}
+	}
+
+	options, err := parseServerOptions(getenv("TOR_PT_SERVER_TRANSPORT_OPTIONS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOR_PT_SERVER_TRANSPORT_OPTIONS: %w", err)
+	}
+	cfg.ServerOptions = options
+
+	return cfg, nil
+}
+
+// parseTransports разбирает список транспортов, "*" означает все поддерживаемые
+func parseTransports(value string) []string {
+	if value == "*" {
+		return bypass.PTTransports()
+	}
+	return strings.Split(value, ",")
+}
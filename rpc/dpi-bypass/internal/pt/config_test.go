@@ -0,0 +1,73 @@
+package pt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestParseConfig_Client(t *testing.T) {
+	cfg, err := parseConfig(env(map[string]string{
+		"TOR_PT_MANAGED_TRANSPORT_VER": "2,1",
+		"TOR_PT_STATE_LOCATION":        "/var/lib/tor/pt_state",
+		"TOR_PT_EXIT_ON_STDIN_CLOSE":   "1",
+		"TOR_PT_CLIENT_TRANSPORTS":     "*",
+	}))
+	require.NoError(t, err)
+	assert.True(t, cfg.IsClient())
+	assert.True(t, cfg.ExitOnStdinClose)
+	assert.Equal(t, []string{"custom", "obfs4"}, cfg.ClientTransports)
+}
+
+func TestParseConfig_Server(t *testing.T) {
+	cfg, err := parseConfig(env(map[string]string{
+		"TOR_PT_MANAGED_TRANSPORT_VER":    "1",
+		"TOR_PT_STATE_LOCATION":           "/var/lib/tor/pt_state",
+		"TOR_PT_SERVER_TRANSPORTS":        "obfs4,custom",
+		"TOR_PT_SERVER_BINDADDR":          "obfs4-0.0.0.0:4443,custom-[::]:8443",
+		"TOR_PT_ORPORT":                   "127.0.0.1:9001",
+		"TOR_PT_SERVER_TRANSPORT_OPTIONS": "custom:password=secret",
+	}))
+	require.NoError(t, err)
+	assert.False(t, cfg.IsClient())
+	assert.Equal(t, []string{"obfs4", "custom"}, cfg.ServerTransports)
+	assert.Equal(t, map[string]string{"obfs4": "0.0.0.0:4443", "custom": "[::]:8443"}, cfg.BindAddrs)
+	assert.Equal(t, "127.0.0.1:9001", cfg.ORPort)
+	assert.Equal(t, "secret", cfg.ServerOptions["custom"]["password"])
+}
+
+func TestParseConfig_Errors(t *testing.T) {
+	_, err := parseConfig(env(map[string]string{"TOR_PT_MANAGED_TRANSPORT_VER": "2"}))
+	assert.ErrorIs(t, err, errNoVersion)
+
+	base := map[string]string{
+		"TOR_PT_MANAGED_TRANSPORT_VER": "1",
+		"TOR_PT_STATE_LOCATION":        "/var/lib/tor/pt_state",
+		"TOR_PT_SERVER_TRANSPORTS":     "obfs4",
+		"TOR_PT_ORPORT":                "127.0.0.1:9001",
+	}
+	for name, override := range map[string]map[string]string{
+		"без состояния":      {"TOR_PT_STATE_LOCATION": ""},
+		"без транспортов":    {"TOR_PT_SERVER_TRANSPORTS": ""},
+		"без ORPort":         {"TOR_PT_ORPORT": ""},
+		"неверный адрес":     {"TOR_PT_SERVER_BINDADDR": "obfs4:0.0.0.0:4443"},
+		"неверные параметры": {"TOR_PT_SERVER_TRANSPORT_OPTIONS": "secret=00ff"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			values := make(map[string]string)
+			for key, value := range base {
+				values[key] = value
+			}
+			for key, value := range override {
+				values[key] = value
+			}
+			_, err := parseConfig(env(values))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package pt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/bypass"
+	"github.com/par1ram/silence/shared/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// handshakeTimeout ограничивает рукопожатия SOCKS и транспорта
+	handshakeTimeout = 30 * time.Second
+	// dialTimeout таймаут подключения к мосту и ORPort
+	dialTimeout = 10 * time.Second
+)
+
+// Proxy управляемый прокси Tor Pluggable Transport v1: сообщает Tor адреса
+// транспортов строками CMETHOD/SMETHOD на stdout и передает трафик через
+// транспорты адаптеров bypass. Логи пишутся в stderr.
+type Proxy struct {
+	config *Config
+	logger *zap.Logger
+
+	outMutex sync.Mutex
+	out      io.Writer
+
+	mutex     sync.Mutex
+	listeners []net.Listener
+	conns     map[net.Conn]struct{}
+	handlers  sync.WaitGroup
+}
+
+// NewProxy создает управляемый прокси
+func NewProxy(cfg *Config, out io.Writer, logger *zap.Logger) *Proxy {
+	return &Proxy{
+		config: cfg,
+		logger: logger,
+		out:    out,
+		conns:  make(map[net.Conn]struct{}),
+	}
+}
+
+// Run запускает управляемый прокси по окружению TOR_PT_* и работает до
+// сигнала завершения или закрытия stdin
+func Run() {
+	logger := logger.NewLogger("dpi-bypass-pt")
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	cfg, err := parseConfig(os.Getenv)
+	if err != nil {
+		if errors.Is(err, errNoVersion) {
+			fmt.Fprintln(os.Stdout, "VERSION-ERROR no-version")
+		} else {
+			fmt.Fprintln(os.Stdout, "ENV-ERROR", err)
+		}
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if cfg.ExitOnStdinClose {
+		go func() {
+			_, _ = io.Copy(io.Discard, os.Stdin)
+			stop()
+		}()
+	}
+
+	if err := NewProxy(cfg, os.Stdout, logger).Serve(ctx); err != nil {
+		logger.Error("pluggable transport failed", zap.Error(err))
+		os.Exit(1)
+	}
+}
+
+// Serve открывает транспорты, сообщает о них Tor и обслуживает соединения
+// до отмены контекста
+func (p *Proxy) Serve(ctx context.Context) error {
+	p.message("VERSION", ptVersion)
+
+	var err error
+	if p.config.IsClient() {
+		err = p.startClients()
+	} else {
+		err = p.startServers()
+	}
+	if err != nil {
+		p.close()
+		return err
+	}
+
+	<-ctx.Done()
+	p.logger.Info("pluggable transport shutting down")
+	p.close()
+	p.handlers.Wait()
+	return nil
+}
+
+// startClients открывает SOCKS5 listener для каждого клиентского транспорта
+func (p *Proxy) startClients() error {
+	if p.config.Proxy != "" {
+		p.message("PROXY-ERROR", "proxy is not supported")
+		return fmt.Errorf("unsupported TOR_PT_PROXY: %s", p.config.Proxy)
+	}
+
+	for _, name := range p.config.ClientTransports {
+		if !supported(name) {
+			p.message("CMETHOD-ERROR", name, "no such transport is supported")
+			continue
+		}
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			p.message("CMETHOD-ERROR", name, err.Error())
+			continue
+		}
+		p.serve(listener, func(conn net.Conn) { p.handleClient(name, conn) })
+
+		p.logger.Info("client transport started", zap.String("transport", name), zap.String("address", listener.Addr().String()))
+		p.message("CMETHOD", name, "socks5", listener.Addr().String())
+	}
+	p.message("CMETHODS", "DONE")
+	return nil
+}
+
+// startServers открывает listener для каждого серверного транспорта.
+// Параметры из состояния дополняются TOR_PT_SERVER_TRANSPORT_OPTIONS, а
+// сгенерированный секрет сохраняется, чтобы строка моста не менялась.
+func (p *Proxy) startServers() error {
+	for _, name := range p.config.ServerTransports {
+		if !supported(name) {
+			p.message("SMETHOD-ERROR", name, "no such transport is supported")
+			continue
+		}
+
+		options, err := p.loadState(name)
+		if err != nil {
+			p.message("SMETHOD-ERROR", name, err.Error())
+			continue
+		}
+		for key, value := range p.config.ServerOptions[name] {
+			options[key] = value
+		}
+		transport, err := bypass.NewPTServer(name, options)
+		if err != nil {
+			p.message("SMETHOD-ERROR", name, err.Error())
+			continue
+		}
+		if err := p.saveState(name, transport.Args()); err != nil {
+			p.message("SMETHOD-ERROR", name, err.Error())
+			continue
+		}
+
+		address := p.config.BindAddrs[name]
+		if address == "" {
+			address = "0.0.0.0:0"
+		}
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			p.message("SMETHOD-ERROR", name, err.Error())
+			continue
+		}
+		p.serve(listener, func(conn net.Conn) { p.handleServer(transport, conn) })
+
+		p.logger.Info("server transport started", zap.String("transport", name), zap.String("address", listener.Addr().String()))
+		p.message("SMETHOD", name, listener.Addr().String(), "ARGS:"+encodeArgs(transport.Args()))
+	}
+	p.message("SMETHODS", "DONE")
+	return nil
+}
+
+// serve принимает соединения listener до его закрытия. Принятые соединения
+// закрываются при остановке прокси.
+func (p *Proxy) serve(listener net.Listener, handle func(conn net.Conn)) {
+	p.mutex.Lock()
+	p.listeners = append(p.listeners, listener)
+	p.mutex.Unlock()
+
+	p.handlers.Add(1)
+	go func() {
+		defer p.handlers.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					p.logger.Error("failed to accept connection", zap.Error(err))
+				}
+				return
+			}
+			if !p.track(conn) {
+				conn.Close()
+				return
+			}
+			p.handlers.Add(1)
+			go func() {
+				defer p.handlers.Done()
+				defer p.untrack(conn)
+				handle(conn)
+			}()
+		}
+	}()
+}
+
+// handleClient принимает SOCKS запрос Tor, подключается к мосту через
+// транспорт и передает трафик
+func (p *Proxy) handleClient(name string, conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	request, err := readSocksRequest(conn)
+	if err != nil {
+		p.logger.Debug("invalid socks request", zap.String("transport", name), zap.Error(err))
+		return
+	}
+
+	transport, err := bypass.NewPTClient(name, request.args)
+	if err != nil {
+		p.logger.Warn("invalid bridge arguments", zap.String("transport", name), zap.Error(err))
+		_ = writeSocksReply(conn, socksReplyFailure)
+		return
+	}
+
+	remote, err := net.DialTimeout("tcp", request.target, dialTimeout)
+	if err != nil {
+		p.logger.Warn("failed to dial bridge", zap.String("transport", name), zap.String("bridge", request.target), zap.Error(err))
+		_ = writeSocksReply(conn, socksReplyCode(err))
+		return
+	}
+	defer remote.Close()
+
+	_ = remote.SetDeadline(time.Now().Add(handshakeTimeout))
+	wrapped, err := transport.Wrap(remote)
+	if err != nil {
+		p.logger.Warn("transport handshake failed", zap.String("transport", name), zap.String("bridge", request.target), zap.Error(err))
+		_ = writeSocksReply(conn, socksReplyFailure)
+		return
+	}
+	if err := writeSocksReply(conn, socksReplySucceeded); err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	_ = remote.SetDeadline(time.Time{})
+
+	relay(conn, wrapped)
+}
+
+// handleServer проверяет рукопожатие клиента транспорта и передает трафик
+// в ORPort моста
+func (p *Proxy) handleServer(transport *bypass.PTTransport, conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	wrapped, err := transport.Wrap(conn)
+	if err != nil {
+		p.logger.Debug("transport handshake failed", zap.String("transport", transport.Name()),
+			zap.String("client", conn.RemoteAddr().String()), zap.Error(err))
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	orport, err := net.DialTimeout("tcp", p.config.ORPort, dialTimeout)
+	if err != nil {
+		p.logger.Error("failed to dial orport", zap.String("orport", p.config.ORPort), zap.Error(err))
+		return
+	}
+	defer orport.Close()
+
+	relay(wrapped, orport)
+}
+
+// message отправляет Tor строку протокола управляемого прокси
+func (p *Proxy) message(keyword string, args ...string) {
+	p.outMutex.Lock()
+	defer p.outMutex.Unlock()
+
+	fmt.Fprintln(p.out, strings.Join(append([]string{keyword}, args...), " "))
+}
+
+// track учитывает соединение, после остановки прокси возвращает false
+func (p *Proxy) track(conn net.Conn) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.conns == nil {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *Proxy) untrack(conn net.Conn) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	conn.Close()
+	delete(p.conns, conn)
+}
+
+// close закрывает listener и принятые соединения
+func (p *Proxy) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, listener := range p.listeners {
+		listener.Close()
+	}
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.conns = nil
+}
+
+// statePath файл состояния транспорта в TOR_PT_STATE_LOCATION
+func (p *Proxy) statePath(name string) string {
+	return filepath.Join(p.config.StateLocation, name+"_state.json")
+}
+
+// loadState читает сохраненные параметры транспорта
+func (p *Proxy) loadState(name string) (map[string]string, error) {
+	options := make(map[string]string)
+	data, err := os.ReadFile(p.statePath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return options, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err := json.Unmarshal(data, &options); err != nil {
+		return nil, fmt.Errorf("failed to parse state: %w", err)
+	}
+	return options, nil
+}
+
+// saveState сохраняет параметры транспорта с секретом
+func (p *Proxy) saveState(name string, args map[string]string) error {
+	if err := os.MkdirAll(p.config.StateLocation, 0o700); err != nil {
+		return fmt.Errorf("failed to create state location: %w", err)
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p.statePath(name), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// supported проверяет, что транспорт доступен
+func supported(name string) bool {
+	for _, transport := range bypass.PTTransports() {
+		if transport == name {
+			return true
+		}
+	}
+	return false
+}
+
+// relay передает данные в обе стороны до закрытия любой из них
+func relay(left, right net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(left, right)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(right, left)
+		done <- struct{}{}
+	}()
+	<-done
+	left.Close()
+	right.Close()
+	<-done
+}
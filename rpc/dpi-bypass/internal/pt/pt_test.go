@@ -0,0 +1,167 @@
+package pt
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/net/proxy"
+)
+
+// lineWriter передает строки протокола в канал: каждая строка пишется
+// одним вызовом Write
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- strings.TrimSuffix(string(p), "\n")
+	return len(p), nil
+}
+
+// startProxy запускает прокси и возвращает строки до *METHODS DONE
+func startProxy(t *testing.T, cfg *Config) []string {
+	t.Helper()
+
+	out := make(lineWriter, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewProxy(cfg, out, zap.NewNop()).Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Error("proxy did not stop")
+		}
+	})
+
+	var lines []string
+	for {
+		select {
+		case line := <-out:
+			lines = append(lines, line)
+			if strings.HasSuffix(line, "METHODS DONE") {
+				return lines
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no METHODS DONE, got %v", lines)
+		}
+	}
+}
+
+// startEcho запускает ORPort, который возвращает полученные данные
+func startEcho(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestProxy_ClientServer(t *testing.T) {
+	orport := startEcho(t)
+	state := t.TempDir()
+
+	server := startProxy(t, &Config{
+		StateLocation:    state,
+		ServerTransports: []string{"obfs4", "custom", "meek"},
+		BindAddrs:        map[string]string{"obfs4": "127.0.0.1:0", "custom": "127.0.0.1:0"},
+		ORPort:           orport,
+		ServerOptions:    map[string]map[string]string{"custom": {"password": "bridge;password"}},
+	})
+	require.Len(t, server, 5)
+	assert.Equal(t, "VERSION 1", server[0])
+	assert.Equal(t, "SMETHOD-ERROR meek no such transport is supported", server[3])
+
+	client := startProxy(t, &Config{
+		StateLocation:    state,
+		ClientTransports: []string{"obfs4", "custom"},
+	})
+	require.Equal(t, []string{"VERSION 1", "CMETHODS DONE"}, []string{client[0], client[3]})
+
+	for i, name := range []string{"obfs4", "custom"} {
+		t.Run(name, func(t *testing.T) {
+			// SMETHOD obfs4 127.0.0.1:port ARGS:secret=...
+			fields := strings.Fields(server[i+1])
+			require.Len(t, fields, 4)
+			require.Equal(t, []string{"SMETHOD", name}, fields[:2])
+			encoded, err := splitEscaped(strings.TrimPrefix(fields[3], "ARGS:"), ',')
+			require.NoError(t, err)
+			args := make(map[string]string)
+			for _, pair := range encoded {
+				key, value, err := parsePair(pair)
+				require.NoError(t, err)
+				args[key] = value
+			}
+
+			// Tor передает параметры моста в имени пользователя SOCKS
+			pairs := make([]string, 0, len(args))
+			for key, value := range args {
+				pairs = append(pairs, key+"="+strings.NewReplacer(";", `\;`).Replace(value))
+			}
+			socks := strings.Fields(client[i+1])
+			require.Equal(t, []string{"CMETHOD", name, "socks5"}, socks[:3])
+			dialer, err := proxy.SOCKS5("tcp", socks[3], &proxy.Auth{User: strings.Join(pairs, ";"), Password: "\x00"}, proxy.Direct)
+			require.NoError(t, err)
+
+			conn, err := dialer.Dial("tcp", fields[2])
+			require.NoError(t, err)
+			defer conn.Close()
+			require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+			_, err = conn.Write([]byte("tor cell"))
+			require.NoError(t, err)
+			buffer := make([]byte, len("tor cell"))
+			_, err = io.ReadFull(conn, buffer)
+			require.NoError(t, err)
+			assert.Equal(t, "tor cell", string(buffer))
+		})
+	}
+
+	// Сгенерированный секрет obfs4 сохранен в состоянии
+	data, err := os.ReadFile(filepath.Join(state, "obfs4_state.json"))
+	require.NoError(t, err)
+	assert.Contains(t, server[1], strings.Split(string(data), `"`)[3])
+}
+
+func TestProxy_ClientBadArgs(t *testing.T) {
+	client := startProxy(t, &Config{StateLocation: t.TempDir(), ClientTransports: []string{"obfs4"}})
+	socks := strings.Fields(client[1])
+
+	dialer, err := proxy.SOCKS5("tcp", socks[3], &proxy.Auth{User: "secret=short", Password: "\x00"}, proxy.Direct)
+	require.NoError(t, err)
+	_, err = dialer.Dial("tcp", "127.0.0.1:9")
+	assert.Error(t, err)
+}
+
+func TestProxy_UnsupportedProxy(t *testing.T) {
+	out := make(lineWriter, 4)
+	err := NewProxy(&Config{ClientTransports: []string{"obfs4"}, Proxy: "socks5://127.0.0.1:1080"}, out, zap.NewNop()).
+		Serve(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, "VERSION 1", <-out)
+	assert.Equal(t, "PROXY-ERROR proxy is not supported", <-out)
+}
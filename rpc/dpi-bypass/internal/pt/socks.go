@@ -0,0 +1,176 @@
+package pt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// SOCKS5 (RFC 1928, RFC 1929): Tor передает параметры моста в имени
+// пользователя и пароле, а адрес моста - в запросе CONNECT
+const (
+	socksVersion         = 0x05
+	socksAuthVersion     = 0x01
+	socksMethodNoAuth    = 0x00
+	socksMethodUserPass  = 0x02
+	socksMethodNoAccept  = 0xff
+	socksCommandConnect  = 0x01
+	socksAddressIPv4     = 0x01
+	socksAddressDomain   = 0x03
+	socksAddressIPv6     = 0x04
+	socksReplySucceeded  = 0x00
+	socksReplyFailure    = 0x01
+	socksReplyRefused    = 0x05
+	socksReplyNoCommand  = 0x07
+	socksReplyNoAddrType = 0x08
+)
+
+// socksRequest запрос Tor: адрес моста и его параметры
+type socksRequest struct {
+	target string
+	args   map[string]string
+}
+
+// readSocksRequest проводит рукопожатие SOCKS5 до запроса CONNECT
+// включительно. Ответ на запрос отправляет writeSocksReply.
+func readSocksRequest(conn io.ReadWriter) (*socksRequest, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	if header[0] != socksVersion {
+		return nil, fmt.Errorf("unsupported socks version: %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return nil, err
+	}
+
+	method := byte(socksMethodNoAccept)
+	for _, offered := range methods {
+		if offered == socksMethodUserPass {
+			method = socksMethodUserPass
+			break
+		}
+		if offered == socksMethodNoAuth {
+			method = socksMethodNoAuth
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion, method}); err != nil {
+		return nil, err
+	}
+	if method == socksMethodNoAccept {
+		return nil, errors.New("no acceptable socks authentication method")
+	}
+
+	request := &socksRequest{args: map[string]string{}}
+	if method == socksMethodUserPass {
+		args, err := readSocksArgs(conn)
+		if err != nil {
+			return nil, err
+		}
+		request.args = args
+	}
+
+	header = make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	if header[0] != socksVersion {
+		return nil, fmt.Errorf("unsupported socks version: %d", header[0])
+	}
+	if header[1] != socksCommandConnect {
+		_ = writeSocksReply(conn, socksReplyNoCommand)
+		return nil, fmt.Errorf("unsupported socks command: %d", header[1])
+	}
+
+	var host string
+	switch header[3] {
+	case socksAddressIPv4, socksAddressIPv6:
+		size := net.IPv4len
+		if header[3] == socksAddressIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return nil, err
+		}
+		host = net.IP(ip).String()
+	case socksAddressDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return nil, err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return nil, err
+		}
+		host = string(domain)
+	default:
+		_ = writeSocksReply(conn, socksReplyNoAddrType)
+		return nil, fmt.Errorf("unsupported socks address type: %d", header[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return nil, err
+	}
+	request.target = net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+	return request, nil
+}
+
+// readSocksArgs читает имя пользователя и пароль и собирает из них параметры
+// моста. Если параметры уместились в имя, пароль состоит из одного NUL.
+func readSocksArgs(conn io.ReadWriter) (map[string]string, error) {
+	version := make([]byte, 2)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		return nil, err
+	}
+	if version[0] != socksAuthVersion {
+		return nil, fmt.Errorf("unsupported socks auth version: %d", version[0])
+	}
+	username := make([]byte, version[1])
+	if _, err := io.ReadFull(conn, username); err != nil {
+		return nil, err
+	}
+	length := make([]byte, 1)
+	if _, err := io.ReadFull(conn, length); err != nil {
+		return nil, err
+	}
+	password := make([]byte, length[0])
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return nil, err
+	}
+
+	value := string(username)
+	if string(password) != "\x00" {
+		value += string(password)
+	}
+	args, err := parseClientArgs(value)
+	if err != nil {
+		_, _ = conn.Write([]byte{socksAuthVersion, 0x01})
+		return nil, fmt.Errorf("invalid bridge arguments: %w", err)
+	}
+	if _, err := conn.Write([]byte{socksAuthVersion, 0x00}); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// writeSocksReply отправляет ответ на запрос CONNECT
+func writeSocksReply(w io.Writer, code byte) error {
+	_, err := w.Write([]byte{socksVersion, code, 0x00, socksAddressIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// socksReplyCode код ответа по ошибке подключения к мосту
+func socksReplyCode(err error) byte {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return socksReplyRefused
+	}
+	return socksReplyFailure
+}
@@ -2,42 +2,71 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
 	"go.uber.org/zap"
 )
 
-// BypassService сервис для управления DPI bypass
+// reloadDrainTimeout время, которое соединения перезапущенной сессии
+// обслуживаются старым listener
+const reloadDrainTimeout = 30 * time.Second
+
+// stopDrainTimeout время, за которое соединения останавливаемой сессии
+// могут завершиться до принудительного закрытия
+const stopDrainTimeout = 10 * time.Second
+
+// BypassService сервис для управления DPI bypass. Запуск сессии в адаптере
+// (для auto - с перебором методов) и перезагрузка выполняются под
+// блокировкой конфигурации, а не всего сервиса
 type BypassService struct {
-	configs map[string]*domain.BypassConfig
-	adapter ports.BypassAdapter
-	mutex   sync.RWMutex
-	logger  *zap.Logger
+	repo     ports.BypassRepository
+	sessions map[string]*domain.BypassSession
+	adapter  ports.BypassAdapter
+	mutex    sync.RWMutex
+	logger   *zap.Logger
+	// configLocks упорядочивают запуск и перезагрузку сессий одной
+	// конфигурации
+	configLocks map[string]*sync.Mutex
 }
 
 // NewBypassService создает новый bypass сервис
-func NewBypassService(adapter ports.BypassAdapter, logger *zap.Logger) ports.DPIBypassService {
+func NewBypassService(repo ports.BypassRepository, adapter ports.BypassAdapter, logger *zap.Logger) ports.DPIBypassService {
 	return &BypassService{
-		configs: make(map[string]*domain.BypassConfig),
-		adapter: adapter,
-		logger:  logger,
+		repo:        repo,
+		sessions:    make(map[string]*domain.BypassSession),
+		adapter:     adapter,
+		logger:      logger,
+		configLocks: make(map[string]*sync.Mutex),
 	}
 }
 
-// CreateBypassConfig создает новую bypass конфигурацию
-func (s *BypassService) CreateBypassConfig(ctx context.Context, req *domain.CreateBypassConfigRequest) (*domain.BypassConfig, error) {
+// configLock блокировка запуска и перезагрузки сессий конфигурации
+func (s *BypassService) configLock(configID string) *sync.Mutex {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// Генерируем ID (в продакшене использовать UUID)
-	id := fmt.Sprintf("%d", time.Now().UnixNano())
+	lock, ok := s.configLocks[configID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.configLocks[configID] = lock
+	}
+	return lock
+}
 
+// CreateBypassConfig создает новую bypass конфигурацию
+func (s *BypassService) CreateBypassConfig(ctx context.Context, req *domain.CreateBypassConfigRequest) (*domain.BypassConfig, error) {
 	config := &domain.BypassConfig{
-		ID:          id,
 		Name:        req.Name,
 		Description: req.Description,
 		Type:        req.Type,
@@ -45,58 +74,81 @@ func (s *BypassService) CreateBypassConfig(ctx context.Context, req *domain.Crea
 		Status:      domain.BypassStatusInactive,
 		Parameters:  req.Parameters,
 		Rules:       []*domain.BypassRule{},
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
 	}
 
-	s.configs[id] = config
+	if err := s.repo.CreateConfig(ctx, config); err != nil {
+		return nil, err
+	}
 
 	s.logger.Info("bypass configuration created",
-		zap.String("id", id),
+		zap.String("id", config.ID),
 		zap.String("name", req.Name),
 		zap.String("method", string(req.Method)))
 
 	return config, nil
 }
 
-// GetBypassConfig получает bypass конфигурацию по ID
+// GetBypassConfig получает bypass конфигурацию по ID вместе с правилами
 func (s *BypassService) GetBypassConfig(ctx context.Context, id string) (*domain.BypassConfig, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	config, err := s.repo.GetConfig(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	config, exists := s.configs[id]
-	if !exists {
-		return nil, fmt.Errorf("bypass configuration not found: %s", id)
+	rules, _, err := s.repo.ListRules(ctx, &domain.BypassRuleFilters{ConfigID: id})
+	if err != nil {
+		return nil, err
 	}
+	config.Rules = rules
 
 	return config, nil
 }
 
-// ListBypassConfigs возвращает список всех bypass конфигураций
+// ListBypassConfigs возвращает список bypass конфигураций
 func (s *BypassService) ListBypassConfigs(ctx context.Context, filters *domain.BypassConfigFilters) ([]*domain.BypassConfig, int, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	configs := make([]*domain.BypassConfig, 0, len(s.configs))
-	for _, config := range s.configs {
-		configs = append(configs, config)
-	}
-
-	return configs, len(configs), nil
+	return s.repo.ListConfigs(ctx, filters)
 }
 
 // StartBypass запускает bypass соединение
 func (s *BypassService) StartBypass(ctx context.Context, req *domain.StartBypassRequest) (*domain.BypassSession, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	// Перезагрузка конфигурации не должна пропустить запускаемую сессию
+	lock := s.configLock(req.ConfigID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	config, err := s.repo.GetConfig(ctx, req.ConfigID)
+	if err != nil {
+		return nil, err
+	}
 
-	config, exists := s.configs[req.ConfigID]
-	if !exists {
-		return nil, fmt.Errorf("bypass configuration not found: %s", req.ConfigID)
+	// Адаптер получает только включенные правила и действующих пользователей
+	rules, _, err := s.repo.ListRules(ctx, &domain.BypassRuleFilters{ConfigID: config.ID, Enabled: true})
+	if err != nil {
+		return nil, err
 	}
+	config.Rules = rules
+	users, _, err := s.repo.ListUsers(ctx, &domain.BypassUserFilters{ConfigID: config.ID})
+	if err != nil {
+		return nil, err
+	}
+	config.Users = users
 
 	// Генерируем ID сессии
-	sessionID := fmt.Sprintf("session_%d", time.Now().UnixNano())
+	sessionID := uuid.New().String()
+	startedAt := time.Now()
+
+	// Запускаем адаптер под ID сессии
+	if err := s.adapter.Start(sessionConfig(config, sessionID, req)); err != nil {
+		s.recordHistory(ctx, &domain.BypassSession{
+			ID:         sessionID,
+			ConfigID:   config.ID,
+			TargetHost: req.TargetHost,
+			TargetPort: req.TargetPort,
+			StartedAt:  startedAt,
+		}, nil, err)
+		s.setConfigStatus(ctx, config.ID, domain.BypassStatusError)
+		return nil, fmt.Errorf("failed to start bypass: %w", err)
+	}
 
 	session := &domain.BypassSession{
 		ID:         sessionID,
@@ -104,71 +156,218 @@ func (s *BypassService) StartBypass(ctx context.Context, req *domain.StartBypass
 		TargetHost: req.TargetHost,
 		TargetPort: req.TargetPort,
 		Status:     domain.BypassStatusActive,
-		StartedAt:  time.Now(),
+		StartedAt:  startedAt,
 		Message:    "Bypass session started",
+		Options:    req.Options,
 	}
 
-	config.Status = domain.BypassStatusActive
-	config.UpdatedAt = time.Now()
+	s.mutex.Lock()
+	s.sessions[sessionID] = session
+	s.mutex.Unlock()
+	s.setConfigStatus(ctx, config.ID, domain.BypassStatusActive)
 
 	s.logger.Info("bypass session started", zap.String("session_id", sessionID))
 	return session, nil
 }
 
-// StopBypass останавливает bypass соединение
+// sessionConfig копирует конфигурацию для сессии: цель и опции запроса
+// дополняют параметры конфигурации
+func sessionConfig(config *domain.BypassConfig, sessionID string, req *domain.StartBypassRequest) *domain.BypassConfig {
+	params := make(map[string]string, len(config.Parameters)+len(req.Options)+2)
+	for key, value := range config.Parameters {
+		params[key] = value
+	}
+	if req.TargetHost != "" {
+		params["target_host"] = req.TargetHost
+	}
+	if req.TargetPort > 0 {
+		params["target_port"] = strconv.Itoa(req.TargetPort)
+	}
+	for key, value := range req.Options {
+		params[key] = value
+	}
+
+	session := *config
+	session.ID = sessionID
+	session.Parameters = params
+	return &session
+}
+
+// StopBypass останавливает bypass соединение и записывает сессию в историю
 func (s *BypassService) StopBypass(ctx context.Context, sessionID string) error {
+	// Соединения завершаются без блокировки сервиса
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	session, exists := s.sessions[sessionID]
+	delete(s.sessions, sessionID)
+	s.mutex.Unlock()
+	if !exists {
+		return fmt.Errorf("bypass session not found: %s", sessionID)
+	}
+
+	// Сессия завершается и при ошибке остановки: ошибка попадает в историю
+	stats, stopErr := s.stopSession(sessionID, stopDrainTimeout)
+	s.recordHistory(ctx, session, stats, stopErr)
+	s.releaseConfig(ctx, session.ConfigID)
+
+	if stopErr != nil {
+		return fmt.Errorf("failed to stop bypass: %w", stopErr)
+	}
 
 	s.logger.Info("bypass session stopped", zap.String("session_id", sessionID))
 	return nil
 }
 
-// GetBypassStats получает статистику bypass соединения
+// StopAllBypass останавливает все сессии при завершении приложения.
+// Соединения завершаются не позже дедлайна ctx, сессии записываются в историю.
+func (s *BypassService) StopAllBypass(ctx context.Context) error {
+	s.mutex.Lock()
+	sessions := make([]*domain.BypassSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessions = make(map[string]*domain.BypassSession)
+	s.mutex.Unlock()
+
+	drainTimeout := stopDrainTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		drainTimeout = min(drainTimeout, time.Until(deadline))
+	}
+	// История записывается и после дедлайна остановки
+	recordCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(sessions))
+	for i, session := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats, err := s.stopSession(session.ID, drainTimeout)
+			s.recordHistory(recordCtx, session, stats, err)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to stop bypass session %s: %w", session.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	configs := make(map[string]bool)
+	for _, session := range sessions {
+		if !configs[session.ConfigID] {
+			configs[session.ConfigID] = true
+			s.releaseConfig(recordCtx, session.ConfigID)
+		}
+	}
+
+	s.logger.Info("bypass sessions stopped", zap.Int("count", len(sessions)))
+	return errors.Join(errs...)
+}
+
+// stopSession останавливает сессию в адаптере и возвращает итоговую
+// статистику. Адаптер с плавной остановкой ждет соединения не дольше
+// drainTimeout.
+func (s *BypassService) stopSession(sessionID string, drainTimeout time.Duration) (*domain.BypassStats, error) {
+	if drainer, ok := s.adapter.(ports.DrainableAdapter); ok {
+		return drainer.Drain(sessionID, drainTimeout)
+	}
+
+	// Статистику снимаем до остановки: после нее адаптер забывает сессию
+	stats, err := s.adapter.GetStats(sessionID)
+	if err != nil {
+		s.logger.Warn("failed to get bypass stats", zap.String("session_id", sessionID), zap.Error(err))
+	}
+	return stats, s.adapter.Stop(sessionID)
+}
+
+// releaseConfig отмечает конфигурацию неактивной, когда у нее не осталось
+// сессий
+func (s *BypassService) releaseConfig(ctx context.Context, configID string) {
+	s.mutex.RLock()
+	for _, other := range s.sessions {
+		if other.ConfigID == configID {
+			s.mutex.RUnlock()
+			return
+		}
+	}
+	s.mutex.RUnlock()
+
+	s.setConfigStatus(ctx, configID, domain.BypassStatusInactive)
+}
+
+// recordHistory записывает завершенную сессию в историю. Ошибка записи
+// не прерывает остановку сессии и только логируется.
+func (s *BypassService) recordHistory(ctx context.Context, session *domain.BypassSession, stats *domain.BypassStats, sessionErr error) {
+	endedAt := time.Now()
+	entry := &domain.BypassHistoryEntry{
+		ConfigID:        session.ConfigID,
+		SessionID:       session.ID,
+		TargetHost:      session.TargetHost,
+		TargetPort:      session.TargetPort,
+		Status:          domain.BypassStatusInactive,
+		StartedAt:       session.StartedAt,
+		EndedAt:         endedAt,
+		DurationSeconds: int64(endedAt.Sub(session.StartedAt).Seconds()),
+	}
+	if stats != nil {
+		entry.BytesTransferred = stats.BytesSent + stats.BytesReceived
+	}
+	if sessionErr != nil {
+		entry.Status = domain.BypassStatusError
+		entry.ErrorMessage = sessionErr.Error()
+	}
+
+	if err := s.repo.CreateHistoryEntry(ctx, entry); err != nil {
+		s.logger.Error("failed to record bypass history", zap.String("session_id", session.ID), zap.Error(err))
+	}
+}
+
+// setConfigStatus сохраняет статус конфигурации. Остальные поля не
+// записываются: за время запуска конфигурацию могли изменить
+func (s *BypassService) setConfigStatus(ctx context.Context, configID string, status domain.BypassStatus) {
+	if err := s.repo.UpdateConfigStatus(ctx, configID, status); err != nil {
+		s.logger.Error("failed to update bypass configuration status",
+			zap.String("id", configID), zap.String("status", string(status)), zap.Error(err))
+	}
+}
+
+// GetBypassStats получает статистику bypass сессии от адаптера, включая
+// статистику пользователей сервера
 func (s *BypassService) GetBypassStats(ctx context.Context, sessionID string) (*domain.BypassStats, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	return &domain.BypassStats{
-		ID:                     "stats_" + sessionID,
-		SessionID:              sessionID,
-		BytesSent:              1024,
-		BytesReceived:          2048,
-		PacketsSent:            100,
-		PacketsReceived:        200,
-		ConnectionsEstablished: 1,
-		ConnectionsFailed:      0,
-		SuccessRate:            1.0,
-		AverageLatency:         50.0,
-		StartTime:              time.Now().Add(-time.Hour),
-		EndTime:                time.Now(),
-	}, nil
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("bypass session not found: %s", sessionID)
+	}
+
+	stats, err := s.adapter.GetStats(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bypass stats: %w", err)
+	}
+	if stats == nil {
+		stats = &domain.BypassStats{StartTime: session.StartedAt, EndTime: time.Now()}
+	}
+	stats.ID = "stats_" + sessionID
+	stats.SessionID = sessionID
+	stats.ConfigID = session.ConfigID
+	return stats, nil
 }
 
 // DeleteBypassConfig удаляет bypass конфигурацию
 func (s *BypassService) DeleteBypassConfig(ctx context.Context, id string) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	config, exists := s.configs[id]
-	if !exists {
-		return fmt.Errorf("bypass configuration not found: %s", id)
+	if err := s.repo.DeleteConfig(ctx, id); err != nil {
+		return err
 	}
 
-	delete(s.configs, id)
-
-	s.logger.Info("bypass configuration deleted", zap.String("id", id), zap.String("name", config.Name))
+	s.logger.Info("bypass configuration deleted", zap.String("id", id))
 	return nil
 }
 
 // UpdateBypassConfig обновляет bypass конфигурацию
 func (s *BypassService) UpdateBypassConfig(ctx context.Context, req *domain.UpdateBypassConfigRequest) (*domain.BypassConfig, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	config, exists := s.configs[req.ID]
-	if !exists {
-		return nil, fmt.Errorf("bypass configuration not found: %s", req.ID)
+	config, err := s.GetBypassConfig(ctx, req.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	config.Name = req.Name
@@ -176,35 +375,139 @@ func (s *BypassService) UpdateBypassConfig(ctx context.Context, req *domain.Upda
 	config.Type = req.Type
 	config.Method = req.Method
 	config.Parameters = req.Parameters
-	config.UpdatedAt = time.Now()
 
+	if err := s.repo.UpdateConfig(ctx, config); err != nil {
+		return nil, err
+	}
+
+	config.Reloads = s.reloadSessions(ctx, config.ID)
 	return config, nil
 }
 
-// GetBypassStatus получает статус bypass сессии
+// reloadSessions применяет сохраненную конфигурацию, ее включенные правила
+// и действующих пользователей к запущенным сессиям конфигурации
+func (s *BypassService) reloadSessions(ctx context.Context, configID string) []*domain.SessionReload {
+	lock := s.configLock(configID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	s.mutex.RLock()
+	var sessions []*domain.BypassSession
+	for _, session := range s.sessions {
+		if session.ConfigID == configID {
+			sessions = append(sessions, session)
+		}
+	}
+	s.mutex.RUnlock()
+	if len(sessions) == 0 {
+		return nil
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+
+	config, err := s.repo.GetConfig(ctx, configID)
+	if err == nil {
+		config.Rules, _, err = s.repo.ListRules(ctx, &domain.BypassRuleFilters{ConfigID: configID, Enabled: true})
+	}
+	if err == nil {
+		config.Users, _, err = s.repo.ListUsers(ctx, &domain.BypassUserFilters{ConfigID: configID})
+	}
+
+	reloads := make([]*domain.SessionReload, 0, len(sessions))
+	for _, session := range sessions {
+		if err != nil {
+			reloads = append(reloads, &domain.SessionReload{
+				SessionID: session.ID,
+				Outcome:   domain.ReloadOutcomeFailed,
+				Message:   err.Error(),
+			})
+			continue
+		}
+		reloads = append(reloads, s.reloadSession(config, session))
+	}
+	return reloads
+}
+
+// reloadSession применяет конфигурацию к одной сессии. Адаптер без
+// поддержки перезагрузки перезапускает сессию.
+func (s *BypassService) reloadSession(config *domain.BypassConfig, session *domain.BypassSession) *domain.SessionReload {
+	next := sessionConfig(config, session.ID, &domain.StartBypassRequest{
+		ConfigID:   session.ConfigID,
+		TargetHost: session.TargetHost,
+		TargetPort: session.TargetPort,
+		Options:    session.Options,
+	})
+
+	reload := &domain.SessionReload{SessionID: session.ID}
+	var err error
+	if reloader, ok := s.adapter.(ports.ReloadableAdapter); ok {
+		reload.Outcome, err = reloader.Reload(next, reloadDrainTimeout)
+	} else {
+		reload.Outcome = domain.ReloadOutcomeRestarted
+		if err = s.adapter.Stop(session.ID); err == nil {
+			err = s.adapter.Start(next)
+		}
+	}
+
+	stopped := false
+	switch {
+	case err != nil:
+		reload.Outcome = domain.ReloadOutcomeFailed
+		reload.Message = err.Error()
+		stopped = !s.adapter.IsRunning(session.ID)
+		s.logger.Error("failed to reload bypass session",
+			zap.String("session_id", session.ID), zap.Error(err))
+	case reload.Outcome == domain.ReloadOutcomeApplied:
+		reload.Message = "Configuration applied in place"
+	default:
+		reload.Message = "Session restarted with new configuration"
+	}
+
+	s.mutex.Lock()
+	if stopped {
+		session.Status = domain.BypassStatusError
+	}
+	session.Message = reload.Message
+	s.mutex.Unlock()
+
+	s.logger.Info("bypass session reloaded",
+		zap.String("session_id", session.ID),
+		zap.String("outcome", string(reload.Outcome)))
+	return reload
+}
+
+// GetBypassStatus получает статус bypass сессии. Сообщение отражает
+// результат последней перезагрузки конфигурации.
 func (s *BypassService) GetBypassStatus(ctx context.Context, sessionID string) (*domain.BypassSessionStatus, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("bypass session not found: %s", sessionID)
+	}
+
 	return &domain.BypassSessionStatus{
-		SessionID:       sessionID,
-		Status:          domain.BypassStatusActive,
-		TargetHost:      "example.com",
-		TargetPort:      443,
-		StartedAt:       time.Now().Add(-time.Hour),
-		DurationSeconds: 3600,
-		Message:         "Session is active",
+		SessionID:       session.ID,
+		ConfigID:        session.ConfigID,
+		Status:          session.Status,
+		TargetHost:      session.TargetHost,
+		TargetPort:      session.TargetPort,
+		StartedAt:       session.StartedAt,
+		DurationSeconds: int64(time.Since(session.StartedAt).Seconds()),
+		Message:         session.Message,
 	}, nil
 }
 
-// GetBypassHistory получает историю bypass сессий
+// GetBypassHistory получает историю bypass сессий по конфигурации и времени
 func (s *BypassService) GetBypassHistory(ctx context.Context, req *domain.BypassHistoryRequest) ([]*domain.BypassHistoryEntry, int, error) {
-	return []*domain.BypassHistoryEntry{}, 0, nil
+	return s.repo.ListHistory(ctx, req)
 }
 
 // AddBypassRule добавляет правило bypass
 func (s *BypassService) AddBypassRule(ctx context.Context, req *domain.AddBypassRuleRequest) (*domain.BypassRule, error) {
-	ruleID := fmt.Sprintf("rule_%d", time.Now().UnixNano())
-
 	rule := &domain.BypassRule{
-		ID:         ruleID,
 		ConfigID:   req.ConfigID,
 		Name:       req.Name,
 		Type:       req.Type,
@@ -213,37 +516,151 @@ func (s *BypassService) AddBypassRule(ctx context.Context, req *domain.AddBypass
 		Parameters: req.Parameters,
 		Priority:   req.Priority,
 		Enabled:    true,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
 	}
 
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("bypass rule added", zap.String("id", rule.ID), zap.String("config_id", rule.ConfigID))
+	s.reloadSessions(ctx, rule.ConfigID)
 	return rule, nil
 }
 
 // UpdateBypassRule обновляет правило bypass
 func (s *BypassService) UpdateBypassRule(ctx context.Context, req *domain.UpdateBypassRuleRequest) (*domain.BypassRule, error) {
-	rule := &domain.BypassRule{
-		ID:         req.ID,
-		Name:       req.Name,
-		Type:       req.Type,
-		Action:     req.Action,
-		Pattern:    req.Pattern,
-		Parameters: req.Parameters,
-		Priority:   req.Priority,
-		Enabled:    req.Enabled,
-		UpdatedAt:  time.Now(),
+	rule, err := s.repo.GetRule(ctx, req.ID)
+	if err != nil {
+		return nil, err
 	}
 
+	rule.Name = req.Name
+	rule.Type = req.Type
+	rule.Action = req.Action
+	rule.Pattern = req.Pattern
+	rule.Parameters = req.Parameters
+	rule.Priority = req.Priority
+	rule.Enabled = req.Enabled
+
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.reloadSessions(ctx, rule.ConfigID)
 	return rule, nil
 }
 
 // DeleteBypassRule удаляет правило bypass
 func (s *BypassService) DeleteBypassRule(ctx context.Context, id string) error {
+	rule, err := s.repo.GetRule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteRule(ctx, id); err != nil {
+		return err
+	}
+
 	s.logger.Info("bypass rule deleted", zap.String("id", id))
+	s.reloadSessions(ctx, rule.ConfigID)
 	return nil
 }
 
 // ListBypassRules получает список правил bypass
 func (s *BypassService) ListBypassRules(ctx context.Context, filters *domain.BypassRuleFilters) ([]*domain.BypassRule, int, error) {
-	return []*domain.BypassRule{}, 0, nil
+	return s.repo.ListRules(ctx, filters)
+}
+
+// AddBypassUser добавляет пользователя сервера обхода и применяет его к
+// запущенным сессиям. Повторное добавление отозванного пользователя
+// восстанавливает его с новым ключом.
+func (s *BypassService) AddBypassUser(ctx context.Context, req *domain.AddBypassUserRequest) (*domain.BypassUser, error) {
+	if req.UserID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+
+	config, err := s.repo.GetConfig(ctx, req.ConfigID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := req.Credential
+	if credential == "" {
+		if credential, err = generateCredential(config); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.repo.GetUser(ctx, config.ID, req.UserID)
+	switch {
+	case err != nil:
+		user = &domain.BypassUser{ID: req.UserID, ConfigID: config.ID, Credential: credential}
+		if err := s.repo.CreateUser(ctx, user); err != nil {
+			return nil, err
+		}
+	case user.Revoked:
+		user.Credential = credential
+		user.Revoked = false
+		if err := s.repo.UpdateUser(ctx, user); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("bypass user already exists: %s", req.UserID)
+	}
+
+	s.logger.Info("bypass user added", zap.String("user_id", user.ID), zap.String("config_id", user.ConfigID))
+	s.reloadSessions(ctx, config.ID)
+	return user, nil
+}
+
+// RevokeBypassUser отзывает пользователя: запущенные сессии перестают
+// принимать его ключ и разрывают его соединения без перезапуска
+func (s *BypassService) RevokeBypassUser(ctx context.Context, configID, userID string) error {
+	user, err := s.repo.GetUser(ctx, configID, userID)
+	if err != nil {
+		return err
+	}
+	if user.Revoked {
+		return nil
+	}
+
+	user.Revoked = true
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+
+	s.logger.Info("bypass user revoked", zap.String("user_id", userID), zap.String("config_id", configID))
+	s.reloadSessions(ctx, configID)
+	return nil
+}
+
+// ListBypassUsers получает список пользователей сервера обхода
+func (s *BypassService) ListBypassUsers(ctx context.Context, filters *domain.BypassUserFilters) ([]*domain.BypassUser, int, error) {
+	return s.repo.ListUsers(ctx, filters)
+}
+
+// generateCredential создает ключ пользователя в формате метода
+// конфигурации: PSK Shadowsocks 2022, UUID VLESS или секрет obfs4
+func generateCredential(config *domain.BypassConfig) (string, error) {
+	switch config.Method {
+	case domain.BypassMethodShadowsocks:
+		size := 32
+		if config.Parameters["encryption"] == "2022-blake3-aes-128-gcm" {
+			size = 16
+		}
+		key := make([]byte, size)
+		if _, err := rand.Read(key); err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(key), nil
+	case domain.BypassMethodV2Ray:
+		return uuid.New().String(), nil
+	case domain.BypassMethodObfs4:
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(secret), nil
+	default:
+		return "", fmt.Errorf("bypass method %s does not support users", config.Method)
+	}
 }
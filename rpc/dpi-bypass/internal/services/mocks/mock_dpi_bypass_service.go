@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
@@ -35,6 +36,20 @@ func (m *MockDPIBypassService) EXPECT() *MockDPIBypassServiceMockRecorder {
 	return m.recorder
 }
 
+// AcknowledgeCensorshipSignals mocks base method.
+func (m *MockDPIBypassService) AcknowledgeCensorshipSignals(ctx context.Context, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcknowledgeCensorshipSignals", ctx, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcknowledgeCensorshipSignals indicates an expected call of AcknowledgeCensorshipSignals.
+func (mr *MockDPIBypassServiceMockRecorder) AcknowledgeCensorshipSignals(ctx, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcknowledgeCensorshipSignals", reflect.TypeOf((*MockDPIBypassService)(nil).AcknowledgeCensorshipSignals), ctx, until)
+}
+
 // AddBypassRule mocks base method.
 func (m *MockDPIBypassService) AddBypassRule(ctx context.Context, req *domain.AddBypassRuleRequest) (*domain.BypassRule, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +65,21 @@ func (mr *MockDPIBypassServiceMockRecorder) AddBypassRule(ctx, req interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBypassRule", reflect.TypeOf((*MockDPIBypassService)(nil).AddBypassRule), ctx, req)
 }
 
+// AddBypassUser mocks base method.
+func (m *MockDPIBypassService) AddBypassUser(ctx context.Context, req *domain.AddBypassUserRequest) (*domain.BypassUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBypassUser", ctx, req)
+	ret0, _ := ret[0].(*domain.BypassUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBypassUser indicates an expected call of AddBypassUser.
+func (mr *MockDPIBypassServiceMockRecorder) AddBypassUser(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBypassUser", reflect.TypeOf((*MockDPIBypassService)(nil).AddBypassUser), ctx, req)
+}
+
 // CreateBypassConfig mocks base method.
 func (m *MockDPIBypassService) CreateBypassConfig(ctx context.Context, req *domain.CreateBypassConfigRequest) (*domain.BypassConfig, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +95,21 @@ func (mr *MockDPIBypassServiceMockRecorder) CreateBypassConfig(ctx, req interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBypassConfig", reflect.TypeOf((*MockDPIBypassService)(nil).CreateBypassConfig), ctx, req)
 }
 
+// CreateSubscriptionToken mocks base method.
+func (m *MockDPIBypassService) CreateSubscriptionToken(ctx context.Context, req *domain.CreateSubscriptionTokenRequest) (*domain.SubscriptionToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscriptionToken", ctx, req)
+	ret0, _ := ret[0].(*domain.SubscriptionToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscriptionToken indicates an expected call of CreateSubscriptionToken.
+func (mr *MockDPIBypassServiceMockRecorder) CreateSubscriptionToken(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscriptionToken", reflect.TypeOf((*MockDPIBypassService)(nil).CreateSubscriptionToken), ctx, req)
+}
+
 // DeleteBypassConfig mocks base method.
 func (m *MockDPIBypassService) DeleteBypassConfig(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -93,6 +138,21 @@ func (mr *MockDPIBypassServiceMockRecorder) DeleteBypassRule(ctx, id interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBypassRule", reflect.TypeOf((*MockDPIBypassService)(nil).DeleteBypassRule), ctx, id)
 }
 
+// ExportCensorshipSignals mocks base method.
+func (m *MockDPIBypassService) ExportCensorshipSignals(ctx context.Context, until time.Time) ([]*domain.CensorshipSignalStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportCensorshipSignals", ctx, until)
+	ret0, _ := ret[0].([]*domain.CensorshipSignalStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportCensorshipSignals indicates an expected call of ExportCensorshipSignals.
+func (mr *MockDPIBypassServiceMockRecorder) ExportCensorshipSignals(ctx, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportCensorshipSignals", reflect.TypeOf((*MockDPIBypassService)(nil).ExportCensorshipSignals), ctx, until)
+}
+
 // GetBypassConfig mocks base method.
 func (m *MockDPIBypassService) GetBypassConfig(ctx context.Context, id string) (*domain.BypassConfig, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +214,51 @@ func (mr *MockDPIBypassServiceMockRecorder) GetBypassStatus(ctx, sessionID inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBypassStatus", reflect.TypeOf((*MockDPIBypassService)(nil).GetBypassStatus), ctx, sessionID)
 }
 
+// GetCensorshipSignals mocks base method.
+func (m *MockDPIBypassService) GetCensorshipSignals(ctx context.Context, filters *domain.CensorshipSignalFilters) ([]*domain.CensorshipSignalStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCensorshipSignals", ctx, filters)
+	ret0, _ := ret[0].([]*domain.CensorshipSignalStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCensorshipSignals indicates an expected call of GetCensorshipSignals.
+func (mr *MockDPIBypassServiceMockRecorder) GetCensorshipSignals(ctx, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCensorshipSignals", reflect.TypeOf((*MockDPIBypassService)(nil).GetCensorshipSignals), ctx, filters)
+}
+
+// GetShareLink mocks base method.
+func (m *MockDPIBypassService) GetShareLink(ctx context.Context, configID, userID string) (*domain.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareLink", ctx, configID, userID)
+	ret0, _ := ret[0].(*domain.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareLink indicates an expected call of GetShareLink.
+func (mr *MockDPIBypassServiceMockRecorder) GetShareLink(ctx, configID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareLink", reflect.TypeOf((*MockDPIBypassService)(nil).GetShareLink), ctx, configID, userID)
+}
+
+// GetSubscription mocks base method.
+func (m *MockDPIBypassService) GetSubscription(ctx context.Context, token string, access *domain.SubscriptionAccess) (*domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscription", ctx, token, access)
+	ret0, _ := ret[0].(*domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscription indicates an expected call of GetSubscription.
+func (mr *MockDPIBypassServiceMockRecorder) GetSubscription(ctx, token, access interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockDPIBypassService)(nil).GetSubscription), ctx, token, access)
+}
+
 // ListBypassConfigs mocks base method.
 func (m *MockDPIBypassService) ListBypassConfigs(ctx context.Context, filters *domain.BypassConfigFilters) ([]*domain.BypassConfig, int, error) {
 	m.ctrl.T.Helper()
@@ -186,6 +291,82 @@ func (mr *MockDPIBypassServiceMockRecorder) ListBypassRules(ctx, filters interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBypassRules", reflect.TypeOf((*MockDPIBypassService)(nil).ListBypassRules), ctx, filters)
 }
 
+// ListBypassUsers mocks base method.
+func (m *MockDPIBypassService) ListBypassUsers(ctx context.Context, filters *domain.BypassUserFilters) ([]*domain.BypassUser, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBypassUsers", ctx, filters)
+	ret0, _ := ret[0].([]*domain.BypassUser)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListBypassUsers indicates an expected call of ListBypassUsers.
+func (mr *MockDPIBypassServiceMockRecorder) ListBypassUsers(ctx, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBypassUsers", reflect.TypeOf((*MockDPIBypassService)(nil).ListBypassUsers), ctx, filters)
+}
+
+// ListSubscriptionAccesses mocks base method.
+func (m *MockDPIBypassService) ListSubscriptionAccesses(ctx context.Context, filters *domain.SubscriptionAccessFilters) ([]*domain.SubscriptionAccess, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptionAccesses", ctx, filters)
+	ret0, _ := ret[0].([]*domain.SubscriptionAccess)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListSubscriptionAccesses indicates an expected call of ListSubscriptionAccesses.
+func (mr *MockDPIBypassServiceMockRecorder) ListSubscriptionAccesses(ctx, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptionAccesses", reflect.TypeOf((*MockDPIBypassService)(nil).ListSubscriptionAccesses), ctx, filters)
+}
+
+// ListSubscriptionTokens mocks base method.
+func (m *MockDPIBypassService) ListSubscriptionTokens(ctx context.Context, filters *domain.SubscriptionTokenFilters) ([]*domain.SubscriptionToken, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptionTokens", ctx, filters)
+	ret0, _ := ret[0].([]*domain.SubscriptionToken)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListSubscriptionTokens indicates an expected call of ListSubscriptionTokens.
+func (mr *MockDPIBypassServiceMockRecorder) ListSubscriptionTokens(ctx, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptionTokens", reflect.TypeOf((*MockDPIBypassService)(nil).ListSubscriptionTokens), ctx, filters)
+}
+
+// RevokeBypassUser mocks base method.
+func (m *MockDPIBypassService) RevokeBypassUser(ctx context.Context, configID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeBypassUser", ctx, configID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeBypassUser indicates an expected call of RevokeBypassUser.
+func (mr *MockDPIBypassServiceMockRecorder) RevokeBypassUser(ctx, configID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeBypassUser", reflect.TypeOf((*MockDPIBypassService)(nil).RevokeBypassUser), ctx, configID, userID)
+}
+
+// RevokeSubscriptionToken mocks base method.
+func (m *MockDPIBypassService) RevokeSubscriptionToken(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSubscriptionToken", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSubscriptionToken indicates an expected call of RevokeSubscriptionToken.
+func (mr *MockDPIBypassServiceMockRecorder) RevokeSubscriptionToken(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSubscriptionToken", reflect.TypeOf((*MockDPIBypassService)(nil).RevokeSubscriptionToken), ctx, id)
+}
+
 // StartBypass mocks base method.
 func (m *MockDPIBypassService) StartBypass(ctx context.Context, req *domain.StartBypassRequest) (*domain.BypassSession, error) {
 	m.ctrl.T.Helper()
@@ -201,6 +382,20 @@ func (mr *MockDPIBypassServiceMockRecorder) StartBypass(ctx, req interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartBypass", reflect.TypeOf((*MockDPIBypassService)(nil).StartBypass), ctx, req)
 }
 
+// StopAllBypass mocks base method.
+func (m *MockDPIBypassService) StopAllBypass(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopAllBypass", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopAllBypass indicates an expected call of StopAllBypass.
+func (mr *MockDPIBypassServiceMockRecorder) StopAllBypass(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopAllBypass", reflect.TypeOf((*MockDPIBypassService)(nil).StopAllBypass), ctx)
+}
+
 // StopBypass mocks base method.
 func (m *MockDPIBypassService) StopBypass(ctx context.Context, sessionID string) error {
 	m.ctrl.T.Helper()
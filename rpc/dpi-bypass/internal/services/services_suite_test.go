@@ -2,11 +2,16 @@ package services_test
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/database"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/services"
 	. "github.com/par1ram/silence/rpc/dpi-bypass/internal/services/mocks"
@@ -14,6 +19,9 @@ import (
 )
 
 //go:generate mockgen -destination=mocks/mock_bypass_adapter.go -package=mocks github.com/par1ram/silence/rpc/dpi-bypass/internal/ports BypassAdapter
+//go:generate mockgen -destination=mocks/mock_drainable_adapter.go -package=mocks github.com/par1ram/silence/rpc/dpi-bypass/internal/ports DrainableAdapter
+//go:generate mockgen -destination=mocks/mock_reloadable_adapter.go -package=mocks github.com/par1ram/silence/rpc/dpi-bypass/internal/ports ReloadableAdapter
+//go:generate mockgen -destination=mocks/mock_signal_adapter.go -package=mocks github.com/par1ram/silence/rpc/dpi-bypass/internal/ports SignalAdapter
 
 func TestServices(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -32,7 +40,7 @@ var _ = Describe("Services", func() {
 		ctrl = gomock.NewController(GinkgoT())
 		mockAdapter = NewMockBypassAdapter(ctrl)
 		logger = zap.NewNop()
-		bypassService = services.NewBypassService(mockAdapter, logger).(*services.BypassService)
+		bypassService = services.NewBypassService(database.NewMemoryRepository(), mockAdapter, logger).(*services.BypassService)
 		healthService = services.NewHealthService("test-service", "1.0.0")
 		ctx = context.Background()
 	})
@@ -195,6 +203,721 @@ var _ = Describe("Services", func() {
 		})
 	})
 
+	Describe("StartBypass and StopBypass", func() {
+		var config *domain.BypassConfig
+
+		BeforeEach(func() {
+			var err error
+			config, err = bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:       "auto-bypass",
+				Type:       domain.BypassTypeTunnelObfuscation,
+				Method:     domain.BypassMethodAuto,
+				Parameters: map[string]string{"local_port": "1080", "candidates": "shadowsocks,v2ray"},
+			})
+			Expect(err).To(BeNil())
+		})
+
+		It("should start adapter with session target and stop it", func() {
+			mockAdapter.EXPECT().Start(gomock.Any()).DoAndReturn(func(sessionConfig *domain.BypassConfig) error {
+				Expect(sessionConfig.Method).To(Equal(domain.BypassMethodAuto))
+				Expect(sessionConfig.Parameters).To(HaveKeyWithValue("target_host", "blocked.example"))
+				Expect(sessionConfig.Parameters).To(HaveKeyWithValue("target_port", "443"))
+				Expect(sessionConfig.Parameters).To(HaveKeyWithValue("candidates", "shadowsocks,v2ray"))
+				return nil
+			})
+
+			session, err := bypassService.StartBypass(ctx, &domain.StartBypassRequest{
+				ConfigID:   config.ID,
+				TargetHost: "blocked.example",
+				TargetPort: 443,
+			})
+			Expect(err).To(BeNil())
+
+			stored, err := bypassService.GetBypassConfig(ctx, config.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Status).To(Equal(domain.BypassStatusActive))
+			Expect(stored.Parameters).NotTo(HaveKey("target_host"))
+
+			mockAdapter.EXPECT().GetStats(session.ID).Return(&domain.BypassStats{BytesSent: 100, BytesReceived: 400}, nil)
+			mockAdapter.EXPECT().Stop(session.ID).Return(nil)
+			Expect(bypassService.StopBypass(ctx, session.ID)).To(Succeed())
+
+			stored, err = bypassService.GetBypassConfig(ctx, config.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Status).To(Equal(domain.BypassStatusInactive))
+
+			entries, total, err := bypassService.GetBypassHistory(ctx, &domain.BypassHistoryRequest{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(1))
+			Expect(entries[0].SessionID).To(Equal(session.ID))
+			Expect(entries[0].TargetHost).To(Equal("blocked.example"))
+			Expect(entries[0].BytesTransferred).To(Equal(int64(500)))
+			Expect(entries[0].Status).To(Equal(domain.BypassStatusInactive))
+			Expect(entries[0].ErrorMessage).To(BeEmpty())
+		})
+
+		It("should pass enabled rules to adapter", func() {
+			enabled, err := bypassService.AddBypassRule(ctx, &domain.AddBypassRuleRequest{
+				ConfigID: config.ID,
+				Name:     "block-ads",
+				Type:     domain.RuleTypeDomain,
+				Action:   domain.RuleActionBlock,
+				Pattern:  "*.ads.example",
+				Priority: 10,
+			})
+			Expect(err).To(BeNil())
+			disabled, err := bypassService.AddBypassRule(ctx, &domain.AddBypassRuleRequest{
+				ConfigID: config.ID,
+				Name:     "bypass-all",
+				Type:     domain.RuleTypeDomain,
+				Action:   domain.RuleActionBypass,
+				Pattern:  "*",
+			})
+			Expect(err).To(BeNil())
+			_, err = bypassService.UpdateBypassRule(ctx, &domain.UpdateBypassRuleRequest{
+				ID:      disabled.ID,
+				Name:    disabled.Name,
+				Type:    disabled.Type,
+				Action:  disabled.Action,
+				Pattern: disabled.Pattern,
+				Enabled: false,
+			})
+			Expect(err).To(BeNil())
+
+			stored, err := bypassService.GetBypassConfig(ctx, config.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Rules).To(HaveLen(2))
+
+			mockAdapter.EXPECT().Start(gomock.Any()).DoAndReturn(func(sessionConfig *domain.BypassConfig) error {
+				Expect(sessionConfig.Rules).To(HaveLen(1))
+				Expect(sessionConfig.Rules[0].ID).To(Equal(enabled.ID))
+				return nil
+			})
+			_, err = bypassService.StartBypass(ctx, &domain.StartBypassRequest{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+		})
+
+		It("should not block other configurations while adapter is probing", func() {
+			other, err := bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:   "other-bypass",
+				Type:   domain.BypassTypeTunnelObfuscation,
+				Method: domain.BypassMethodShadowsocks,
+			})
+			Expect(err).To(BeNil())
+
+			probing := make(chan struct{})
+			release := make(chan struct{})
+			mockAdapter.EXPECT().Start(gomock.Any()).DoAndReturn(func(sessionConfig *domain.BypassConfig) error {
+				if sessionConfig.Name == config.Name {
+					close(probing)
+					<-release
+				}
+				return nil
+			}).Times(2)
+
+			done := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				_, err := bypassService.StartBypass(ctx, &domain.StartBypassRequest{ConfigID: config.ID})
+				done <- err
+			}()
+			Eventually(probing).Should(BeClosed())
+
+			session, err := bypassService.StartBypass(ctx, &domain.StartBypassRequest{ConfigID: other.ID})
+			Expect(err).To(BeNil())
+			_, err = bypassService.GetBypassStatus(ctx, session.ID)
+			Expect(err).To(BeNil())
+
+			close(release)
+			Eventually(done).Should(Receive(BeNil()))
+		})
+
+		It("should keep configuration edits made while adapter is probing", func() {
+			probing := make(chan struct{})
+			release := make(chan struct{})
+			gomock.InOrder(
+				mockAdapter.EXPECT().Start(gomock.Any()).DoAndReturn(func(*domain.BypassConfig) error {
+					close(probing)
+					<-release
+					return nil
+				}),
+				mockAdapter.EXPECT().Stop(gomock.Any()).Return(nil),
+				mockAdapter.EXPECT().Start(gomock.Any()).DoAndReturn(func(sessionConfig *domain.BypassConfig) error {
+					Expect(sessionConfig.Parameters).To(HaveKeyWithValue("candidates", "obfs4"))
+					return nil
+				}),
+			)
+
+			started := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				_, err := bypassService.StartBypass(ctx, &domain.StartBypassRequest{ConfigID: config.ID})
+				started <- err
+			}()
+			Eventually(probing).Should(BeClosed())
+
+			// Правка сохраняется сразу, перезагрузка сессий ждет запуска
+			updated := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				_, err := bypassService.UpdateBypassConfig(ctx, &domain.UpdateBypassConfigRequest{
+					ID:         config.ID,
+					Name:       config.Name,
+					Type:       config.Type,
+					Method:     config.Method,
+					Parameters: map[string]string{"local_port": "1080", "candidates": "obfs4"},
+				})
+				updated <- err
+			}()
+			Eventually(func() map[string]string {
+				stored, err := bypassService.GetBypassConfig(ctx, config.ID)
+				Expect(err).To(BeNil())
+				return stored.Parameters
+			}).Should(HaveKeyWithValue("candidates", "obfs4"))
+
+			close(release)
+			Eventually(started).Should(Receive(BeNil()))
+			Eventually(updated).Should(Receive(BeNil()))
+
+			stored, err := bypassService.GetBypassConfig(ctx, config.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Status).To(Equal(domain.BypassStatusActive))
+			Expect(stored.Parameters).To(HaveKeyWithValue("candidates", "obfs4"))
+		})
+
+		It("should return adapter error when no method works", func() {
+			mockAdapter.EXPECT().Start(gomock.Any()).Return(errors.New("no working bypass method"))
+
+			session, err := bypassService.StartBypass(ctx, &domain.StartBypassRequest{ConfigID: config.ID})
+			Expect(err).To(MatchError(ContainSubstring("no working bypass method")))
+			Expect(session).To(BeNil())
+
+			stored, err := bypassService.GetBypassConfig(ctx, config.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Status).To(Equal(domain.BypassStatusError))
+
+			entries, _, err := bypassService.GetBypassHistory(ctx, &domain.BypassHistoryRequest{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Status).To(Equal(domain.BypassStatusError))
+			Expect(entries[0].ErrorMessage).To(ContainSubstring("no working bypass method"))
+		})
+
+		It("should return error for unknown session", func() {
+			Expect(bypassService.StopBypass(ctx, "unknown")).NotTo(Succeed())
+		})
+	})
+
+	Describe("Draining sessions", func() {
+		var drainable *MockDrainableAdapter
+		var config *domain.BypassConfig
+
+		BeforeEach(func() {
+			drainable = NewMockDrainableAdapter(ctrl)
+			bypassService = services.NewBypassService(database.NewMemoryRepository(), drainable, logger).(*services.BypassService)
+
+			var err error
+			config, err = bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:       "ss",
+				Method:     domain.BypassMethodShadowsocks,
+				Parameters: map[string]string{"local_port": "1080", "remote_host": "a.example"},
+			})
+			Expect(err).To(BeNil())
+		})
+
+		start := func() *domain.BypassSession {
+			drainable.EXPECT().Start(gomock.Any()).Return(nil)
+			session, err := bypassService.StartBypass(ctx, &domain.StartBypassRequest{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+			return session
+		}
+
+		It("should record final stats after connections finish", func() {
+			session := start()
+
+			// Статистика берется после завершения соединений, без GetStats
+			drainable.EXPECT().Drain(session.ID, gomock.Any()).
+				Return(&domain.BypassStats{BytesSent: 300, BytesReceived: 700, EndTime: time.Now()}, nil)
+			Expect(bypassService.StopBypass(ctx, session.ID)).To(Succeed())
+
+			entries, _, err := bypassService.GetBypassHistory(ctx, &domain.BypassHistoryRequest{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].BytesTransferred).To(Equal(int64(1000)))
+			Expect(entries[0].Status).To(Equal(domain.BypassStatusInactive))
+
+			stored, err := bypassService.GetBypassConfig(ctx, config.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Status).To(Equal(domain.BypassStatusInactive))
+		})
+
+		It("should stop all sessions within shutdown deadline", func() {
+			first := start()
+			second := start()
+
+			shutdownCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			for _, session := range []*domain.BypassSession{first, second} {
+				drainable.EXPECT().Drain(session.ID, gomock.Any()).DoAndReturn(func(_ string, timeout time.Duration) (*domain.BypassStats, error) {
+					Expect(timeout).To(BeNumerically("<=", 2*time.Second))
+					return &domain.BypassStats{BytesSent: 10, BytesReceived: 20}, nil
+				})
+			}
+			Expect(bypassService.StopAllBypass(shutdownCtx)).To(Succeed())
+
+			_, err := bypassService.GetBypassStatus(ctx, first.ID)
+			Expect(err).NotTo(BeNil())
+
+			entries, total, err := bypassService.GetBypassHistory(ctx, &domain.BypassHistoryRequest{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(2))
+			Expect(entries[0].BytesTransferred).To(Equal(int64(30)))
+
+			stored, err := bypassService.GetBypassConfig(ctx, config.ID)
+			Expect(err).To(BeNil())
+			Expect(stored.Status).To(Equal(domain.BypassStatusInactive))
+		})
+
+		It("should return drain errors and still record history", func() {
+			session := start()
+
+			drainable.EXPECT().Drain(session.ID, gomock.Any()).Return(nil, errors.New("drain failed"))
+			Expect(bypassService.StopAllBypass(ctx)).To(MatchError(ContainSubstring("drain failed")))
+
+			entries, _, err := bypassService.GetBypassHistory(ctx, &domain.BypassHistoryRequest{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].ErrorMessage).To(ContainSubstring("drain failed"))
+		})
+	})
+
+	Describe("Hot reload", func() {
+		var reloadable *MockReloadableAdapter
+		var config *domain.BypassConfig
+		var session *domain.BypassSession
+
+		BeforeEach(func() {
+			reloadable = NewMockReloadableAdapter(ctrl)
+			bypassService = services.NewBypassService(database.NewMemoryRepository(), reloadable, logger).(*services.BypassService)
+
+			var err error
+			config, err = bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:       "ss",
+				Method:     domain.BypassMethodShadowsocks,
+				Parameters: map[string]string{"local_port": "1080", "remote_host": "a.example"},
+			})
+			Expect(err).To(BeNil())
+
+			reloadable.EXPECT().Start(gomock.Any()).Return(nil)
+			session, err = bypassService.StartBypass(ctx, &domain.StartBypassRequest{
+				ConfigID:   config.ID,
+				TargetHost: "blocked.example",
+				Options:    map[string]string{"udp_mode": "native"},
+			})
+			Expect(err).To(BeNil())
+		})
+
+		update := func(params map[string]string) (*domain.BypassConfig, error) {
+			return bypassService.UpdateBypassConfig(ctx, &domain.UpdateBypassConfigRequest{
+				ID:         config.ID,
+				Name:       config.Name,
+				Method:     config.Method,
+				Parameters: params,
+			})
+		}
+
+		It("should apply parameters in place and keep session options", func() {
+			reloadable.EXPECT().Reload(gomock.Any(), gomock.Any()).DoAndReturn(func(next *domain.BypassConfig, drain time.Duration) (domain.ReloadOutcome, error) {
+				Expect(next.ID).To(Equal(session.ID))
+				Expect(next.Parameters).To(HaveKeyWithValue("remote_host", "b.example"))
+				Expect(next.Parameters).To(HaveKeyWithValue("target_host", "blocked.example"))
+				Expect(next.Parameters).To(HaveKeyWithValue("udp_mode", "native"))
+				Expect(drain).To(BeNumerically(">", 0))
+				return domain.ReloadOutcomeApplied, nil
+			})
+
+			updated, err := update(map[string]string{"local_port": "1080", "remote_host": "b.example"})
+			Expect(err).To(BeNil())
+			Expect(updated.Reloads).To(HaveLen(1))
+			Expect(updated.Reloads[0].SessionID).To(Equal(session.ID))
+			Expect(updated.Reloads[0].Outcome).To(Equal(domain.ReloadOutcomeApplied))
+
+			status, err := bypassService.GetBypassStatus(ctx, session.ID)
+			Expect(err).To(BeNil())
+			Expect(status.Status).To(Equal(domain.BypassStatusActive))
+			Expect(status.Message).To(Equal(updated.Reloads[0].Message))
+		})
+
+		It("should report restart", func() {
+			reloadable.EXPECT().Reload(gomock.Any(), gomock.Any()).Return(domain.ReloadOutcomeRestarted, nil)
+
+			updated, err := update(map[string]string{"local_port": "1081"})
+			Expect(err).To(BeNil())
+			Expect(updated.Reloads[0].Outcome).To(Equal(domain.ReloadOutcomeRestarted))
+		})
+
+		It("should mark session failed when it stopped", func() {
+			reloadable.EXPECT().Reload(gomock.Any(), gomock.Any()).Return(domain.ReloadOutcomeFailed, errors.New("address already in use"))
+			reloadable.EXPECT().IsRunning(session.ID).Return(false)
+
+			updated, err := update(map[string]string{"local_port": "1081"})
+			Expect(err).To(BeNil())
+			Expect(updated.Reloads[0].Outcome).To(Equal(domain.ReloadOutcomeFailed))
+			Expect(updated.Reloads[0].Message).To(ContainSubstring("address already in use"))
+
+			status, err := bypassService.GetBypassStatus(ctx, session.ID)
+			Expect(err).To(BeNil())
+			Expect(status.Status).To(Equal(domain.BypassStatusError))
+		})
+
+		It("should reload sessions when rules change", func() {
+			reloadable.EXPECT().Reload(gomock.Any(), gomock.Any()).DoAndReturn(func(next *domain.BypassConfig, _ time.Duration) (domain.ReloadOutcome, error) {
+				Expect(next.Rules).To(HaveLen(1))
+				return domain.ReloadOutcomeApplied, nil
+			})
+			rule, err := bypassService.AddBypassRule(ctx, &domain.AddBypassRuleRequest{
+				ConfigID: config.ID,
+				Name:     "block-ads",
+				Type:     domain.RuleTypeDomain,
+				Action:   domain.RuleActionBlock,
+				Pattern:  "*.ads.example",
+			})
+			Expect(err).To(BeNil())
+
+			reloadable.EXPECT().Reload(gomock.Any(), gomock.Any()).DoAndReturn(func(next *domain.BypassConfig, _ time.Duration) (domain.ReloadOutcome, error) {
+				Expect(next.Rules).To(BeEmpty())
+				return domain.ReloadOutcomeApplied, nil
+			})
+			Expect(bypassService.DeleteBypassRule(ctx, rule.ID)).To(Succeed())
+		})
+
+		It("should return error for unknown session status", func() {
+			_, err := bypassService.GetBypassStatus(ctx, "unknown")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("Bypass users", func() {
+		var reloadable *MockReloadableAdapter
+		var config *domain.BypassConfig
+
+		BeforeEach(func() {
+			reloadable = NewMockReloadableAdapter(ctrl)
+			bypassService = services.NewBypassService(database.NewMemoryRepository(), reloadable, logger).(*services.BypassService)
+
+			var err error
+			config, err = bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:       "ss-server",
+				Method:     domain.BypassMethodShadowsocks,
+				Parameters: map[string]string{"local_port": "8388", "role": "server", "encryption": "2022-blake3-aes-128-gcm"},
+			})
+			Expect(err).To(BeNil())
+		})
+
+		It("should generate credentials in the method format", func() {
+			user, err := bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: config.ID, UserID: "user-1"})
+			Expect(err).To(BeNil())
+			Expect(user.ConfigID).To(Equal(config.ID))
+			key, err := base64.StdEncoding.DecodeString(user.Credential)
+			Expect(err).To(BeNil())
+			Expect(key).To(HaveLen(16))
+
+			vless, err := bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:   "vless-server",
+				Method: domain.BypassMethodV2Ray,
+			})
+			Expect(err).To(BeNil())
+			user, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: vless.ID, UserID: "user-1"})
+			Expect(err).To(BeNil())
+			_, err = uuid.Parse(user.Credential)
+			Expect(err).To(BeNil())
+
+			fragment, err := bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:   "fragment",
+				Method: domain.BypassMethodTCPFragment,
+			})
+			Expect(err).To(BeNil())
+			_, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: fragment.ID, UserID: "user-1"})
+			Expect(err).To(MatchError(ContainSubstring("does not support users")))
+		})
+
+		It("should reject duplicate users and restore revoked ones", func() {
+			_, err := bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: config.ID, UserID: "user-1", Credential: "a"})
+			Expect(err).To(BeNil())
+			_, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: config.ID, UserID: "user-1"})
+			Expect(err).To(MatchError(ContainSubstring("already exists")))
+
+			Expect(bypassService.RevokeBypassUser(ctx, config.ID, "user-1")).To(Succeed())
+			users, total, err := bypassService.ListBypassUsers(ctx, &domain.BypassUserFilters{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(0))
+			Expect(users).To(BeEmpty())
+
+			restored, err := bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: config.ID, UserID: "user-1", Credential: "b"})
+			Expect(err).To(BeNil())
+			Expect(restored.Revoked).To(BeFalse())
+			Expect(restored.Credential).To(Equal("b"))
+		})
+
+		It("should pass users to sessions and apply revocation in place", func() {
+			_, err := bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: config.ID, UserID: "user-1"})
+			Expect(err).To(BeNil())
+			_, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: config.ID, UserID: "user-2"})
+			Expect(err).To(BeNil())
+
+			reloadable.EXPECT().Start(gomock.Any()).DoAndReturn(func(sessionConfig *domain.BypassConfig) error {
+				Expect(sessionConfig.Users).To(HaveLen(2))
+				return nil
+			})
+			session, err := bypassService.StartBypass(ctx, &domain.StartBypassRequest{ConfigID: config.ID})
+			Expect(err).To(BeNil())
+
+			reloadable.EXPECT().Reload(gomock.Any(), gomock.Any()).DoAndReturn(func(next *domain.BypassConfig, _ time.Duration) (domain.ReloadOutcome, error) {
+				Expect(next.ID).To(Equal(session.ID))
+				Expect(next.Users).To(HaveLen(1))
+				Expect(next.Users[0].ID).To(Equal("user-2"))
+				return domain.ReloadOutcomeApplied, nil
+			})
+			Expect(bypassService.RevokeBypassUser(ctx, config.ID, "user-1")).To(Succeed())
+
+			reloadable.EXPECT().GetStats(session.ID).Return(&domain.BypassStats{
+				BytesSent: 500,
+				Users: []*domain.UserStats{
+					{UserID: "user-1", BytesSent: 200, Revoked: true},
+					{UserID: "user-2", BytesSent: 300},
+				},
+			}, nil)
+			stats, err := bypassService.GetBypassStats(ctx, session.ID)
+			Expect(err).To(BeNil())
+			Expect(stats.SessionID).To(Equal(session.ID))
+			Expect(stats.ConfigID).To(Equal(config.ID))
+			Expect(stats.Users).To(HaveLen(2))
+			Expect(stats.Users[0].Revoked).To(BeTrue())
+		})
+
+		It("should return error for unknown session stats", func() {
+			_, err := bypassService.GetBypassStats(ctx, "unknown")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("Censorship signals", func() {
+		It("should return signals from the adapter", func() {
+			signalAdapter := NewMockSignalAdapter(ctrl)
+			bypassService = services.NewBypassService(database.NewMemoryRepository(), signalAdapter, logger).(*services.BypassService)
+			filters := &domain.CensorshipSignalFilters{Method: domain.BypassMethodTLSHandshake}
+			expected := []*domain.CensorshipSignalStats{{Method: domain.BypassMethodTLSHandshake, Target: "example.com", Connections: 4, RSTAfterClientHello: 2}}
+
+			signalAdapter.EXPECT().CensorshipSignals(filters).Return(expected, nil)
+			signals, err := bypassService.GetCensorshipSignals(ctx, filters)
+			Expect(err).To(BeNil())
+			Expect(signals).To(Equal(expected))
+
+			until := time.Now()
+			signalAdapter.EXPECT().ExportCensorshipSignals(until).Return(expected)
+			exported, err := bypassService.ExportCensorshipSignals(ctx, until)
+			Expect(err).To(BeNil())
+			Expect(exported).To(Equal(expected))
+
+			signalAdapter.EXPECT().AcknowledgeCensorshipSignals(until)
+			Expect(bypassService.AcknowledgeCensorshipSignals(ctx, until)).To(Succeed())
+		})
+
+		It("should fail when the adapter does not collect signals", func() {
+			_, err := bypassService.GetCensorshipSignals(ctx, &domain.CensorshipSignalFilters{})
+			Expect(err).To(MatchError(ContainSubstring("does not collect censorship signals")))
+
+			_, err = bypassService.ExportCensorshipSignals(ctx, time.Now())
+			Expect(err).NotTo(BeNil())
+			Expect(bypassService.AcknowledgeCensorshipSignals(ctx, time.Now())).NotTo(Succeed())
+		})
+	})
+
+	Describe("Share links and subscriptions", func() {
+		var shadowsocks, vless *domain.BypassConfig
+
+		BeforeEach(func() {
+			var err error
+			shadowsocks, err = bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:   "Frankfurt SS",
+				Method: domain.BypassMethodShadowsocks,
+				Parameters: map[string]string{
+					"role":        "server",
+					"local_port":  "8388",
+					"public_host": "de.example.com",
+					"password":    "c2VydmVyLXBzay0xNmJ5dA==",
+					"encryption":  "2022-blake3-aes-128-gcm",
+				},
+			})
+			Expect(err).To(BeNil())
+			vless, err = bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:   "Frankfurt VLESS",
+				Method: domain.BypassMethodV2Ray,
+				Parameters: map[string]string{
+					"role":          "server",
+					"local_port":    "10443",
+					"public_host":   "de.example.com",
+					"public_port":   "443",
+					"encryption":    "tls",
+					"tls_cert_file": "/etc/silence/de.example.com.crt",
+					"tls_key_file":  "/etc/silence/de.example.com.key",
+				},
+			})
+			Expect(err).To(BeNil())
+		})
+
+		It("should build standard share URIs", func() {
+			_, err := bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: shadowsocks.ID, UserID: "user-1", Credential: "dXNlci1wc2stMTZieXRlcw=="})
+			Expect(err).To(BeNil())
+			link, err := bypassService.GetShareLink(ctx, shadowsocks.ID, "user-1")
+			Expect(err).To(BeNil())
+			Expect(link.URI).To(Equal("ss://2022-blake3-aes-128-gcm:c2VydmVyLXBzay0xNmJ5dA==%3AdXNlci1wc2stMTZieXRlcw==@de.example.com:8388#Frankfurt%20SS"))
+			Expect(link.Password).To(Equal("c2VydmVyLXBzay0xNmJ5dA==:dXNlci1wc2stMTZieXRlcw=="))
+
+			user, err := bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: vless.ID, UserID: "user-1"})
+			Expect(err).To(BeNil())
+			link, err = bypassService.GetShareLink(ctx, vless.ID, "user-1")
+			Expect(err).To(BeNil())
+			Expect(link.Port).To(Equal(443))
+			Expect(link.URI).To(Equal("vless://" + user.Credential + "@de.example.com:443?encryption=none&security=tls&sni=de.example.com&type=tcp#Frankfurt%20VLESS"))
+
+			Expect(bypassService.RevokeBypassUser(ctx, vless.ID, "user-1")).To(Succeed())
+			_, err = bypassService.GetShareLink(ctx, vless.ID, "user-1")
+			Expect(err).To(MatchError(ContainSubstring("revoked")))
+		})
+
+		It("should require a public server endpoint", func() {
+			client, err := bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:       "client",
+				Method:     domain.BypassMethodObfs4,
+				Parameters: map[string]string{"role": "server", "local_port": "9443"},
+			})
+			Expect(err).To(BeNil())
+			_, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: client.ID, UserID: "user-1"})
+			Expect(err).To(BeNil())
+			_, err = bypassService.GetShareLink(ctx, client.ID, "user-1")
+			Expect(err).To(MatchError(ContainSubstring("public_host")))
+		})
+
+		It("should refuse links stock clients cannot use", func() {
+			_, err := bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: shadowsocks.ID, UserID: "user-1", Credential: "c2hvcnQ="})
+			Expect(err).To(BeNil())
+			_, err = bypassService.GetShareLink(ctx, shadowsocks.ID, "user-1")
+			Expect(err).To(MatchError(ContainSubstring("invalid credential")))
+
+			selfSigned, err := bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:       "self-signed",
+				Method:     domain.BypassMethodV2Ray,
+				Parameters: map[string]string{"role": "server", "local_port": "10443", "public_host": "de.example.com", "encryption": "tls"},
+			})
+			Expect(err).To(BeNil())
+			_, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: selfSigned.ID, UserID: "user-1"})
+			Expect(err).To(BeNil())
+			_, err = bypassService.GetShareLink(ctx, selfSigned.ID, "user-1")
+			Expect(err).To(MatchError(ContainSubstring("tls_cert_file")))
+
+			obfs4, err := bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{
+				Name:       "bridge",
+				Method:     domain.BypassMethodObfs4,
+				Parameters: map[string]string{"role": "server", "local_port": "9443", "public_host": "nl.example.com"},
+			})
+			Expect(err).To(BeNil())
+			_, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: obfs4.ID, UserID: "user-1"})
+			Expect(err).To(BeNil())
+			_, err = bypassService.GetShareLink(ctx, obfs4.ID, "user-1")
+			Expect(err).To(MatchError(ContainSubstring("does not support share links")))
+		})
+
+		It("should serve entitled links by token, log access and honour revocation", func() {
+			_, err := bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: shadowsocks.ID, UserID: "user-1", Credential: "dXNlci1wc2stMTZieXRlcw=="})
+			Expect(err).To(BeNil())
+			_, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: vless.ID, UserID: "user-1"})
+			Expect(err).To(BeNil())
+			_, err = bypassService.AddBypassUser(ctx, &domain.AddBypassUserRequest{ConfigID: vless.ID, UserID: "user-2"})
+			Expect(err).To(BeNil())
+			Expect(bypassService.RevokeBypassUser(ctx, vless.ID, "user-1")).To(Succeed())
+
+			token, err := bypassService.CreateSubscriptionToken(ctx, &domain.CreateSubscriptionTokenRequest{UserID: "user-1"})
+			Expect(err).To(BeNil())
+			Expect(token.Token).To(HaveLen(43))
+			Expect(token.TokenHash).To(HaveLen(64))
+
+			subscription, err := bypassService.GetSubscription(ctx, token.Token, &domain.SubscriptionAccess{
+				Format:     domain.SubscriptionFormatClash,
+				RemoteAddr: "203.0.113.7",
+				UserAgent:  "clash.meta",
+			})
+			Expect(err).To(BeNil())
+			Expect(subscription.Links).To(HaveLen(1))
+			Expect(subscription.Links[0].ConfigID).To(Equal(shadowsocks.ID))
+
+			accesses, total, err := bypassService.ListSubscriptionAccesses(ctx, &domain.SubscriptionAccessFilters{TokenID: token.ID})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(1))
+			Expect(accesses[0].UserID).To(Equal("user-1"))
+			Expect(accesses[0].Format).To(Equal(domain.SubscriptionFormatClash))
+			Expect(accesses[0].RemoteAddr).To(Equal("203.0.113.7"))
+
+			tokens, _, err := bypassService.ListSubscriptionTokens(ctx, &domain.SubscriptionTokenFilters{UserID: "user-1"})
+			Expect(err).To(BeNil())
+			Expect(tokens).To(HaveLen(1))
+			Expect(tokens[0].Token).To(BeEmpty())
+			Expect(tokens[0].TokenHash).To(Equal(token.TokenHash))
+			Expect(tokens[0].LastAccessedAt).To(Equal(accesses[0].AccessedAt))
+
+			Expect(bypassService.RevokeSubscriptionToken(ctx, token.ID)).To(Succeed())
+			_, err = bypassService.GetSubscription(ctx, token.Token, nil)
+			Expect(err).To(MatchError(domain.ErrSubscriptionNotFound))
+			_, err = bypassService.GetSubscription(ctx, "unknown", nil)
+			Expect(err).To(MatchError(domain.ErrSubscriptionNotFound))
+		})
+	})
+
+	Describe("GetBypassHistory", func() {
+		It("should filter by config and time range and paginate", func() {
+			first, err := bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{Name: "first", Method: domain.BypassMethodShadowsocks})
+			Expect(err).To(BeNil())
+			second, err := bypassService.CreateBypassConfig(ctx, &domain.CreateBypassConfigRequest{Name: "second", Method: domain.BypassMethodV2Ray})
+			Expect(err).To(BeNil())
+
+			mockAdapter.EXPECT().Start(gomock.Any()).Return(nil).Times(4)
+			mockAdapter.EXPECT().GetStats(gomock.Any()).Return(nil, nil).Times(4)
+			mockAdapter.EXPECT().Stop(gomock.Any()).Return(nil).Times(4)
+
+			var middle time.Time
+			for i, configID := range []string{first.ID, first.ID, first.ID, second.ID} {
+				if i == 1 {
+					middle = time.Now()
+				}
+				session, err := bypassService.StartBypass(ctx, &domain.StartBypassRequest{ConfigID: configID})
+				Expect(err).To(BeNil())
+				Expect(bypassService.StopBypass(ctx, session.ID)).To(Succeed())
+			}
+
+			entries, total, err := bypassService.GetBypassHistory(ctx, &domain.BypassHistoryRequest{ConfigID: first.ID})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(3))
+			Expect(entries).To(HaveLen(3))
+
+			entries, total, err = bypassService.GetBypassHistory(ctx, &domain.BypassHistoryRequest{
+				ConfigID:  first.ID,
+				StartTime: middle,
+				Limit:     1,
+			})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(2))
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].StartedAt).NotTo(BeTemporally("<", middle))
+
+			entries, total, err = bypassService.GetBypassHistory(ctx, &domain.BypassHistoryRequest{
+				EndTime: middle,
+			})
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(1))
+			Expect(entries[0].ConfigID).To(Equal(first.ID))
+		})
+	})
+
 	Describe("HealthService", func() {
 		It("should return health status", func() {
 			status := healthService.GetHealth()
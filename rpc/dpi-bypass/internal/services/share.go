@@ -0,0 +1,120 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+)
+
+// Методы Shadowsocks 2022, которые обслуживает адаптер: PSK 16 байт для
+// AES-128, иначе 32 байта для AES-256
+const (
+	shadowsocksCipher128 = "2022-blake3-aes-128-gcm"
+	shadowsocksCipher256 = "2022-blake3-aes-256-gcm"
+)
+
+// buildShareLink собирает параметры подключения пользователя к серверу
+// обхода. Адрес для клиентов задается параметрами public_host и public_port
+// (по умолчанию local_port) конфигурации в роли сервера. Ссылка строится,
+// только если стандартный клиент сможет подключиться по ней.
+func buildShareLink(config *domain.BypassConfig, user *domain.BypassUser) (*domain.ShareLink, error) {
+	if config.Parameters["role"] != "server" {
+		return nil, fmt.Errorf("bypass configuration is not a server: %s", config.ID)
+	}
+	host := config.Parameters["public_host"]
+	if host == "" {
+		return nil, fmt.Errorf("public_host is not set for bypass configuration: %s", config.ID)
+	}
+	portValue := config.Parameters["public_port"]
+	if portValue == "" {
+		portValue = config.Parameters["local_port"]
+	}
+	if portValue == "" {
+		portValue = "1080"
+	}
+	port, err := strconv.Atoi(portValue)
+	if err != nil || port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid public port: %s", portValue)
+	}
+
+	name := config.Name
+	if name == "" {
+		name = config.ID
+	}
+	link := &domain.ShareLink{
+		ConfigID: config.ID,
+		UserID:   user.ID,
+		Method:   config.Method,
+		Name:     name,
+		Server:   host,
+		Port:     port,
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	switch config.Method {
+	case domain.BypassMethodShadowsocks:
+		// SIP002: для методов 2022 userinfo не кодируется в base64,
+		// пароль состоит из PSK сервера и PSK пользователя. Метод
+		// соответствует размеру PSK, с которым работает сервер.
+		size := 32
+		link.Cipher = shadowsocksCipher256
+		if config.Parameters["encryption"] == shadowsocksCipher128 {
+			link.Cipher, size = shadowsocksCipher128, 16
+		}
+		if err := checkShadowsocksKey(config.Parameters["password"], size); err != nil {
+			return nil, fmt.Errorf("invalid server password: %w", err)
+		}
+		if err := checkShadowsocksKey(user.Credential, size); err != nil {
+			return nil, fmt.Errorf("invalid credential of user %s: %w", user.ID, err)
+		}
+		link.Password = config.Parameters["password"] + ":" + user.Credential
+		link.URI = (&url.URL{
+			Scheme:   "ss",
+			User:     url.UserPassword(link.Cipher, link.Password),
+			Host:     address,
+			Fragment: name,
+		}).String()
+	case domain.BypassMethodV2Ray:
+		link.UUID = user.Credential
+		link.TLS = config.Parameters["encryption"] == "tls"
+		query := url.Values{"encryption": {"none"}, "type": {"tcp"}, "security": {"none"}}
+		if link.TLS {
+			// Самоподписанный сертификат сервера клиенты отклоняют
+			if config.Parameters["tls_cert_file"] == "" {
+				return nil, fmt.Errorf("tls_cert_file is not set for bypass configuration: %s", config.ID)
+			}
+			link.ServerName = host
+			query.Set("security", "tls")
+			query.Set("sni", host)
+		}
+		link.URI = (&url.URL{
+			Scheme:   "vless",
+			User:     url.User(link.UUID),
+			Host:     address,
+			RawQuery: query.Encode(),
+			Fragment: name,
+		}).String()
+	default:
+		// obfs4 адаптера несовместим с obfs4proxy: строки моста Tor для
+		// него нет
+		return nil, fmt.Errorf("bypass method %s does not support share links", config.Method)
+	}
+
+	return link, nil
+}
+
+// checkShadowsocksKey проверяет PSK Shadowsocks 2022 в base64
+func checkShadowsocksKey(value string, size int) error {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return err
+	}
+	if len(key) != size {
+		return fmt.Errorf("%d bytes, want %d", len(key), size)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
+)
+
+// GetCensorshipSignals возвращает сигналы цензуры исходящих соединений,
+// агрегированные по методу, цели и окну времени
+func (s *BypassService) GetCensorshipSignals(ctx context.Context, filters *domain.CensorshipSignalFilters) ([]*domain.CensorshipSignalStats, error) {
+	adapter, err := s.signalAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return adapter.CensorshipSignals(filters)
+}
+
+// ExportCensorshipSignals возвращает завершенные к until минутные агрегаты
+// сигналов, не подтвержденные ранее. Повторный вызов до подтверждения
+// возвращает те же минуты.
+func (s *BypassService) ExportCensorshipSignals(ctx context.Context, until time.Time) ([]*domain.CensorshipSignalStats, error) {
+	adapter, err := s.signalAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return adapter.ExportCensorshipSignals(until), nil
+}
+
+// AcknowledgeCensorshipSignals отмечает агрегаты, завершенные к until,
+// доставленными потребителю
+func (s *BypassService) AcknowledgeCensorshipSignals(ctx context.Context, until time.Time) error {
+	adapter, err := s.signalAdapter()
+	if err != nil {
+		return err
+	}
+	adapter.AcknowledgeCensorshipSignals(until)
+	return nil
+}
+
+// signalAdapter возвращает адаптер с классификацией сигналов цензуры
+func (s *BypassService) signalAdapter() (ports.SignalAdapter, error) {
+	adapter, ok := s.adapter.(ports.SignalAdapter)
+	if !ok {
+		return nil, fmt.Errorf("bypass adapter does not collect censorship signals")
+	}
+	return adapter, nil
+}
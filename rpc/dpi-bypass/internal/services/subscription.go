@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/domain"
+	"go.uber.org/zap"
+)
+
+// subscriptionTokenSize размер случайной части токена подписки в байтах
+const subscriptionTokenSize = 32
+
+// GetShareLink возвращает ссылку для подключения пользователя к серверу
+// обхода
+func (s *BypassService) GetShareLink(ctx context.Context, configID, userID string) (*domain.ShareLink, error) {
+	config, err := s.repo.GetConfig(ctx, configID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetUser(ctx, configID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Revoked {
+		return nil, fmt.Errorf("bypass user is revoked: %s", userID)
+	}
+	return buildShareLink(config, user)
+}
+
+// CreateSubscriptionToken выпускает токен подписки пользователя. Значение
+// токена возвращается только здесь: в хранилище попадает его хеш.
+func (s *BypassService) CreateSubscriptionToken(ctx context.Context, req *domain.CreateSubscriptionTokenRequest) (*domain.SubscriptionToken, error) {
+	if req.UserID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+
+	value := make([]byte, subscriptionTokenSize)
+	if _, err := rand.Read(value); err != nil {
+		return nil, fmt.Errorf("failed to generate subscription token: %w", err)
+	}
+
+	raw := base64.RawURLEncoding.EncodeToString(value)
+	token := &domain.SubscriptionToken{
+		UserID:    req.UserID,
+		Token:     raw,
+		TokenHash: hashSubscriptionToken(raw),
+	}
+	if err := s.repo.CreateSubscriptionToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("subscription token created", zap.String("id", token.ID), zap.String("user_id", token.UserID))
+	return token, nil
+}
+
+// RevokeSubscriptionToken отзывает токен подписки: следующие запросы по
+// нему отклоняются
+func (s *BypassService) RevokeSubscriptionToken(ctx context.Context, id string) error {
+	token, err := s.repo.GetSubscriptionToken(ctx, id)
+	if err != nil {
+		return err
+	}
+	if token.Revoked {
+		return nil
+	}
+
+	token.Revoked = true
+	if err := s.repo.UpdateSubscriptionToken(ctx, token); err != nil {
+		return err
+	}
+
+	s.logger.Info("subscription token revoked", zap.String("id", id), zap.String("user_id", token.UserID))
+	return nil
+}
+
+// ListSubscriptionTokens получает список токенов подписки
+func (s *BypassService) ListSubscriptionTokens(ctx context.Context, filters *domain.SubscriptionTokenFilters) ([]*domain.SubscriptionToken, int, error) {
+	return s.repo.ListSubscriptionTokens(ctx, filters)
+}
+
+// ListSubscriptionAccesses получает журнал обращений к подпискам
+func (s *BypassService) ListSubscriptionAccesses(ctx context.Context, filters *domain.SubscriptionAccessFilters) ([]*domain.SubscriptionAccess, int, error) {
+	return s.repo.ListSubscriptionAccesses(ctx, filters)
+}
+
+// GetSubscription возвращает ссылки всех серверов, где пользователь токена
+// не отозван. Конфигурации без ссылок пропускаются. Обращение записывается
+// в журнал; ошибка записи не мешает выдаче подписки.
+func (s *BypassService) GetSubscription(ctx context.Context, value string, access *domain.SubscriptionAccess) (*domain.Subscription, error) {
+	token, err := s.repo.GetSubscriptionTokenByHash(ctx, hashSubscriptionToken(value))
+	if err != nil || token.Revoked {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	users, _, err := s.repo.ListUsers(ctx, &domain.BypassUserFilters{UserID: token.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &domain.Subscription{UserID: token.UserID, Links: []*domain.ShareLink{}}
+	for _, user := range users {
+		config, err := s.repo.GetConfig(ctx, user.ConfigID)
+		if err != nil {
+			return nil, err
+		}
+		link, err := buildShareLink(config, user)
+		if err != nil {
+			s.logger.Debug("bypass configuration skipped in subscription",
+				zap.String("config_id", config.ID), zap.Error(err))
+			continue
+		}
+		subscription.Links = append(subscription.Links, link)
+	}
+
+	s.recordAccess(ctx, token, access)
+	return subscription, nil
+}
+
+// hashSubscriptionToken возвращает SHA-256 токена подписки в hex. Токен -
+// 32 случайных байта, поэтому соль и медленный хеш не нужны.
+func hashSubscriptionToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordAccess записывает обращение к подписке
+func (s *BypassService) recordAccess(ctx context.Context, token *domain.SubscriptionToken, access *domain.SubscriptionAccess) {
+	if access == nil {
+		access = &domain.SubscriptionAccess{}
+	}
+	access.TokenID = token.ID
+	access.UserID = token.UserID
+	if access.AccessedAt.IsZero() {
+		access.AccessedAt = time.Now()
+	}
+
+	if err := s.repo.CreateSubscriptionAccess(ctx, access); err != nil {
+		s.logger.Warn("failed to record subscription access", zap.String("token_id", token.ID), zap.Error(err))
+	}
+
+	s.logger.Info("subscription accessed",
+		zap.String("token_id", token.ID),
+		zap.String("user_id", token.UserID),
+		zap.String("format", string(access.Format)),
+		zap.String("remote_addr", access.RemoteAddr),
+		zap.String("user_agent", access.UserAgent))
+}
@@ -1,8 +1,18 @@
 package svc
 
 import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/lib/pq"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/bypass"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/database"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/dns"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/grpc"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/metrics"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/subscription"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/ports"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/services"
@@ -13,32 +23,151 @@ import (
 type ServiceContext struct {
 	Config        *config.Config
 	Logger        *zap.Logger
+	DB            *sql.DB
 	HealthService ports.HealthService
 	BypassService ports.DPIBypassService
 	BypassAdapter ports.BypassAdapter
 	GRPCServer    *grpc.Server
+	// SubscriptionServer HTTP сервер подписок пользователей
+	SubscriptionServer *subscription.Server
+	// MetricsServer HTTP сервер метрик для сервиса аналитики
+	MetricsServer *metrics.Server
+	// DNSServer локальный DNS сервер для клиентов VPN
+	DNSServer *dns.Server
 }
 
 // NewServiceContext создает новый контекст сервиса
-func NewServiceContext(cfg *config.Config, logger *zap.Logger) *ServiceContext {
+func NewServiceContext(cfg *config.Config, logger *zap.Logger) (*ServiceContext, error) {
 	// Создаем сервисы
 	healthService := services.NewHealthService("dpi-bypass", cfg.Version)
 
+	// Создаем репозиторий
+	db, repo, err := newRepository(cfg.Database, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// Создаем мульти-адаптер для обфускации
 	bypassAdapter := bypass.NewMultiBypassAdapter(logger)
 
+	// Создаем зашифрованный резолвер
+	resolver, err := newResolver(cfg.DNS, logger)
+	if err != nil {
+		return nil, err
+	}
+	if resolver != nil {
+		bypassAdapter.SetResolver(resolver)
+	}
+
 	// Создаем bypass сервис
-	bypassService := services.NewBypassService(bypassAdapter, logger)
+	bypassService := services.NewBypassService(repo, bypassAdapter, logger)
 
 	// Создаем gRPC сервер
 	grpcServer := grpc.NewServer(bypassService, logger, cfg)
 
+	// Создаем HTTP сервер подписок
+	subscriptionServer := subscription.NewServer(bypassService, logger, cfg)
+
+	// Создаем HTTP сервер метрик
+	metricsServer := metrics.NewServer(bypassService, logger, cfg)
+
+	// Создаем локальный DNS сервер
+	dnsServer := dns.NewServer(resolver, logger, cfg)
+
 	return &ServiceContext{
 		Config:        cfg,
 		Logger:        logger,
+		DB:            db,
 		HealthService: healthService,
 		BypassService: bypassService,
 		BypassAdapter: bypassAdapter,
 		GRPCServer:    grpcServer,
+
+		SubscriptionServer: subscriptionServer,
+		MetricsServer:      metricsServer,
+		DNSServer:          dnsServer,
+	}, nil
+}
+
+// Close закрывает соединение с базой данных
+func (c *ServiceContext) Close() error {
+	if c.DB == nil {
+		return nil
+	}
+	return c.DB.Close()
+}
+
+// newResolver создает зашифрованный резолвер. Без серверов резолвер не
+// создается, и соединения используют системный.
+func newResolver(cfg config.DNSConfig, logger *zap.Logger) (*dns.Resolver, error) {
+	if len(cfg.Upstreams) == 0 {
+		return nil, nil
+	}
+	upstreams, err := dns.ParseUpstreams(cfg.Upstreams, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure dns resolver: %w", err)
+	}
+	return dns.NewResolver(upstreams, logger), nil
+}
+
+// newRepository подключается к PostgreSQL и выполняет миграции. Без DB_HOST
+// данные хранятся в памяти процесса.
+func newRepository(cfg config.DatabaseConfig, logger *zap.Logger) (*sql.DB, ports.BypassRepository, error) {
+	if cfg.Host == "" {
+		logger.Warn("database is not configured, bypass configurations are kept in memory")
+		return nil, database.NewMemoryRepository(), nil
 	}
+
+	db, err := sql.Open("postgres", fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
+	))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Проверяем соединение
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	migrationsDir, err := resolveMigrationsDir(cfg.MigrationsDir)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	logger.Info("Используется путь к миграциям", zap.String("migrationsDir", migrationsDir))
+
+	if err := database.NewMigrator(db, logger).RunMigrations(migrationsDir); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return db, database.NewPostgresRepository(db, logger), nil
+}
+
+// resolveMigrationsDir возвращает путь к миграциям: из конфигурации, рядом
+// с бинарём или из исходников
+func resolveMigrationsDir(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	candidates := []string{
+		filepath.Join(filepath.Dir(execPath), "internal", "adapters", "database", "migrations"),
+		filepath.Join("rpc", "dpi-bypass", "internal", "adapters", "database", "migrations"),
+	}
+	for _, dir := range candidates {
+		if _, err := os.Stat(dir); err == nil {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("migrations not found: %v", candidates)
 }
@@ -4,7 +4,10 @@ import (
 	"testing"
 
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/bypass"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/dns"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/grpc"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/metrics"
+	"github.com/par1ram/silence/rpc/dpi-bypass/internal/adapters/subscription"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/config"
 	"github.com/par1ram/silence/rpc/dpi-bypass/internal/services"
 	"github.com/stretchr/testify/assert"
@@ -21,13 +24,29 @@ func TestNewServiceContext(t *testing.T) {
 	}
 	logger := zap.NewNop()
 
-	svcCtx := NewServiceContext(cfg, logger)
+	svcCtx, err := NewServiceContext(cfg, logger)
 
+	assert.NoError(t, err)
 	assert.NotNil(t, svcCtx)
+	assert.Nil(t, svcCtx.DB)
 	assert.Equal(t, cfg, svcCtx.Config)
 	assert.Equal(t, logger, svcCtx.Logger)
 	assert.IsType(t, &services.HealthService{}, svcCtx.HealthService)
 	assert.IsType(t, &services.BypassService{}, svcCtx.BypassService)
 	assert.IsType(t, &bypass.MultiBypassAdapter{}, svcCtx.BypassAdapter)
 	assert.IsType(t, &grpc.Server{}, svcCtx.GRPCServer)
+	assert.IsType(t, &subscription.Server{}, svcCtx.SubscriptionServer)
+	assert.IsType(t, &metrics.Server{}, svcCtx.MetricsServer)
+	assert.IsType(t, &dns.Server{}, svcCtx.DNSServer)
+}
+
+func TestNewServiceContext_InvalidDNSUpstream(t *testing.T) {
+	cfg := &config.Config{
+		DNS: config.DNSConfig{Upstreams: []string{"udp://1.1.1.1"}},
+	}
+
+	svcCtx, err := NewServiceContext(cfg, zap.NewNop())
+
+	assert.Error(t, err)
+	assert.Nil(t, svcCtx)
 }
@@ -86,6 +86,7 @@ const (
 	ServerStatus_SERVER_STATUS_STOPPED     ServerStatus = 3
 	ServerStatus_SERVER_STATUS_ERROR       ServerStatus = 4
 	ServerStatus_SERVER_STATUS_DELETING    ServerStatus = 5
+	ServerStatus_SERVER_STATUS_UPDATING    ServerStatus = 6
 )
 
 // Enum value maps for ServerStatus.
@@ -97,6 +98,7 @@ var (
 		3: "SERVER_STATUS_STOPPED",
 		4: "SERVER_STATUS_ERROR",
 		5: "SERVER_STATUS_DELETING",
+		6: "SERVER_STATUS_UPDATING",
 	}
 	ServerStatus_value = map[string]int32{
 		"SERVER_STATUS_UNSPECIFIED": 0,
@@ -105,6 +107,7 @@ var (
 		"SERVER_STATUS_STOPPED":     3,
 		"SERVER_STATUS_ERROR":       4,
 		"SERVER_STATUS_DELETING":    5,
+		"SERVER_STATUS_UPDATING":    6,
 	}
 )
 
@@ -301,6 +304,7 @@ type Server struct {
 	Config        map[string]string      `protobuf:"bytes,12,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	NodeId        string                 `protobuf:"bytes,15,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"` // узел Docker при ORCHESTRATOR_TYPE=docker-multihost
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -433,12 +437,20 @@ func (x *Server) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Server) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
 type CreateServerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Type          ServerType             `protobuf:"varint,2,opt,name=type,proto3,enum=server.ServerType" json:"type,omitempty"`
 	Region        string                 `protobuf:"bytes,3,opt,name=region,proto3" json:"region,omitempty"`
 	Config        map[string]string      `protobuf:"bytes,4,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	NodeSelector  map[string]string      `protobuf:"bytes,5,rep,name=node_selector,json=nodeSelector,proto3" json:"node_selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // метки узла для размещения
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -501,6 +513,13 @@ func (x *CreateServerRequest) GetConfig() map[string]string {
 	return nil
 }
 
+func (x *CreateServerRequest) GetNodeSelector() map[string]string {
+	if x != nil {
+		return x.NodeSelector
+	}
+	return nil
+}
+
 type GetServerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -1510,6 +1529,8 @@ type ServerMonitorEvent struct {
 	Stats         *ServerStats           `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
 	Health        *ServerHealth          `protobuf:"bytes,4,opt,name=health,proto3" json:"health,omitempty"`
 	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	Status        ServerStatus           `protobuf:"varint,7,opt,name=status,proto3,enum=server.ServerStatus" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1579,6 +1600,20 @@ func (x *ServerMonitorEvent) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *ServerMonitorEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServerMonitorEvent) GetStatus() ServerStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ServerStatus_SERVER_STATUS_UNSPECIFIED
+}
+
 // Server Filtering
 type GetServersByTypeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -2025,6 +2060,220 @@ func (x *ScaleServerResponse) GetMessage() string {
 	return ""
 }
 
+// Оценка политик масштабирования; в режиме dry_run решения только
+// рассчитываются
+type EvaluateScalingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DryRun        bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvaluateScalingRequest) Reset() {
+	*x = EvaluateScalingRequest{}
+	mi := &file_server_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluateScalingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateScalingRequest) ProtoMessage() {}
+
+func (x *EvaluateScalingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateScalingRequest.ProtoReflect.Descriptor instead.
+func (*EvaluateScalingRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *EvaluateScalingRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ScalingDecision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PolicyId      string                 `protobuf:"bytes,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	ServerType    ServerType             `protobuf:"varint,2,opt,name=server_type,json=serverType,proto3,enum=server.ServerType" json:"server_type,omitempty"`
+	Region        string                 `protobuf:"bytes,3,opt,name=region,proto3" json:"region,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"` // none, scale_up, scale_down
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	ServersBefore int32                  `protobuf:"varint,6,opt,name=servers_before,json=serversBefore,proto3" json:"servers_before,omitempty"`
+	ServersAfter  int32                  `protobuf:"varint,7,opt,name=servers_after,json=serversAfter,proto3" json:"servers_after,omitempty"`
+	CpuUsage      float64                `protobuf:"fixed64,8,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`          // средняя загрузка группы, %
+	MemoryUsage   float64                `protobuf:"fixed64,9,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"` // среднее использование памяти группы, %
+	DryRun        bool                   `protobuf:"varint,10,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	TriggeredAt   *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=triggered_at,json=triggeredAt,proto3" json:"triggered_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScalingDecision) Reset() {
+	*x = ScalingDecision{}
+	mi := &file_server_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScalingDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScalingDecision) ProtoMessage() {}
+
+func (x *ScalingDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScalingDecision.ProtoReflect.Descriptor instead.
+func (*ScalingDecision) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ScalingDecision) GetPolicyId() string {
+	if x != nil {
+		return x.PolicyId
+	}
+	return ""
+}
+
+func (x *ScalingDecision) GetServerType() ServerType {
+	if x != nil {
+		return x.ServerType
+	}
+	return ServerType_SERVER_TYPE_UNSPECIFIED
+}
+
+func (x *ScalingDecision) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ScalingDecision) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ScalingDecision) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ScalingDecision) GetServersBefore() int32 {
+	if x != nil {
+		return x.ServersBefore
+	}
+	return 0
+}
+
+func (x *ScalingDecision) GetServersAfter() int32 {
+	if x != nil {
+		return x.ServersAfter
+	}
+	return 0
+}
+
+func (x *ScalingDecision) GetCpuUsage() float64 {
+	if x != nil {
+		return x.CpuUsage
+	}
+	return 0
+}
+
+func (x *ScalingDecision) GetMemoryUsage() float64 {
+	if x != nil {
+		return x.MemoryUsage
+	}
+	return 0
+}
+
+func (x *ScalingDecision) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ScalingDecision) GetTriggeredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TriggeredAt
+	}
+	return nil
+}
+
+type EvaluateScalingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Decisions     []*ScalingDecision     `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvaluateScalingResponse) Reset() {
+	*x = EvaluateScalingResponse{}
+	mi := &file_server_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluateScalingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateScalingResponse) ProtoMessage() {}
+
+func (x *EvaluateScalingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateScalingResponse.ProtoReflect.Descriptor instead.
+func (*EvaluateScalingResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *EvaluateScalingResponse) GetDecisions() []*ScalingDecision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
 type CreateBackupRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
@@ -2036,7 +2285,7 @@ type CreateBackupRequest struct {
 
 func (x *CreateBackupRequest) Reset() {
 	*x = CreateBackupRequest{}
-	mi := &file_server_proto_msgTypes[32]
+	mi := &file_server_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2048,7 +2297,7 @@ func (x *CreateBackupRequest) String() string {
 func (*CreateBackupRequest) ProtoMessage() {}
 
 func (x *CreateBackupRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[32]
+	mi := &file_server_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2061,7 +2310,7 @@ func (x *CreateBackupRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateBackupRequest.ProtoReflect.Descriptor instead.
 func (*CreateBackupRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{32}
+	return file_server_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *CreateBackupRequest) GetServerId() string {
@@ -2096,7 +2345,7 @@ type CreateBackupResponse struct {
 
 func (x *CreateBackupResponse) Reset() {
 	*x = CreateBackupResponse{}
-	mi := &file_server_proto_msgTypes[33]
+	mi := &file_server_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2108,7 +2357,7 @@ func (x *CreateBackupResponse) String() string {
 func (*CreateBackupResponse) ProtoMessage() {}
 
 func (x *CreateBackupResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[33]
+	mi := &file_server_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2121,7 +2370,7 @@ func (x *CreateBackupResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateBackupResponse.ProtoReflect.Descriptor instead.
 func (*CreateBackupResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{33}
+	return file_server_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *CreateBackupResponse) GetSuccess() bool {
@@ -2155,7 +2404,7 @@ type RestoreBackupRequest struct {
 
 func (x *RestoreBackupRequest) Reset() {
 	*x = RestoreBackupRequest{}
-	mi := &file_server_proto_msgTypes[34]
+	mi := &file_server_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2167,7 +2416,7 @@ func (x *RestoreBackupRequest) String() string {
 func (*RestoreBackupRequest) ProtoMessage() {}
 
 func (x *RestoreBackupRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[34]
+	mi := &file_server_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2180,7 +2429,7 @@ func (x *RestoreBackupRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestoreBackupRequest.ProtoReflect.Descriptor instead.
 func (*RestoreBackupRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{34}
+	return file_server_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *RestoreBackupRequest) GetServerId() string {
@@ -2207,7 +2456,7 @@ type RestoreBackupResponse struct {
 
 func (x *RestoreBackupResponse) Reset() {
 	*x = RestoreBackupResponse{}
-	mi := &file_server_proto_msgTypes[35]
+	mi := &file_server_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2219,7 +2468,7 @@ func (x *RestoreBackupResponse) String() string {
 func (*RestoreBackupResponse) ProtoMessage() {}
 
 func (x *RestoreBackupResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[35]
+	mi := &file_server_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2232,7 +2481,7 @@ func (x *RestoreBackupResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestoreBackupResponse.ProtoReflect.Descriptor instead.
 func (*RestoreBackupResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{35}
+	return file_server_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *RestoreBackupResponse) GetSuccess() bool {
@@ -2249,18 +2498,24 @@ func (x *RestoreBackupResponse) GetMessage() string {
 	return ""
 }
 
+// Без server_id обновляются все серверы server_type (и region) партиями
 type UpdateServerSoftwareRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Force         bool                   `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ServerId             string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Version              string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"` // тег образа или полная ссылка на образ
+	Force                bool                   `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
+	ServerType           ServerType             `protobuf:"varint,4,opt,name=server_type,json=serverType,proto3,enum=server.ServerType" json:"server_type,omitempty"`
+	Region               string                 `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"`
+	CanaryPercent        int32                  `protobuf:"varint,6,opt,name=canary_percent,json=canaryPercent,proto3" json:"canary_percent,omitempty"`
+	MaxUnavailable       int32                  `protobuf:"varint,7,opt,name=max_unavailable,json=maxUnavailable,proto3" json:"max_unavailable,omitempty"`
+	HealthTimeoutSeconds int32                  `protobuf:"varint,8,opt,name=health_timeout_seconds,json=healthTimeoutSeconds,proto3" json:"health_timeout_seconds,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *UpdateServerSoftwareRequest) Reset() {
 	*x = UpdateServerSoftwareRequest{}
-	mi := &file_server_proto_msgTypes[36]
+	mi := &file_server_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2272,7 +2527,7 @@ func (x *UpdateServerSoftwareRequest) String() string {
 func (*UpdateServerSoftwareRequest) ProtoMessage() {}
 
 func (x *UpdateServerSoftwareRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[36]
+	mi := &file_server_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2285,7 +2540,7 @@ func (x *UpdateServerSoftwareRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateServerSoftwareRequest.ProtoReflect.Descriptor instead.
 func (*UpdateServerSoftwareRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{36}
+	return file_server_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *UpdateServerSoftwareRequest) GetServerId() string {
@@ -2309,6 +2564,41 @@ func (x *UpdateServerSoftwareRequest) GetForce() bool {
 	return false
 }
 
+func (x *UpdateServerSoftwareRequest) GetServerType() ServerType {
+	if x != nil {
+		return x.ServerType
+	}
+	return ServerType_SERVER_TYPE_UNSPECIFIED
+}
+
+func (x *UpdateServerSoftwareRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *UpdateServerSoftwareRequest) GetCanaryPercent() int32 {
+	if x != nil {
+		return x.CanaryPercent
+	}
+	return 0
+}
+
+func (x *UpdateServerSoftwareRequest) GetMaxUnavailable() int32 {
+	if x != nil {
+		return x.MaxUnavailable
+	}
+	return 0
+}
+
+func (x *UpdateServerSoftwareRequest) GetHealthTimeoutSeconds() int32 {
+	if x != nil {
+		return x.HealthTimeoutSeconds
+	}
+	return 0
+}
+
 type UpdateServerSoftwareResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -2320,7 +2610,7 @@ type UpdateServerSoftwareResponse struct {
 
 func (x *UpdateServerSoftwareResponse) Reset() {
 	*x = UpdateServerSoftwareResponse{}
-	mi := &file_server_proto_msgTypes[37]
+	mi := &file_server_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2332,7 +2622,7 @@ func (x *UpdateServerSoftwareResponse) String() string {
 func (*UpdateServerSoftwareResponse) ProtoMessage() {}
 
 func (x *UpdateServerSoftwareResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[37]
+	mi := &file_server_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2345,7 +2635,7 @@ func (x *UpdateServerSoftwareResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateServerSoftwareResponse.ProtoReflect.Descriptor instead.
 func (*UpdateServerSoftwareResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{37}
+	return file_server_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *UpdateServerSoftwareResponse) GetSuccess() bool {
@@ -2369,33 +2659,28 @@ func (x *UpdateServerSoftwareResponse) GetStatus() *UpdateStatus {
 	return nil
 }
 
-type UpdateStatus struct {
+type GetServerSoftwareUpdateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Progress      int32                  `protobuf:"varint,3,opt,name=progress,proto3" json:"progress,omitempty"`
-	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
-	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateStatus) Reset() {
-	*x = UpdateStatus{}
-	mi := &file_server_proto_msgTypes[38]
+func (x *GetServerSoftwareUpdateRequest) Reset() {
+	*x = GetServerSoftwareUpdateRequest{}
+	mi := &file_server_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateStatus) String() string {
+func (x *GetServerSoftwareUpdateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateStatus) ProtoMessage() {}
+func (*GetServerSoftwareUpdateRequest) ProtoMessage() {}
 
-func (x *UpdateStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[38]
+func (x *GetServerSoftwareUpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2406,47 +2691,1742 @@ func (x *UpdateStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateStatus.ProtoReflect.Descriptor instead.
-func (*UpdateStatus) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{38}
+// Deprecated: Use GetServerSoftwareUpdateRequest.ProtoReflect.Descriptor instead.
+func (*GetServerSoftwareUpdateRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *UpdateStatus) GetServerId() string {
+func (x *GetServerSoftwareUpdateRequest) GetServerId() string {
 	if x != nil {
 		return x.ServerId
 	}
 	return ""
 }
 
-func (x *UpdateStatus) GetStatus() string {
-	if x != nil {
+type CancelServerSoftwareUpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelServerSoftwareUpdateRequest) Reset() {
+	*x = CancelServerSoftwareUpdateRequest{}
+	mi := &file_server_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelServerSoftwareUpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelServerSoftwareUpdateRequest) ProtoMessage() {}
+
+func (x *CancelServerSoftwareUpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelServerSoftwareUpdateRequest.ProtoReflect.Descriptor instead.
+func (*CancelServerSoftwareUpdateRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CancelServerSoftwareUpdateRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+type CancelServerSoftwareUpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelServerSoftwareUpdateResponse) Reset() {
+	*x = CancelServerSoftwareUpdateResponse{}
+	mi := &file_server_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelServerSoftwareUpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelServerSoftwareUpdateResponse) ProtoMessage() {}
+
+func (x *CancelServerSoftwareUpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelServerSoftwareUpdateResponse.ProtoReflect.Descriptor instead.
+func (*CancelServerSoftwareUpdateResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *CancelServerSoftwareUpdateResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CancelServerSoftwareUpdateResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type UpdateStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Progress      int32                  `protobuf:"varint,3,opt,name=progress,proto3" json:"progress,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateStatus) Reset() {
+	*x = UpdateStatus{}
+	mi := &file_server_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateStatus) ProtoMessage() {}
+
+func (x *UpdateStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateStatus.ProtoReflect.Descriptor instead.
+func (*UpdateStatus) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *UpdateStatus) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *UpdateStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateStatus) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *UpdateStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateStatus) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *UpdateStatus) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+// Region
+type Region struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Country       string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Latitude      float64                `protobuf:"fixed64,4,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,5,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	MaxServers    int32                  `protobuf:"varint,6,opt,name=max_servers,json=maxServers,proto3" json:"max_servers,omitempty"` // 0 - без ограничения
+	MaxLoad       float64                `protobuf:"fixed64,7,opt,name=max_load,json=maxLoad,proto3" json:"max_load,omitempty"`         // загрузка сервера (CPU или памяти), %; 0 - 80
+	Enabled       bool                   `protobuf:"varint,9,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Region) Reset() {
+	*x = Region{}
+	mi := &file_server_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Region) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Region) ProtoMessage() {}
+
+func (x *Region) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Region.ProtoReflect.Descriptor instead.
+func (*Region) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *Region) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Region) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Region) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Region) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Region) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Region) GetMaxServers() int32 {
+	if x != nil {
+		return x.MaxServers
+	}
+	return 0
+}
+
+func (x *Region) GetMaxLoad() float64 {
+	if x != nil {
+		return x.MaxLoad
+	}
+	return 0
+}
+
+func (x *Region) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Region) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Region) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListRegionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRegionsRequest) Reset() {
+	*x = ListRegionsRequest{}
+	mi := &file_server_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRegionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRegionsRequest) ProtoMessage() {}
+
+func (x *ListRegionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRegionsRequest.ProtoReflect.Descriptor instead.
+func (*ListRegionsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{46}
+}
+
+type ListRegionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Regions       []*Region              `protobuf:"bytes,1,rep,name=regions,proto3" json:"regions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRegionsResponse) Reset() {
+	*x = ListRegionsResponse{}
+	mi := &file_server_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRegionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRegionsResponse) ProtoMessage() {}
+
+func (x *ListRegionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRegionsResponse.ProtoReflect.Descriptor instead.
+func (*ListRegionsResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ListRegionsResponse) GetRegions() []*Region {
+	if x != nil {
+		return x.Regions
+	}
+	return nil
+}
+
+type DeleteRegionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRegionRequest) Reset() {
+	*x = DeleteRegionRequest{}
+	mi := &file_server_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRegionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRegionRequest) ProtoMessage() {}
+
+func (x *DeleteRegionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRegionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRegionRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *DeleteRegionRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type DeleteRegionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRegionResponse) Reset() {
+	*x = DeleteRegionResponse{}
+	mi := &file_server_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRegionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRegionResponse) ProtoMessage() {}
+
+func (x *DeleteRegionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRegionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteRegionResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *DeleteRegionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteRegionResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GeoLocation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GeoLocation) Reset() {
+	*x = GeoLocation{}
+	mi := &file_server_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeoLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeoLocation) ProtoMessage() {}
+
+func (x *GeoLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeoLocation.ProtoReflect.Descriptor instead.
+func (*GeoLocation) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GeoLocation) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *GeoLocation) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+// Подсказки о клиенте необязательны: без location используется country
+type RecommendServerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerType    ServerType             `protobuf:"varint,1,opt,name=server_type,json=serverType,proto3,enum=server.ServerType" json:"server_type,omitempty"` // по умолчанию VPN
+	Region        string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`                                                   // предпочтительный регион
+	Country       string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`                                                 // ISO 3166-1 alpha-2
+	Location      *GeoLocation           `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecommendServerRequest) Reset() {
+	*x = RecommendServerRequest{}
+	mi := &file_server_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendServerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendServerRequest) ProtoMessage() {}
+
+func (x *RecommendServerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendServerRequest.ProtoReflect.Descriptor instead.
+func (*RecommendServerRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *RecommendServerRequest) GetServerType() ServerType {
+	if x != nil {
+		return x.ServerType
+	}
+	return ServerType_SERVER_TYPE_UNSPECIFIED
+}
+
+func (x *RecommendServerRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *RecommendServerRequest) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *RecommendServerRequest) GetLocation() *GeoLocation {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+type RecommendServerResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Server             *Server                `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	Region             *Region                `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	Load               float64                `protobuf:"fixed64,3,opt,name=load,proto3" json:"load,omitempty"` // загрузка сервера (CPU или памяти), %
+	DistanceKm         float64                `protobuf:"fixed64,4,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
+	EstimatedLatencyMs float64                `protobuf:"fixed64,5,opt,name=estimated_latency_ms,json=estimatedLatencyMs,proto3" json:"estimated_latency_ms,omitempty"`
+	Reason             string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RecommendServerResponse) Reset() {
+	*x = RecommendServerResponse{}
+	mi := &file_server_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendServerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendServerResponse) ProtoMessage() {}
+
+func (x *RecommendServerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendServerResponse.ProtoReflect.Descriptor instead.
+func (*RecommendServerResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *RecommendServerResponse) GetServer() *Server {
+	if x != nil {
+		return x.Server
+	}
+	return nil
+}
+
+func (x *RecommendServerResponse) GetRegion() *Region {
+	if x != nil {
+		return x.Region
+	}
+	return nil
+}
+
+func (x *RecommendServerResponse) GetLoad() float64 {
+	if x != nil {
+		return x.Load
+	}
+	return 0
+}
+
+func (x *RecommendServerResponse) GetDistanceKm() float64 {
+	if x != nil {
+		return x.DistanceKm
+	}
+	return 0
+}
+
+func (x *RecommendServerResponse) GetEstimatedLatencyMs() float64 {
+	if x != nil {
+		return x.EstimatedLatencyMs
+	}
+	return 0
+}
+
+func (x *RecommendServerResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Nodes
+// Узел Docker Engine. Пути к сертификатам TLS - файлы на хосте
+// server-manager; без них соединение без TLS
+type Node struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"` // tcp://host:2376, ssh://root@host:22
+	TlsCaCert     string                 `protobuf:"bytes,3,opt,name=tls_ca_cert,json=tlsCaCert,proto3" json:"tls_ca_cert,omitempty"`
+	TlsCert       string                 `protobuf:"bytes,4,opt,name=tls_cert,json=tlsCert,proto3" json:"tls_cert,omitempty"`
+	TlsKey        string                 `protobuf:"bytes,5,opt,name=tls_key,json=tlsKey,proto3" json:"tls_key,omitempty"`
+	Region        string                 `protobuf:"bytes,6,opt,name=region,proto3" json:"region,omitempty"` // пустой - серверы любого региона
+	Labels        map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Capacity      int32                  `protobuf:"varint,8,opt,name=capacity,proto3" json:"capacity,omitempty"` // число серверов, 0 - без ограничения
+	State         string                 `protobuf:"bytes,9,opt,name=state,proto3" json:"state,omitempty"`        // active, cordoned, draining
+	Healthy       bool                   `protobuf:"varint,10,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Message       string                 `protobuf:"bytes,11,opt,name=message,proto3" json:"message,omitempty"` // результат последней проверки
+	LastCheckAt   *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=last_check_at,json=lastCheckAt,proto3" json:"last_check_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Kind          string                 `protobuf:"bytes,15,opt,name=kind,proto3" json:"kind,omitempty"` // docker, ssh - хост, подготовленный ProvisionServer
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_server_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *Node) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Node) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *Node) GetTlsCaCert() string {
+	if x != nil {
+		return x.TlsCaCert
+	}
+	return ""
+}
+
+func (x *Node) GetTlsCert() string {
+	if x != nil {
+		return x.TlsCert
+	}
+	return ""
+}
+
+func (x *Node) GetTlsKey() string {
+	if x != nil {
+		return x.TlsKey
+	}
+	return ""
+}
+
+func (x *Node) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Node) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Node) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *Node) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Node) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *Node) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Node) GetLastCheckAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastCheckAt
+	}
+	return nil
+}
+
+func (x *Node) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Node) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Node) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+type ListNodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNodesRequest) Reset() {
+	*x = ListNodesRequest{}
+	mi := &file_server_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNodesRequest) ProtoMessage() {}
+
+func (x *ListNodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNodesRequest.ProtoReflect.Descriptor instead.
+func (*ListNodesRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{54}
+}
+
+type ListNodesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNodesResponse) Reset() {
+	*x = ListNodesResponse{}
+	mi := &file_server_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNodesResponse) ProtoMessage() {}
+
+func (x *ListNodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNodesResponse.ProtoReflect.Descriptor instead.
+func (*ListNodesResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ListNodesResponse) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type RemoveNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveNodeRequest) Reset() {
+	*x = RemoveNodeRequest{}
+	mi := &file_server_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveNodeRequest) ProtoMessage() {}
+
+func (x *RemoveNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveNodeRequest.ProtoReflect.Descriptor instead.
+func (*RemoveNodeRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *RemoveNodeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RemoveNodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveNodeResponse) Reset() {
+	*x = RemoveNodeResponse{}
+	mi := &file_server_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveNodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveNodeResponse) ProtoMessage() {}
+
+func (x *RemoveNodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveNodeResponse.ProtoReflect.Descriptor instead.
+func (*RemoveNodeResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *RemoveNodeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RemoveNodeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type CordonNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CordonNodeRequest) Reset() {
+	*x = CordonNodeRequest{}
+	mi := &file_server_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CordonNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CordonNodeRequest) ProtoMessage() {}
+
+func (x *CordonNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CordonNodeRequest.ProtoReflect.Descriptor instead.
+func (*CordonNodeRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *CordonNodeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UncordonNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UncordonNodeRequest) Reset() {
+	*x = UncordonNodeRequest{}
+	mi := &file_server_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UncordonNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UncordonNodeRequest) ProtoMessage() {}
+
+func (x *UncordonNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UncordonNodeRequest.ProtoReflect.Descriptor instead.
+func (*UncordonNodeRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *UncordonNodeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DrainNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainNodeRequest) Reset() {
+	*x = DrainNodeRequest{}
+	mi := &file_server_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainNodeRequest) ProtoMessage() {}
+
+func (x *DrainNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainNodeRequest.ProtoReflect.Descriptor instead.
+func (*DrainNodeRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *DrainNodeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Reconciliation
+// Расхождение сервера в базе и ресурса оркестратора
+type Drift struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	NodeId        string                 `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Handle        string                 `protobuf:"bytes,4,opt,name=handle,proto3" json:"handle,omitempty"`
+	Type          string                 `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`         // status, stopped, missing, handle, orphan
+	Expected      string                 `protobuf:"bytes,6,opt,name=expected,proto3" json:"expected,omitempty"` // значение в базе
+	Actual        string                 `protobuf:"bytes,7,opt,name=actual,proto3" json:"actual,omitempty"`     // значение в оркестраторе
+	Action        string                 `protobuf:"bytes,8,opt,name=action,proto3" json:"action,omitempty"`     // none, update_status, update_handle, restart, adopt, remove
+	Applied       bool                   `protobuf:"varint,9,opt,name=applied,proto3" json:"applied,omitempty"`
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Drift) Reset() {
+	*x = Drift{}
+	mi := &file_server_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Drift) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Drift) ProtoMessage() {}
+
+func (x *Drift) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Drift.ProtoReflect.Descriptor instead.
+func (*Drift) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *Drift) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *Drift) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Drift) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *Drift) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *Drift) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Drift) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+func (x *Drift) GetActual() string {
+	if x != nil {
+		return x.Actual
+	}
+	return ""
+}
+
+func (x *Drift) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Drift) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+func (x *Drift) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DriftReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Drifts        []*Drift               `protobuf:"bytes,1,rep,name=drifts,proto3" json:"drifts,omitempty"`
+	Checked       int32                  `protobuf:"varint,2,opt,name=checked,proto3" json:"checked,omitempty"`
+	Skipped       int32                  `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"` // серверы недоступных узлов, в процессе изменения и вне оркестратора
+	OrphanPolicy  string                 `protobuf:"bytes,4,opt,name=orphan_policy,json=orphanPolicy,proto3" json:"orphan_policy,omitempty"`
+	DryRun        bool                   `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	CheckedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DriftReport) Reset() {
+	*x = DriftReport{}
+	mi := &file_server_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DriftReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DriftReport) ProtoMessage() {}
+
+func (x *DriftReport) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DriftReport.ProtoReflect.Descriptor instead.
+func (*DriftReport) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *DriftReport) GetDrifts() []*Drift {
+	if x != nil {
+		return x.Drifts
+	}
+	return nil
+}
+
+func (x *DriftReport) GetChecked() int32 {
+	if x != nil {
+		return x.Checked
+	}
+	return 0
+}
+
+func (x *DriftReport) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *DriftReport) GetOrphanPolicy() string {
+	if x != nil {
+		return x.OrphanPolicy
+	}
+	return ""
+}
+
+func (x *DriftReport) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *DriftReport) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+// Отчет без изменений; orphan_policy показывает планируемые действия
+type GetDriftReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrphanPolicy  string                 `protobuf:"bytes,1,opt,name=orphan_policy,json=orphanPolicy,proto3" json:"orphan_policy,omitempty"` // ignore, adopt, remove; по умолчанию ignore
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDriftReportRequest) Reset() {
+	*x = GetDriftReportRequest{}
+	mi := &file_server_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDriftReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDriftReportRequest) ProtoMessage() {}
+
+func (x *GetDriftReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDriftReportRequest.ProtoReflect.Descriptor instead.
+func (*GetDriftReportRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *GetDriftReportRequest) GetOrphanPolicy() string {
+	if x != nil {
+		return x.OrphanPolicy
+	}
+	return ""
+}
+
+type ReconcileServersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrphanPolicy  string                 `protobuf:"bytes,1,opt,name=orphan_policy,json=orphanPolicy,proto3" json:"orphan_policy,omitempty"` // ignore, adopt, remove; по умолчанию ignore
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconcileServersRequest) Reset() {
+	*x = ReconcileServersRequest{}
+	mi := &file_server_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileServersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileServersRequest) ProtoMessage() {}
+
+func (x *ReconcileServersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileServersRequest.ProtoReflect.Descriptor instead.
+func (*ReconcileServersRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *ReconcileServersRequest) GetOrphanPolicy() string {
+	if x != nil {
+		return x.OrphanPolicy
+	}
+	return ""
+}
+
+func (x *ReconcileServersRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// Provisioning
+// Подготовка хоста VPN по SSH; хост регистрируется как сервер vpn
+type ProvisionServerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Region        string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"` // пустой - выбирается размещением
+	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	SshPort       int32                  `protobuf:"varint,4,opt,name=ssh_port,json=sshPort,proto3" json:"ssh_port,omitempty"`           // по умолчанию 22
+	User          string                 `protobuf:"bytes,5,opt,name=user,proto3" json:"user,omitempty"`                                 // по умолчанию root, иначе команды выполняются через sudo
+	HostKey       string                 `protobuf:"bytes,7,opt,name=host_key,json=hostKey,proto3" json:"host_key,omitempty"`            // открытый ключ хоста в формате authorized_keys
+	Mode          string                 `protobuf:"bytes,8,opt,name=mode,proto3" json:"mode,omitempty"`                                 // binary или container; по умолчанию binary
+	BinaryUrl     string                 `protobuf:"bytes,9,opt,name=binary_url,json=binaryUrl,proto3" json:"binary_url,omitempty"`      // обязателен для binary
+	Image         string                 `protobuf:"bytes,10,opt,name=image,proto3" json:"image,omitempty"`                              // для container
+	ListenPort    int32                  `protobuf:"varint,11,opt,name=listen_port,json=listenPort,proto3" json:"listen_port,omitempty"` // порт WireGuard, по умолчанию 51820
+	Env           map[string]string      `protobuf:"bytes,12,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	BinarySha256  string                 `protobuf:"bytes,13,opt,name=binary_sha256,json=binarySha256,proto3" json:"binary_sha256,omitempty"` // обязателен для binary, hex
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvisionServerRequest) Reset() {
+	*x = ProvisionServerRequest{}
+	mi := &file_server_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvisionServerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvisionServerRequest) ProtoMessage() {}
+
+func (x *ProvisionServerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvisionServerRequest.ProtoReflect.Descriptor instead.
+func (*ProvisionServerRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ProvisionServerRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProvisionServerRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ProvisionServerRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *ProvisionServerRequest) GetSshPort() int32 {
+	if x != nil {
+		return x.SshPort
+	}
+	return 0
+}
+
+func (x *ProvisionServerRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *ProvisionServerRequest) GetHostKey() string {
+	if x != nil {
+		return x.HostKey
+	}
+	return ""
+}
+
+func (x *ProvisionServerRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *ProvisionServerRequest) GetBinaryUrl() string {
+	if x != nil {
+		return x.BinaryUrl
+	}
+	return ""
+}
+
+func (x *ProvisionServerRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ProvisionServerRequest) GetListenPort() int32 {
+	if x != nil {
+		return x.ListenPort
+	}
+	return 0
+}
+
+func (x *ProvisionServerRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *ProvisionServerRequest) GetBinarySha256() string {
+	if x != nil {
+		return x.BinarySha256
+	}
+	return ""
+}
+
+type GetServerProvisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerProvisionRequest) Reset() {
+	*x = GetServerProvisionRequest{}
+	mi := &file_server_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerProvisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerProvisionRequest) ProtoMessage() {}
+
+func (x *GetServerProvisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerProvisionRequest.ProtoReflect.Descriptor instead.
+func (*GetServerProvisionRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *GetServerProvisionRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+type Provision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Host          string                 `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	Mode          string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // in_progress, completed, failed
+	Step          string                 `protobuf:"bytes,5,opt,name=step,proto3" json:"step,omitempty"`     // connect, prerequisites, install, configure, start, register
+	Progress      int32                  `protobuf:"varint,6,opt,name=progress,proto3" json:"progress,omitempty"`
+	Message       string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Provision) Reset() {
+	*x = Provision{}
+	mi := &file_server_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Provision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Provision) ProtoMessage() {}
+
+func (x *Provision) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Provision.ProtoReflect.Descriptor instead.
+func (*Provision) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *Provision) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *Provision) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Provision) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Provision) GetStatus() string {
+	if x != nil {
 		return x.Status
 	}
 	return ""
 }
 
-func (x *UpdateStatus) GetProgress() int32 {
+func (x *Provision) GetStep() string {
+	if x != nil {
+		return x.Step
+	}
+	return ""
+}
+
+func (x *Provision) GetProgress() int32 {
 	if x != nil {
 		return x.Progress
 	}
 	return 0
 }
 
-func (x *UpdateStatus) GetMessage() string {
+func (x *Provision) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *UpdateStatus) GetStartedAt() *timestamppb.Timestamp {
+func (x *Provision) GetStartedAt() *timestamppb.Timestamp {
 	if x != nil {
 		return x.StartedAt
 	}
 	return nil
 }
 
-func (x *UpdateStatus) GetCompletedAt() *timestamppb.Timestamp {
+func (x *Provision) GetCompletedAt() *timestamppb.Timestamp {
 	if x != nil {
 		return x.CompletedAt
 	}
@@ -2462,7 +4442,7 @@ const file_server_proto_rawDesc = "" +
 	"\x0eHealthResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x128\n" +
-	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xfb\x03\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x94\x04\n" +
 	"\x06Server\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12&\n" +
@@ -2480,17 +4460,22 @@ const file_server_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x1a9\n" +
+	"updated_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x17\n" +
+	"\anode_id\x18\x0f \x01(\tR\x06nodeId\x1a9\n" +
 	"\vConfigEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe5\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xfa\x02\n" +
 	"\x13CreateServerRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12&\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x12.server.ServerTypeR\x04type\x12\x16\n" +
 	"\x06region\x18\x03 \x01(\tR\x06region\x12?\n" +
-	"\x06config\x18\x04 \x03(\v2'.server.CreateServerRequest.ConfigEntryR\x06config\x1a9\n" +
+	"\x06config\x18\x04 \x03(\v2'.server.CreateServerRequest.ConfigEntryR\x06config\x12R\n" +
+	"\rnode_selector\x18\x05 \x03(\v2-.server.CreateServerRequest.NodeSelectorEntryR\fnodeSelector\x1a9\n" +
 	"\vConfigEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11NodeSelectorEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\"\n" +
 	"\x10GetServerRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"\xb0\x01\n" +
@@ -2557,14 +4542,16 @@ const file_server_proto_rawDesc = "" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"Q\n" +
 	"\x14MonitorServerRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
-	"\x10interval_seconds\x18\x02 \x01(\x05R\x0fintervalSeconds\"\xe3\x01\n" +
+	"\x10interval_seconds\x18\x02 \x01(\x05R\x0fintervalSeconds\"\xab\x02\n" +
 	"\x12ServerMonitorEvent\x12\x1b\n" +
 	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x1d\n" +
 	"\n" +
 	"event_type\x18\x02 \x01(\tR\teventType\x12)\n" +
 	"\x05stats\x18\x03 \x01(\v2\x13.server.ServerStatsR\x05stats\x12,\n" +
 	"\x06health\x18\x04 \x01(\v2\x14.server.ServerHealthR\x06health\x128\n" +
-	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"A\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage\x12,\n" +
+	"\x06status\x18\a \x01(\x0e2\x14.server.ServerStatusR\x06status\"A\n" +
 	"\x17GetServersByTypeRequest\x12&\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x12.server.ServerTypeR\x04type\"D\n" +
 	"\x18GetServersByTypeResponse\x12(\n" +
@@ -2588,7 +4575,25 @@ const file_server_proto_rawDesc = "" +
 	"\breplicas\x18\x04 \x01(\x05R\breplicas\"I\n" +
 	"\x13ScaleServerResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"u\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"1\n" +
+	"\x16EvaluateScalingRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\"\x8f\x03\n" +
+	"\x0fScalingDecision\x12\x1b\n" +
+	"\tpolicy_id\x18\x01 \x01(\tR\bpolicyId\x123\n" +
+	"\vserver_type\x18\x02 \x01(\x0e2\x12.server.ServerTypeR\n" +
+	"serverType\x12\x16\n" +
+	"\x06region\x18\x03 \x01(\tR\x06region\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12%\n" +
+	"\x0eservers_before\x18\x06 \x01(\x05R\rserversBefore\x12#\n" +
+	"\rservers_after\x18\a \x01(\x05R\fserversAfter\x12\x1b\n" +
+	"\tcpu_usage\x18\b \x01(\x01R\bcpuUsage\x12!\n" +
+	"\fmemory_usage\x18\t \x01(\x01R\vmemoryUsage\x12\x17\n" +
+	"\adry_run\x18\n" +
+	" \x01(\bR\x06dryRun\x12=\n" +
+	"\ftriggered_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vtriggeredAt\"P\n" +
+	"\x17EvaluateScalingResponse\x125\n" +
+	"\tdecisions\x18\x01 \x03(\v2\x17.server.ScalingDecisionR\tdecisions\"u\n" +
 	"\x13CreateBackupRequest\x12\x1b\n" +
 	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x1f\n" +
 	"\vbackup_type\x18\x02 \x01(\tR\n" +
@@ -2603,15 +4608,28 @@ const file_server_proto_rawDesc = "" +
 	"\tbackup_id\x18\x02 \x01(\tR\bbackupId\"K\n" +
 	"\x15RestoreBackupResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"j\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xbd\x02\n" +
 	"\x1bUpdateServerSoftwareRequest\x12\x1b\n" +
 	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x14\n" +
-	"\x05force\x18\x03 \x01(\bR\x05force\"\x80\x01\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\x123\n" +
+	"\vserver_type\x18\x04 \x01(\x0e2\x12.server.ServerTypeR\n" +
+	"serverType\x12\x16\n" +
+	"\x06region\x18\x05 \x01(\tR\x06region\x12%\n" +
+	"\x0ecanary_percent\x18\x06 \x01(\x05R\rcanaryPercent\x12'\n" +
+	"\x0fmax_unavailable\x18\a \x01(\x05R\x0emaxUnavailable\x124\n" +
+	"\x16health_timeout_seconds\x18\b \x01(\x05R\x14healthTimeoutSeconds\"\x80\x01\n" +
 	"\x1cUpdateServerSoftwareResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12,\n" +
-	"\x06status\x18\x03 \x01(\v2\x14.server.UpdateStatusR\x06status\"\xf3\x01\n" +
+	"\x06status\x18\x03 \x01(\v2\x14.server.UpdateStatusR\x06status\"=\n" +
+	"\x1eGetServerSoftwareUpdateRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\"@\n" +
+	"!CancelServerSoftwareUpdateRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\"X\n" +
+	"\"CancelServerSoftwareUpdateResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xf3\x01\n" +
 	"\fUpdateStatus\x12\x1b\n" +
 	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
@@ -2619,26 +4637,160 @@ const file_server_proto_rawDesc = "" +
 	"\amessage\x18\x04 \x01(\tR\amessage\x129\n" +
 	"\n" +
 	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
-	"\fcompleted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt*\x87\x01\n" +
+	"\fcompleted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"\xd6\x02\n" +
+	"\x06Region\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\x12\x1a\n" +
+	"\blatitude\x18\x04 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x05 \x01(\x01R\tlongitude\x12\x1f\n" +
+	"\vmax_servers\x18\x06 \x01(\x05R\n" +
+	"maxServers\x12\x19\n" +
+	"\bmax_load\x18\a \x01(\x01R\amaxLoad\x12\x18\n" +
+	"\aenabled\x18\t \x01(\bR\aenabled\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAtJ\x04\b\b\x10\t\"\x14\n" +
+	"\x12ListRegionsRequest\"?\n" +
+	"\x13ListRegionsResponse\x12(\n" +
+	"\aregions\x18\x01 \x03(\v2\x0e.server.RegionR\aregions\")\n" +
+	"\x13DeleteRegionRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\"J\n" +
+	"\x14DeleteRegionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"G\n" +
+	"\vGeoLocation\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\"\xb0\x01\n" +
+	"\x16RecommendServerRequest\x123\n" +
+	"\vserver_type\x18\x01 \x01(\x0e2\x12.server.ServerTypeR\n" +
+	"serverType\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\x12/\n" +
+	"\blocation\x18\x04 \x01(\v2\x13.server.GeoLocationR\blocation\"\xe8\x01\n" +
+	"\x17RecommendServerResponse\x12&\n" +
+	"\x06server\x18\x01 \x01(\v2\x0e.server.ServerR\x06server\x12&\n" +
+	"\x06region\x18\x02 \x01(\v2\x0e.server.RegionR\x06region\x12\x12\n" +
+	"\x04load\x18\x03 \x01(\x01R\x04load\x12\x1f\n" +
+	"\vdistance_km\x18\x04 \x01(\x01R\n" +
+	"distanceKm\x120\n" +
+	"\x14estimated_latency_ms\x18\x05 \x01(\x01R\x12estimatedLatencyMs\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\"\xbb\x04\n" +
+	"\x04Node\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bendpoint\x18\x02 \x01(\tR\bendpoint\x12\x1e\n" +
+	"\vtls_ca_cert\x18\x03 \x01(\tR\ttlsCaCert\x12\x19\n" +
+	"\btls_cert\x18\x04 \x01(\tR\atlsCert\x12\x17\n" +
+	"\atls_key\x18\x05 \x01(\tR\x06tlsKey\x12\x16\n" +
+	"\x06region\x18\x06 \x01(\tR\x06region\x120\n" +
+	"\x06labels\x18\a \x03(\v2\x18.server.Node.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bcapacity\x18\b \x01(\x05R\bcapacity\x12\x14\n" +
+	"\x05state\x18\t \x01(\tR\x05state\x12\x18\n" +
+	"\ahealthy\x18\n" +
+	" \x01(\bR\ahealthy\x12\x18\n" +
+	"\amessage\x18\v \x01(\tR\amessage\x12>\n" +
+	"\rlast_check_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\vlastCheckAt\x129\n" +
+	"\n" +
+	"created_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04kind\x18\x0f \x01(\tR\x04kind\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x12\n" +
+	"\x10ListNodesRequest\"7\n" +
+	"\x11ListNodesResponse\x12\"\n" +
+	"\x05nodes\x18\x01 \x03(\v2\f.server.NodeR\x05nodes\"#\n" +
+	"\x11RemoveNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"H\n" +
+	"\x12RemoveNodeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"#\n" +
+	"\x11CordonNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"%\n" +
+	"\x13UncordonNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\"\n" +
+	"\x10DrainNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xf9\x01\n" +
+	"\x05Drift\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x17\n" +
+	"\anode_id\x18\x03 \x01(\tR\x06nodeId\x12\x16\n" +
+	"\x06handle\x18\x04 \x01(\tR\x06handle\x12\x12\n" +
+	"\x04type\x18\x05 \x01(\tR\x04type\x12\x1a\n" +
+	"\bexpected\x18\x06 \x01(\tR\bexpected\x12\x16\n" +
+	"\x06actual\x18\a \x01(\tR\x06actual\x12\x16\n" +
+	"\x06action\x18\b \x01(\tR\x06action\x12\x18\n" +
+	"\aapplied\x18\t \x01(\bR\aapplied\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\"\xe1\x01\n" +
+	"\vDriftReport\x12%\n" +
+	"\x06drifts\x18\x01 \x03(\v2\r.server.DriftR\x06drifts\x12\x18\n" +
+	"\achecked\x18\x02 \x01(\x05R\achecked\x12\x18\n" +
+	"\askipped\x18\x03 \x01(\x05R\askipped\x12#\n" +
+	"\rorphan_policy\x18\x04 \x01(\tR\forphanPolicy\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\x129\n" +
+	"\n" +
+	"checked_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\"<\n" +
+	"\x15GetDriftReportRequest\x12#\n" +
+	"\rorphan_policy\x18\x01 \x01(\tR\forphanPolicy\"W\n" +
+	"\x17ReconcileServersRequest\x12#\n" +
+	"\rorphan_policy\x18\x01 \x01(\tR\forphanPolicy\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\xbc\x03\n" +
+	"\x16ProvisionServerRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12\x19\n" +
+	"\bssh_port\x18\x04 \x01(\x05R\asshPort\x12\x12\n" +
+	"\x04user\x18\x05 \x01(\tR\x04user\x12\x19\n" +
+	"\bhost_key\x18\a \x01(\tR\ahostKey\x12\x12\n" +
+	"\x04mode\x18\b \x01(\tR\x04mode\x12\x1d\n" +
+	"\n" +
+	"binary_url\x18\t \x01(\tR\tbinaryUrl\x12\x14\n" +
+	"\x05image\x18\n" +
+	" \x01(\tR\x05image\x12\x1f\n" +
+	"\vlisten_port\x18\v \x01(\x05R\n" +
+	"listenPort\x129\n" +
+	"\x03env\x18\f \x03(\v2'.server.ProvisionServerRequest.EnvEntryR\x03env\x12#\n" +
+	"\rbinary_sha256\x18\r \x01(\tR\fbinarySha256\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01J\x04\b\x06\x10\aR\x10private_key_path\"8\n" +
+	"\x19GetServerProvisionRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\"\xac\x02\n" +
+	"\tProvision\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x12\n" +
+	"\x04step\x18\x05 \x01(\tR\x04step\x12\x1a\n" +
+	"\bprogress\x18\x06 \x01(\x05R\bprogress\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\x129\n" +
+	"\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt*\x87\x01\n" +
 	"\n" +
 	"ServerType\x12\x1b\n" +
 	"\x17SERVER_TYPE_UNSPECIFIED\x10\x00\x12\x13\n" +
 	"\x0fSERVER_TYPE_VPN\x10\x01\x12\x13\n" +
 	"\x0fSERVER_TYPE_DPI\x10\x02\x12\x17\n" +
 	"\x13SERVER_TYPE_GATEWAY\x10\x03\x12\x19\n" +
-	"\x15SERVER_TYPE_ANALYTICS\x10\x04*\xb4\x01\n" +
+	"\x15SERVER_TYPE_ANALYTICS\x10\x04*\xd0\x01\n" +
 	"\fServerStatus\x12\x1d\n" +
 	"\x19SERVER_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
 	"\x16SERVER_STATUS_CREATING\x10\x01\x12\x19\n" +
 	"\x15SERVER_STATUS_RUNNING\x10\x02\x12\x19\n" +
 	"\x15SERVER_STATUS_STOPPED\x10\x03\x12\x17\n" +
 	"\x13SERVER_STATUS_ERROR\x10\x04\x12\x1a\n" +
-	"\x16SERVER_STATUS_DELETING\x10\x05*n\n" +
+	"\x16SERVER_STATUS_DELETING\x10\x05\x12\x1a\n" +
+	"\x16SERVER_STATUS_UPDATING\x10\x06*n\n" +
 	"\vScaleAction\x12\x1c\n" +
 	"\x18SCALE_ACTION_UNSPECIFIED\x10\x00\x12\x13\n" +
 	"\x0fSCALE_ACTION_UP\x10\x01\x12\x15\n" +
 	"\x11SCALE_ACTION_DOWN\x10\x02\x12\x15\n" +
-	"\x11SCALE_ACTION_AUTO\x10\x032\x9f\v\n" +
+	"\x11SCALE_ACTION_AUTO\x10\x032\xcf\x14\n" +
 	"\x14ServerManagerService\x127\n" +
 	"\x06Health\x12\x15.server.HealthRequest\x1a\x16.server.HealthResponse\x12;\n" +
 	"\fCreateServer\x12\x1b.server.CreateServerRequest\x1a\x0e.server.Server\x125\n" +
@@ -2656,10 +4808,30 @@ const file_server_proto_rawDesc = "" +
 	"\x10GetServersByType\x12\x1f.server.GetServersByTypeRequest\x1a .server.GetServersByTypeResponse\x12[\n" +
 	"\x12GetServersByRegion\x12!.server.GetServersByRegionRequest\x1a\".server.GetServersByRegionResponse\x12[\n" +
 	"\x12GetServersByStatus\x12!.server.GetServersByStatusRequest\x1a\".server.GetServersByStatusResponse\x12F\n" +
-	"\vScaleServer\x12\x1a.server.ScaleServerRequest\x1a\x1b.server.ScaleServerResponse\x12I\n" +
+	"\vScaleServer\x12\x1a.server.ScaleServerRequest\x1a\x1b.server.ScaleServerResponse\x12R\n" +
+	"\x0fEvaluateScaling\x12\x1e.server.EvaluateScalingRequest\x1a\x1f.server.EvaluateScalingResponse\x12I\n" +
 	"\fCreateBackup\x12\x1b.server.CreateBackupRequest\x1a\x1c.server.CreateBackupResponse\x12L\n" +
 	"\rRestoreBackup\x12\x1c.server.RestoreBackupRequest\x1a\x1d.server.RestoreBackupResponse\x12a\n" +
-	"\x14UpdateServerSoftware\x12#.server.UpdateServerSoftwareRequest\x1a$.server.UpdateServerSoftwareResponseB9Z7github.com/par1ram/silence/rpc/server-manager/api/protob\x06proto3"
+	"\x14UpdateServerSoftware\x12#.server.UpdateServerSoftwareRequest\x1a$.server.UpdateServerSoftwareResponse\x12W\n" +
+	"\x17GetServerSoftwareUpdate\x12&.server.GetServerSoftwareUpdateRequest\x1a\x14.server.UpdateStatus\x12s\n" +
+	"\x1aCancelServerSoftwareUpdate\x12).server.CancelServerSoftwareUpdateRequest\x1a*.server.CancelServerSoftwareUpdateResponse\x12F\n" +
+	"\vListRegions\x12\x1a.server.ListRegionsRequest\x1a\x1b.server.ListRegionsResponse\x12,\n" +
+	"\n" +
+	"SaveRegion\x12\x0e.server.Region\x1a\x0e.server.Region\x12I\n" +
+	"\fDeleteRegion\x12\x1b.server.DeleteRegionRequest\x1a\x1c.server.DeleteRegionResponse\x12R\n" +
+	"\x0fRecommendServer\x12\x1e.server.RecommendServerRequest\x1a\x1f.server.RecommendServerResponse\x12*\n" +
+	"\fRegisterNode\x12\f.server.Node\x1a\f.server.Node\x12@\n" +
+	"\tListNodes\x12\x18.server.ListNodesRequest\x1a\x19.server.ListNodesResponse\x12C\n" +
+	"\n" +
+	"RemoveNode\x12\x19.server.RemoveNodeRequest\x1a\x1a.server.RemoveNodeResponse\x125\n" +
+	"\n" +
+	"CordonNode\x12\x19.server.CordonNodeRequest\x1a\f.server.Node\x129\n" +
+	"\fUncordonNode\x12\x1b.server.UncordonNodeRequest\x1a\f.server.Node\x123\n" +
+	"\tDrainNode\x12\x18.server.DrainNodeRequest\x1a\f.server.Node\x12D\n" +
+	"\x0eGetDriftReport\x12\x1d.server.GetDriftReportRequest\x1a\x13.server.DriftReport\x12H\n" +
+	"\x10ReconcileServers\x12\x1f.server.ReconcileServersRequest\x1a\x13.server.DriftReport\x12A\n" +
+	"\x0fProvisionServer\x12\x1e.server.ProvisionServerRequest\x1a\x0e.server.Server\x12J\n" +
+	"\x12GetServerProvision\x12!.server.GetServerProvisionRequest\x1a\x11.server.ProvisionB9Z7github.com/par1ram/silence/rpc/server-manager/api/protob\x06proto3"
 
 var (
 	file_server_proto_rawDescOnce sync.Once
@@ -2674,128 +4846,217 @@ func file_server_proto_rawDescGZIP() []byte {
 }
 
 var file_server_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
+var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 74)
 var file_server_proto_goTypes = []any{
-	(ServerType)(0),                      // 0: server.ServerType
-	(ServerStatus)(0),                    // 1: server.ServerStatus
-	(ScaleAction)(0),                     // 2: server.ScaleAction
-	(*HealthRequest)(nil),                // 3: server.HealthRequest
-	(*HealthResponse)(nil),               // 4: server.HealthResponse
-	(*Server)(nil),                       // 5: server.Server
-	(*CreateServerRequest)(nil),          // 6: server.CreateServerRequest
-	(*GetServerRequest)(nil),             // 7: server.GetServerRequest
-	(*ListServersRequest)(nil),           // 8: server.ListServersRequest
-	(*ListServersResponse)(nil),          // 9: server.ListServersResponse
-	(*UpdateServerRequest)(nil),          // 10: server.UpdateServerRequest
-	(*DeleteServerRequest)(nil),          // 11: server.DeleteServerRequest
-	(*DeleteServerResponse)(nil),         // 12: server.DeleteServerResponse
-	(*StartServerRequest)(nil),           // 13: server.StartServerRequest
-	(*StartServerResponse)(nil),          // 14: server.StartServerResponse
-	(*StopServerRequest)(nil),            // 15: server.StopServerRequest
-	(*StopServerResponse)(nil),           // 16: server.StopServerResponse
-	(*RestartServerRequest)(nil),         // 17: server.RestartServerRequest
-	(*RestartServerResponse)(nil),        // 18: server.RestartServerResponse
-	(*ServerStats)(nil),                  // 19: server.ServerStats
-	(*GetServerStatsRequest)(nil),        // 20: server.GetServerStatsRequest
-	(*ServerHealth)(nil),                 // 21: server.ServerHealth
-	(*HealthCheck)(nil),                  // 22: server.HealthCheck
-	(*GetServerHealthRequest)(nil),       // 23: server.GetServerHealthRequest
-	(*MonitorServerRequest)(nil),         // 24: server.MonitorServerRequest
-	(*ServerMonitorEvent)(nil),           // 25: server.ServerMonitorEvent
-	(*GetServersByTypeRequest)(nil),      // 26: server.GetServersByTypeRequest
-	(*GetServersByTypeResponse)(nil),     // 27: server.GetServersByTypeResponse
-	(*GetServersByRegionRequest)(nil),    // 28: server.GetServersByRegionRequest
-	(*GetServersByRegionResponse)(nil),   // 29: server.GetServersByRegionResponse
-	(*GetServersByStatusRequest)(nil),    // 30: server.GetServersByStatusRequest
-	(*GetServersByStatusResponse)(nil),   // 31: server.GetServersByStatusResponse
-	(*ScaleServerRequest)(nil),           // 32: server.ScaleServerRequest
-	(*ScaleSpec)(nil),                    // 33: server.ScaleSpec
-	(*ScaleServerResponse)(nil),          // 34: server.ScaleServerResponse
-	(*CreateBackupRequest)(nil),          // 35: server.CreateBackupRequest
-	(*CreateBackupResponse)(nil),         // 36: server.CreateBackupResponse
-	(*RestoreBackupRequest)(nil),         // 37: server.RestoreBackupRequest
-	(*RestoreBackupResponse)(nil),        // 38: server.RestoreBackupResponse
-	(*UpdateServerSoftwareRequest)(nil),  // 39: server.UpdateServerSoftwareRequest
-	(*UpdateServerSoftwareResponse)(nil), // 40: server.UpdateServerSoftwareResponse
-	(*UpdateStatus)(nil),                 // 41: server.UpdateStatus
-	nil,                                  // 42: server.Server.ConfigEntry
-	nil,                                  // 43: server.CreateServerRequest.ConfigEntry
-	nil,                                  // 44: server.UpdateServerRequest.ConfigEntry
-	(*timestamppb.Timestamp)(nil),        // 45: google.protobuf.Timestamp
+	(ServerType)(0),                            // 0: server.ServerType
+	(ServerStatus)(0),                          // 1: server.ServerStatus
+	(ScaleAction)(0),                           // 2: server.ScaleAction
+	(*HealthRequest)(nil),                      // 3: server.HealthRequest
+	(*HealthResponse)(nil),                     // 4: server.HealthResponse
+	(*Server)(nil),                             // 5: server.Server
+	(*CreateServerRequest)(nil),                // 6: server.CreateServerRequest
+	(*GetServerRequest)(nil),                   // 7: server.GetServerRequest
+	(*ListServersRequest)(nil),                 // 8: server.ListServersRequest
+	(*ListServersResponse)(nil),                // 9: server.ListServersResponse
+	(*UpdateServerRequest)(nil),                // 10: server.UpdateServerRequest
+	(*DeleteServerRequest)(nil),                // 11: server.DeleteServerRequest
+	(*DeleteServerResponse)(nil),               // 12: server.DeleteServerResponse
+	(*StartServerRequest)(nil),                 // 13: server.StartServerRequest
+	(*StartServerResponse)(nil),                // 14: server.StartServerResponse
+	(*StopServerRequest)(nil),                  // 15: server.StopServerRequest
+	(*StopServerResponse)(nil),                 // 16: server.StopServerResponse
+	(*RestartServerRequest)(nil),               // 17: server.RestartServerRequest
+	(*RestartServerResponse)(nil),              // 18: server.RestartServerResponse
+	(*ServerStats)(nil),                        // 19: server.ServerStats
+	(*GetServerStatsRequest)(nil),              // 20: server.GetServerStatsRequest
+	(*ServerHealth)(nil),                       // 21: server.ServerHealth
+	(*HealthCheck)(nil),                        // 22: server.HealthCheck
+	(*GetServerHealthRequest)(nil),             // 23: server.GetServerHealthRequest
+	(*MonitorServerRequest)(nil),               // 24: server.MonitorServerRequest
+	(*ServerMonitorEvent)(nil),                 // 25: server.ServerMonitorEvent
+	(*GetServersByTypeRequest)(nil),            // 26: server.GetServersByTypeRequest
+	(*GetServersByTypeResponse)(nil),           // 27: server.GetServersByTypeResponse
+	(*GetServersByRegionRequest)(nil),          // 28: server.GetServersByRegionRequest
+	(*GetServersByRegionResponse)(nil),         // 29: server.GetServersByRegionResponse
+	(*GetServersByStatusRequest)(nil),          // 30: server.GetServersByStatusRequest
+	(*GetServersByStatusResponse)(nil),         // 31: server.GetServersByStatusResponse
+	(*ScaleServerRequest)(nil),                 // 32: server.ScaleServerRequest
+	(*ScaleSpec)(nil),                          // 33: server.ScaleSpec
+	(*ScaleServerResponse)(nil),                // 34: server.ScaleServerResponse
+	(*EvaluateScalingRequest)(nil),             // 35: server.EvaluateScalingRequest
+	(*ScalingDecision)(nil),                    // 36: server.ScalingDecision
+	(*EvaluateScalingResponse)(nil),            // 37: server.EvaluateScalingResponse
+	(*CreateBackupRequest)(nil),                // 38: server.CreateBackupRequest
+	(*CreateBackupResponse)(nil),               // 39: server.CreateBackupResponse
+	(*RestoreBackupRequest)(nil),               // 40: server.RestoreBackupRequest
+	(*RestoreBackupResponse)(nil),              // 41: server.RestoreBackupResponse
+	(*UpdateServerSoftwareRequest)(nil),        // 42: server.UpdateServerSoftwareRequest
+	(*UpdateServerSoftwareResponse)(nil),       // 43: server.UpdateServerSoftwareResponse
+	(*GetServerSoftwareUpdateRequest)(nil),     // 44: server.GetServerSoftwareUpdateRequest
+	(*CancelServerSoftwareUpdateRequest)(nil),  // 45: server.CancelServerSoftwareUpdateRequest
+	(*CancelServerSoftwareUpdateResponse)(nil), // 46: server.CancelServerSoftwareUpdateResponse
+	(*UpdateStatus)(nil),                       // 47: server.UpdateStatus
+	(*Region)(nil),                             // 48: server.Region
+	(*ListRegionsRequest)(nil),                 // 49: server.ListRegionsRequest
+	(*ListRegionsResponse)(nil),                // 50: server.ListRegionsResponse
+	(*DeleteRegionRequest)(nil),                // 51: server.DeleteRegionRequest
+	(*DeleteRegionResponse)(nil),               // 52: server.DeleteRegionResponse
+	(*GeoLocation)(nil),                        // 53: server.GeoLocation
+	(*RecommendServerRequest)(nil),             // 54: server.RecommendServerRequest
+	(*RecommendServerResponse)(nil),            // 55: server.RecommendServerResponse
+	(*Node)(nil),                               // 56: server.Node
+	(*ListNodesRequest)(nil),                   // 57: server.ListNodesRequest
+	(*ListNodesResponse)(nil),                  // 58: server.ListNodesResponse
+	(*RemoveNodeRequest)(nil),                  // 59: server.RemoveNodeRequest
+	(*RemoveNodeResponse)(nil),                 // 60: server.RemoveNodeResponse
+	(*CordonNodeRequest)(nil),                  // 61: server.CordonNodeRequest
+	(*UncordonNodeRequest)(nil),                // 62: server.UncordonNodeRequest
+	(*DrainNodeRequest)(nil),                   // 63: server.DrainNodeRequest
+	(*Drift)(nil),                              // 64: server.Drift
+	(*DriftReport)(nil),                        // 65: server.DriftReport
+	(*GetDriftReportRequest)(nil),              // 66: server.GetDriftReportRequest
+	(*ReconcileServersRequest)(nil),            // 67: server.ReconcileServersRequest
+	(*ProvisionServerRequest)(nil),             // 68: server.ProvisionServerRequest
+	(*GetServerProvisionRequest)(nil),          // 69: server.GetServerProvisionRequest
+	(*Provision)(nil),                          // 70: server.Provision
+	nil,                                        // 71: server.Server.ConfigEntry
+	nil,                                        // 72: server.CreateServerRequest.ConfigEntry
+	nil,                                        // 73: server.CreateServerRequest.NodeSelectorEntry
+	nil,                                        // 74: server.UpdateServerRequest.ConfigEntry
+	nil,                                        // 75: server.Node.LabelsEntry
+	nil,                                        // 76: server.ProvisionServerRequest.EnvEntry
+	(*timestamppb.Timestamp)(nil),              // 77: google.protobuf.Timestamp
 }
 var file_server_proto_depIdxs = []int32{
-	45, // 0: server.HealthResponse.timestamp:type_name -> google.protobuf.Timestamp
+	77, // 0: server.HealthResponse.timestamp:type_name -> google.protobuf.Timestamp
 	0,  // 1: server.Server.type:type_name -> server.ServerType
 	1,  // 2: server.Server.status:type_name -> server.ServerStatus
-	42, // 3: server.Server.config:type_name -> server.Server.ConfigEntry
-	45, // 4: server.Server.created_at:type_name -> google.protobuf.Timestamp
-	45, // 5: server.Server.updated_at:type_name -> google.protobuf.Timestamp
+	71, // 3: server.Server.config:type_name -> server.Server.ConfigEntry
+	77, // 4: server.Server.created_at:type_name -> google.protobuf.Timestamp
+	77, // 5: server.Server.updated_at:type_name -> google.protobuf.Timestamp
 	0,  // 6: server.CreateServerRequest.type:type_name -> server.ServerType
-	43, // 7: server.CreateServerRequest.config:type_name -> server.CreateServerRequest.ConfigEntry
-	0,  // 8: server.ListServersRequest.type:type_name -> server.ServerType
-	1,  // 9: server.ListServersRequest.status:type_name -> server.ServerStatus
-	5,  // 10: server.ListServersResponse.servers:type_name -> server.Server
-	1,  // 11: server.UpdateServerRequest.status:type_name -> server.ServerStatus
-	44, // 12: server.UpdateServerRequest.config:type_name -> server.UpdateServerRequest.ConfigEntry
-	45, // 13: server.ServerStats.timestamp:type_name -> google.protobuf.Timestamp
-	22, // 14: server.ServerHealth.checks:type_name -> server.HealthCheck
-	45, // 15: server.ServerHealth.timestamp:type_name -> google.protobuf.Timestamp
-	19, // 16: server.ServerMonitorEvent.stats:type_name -> server.ServerStats
-	21, // 17: server.ServerMonitorEvent.health:type_name -> server.ServerHealth
-	45, // 18: server.ServerMonitorEvent.timestamp:type_name -> google.protobuf.Timestamp
-	0,  // 19: server.GetServersByTypeRequest.type:type_name -> server.ServerType
-	5,  // 20: server.GetServersByTypeResponse.servers:type_name -> server.Server
-	5,  // 21: server.GetServersByRegionResponse.servers:type_name -> server.Server
-	1,  // 22: server.GetServersByStatusRequest.status:type_name -> server.ServerStatus
-	5,  // 23: server.GetServersByStatusResponse.servers:type_name -> server.Server
-	2,  // 24: server.ScaleServerRequest.action:type_name -> server.ScaleAction
-	33, // 25: server.ScaleServerRequest.spec:type_name -> server.ScaleSpec
-	41, // 26: server.UpdateServerSoftwareResponse.status:type_name -> server.UpdateStatus
-	45, // 27: server.UpdateStatus.started_at:type_name -> google.protobuf.Timestamp
-	45, // 28: server.UpdateStatus.completed_at:type_name -> google.protobuf.Timestamp
-	3,  // 29: server.ServerManagerService.Health:input_type -> server.HealthRequest
-	6,  // 30: server.ServerManagerService.CreateServer:input_type -> server.CreateServerRequest
-	7,  // 31: server.ServerManagerService.GetServer:input_type -> server.GetServerRequest
-	8,  // 32: server.ServerManagerService.ListServers:input_type -> server.ListServersRequest
-	10, // 33: server.ServerManagerService.UpdateServer:input_type -> server.UpdateServerRequest
-	11, // 34: server.ServerManagerService.DeleteServer:input_type -> server.DeleteServerRequest
-	13, // 35: server.ServerManagerService.StartServer:input_type -> server.StartServerRequest
-	15, // 36: server.ServerManagerService.StopServer:input_type -> server.StopServerRequest
-	17, // 37: server.ServerManagerService.RestartServer:input_type -> server.RestartServerRequest
-	20, // 38: server.ServerManagerService.GetServerStats:input_type -> server.GetServerStatsRequest
-	23, // 39: server.ServerManagerService.GetServerHealth:input_type -> server.GetServerHealthRequest
-	24, // 40: server.ServerManagerService.MonitorServer:input_type -> server.MonitorServerRequest
-	26, // 41: server.ServerManagerService.GetServersByType:input_type -> server.GetServersByTypeRequest
-	28, // 42: server.ServerManagerService.GetServersByRegion:input_type -> server.GetServersByRegionRequest
-	30, // 43: server.ServerManagerService.GetServersByStatus:input_type -> server.GetServersByStatusRequest
-	32, // 44: server.ServerManagerService.ScaleServer:input_type -> server.ScaleServerRequest
-	35, // 45: server.ServerManagerService.CreateBackup:input_type -> server.CreateBackupRequest
-	37, // 46: server.ServerManagerService.RestoreBackup:input_type -> server.RestoreBackupRequest
-	39, // 47: server.ServerManagerService.UpdateServerSoftware:input_type -> server.UpdateServerSoftwareRequest
-	4,  // 48: server.ServerManagerService.Health:output_type -> server.HealthResponse
-	5,  // 49: server.ServerManagerService.CreateServer:output_type -> server.Server
-	5,  // 50: server.ServerManagerService.GetServer:output_type -> server.Server
-	9,  // 51: server.ServerManagerService.ListServers:output_type -> server.ListServersResponse
-	5,  // 52: server.ServerManagerService.UpdateServer:output_type -> server.Server
-	12, // 53: server.ServerManagerService.DeleteServer:output_type -> server.DeleteServerResponse
-	14, // 54: server.ServerManagerService.StartServer:output_type -> server.StartServerResponse
-	16, // 55: server.ServerManagerService.StopServer:output_type -> server.StopServerResponse
-	18, // 56: server.ServerManagerService.RestartServer:output_type -> server.RestartServerResponse
-	19, // 57: server.ServerManagerService.GetServerStats:output_type -> server.ServerStats
-	21, // 58: server.ServerManagerService.GetServerHealth:output_type -> server.ServerHealth
-	25, // 59: server.ServerManagerService.MonitorServer:output_type -> server.ServerMonitorEvent
-	27, // 60: server.ServerManagerService.GetServersByType:output_type -> server.GetServersByTypeResponse
-	29, // 61: server.ServerManagerService.GetServersByRegion:output_type -> server.GetServersByRegionResponse
-	31, // 62: server.ServerManagerService.GetServersByStatus:output_type -> server.GetServersByStatusResponse
-	34, // 63: server.ServerManagerService.ScaleServer:output_type -> server.ScaleServerResponse
-	36, // 64: server.ServerManagerService.CreateBackup:output_type -> server.CreateBackupResponse
-	38, // 65: server.ServerManagerService.RestoreBackup:output_type -> server.RestoreBackupResponse
-	40, // 66: server.ServerManagerService.UpdateServerSoftware:output_type -> server.UpdateServerSoftwareResponse
-	48, // [48:67] is the sub-list for method output_type
-	29, // [29:48] is the sub-list for method input_type
-	29, // [29:29] is the sub-list for extension type_name
-	29, // [29:29] is the sub-list for extension extendee
-	0,  // [0:29] is the sub-list for field type_name
+	72, // 7: server.CreateServerRequest.config:type_name -> server.CreateServerRequest.ConfigEntry
+	73, // 8: server.CreateServerRequest.node_selector:type_name -> server.CreateServerRequest.NodeSelectorEntry
+	0,  // 9: server.ListServersRequest.type:type_name -> server.ServerType
+	1,  // 10: server.ListServersRequest.status:type_name -> server.ServerStatus
+	5,  // 11: server.ListServersResponse.servers:type_name -> server.Server
+	1,  // 12: server.UpdateServerRequest.status:type_name -> server.ServerStatus
+	74, // 13: server.UpdateServerRequest.config:type_name -> server.UpdateServerRequest.ConfigEntry
+	77, // 14: server.ServerStats.timestamp:type_name -> google.protobuf.Timestamp
+	22, // 15: server.ServerHealth.checks:type_name -> server.HealthCheck
+	77, // 16: server.ServerHealth.timestamp:type_name -> google.protobuf.Timestamp
+	19, // 17: server.ServerMonitorEvent.stats:type_name -> server.ServerStats
+	21, // 18: server.ServerMonitorEvent.health:type_name -> server.ServerHealth
+	77, // 19: server.ServerMonitorEvent.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 20: server.ServerMonitorEvent.status:type_name -> server.ServerStatus
+	0,  // 21: server.GetServersByTypeRequest.type:type_name -> server.ServerType
+	5,  // 22: server.GetServersByTypeResponse.servers:type_name -> server.Server
+	5,  // 23: server.GetServersByRegionResponse.servers:type_name -> server.Server
+	1,  // 24: server.GetServersByStatusRequest.status:type_name -> server.ServerStatus
+	5,  // 25: server.GetServersByStatusResponse.servers:type_name -> server.Server
+	2,  // 26: server.ScaleServerRequest.action:type_name -> server.ScaleAction
+	33, // 27: server.ScaleServerRequest.spec:type_name -> server.ScaleSpec
+	0,  // 28: server.ScalingDecision.server_type:type_name -> server.ServerType
+	77, // 29: server.ScalingDecision.triggered_at:type_name -> google.protobuf.Timestamp
+	36, // 30: server.EvaluateScalingResponse.decisions:type_name -> server.ScalingDecision
+	0,  // 31: server.UpdateServerSoftwareRequest.server_type:type_name -> server.ServerType
+	47, // 32: server.UpdateServerSoftwareResponse.status:type_name -> server.UpdateStatus
+	77, // 33: server.UpdateStatus.started_at:type_name -> google.protobuf.Timestamp
+	77, // 34: server.UpdateStatus.completed_at:type_name -> google.protobuf.Timestamp
+	77, // 35: server.Region.created_at:type_name -> google.protobuf.Timestamp
+	77, // 36: server.Region.updated_at:type_name -> google.protobuf.Timestamp
+	48, // 37: server.ListRegionsResponse.regions:type_name -> server.Region
+	0,  // 38: server.RecommendServerRequest.server_type:type_name -> server.ServerType
+	53, // 39: server.RecommendServerRequest.location:type_name -> server.GeoLocation
+	5,  // 40: server.RecommendServerResponse.server:type_name -> server.Server
+	48, // 41: server.RecommendServerResponse.region:type_name -> server.Region
+	75, // 42: server.Node.labels:type_name -> server.Node.LabelsEntry
+	77, // 43: server.Node.last_check_at:type_name -> google.protobuf.Timestamp
+	77, // 44: server.Node.created_at:type_name -> google.protobuf.Timestamp
+	77, // 45: server.Node.updated_at:type_name -> google.protobuf.Timestamp
+	56, // 46: server.ListNodesResponse.nodes:type_name -> server.Node
+	64, // 47: server.DriftReport.drifts:type_name -> server.Drift
+	77, // 48: server.DriftReport.checked_at:type_name -> google.protobuf.Timestamp
+	76, // 49: server.ProvisionServerRequest.env:type_name -> server.ProvisionServerRequest.EnvEntry
+	77, // 50: server.Provision.started_at:type_name -> google.protobuf.Timestamp
+	77, // 51: server.Provision.completed_at:type_name -> google.protobuf.Timestamp
+	3,  // 52: server.ServerManagerService.Health:input_type -> server.HealthRequest
+	6,  // 53: server.ServerManagerService.CreateServer:input_type -> server.CreateServerRequest
+	7,  // 54: server.ServerManagerService.GetServer:input_type -> server.GetServerRequest
+	8,  // 55: server.ServerManagerService.ListServers:input_type -> server.ListServersRequest
+	10, // 56: server.ServerManagerService.UpdateServer:input_type -> server.UpdateServerRequest
+	11, // 57: server.ServerManagerService.DeleteServer:input_type -> server.DeleteServerRequest
+	13, // 58: server.ServerManagerService.StartServer:input_type -> server.StartServerRequest
+	15, // 59: server.ServerManagerService.StopServer:input_type -> server.StopServerRequest
+	17, // 60: server.ServerManagerService.RestartServer:input_type -> server.RestartServerRequest
+	20, // 61: server.ServerManagerService.GetServerStats:input_type -> server.GetServerStatsRequest
+	23, // 62: server.ServerManagerService.GetServerHealth:input_type -> server.GetServerHealthRequest
+	24, // 63: server.ServerManagerService.MonitorServer:input_type -> server.MonitorServerRequest
+	26, // 64: server.ServerManagerService.GetServersByType:input_type -> server.GetServersByTypeRequest
+	28, // 65: server.ServerManagerService.GetServersByRegion:input_type -> server.GetServersByRegionRequest
+	30, // 66: server.ServerManagerService.GetServersByStatus:input_type -> server.GetServersByStatusRequest
+	32, // 67: server.ServerManagerService.ScaleServer:input_type -> server.ScaleServerRequest
+	35, // 68: server.ServerManagerService.EvaluateScaling:input_type -> server.EvaluateScalingRequest
+	38, // 69: server.ServerManagerService.CreateBackup:input_type -> server.CreateBackupRequest
+	40, // 70: server.ServerManagerService.RestoreBackup:input_type -> server.RestoreBackupRequest
+	42, // 71: server.ServerManagerService.UpdateServerSoftware:input_type -> server.UpdateServerSoftwareRequest
+	44, // 72: server.ServerManagerService.GetServerSoftwareUpdate:input_type -> server.GetServerSoftwareUpdateRequest
+	45, // 73: server.ServerManagerService.CancelServerSoftwareUpdate:input_type -> server.CancelServerSoftwareUpdateRequest
+	49, // 74: server.ServerManagerService.ListRegions:input_type -> server.ListRegionsRequest
+	48, // 75: server.ServerManagerService.SaveRegion:input_type -> server.Region
+	51, // 76: server.ServerManagerService.DeleteRegion:input_type -> server.DeleteRegionRequest
+	54, // 77: server.ServerManagerService.RecommendServer:input_type -> server.RecommendServerRequest
+	56, // 78: server.ServerManagerService.RegisterNode:input_type -> server.Node
+	57, // 79: server.ServerManagerService.ListNodes:input_type -> server.ListNodesRequest
+	59, // 80: server.ServerManagerService.RemoveNode:input_type -> server.RemoveNodeRequest
+	61, // 81: server.ServerManagerService.CordonNode:input_type -> server.CordonNodeRequest
+	62, // 82: server.ServerManagerService.UncordonNode:input_type -> server.UncordonNodeRequest
+	63, // 83: server.ServerManagerService.DrainNode:input_type -> server.DrainNodeRequest
+	66, // 84: server.ServerManagerService.GetDriftReport:input_type -> server.GetDriftReportRequest
+	67, // 85: server.ServerManagerService.ReconcileServers:input_type -> server.ReconcileServersRequest
+	68, // 86: server.ServerManagerService.ProvisionServer:input_type -> server.ProvisionServerRequest
+	69, // 87: server.ServerManagerService.GetServerProvision:input_type -> server.GetServerProvisionRequest
+	4,  // 88: server.ServerManagerService.Health:output_type -> server.HealthResponse
+	5,  // 89: server.ServerManagerService.CreateServer:output_type -> server.Server
+	5,  // 90: server.ServerManagerService.GetServer:output_type -> server.Server
+	9,  // 91: server.ServerManagerService.ListServers:output_type -> server.ListServersResponse
+	5,  // 92: server.ServerManagerService.UpdateServer:output_type -> server.Server
+	12, // 93: server.ServerManagerService.DeleteServer:output_type -> server.DeleteServerResponse
+	14, // 94: server.ServerManagerService.StartServer:output_type -> server.StartServerResponse
+	16, // 95: server.ServerManagerService.StopServer:output_type -> server.StopServerResponse
+	18, // 96: server.ServerManagerService.RestartServer:output_type -> server.RestartServerResponse
+	19, // 97: server.ServerManagerService.GetServerStats:output_type -> server.ServerStats
+	21, // 98: server.ServerManagerService.GetServerHealth:output_type -> server.ServerHealth
+	25, // 99: server.ServerManagerService.MonitorServer:output_type -> server.ServerMonitorEvent
+	27, // 100: server.ServerManagerService.GetServersByType:output_type -> server.GetServersByTypeResponse
+	29, // 101: server.ServerManagerService.GetServersByRegion:output_type -> server.GetServersByRegionResponse
+	31, // 102: server.ServerManagerService.GetServersByStatus:output_type -> server.GetServersByStatusResponse
+	34, // 103: server.ServerManagerService.ScaleServer:output_type -> server.ScaleServerResponse
+	37, // 104: server.ServerManagerService.EvaluateScaling:output_type -> server.EvaluateScalingResponse
+	39, // 105: server.ServerManagerService.CreateBackup:output_type -> server.CreateBackupResponse
+	41, // 106: server.ServerManagerService.RestoreBackup:output_type -> server.RestoreBackupResponse
+	43, // 107: server.ServerManagerService.UpdateServerSoftware:output_type -> server.UpdateServerSoftwareResponse
+	47, // 108: server.ServerManagerService.GetServerSoftwareUpdate:output_type -> server.UpdateStatus
+	46, // 109: server.ServerManagerService.CancelServerSoftwareUpdate:output_type -> server.CancelServerSoftwareUpdateResponse
+	50, // 110: server.ServerManagerService.ListRegions:output_type -> server.ListRegionsResponse
+	48, // 111: server.ServerManagerService.SaveRegion:output_type -> server.Region
+	52, // 112: server.ServerManagerService.DeleteRegion:output_type -> server.DeleteRegionResponse
+	55, // 113: server.ServerManagerService.RecommendServer:output_type -> server.RecommendServerResponse
+	56, // 114: server.ServerManagerService.RegisterNode:output_type -> server.Node
+	58, // 115: server.ServerManagerService.ListNodes:output_type -> server.ListNodesResponse
+	60, // 116: server.ServerManagerService.RemoveNode:output_type -> server.RemoveNodeResponse
+	56, // 117: server.ServerManagerService.CordonNode:output_type -> server.Node
+	56, // 118: server.ServerManagerService.UncordonNode:output_type -> server.Node
+	56, // 119: server.ServerManagerService.DrainNode:output_type -> server.Node
+	65, // 120: server.ServerManagerService.GetDriftReport:output_type -> server.DriftReport
+	65, // 121: server.ServerManagerService.ReconcileServers:output_type -> server.DriftReport
+	5,  // 122: server.ServerManagerService.ProvisionServer:output_type -> server.Server
+	70, // 123: server.ServerManagerService.GetServerProvision:output_type -> server.Provision
+	88, // [88:124] is the sub-list for method output_type
+	52, // [52:88] is the sub-list for method input_type
+	52, // [52:52] is the sub-list for extension type_name
+	52, // [52:52] is the sub-list for extension extendee
+	0,  // [0:52] is the sub-list for field type_name
 }
 
 func init() { file_server_proto_init() }
@@ -2809,7 +5070,7 @@ func file_server_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_server_proto_rawDesc), len(file_server_proto_rawDesc)),
 			NumEnums:      3,
-			NumMessages:   42,
+			NumMessages:   74,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,260 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// BackupRepository репозиторий конфигураций и резервных копий
+type BackupRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewBackupRepository создает репозиторий резервного копирования
+func NewBackupRepository(db *sql.DB, logger *zap.Logger) *BackupRepository {
+	return &BackupRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const backupConfigColumns = `id, server_id, schedule, retention, type, destination, enabled, last_backup, next_backup`
+
+// SaveConfig сохраняет конфигурацию резервного копирования
+func (r *BackupRepository) SaveConfig(ctx context.Context, config *domain.BackupConfig) error {
+	query := `
+		INSERT INTO backup_configs (` + backupConfigColumns + `, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		config.ID, config.ServerID, config.Schedule, config.Retention, config.Type, config.Destination,
+		config.Enabled, config.LastBackup, config.NextBackup, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save backup config: %w", err)
+	}
+
+	r.logger.Info("backup config saved", zap.String("id", config.ID), zap.String("server_id", config.ServerID))
+	return nil
+}
+
+// GetConfig получает конфигурацию резервного копирования по ID
+func (r *BackupRepository) GetConfig(ctx context.Context, id string) (*domain.BackupConfig, error) {
+	query := `SELECT ` + backupConfigColumns + ` FROM backup_configs WHERE id = $1`
+
+	config, err := scanBackupConfig(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("backup config not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get backup config: %w", err)
+	}
+
+	return config, nil
+}
+
+// ListConfigs получает все конфигурации резервного копирования
+func (r *BackupRepository) ListConfigs(ctx context.Context) ([]*domain.BackupConfig, error) {
+	query := `SELECT ` + backupConfigColumns + ` FROM backup_configs ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup configs: %w", err)
+	}
+	defer rows.Close()
+
+	configs := []*domain.BackupConfig{}
+	for rows.Next() {
+		config, err := scanBackupConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan backup config: %w", err)
+		}
+		configs = append(configs, config)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list backup configs: %w", err)
+	}
+
+	return configs, nil
+}
+
+// UpdateConfig обновляет конфигурацию резервного копирования
+func (r *BackupRepository) UpdateConfig(ctx context.Context, config *domain.BackupConfig) error {
+	query := `
+		UPDATE backup_configs
+		SET server_id = $2, schedule = $3, retention = $4, type = $5, destination = $6, enabled = $7,
+		    last_backup = $8, next_backup = $9, updated_at = $10
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		config.ID, config.ServerID, config.Schedule, config.Retention, config.Type, config.Destination,
+		config.Enabled, config.LastBackup, config.NextBackup, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update backup config: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("backup config not found: %s", config.ID)
+	}
+
+	return nil
+}
+
+// DeleteConfig удаляет конфигурацию резервного копирования. Созданные по
+// ней копии остаются
+func (r *BackupRepository) DeleteConfig(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM backup_configs WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete backup config: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("backup config not found: %s", id)
+	}
+
+	r.logger.Info("backup config deleted", zap.String("id", id))
+	return nil
+}
+
+const backupColumns = `id, server_id, server_type, region, name, description, type, parent_id,
+		destination, key, size, checksum, created_at`
+
+// SaveBackup сохраняет запись о резервной копии
+func (r *BackupRepository) SaveBackup(ctx context.Context, backup *domain.Backup) error {
+	query := `
+		INSERT INTO backups (` + backupColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	var parentID sql.NullString
+	if backup.ParentID != "" {
+		parentID = sql.NullString{String: backup.ParentID, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		backup.ID, backup.ServerID, backup.ServerType, backup.Region, backup.Name, backup.Description,
+		backup.Type, parentID, backup.Destination, backup.Key, backup.Size, backup.Checksum, backup.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	r.logger.Info("backup saved", zap.String("id", backup.ID), zap.String("server_id", backup.ServerID))
+	return nil
+}
+
+// GetBackup получает резервную копию по ID
+func (r *BackupRepository) GetBackup(ctx context.Context, id string) (*domain.Backup, error) {
+	query := `SELECT ` + backupColumns + ` FROM backups WHERE id = $1`
+
+	backup, err := scanBackup(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("backup not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+
+	return backup, nil
+}
+
+// ListBackups получает резервные копии сервера от старых к новым
+func (r *BackupRepository) ListBackups(ctx context.Context, serverID string) ([]*domain.Backup, error) {
+	query := `SELECT ` + backupColumns + ` FROM backups WHERE server_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	defer rows.Close()
+
+	backups := []*domain.Backup{}
+	for rows.Next() {
+		backup, err := scanBackup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan backup: %w", err)
+		}
+		backups = append(backups, backup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	return backups, nil
+}
+
+// DeleteBackup удаляет запись о резервной копии
+func (r *BackupRepository) DeleteBackup(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM backups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("backup not found: %s", id)
+	}
+
+	r.logger.Info("backup deleted", zap.String("id", id))
+	return nil
+}
+
+// scanBackupConfig читает конфигурацию резервного копирования
+func scanBackupConfig(row rowScanner) (*domain.BackupConfig, error) {
+	config := &domain.BackupConfig{}
+	var lastBackup, nextBackup sql.NullTime
+
+	err := row.Scan(
+		&config.ID, &config.ServerID, &config.Schedule, &config.Retention, &config.Type, &config.Destination,
+		&config.Enabled, &lastBackup, &nextBackup,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastBackup.Valid {
+		config.LastBackup = &lastBackup.Time
+	}
+	if nextBackup.Valid {
+		config.NextBackup = &nextBackup.Time
+	}
+	return config, nil
+}
+
+// scanBackup читает резервную копию
+func scanBackup(row rowScanner) (*domain.Backup, error) {
+	backup := &domain.Backup{}
+	var parentID sql.NullString
+
+	err := row.Scan(
+		&backup.ID, &backup.ServerID, &backup.ServerType, &backup.Region, &backup.Name, &backup.Description,
+		&backup.Type, &parentID, &backup.Destination, &backup.Key, &backup.Size, &backup.Checksum,
+		&backup.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	backup.ParentID = parentID.String
+	return backup, nil
+}
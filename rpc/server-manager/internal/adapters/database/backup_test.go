@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var backupRows = []string{"id", "server_id", "server_type", "region", "name", "description", "type", "parent_id",
+	"destination", "key", "size", "checksum", "created_at"}
+
+func TestBackupRepository_SaveBackup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBackupRepository(db, zap.NewNop())
+	createdAt := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	backup := &domain.Backup{
+		ID:          "backup-1",
+		ServerID:    "server-1",
+		ServerType:  domain.ServerTypeVPN,
+		Region:      "eu-west-1",
+		Name:        "vpn-eu-1-20261019-030000",
+		Type:        domain.BackupTypeFull,
+		Destination: "file:///var/backups",
+		Key:         "server-1/backup-1.tar",
+		Size:        10240,
+		Checksum:    "abc",
+		CreatedAt:   createdAt,
+	}
+
+	// Полная копия без родителя пишет NULL в parent_id
+	mock.ExpectExec("INSERT INTO backups").
+		WithArgs("backup-1", "server-1", domain.ServerTypeVPN, "eu-west-1", backup.Name, "",
+			domain.BackupTypeFull, sql.NullString{}, backup.Destination, backup.Key, int64(10240), "abc", createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.SaveBackup(context.Background(), backup))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackupRepository_ListBackups(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBackupRepository(db, zap.NewNop())
+	now := time.Now()
+
+	rows := sqlmock.NewRows(backupRows).
+		AddRow("backup-1", "server-1", "vpn", "eu-west-1", "full", "", "full", nil,
+			"file:///var/backups", "server-1/backup-1.tar", 100, "abc", now.Add(-time.Hour)).
+		AddRow("backup-2", "server-1", "vpn", "eu-west-1", "incr", "", "incremental", "backup-1",
+			"file:///var/backups", "server-1/backup-2.tar", 10, "def", now)
+	mock.ExpectQuery(`SELECT .+ FROM backups WHERE server_id = \$1 ORDER BY created_at`).
+		WithArgs("server-1").
+		WillReturnRows(rows)
+
+	backups, err := repo.ListBackups(context.Background(), "server-1")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Empty(t, backups[0].ParentID)
+	assert.Equal(t, domain.BackupTypeIncremental, backups[1].Type)
+	assert.Equal(t, "backup-1", backups[1].ParentID)
+	assert.Equal(t, int64(10), backups[1].Size)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackupRepository_GetConfig(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBackupRepository(db, zap.NewNop())
+	next := time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "server_id", "schedule", "retention", "type", "destination",
+		"enabled", "last_backup", "next_backup"}).
+		AddRow("config-1", "server-1", "0 3 * * *", 7, "incremental", "s3://backups", true, nil, next)
+	mock.ExpectQuery(`SELECT .+ FROM backup_configs WHERE id = \$1`).
+		WithArgs("config-1").
+		WillReturnRows(rows)
+
+	config, err := repo.GetConfig(context.Background(), "config-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.BackupTypeIncremental, config.Type)
+	assert.Equal(t, 7, config.Retention)
+	assert.Nil(t, config.LastBackup)
+	require.NotNil(t, config.NextBackup)
+	assert.True(t, next.Equal(*config.NextBackup))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackupRepository_DeleteBackupNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBackupRepository(db, zap.NewNop())
+
+	mock.ExpectExec("DELETE FROM backups").WithArgs("missing").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.Error(t, repo.DeleteBackup(context.Background(), "missing"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
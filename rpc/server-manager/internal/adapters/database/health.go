@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// HealthRepository репозиторий истории здоровья серверов
+type HealthRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewHealthRepository создает репозиторий здоровья серверов
+func NewHealthRepository(db *sql.DB, logger *zap.Logger) *HealthRepository {
+	return &HealthRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const healthColumns = `server_id, status, message, checks, checked_at`
+
+// SaveHealth сохраняет результат проверки здоровья
+func (r *HealthRepository) SaveHealth(ctx context.Context, health *domain.ServerHealth) error {
+	checks := health.Checks
+	if checks == nil {
+		checks = []map[string]interface{}{}
+	}
+	data, err := json.Marshal(checks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal health checks: %w", err)
+	}
+
+	query := `INSERT INTO server_health (` + healthColumns + `) VALUES ($1, $2, $3, $4, $5)`
+	_, err = r.db.ExecContext(ctx, query,
+		health.ServerID, health.Status, health.Message, string(data), health.LastCheckAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save health: %w", err)
+	}
+
+	return nil
+}
+
+// GetHealth получает последнюю проверку здоровья сервера
+func (r *HealthRepository) GetHealth(ctx context.Context, serverID string) (*domain.ServerHealth, error) {
+	query := `
+		SELECT ` + healthColumns + ` FROM server_health
+		WHERE server_id = $1 ORDER BY checked_at DESC LIMIT 1
+	`
+
+	health, err := scanHealth(r.db.QueryRowContext(ctx, query, serverID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("health not found: %s", serverID)
+		}
+		return nil, fmt.Errorf("failed to get health: %w", err)
+	}
+
+	return health, nil
+}
+
+// GetAllHealth получает последнюю проверку здоровья каждого сервера
+func (r *HealthRepository) GetAllHealth(ctx context.Context) ([]*domain.ServerHealth, error) {
+	query := `
+		SELECT DISTINCT ON (server_id) ` + healthColumns + ` FROM server_health
+		ORDER BY server_id, checked_at DESC
+	`
+
+	return r.queryHealth(ctx, query)
+}
+
+// GetHealthHistory получает последние limit проверок сервера, от новых к
+// старым
+func (r *HealthRepository) GetHealthHistory(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealth, error) {
+	query := `
+		SELECT ` + healthColumns + ` FROM server_health
+		WHERE server_id = $1 ORDER BY checked_at DESC LIMIT $2
+	`
+
+	return r.queryHealth(ctx, query, serverID, limit)
+}
+
+// DeleteHealthBefore удаляет проверки старше before
+func (r *HealthRepository) DeleteHealthBefore(ctx context.Context, before time.Time) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM server_health WHERE checked_at < $1`, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete health: %w", err)
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
+		r.logger.Info("health history deleted", zap.Time("before", before), zap.Int64("rows", rowsAffected))
+	}
+	return nil
+}
+
+// queryHealth выполняет запрос списка проверок
+func (r *HealthRepository) queryHealth(ctx context.Context, query string, args ...interface{}) ([]*domain.ServerHealth, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health: %w", err)
+	}
+	defer rows.Close()
+
+	result := []*domain.ServerHealth{}
+	for rows.Next() {
+		health, err := scanHealth(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan health: %w", err)
+		}
+		result = append(result, health)
+	}
+
+	return result, rows.Err()
+}
+
+// scanHealth читает строку проверки здоровья
+func scanHealth(row rowScanner) (*domain.ServerHealth, error) {
+	health := &domain.ServerHealth{}
+	var checks []byte
+	if err := row.Scan(&health.ServerID, &health.Status, &health.Message, &checks, &health.LastCheckAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(checks, &health.Checks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal health checks: %w", err)
+	}
+	return health, nil
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var healthRows = []string{"server_id", "status", "message", "checks", "checked_at"}
+
+func TestHealthRepository_SaveHealth(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHealthRepository(db, zap.NewNop())
+	now := time.Now()
+
+	// Проверки сохраняются как JSON, отсутствие проверок - пустой массив
+	mock.ExpectExec("INSERT INTO server_health").
+		WithArgs("server-1", domain.ServerStatusError, "container is unhealthy",
+			`[{"message":"timeout","name":"healthcheck","status":"unhealthy"}]`, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO server_health").
+		WithArgs("server-1", domain.ServerStatusRunning, "running", "[]", now).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	assert.NoError(t, repo.SaveHealth(context.Background(), &domain.ServerHealth{
+		ServerID:    "server-1",
+		Status:      domain.ServerStatusError,
+		Message:     "container is unhealthy",
+		LastCheckAt: now,
+		Checks: []map[string]interface{}{
+			{"name": "healthcheck", "status": "unhealthy", "message": "timeout"},
+		},
+	}))
+	assert.NoError(t, repo.SaveHealth(context.Background(), &domain.ServerHealth{
+		ServerID:    "server-1",
+		Status:      domain.ServerStatusRunning,
+		Message:     "running",
+		LastCheckAt: now,
+	}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHealthRepository_GetAllHealth(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHealthRepository(db, zap.NewNop())
+	now := time.Now()
+
+	rows := sqlmock.NewRows(healthRows).
+		AddRow("server-1", "running", "running", []byte(`[]`), now).
+		AddRow("server-2", "error", "container is unhealthy", []byte(`[{"name":"healthcheck","status":"unhealthy"}]`), now)
+	mock.ExpectQuery(`SELECT DISTINCT ON \(server_id\) .+ FROM server_health`).
+		WillReturnRows(rows)
+
+	health, err := repo.GetAllHealth(context.Background())
+	require.NoError(t, err)
+	require.Len(t, health, 2)
+	assert.Equal(t, domain.ServerStatusRunning, health[0].Status)
+	assert.Empty(t, health[0].Checks)
+	assert.Equal(t, "unhealthy", health[1].Checks[0]["status"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHealthRepository_GetHealthHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHealthRepository(db, zap.NewNop())
+	now := time.Now()
+
+	rows := sqlmock.NewRows(healthRows).
+		AddRow("server-1", "error", "container is unhealthy", []byte(`[]`), now).
+		AddRow("server-1", "running", "running", []byte(`[]`), now.Add(-time.Minute))
+	mock.ExpectQuery(`SELECT .+ FROM server_health WHERE server_id = \$1 ORDER BY checked_at DESC LIMIT \$2`).
+		WithArgs("server-1", 10).
+		WillReturnRows(rows)
+
+	history, err := repo.GetHealthHistory(context.Background(), "server-1", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, domain.ServerStatusError, history[0].Status)
+	assert.Equal(t, now.Add(-time.Minute), history[1].LastCheckAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHealthRepository_DeleteHealthBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHealthRepository(db, zap.NewNop())
+	before := time.Now().Add(-30 * 24 * time.Hour)
+
+	mock.ExpectExec(`DELETE FROM server_health WHERE checked_at < \$1`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	assert.NoError(t, repo.DeleteHealthBefore(context.Background(), before))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,4 @@
+-- Идентификатор ресурса сервера в оркестраторе (ID контейнера или имя Deployment)
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS orchestrator_handle VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_servers_orchestrator_handle ON servers(orchestrator_handle);
@@ -0,0 +1,11 @@
+-- Группа серверов политики масштабирования: тип и регион (пусто - все регионы)
+ALTER TABLE scaling_policies ADD COLUMN IF NOT EXISTS server_type VARCHAR(50) NOT NULL DEFAULT 'vpn';
+ALTER TABLE scaling_policies ADD COLUMN IF NOT EXISTS region VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Решения масштабирования с группой и нагрузкой, на основе которой они приняты
+ALTER TABLE scaling_history ADD COLUMN IF NOT EXISTS server_type VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE scaling_history ADD COLUMN IF NOT EXISTS region VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE scaling_history ADD COLUMN IF NOT EXISTS cpu_usage DOUBLE PRECISION NOT NULL DEFAULT 0.0;
+ALTER TABLE scaling_history ADD COLUMN IF NOT EXISTS memory_usage DOUBLE PRECISION NOT NULL DEFAULT 0.0;
+
+CREATE INDEX IF NOT EXISTS idx_scaling_history_policy_region ON scaling_history(policy_id, region, triggered_at);
@@ -0,0 +1,36 @@
+-- Создание таблицы конфигураций резервного копирования
+CREATE TABLE IF NOT EXISTS backup_configs (
+    id VARCHAR(36) PRIMARY KEY,
+    server_id VARCHAR(36) NOT NULL,
+    schedule VARCHAR(100) NOT NULL, -- cron выражение
+    retention INTEGER NOT NULL DEFAULT 0, -- дни, 0 - хранить всегда
+    type VARCHAR(20) NOT NULL DEFAULT 'full', -- 'full', 'incremental'
+    destination TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_backup TIMESTAMP WITH TIME ZONE,
+    next_backup TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_backup_configs_server_id ON backup_configs(server_id);
+
+-- Создание таблицы резервных копий. Копии переживают удаление сервера,
+-- поэтому внешнего ключа на servers нет
+CREATE TABLE IF NOT EXISTS backups (
+    id VARCHAR(36) PRIMARY KEY,
+    server_id VARCHAR(36) NOT NULL,
+    server_type VARCHAR(50) NOT NULL,
+    region VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    type VARCHAR(20) NOT NULL, -- 'full', 'incremental'
+    parent_id VARCHAR(36) REFERENCES backups(id),
+    destination TEXT NOT NULL,
+    key TEXT NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64) NOT NULL, -- sha256
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_backups_server_id ON backups(server_id, created_at);
@@ -0,0 +1,2 @@
+-- Образ ПО сервера, меняется при обновлении
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS image VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Создание таблицы статусов обновления ПО. Хранится последнее обновление
+-- каждого сервера
+CREATE TABLE IF NOT EXISTS server_updates (
+    server_id VARCHAR(36) PRIMARY KEY REFERENCES servers(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL, -- 'pending', 'in_progress', 'completed', 'failed', 'rolled_back', 'cancelled'
+    progress INTEGER NOT NULL DEFAULT 0,
+    message TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,9 @@
+-- Счетчики трафика и время работы из оркестратора. resolution - длина
+-- интервала в секундах, за который усреднена строка (0 - исходный замер),
+-- samples - число исходных замеров в строке
+ALTER TABLE server_stats DROP COLUMN IF EXISTS network;
+ALTER TABLE server_stats ADD COLUMN IF NOT EXISTS network_in BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE server_stats ADD COLUMN IF NOT EXISTS network_out BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE server_stats ADD COLUMN IF NOT EXISTS uptime BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE server_stats ADD COLUMN IF NOT EXISTS resolution INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE server_stats ADD COLUMN IF NOT EXISTS samples INTEGER NOT NULL DEFAULT 1;
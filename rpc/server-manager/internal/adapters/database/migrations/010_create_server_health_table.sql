@@ -0,0 +1,14 @@
+-- Создание таблицы истории здоровья серверов
+CREATE TABLE IF NOT EXISTS server_health (
+    id SERIAL PRIMARY KEY,
+    server_id VARCHAR(36) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    checks JSONB NOT NULL DEFAULT '[]',
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (server_id) REFERENCES servers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_server_health_server_checked ON server_health(server_id, checked_at);
+CREATE INDEX IF NOT EXISTS idx_server_health_checked ON server_health(checked_at);
@@ -0,0 +1,15 @@
+-- Создание таблицы регионов размещения серверов
+CREATE TABLE IF NOT EXISTS regions (
+    code VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION NOT NULL DEFAULT 0.0,
+    longitude DOUBLE PRECISION NOT NULL DEFAULT 0.0,
+    max_servers INTEGER NOT NULL DEFAULT 0, -- 0 - без ограничения
+    max_load DOUBLE PRECISION NOT NULL DEFAULT 0.0, -- загрузка CPU, %; 0 - по умолчанию
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_regions_enabled ON regions(enabled);
@@ -0,0 +1,24 @@
+-- Создание таблицы узлов Docker Engine
+CREATE TABLE IF NOT EXISTS nodes (
+    id VARCHAR(100) PRIMARY KEY,
+    endpoint VARCHAR(255) NOT NULL,
+    tls_ca_cert VARCHAR(255) NOT NULL DEFAULT '', -- пути к сертификатам на хосте server-manager
+    tls_cert VARCHAR(255) NOT NULL DEFAULT '',
+    tls_key VARCHAR(255) NOT NULL DEFAULT '',
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    labels JSONB NOT NULL DEFAULT '{}',
+    capacity INTEGER NOT NULL DEFAULT 0, -- 0 - без ограничения
+    state VARCHAR(20) NOT NULL DEFAULT 'active', -- 'active', 'cordoned', 'draining'
+    healthy BOOLEAN NOT NULL DEFAULT false,
+    message TEXT NOT NULL DEFAULT '',
+    last_check_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_nodes_region ON nodes(region);
+
+-- Узел, на котором работает сервер
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS node_id VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_servers_node_id ON servers(node_id);
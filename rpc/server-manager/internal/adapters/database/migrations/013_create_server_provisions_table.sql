@@ -0,0 +1,13 @@
+-- Создание таблицы подготовки хостов серверов по SSH. Хранится последняя
+-- подготовка каждого сервера
+CREATE TABLE IF NOT EXISTS server_provisions (
+    server_id VARCHAR(36) PRIMARY KEY REFERENCES servers(id) ON DELETE CASCADE,
+    host VARCHAR(255) NOT NULL,
+    mode VARCHAR(20) NOT NULL, -- 'binary', 'container'
+    status VARCHAR(20) NOT NULL, -- 'in_progress', 'completed', 'failed'
+    step VARCHAR(20) NOT NULL, -- 'connect', 'prerequisites', 'install', 'configure', 'start', 'register'
+    progress INTEGER NOT NULL DEFAULT 0,
+    message TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
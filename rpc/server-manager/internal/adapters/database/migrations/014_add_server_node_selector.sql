@@ -0,0 +1,2 @@
+-- Метки узла, на которых должен работать сервер; учитываются и при переносе
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS node_selector JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- Вид узла: docker - узел Docker Engine, ssh - хост, подготовленный по SSH
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS kind VARCHAR(16) NOT NULL DEFAULT 'docker';
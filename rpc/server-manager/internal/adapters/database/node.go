@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// NodeRepository репозиторий реестра узлов
+type NodeRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewNodeRepository создает репозиторий узлов
+func NewNodeRepository(db *sql.DB, logger *zap.Logger) *NodeRepository {
+	return &NodeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const nodeColumns = `id, endpoint, tls_ca_cert, tls_cert, tls_key, region, labels, capacity, state,
+		healthy, message, last_check_at, created_at, updated_at, kind`
+
+// SaveNode создает узел или обновляет существующий с тем же ID
+func (r *NodeRepository) SaveNode(ctx context.Context, node *domain.Node) error {
+	labels := node.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node labels: %w", err)
+	}
+
+	query := `
+		INSERT INTO nodes (` + nodeColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13, $14)
+		ON CONFLICT (id) DO UPDATE
+		SET endpoint = EXCLUDED.endpoint, tls_ca_cert = EXCLUDED.tls_ca_cert, tls_cert = EXCLUDED.tls_cert,
+		    tls_key = EXCLUDED.tls_key, region = EXCLUDED.region, labels = EXCLUDED.labels,
+		    capacity = EXCLUDED.capacity, state = EXCLUDED.state, healthy = EXCLUDED.healthy,
+		    message = EXCLUDED.message, last_check_at = EXCLUDED.last_check_at, updated_at = EXCLUDED.updated_at,
+		    kind = EXCLUDED.kind
+		RETURNING created_at, updated_at
+	`
+
+	kind := node.Kind
+	if kind == "" {
+		kind = domain.NodeKindDocker
+	}
+
+	err = r.db.QueryRowContext(ctx, query,
+		node.ID, node.Endpoint, node.TLSCACert, node.TLSCert, node.TLSKey, node.Region, string(data),
+		node.Capacity, node.State, node.Healthy, node.Message, node.LastCheckAt, time.Now(), kind,
+	).Scan(&node.CreatedAt, &node.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save node: %w", err)
+	}
+
+	r.logger.Info("node saved", zap.String("id", node.ID), zap.String("endpoint", node.Endpoint))
+	return nil
+}
+
+// GetNode получает узел по ID
+func (r *NodeRepository) GetNode(ctx context.Context, id string) (*domain.Node, error) {
+	query := `SELECT ` + nodeColumns + ` FROM nodes WHERE id = $1`
+
+	node, err := scanNode(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("node not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	return node, nil
+}
+
+// ListNodes получает все узлы
+func (r *NodeRepository) ListNodes(ctx context.Context) ([]*domain.Node, error) {
+	query := `SELECT ` + nodeColumns + ` FROM nodes ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := []*domain.Node{}
+	for rows.Next() {
+		node, err := scanNode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	return nodes, nil
+}
+
+// DeleteNode удаляет узел
+func (r *NodeRepository) DeleteNode(ctx context.Context, id string) error {
+	return r.exec(ctx, "delete node", `DELETE FROM nodes WHERE id = $1`, id)
+}
+
+// UpdateNodeState меняет состояние узла
+func (r *NodeRepository) UpdateNodeState(ctx context.Context, id string, state domain.NodeState) error {
+	query := `UPDATE nodes SET state = $2, updated_at = $3 WHERE id = $1`
+	return r.exec(ctx, "update node state", query, id, state, time.Now())
+}
+
+// UpdateNodeHealth записывает результат проверки узла
+func (r *NodeRepository) UpdateNodeHealth(ctx context.Context, id string, healthy bool, message string, checkedAt time.Time) error {
+	query := `UPDATE nodes SET healthy = $2, message = $3, last_check_at = $4 WHERE id = $1`
+	return r.exec(ctx, "update node health", query, id, healthy, message, checkedAt)
+}
+
+// exec выполняет изменение одного узла
+func (r *NodeRepository) exec(ctx context.Context, action, query string, id string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("node not found: %s", id)
+	}
+
+	return nil
+}
+
+// scanNode читает строку узла
+func scanNode(row rowScanner) (*domain.Node, error) {
+	node := &domain.Node{}
+	var labels []byte
+	var lastCheckAt sql.NullTime
+	err := row.Scan(
+		&node.ID, &node.Endpoint, &node.TLSCACert, &node.TLSCert, &node.TLSKey, &node.Region, &labels,
+		&node.Capacity, &node.State, &node.Healthy, &node.Message, &lastCheckAt,
+		&node.CreatedAt, &node.UpdatedAt, &node.Kind,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(labels, &node.Labels); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal node labels: %w", err)
+	}
+	if lastCheckAt.Valid {
+		node.LastCheckAt = &lastCheckAt.Time
+	}
+	return node, nil
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var nodeRows = []string{"id", "endpoint", "tls_ca_cert", "tls_cert", "tls_key", "region", "labels", "capacity",
+	"state", "healthy", "message", "last_check_at", "created_at", "updated_at", "kind"}
+
+func TestNodeRepository_SaveNode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNodeRepository(db, zap.NewNop())
+	checkedAt := time.Now()
+	node := &domain.Node{
+		ID:          "eu-node-1",
+		Endpoint:    "tcp://10.0.0.5:2376",
+		TLSCACert:   "/etc/server-manager/nodes/eu-node-1/ca.pem",
+		TLSCert:     "/etc/server-manager/nodes/eu-node-1/cert.pem",
+		TLSKey:      "/etc/server-manager/nodes/eu-node-1/key.pem",
+		Region:      "eu-west-1",
+		Labels:      map[string]string{"disk": "ssd"},
+		Capacity:    20,
+		State:       domain.NodeStateActive,
+		Healthy:     true,
+		LastCheckAt: &checkedAt,
+	}
+
+	mock.ExpectQuery("INSERT INTO nodes .+ ON CONFLICT \\(id\\) DO UPDATE").
+		WithArgs(node.ID, node.Endpoint, node.TLSCACert, node.TLSCert, node.TLSKey, node.Region, `{"disk":"ssd"}`,
+			20, domain.NodeStateActive, true, "", &checkedAt, sqlmock.AnyArg(), domain.NodeKindDocker).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+	require.NoError(t, repo.SaveNode(context.Background(), node))
+	assert.False(t, node.CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNodeRepository_ListNodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNodeRepository(db, zap.NewNop())
+
+	now := time.Now()
+	rows := sqlmock.NewRows(nodeRows).
+		AddRow("eu-node-1", "tcp://10.0.0.5:2376", "", "", "", "eu-west-1", []byte(`{"disk":"ssd"}`), 20,
+			"active", true, "", now, now, now, "docker").
+		AddRow("203.0.113.10", "ssh://root@203.0.113.10:22", "", "", "", "eu-west-1", []byte(`{}`), 0,
+			"cordoned", false, "connection refused", nil, now, now, "ssh")
+	mock.ExpectQuery(`SELECT .+ FROM nodes ORDER BY id`).WillReturnRows(rows)
+
+	nodes, err := repo.ListNodes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, map[string]string{"disk": "ssd"}, nodes[0].Labels)
+	assert.True(t, nodes[0].Schedulable())
+	assert.True(t, nodes[0].Docker())
+	assert.False(t, nodes[1].Docker())
+	require.NotNil(t, nodes[0].LastCheckAt)
+	assert.Equal(t, domain.NodeStateCordoned, nodes[1].State)
+	assert.False(t, nodes[1].Schedulable())
+	assert.Nil(t, nodes[1].LastCheckAt)
+	assert.Equal(t, "connection refused", nodes[1].Message)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNodeRepository_UpdateNode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNodeRepository(db, zap.NewNop())
+	checkedAt := time.Now()
+
+	mock.ExpectExec(`UPDATE nodes SET state = \$2, updated_at = \$3 WHERE id = \$1`).
+		WithArgs("eu-node-1", domain.NodeStateDraining, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE nodes SET healthy = \$2, message = \$3, last_check_at = \$4 WHERE id = \$1`).
+		WithArgs("eu-node-1", false, "connection refused", checkedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM nodes WHERE id = \$1`).
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	assert.NoError(t, repo.UpdateNodeState(ctx, "eu-node-1", domain.NodeStateDraining))
+	assert.NoError(t, repo.UpdateNodeHealth(ctx, "eu-node-1", false, "connection refused", checkedAt))
+	assert.EqualError(t, repo.DeleteNode(ctx, "missing"), "node not found: missing")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -28,24 +29,46 @@ func NewPostgresRepository(db *sql.DB, logger *zap.Logger) *PostgresRepository {
 // Create создает новый сервер
 func (r *PostgresRepository) Create(ctx context.Context, server *domain.Server) error {
 	query := `
-		INSERT INTO servers (id, name, type, status, region, ip, port, cpu, memory, disk, network, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO servers (id, name, type, status, region, ip, port, cpu, memory, disk, network, orchestrator_handle, image, node_id, node_selector, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (id) DO NOTHING
 	`
 
-	server.ID = uuid.New().String()
+	// ID задан у серверов, найденных в оркестраторе
+	if server.ID == "" {
+		server.ID = uuid.New().String()
+	}
 	server.CreatedAt = time.Now()
 	server.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(ctx, query,
+	selector := server.NodeSelector
+	if selector == nil {
+		selector = map[string]string{}
+	}
+	data, err := json.Marshal(selector)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node selector: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query,
 		server.ID, server.Name, server.Type, server.Status, server.Region,
 		server.IP, server.Port, server.CPU, server.Memory, server.Disk, server.Network,
-		server.CreatedAt, server.UpdatedAt,
+		server.OrchestratorHandle, server.Image, server.NodeID, data, server.CreatedAt, server.UpdatedAt,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create server: %w", err)
 	}
 
+	// Строка удаленного сервера остается в таблице и занимает его ID
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrServerExists, server.ID)
+	}
+
 	r.logger.Info("server created", zap.String("id", server.ID), zap.String("name", server.Name))
 	return nil
 }
@@ -53,7 +76,7 @@ func (r *PostgresRepository) Create(ctx context.Context, server *domain.Server)
 // GetByID получает сервер по ID
 func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Server, error) {
 	query := `
-		SELECT id, name, type, status, region, ip, port, cpu, memory, disk, network, created_at, updated_at, deleted_at
+		SELECT id, name, type, status, region, ip, port, cpu, memory, disk, network, orchestrator_handle, image, node_id, node_selector, created_at, updated_at, deleted_at
 		FROM servers WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -64,16 +87,18 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Se
 	var memory sql.NullFloat64
 	var disk sql.NullFloat64
 	var network sql.NullFloat64
+	var handle sql.NullString
+	var selector []byte
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&server.ID, &server.Name, &server.Type, &server.Status, &server.Region,
-		&ip, &port, &cpu, &memory, &disk, &network,
+		&ip, &port, &cpu, &memory, &disk, &network, &handle, &server.Image, &server.NodeID, &selector,
 		&server.CreatedAt, &server.UpdatedAt, &server.DeletedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("server not found: %s", id)
+			return nil, fmt.Errorf("%w: %s", domain.ErrServerNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get server: %w", err)
 	}
@@ -97,6 +122,12 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Se
 	if network.Valid {
 		server.Network = network.Float64
 	}
+	if handle.Valid {
+		server.OrchestratorHandle = handle.String
+	}
+	if err := json.Unmarshal(selector, &server.NodeSelector); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal node selector: %w", err)
+	}
 
 	return server, nil
 }
@@ -104,7 +135,7 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Se
 // List получает список серверов с фильтрами
 func (r *PostgresRepository) List(ctx context.Context, filters map[string]interface{}) ([]*domain.Server, error) {
 	query := `
-		SELECT id, name, type, status, region, ip, port, cpu, memory, disk, network, created_at, updated_at, deleted_at
+		SELECT id, name, type, status, region, ip, port, cpu, memory, disk, network, orchestrator_handle, image, node_id, node_selector, created_at, updated_at, deleted_at
 		FROM servers WHERE deleted_at IS NULL
 	`
 
@@ -127,6 +158,12 @@ func (r *PostgresRepository) List(ctx context.Context, filters map[string]interf
 	if status, ok := filters["status"].(domain.ServerStatus); ok {
 		query += fmt.Sprintf(" AND status = $%d", argIndex)
 		args = append(args, status)
+		argIndex++
+	}
+
+	if nodeID, ok := filters["node_id"].(string); ok {
+		query += fmt.Sprintf(" AND node_id = $%d", argIndex)
+		args = append(args, nodeID)
 	}
 
 	query += " ORDER BY created_at DESC"
@@ -146,10 +183,12 @@ func (r *PostgresRepository) List(ctx context.Context, filters map[string]interf
 		var memory sql.NullFloat64
 		var disk sql.NullFloat64
 		var network sql.NullFloat64
+		var handle sql.NullString
+		var selector []byte
 
 		err := rows.Scan(
 			&server.ID, &server.Name, &server.Type, &server.Status, &server.Region,
-			&ip, &port, &cpu, &memory, &disk, &network,
+			&ip, &port, &cpu, &memory, &disk, &network, &handle, &server.Image, &server.NodeID, &selector,
 			&server.CreatedAt, &server.UpdatedAt, &server.DeletedAt,
 		)
 		if err != nil {
@@ -175,6 +214,12 @@ func (r *PostgresRepository) List(ctx context.Context, filters map[string]interf
 		if network.Valid {
 			server.Network = network.Float64
 		}
+		if handle.Valid {
+			server.OrchestratorHandle = handle.String
+		}
+		if err := json.Unmarshal(selector, &server.NodeSelector); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal node selector: %w", err)
+		}
 
 		servers = append(servers, server)
 	}
@@ -187,7 +232,7 @@ func (r *PostgresRepository) Update(ctx context.Context, server *domain.Server)
 	query := `
 		UPDATE servers 
 		SET name = $2, type = $3, status = $4, region = $5, ip = $6, port = $7, 
-		    cpu = $8, memory = $9, disk = $10, network = $11, updated_at = $12
+		    cpu = $8, memory = $9, disk = $10, network = $11, orchestrator_handle = $12, image = $13, node_id = $14, updated_at = $15
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -196,7 +241,7 @@ func (r *PostgresRepository) Update(ctx context.Context, server *domain.Server)
 	result, err := r.db.ExecContext(ctx, query,
 		server.ID, server.Name, server.Type, server.Status, server.Region,
 		server.IP, server.Port, server.CPU, server.Memory, server.Disk, server.Network,
-		server.UpdatedAt,
+		server.OrchestratorHandle, server.Image, server.NodeID, server.UpdatedAt,
 	)
 
 	if err != nil {
@@ -209,7 +254,7 @@ func (r *PostgresRepository) Update(ctx context.Context, server *domain.Server)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("server not found: %s", server.ID)
+		return fmt.Errorf("%w: %s", domain.ErrServerNotFound, server.ID)
 	}
 
 	r.logger.Info("server updated", zap.String("id", server.ID))
@@ -231,7 +276,7 @@ func (r *PostgresRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("server not found: %s", id)
+		return fmt.Errorf("%w: %s", domain.ErrServerNotFound, id)
 	}
 
 	r.logger.Info("server deleted", zap.String("id", id))
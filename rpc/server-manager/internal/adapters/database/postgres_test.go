@@ -30,12 +30,14 @@ func TestPostgresRepository_Create(t *testing.T) {
 		Memory:  1024,
 		Disk:    20480,
 		Network: 100,
+		// Метки узла сохраняются, чтобы учитывать их при переносе
+		NodeSelector: map[string]string{"zone": "a"},
 	}
 
 	mock.ExpectExec("INSERT INTO servers").
 		WithArgs(sqlmock.AnyArg(), server.Name, server.Type, server.Status, server.Region,
 			server.IP, server.Port, server.CPU, server.Memory, server.Disk, server.Network,
-			sqlmock.AnyArg(), sqlmock.AnyArg()).
+			server.OrchestratorHandle, server.Image, server.NodeID, []byte(`{"zone":"a"}`), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err = repo.Create(context.Background(), server)
@@ -46,6 +48,41 @@ func TestPostgresRepository_Create(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestPostgresRepository_CreateWithID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	// Сервер, найденный в оркестраторе, сохраняет ID из метки ресурса
+	server := &domain.Server{
+		ID:                 "5f0c6b7e-2d1a-4c3b-9e8f-7a6b5c4d3e2f",
+		Name:               "silence-vpn-5f0c6b7e",
+		Type:               domain.ServerTypeVPN,
+		Status:             domain.ServerStatusRunning,
+		OrchestratorHandle: "c7",
+	}
+
+	mock.ExpectExec("INSERT INTO servers").
+		WithArgs(server.ID, server.Name, server.Type, server.Status, server.Region,
+			server.IP, server.Port, server.CPU, server.Memory, server.Disk, server.Network,
+			server.OrchestratorHandle, server.Image, server.NodeID, []byte("{}"), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.Create(context.Background(), server)
+	assert.NoError(t, err)
+	assert.Equal(t, "5f0c6b7e-2d1a-4c3b-9e8f-7a6b5c4d3e2f", server.ID)
+
+	// ID удаленного сервера
+	mock.ExpectExec("INSERT INTO servers .+ ON CONFLICT \\(id\\) DO NOTHING").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Create(context.Background(), server)
+	assert.ErrorIs(t, err, domain.ErrServerExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPostgresRepository_GetByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -55,24 +92,26 @@ func TestPostgresRepository_GetByID(t *testing.T) {
 
 	serverID := uuid.New().String()
 	expectedServer := &domain.Server{
-		ID:        serverID,
-		Name:      "test-server",
-		Type:      domain.ServerTypeVPN,
-		Status:    domain.ServerStatusRunning,
-		Region:    "us-east-1",
-		IP:        "127.0.0.1",
-		Port:      1194,
-		CPU:       0.5,
-		Memory:    1024,
-		Disk:      20480,
-		Network:   100,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:                 serverID,
+		Name:               "test-server",
+		Type:               domain.ServerTypeVPN,
+		Status:             domain.ServerStatusRunning,
+		Region:             "us-east-1",
+		IP:                 "127.0.0.1",
+		Port:               1194,
+		CPU:                0.5,
+		Memory:             1024,
+		Disk:               20480,
+		Network:            100,
+		OrchestratorHandle: "silence-vpn-container",
+		Image:              "silence/vpn-core:1.2.0",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "created_at", "updated_at", "deleted_at"}).
+	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "orchestrator_handle", "image", "node_id", "node_selector", "created_at", "updated_at", "deleted_at"}).
 		AddRow(expectedServer.ID, expectedServer.Name, expectedServer.Type, expectedServer.Status, expectedServer.Region,
-			expectedServer.IP, expectedServer.Port, expectedServer.CPU, expectedServer.Memory, expectedServer.Disk, expectedServer.Network,
+			expectedServer.IP, expectedServer.Port, expectedServer.CPU, expectedServer.Memory, expectedServer.Disk, expectedServer.Network, expectedServer.OrchestratorHandle, expectedServer.Image, expectedServer.NodeID, []byte(`{}`),
 			expectedServer.CreatedAt, expectedServer.UpdatedAt, nil)
 
 	mock.ExpectQuery(`SELECT .+ FROM servers WHERE id = \$1 AND deleted_at IS NULL`).
@@ -84,6 +123,16 @@ func TestPostgresRepository_GetByID(t *testing.T) {
 	assert.NotNil(t, server)
 	assert.Equal(t, expectedServer.ID, server.ID)
 	assert.Equal(t, expectedServer.Name, server.Name)
+	assert.Equal(t, expectedServer.OrchestratorHandle, server.OrchestratorHandle)
+	assert.Equal(t, expectedServer.Image, server.Image)
+
+	// Удаленный или неизвестный сервер
+	mock.ExpectQuery(`SELECT .+ FROM servers WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(serverID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.GetByID(context.Background(), serverID)
+	assert.ErrorIs(t, err, domain.ErrServerNotFound)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -125,12 +174,12 @@ func TestPostgresRepository_List(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "created_at", "updated_at", "deleted_at"}).
+	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "orchestrator_handle", "image", "node_id", "node_selector", "created_at", "updated_at", "deleted_at"}).
 		AddRow(server1.ID, server1.Name, server1.Type, server1.Status, server1.Region,
-			server1.IP, server1.Port, server1.CPU, server1.Memory, server1.Disk, server1.Network,
+			server1.IP, server1.Port, server1.CPU, server1.Memory, server1.Disk, server1.Network, server1.OrchestratorHandle, server1.Image, server1.NodeID, []byte(`{}`),
 			server1.CreatedAt, server1.UpdatedAt, nil).
 		AddRow(server2.ID, server2.Name, server2.Type, server2.Status, server2.Region,
-			server2.IP, server2.Port, server2.CPU, server2.Memory, server2.Disk, server2.Network,
+			server2.IP, server2.Port, server2.CPU, server2.Memory, server2.Disk, server2.Network, server2.OrchestratorHandle, server2.Image, server2.NodeID, []byte(`{}`),
 			server2.CreatedAt, server2.UpdatedAt, nil)
 
 	mock.ExpectQuery("SELECT .+ FROM servers WHERE deleted_at IS NULL ORDER BY created_at DESC").
@@ -171,7 +220,7 @@ func TestPostgresRepository_Update(t *testing.T) {
 	mock.ExpectExec("UPDATE servers").
 		WithArgs(updatedServer.ID, updatedServer.Name, updatedServer.Type, updatedServer.Status, updatedServer.Region,
 			updatedServer.IP, updatedServer.Port, updatedServer.CPU, updatedServer.Memory, updatedServer.Disk, updatedServer.Network,
-			sqlmock.AnyArg()).
+			updatedServer.OrchestratorHandle, updatedServer.Image, updatedServer.NodeID, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err = repo.Update(context.Background(), updatedServer)
@@ -221,9 +270,9 @@ func TestPostgresRepository_GetByType(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "created_at", "updated_at", "deleted_at"}).
+	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "orchestrator_handle", "image", "node_id", "node_selector", "created_at", "updated_at", "deleted_at"}).
 		AddRow(expectedServer.ID, expectedServer.Name, expectedServer.Type, expectedServer.Status, expectedServer.Region,
-			expectedServer.IP, expectedServer.Port, expectedServer.CPU, expectedServer.Memory, expectedServer.Disk, expectedServer.Network,
+			expectedServer.IP, expectedServer.Port, expectedServer.CPU, expectedServer.Memory, expectedServer.Disk, expectedServer.Network, expectedServer.OrchestratorHandle, expectedServer.Image, expectedServer.NodeID, []byte(`{}`),
 			expectedServer.CreatedAt, expectedServer.UpdatedAt, nil)
 
 	mock.ExpectQuery(`SELECT .+ FROM servers WHERE deleted_at IS NULL AND type = \$1 ORDER BY created_at DESC`).
@@ -261,9 +310,9 @@ func TestPostgresRepository_GetByRegion(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "created_at", "updated_at", "deleted_at"}).
+	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "orchestrator_handle", "image", "node_id", "node_selector", "created_at", "updated_at", "deleted_at"}).
 		AddRow(expectedServer.ID, expectedServer.Name, expectedServer.Type, expectedServer.Status, expectedServer.Region,
-			expectedServer.IP, expectedServer.Port, expectedServer.CPU, expectedServer.Memory, expectedServer.Disk, expectedServer.Network,
+			expectedServer.IP, expectedServer.Port, expectedServer.CPU, expectedServer.Memory, expectedServer.Disk, expectedServer.Network, expectedServer.OrchestratorHandle, expectedServer.Image, expectedServer.NodeID, []byte(`{}`),
 			expectedServer.CreatedAt, expectedServer.UpdatedAt, nil)
 
 	mock.ExpectQuery(`SELECT .+ FROM servers WHERE deleted_at IS NULL AND region = \$1 ORDER BY created_at DESC`).
@@ -301,9 +350,9 @@ func TestPostgresRepository_GetByStatus(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "created_at", "updated_at", "deleted_at"}).
+	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "orchestrator_handle", "image", "node_id", "node_selector", "created_at", "updated_at", "deleted_at"}).
 		AddRow(expectedServer.ID, expectedServer.Name, expectedServer.Type, expectedServer.Status, expectedServer.Region,
-			expectedServer.IP, expectedServer.Port, expectedServer.CPU, expectedServer.Memory, expectedServer.Disk, expectedServer.Network,
+			expectedServer.IP, expectedServer.Port, expectedServer.CPU, expectedServer.Memory, expectedServer.Disk, expectedServer.Network, expectedServer.OrchestratorHandle, expectedServer.Image, expectedServer.NodeID, []byte(`{}`),
 			expectedServer.CreatedAt, expectedServer.UpdatedAt, nil)
 
 	mock.ExpectQuery(`SELECT .+ FROM servers WHERE deleted_at IS NULL AND status = \$1 ORDER BY created_at DESC`).
@@ -316,3 +365,30 @@ func TestPostgresRepository_GetByStatus(t *testing.T) {
 	assert.Equal(t, expectedServer.ID, servers[0].ID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestPostgresRepository_ListByNode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresRepository(db, zap.NewNop())
+
+	rows := sqlmock.NewRows([]string{"id", "name", "type", "status", "region", "ip", "port", "cpu", "memory", "disk", "network", "orchestrator_handle", "image", "node_id", "node_selector", "created_at", "updated_at", "deleted_at"}).
+		AddRow(uuid.New().String(), "vpn-server", domain.ServerTypeVPN, domain.ServerStatusRunning, "eu-west-1",
+			nil, nil, nil, nil, nil, nil, "eu-node-1/container-1", "silence/vpn-core:latest", "eu-node-1", []byte(`{"zone":"a"}`),
+			time.Now(), time.Now(), nil)
+
+	mock.ExpectQuery(`SELECT .+ FROM servers WHERE deleted_at IS NULL AND status = \$1 AND node_id = \$2 ORDER BY created_at DESC`).
+		WithArgs(domain.ServerStatusRunning, "eu-node-1").
+		WillReturnRows(rows)
+
+	servers, err := repo.List(context.Background(), map[string]interface{}{
+		"status":  domain.ServerStatusRunning,
+		"node_id": "eu-node-1",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, servers, 1)
+	assert.Equal(t, "eu-node-1", servers[0].NodeID)
+	assert.Equal(t, map[string]string{"zone": "a"}, servers[0].NodeSelector)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
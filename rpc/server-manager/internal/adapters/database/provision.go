@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ProvisionRepository репозиторий подготовки хостов серверов. У сервера
+// хранится последняя подготовка
+type ProvisionRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewProvisionRepository создает репозиторий подготовки хостов
+func NewProvisionRepository(db *sql.DB, logger *zap.Logger) *ProvisionRepository {
+	return &ProvisionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveProvision сохраняет подготовку, заменяя предыдущую
+func (r *ProvisionRepository) SaveProvision(ctx context.Context, provision *domain.Provision) error {
+	query := `
+		INSERT INTO server_provisions (server_id, host, mode, status, step, progress, message, started_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (server_id) DO UPDATE
+		SET host = EXCLUDED.host, mode = EXCLUDED.mode, status = EXCLUDED.status, step = EXCLUDED.step,
+		    progress = EXCLUDED.progress, message = EXCLUDED.message,
+		    started_at = EXCLUDED.started_at, completed_at = EXCLUDED.completed_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		provision.ServerID, provision.Host, provision.Mode, provision.Status, provision.Step,
+		provision.Progress, provision.Message, provision.StartedAt, provision.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save provision: %w", err)
+	}
+
+	return nil
+}
+
+// GetProvision получает последнюю подготовку хоста сервера
+func (r *ProvisionRepository) GetProvision(ctx context.Context, serverID string) (*domain.Provision, error) {
+	query := `
+		SELECT server_id, host, mode, status, step, progress, message, started_at, completed_at
+		FROM server_provisions WHERE server_id = $1
+	`
+
+	provision := &domain.Provision{}
+	var completedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, serverID).Scan(
+		&provision.ServerID, &provision.Host, &provision.Mode, &provision.Status, &provision.Step,
+		&provision.Progress, &provision.Message, &provision.StartedAt, &completedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("provision not found: %s", serverID)
+		}
+		return nil, fmt.Errorf("failed to get provision: %w", err)
+	}
+
+	if completedAt.Valid {
+		provision.CompletedAt = &completedAt.Time
+	}
+	return provision, nil
+}
+
+// UpdateProvisionStep записывает начало шага незавершенной подготовки
+func (r *ProvisionRepository) UpdateProvisionStep(ctx context.Context, serverID string, step domain.ProvisionStep, progress int, message string) error {
+	query := `
+		UPDATE server_provisions SET step = $2, progress = $3, message = $4
+		WHERE server_id = $1 AND completed_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, serverID, step, progress, message)
+	if err != nil {
+		return fmt.Errorf("failed to update provision step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("provision in progress not found: %s", serverID)
+	}
+
+	return nil
+}
+
+// FailInterruptedProvisions завершает все незавершенные подготовки со
+// статусом failed и возвращает ID их серверов
+func (r *ProvisionRepository) FailInterruptedProvisions(ctx context.Context, message string) ([]string, error) {
+	query := `
+		UPDATE server_provisions SET status = $1, message = $2, completed_at = $3
+		WHERE completed_at IS NULL
+		RETURNING server_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.ProvisionStatusFailed, message, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail interrupted provisions: %w", err)
+	}
+	defer rows.Close()
+
+	var serverIDs []string
+	for rows.Next() {
+		var serverID string
+		if err := rows.Scan(&serverID); err != nil {
+			return nil, fmt.Errorf("failed to scan provision: %w", err)
+		}
+		serverIDs = append(serverIDs, serverID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate provisions: %w", err)
+	}
+	return serverIDs, nil
+}
+
+// CompleteProvision завершает подготовку со статусом completed или failed
+func (r *ProvisionRepository) CompleteProvision(ctx context.Context, serverID string, success bool, message string) error {
+	// Шаг и прогресс неудачной подготовки сохраняются
+	query := `
+		UPDATE server_provisions SET status = $2, progress = GREATEST(progress, $3), message = $4, completed_at = $5
+		WHERE server_id = $1 AND completed_at IS NULL
+	`
+
+	status, progress := domain.ProvisionStatusFailed, 0
+	if success {
+		status, progress = domain.ProvisionStatusCompleted, 100
+	}
+
+	result, err := r.db.ExecContext(ctx, query, serverID, status, progress, message, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to complete provision: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("provision in progress not found: %s", serverID)
+	}
+
+	r.logger.Info("provision completed",
+		zap.String("server_id", serverID),
+		zap.String("status", status),
+		zap.String("message", message))
+	return nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProvisionRepository_SaveProvision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProvisionRepository(db, zap.NewNop())
+	startedAt := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	provision := &domain.Provision{
+		ServerID:  "server-1",
+		Host:      "203.0.113.10",
+		Mode:      domain.ProvisionModeBinary,
+		Status:    domain.ProvisionStatusInProgress,
+		Step:      domain.ProvisionStepConnect,
+		StartedAt: startedAt,
+	}
+
+	mock.ExpectExec(`INSERT INTO server_provisions .+ ON CONFLICT \(server_id\) DO UPDATE`).
+		WithArgs("server-1", "203.0.113.10", domain.ProvisionModeBinary, domain.ProvisionStatusInProgress,
+			domain.ProvisionStepConnect, 0, "", startedAt, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.SaveProvision(context.Background(), provision))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProvisionRepository_GetProvision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProvisionRepository(db, zap.NewNop())
+	startedAt := time.Now().Add(-time.Minute)
+	completedAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"server_id", "host", "mode", "status", "step", "progress", "message", "started_at", "completed_at"}).
+		AddRow("server-1", "203.0.113.10", "container", domain.ProvisionStatusFailed, "install", 33,
+			"docker pull failed", startedAt, completedAt)
+	mock.ExpectQuery(`SELECT .+ FROM server_provisions WHERE server_id = \$1`).
+		WithArgs("server-1").
+		WillReturnRows(rows)
+
+	provision, err := repo.GetProvision(context.Background(), "server-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProvisionModeContainer, provision.Mode)
+	assert.Equal(t, domain.ProvisionStepInstall, provision.Step)
+	assert.Equal(t, 33, provision.Progress)
+	require.NotNil(t, provision.CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProvisionRepository_UpdateAndComplete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProvisionRepository(db, zap.NewNop())
+
+	mock.ExpectExec("UPDATE server_provisions SET step").
+		WithArgs("server-1", domain.ProvisionStepStart, 66, "starting silence-vpn-server-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE server_provisions SET status").
+		WithArgs("server-1", domain.ProvisionStatusCompleted, 100, "provisioned", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Завершенная подготовка повторно не завершается и не меняется
+	mock.ExpectExec("UPDATE server_provisions SET status").
+		WithArgs("server-1", domain.ProvisionStatusFailed, 0, "connection refused", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE server_provisions SET step").
+		WithArgs("server-1", domain.ProvisionStepRegister, 83, "registering server").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	assert.NoError(t, repo.UpdateProvisionStep(ctx, "server-1", domain.ProvisionStepStart, 66, "starting silence-vpn-server-1"))
+	assert.NoError(t, repo.CompleteProvision(ctx, "server-1", true, "provisioned"))
+	assert.Error(t, repo.CompleteProvision(ctx, "server-1", false, "connection refused"))
+	assert.Error(t, repo.UpdateProvisionStep(ctx, "server-1", domain.ProvisionStepRegister, 83, "registering server"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProvisionRepository_FailInterruptedProvisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProvisionRepository(db, zap.NewNop())
+
+	mock.ExpectQuery("UPDATE server_provisions SET status").
+		WithArgs(domain.ProvisionStatusFailed, "interrupted", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"server_id"}).AddRow("server-1").AddRow("server-2"))
+
+	serverIDs, err := repo.FailInterruptedProvisions(context.Background(), "interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"server-1", "server-2"}, serverIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// RegionRepository репозиторий регионов размещения серверов
+type RegionRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewRegionRepository создает репозиторий регионов
+func NewRegionRepository(db *sql.DB, logger *zap.Logger) *RegionRepository {
+	return &RegionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const regionColumns = `code, name, country, latitude, longitude, max_servers, max_load, enabled,
+		created_at, updated_at`
+
+// SaveRegion создает регион или обновляет существующий с тем же кодом
+func (r *RegionRepository) SaveRegion(ctx context.Context, region *domain.Region) error {
+	query := `
+		INSERT INTO regions (` + regionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (code) DO UPDATE
+		SET name = EXCLUDED.name, country = EXCLUDED.country, latitude = EXCLUDED.latitude,
+		    longitude = EXCLUDED.longitude, max_servers = EXCLUDED.max_servers, max_load = EXCLUDED.max_load,
+		    enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		region.Code, region.Name, region.Country, region.Latitude, region.Longitude,
+		region.MaxServers, region.MaxLoad, region.Enabled, time.Now(),
+	).Scan(&region.CreatedAt, &region.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save region: %w", err)
+	}
+
+	r.logger.Info("region saved", zap.String("code", region.Code))
+	return nil
+}
+
+// GetRegion получает регион по коду
+func (r *RegionRepository) GetRegion(ctx context.Context, code string) (*domain.Region, error) {
+	query := `SELECT ` + regionColumns + ` FROM regions WHERE code = $1`
+
+	region, err := scanRegion(r.db.QueryRowContext(ctx, query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("region not found: %s", code)
+		}
+		return nil, fmt.Errorf("failed to get region: %w", err)
+	}
+
+	return region, nil
+}
+
+// ListRegions получает все регионы
+func (r *RegionRepository) ListRegions(ctx context.Context) ([]*domain.Region, error) {
+	query := `SELECT ` + regionColumns + ` FROM regions ORDER BY code`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regions: %w", err)
+	}
+	defer rows.Close()
+
+	regions := []*domain.Region{}
+	for rows.Next() {
+		region, err := scanRegion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan region: %w", err)
+		}
+		regions = append(regions, region)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list regions: %w", err)
+	}
+
+	return regions, nil
+}
+
+// DeleteRegion удаляет регион
+func (r *RegionRepository) DeleteRegion(ctx context.Context, code string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM regions WHERE code = $1`, code)
+	if err != nil {
+		return fmt.Errorf("failed to delete region: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("region not found: %s", code)
+	}
+
+	r.logger.Info("region deleted", zap.String("code", code))
+	return nil
+}
+
+// scanRegion читает строку региона
+func scanRegion(row rowScanner) (*domain.Region, error) {
+	region := &domain.Region{}
+	err := row.Scan(
+		&region.Code, &region.Name, &region.Country, &region.Latitude, &region.Longitude,
+		&region.MaxServers, &region.MaxLoad, &region.Enabled,
+		&region.CreatedAt, &region.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return region, nil
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var regionRows = []string{"code", "name", "country", "latitude", "longitude", "max_servers", "max_load",
+	"enabled", "created_at", "updated_at"}
+
+func TestRegionRepository_SaveRegion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRegionRepository(db, zap.NewNop())
+	region := &domain.Region{
+		Code:       "eu-west-1",
+		Name:       "Europe (Ireland)",
+		Country:    "IE",
+		Latitude:   53.35,
+		Longitude:  -6.26,
+		MaxServers: 20,
+		MaxLoad:    75,
+		Enabled:    true,
+	}
+
+	created := time.Now().Add(-time.Hour)
+	mock.ExpectQuery("INSERT INTO regions .+ ON CONFLICT \\(code\\) DO UPDATE").
+		WithArgs(region.Code, region.Name, "IE", 53.35, -6.26, 20, 75.0, true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(created, time.Now()))
+
+	require.NoError(t, repo.SaveRegion(context.Background(), region))
+	assert.Equal(t, created, region.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegionRepository_ListRegions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRegionRepository(db, zap.NewNop())
+
+	now := time.Now()
+	rows := sqlmock.NewRows(regionRows).
+		AddRow("eu-west-1", "Europe (Ireland)", "IE", 53.35, -6.26, 20, 75.0, true, now, now).
+		AddRow("us-east-1", "us-east-1", "", 0.0, 0.0, 0, 0.0, false, now, now)
+	mock.ExpectQuery(`SELECT .+ FROM regions ORDER BY code`).WillReturnRows(rows)
+
+	regions, err := repo.ListRegions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+	assert.Equal(t, "IE", regions[0].Country)
+	assert.True(t, regions[0].HasLocation())
+	assert.Equal(t, 75.0, regions[0].LoadLimit())
+	assert.False(t, regions[1].HasLocation())
+	assert.Equal(t, domain.DefaultRegionMaxLoad, regions[1].LoadLimit())
+	assert.False(t, regions[1].Enabled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegionRepository_GetRegionNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRegionRepository(db, zap.NewNop())
+
+	mock.ExpectQuery(`SELECT .+ FROM regions WHERE code = \$1`).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(regionRows))
+
+	_, err = repo.GetRegion(context.Background(), "missing")
+	assert.EqualError(t, err, "region not found: missing")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegionRepository_DeleteRegion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRegionRepository(db, zap.NewNop())
+
+	mock.ExpectExec(`DELETE FROM regions WHERE code = \$1`).
+		WithArgs("eu-west-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM regions WHERE code = \$1`).
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.DeleteRegion(context.Background(), "eu-west-1"))
+	assert.EqualError(t, repo.DeleteRegion(context.Background(), "missing"), "region not found: missing")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
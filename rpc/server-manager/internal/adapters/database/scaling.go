@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ScalingRepository репозиторий политик и истории масштабирования
+type ScalingRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewScalingRepository создает репозиторий масштабирования
+func NewScalingRepository(db *sql.DB, logger *zap.Logger) *ScalingRepository {
+	return &ScalingRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const scalingPolicyColumns = `id, name, server_type, region, min_servers, max_servers, cpu_threshold, memory_threshold,
+		scale_up_cooldown, scale_down_cooldown, enabled`
+
+// SavePolicy сохраняет политику масштабирования
+func (r *ScalingRepository) SavePolicy(ctx context.Context, policy *domain.ScalingPolicy) error {
+	query := `
+		INSERT INTO scaling_policies (` + scalingPolicyColumns + `, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		policy.ID, policy.Name, policy.ServerType, policy.Region, policy.MinServers, policy.MaxServers,
+		policy.CPUThreshold, policy.MemoryThreshold,
+		int64(policy.ScaleUpCooldown.Seconds()), int64(policy.ScaleDownCooldown.Seconds()),
+		policy.Enabled, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save scaling policy: %w", err)
+	}
+
+	r.logger.Info("scaling policy saved", zap.String("id", policy.ID), zap.String("name", policy.Name))
+	return nil
+}
+
+// GetPolicy получает политику масштабирования по ID
+func (r *ScalingRepository) GetPolicy(ctx context.Context, id string) (*domain.ScalingPolicy, error) {
+	query := `SELECT ` + scalingPolicyColumns + ` FROM scaling_policies WHERE id = $1`
+
+	policy, err := scanScalingPolicy(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("scaling policy not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get scaling policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// ListPolicies получает все политики масштабирования
+func (r *ScalingRepository) ListPolicies(ctx context.Context) ([]*domain.ScalingPolicy, error) {
+	query := `SELECT ` + scalingPolicyColumns + ` FROM scaling_policies ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scaling policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []*domain.ScalingPolicy{}
+	for rows.Next() {
+		policy, err := scanScalingPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scaling policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list scaling policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// UpdatePolicy обновляет политику масштабирования
+func (r *ScalingRepository) UpdatePolicy(ctx context.Context, policy *domain.ScalingPolicy) error {
+	query := `
+		UPDATE scaling_policies
+		SET name = $2, server_type = $3, region = $4, min_servers = $5, max_servers = $6,
+		    cpu_threshold = $7, memory_threshold = $8, scale_up_cooldown = $9, scale_down_cooldown = $10,
+		    enabled = $11, updated_at = $12
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		policy.ID, policy.Name, policy.ServerType, policy.Region, policy.MinServers, policy.MaxServers,
+		policy.CPUThreshold, policy.MemoryThreshold,
+		int64(policy.ScaleUpCooldown.Seconds()), int64(policy.ScaleDownCooldown.Seconds()),
+		policy.Enabled, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update scaling policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("scaling policy not found: %s", policy.ID)
+	}
+
+	r.logger.Info("scaling policy updated", zap.String("id", policy.ID))
+	return nil
+}
+
+// DeletePolicy удаляет политику масштабирования вместе с ее историей
+func (r *ScalingRepository) DeletePolicy(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM scaling_policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scaling policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("scaling policy not found: %s", id)
+	}
+
+	r.logger.Info("scaling policy deleted", zap.String("id", id))
+	return nil
+}
+
+// SaveDecision записывает решение масштабирования в историю
+func (r *ScalingRepository) SaveDecision(ctx context.Context, decision *domain.ScalingDecision) error {
+	query := `
+		INSERT INTO scaling_history (policy_id, server_type, region, action, reason, servers_before, servers_after,
+		                             cpu_usage, memory_usage, triggered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		decision.PolicyID, decision.ServerType, decision.Region, decision.Action, decision.Reason,
+		decision.ServersBefore, decision.ServersAfter, decision.CPUUsage, decision.MemoryUsage,
+		decision.TriggeredAt,
+	).Scan(&decision.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save scaling decision: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastAction возвращает последнее масштабирование группы
+func (r *ScalingRepository) GetLastAction(ctx context.Context, policyID, region string) (*domain.ScalingDecision, error) {
+	query := `
+		SELECT id, policy_id, server_type, region, action, reason, servers_before, servers_after,
+		       cpu_usage, memory_usage, triggered_at
+		FROM scaling_history
+		WHERE policy_id = $1 AND region = $2 AND action IN ($3, $4)
+		ORDER BY triggered_at DESC
+		LIMIT 1
+	`
+
+	decision := &domain.ScalingDecision{}
+	var reason sql.NullString
+	err := r.db.QueryRowContext(ctx, query, policyID, region,
+		domain.ScalingActionScaleUp, domain.ScalingActionScaleDown,
+	).Scan(
+		&decision.ID, &decision.PolicyID, &decision.ServerType, &decision.Region, &decision.Action, &reason,
+		&decision.ServersBefore, &decision.ServersAfter, &decision.CPUUsage, &decision.MemoryUsage,
+		&decision.TriggeredAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last scaling action: %w", err)
+	}
+	decision.Reason = reason.String
+
+	return decision, nil
+}
+
+// rowScanner строка результата запроса
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanScalingPolicy читает политику; кулдауны хранятся в секундах
+func scanScalingPolicy(row rowScanner) (*domain.ScalingPolicy, error) {
+	policy := &domain.ScalingPolicy{}
+	var scaleUpCooldown, scaleDownCooldown int64
+
+	err := row.Scan(
+		&policy.ID, &policy.Name, &policy.ServerType, &policy.Region, &policy.MinServers, &policy.MaxServers,
+		&policy.CPUThreshold, &policy.MemoryThreshold, &scaleUpCooldown, &scaleDownCooldown, &policy.Enabled,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.ScaleUpCooldown = time.Duration(scaleUpCooldown) * time.Second
+	policy.ScaleDownCooldown = time.Duration(scaleDownCooldown) * time.Second
+	return policy, nil
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var scalingPolicyRows = []string{"id", "name", "server_type", "region", "min_servers", "max_servers",
+	"cpu_threshold", "memory_threshold", "scale_up_cooldown", "scale_down_cooldown", "enabled"}
+
+func TestScalingRepository_SavePolicy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewScalingRepository(db, zap.NewNop())
+	policy := &domain.ScalingPolicy{
+		ID:                "policy-1",
+		Name:              "vpn-eu",
+		ServerType:        domain.ServerTypeVPN,
+		Region:            "eu-west-1",
+		MinServers:        2,
+		MaxServers:        10,
+		CPUThreshold:      0.7,
+		MemoryThreshold:   0.8,
+		ScaleUpCooldown:   5 * time.Minute,
+		ScaleDownCooldown: 10 * time.Minute,
+		Enabled:           true,
+	}
+
+	mock.ExpectExec("INSERT INTO scaling_policies").
+		WithArgs(policy.ID, policy.Name, policy.ServerType, policy.Region, 2, 10, 0.7, 0.8,
+			int64(300), int64(600), true, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.SavePolicy(context.Background(), policy))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScalingRepository_ListPolicies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewScalingRepository(db, zap.NewNop())
+
+	rows := sqlmock.NewRows(scalingPolicyRows).
+		AddRow("policy-1", "vpn-eu", "vpn", "eu-west-1", 2, 10, 0.7, 0.8, 300, 600, true).
+		AddRow("policy-2", "dpi", "dpi", "", 1, 3, 0.9, 0.9, 60, 120, false)
+	mock.ExpectQuery(`SELECT .+ FROM scaling_policies ORDER BY name`).WillReturnRows(rows)
+
+	policies, err := repo.ListPolicies(context.Background())
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, domain.ServerTypeVPN, policies[0].ServerType)
+	assert.Equal(t, "eu-west-1", policies[0].Region)
+	assert.Equal(t, 5*time.Minute, policies[0].ScaleUpCooldown)
+	assert.Equal(t, 10*time.Minute, policies[0].ScaleDownCooldown)
+	assert.False(t, policies[1].Enabled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScalingRepository_SaveDecision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewScalingRepository(db, zap.NewNop())
+	decision := &domain.ScalingDecision{
+		PolicyID:      "policy-1",
+		ServerType:    domain.ServerTypeVPN,
+		Region:        "eu-west-1",
+		Action:        domain.ScalingActionScaleUp,
+		Reason:        "cpu 91.0%, memory 40.0% above threshold",
+		ServersBefore: 2,
+		ServersAfter:  3,
+		CPUUsage:      91,
+		MemoryUsage:   40,
+		TriggeredAt:   time.Now(),
+	}
+
+	mock.ExpectQuery("INSERT INTO scaling_history").
+		WithArgs(decision.PolicyID, decision.ServerType, decision.Region, decision.Action, decision.Reason,
+			2, 3, 91.0, 40.0, decision.TriggeredAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+
+	require.NoError(t, repo.SaveDecision(context.Background(), decision))
+	assert.Equal(t, int64(42), decision.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScalingRepository_GetLastAction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewScalingRepository(db, zap.NewNop())
+	triggeredAt := time.Now().Add(-time.Minute)
+
+	mock.ExpectQuery(`SELECT .+ FROM scaling_history`).
+		WithArgs("policy-1", "eu-west-1", domain.ScalingActionScaleUp, domain.ScalingActionScaleDown).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "policy_id", "server_type", "region", "action", "reason",
+			"servers_before", "servers_after", "cpu_usage", "memory_usage", "triggered_at"}).
+			AddRow(7, "policy-1", "vpn", "eu-west-1", "scale_down", nil, 3, 2, 10.0, 20.0, triggeredAt))
+
+	decision, err := repo.GetLastAction(context.Background(), "policy-1", "eu-west-1")
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	assert.Equal(t, domain.ScalingActionScaleDown, decision.Action)
+	assert.Equal(t, triggeredAt, decision.TriggeredAt)
+	assert.Empty(t, decision.Reason)
+
+	// Без истории кулдаун не действует
+	mock.ExpectQuery(`SELECT .+ FROM scaling_history`).
+		WithArgs("policy-1", "us-east-1", domain.ScalingActionScaleUp, domain.ScalingActionScaleDown).
+		WillReturnRows(sqlmock.NewRows(nil))
+
+	decision, err = repo.GetLastAction(context.Background(), "policy-1", "us-east-1")
+	assert.NoError(t, err)
+	assert.Nil(t, decision)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
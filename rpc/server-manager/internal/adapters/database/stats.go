@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// statsPeriods периоды агрегированной статистики
+var statsPeriods = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// StatsRepository репозиторий истории статистики серверов. Старые замеры
+// прореживаются до средних за интервал, поэтому средние считаются с весом
+// samples
+type StatsRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewStatsRepository создает репозиторий статистики
+func NewStatsRepository(db *sql.DB, logger *zap.Logger) *StatsRepository {
+	return &StatsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const statsColumns = `server_id, cpu, memory, disk, network_in, network_out, connections, uptime, timestamp`
+
+// SaveStats сохраняет замер статистики
+func (r *StatsRepository) SaveStats(ctx context.Context, stats *domain.ServerStats) error {
+	query := `INSERT INTO server_stats (` + statsColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		stats.ServerID, stats.CPUUsage, stats.MemoryUsage, stats.StorageUsage,
+		stats.NetworkIn, stats.NetworkOut, stats.RequestCount, stats.Uptime, stats.Timestamp,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save stats: %w", err)
+	}
+
+	return nil
+}
+
+// GetStats получает последние limit замеров сервера, от новых к старым
+func (r *StatsRepository) GetStats(ctx context.Context, serverID string, limit int) ([]*domain.ServerStats, error) {
+	query := `
+		SELECT ` + statsColumns + ` FROM server_stats
+		WHERE server_id = $1 ORDER BY timestamp DESC LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, serverID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := []*domain.ServerStats{}
+	for rows.Next() {
+		stats, err := scanStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stats: %w", err)
+		}
+		result = append(result, stats)
+	}
+
+	return result, rows.Err()
+}
+
+// GetLatestStats получает последний замер сервера
+func (r *StatsRepository) GetLatestStats(ctx context.Context, serverID string) (*domain.ServerStats, error) {
+	query := `
+		SELECT ` + statsColumns + ` FROM server_stats
+		WHERE server_id = $1 ORDER BY timestamp DESC LIMIT 1
+	`
+
+	stats, err := scanStats(r.db.QueryRowContext(ctx, query, serverID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("stats not found: %s", serverID)
+		}
+		return nil, fmt.Errorf("failed to get latest stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetLatestStatsBatch получает последние замеры серверов одним запросом.
+// Серверов без замеров в результате нет
+func (r *StatsRepository) GetLatestStatsBatch(ctx context.Context, serverIDs []string) (map[string]*domain.ServerStats, error) {
+	result := make(map[string]*domain.ServerStats, len(serverIDs))
+	if len(serverIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT DISTINCT ON (server_id) ` + statsColumns + ` FROM server_stats
+		WHERE server_id = ANY($1) ORDER BY server_id, timestamp DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(serverIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		stats, err := scanStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stats: %w", err)
+		}
+		result[stats.ServerID] = stats
+	}
+
+	return result, rows.Err()
+}
+
+// GetAggregatedStats получает статистику сервера за период 1h, 24h или 7d:
+// средние загрузки и соединения, трафик за период и последнее время работы
+func (r *StatsRepository) GetAggregatedStats(ctx context.Context, serverID string, period string) (*domain.ServerStats, error) {
+	duration, ok := statsPeriods[period]
+	if !ok {
+		return nil, fmt.Errorf("unsupported stats period: %s", period)
+	}
+
+	// Счетчики трафика растут с запуска контейнера и сбрасываются при его
+	// перезапуске и замене. Трафик за период - сумма приростов между
+	// соседними замерами; после сброса приростом считается новое значение
+	query := `
+		WITH deltas AS (
+			SELECT samples, cpu, memory, disk, connections, uptime,
+			       CASE WHEN LAG(network_in) OVER w IS NULL THEN 0
+			            WHEN network_in >= LAG(network_in) OVER w THEN network_in - LAG(network_in) OVER w
+			            ELSE network_in END AS network_in,
+			       CASE WHEN LAG(network_out) OVER w IS NULL THEN 0
+			            WHEN network_out >= LAG(network_out) OVER w THEN network_out - LAG(network_out) OVER w
+			            ELSE network_out END AS network_out
+			FROM server_stats
+			WHERE server_id = $1 AND timestamp >= $2
+			WINDOW w AS (ORDER BY timestamp)
+		)
+		SELECT COALESCE(SUM(samples), 0),
+		       COALESCE(SUM(cpu * samples) / NULLIF(SUM(samples), 0), 0),
+		       COALESCE(SUM(memory * samples) / NULLIF(SUM(samples), 0), 0),
+		       COALESCE(SUM(disk * samples) / NULLIF(SUM(samples), 0), 0),
+		       COALESCE(SUM(network_in), 0),
+		       COALESCE(SUM(network_out), 0),
+		       COALESCE(SUM(connections * samples) / NULLIF(SUM(samples), 0), 0),
+		       COALESCE(MAX(uptime), 0)
+		FROM deltas
+	`
+
+	now := time.Now()
+	stats := &domain.ServerStats{ServerID: serverID, Timestamp: now}
+	var samples int64
+	err := r.db.QueryRowContext(ctx, query, serverID, now.Add(-duration)).Scan(
+		&samples, &stats.CPUUsage, &stats.MemoryUsage, &stats.StorageUsage,
+		&stats.NetworkIn, &stats.NetworkOut, &stats.RequestCount, &stats.Uptime,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aggregated stats: %w", err)
+	}
+	if samples == 0 {
+		return nil, fmt.Errorf("stats not found: %s", serverID)
+	}
+
+	return stats, nil
+}
+
+// DownsampleStats заменяет исходные замеры старше before средними за
+// интервалы длиной bucket
+func (r *StatsRepository) DownsampleStats(ctx context.Context, before time.Time, bucket time.Duration) error {
+	query := `
+		WITH raw AS (
+			DELETE FROM server_stats WHERE resolution = 0 AND timestamp < $1
+			RETURNING ` + statsColumns + `, samples
+		)
+		INSERT INTO server_stats (` + statsColumns + `, resolution, samples)
+		SELECT server_id,
+		       SUM(cpu * samples) / SUM(samples),
+		       SUM(memory * samples) / SUM(samples),
+		       SUM(disk * samples) / SUM(samples),
+		       MAX(network_in), MAX(network_out),
+		       SUM(connections * samples) / SUM(samples),
+		       MAX(uptime),
+		       to_timestamp(floor(extract(epoch FROM timestamp) / $2) * $2),
+		       $2, SUM(samples)
+		FROM raw
+		GROUP BY server_id, floor(extract(epoch FROM timestamp) / $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, before, int64(bucket.Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to downsample stats: %w", err)
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
+		r.logger.Info("stats downsampled", zap.Time("before", before), zap.Int64("rows", rowsAffected))
+	}
+	return nil
+}
+
+// DeleteStatsBefore удаляет статистику старше before
+func (r *StatsRepository) DeleteStatsBefore(ctx context.Context, before time.Time) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM server_stats WHERE timestamp < $1`, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete stats: %w", err)
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
+		r.logger.Info("stats deleted", zap.Time("before", before), zap.Int64("rows", rowsAffected))
+	}
+	return nil
+}
+
+// scanStats читает строку статистики
+func scanStats(row rowScanner) (*domain.ServerStats, error) {
+	stats := &domain.ServerStats{}
+	err := row.Scan(
+		&stats.ServerID, &stats.CPUUsage, &stats.MemoryUsage, &stats.StorageUsage,
+		&stats.NetworkIn, &stats.NetworkOut, &stats.RequestCount, &stats.Uptime, &stats.Timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
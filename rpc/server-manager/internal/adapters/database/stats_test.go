@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var statsRows = []string{"server_id", "cpu", "memory", "disk", "network_in", "network_out", "connections", "uptime", "timestamp"}
+
+func TestStatsRepository_SaveStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsRepository(db, zap.NewNop())
+	now := time.Now()
+	stats := &domain.ServerStats{
+		ServerID:     "server-1",
+		CPUUsage:     42.5,
+		MemoryUsage:  30,
+		StorageUsage: 10,
+		NetworkIn:    1024,
+		NetworkOut:   2048,
+		RequestCount: 7,
+		Uptime:       3600,
+		Timestamp:    now,
+	}
+
+	mock.ExpectExec("INSERT INTO server_stats").
+		WithArgs("server-1", 42.5, 30.0, 10.0, int64(1024), int64(2048), int64(7), int64(3600), now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.SaveStats(context.Background(), stats))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatsRepository_GetStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsRepository(db, zap.NewNop())
+	now := time.Now()
+
+	rows := sqlmock.NewRows(statsRows).
+		AddRow("server-1", 50.0, 40.0, 10.0, 2000, 4000, 3, 120, now).
+		AddRow("server-1", 20.0, 35.0, 10.0, 1000, 2000, 1, 60, now.Add(-time.Minute))
+	mock.ExpectQuery(`SELECT .+ FROM server_stats WHERE server_id = \$1 ORDER BY timestamp DESC LIMIT \$2`).
+		WithArgs("server-1", 2).
+		WillReturnRows(rows)
+
+	stats, err := repo.GetStats(context.Background(), "server-1", 2)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, 50.0, stats[0].CPUUsage)
+	assert.Equal(t, int64(2000), stats[0].NetworkIn)
+	assert.Equal(t, int64(60), stats[1].Uptime)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatsRepository_GetLatestStatsBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsRepository(db, zap.NewNop())
+	now := time.Now()
+
+	rows := sqlmock.NewRows(statsRows).
+		AddRow("server-1", 50.0, 40.0, 10.0, 2000, 4000, 3, 120, now).
+		AddRow("server-2", 20.0, 70.0, 10.0, 1000, 2000, 1, 60, now)
+	mock.ExpectQuery(`SELECT DISTINCT ON \(server_id\) .+ FROM server_stats WHERE server_id = ANY\(\$1\)`).
+		WithArgs(pq.Array([]string{"server-1", "server-2", "server-3"})).
+		WillReturnRows(rows)
+
+	stats, err := repo.GetLatestStatsBatch(context.Background(), []string{"server-1", "server-2", "server-3"})
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, 50.0, stats["server-1"].CPUUsage)
+	assert.Equal(t, 70.0, stats["server-2"].MemoryUsage)
+
+	// Без серверов запрос не выполняется
+	stats, err = repo.GetLatestStatsBatch(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatsRepository_GetAggregatedStats(t *testing.T) {
+	tests := []struct {
+		period string
+		window time.Duration
+	}{
+		{period: "1h", window: time.Hour},
+		{period: "24h", window: 24 * time.Hour},
+		{period: "7d", window: 7 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewStatsRepository(db, zap.NewNop())
+
+			// Средние взвешиваются числом исходных замеров в строке, трафик -
+			// сумма приростов счетчиков с учетом сбросов
+			mock.ExpectQuery(`(?s)WHEN network_in >= LAG\(network_in\) OVER w .+ELSE network_in END.+`+
+				`SUM\(cpu \* samples\) / NULLIF\(SUM\(samples\), 0\).+SUM\(network_in\)`).
+				WithArgs("server-1", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"samples", "cpu", "memory", "disk", "network_in", "network_out", "connections", "uptime"}).
+					AddRow(120, 35.5, 40.0, 12.0, 10240, 20480, 4, 7200))
+
+			before := time.Now()
+			stats, err := repo.GetAggregatedStats(context.Background(), "server-1", tt.period)
+			require.NoError(t, err)
+			assert.Equal(t, "server-1", stats.ServerID)
+			assert.Equal(t, 35.5, stats.CPUUsage)
+			assert.Equal(t, int64(10240), stats.NetworkIn)
+			assert.Equal(t, int64(7200), stats.Uptime)
+			assert.False(t, stats.Timestamp.Before(before))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("no samples", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := NewStatsRepository(db, zap.NewNop())
+		mock.ExpectQuery("FROM server_stats").
+			WillReturnRows(sqlmock.NewRows([]string{"samples", "cpu", "memory", "disk", "network_in", "network_out", "connections", "uptime"}).
+				AddRow(0, 0.0, 0.0, 0.0, 0, 0, 0, 0))
+
+		_, err = repo.GetAggregatedStats(context.Background(), "server-1", "1h")
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unsupported period", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		_, err = NewStatsRepository(db, zap.NewNop()).GetAggregatedStats(context.Background(), "server-1", "30d")
+		assert.ErrorContains(t, err, "unsupported stats period")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStatsRepository_DownsampleStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsRepository(db, zap.NewNop())
+	before := time.Now().Add(-24 * time.Hour)
+
+	// Усредняются только исходные замеры, интервал передается в секундах
+	mock.ExpectExec(`DELETE FROM server_stats WHERE resolution = 0 AND timestamp < \$1.+INSERT INTO server_stats`).
+		WithArgs(before, int64(3600)).
+		WillReturnResult(sqlmock.NewResult(0, 24))
+
+	assert.NoError(t, repo.DownsampleStats(context.Background(), before, time.Hour))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatsRepository_DeleteStatsBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsRepository(db, zap.NewNop())
+	before := time.Now().Add(-30 * 24 * time.Hour)
+
+	mock.ExpectExec(`DELETE FROM server_stats WHERE timestamp < \$1`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 100))
+
+	assert.NoError(t, repo.DeleteStatsBefore(context.Background(), before))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
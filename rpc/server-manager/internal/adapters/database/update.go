@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// UpdateRepository репозиторий статусов обновления ПО серверов. У сервера
+// хранится статус последнего обновления
+type UpdateRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewUpdateRepository создает репозиторий статусов обновления
+func NewUpdateRepository(db *sql.DB, logger *zap.Logger) *UpdateRepository {
+	return &UpdateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveUpdateStatus сохраняет статус обновления, заменяя предыдущий
+func (r *UpdateRepository) SaveUpdateStatus(ctx context.Context, status *domain.UpdateStatus) error {
+	query := `
+		INSERT INTO server_updates (server_id, status, progress, message, started_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (server_id) DO UPDATE
+		SET status = EXCLUDED.status, progress = EXCLUDED.progress, message = EXCLUDED.message,
+		    started_at = EXCLUDED.started_at, completed_at = EXCLUDED.completed_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		status.ServerID, status.Status, status.Progress, status.Message, status.StartedAt, status.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save update status: %w", err)
+	}
+
+	return nil
+}
+
+// GetUpdateStatus получает статус последнего обновления сервера
+func (r *UpdateRepository) GetUpdateStatus(ctx context.Context, serverID string) (*domain.UpdateStatus, error) {
+	query := `
+		SELECT server_id, status, progress, message, started_at, completed_at
+		FROM server_updates WHERE server_id = $1
+	`
+
+	status := &domain.UpdateStatus{}
+	var completedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, serverID).Scan(
+		&status.ServerID, &status.Status, &status.Progress, &status.Message, &status.StartedAt, &completedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("update status not found: %s", serverID)
+		}
+		return nil, fmt.Errorf("failed to get update status: %w", err)
+	}
+
+	if completedAt.Valid {
+		status.CompletedAt = &completedAt.Time
+	}
+	return status, nil
+}
+
+// UpdateProgress обновляет прогресс незавершенного обновления
+func (r *UpdateRepository) UpdateProgress(ctx context.Context, serverID string, progress int, message string) error {
+	query := `
+		UPDATE server_updates SET progress = $2, message = $3
+		WHERE server_id = $1 AND completed_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, serverID, progress, message)
+	if err != nil {
+		return fmt.Errorf("failed to update progress: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("update in progress not found: %s", serverID)
+	}
+
+	return nil
+}
+
+// CompleteUpdate завершает обновление со статусом completed или failed
+func (r *UpdateRepository) CompleteUpdate(ctx context.Context, serverID string, success bool, message string) error {
+	// Прогресс неудачного обновления сохраняется
+	query := `
+		UPDATE server_updates SET status = $2, progress = GREATEST(progress, $3), message = $4, completed_at = $5
+		WHERE server_id = $1 AND completed_at IS NULL
+	`
+
+	status, progress := domain.UpdateStatusFailed, 0
+	if success {
+		status, progress = domain.UpdateStatusCompleted, 100
+	}
+
+	result, err := r.db.ExecContext(ctx, query, serverID, status, progress, message, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to complete update: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("update in progress not found: %s", serverID)
+	}
+
+	r.logger.Info("update completed",
+		zap.String("server_id", serverID),
+		zap.String("status", status),
+		zap.String("message", message))
+	return nil
+}
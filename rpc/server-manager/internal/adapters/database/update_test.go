@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUpdateRepository_SaveUpdateStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUpdateRepository(db, zap.NewNop())
+	startedAt := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	status := &domain.UpdateStatus{
+		ServerID:  "server-1",
+		Status:    domain.UpdateStatusInProgress,
+		Message:   "updating to silence/vpn-core:1.2.0",
+		StartedAt: startedAt,
+	}
+
+	mock.ExpectExec(`INSERT INTO server_updates .+ ON CONFLICT \(server_id\) DO UPDATE`).
+		WithArgs("server-1", domain.UpdateStatusInProgress, 0, status.Message, startedAt, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.SaveUpdateStatus(context.Background(), status))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateRepository_GetUpdateStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUpdateRepository(db, zap.NewNop())
+	startedAt := time.Now().Add(-time.Minute)
+
+	rows := sqlmock.NewRows([]string{"server_id", "status", "progress", "message", "started_at", "completed_at"}).
+		AddRow("server-1", domain.UpdateStatusInProgress, 50, "waiting for health checks", startedAt, nil)
+	mock.ExpectQuery(`SELECT .+ FROM server_updates WHERE server_id = \$1`).
+		WithArgs("server-1").
+		WillReturnRows(rows)
+
+	status, err := repo.GetUpdateStatus(context.Background(), "server-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.UpdateStatusInProgress, status.Status)
+	assert.Equal(t, 50, status.Progress)
+	assert.Nil(t, status.CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateRepository_CompleteUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUpdateRepository(db, zap.NewNop())
+
+	mock.ExpectExec("UPDATE server_updates SET status").
+		WithArgs("server-1", domain.UpdateStatusCompleted, 100, "updated", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Завершенное обновление повторно не завершается
+	mock.ExpectExec("UPDATE server_updates SET status").
+		WithArgs("server-1", domain.UpdateStatusFailed, 0, "health check failed", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.CompleteUpdate(context.Background(), "server-1", true, "updated"))
+	assert.Error(t, repo.CompleteUpdate(context.Background(), "server-1", false, "health check failed"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,36 +3,78 @@ package docker
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
-	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"go.uber.org/zap"
 )
 
+// Client операции Docker Engine API, которые использует адаптер
+type Client interface {
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error)
+	ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error
+	ContainerStop(ctx context.Context, containerID string, options container.StopOptions) error
+	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
+	ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error)
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	ContainerRename(ctx context.Context, containerID, newContainerName string) error
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, container.PathStat, error)
+	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options container.CopyToContainerOptions) error
+	ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
+	Ping(ctx context.Context) (types.Ping, error)
+	Close() error
+}
+
 // DockerAdapter адаптер для работы с Docker
 type DockerAdapter struct {
-	client *client.Client
+	client Client
 	logger *zap.Logger
 }
 
 // NewDockerAdapter создает новый Docker адаптер
 func NewDockerAdapter(host string, apiVersion string, timeout time.Duration, logger *zap.Logger) (*DockerAdapter, error) {
-	cli, err := client.NewClientWithOpts(
+	cli, err := NewClient(host, apiVersion, timeout, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDockerAdapterWithClient(cli, logger), nil
+}
+
+// NewClient создает клиент Docker Engine API. Если заданы пути к
+// сертификатам, соединение защищается TLS с проверкой сертификата сервера
+func NewClient(host, apiVersion string, timeout time.Duration, caCert, cert, key string) (*client.Client, error) {
+	opts := []client.Opt{
 		client.WithHost(host),
 		client.WithVersion(apiVersion),
 		client.WithTimeout(timeout),
-	)
+	}
+	if caCert != "" || cert != "" || key != "" {
+		opts = append(opts, client.WithTLSClientConfig(caCert, cert, key))
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create docker client: %w", err)
 	}
+	return cli, nil
+}
 
+// NewDockerAdapterWithClient создает Docker адаптер с готовым клиентом
+func NewDockerAdapterWithClient(cli Client, logger *zap.Logger) *DockerAdapter {
 	return &DockerAdapter{
 		client: cli,
 		logger: logger,
-	}, nil
+	}
 }
 
 // CreateContainer создает контейнер
@@ -56,6 +98,19 @@ func (d *DockerAdapter) CreateContainer(ctx context.Context, name, image string,
 		containerConfig.Cmd = cmd
 	}
 
+	// Метки позволяют найти контейнеры сервиса
+	if labels, ok := config["labels"].(map[string]string); ok {
+		containerConfig.Labels = labels
+	}
+
+	// Анонимные тома переживают перезапуск и удаляются вместе с контейнером
+	if volumes, ok := config["volumes"].([]string); ok {
+		containerConfig.Volumes = make(map[string]struct{}, len(volumes))
+		for _, volume := range volumes {
+			containerConfig.Volumes[volume] = struct{}{}
+		}
+	}
+
 	// Создаем контейнер
 	resp, err := d.client.ContainerCreate(ctx, containerConfig, nil, nil, nil, name)
 	if err != nil {
@@ -98,10 +153,11 @@ func (d *DockerAdapter) StopContainer(ctx context.Context, containerID string, t
 	return nil
 }
 
-// RemoveContainer удаляет контейнер
+// RemoveContainer удаляет контейнер вместе с его анонимными томами
 func (d *DockerAdapter) RemoveContainer(ctx context.Context, containerID string, force bool) error {
 	err := d.client.ContainerRemove(ctx, containerID, container.RemoveOptions{
-		Force: force,
+		Force:         force,
+		RemoveVolumes: true,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to remove container: %w", err)
@@ -111,53 +167,8 @@ func (d *DockerAdapter) RemoveContainer(ctx context.Context, containerID string,
 	return nil
 }
 
-// GetContainerStats получает статистику контейнера
-func (d *DockerAdapter) GetContainerStats(ctx context.Context, containerID string) (*domain.ServerStats, error) {
-	stats, err := d.client.ContainerStats(ctx, containerID, false)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get container stats: %w", err)
-	}
-	defer stats.Body.Close()
-
-	// Парсим статистику (упрощенно)
-	// В реальной реализации нужно парсить JSON из stats.Body
-	statsData := &domain.ServerStats{
-		ServerID:     containerID,
-		CPUUsage:     0.0, // TODO: парсить из stats
-		MemoryUsage:  0.0, // TODO: парсить из stats
-		StorageUsage: 0.0, // TODO: парсить из stats
-		NetworkIn:    0,   // TODO: парсить из stats
-		NetworkOut:   0,   // TODO: парсить из stats
-		Uptime:       0,   // TODO: парсить из stats
-		RequestCount: 0,   // TODO: парсить из stats
-		ResponseTime: 0.0, // TODO: парсить из stats
-		ErrorRate:    0.0, // TODO: парсить из stats
-		Timestamp:    time.Now(),
-	}
-
-	return statsData, nil
-}
-
-// GetContainerHealth получает здоровье контейнера
-func (d *DockerAdapter) GetContainerHealth(ctx context.Context, containerID string) (*domain.ServerHealth, error) {
-	inspect, err := d.client.ContainerInspect(ctx, containerID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to inspect container: %w", err)
-	}
-
-	health := &domain.ServerHealth{
-		ServerID:    containerID,
-		Status:      domain.ServerStatus(inspect.State.Status),
-		Message:     inspect.State.Status,
-		LastCheckAt: time.Now(),
-		Checks:      []map[string]interface{}{},
-	}
-
-	return health, nil
-}
-
 // ListContainers получает список контейнеров
-func (d *DockerAdapter) ListContainers(ctx context.Context) ([]types.Container, error) {
+func (d *DockerAdapter) ListContainers(ctx context.Context) ([]container.Summary, error) {
 	containers, err := d.client.ContainerList(ctx, container.ListOptions{All: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list containers: %w", err)
@@ -166,6 +177,14 @@ func (d *DockerAdapter) ListContainers(ctx context.Context) ([]types.Container,
 	return containers, nil
 }
 
+// Ping проверяет доступность Docker Engine
+func (d *DockerAdapter) Ping(ctx context.Context) error {
+	if _, err := d.client.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping docker: %w", err)
+	}
+	return nil
+}
+
 // Close закрывает соединение с Docker
 func (d *DockerAdapter) Close() error {
 	return d.client.Close()
@@ -0,0 +1,67 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/zap"
+)
+
+// ArchiveDirectory пишет в w tar-архив каталога dir контейнера. Docker
+// отдает записи с префиксом имени каталога, он отрезается, чтобы пути
+// были относительны dir
+func (d *DockerAdapter) ArchiveDirectory(ctx context.Context, containerID, dir string, w io.Writer) error {
+	content, _, err := d.client.CopyFromContainer(ctx, containerID, dir)
+	if err != nil {
+		return fmt.Errorf("failed to copy from container: %w", err)
+	}
+	defer content.Close()
+
+	reader := tar.NewReader(content)
+	writer := tar.NewWriter(w)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read container archive: %w", err)
+		}
+
+		// Запись самого каталога пропускаем
+		_, name, found := strings.Cut(header.Name, "/")
+		if !found || name == "" {
+			continue
+		}
+		header.Name = name
+
+		if err := writer.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := io.Copy(writer, reader); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	d.logger.Info("container directory archived", zap.String("id", containerID), zap.String("dir", dir))
+	return nil
+}
+
+// ExtractArchive распаковывает tar-архив из r в каталог dir контейнера
+func (d *DockerAdapter) ExtractArchive(ctx context.Context, containerID, dir string, r io.Reader) error {
+	err := d.client.CopyToContainer(ctx, containerID, dir, r, container.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to copy to container: %w", err)
+	}
+
+	d.logger.Info("archive extracted to container", zap.String("id", containerID), zap.String("dir", dir))
+	return nil
+}
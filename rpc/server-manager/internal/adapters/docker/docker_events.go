@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"go.uber.org/zap"
+)
+
+// WatchContainers передает события завершения, OOM и перезапуска
+// контейнеров с меткой label (key=value). Канал закрывается при отмене ctx
+// или обрыве потока событий
+func (d *DockerAdapter) WatchContainers(ctx context.Context, label string) <-chan events.Message {
+	messages, errs := d.client.Events(ctx, events.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", string(events.ContainerEventType)),
+			filters.Arg("label", label),
+			filters.Arg("event", string(events.ActionDie)),
+			filters.Arg("event", string(events.ActionOOM)),
+			filters.Arg("event", string(events.ActionRestart)),
+		),
+	})
+
+	out := make(chan events.Message)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errs:
+				if ctx.Err() == nil {
+					d.logger.Warn("docker event stream closed", zap.Error(err))
+				}
+				return
+			case message := <-messages:
+				select {
+				case out <- message:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/zap"
+)
+
+// MoveContainer переносит контейнер на Docker Engine target с тем же
+// образом, именем, окружением, метками и содержимым томов, возвращает ID
+// нового контейнера. Исходный контейнер останавливается на время
+// копирования томов и удаляется только после запуска нового, при ошибке
+// он запускается снова
+func (d *DockerAdapter) MoveContainer(ctx context.Context, containerID string, target *DockerAdapter) (string, error) {
+	inspect, err := d.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if inspect.ContainerJSONBase == nil || inspect.Config == nil {
+		return "", fmt.Errorf("container %s has no config", containerID)
+	}
+	name := strings.TrimPrefix(inspect.Name, "/")
+	running := inspect.State != nil && inspect.State.Running
+
+	if err := target.PullImage(ctx, inspect.Config.Image); err != nil {
+		return "", err
+	}
+
+	// Данные копируются с остановленного контейнера, чтобы снимок томов
+	// был согласованным
+	if running {
+		if err := d.StopContainer(ctx, containerID, nil); err != nil {
+			return "", err
+		}
+	}
+
+	config := &container.Config{
+		Image:   inspect.Config.Image,
+		Cmd:     inspect.Config.Cmd,
+		Env:     inspect.Config.Env,
+		Labels:  inspect.Config.Labels,
+		Volumes: inspect.Config.Volumes,
+	}
+	resp, err := target.client.ContainerCreate(ctx, config, nil, nil, nil, name)
+	if err != nil {
+		d.restoreMovedContainer(ctx, containerID, target, "", running)
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	for volume := range inspect.Config.Volumes {
+		if err := d.copyVolume(ctx, containerID, target, resp.ID, volume); err != nil {
+			d.restoreMovedContainer(ctx, containerID, target, resp.ID, running)
+			return "", err
+		}
+	}
+
+	if running {
+		if err := target.StartContainer(ctx, resp.ID); err != nil {
+			d.restoreMovedContainer(ctx, containerID, target, resp.ID, running)
+			return "", err
+		}
+	}
+
+	if err := d.RemoveContainer(ctx, containerID, true); err != nil {
+		d.logger.Warn("failed to remove moved container", zap.String("id", containerID), zap.Error(err))
+	}
+
+	d.logger.Info("container moved",
+		zap.String("old_id", containerID),
+		zap.String("id", resp.ID),
+		zap.String("name", name))
+	return resp.ID, nil
+}
+
+// restoreMovedContainer откатывает неудачный перенос: удаляет созданный на
+// target контейнер moved и запускает исходный, если он работал
+func (d *DockerAdapter) restoreMovedContainer(ctx context.Context, containerID string, target *DockerAdapter, moved string, running bool) {
+	// Откат выполняется и после отмены контекста переноса
+	ctx = context.WithoutCancel(ctx)
+
+	if moved != "" {
+		if err := target.RemoveContainer(ctx, moved, true); err != nil {
+			target.logger.Warn("failed to remove moved container", zap.String("id", moved), zap.Error(err))
+		}
+	}
+	if running {
+		if err := d.StartContainer(ctx, containerID); err != nil {
+			d.logger.Error("failed to restart container after move failure", zap.String("id", containerID), zap.Error(err))
+		}
+	}
+}
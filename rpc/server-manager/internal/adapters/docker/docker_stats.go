@@ -0,0 +1,149 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+)
+
+// GetContainerStats получает статистику контейнера: загрузку CPU и памяти в
+// процентах, сетевой трафик и время работы
+func (d *DockerAdapter) GetContainerStats(ctx context.Context, containerID string) (*domain.ServerStats, error) {
+	inspect, err := d.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	stats := &domain.ServerStats{
+		ServerID:  containerID,
+		Timestamp: time.Now(),
+	}
+	if inspect.State == nil || !inspect.State.Running {
+		return stats, nil
+	}
+	if startedAt, err := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); err == nil {
+		stats.Uptime = int64(time.Since(startedAt).Seconds())
+	}
+
+	reader, err := d.client.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	defer reader.Body.Close()
+
+	var sample container.StatsResponse
+	if err := json.NewDecoder(reader.Body).Decode(&sample); err != nil {
+		return nil, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+
+	stats.CPUUsage = cpuPercent(&sample)
+	stats.MemoryUsage = memoryPercent(&sample.MemoryStats)
+	for _, network := range sample.Networks {
+		stats.NetworkIn += int64(network.RxBytes)
+		stats.NetworkOut += int64(network.TxBytes)
+	}
+
+	return stats, nil
+}
+
+// cpuPercent загрузка CPU между двумя замерами, как в docker stats
+func cpuPercent(sample *container.StatsResponse) float64 {
+	cpuDelta := float64(sample.CPUStats.CPUUsage.TotalUsage) - float64(sample.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(sample.CPUStats.SystemUsage) - float64(sample.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	cpus := float64(sample.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(sample.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * cpus * 100
+}
+
+// memoryPercent использование памяти без страничного кэша относительно лимита
+func memoryPercent(memory *container.MemoryStats) float64 {
+	if memory.Limit == 0 {
+		return 0
+	}
+
+	used := memory.Usage
+	// cgroup v2 сообщает inactive_file, cgroup v1 - cache
+	if cache, ok := memory.Stats["inactive_file"]; ok && cache < used {
+		used -= cache
+	} else if cache, ok := memory.Stats["cache"]; ok && cache < used {
+		used -= cache
+	}
+	return float64(used) / float64(memory.Limit) * 100
+}
+
+// GetContainerHealth получает здоровье контейнера
+func (d *DockerAdapter) GetContainerHealth(ctx context.Context, containerID string) (*domain.ServerHealth, error) {
+	inspect, err := d.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	health := &domain.ServerHealth{
+		ServerID:    containerID,
+		Status:      domain.ServerStatusError,
+		Message:     "container state is unknown",
+		LastCheckAt: time.Now(),
+		Checks:      []map[string]interface{}{},
+	}
+	if inspect.State == nil {
+		return health, nil
+	}
+
+	health.Status = ContainerStatus(inspect.State.Status)
+	health.Message = inspect.State.Status
+	if inspect.State.Error != "" {
+		health.Message = inspect.State.Error
+	}
+
+	// HEALTHCHECK образа уточняет состояние работающего контейнера
+	if check := inspect.State.Health; check != nil {
+		message := ""
+		if len(check.Log) > 0 {
+			message = check.Log[len(check.Log)-1].Output
+		}
+		health.Checks = append(health.Checks, map[string]interface{}{
+			"name":    "healthcheck",
+			"status":  check.Status,
+			"message": message,
+		})
+		switch check.Status {
+		case container.Unhealthy:
+			health.Status = domain.ServerStatusError
+			health.Message = "container is unhealthy"
+		case container.Starting:
+			// Проверки еще не прошли после запуска
+			if health.Status == domain.ServerStatusRunning {
+				health.Status = domain.ServerStatusCreating
+				health.Message = "container health check is starting"
+			}
+		}
+	}
+
+	return health, nil
+}
+
+// ContainerStatus переводит состояние контейнера в статус сервера
+func ContainerStatus(state container.ContainerState) domain.ServerStatus {
+	switch state {
+	case container.StateRunning:
+		return domain.ServerStatusRunning
+	case container.StateCreated, container.StateExited, container.StatePaused:
+		return domain.ServerStatusStopped
+	case container.StateRestarting:
+		return domain.ServerStatusCreating
+	case container.StateRemoving:
+		return domain.ServerStatusDeleting
+	default:
+		return domain.ServerStatusError
+	}
+}
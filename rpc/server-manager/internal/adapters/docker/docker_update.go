@@ -0,0 +1,178 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"go.uber.org/zap"
+)
+
+// pullMessage сообщение потока прогресса загрузки образа
+type pullMessage struct {
+	Error string `json:"error"`
+}
+
+// PullImage загружает образ. Ошибки загрузки Docker передает в потоке
+// прогресса, поэтому поток читается до конца
+func (d *DockerAdapter) PullImage(ctx context.Context, ref string) error {
+	body, err := d.client.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	for {
+		var message pullMessage
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("failed to pull image: %w", err)
+		}
+		if message.Error != "" {
+			return fmt.Errorf("failed to pull image: %s", message.Error)
+		}
+	}
+
+	d.logger.Info("image pulled", zap.String("image", ref))
+	return nil
+}
+
+// ReplaceContainer заменяет контейнер новым из образа ref с тем же именем,
+// окружением, метками и содержимым томов, возвращает ID нового контейнера.
+// Старый контейнер переименовывается и удаляется только после запуска
+// нового, при ошибке он возвращается на место
+func (d *DockerAdapter) ReplaceContainer(ctx context.Context, containerID, ref string) (string, error) {
+	if err := d.PullImage(ctx, ref); err != nil {
+		return "", err
+	}
+	return d.replaceContainer(ctx, containerID, ref, true)
+}
+
+// ResetContainer заменяет контейнер таким же с пустыми анонимными томами и
+// возвращает ID нового контейнера. Docker не удаляет файлы из контейнера
+// через API, поэтому каталог данных очищается заменой тома
+func (d *DockerAdapter) ResetContainer(ctx context.Context, containerID string) (string, error) {
+	return d.replaceContainer(ctx, containerID, "", false)
+}
+
+// replaceContainer заменяет контейнер новым из образа ref (пустой - образ
+// прежнего контейнера), копируя содержимое томов при copyVolumes
+func (d *DockerAdapter) replaceContainer(ctx context.Context, containerID, ref string, copyVolumes bool) (string, error) {
+	inspect, err := d.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if inspect.ContainerJSONBase == nil || inspect.Config == nil {
+		return "", fmt.Errorf("container %s has no config", containerID)
+	}
+	if ref == "" {
+		ref = inspect.Config.Image
+	}
+	name := strings.TrimPrefix(inspect.Name, "/")
+	running := inspect.State != nil && inspect.State.Running
+
+	if running {
+		if err := d.StopContainer(ctx, containerID, nil); err != nil {
+			return "", err
+		}
+	}
+
+	// Имя освобождается для нового контейнера
+	previousName := fmt.Sprintf("%s-%.12s", name, containerID)
+	if err := d.client.ContainerRename(ctx, containerID, previousName); err != nil {
+		d.restoreContainer(ctx, containerID, "", "", running)
+		return "", fmt.Errorf("failed to rename container: %w", err)
+	}
+
+	config := &container.Config{
+		Image:   ref,
+		Cmd:     []string{},
+		Env:     inspect.Config.Env,
+		Labels:  inspect.Config.Labels,
+		Volumes: inspect.Config.Volumes,
+	}
+	resp, err := d.client.ContainerCreate(ctx, config, nil, nil, nil, name)
+	if err != nil {
+		d.restoreContainer(ctx, containerID, name, "", running)
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	// Анонимные тома нового контейнера пусты, данные копируются
+	if copyVolumes {
+		for volume := range inspect.Config.Volumes {
+			if err := d.copyVolume(ctx, containerID, d, resp.ID, volume); err != nil {
+				d.restoreContainer(ctx, containerID, name, resp.ID, running)
+				return "", err
+			}
+		}
+	}
+
+	if running {
+		if err := d.StartContainer(ctx, resp.ID); err != nil {
+			d.restoreContainer(ctx, containerID, name, resp.ID, running)
+			return "", err
+		}
+	}
+
+	if err := d.RemoveContainer(ctx, containerID, true); err != nil {
+		d.logger.Warn("failed to remove replaced container", zap.String("id", containerID), zap.Error(err))
+	}
+
+	d.logger.Info("container replaced",
+		zap.String("old_id", containerID),
+		zap.Bool("volumes_copied", copyVolumes),
+		zap.String("id", resp.ID),
+		zap.String("image", ref))
+	return resp.ID, nil
+}
+
+// copyVolume копирует каталог volume из контейнера src в контейнер dst
+// Docker Engine target
+func (d *DockerAdapter) copyVolume(ctx context.Context, src string, target *DockerAdapter, dst, volume string) error {
+	content, _, err := d.client.CopyFromContainer(ctx, src, volume)
+	if err != nil {
+		return fmt.Errorf("failed to copy volume %s: %w", volume, err)
+	}
+	defer content.Close()
+
+	// Записи архива начинаются с имени каталога, поэтому он
+	// распаковывается в родительский каталог
+	err = target.client.CopyToContainer(ctx, dst, path.Dir(volume), content, container.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to copy volume %s: %w", volume, err)
+	}
+	return nil
+}
+
+// restoreContainer возвращает прежний контейнер после неудачной замены:
+// удаляет созданный replacement, возвращает имя name и запускает, если он
+// работал
+func (d *DockerAdapter) restoreContainer(ctx context.Context, containerID, name, replacement string, running bool) {
+	// Откат выполняется и после отмены контекста замены
+	ctx = context.WithoutCancel(ctx)
+
+	if replacement != "" {
+		if err := d.RemoveContainer(ctx, replacement, true); err != nil {
+			d.logger.Warn("failed to remove replacement container", zap.String("id", replacement), zap.Error(err))
+		}
+	}
+	if name != "" {
+		if err := d.client.ContainerRename(ctx, containerID, name); err != nil {
+			d.logger.Error("failed to restore container name", zap.String("id", containerID), zap.Error(err))
+		}
+	}
+	if running {
+		if err := d.StartContainer(ctx, containerID); err != nil {
+			d.logger.Error("failed to restart replaced container", zap.String("id", containerID), zap.Error(err))
+		}
+	}
+}
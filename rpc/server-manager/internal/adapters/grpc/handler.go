@@ -2,6 +2,8 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/par1ram/silence/rpc/server-manager/api/proto"
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
@@ -43,13 +45,17 @@ func (h *ServerManagerHandler) CreateServer(ctx context.Context, req *proto.Crea
 	h.logger.Debug("create server requested", zap.String("name", req.Name))
 
 	domainReq := &domain.CreateServerRequest{
-		Name:   req.Name,
-		Type:   h.convertServerType(req.Type),
-		Region: req.Region,
-		Config: req.Config,
+		Name:         req.Name,
+		Type:         h.convertServerType(req.Type),
+		Region:       req.Region,
+		Config:       req.Config,
+		NodeSelector: req.NodeSelector,
 	}
 
 	server, err := h.serverService.CreateServer(ctx, domainReq)
+	if errors.Is(err, domain.ErrNoCapacity) {
+		return nil, status.Errorf(codes.ResourceExhausted, "failed to create server: %v", err)
+	}
 	if err != nil {
 		h.logger.Error("failed to create server", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to create server: %v", err)
@@ -191,16 +197,7 @@ func (h *ServerManagerHandler) GetServerStats(ctx context.Context, req *proto.Ge
 		return nil, status.Errorf(codes.Internal, "failed to get server stats: %v", err)
 	}
 
-	return &proto.ServerStats{
-		ServerId:     stats.ServerID,
-		CpuUsage:     stats.CPUUsage,
-		MemoryUsage:  stats.MemoryUsage,
-		DiskUsage:    stats.StorageUsage,
-		NetworkUsage: 0.0, // Заглушка
-		Connections:  int32(stats.RequestCount),
-		Uptime:       stats.Uptime,
-		Timestamp:    timestamppb.New(stats.Timestamp),
-	}, nil
+	return h.domainStatsToProto(stats), nil
 }
 
 // GetServerHealth получает состояние здоровья сервера
@@ -213,36 +210,32 @@ func (h *ServerManagerHandler) GetServerHealth(ctx context.Context, req *proto.G
 		return nil, status.Errorf(codes.Internal, "failed to get server health: %v", err)
 	}
 
-	return &proto.ServerHealth{
-		ServerId:  health.ServerID,
-		Status:    string(health.Status),
-		Message:   health.Message,
-		Checks:    h.convertHealthChecksToProto(health.Checks),
-		Timestamp: timestamppb.New(health.LastCheckAt),
-	}, nil
+	return h.domainHealthToProto(health), nil
 }
 
 // MonitorServer мониторинг сервера (stream)
 func (h *ServerManagerHandler) MonitorServer(req *proto.MonitorServerRequest, stream proto.ServerManagerService_MonitorServerServer) error {
-	h.logger.Debug("monitor server requested", zap.String("id", req.Id))
+	h.logger.Debug("monitor server requested", zap.String("id", req.Id), zap.Int32("interval_seconds", req.IntervalSeconds))
 
-	// Заглушка для мониторинга - отправляем тестовое событие
 	ctx := stream.Context()
-
-	// Создаем тестовое событие
-	testEvent := &proto.ServerMonitorEvent{
-		ServerId:  req.Id,
-		EventType: "info",
-		Timestamp: timestamppb.Now(),
+	interval := time.Duration(req.IntervalSeconds) * time.Second
+	events, err := h.serverService.MonitorServer(ctx, req.Id, interval)
+	if err != nil {
+		h.logger.Error("failed to monitor server", zap.Error(err))
+		if errors.Is(err, domain.ErrServerNotFound) {
+			return status.Errorf(codes.NotFound, "failed to monitor server: %v", err)
+		}
+		return status.Errorf(codes.Internal, "failed to monitor server: %v", err)
 	}
 
-	if err := stream.Send(testEvent); err != nil {
-		h.logger.Error("failed to send monitor event", zap.Error(err))
-		return err
+	// Канал закрывается при отключении клиента или удалении сервера
+	for event := range events {
+		if err := stream.Send(h.domainMonitorEventToProto(event)); err != nil {
+			h.logger.Error("failed to send monitor event", zap.Error(err))
+			return err
+		}
 	}
 
-	// Ждем завершения контекста
-	<-ctx.Done()
 	return ctx.Err()
 }
 
@@ -322,8 +315,18 @@ func (h *ServerManagerHandler) GetServersByStatus(ctx context.Context, req *prot
 func (h *ServerManagerHandler) ScaleServer(ctx context.Context, req *proto.ScaleServerRequest) (*proto.ScaleServerResponse, error) {
 	h.logger.Debug("scale server requested", zap.String("id", req.Id))
 
-	// Заглушка для масштабирования - просто возвращаем успех
-	h.logger.Info("scale server requested", zap.String("id", req.Id))
+	if req.Spec == nil {
+		return nil, status.Error(codes.InvalidArgument, "spec is required")
+	}
+
+	err := h.serverService.ScaleServer(ctx, req.Id, req.Spec.Replicas)
+	if errors.Is(err, domain.ErrNotSupported) {
+		return nil, status.Errorf(codes.Unimplemented, "failed to scale server: %v", err)
+	}
+	if err != nil {
+		h.logger.Error("failed to scale server", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to scale server: %v", err)
+	}
 
 	return &proto.ScaleServerResponse{
 		Success: true,
@@ -331,12 +334,33 @@ func (h *ServerManagerHandler) ScaleServer(ctx context.Context, req *proto.Scale
 	}, nil
 }
 
+// EvaluateScaling оценивает политики масштабирования; в режиме dry run
+// решения только рассчитываются
+func (h *ServerManagerHandler) EvaluateScaling(ctx context.Context, req *proto.EvaluateScalingRequest) (*proto.EvaluateScalingResponse, error) {
+	h.logger.Debug("evaluate scaling requested", zap.Bool("dry_run", req.DryRun))
+
+	decisions, err := h.serverService.EvaluateScaling(ctx, req.DryRun)
+	if err != nil {
+		h.logger.Error("failed to evaluate scaling", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to evaluate scaling: %v", err)
+	}
+
+	result := make([]*proto.ScalingDecision, len(decisions))
+	for i, decision := range decisions {
+		result[i] = h.domainScalingDecisionToProto(decision)
+	}
+	return &proto.EvaluateScalingResponse{Decisions: result}, nil
+}
+
 // CreateBackup создает резервную копию сервера
 func (h *ServerManagerHandler) CreateBackup(ctx context.Context, req *proto.CreateBackupRequest) (*proto.CreateBackupResponse, error) {
 	h.logger.Debug("create backup requested", zap.String("server_id", req.ServerId))
 
-	// Заглушка для создания резервной копии
-	err := h.serverService.CreateBackup(ctx, req.ServerId)
+	backup, err := h.serverService.CreateBackup(ctx, &domain.CreateBackupRequest{
+		ServerID:    req.ServerId,
+		Description: req.Description,
+		Type:        domain.BackupType(req.BackupType),
+	})
 	if err != nil {
 		h.logger.Error("failed to create backup", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to create backup: %v", err)
@@ -344,17 +368,20 @@ func (h *ServerManagerHandler) CreateBackup(ctx context.Context, req *proto.Crea
 
 	return &proto.CreateBackupResponse{
 		Success:  true,
-		BackupId: "backup-" + req.ServerId,
+		BackupId: backup.ID,
 		Message:  "Backup created successfully",
 	}, nil
 }
 
-// RestoreBackup восстанавливает сервер из резервной копии
+// RestoreBackup восстанавливает сервер из резервной копии. Пустой
+// server_id - восстановление в новый сервер
 func (h *ServerManagerHandler) RestoreBackup(ctx context.Context, req *proto.RestoreBackupRequest) (*proto.RestoreBackupResponse, error) {
 	h.logger.Debug("restore backup requested", zap.String("backup_id", req.BackupId))
 
-	// Заглушка для восстановления резервной копии
-	err := h.serverService.RestoreBackup(ctx, req.ServerId, req.BackupId)
+	server, err := h.serverService.RestoreBackup(ctx, &domain.RestoreBackupRequest{
+		BackupID: req.BackupId,
+		ServerID: req.ServerId,
+	})
 	if err != nil {
 		h.logger.Error("failed to restore backup", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to restore backup: %v", err)
@@ -362,19 +389,29 @@ func (h *ServerManagerHandler) RestoreBackup(ctx context.Context, req *proto.Res
 
 	return &proto.RestoreBackupResponse{
 		Success: true,
-		Message: "Backup restored successfully",
+		Message: "Backup restored to server " + server.ID,
 	}, nil
 }
 
-// UpdateServerSoftware обновляет ПО сервера
+// UpdateServerSoftware запускает обновление ПО сервера или, без server_id,
+// всех серверов типа server_type. Обновление выполняется в фоне, его ход
+// доступен через GetServerSoftwareUpdate
 func (h *ServerManagerHandler) UpdateServerSoftware(ctx context.Context, req *proto.UpdateServerSoftwareRequest) (*proto.UpdateServerSoftwareResponse, error) {
-	h.logger.Debug("update server software requested", zap.String("server_id", req.ServerId))
+	h.logger.Debug("update server software requested",
+		zap.String("server_id", req.ServerId),
+		zap.String("version", req.Version))
 
-	// Заглушка для обновления ПО сервера
 	updateReq := &domain.UpdateRequest{
-		ServerID: req.ServerId,
-		Version:  req.Version,
-		Force:    req.Force,
+		ServerID:       req.ServerId,
+		Region:         req.Region,
+		Version:        req.Version,
+		Force:          req.Force,
+		CanaryPercent:  int(req.CanaryPercent),
+		MaxUnavailable: int(req.MaxUnavailable),
+		HealthTimeout:  time.Duration(req.HealthTimeoutSeconds) * time.Second,
+	}
+	if req.ServerType != proto.ServerType_SERVER_TYPE_UNSPECIFIED {
+		updateReq.ServerType = h.convertServerType(req.ServerType)
 	}
 
 	err := h.serverService.StartUpdate(ctx, updateReq)
@@ -383,12 +420,335 @@ func (h *ServerManagerHandler) UpdateServerSoftware(ctx context.Context, req *pr
 		return nil, status.Errorf(codes.Internal, "failed to update server software: %v", err)
 	}
 
-	return &proto.UpdateServerSoftwareResponse{
+	resp := &proto.UpdateServerSoftwareResponse{
+		Success: true,
+		Message: "Server software update started",
+	}
+	if req.ServerId != "" {
+		if updateStatus, err := h.serverService.GetUpdateStatus(ctx, req.ServerId); err == nil {
+			resp.Status = h.domainUpdateStatusToProto(updateStatus)
+		}
+	}
+	return resp, nil
+}
+
+// GetServerSoftwareUpdate получает статус последнего обновления ПО сервера
+func (h *ServerManagerHandler) GetServerSoftwareUpdate(ctx context.Context, req *proto.GetServerSoftwareUpdateRequest) (*proto.UpdateStatus, error) {
+	h.logger.Debug("get server software update requested", zap.String("server_id", req.ServerId))
+
+	updateStatus, err := h.serverService.GetUpdateStatus(ctx, req.ServerId)
+	if err != nil {
+		h.logger.Error("failed to get update status", zap.Error(err))
+		return nil, status.Errorf(codes.NotFound, "failed to get update status: %v", err)
+	}
+
+	return h.domainUpdateStatusToProto(updateStatus), nil
+}
+
+// CancelServerSoftwareUpdate отменяет обновление, в которое входит сервер
+func (h *ServerManagerHandler) CancelServerSoftwareUpdate(ctx context.Context, req *proto.CancelServerSoftwareUpdateRequest) (*proto.CancelServerSoftwareUpdateResponse, error) {
+	h.logger.Debug("cancel server software update requested", zap.String("server_id", req.ServerId))
+
+	if err := h.serverService.CancelUpdate(ctx, req.ServerId); err != nil {
+		h.logger.Error("failed to cancel update", zap.Error(err))
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to cancel update: %v", err)
+	}
+
+	return &proto.CancelServerSoftwareUpdateResponse{
+		Success: true,
+		Message: "Server software update cancelled",
+	}, nil
+}
+
+// ListRegions получает регионы размещения
+func (h *ServerManagerHandler) ListRegions(ctx context.Context, req *proto.ListRegionsRequest) (*proto.ListRegionsResponse, error) {
+	h.logger.Debug("list regions requested")
+
+	regions, err := h.serverService.ListRegions(ctx)
+	if err != nil {
+		h.logger.Error("failed to list regions", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to list regions: %v", err)
+	}
+
+	protoRegions := make([]*proto.Region, len(regions))
+	for i, region := range regions {
+		protoRegions[i] = h.domainRegionToProto(region)
+	}
+
+	return &proto.ListRegionsResponse{
+		Regions: protoRegions,
+	}, nil
+}
+
+// SaveRegion создает или обновляет регион
+func (h *ServerManagerHandler) SaveRegion(ctx context.Context, req *proto.Region) (*proto.Region, error) {
+	h.logger.Debug("save region requested", zap.String("code", req.Code))
+
+	region := &domain.Region{
+		Code:       req.Code,
+		Name:       req.Name,
+		Country:    req.Country,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+		MaxServers: int(req.MaxServers),
+		MaxLoad:    req.MaxLoad,
+		Enabled:    req.Enabled,
+	}
+	if err := h.serverService.SaveRegion(ctx, region); err != nil {
+		h.logger.Error("failed to save region", zap.Error(err))
+		return nil, status.Errorf(codes.InvalidArgument, "failed to save region: %v", err)
+	}
+
+	return h.domainRegionToProto(region), nil
+}
+
+// DeleteRegion удаляет регион без серверов
+func (h *ServerManagerHandler) DeleteRegion(ctx context.Context, req *proto.DeleteRegionRequest) (*proto.DeleteRegionResponse, error) {
+	h.logger.Debug("delete region requested", zap.String("code", req.Code))
+
+	if err := h.serverService.DeleteRegion(ctx, req.Code); err != nil {
+		h.logger.Error("failed to delete region", zap.Error(err))
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to delete region: %v", err)
+	}
+
+	return &proto.DeleteRegionResponse{
 		Success: true,
-		Message: "Server software updated successfully",
+		Message: "Region deleted successfully",
+	}, nil
+}
+
+// RecommendServer выбирает сервер для подключения пользователя
+func (h *ServerManagerHandler) RecommendServer(ctx context.Context, req *proto.RecommendServerRequest) (*proto.RecommendServerResponse, error) {
+	h.logger.Debug("recommend server requested",
+		zap.String("region", req.Region),
+		zap.String("country", req.Country))
+
+	placementReq := &domain.PlacementRequest{
+		Region:  req.Region,
+		Country: req.Country,
+	}
+	if req.ServerType != proto.ServerType_SERVER_TYPE_UNSPECIFIED {
+		placementReq.ServerType = h.convertServerType(req.ServerType)
+	}
+	if req.Location != nil {
+		placementReq.Latitude = req.Location.Latitude
+		placementReq.Longitude = req.Location.Longitude
+		placementReq.HasLocation = true
+	}
+
+	placement, err := h.serverService.RecommendServer(ctx, placementReq)
+	if errors.Is(err, domain.ErrNoCapacity) {
+		return nil, status.Errorf(codes.ResourceExhausted, "failed to recommend server: %v", err)
+	}
+	if err != nil {
+		h.logger.Error("failed to recommend server", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to recommend server: %v", err)
+	}
+
+	return &proto.RecommendServerResponse{
+		Server:             h.domainServerToProto(placement.Server),
+		Region:             h.domainRegionToProto(placement.Region),
+		Load:               placement.Load,
+		DistanceKm:         placement.DistanceKm,
+		EstimatedLatencyMs: placement.LatencyMs,
+		Reason:             placement.Reason,
 	}, nil
 }
 
+// RegisterNode добавляет или обновляет узел Docker Engine
+func (h *ServerManagerHandler) RegisterNode(ctx context.Context, req *proto.Node) (*proto.Node, error) {
+	h.logger.Debug("register node requested", zap.String("id", req.Id), zap.String("endpoint", req.Endpoint))
+
+	node := &domain.Node{
+		ID:        req.Id,
+		Endpoint:  req.Endpoint,
+		TLSCACert: req.TlsCaCert,
+		TLSCert:   req.TlsCert,
+		TLSKey:    req.TlsKey,
+		Region:    req.Region,
+		Labels:    req.Labels,
+		Capacity:  int(req.Capacity),
+	}
+	err := h.serverService.RegisterNode(ctx, node)
+	if errors.Is(err, domain.ErrNotSupported) {
+		return nil, status.Errorf(codes.Unimplemented, "failed to register node: %v", err)
+	}
+	if err != nil {
+		h.logger.Error("failed to register node", zap.Error(err))
+		return nil, status.Errorf(codes.InvalidArgument, "failed to register node: %v", err)
+	}
+
+	return h.domainNodeToProto(node), nil
+}
+
+// ListNodes получает узлы реестра
+func (h *ServerManagerHandler) ListNodes(ctx context.Context, req *proto.ListNodesRequest) (*proto.ListNodesResponse, error) {
+	h.logger.Debug("list nodes requested")
+
+	nodes, err := h.serverService.ListNodes(ctx)
+	if err != nil {
+		h.logger.Error("failed to list nodes", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to list nodes: %v", err)
+	}
+
+	protoNodes := make([]*proto.Node, len(nodes))
+	for i, node := range nodes {
+		protoNodes[i] = h.domainNodeToProto(node)
+	}
+
+	return &proto.ListNodesResponse{
+		Nodes: protoNodes,
+	}, nil
+}
+
+// RemoveNode удаляет узел без серверов
+func (h *ServerManagerHandler) RemoveNode(ctx context.Context, req *proto.RemoveNodeRequest) (*proto.RemoveNodeResponse, error) {
+	h.logger.Debug("remove node requested", zap.String("id", req.Id))
+
+	if err := h.serverService.RemoveNode(ctx, req.Id); err != nil {
+		h.logger.Error("failed to remove node", zap.Error(err))
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to remove node: %v", err)
+	}
+
+	return &proto.RemoveNodeResponse{
+		Success: true,
+		Message: "Node removed successfully",
+	}, nil
+}
+
+// CordonNode запрещает размещать на узле новые серверы
+func (h *ServerManagerHandler) CordonNode(ctx context.Context, req *proto.CordonNodeRequest) (*proto.Node, error) {
+	h.logger.Debug("cordon node requested", zap.String("id", req.Id))
+
+	if err := h.serverService.CordonNode(ctx, req.Id); err != nil {
+		h.logger.Error("failed to cordon node", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to cordon node: %v", err)
+	}
+
+	return h.getNode(ctx, req.Id)
+}
+
+// UncordonNode снова разрешает размещать на узле новые серверы
+func (h *ServerManagerHandler) UncordonNode(ctx context.Context, req *proto.UncordonNodeRequest) (*proto.Node, error) {
+	h.logger.Debug("uncordon node requested", zap.String("id", req.Id))
+
+	if err := h.serverService.UncordonNode(ctx, req.Id); err != nil {
+		h.logger.Error("failed to uncordon node", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to uncordon node: %v", err)
+	}
+
+	return h.getNode(ctx, req.Id)
+}
+
+// DrainNode переносит серверы узла на другие узлы
+func (h *ServerManagerHandler) DrainNode(ctx context.Context, req *proto.DrainNodeRequest) (*proto.Node, error) {
+	h.logger.Debug("drain node requested", zap.String("id", req.Id))
+
+	err := h.serverService.DrainNode(ctx, req.Id)
+	if errors.Is(err, domain.ErrNotSupported) {
+		return nil, status.Errorf(codes.Unimplemented, "failed to drain node: %v", err)
+	}
+	if errors.Is(err, domain.ErrNoCapacity) {
+		return nil, status.Errorf(codes.ResourceExhausted, "failed to drain node: %v", err)
+	}
+	if err != nil {
+		h.logger.Error("failed to drain node", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to drain node: %v", err)
+	}
+
+	return h.getNode(ctx, req.Id)
+}
+
+// GetDriftReport сверяет серверы с оркестратором без изменений
+func (h *ServerManagerHandler) GetDriftReport(ctx context.Context, req *proto.GetDriftReportRequest) (*proto.DriftReport, error) {
+	h.logger.Debug("drift report requested")
+
+	return h.reconcileServers(ctx, &domain.ReconcileRequest{
+		OrphanPolicy: domain.OrphanPolicy(req.OrphanPolicy),
+		DryRun:       true,
+	})
+}
+
+// ReconcileServers устраняет расхождения серверов с оркестратором
+func (h *ServerManagerHandler) ReconcileServers(ctx context.Context, req *proto.ReconcileServersRequest) (*proto.DriftReport, error) {
+	h.logger.Debug("reconcile servers requested",
+		zap.String("orphan_policy", req.OrphanPolicy),
+		zap.Bool("dry_run", req.DryRun))
+
+	return h.reconcileServers(ctx, &domain.ReconcileRequest{
+		OrphanPolicy: domain.OrphanPolicy(req.OrphanPolicy),
+		DryRun:       req.DryRun,
+	})
+}
+
+func (h *ServerManagerHandler) reconcileServers(ctx context.Context, req *domain.ReconcileRequest) (*proto.DriftReport, error) {
+	if !req.OrphanPolicy.Valid() {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown orphan policy: %s", req.OrphanPolicy)
+	}
+
+	report, err := h.serverService.ReconcileServers(ctx, req)
+	if err != nil {
+		h.logger.Error("failed to reconcile servers", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to reconcile servers: %v", err)
+	}
+
+	return h.domainDriftReportToProto(report), nil
+}
+
+// ProvisionServer подготавливает хост VPN по SSH и регистрирует его как сервер
+func (h *ServerManagerHandler) ProvisionServer(ctx context.Context, req *proto.ProvisionServerRequest) (*proto.Server, error) {
+	h.logger.Debug("provision server requested",
+		zap.String("name", req.Name),
+		zap.String("host", req.Host),
+		zap.String("mode", req.Mode))
+
+	server, err := h.serverService.ProvisionServer(ctx, &domain.ProvisionRequest{
+		Name:         req.Name,
+		Region:       req.Region,
+		Host:         req.Host,
+		SSHPort:      int(req.SshPort),
+		User:         req.User,
+		HostKey:      req.HostKey,
+		Mode:         domain.ProvisionMode(req.Mode),
+		BinaryURL:    req.BinaryUrl,
+		BinarySHA256: req.BinarySha256,
+		Image:        req.Image,
+		ListenPort:   int(req.ListenPort),
+		Env:          req.Env,
+	})
+	if errors.Is(err, domain.ErrNoCapacity) {
+		return nil, status.Errorf(codes.ResourceExhausted, "failed to provision server: %v", err)
+	}
+	if err != nil {
+		h.logger.Error("failed to provision server", zap.Error(err))
+		return nil, status.Errorf(codes.InvalidArgument, "failed to provision server: %v", err)
+	}
+
+	return h.domainServerToProto(server), nil
+}
+
+// GetServerProvision получает ход подготовки хоста сервера
+func (h *ServerManagerHandler) GetServerProvision(ctx context.Context, req *proto.GetServerProvisionRequest) (*proto.Provision, error) {
+	h.logger.Debug("get server provision requested", zap.String("server_id", req.ServerId))
+
+	provision, err := h.serverService.GetProvision(ctx, req.ServerId)
+	if err != nil {
+		h.logger.Error("failed to get server provision", zap.Error(err))
+		return nil, status.Errorf(codes.NotFound, "failed to get server provision: %v", err)
+	}
+
+	return h.domainProvisionToProto(provision), nil
+}
+
+// getNode получает узел после изменения состояния
+func (h *ServerManagerHandler) getNode(ctx context.Context, id string) (*proto.Node, error) {
+	node, err := h.serverService.GetNode(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to get node: %v", err)
+	}
+	return h.domainNodeToProto(node), nil
+}
+
 // Helper methods for type conversion
 
 func (h *ServerManagerHandler) convertServerType(protoType proto.ServerType) domain.ServerType {
@@ -418,6 +778,8 @@ func (h *ServerManagerHandler) convertServerStatus(protoStatus proto.ServerStatu
 		return domain.ServerStatusError
 	case proto.ServerStatus_SERVER_STATUS_DELETING:
 		return domain.ServerStatusDeleting
+	case proto.ServerStatus_SERVER_STATUS_UPDATING:
+		return domain.ServerStatusUpdating
 	default:
 		return domain.ServerStatusStopped
 	}
@@ -450,6 +812,8 @@ func (h *ServerManagerHandler) convertServerStatusToProto(domainStatus domain.Se
 		return proto.ServerStatus_SERVER_STATUS_ERROR
 	case domain.ServerStatusDeleting:
 		return proto.ServerStatus_SERVER_STATUS_DELETING
+	case domain.ServerStatusUpdating:
+		return proto.ServerStatus_SERVER_STATUS_UPDATING
 	default:
 		return proto.ServerStatus_SERVER_STATUS_STOPPED
 	}
@@ -475,9 +839,163 @@ func (h *ServerManagerHandler) domainServerToProto(server *domain.Server) *proto
 		Config:    make(map[string]string),
 		CreatedAt: timestamppb.New(server.CreatedAt),
 		UpdatedAt: timestamppb.New(server.UpdatedAt),
+		NodeId:    server.NodeID,
+	}
+}
+
+func (h *ServerManagerHandler) domainStatsToProto(stats *domain.ServerStats) *proto.ServerStats {
+	return &proto.ServerStats{
+		ServerId:     stats.ServerID,
+		CpuUsage:     stats.CPUUsage,
+		MemoryUsage:  stats.MemoryUsage,
+		DiskUsage:    stats.StorageUsage,
+		NetworkUsage: 0.0, // Заглушка
+		Connections:  int32(stats.RequestCount),
+		Uptime:       stats.Uptime,
+		Timestamp:    timestamppb.New(stats.Timestamp),
+	}
+}
+
+func (h *ServerManagerHandler) domainHealthToProto(health *domain.ServerHealth) *proto.ServerHealth {
+	return &proto.ServerHealth{
+		ServerId:  health.ServerID,
+		Status:    string(health.Status),
+		Message:   health.Message,
+		Checks:    h.convertHealthChecksToProto(health.Checks),
+		Timestamp: timestamppb.New(health.LastCheckAt),
 	}
 }
 
+func (h *ServerManagerHandler) domainMonitorEventToProto(event *domain.ServerMonitorEvent) *proto.ServerMonitorEvent {
+	result := &proto.ServerMonitorEvent{
+		ServerId:  event.ServerID,
+		EventType: h.convertMonitorEventTypeToProto(event.Type),
+		Message:   event.Message,
+		Timestamp: timestamppb.New(event.Timestamp),
+	}
+	if event.Status != "" {
+		result.Status = h.convertServerStatusToProto(event.Status)
+	}
+	if event.Stats != nil {
+		result.Stats = h.domainStatsToProto(event.Stats)
+	}
+	if event.Health != nil {
+		result.Health = h.domainHealthToProto(event.Health)
+	}
+	return result
+}
+
+func (h *ServerManagerHandler) domainRegionToProto(region *domain.Region) *proto.Region {
+	return &proto.Region{
+		Code:       region.Code,
+		Name:       region.Name,
+		Country:    region.Country,
+		Latitude:   region.Latitude,
+		Longitude:  region.Longitude,
+		MaxServers: int32(region.MaxServers),
+		MaxLoad:    region.MaxLoad,
+		Enabled:    region.Enabled,
+		CreatedAt:  timestamppb.New(region.CreatedAt),
+		UpdatedAt:  timestamppb.New(region.UpdatedAt),
+	}
+}
+
+func (h *ServerManagerHandler) domainNodeToProto(node *domain.Node) *proto.Node {
+	result := &proto.Node{
+		Id:        node.ID,
+		Endpoint:  node.Endpoint,
+		TlsCaCert: node.TLSCACert,
+		TlsCert:   node.TLSCert,
+		TlsKey:    node.TLSKey,
+		Region:    node.Region,
+		Labels:    node.Labels,
+		Capacity:  int32(node.Capacity),
+		State:     string(node.State),
+		Healthy:   node.Healthy,
+		Message:   node.Message,
+		CreatedAt: timestamppb.New(node.CreatedAt),
+		UpdatedAt: timestamppb.New(node.UpdatedAt),
+		Kind:      string(node.Kind),
+	}
+	if node.LastCheckAt != nil {
+		result.LastCheckAt = timestamppb.New(*node.LastCheckAt)
+	}
+	return result
+}
+
+func (h *ServerManagerHandler) domainDriftReportToProto(report *domain.DriftReport) *proto.DriftReport {
+	drifts := make([]*proto.Drift, len(report.Drifts))
+	for i, drift := range report.Drifts {
+		drifts[i] = &proto.Drift{
+			ServerId: drift.ServerID,
+			Name:     drift.Name,
+			NodeId:   drift.NodeID,
+			Handle:   drift.Handle,
+			Type:     string(drift.Type),
+			Expected: drift.Expected,
+			Actual:   drift.Actual,
+			Action:   string(drift.Action),
+			Applied:  drift.Applied,
+			Error:    drift.Error,
+		}
+	}
+	return &proto.DriftReport{
+		Drifts:       drifts,
+		Checked:      int32(report.Checked),
+		Skipped:      int32(report.Skipped),
+		OrphanPolicy: string(report.OrphanPolicy),
+		DryRun:       report.DryRun,
+		CheckedAt:    timestamppb.New(report.CheckedAt),
+	}
+}
+
+func (h *ServerManagerHandler) domainScalingDecisionToProto(decision *domain.ScalingDecision) *proto.ScalingDecision {
+	return &proto.ScalingDecision{
+		PolicyId:      decision.PolicyID,
+		ServerType:    h.convertServerTypeToProto(decision.ServerType),
+		Region:        decision.Region,
+		Action:        string(decision.Action),
+		Reason:        decision.Reason,
+		ServersBefore: int32(decision.ServersBefore),
+		ServersAfter:  int32(decision.ServersAfter),
+		CpuUsage:      decision.CPUUsage,
+		MemoryUsage:   decision.MemoryUsage,
+		DryRun:        decision.DryRun,
+		TriggeredAt:   timestamppb.New(decision.TriggeredAt),
+	}
+}
+
+func (h *ServerManagerHandler) domainUpdateStatusToProto(updateStatus *domain.UpdateStatus) *proto.UpdateStatus {
+	result := &proto.UpdateStatus{
+		ServerId:  updateStatus.ServerID,
+		Status:    updateStatus.Status,
+		Progress:  int32(updateStatus.Progress),
+		Message:   updateStatus.Message,
+		StartedAt: timestamppb.New(updateStatus.StartedAt),
+	}
+	if updateStatus.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*updateStatus.CompletedAt)
+	}
+	return result
+}
+
+func (h *ServerManagerHandler) domainProvisionToProto(provision *domain.Provision) *proto.Provision {
+	result := &proto.Provision{
+		ServerId:  provision.ServerID,
+		Host:      provision.Host,
+		Mode:      string(provision.Mode),
+		Status:    provision.Status,
+		Step:      string(provision.Step),
+		Progress:  int32(provision.Progress),
+		Message:   provision.Message,
+		StartedAt: timestamppb.New(provision.StartedAt),
+	}
+	if provision.CompletedAt != nil {
+		result.CompletedAt = timestamppb.New(*provision.CompletedAt)
+	}
+	return result
+}
+
 func (h *ServerManagerHandler) convertHealthChecksToProto(checks []map[string]interface{}) []*proto.HealthCheck {
 	protoChecks := make([]*proto.HealthCheck, len(checks))
 	for i, check := range checks {
@@ -3,11 +3,13 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -17,9 +19,25 @@ import (
 	"k8s.io/utils/ptr"
 )
 
-// KubernetesAdapter адаптер для работы с Kubernetes
+const (
+	// managedLabel метка ресурсов, созданных server-manager
+	managedLabel = "managed"
+	managedValue = "server-manager"
+	// serverIDLabel метка с ID сервера в базе данных
+	serverIDLabel = "server-id"
+	// dataVolumeSize размер PersistentVolumeClaim с данными сервера
+	dataVolumeSize = "1Gi"
+)
+
+// KubernetesAdapter адаптер для работы с Kubernetes. Сервер представлен
+// Deployment и Service с одинаковым именем, которое служит handle, и
+// PersistentVolumeClaim <handle>-data с каталогом данных. Том ReadWriteOnce
+// подключается к одному поду, поэтому у сервера не больше одной реплики, а
+// поды при обновлении заменяются стратегией Recreate.
 type KubernetesAdapter struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
+	metrics   MetricsSource
+	executor  PodExecutor
 	namespace string
 	logger    *zap.Logger
 }
@@ -45,45 +63,101 @@ func NewKubernetesAdapter(kubeconfig string, namespace string, logger *zap.Logge
 		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
+	metrics := NewRESTMetricsSource(clientset.Discovery().RESTClient())
+	executor := NewSPDYExecutor(config, clientset.CoreV1().RESTClient())
+	return NewKubernetesAdapterWithClient(clientset, metrics, executor, namespace, logger), nil
+}
+
+// NewKubernetesAdapterWithClient создает Kubernetes адаптер с готовыми
+// клиентами API, метрик и exec в поды
+func NewKubernetesAdapterWithClient(clientset kubernetes.Interface, metrics MetricsSource, executor PodExecutor, namespace string, logger *zap.Logger) *KubernetesAdapter {
 	return &KubernetesAdapter{
 		clientset: clientset,
+		metrics:   metrics,
+		executor:  executor,
 		namespace: namespace,
 		logger:    logger,
-	}, nil
+	}
 }
 
-// CreateServer создает сервер в Kubernetes (Deployment + Service)
-func (k *KubernetesAdapter) CreateServer(ctx context.Context, server *domain.Server) error {
+// CreateServer создает сервер в Kubernetes (Deployment + Service) и
+// возвращает имя Deployment
+func (k *KubernetesAdapter) CreateServer(ctx context.Context, server *domain.Server, spec *domain.ServerSpec) (string, error) {
+	name := server.ResourceName()
+
+	// Создаем том с данными сервера
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dataClaimName(name),
+			Namespace: k.namespace,
+			Labels: map[string]string{
+				"app":         name,
+				managedLabel:  managedValue,
+				serverIDLabel: server.ID,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(dataVolumeSize),
+				},
+			},
+		},
+	}
+
+	_, err := k.clientset.CoreV1().PersistentVolumeClaims(k.namespace).Create(ctx, claim, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create persistent volume claim: %w", err)
+	}
+
 	// Создаем Deployment
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      server.Name,
+			Name:      name,
 			Namespace: k.namespace,
 			Labels: map[string]string{
-				"app":     server.Name,
-				"type":    string(server.Type),
-				"region":  server.Region,
-				"managed": "server-manager",
+				"app":         name,
+				"type":        string(server.Type),
+				"region":      server.Region,
+				managedLabel:  managedValue,
+				serverIDLabel: server.ID,
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: ptr.To(int32(1)),
+			// Новый под не получит том, пока его держит прежний:
+			// RollingUpdate ждал бы его бесконечно
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
-					"app": server.Name,
+					"app": name,
 				},
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
+					// Метки сервера позволяют отслеживать события подов
 					Labels: map[string]string{
-						"app": server.Name,
+						"app":         name,
+						managedLabel:  managedValue,
+						serverIDLabel: server.ID,
 					},
 				},
 				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: dataClaimName(name),
+								},
+							},
+						},
+					},
 					Containers: []corev1.Container{
 						{
-							Name:  server.Name,
-							Image: "silence/vpn-core:latest",
+							Name:  name,
+							Image: spec.Image,
 							Ports: []corev1.ContainerPort{
 								{
 									Name:          "http",
@@ -96,18 +170,11 @@ func (k *KubernetesAdapter) CreateServer(ctx context.Context, server *domain.Ser
 									Protocol:      corev1.ProtocolUDP,
 								},
 							},
-							Env: []corev1.EnvVar{
+							Env: containerEnv(server, spec),
+							VolumeMounts: []corev1.VolumeMount{
 								{
-									Name:  "SERVER_ID",
-									Value: server.ID,
-								},
-								{
-									Name:  "SERVER_TYPE",
-									Value: string(server.Type),
-								},
-								{
-									Name:  "REGION",
-									Value: server.Region,
+									Name:      "data",
+									MountPath: domain.ServerDataPath,
 								},
 							},
 							Resources: corev1.ResourceRequirements{
@@ -148,24 +215,26 @@ func (k *KubernetesAdapter) CreateServer(ctx context.Context, server *domain.Ser
 	}
 
 	// Создаем Deployment
-	_, err := k.clientset.AppsV1().Deployments(k.namespace).Create(ctx, deployment, metav1.CreateOptions{})
+	_, err = k.clientset.AppsV1().Deployments(k.namespace).Create(ctx, deployment, metav1.CreateOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to create deployment: %w", err)
+		k.deleteDataClaim(ctx, name)
+		return "", fmt.Errorf("failed to create deployment: %w", err)
 	}
 
 	// Создаем Service
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      server.Name,
+			Name:      name,
 			Namespace: k.namespace,
 			Labels: map[string]string{
-				"app":     server.Name,
-				"managed": "server-manager",
+				"app":         name,
+				managedLabel:  managedValue,
+				serverIDLabel: server.ID,
 			},
 		},
 		Spec: corev1.ServiceSpec{
 			Selector: map[string]string{
-				"app": server.Name,
+				"app": name,
 			},
 			Ports: []corev1.ServicePort{
 				{
@@ -188,20 +257,66 @@ func (k *KubernetesAdapter) CreateServer(ctx context.Context, server *domain.Ser
 	// Создаем Service
 	_, err = k.clientset.CoreV1().Services(k.namespace).Create(ctx, service, metav1.CreateOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to create service: %w", err)
+		// Без Service сервер недоступен, удаляем Deployment
+		if err := k.clientset.AppsV1().Deployments(k.namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			k.logger.Error("failed to remove deployment", zap.String("name", name), zap.Error(err))
+		}
+		k.deleteDataClaim(ctx, name)
+		return "", fmt.Errorf("failed to create service: %w", err)
 	}
 
 	k.logger.Info("kubernetes server created",
-		zap.String("name", server.Name),
+		zap.String("name", name),
 		zap.String("namespace", k.namespace))
 
-	return nil
+	return name, nil
+}
+
+// dataClaimName имя PersistentVolumeClaim с данными сервера
+func dataClaimName(handle string) string {
+	return handle + "-data"
 }
 
-// StartServer запускает сервер в Kubernetes
-func (k *KubernetesAdapter) StartServer(ctx context.Context, serverID string) error {
-	// Масштабируем Deployment до 1 реплики
-	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, serverID, metav1.GetOptions{})
+// deleteDataClaim удаляет том сервера после неудачного создания
+func (k *KubernetesAdapter) deleteDataClaim(ctx context.Context, handle string) {
+	err := k.clientset.CoreV1().PersistentVolumeClaims(k.namespace).Delete(ctx, dataClaimName(handle), metav1.DeleteOptions{})
+	if err != nil {
+		k.logger.Error("failed to remove persistent volume claim", zap.String("name", handle), zap.Error(err))
+	}
+}
+
+// containerEnv переменные окружения контейнера сервера
+func containerEnv(server *domain.Server, spec *domain.ServerSpec) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{
+			Name:  "SERVER_ID",
+			Value: server.ID,
+		},
+		{
+			Name:  "SERVER_TYPE",
+			Value: string(server.Type),
+		},
+		{
+			Name:  "REGION",
+			Value: server.Region,
+		},
+	}
+
+	keys := make([]string, 0, len(spec.Env))
+	for key := range spec.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, corev1.EnvVar{Name: key, Value: spec.Env[key]})
+	}
+	return env
+}
+
+// StartServer запускает сервер в Kubernetes: возвращает Deployment одну
+// реплику
+func (k *KubernetesAdapter) StartServer(ctx context.Context, handle string) error {
+	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, handle, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to get deployment: %w", err)
 	}
@@ -212,14 +327,14 @@ func (k *KubernetesAdapter) StartServer(ctx context.Context, serverID string) er
 		return fmt.Errorf("failed to scale deployment: %w", err)
 	}
 
-	k.logger.Info("kubernetes server started", zap.String("id", serverID))
+	k.logger.Info("kubernetes server started", zap.String("name", handle))
 	return nil
 }
 
-// StopServer останавливает сервер в Kubernetes
-func (k *KubernetesAdapter) StopServer(ctx context.Context, serverID string) error {
-	// Масштабируем Deployment до 0 реплик
-	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, serverID, metav1.GetOptions{})
+// StopServer останавливает сервер в Kubernetes: масштабирует Deployment до
+// 0 реплик
+func (k *KubernetesAdapter) StopServer(ctx context.Context, handle string) error {
+	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, handle, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to get deployment: %w", err)
 	}
@@ -230,24 +345,30 @@ func (k *KubernetesAdapter) StopServer(ctx context.Context, serverID string) err
 		return fmt.Errorf("failed to scale deployment: %w", err)
 	}
 
-	k.logger.Info("kubernetes server stopped", zap.String("id", serverID))
+	k.logger.Info("kubernetes server stopped", zap.String("name", handle))
 	return nil
 }
 
 // DeleteServer удаляет сервер из Kubernetes
-func (k *KubernetesAdapter) DeleteServer(ctx context.Context, serverID string) error {
+func (k *KubernetesAdapter) DeleteServer(ctx context.Context, handle string) error {
 	// Удаляем Deployment
-	err := k.clientset.AppsV1().Deployments(k.namespace).Delete(ctx, serverID, metav1.DeleteOptions{})
+	err := k.clientset.AppsV1().Deployments(k.namespace).Delete(ctx, handle, metav1.DeleteOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to delete deployment: %w", err)
 	}
 
-	// Удаляем Service
-	err = k.clientset.CoreV1().Services(k.namespace).Delete(ctx, serverID, metav1.DeleteOptions{})
-	if err != nil {
+	// Удаляем Service, он мог не создаться
+	err = k.clientset.CoreV1().Services(k.namespace).Delete(ctx, handle, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("failed to delete service: %w", err)
 	}
 
-	k.logger.Info("kubernetes server deleted", zap.String("id", serverID))
+	// Удаляем том с данными, резервные копии хранятся отдельно
+	err = k.clientset.CoreV1().PersistentVolumeClaims(k.namespace).Delete(ctx, dataClaimName(handle), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete persistent volume claim: %w", err)
+	}
+
+	k.logger.Info("kubernetes server deleted", zap.String("name", handle))
 	return nil
 }
@@ -0,0 +1,195 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// copyJobLabel метка пода Job копирования данных
+	copyJobLabel = "server-manager/copy-job"
+	// copyJobImage образ с tar для копирования данных
+	copyJobImage = "busybox:1.36"
+	// copyJobContainer имя контейнера Job копирования
+	copyJobContainer = "copy"
+	// copyJobMountPath точка монтирования тома сервера в поде Job
+	copyJobMountPath = "/data"
+	// copyJobTimeout время ожидания запуска пода Job
+	copyJobTimeout = 2 * time.Minute
+	// copyJobDeadline максимальное время жизни Job
+	copyJobDeadline = int64(3600)
+)
+
+// SnapshotServer архивирует том сервера через Job копирования
+func (k *KubernetesAdapter) SnapshotServer(ctx context.Context, handle string, w io.Writer) error {
+	command := []string{"tar", "cf", "-", "-C", copyJobMountPath, "."}
+	if err := k.runCopyJob(ctx, handle, command, nil, w); err != nil {
+		return fmt.Errorf("failed to snapshot server: %w", err)
+	}
+
+	k.logger.Info("kubernetes server snapshot created", zap.String("name", handle))
+	return nil
+}
+
+// ResetServerData удаляет содержимое тома сервера через Job копирования
+func (k *KubernetesAdapter) ResetServerData(ctx context.Context, handle string) (string, error) {
+	command := []string{"find", copyJobMountPath, "-mindepth", "1", "-delete"}
+	if err := k.runCopyJob(ctx, handle, command, nil, nil); err != nil {
+		return "", fmt.Errorf("failed to reset server data: %w", err)
+	}
+
+	k.logger.Info("kubernetes server data reset", zap.String("name", handle))
+	return handle, nil
+}
+
+// RestoreServer распаковывает архив в том сервера через Job копирования
+func (k *KubernetesAdapter) RestoreServer(ctx context.Context, handle string, r io.Reader) error {
+	command := []string{"tar", "xf", "-", "-C", copyJobMountPath}
+	if err := k.runCopyJob(ctx, handle, command, r, nil); err != nil {
+		return fmt.Errorf("failed to restore server: %w", err)
+	}
+
+	k.logger.Info("kubernetes server restored", zap.String("name", handle))
+	return nil
+}
+
+// runCopyJob запускает Job с томом сервера, выполняет в его поде команду
+// и удаляет Job. Том ReadWriteOnce подключен к узлу пода сервера, поэтому
+// под Job размещается только на этом узле; у остановленного сервера том
+// свободен
+func (k *KubernetesAdapter) runCopyJob(ctx context.Context, handle string, command []string, stdin io.Reader, stdout io.Writer) error {
+	claim := dataClaimName(handle)
+	if _, err := k.clientset.CoreV1().PersistentVolumeClaims(k.namespace).Get(ctx, claim, metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("failed to get persistent volume claim: %w", err)
+	}
+
+	serverPods, err := k.clientset.CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=" + handle,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	var affinity *corev1.Affinity
+	if len(serverPods.Items) > 0 {
+		affinity = &corev1.Affinity{
+			PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+					{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": handle},
+						},
+						TopologyKey: corev1.LabelHostname,
+					},
+				},
+			},
+		}
+	}
+
+	name := handle + "-copy"
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: k.namespace,
+			Labels: map[string]string{
+				managedLabel: managedValue,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          ptr.To(int32(0)),
+			ActiveDeadlineSeconds: ptr.To(copyJobDeadline),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						copyJobLabel: name,
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Affinity:      affinity,
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: claim,
+								},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:    copyJobContainer,
+							Image:   copyJobImage,
+							Command: []string{"sleep", fmt.Sprint(copyJobDeadline)},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "data",
+									MountPath: copyJobMountPath,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if _, err := k.clientset.BatchV1().Jobs(k.namespace).Create(ctx, job, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create copy job: %w", err)
+	}
+	defer func() {
+		// Удаляем Job даже при отмене запроса
+		err := k.clientset.BatchV1().Jobs(k.namespace).Delete(context.WithoutCancel(ctx), name, metav1.DeleteOptions{
+			PropagationPolicy: ptr.To(metav1.DeletePropagationBackground),
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			k.logger.Error("failed to remove copy job", zap.String("name", name), zap.Error(err))
+		}
+	}()
+
+	pod, err := k.waitCopyPod(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return k.executor.Exec(ctx, k.namespace, pod, copyJobContainer, command, stdin, stdout)
+}
+
+// waitCopyPod ждет запуска пода Job и возвращает его имя
+func (k *KubernetesAdapter) waitCopyPod(ctx context.Context, job string) (string, error) {
+	var name string
+	err := wait.PollUntilContextTimeout(ctx, time.Second, copyJobTimeout, true, func(ctx context.Context) (bool, error) {
+		pods, err := k.clientset.CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: copyJobLabel + "=" + job,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to list pods: %w", err)
+		}
+
+		for _, pod := range pods.Items {
+			switch pod.Status.Phase {
+			case corev1.PodRunning:
+				name = pod.Name
+				return true, nil
+			case corev1.PodFailed:
+				return false, fmt.Errorf("copy pod %s failed: %s", pod.Name, pod.Status.Message)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("copy job %s did not start: %w", job, err)
+	}
+
+	return name, nil
+}
@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestKubernetesAdapter_CopyJobAffinity(t *testing.T) {
+	const handle = "silence-vpn-1"
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: dataClaimName(handle), Namespace: "silence"}}
+	serverPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: handle + "-abc", Namespace: "silence", Labels: map[string]string{"app": handle}}}
+
+	// copyJob возвращает Job, которую адаптер пытался создать
+	copyJob := func(t *testing.T, objects ...runtime.Object) *batchv1.Job {
+		clientset := fake.NewSimpleClientset(objects...)
+		var job *batchv1.Job
+		clientset.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			job = action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+			return true, nil, errors.New("rejected")
+		})
+		adapter := NewKubernetesAdapterWithClient(clientset, nil, nil, "silence", zap.NewNop())
+
+		err := adapter.runCopyJob(context.Background(), handle, []string{"tar", "cf", "-"}, nil, nil)
+		require.ErrorContains(t, err, "failed to create copy job")
+		require.NotNil(t, job)
+		return job
+	}
+
+	t.Run("running server", func(t *testing.T) {
+		// Том подключен к узлу пода сервера, другой узел его не получит
+		affinity := copyJob(t, claim, serverPod).Spec.Template.Spec.Affinity
+		require.NotNil(t, affinity)
+		require.Len(t, affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution, 1)
+		term := affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0]
+		assert.Equal(t, map[string]string{"app": handle}, term.LabelSelector.MatchLabels)
+		assert.Equal(t, corev1.LabelHostname, term.TopologyKey)
+		assert.Empty(t, affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+	})
+
+	t.Run("stopped server", func(t *testing.T) {
+		assert.Nil(t, copyJob(t, claim).Spec.Template.Spec.Affinity)
+	})
+}
@@ -0,0 +1,116 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// oomKilledReason причина завершения контейнера при нехватке памяти
+const oomKilledReason = "OOMKilled"
+
+// WatchEvents передает перезапуски контейнеров подов серверов. Счетчики
+// перезапусков берутся из списка подов, затем изменения отслеживаются
+// watch с его resourceVersion
+func (k *KubernetesAdapter) WatchEvents(ctx context.Context) (<-chan *domain.ServerMonitorEvent, error) {
+	selector := managedLabel + "=" + managedValue
+	pods, err := k.clientset.CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	// Перезапуски до подписки не передаются
+	restarts := make(map[string]int32)
+	for i := range pods.Items {
+		podRestarts(&pods.Items[i], restarts)
+	}
+
+	watcher, err := k.clientset.CoreV1().Pods(k.namespace).Watch(ctx, metav1.ListOptions{
+		LabelSelector:   selector,
+		ResourceVersion: pods.ResourceVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch pods: %w", err)
+	}
+
+	out := make(chan *domain.ServerMonitorEvent)
+	go func() {
+		defer close(out)
+		defer watcher.Stop()
+
+		for {
+			var change watch.Event
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case change, ok = <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+			}
+
+			pod, isPod := change.Object.(*corev1.Pod)
+			if !isPod {
+				continue
+			}
+			if change.Type == watch.Deleted {
+				for _, status := range pod.Status.ContainerStatuses {
+					delete(restarts, pod.Name+"/"+status.Name)
+				}
+				continue
+			}
+
+			for _, event := range podRestarts(pod, restarts) {
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// podRestarts сравнивает счетчики перезапусков контейнеров пода с
+// известными в restarts, обновляет их и возвращает события новых
+// перезапусков. Для нового пода отсчет ведется от нуля
+func podRestarts(pod *corev1.Pod, restarts map[string]int32) []*domain.ServerMonitorEvent {
+	serverID := pod.Labels[serverIDLabel]
+	if serverID == "" {
+		return nil
+	}
+
+	var result []*domain.ServerMonitorEvent
+	for _, status := range pod.Status.ContainerStatuses {
+		key := pod.Name + "/" + status.Name
+		previous := restarts[key]
+		restarts[key] = status.RestartCount
+		if status.RestartCount <= previous {
+			continue
+		}
+
+		event := &domain.ServerMonitorEvent{
+			ServerID:  serverID,
+			Type:      domain.MonitorEventRestarted,
+			Message:   fmt.Sprintf("container %s in pod %s restarted", status.Name, pod.Name),
+			Timestamp: time.Now(),
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			event.Message = fmt.Sprintf("container %s in pod %s restarted: %s, exit code %d",
+				status.Name, pod.Name, terminated.Reason, terminated.ExitCode)
+			if terminated.Reason == oomKilledReason {
+				event.Type = domain.MonitorEventOOMKilled
+			}
+		}
+		result = append(result, event)
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// PodExecutor выполняет команду в контейнере пода
+type PodExecutor interface {
+	Exec(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader, stdout io.Writer) error
+}
+
+// SPDYExecutor PodExecutor через подресурс exec API сервера
+type SPDYExecutor struct {
+	config *rest.Config
+	client rest.Interface
+}
+
+// NewSPDYExecutor создает PodExecutor для клиента core/v1
+func NewSPDYExecutor(config *rest.Config, client rest.Interface) *SPDYExecutor {
+	return &SPDYExecutor{
+		config: config,
+		client: client,
+	}
+}
+
+// Exec выполняет команду и ждет ее завершения. Stderr команды попадает в
+// текст ошибки
+func (e *SPDYExecutor) Exec(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader, stdout io.Writer) error {
+	req := e.client.Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("exec %s: %w: %s", command[0], err, message)
+		}
+		return fmt.Errorf("exec %s: %w", command[0], err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/rest"
+)
+
+// PodMetrics потребление ресурсов пода по данным metrics.k8s.io
+type PodMetrics struct {
+	Name   string
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// MetricsSource источник метрик подов
+type MetricsSource interface {
+	PodMetrics(ctx context.Context, namespace, selector string) ([]PodMetrics, error)
+}
+
+// RESTMetricsSource читает метрики metrics-server через API сервер
+// (metrics.k8s.io/v1beta1)
+type RESTMetricsSource struct {
+	client rest.Interface
+}
+
+// NewRESTMetricsSource создает источник метрик
+func NewRESTMetricsSource(client rest.Interface) *RESTMetricsSource {
+	return &RESTMetricsSource{client: client}
+}
+
+// podMetricsList ответ metrics.k8s.io/v1beta1 PodMetricsList
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Containers []struct {
+			Usage map[string]resource.Quantity `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// PodMetrics возвращает суммарное потребление контейнеров каждого пода
+func (m *RESTMetricsSource) PodMetrics(ctx context.Context, namespace, selector string) ([]PodMetrics, error) {
+	data, err := m.client.Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods").
+		Param("labelSelector", selector).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod metrics: %w", err)
+	}
+
+	var list podMetricsList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode pod metrics: %w", err)
+	}
+
+	metrics := make([]PodMetrics, 0, len(list.Items))
+	for _, item := range list.Items {
+		pod := PodMetrics{Name: item.Metadata.Name}
+		for _, container := range item.Containers {
+			if cpu, ok := container.Usage["cpu"]; ok {
+				pod.CPU.Add(cpu)
+			}
+			if memory, ok := container.Usage["memory"]; ok {
+				pod.Memory.Add(memory)
+			}
+		}
+		metrics = append(metrics, pod)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,63 @@
+package kubernetes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest/fake"
+)
+
+func TestRESTMetricsSource_PodMetrics(t *testing.T) {
+	client := &fake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "/apis/metrics.k8s.io/v1beta1/namespaces/silence/pods", req.URL.Path)
+			assert.Equal(t, "app=silence-vpn-1", req.URL.Query().Get("labelSelector"))
+
+			body := `{"kind":"PodMetricsList","items":[
+				{"metadata":{"name":"silence-vpn-1-a"},"containers":[
+					{"name":"app","usage":{"cpu":"150m","memory":"64Mi"}},
+					{"name":"sidecar","usage":{"cpu":"50m","memory":"16Mi"}}
+				]},
+				{"metadata":{"name":"silence-vpn-1-b"},"containers":[
+					{"name":"app","usage":{"cpu":"1","memory":"128Mi"}}
+				]}
+			]}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}),
+	}
+
+	pods, err := NewRESTMetricsSource(client).PodMetrics(context.Background(), "silence", "app=silence-vpn-1")
+	require.NoError(t, err)
+	require.Len(t, pods, 2)
+
+	assert.Equal(t, "silence-vpn-1-a", pods[0].Name)
+	assert.Equal(t, int64(200), pods[0].CPU.MilliValue())
+	assert.Equal(t, int64(80<<20), pods[0].Memory.Value())
+	assert.Equal(t, int64(1000), pods[1].CPU.MilliValue())
+	assert.Equal(t, int64(128<<20), pods[1].Memory.Value())
+}
+
+func TestRESTMetricsSource_Unavailable(t *testing.T) {
+	client := &fake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Resp: &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader("metrics-server is not installed")),
+		},
+	}
+
+	_, err := NewRESTMetricsSource(client).PodMetrics(context.Background(), "silence", "app=x")
+	assert.Error(t, err)
+}
@@ -6,13 +6,22 @@ import (
 
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
 	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
 
-// ScaleServer масштабирует сервер в Kubernetes
-func (k *KubernetesAdapter) ScaleServer(ctx context.Context, serverID string, replicas int32) error {
-	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, serverID, metav1.GetOptions{})
+// ScaleServer масштабирует сервер в Kubernetes. Данные сервера лежат на
+// томе ReadWriteOnce, поэтому больше одной реплики не поддерживается
+func (k *KubernetesAdapter) ScaleServer(ctx context.Context, handle string, replicas int32) error {
+	if replicas < 0 {
+		return fmt.Errorf("invalid replicas: %d", replicas)
+	}
+	if replicas > 1 {
+		return fmt.Errorf("kubernetes: server data volume is ReadWriteOnce, %d replicas: %w", replicas, domain.ErrNotSupported)
+	}
+
+	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, handle, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to get deployment: %w", err)
 	}
@@ -24,7 +33,7 @@ func (k *KubernetesAdapter) ScaleServer(ctx context.Context, serverID string, re
 	}
 
 	k.logger.Info("kubernetes server scaled",
-		zap.String("id", serverID),
+		zap.String("name", handle),
 		zap.Int32("replicas", replicas))
 
 	return nil
@@ -33,7 +42,7 @@ func (k *KubernetesAdapter) ScaleServer(ctx context.Context, serverID string, re
 // ListServers получает список серверов из Kubernetes
 func (k *KubernetesAdapter) ListServers(ctx context.Context) ([]*domain.Server, error) {
 	deployments, err := k.clientset.AppsV1().Deployments(k.namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: "managed=server-manager",
+		LabelSelector: managedLabel + "=" + managedValue,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list deployments: %w", err)
@@ -42,22 +51,16 @@ func (k *KubernetesAdapter) ListServers(ctx context.Context) ([]*domain.Server,
 	var servers []*domain.Server
 	for _, deployment := range deployments.Items {
 		server := &domain.Server{
-			ID:        deployment.Name,
-			Name:      deployment.Name,
-			Type:      domain.ServerType(deployment.Labels["type"]),
-			Region:    deployment.Labels["region"],
-			Status:    domain.ServerStatus("unknown"),
-			CreatedAt: deployment.CreationTimestamp.Time,
-			UpdatedAt: deployment.CreationTimestamp.Time,
-		}
-
-		// Определяем статус
-		if deployment.Status.ReadyReplicas > 0 {
-			server.Status = domain.ServerStatus("running")
-		} else if deployment.Status.Replicas == 0 {
-			server.Status = domain.ServerStatus("stopped")
-		} else {
-			server.Status = domain.ServerStatus("starting")
+			ID:                 deployment.Labels[serverIDLabel],
+			Name:               deployment.Name,
+			Type:               domain.ServerType(deployment.Labels["type"]),
+			Region:             deployment.Labels["region"],
+			Status:             deploymentStatus(&deployment),
+			OrchestratorHandle: deployment.Name,
+			Image:              deploymentImage(&deployment),
+			Replicas:           ptr.Deref(deployment.Spec.Replicas, 1),
+			CreatedAt:          deployment.CreationTimestamp.Time,
+			UpdatedAt:          deployment.CreationTimestamp.Time,
 		}
 
 		servers = append(servers, server)
@@ -65,3 +68,19 @@ func (k *KubernetesAdapter) ListServers(ctx context.Context) ([]*domain.Server,
 
 	return servers, nil
 }
+
+// deploymentStatus статус сервера по состоянию Deployment
+func deploymentStatus(deployment *appsv1.Deployment) domain.ServerStatus {
+	switch {
+	case ptr.Deref(deployment.Spec.Replicas, 1) == 0:
+		return domain.ServerStatusStopped
+	case rolloutFailed(deployment):
+		return domain.ServerStatusError
+	case rolloutInProgress(deployment):
+		return domain.ServerStatusUpdating
+	case deployment.Status.ReadyReplicas > 0:
+		return domain.ServerStatusRunning
+	default:
+		return domain.ServerStatusCreating
+	}
+}
@@ -6,89 +6,117 @@ import (
 	"time"
 
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
-// GetServerStats получает статистику сервера из Kubernetes
-func (k *KubernetesAdapter) GetServerStats(ctx context.Context, serverID string) (*domain.ServerStats, error) {
-	// Получаем Pod'ы для сервера
-	pods, err := k.clientset.CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("app=%s", serverID),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %w", err)
+// GetServerStats получает статистику сервера из Kubernetes: загрузку CPU и
+// памяти работающих подов относительно их лимитов по данным metrics-server
+func (k *KubernetesAdapter) GetServerStats(ctx context.Context, handle string) (*domain.ServerStats, error) {
+	if _, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, handle, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
 	}
 
-	if len(pods.Items) == 0 {
-		return &domain.ServerStats{
-			ServerID:     serverID,
-			CPUUsage:     0.0,
-			MemoryUsage:  0.0,
-			StorageUsage: 0.0,
-			NetworkIn:    0,
-			NetworkOut:   0,
-			Uptime:       0,
-			RequestCount: 0,
-			ResponseTime: 0.0,
-			ErrorRate:    0.0,
-			Timestamp:    time.Now(),
-		}, nil
+	pods, err := k.listPods(ctx, handle)
+	if err != nil {
+		return nil, err
 	}
 
-	// Получаем метрики для первого Pod'а
-	_ = pods.Items[0] // TODO: использовать pod для получения метрик
 	stats := &domain.ServerStats{
-		ServerID:     serverID,
-		CPUUsage:     0.0,
-		MemoryUsage:  0.0,
-		StorageUsage: 0.0,
-		NetworkIn:    0,
-		NetworkOut:   0,
-		Uptime:       0,
-		RequestCount: 0,
-		ResponseTime: 0.0,
-		ErrorRate:    0.0,
-		Timestamp:    time.Now(),
+		ServerID:  handle,
+		Timestamp: time.Now(),
 	}
 
-	// TODO: Получить реальные метрики из Kubernetes Metrics API
-	// Это требует дополнительной настройки metrics-server
+	// Лимиты и время старта считаем только по работающим подам
+	running := make(map[string]bool)
+	var cpuLimit, memoryLimit int64
+	var startedAt time.Time
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		running[pod.Name] = true
+		for _, container := range pod.Spec.Containers {
+			cpuLimit += container.Resources.Limits.Cpu().MilliValue()
+			memoryLimit += container.Resources.Limits.Memory().Value()
+		}
+		if start := pod.Status.StartTime; start != nil && (startedAt.IsZero() || start.Time.Before(startedAt)) {
+			startedAt = start.Time
+		}
+	}
+	if len(running) == 0 {
+		return stats, nil
+	}
+	stats.Uptime = int64(time.Since(startedAt).Seconds())
+
+	metrics, err := k.metrics.PodMetrics(ctx, k.namespace, podSelector(handle))
+	if err != nil {
+		// Без metrics-server доступны только время работы и состояние подов
+		k.logger.Warn("pod metrics unavailable", zap.String("name", handle), zap.Error(err))
+		return stats, nil
+	}
+
+	var cpuUsed, memoryUsed int64
+	for _, pod := range metrics {
+		if running[pod.Name] {
+			cpuUsed += pod.CPU.MilliValue()
+			memoryUsed += pod.Memory.Value()
+		}
+	}
+	if cpuLimit > 0 {
+		stats.CPUUsage = float64(cpuUsed) / float64(cpuLimit) * 100
+	}
+	if memoryLimit > 0 {
+		stats.MemoryUsage = float64(memoryUsed) / float64(memoryLimit) * 100
+	}
 
 	return stats, nil
 }
 
 // GetServerHealth получает здоровье сервера из Kubernetes
-func (k *KubernetesAdapter) GetServerHealth(ctx context.Context, serverID string) (*domain.ServerHealth, error) {
+func (k *KubernetesAdapter) GetServerHealth(ctx context.Context, handle string) (*domain.ServerHealth, error) {
 	// Получаем Deployment
-	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, serverID, metav1.GetOptions{})
+	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, handle, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get deployment: %w", err)
 	}
 
 	// Получаем Pod'ы
-	pods, err := k.clientset.CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("app=%s", serverID),
-	})
+	pods, err := k.listPods(ctx, handle)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %w", err)
+		return nil, err
 	}
 
+	desired := ptr.Deref(deployment.Spec.Replicas, 1)
 	health := &domain.ServerHealth{
-		ServerID:    serverID,
+		ServerID:    handle,
 		Status:      domain.ServerStatusError,
 		Message:     "",
 		LastCheckAt: time.Now(),
-		Checks:      []map[string]interface{}{},
+		Checks: []map[string]interface{}{
+			{
+				"name":    "replicas",
+				"status":  fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, desired),
+				"message": "ready replicas",
+			},
+		},
 	}
 
 	// Определяем статус на основе состояния Deployment и Pod'ов
-	if deployment.Status.ReadyReplicas > 0 {
-		health.Status = domain.ServerStatusRunning
-		health.Message = "Server is running"
-	} else if deployment.Status.Replicas == 0 {
+	if desired == 0 {
 		health.Status = domain.ServerStatusStopped
 		health.Message = "Server is stopped"
+	} else if rolloutFailed(deployment) {
+		health.Status = domain.ServerStatusError
+		health.Message = "Rollout exceeded its progress deadline"
+	} else if rolloutInProgress(deployment) {
+		health.Status = domain.ServerStatusUpdating
+		health.Message = "Rollout in progress"
+	} else if deployment.Status.ReadyReplicas > 0 {
+		health.Status = domain.ServerStatusRunning
+		health.Message = "Server is running"
 	} else {
 		health.Status = domain.ServerStatusError
 		health.Message = "Server has issues"
@@ -97,7 +125,7 @@ func (k *KubernetesAdapter) GetServerHealth(ctx context.Context, serverID string
 	// Проверяем состояние Pod'ов
 	for _, pod := range pods.Items {
 		if pod.Status.Phase == corev1.PodFailed {
-			health.Status = "error"
+			health.Status = domain.ServerStatusError
 			health.Message = "Pod failed"
 			break
 		}
@@ -105,3 +133,19 @@ func (k *KubernetesAdapter) GetServerHealth(ctx context.Context, serverID string
 
 	return health, nil
 }
+
+// listPods возвращает поды Deployment
+func (k *KubernetesAdapter) listPods(ctx context.Context, handle string) (*corev1.PodList, error) {
+	pods, err := k.clientset.CoreV1().Pods(k.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: podSelector(handle),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	return pods, nil
+}
+
+// podSelector селектор подов Deployment
+func podSelector(handle string) string {
+	return "app=" + handle
+}
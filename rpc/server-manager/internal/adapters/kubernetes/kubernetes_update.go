@@ -0,0 +1,71 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// progressDeadlineExceeded причина условия Progressing, когда выкатка не
+// уложилась в progressDeadlineSeconds
+const progressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// UpdateServer меняет образ в шаблоне пода Deployment. Поды заменяет
+// контроллер Deployment, handle не меняется
+func (k *KubernetesAdapter) UpdateServer(ctx context.Context, handle string, image string) (string, error) {
+	deployment, err := k.clientset.AppsV1().Deployments(k.namespace).Get(ctx, handle, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return "", fmt.Errorf("deployment %s has no containers", handle)
+	}
+	containers[0].Image = image
+
+	_, err = k.clientset.AppsV1().Deployments(k.namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to update deployment: %w", err)
+	}
+
+	k.logger.Info("kubernetes server image updated",
+		zap.String("name", handle),
+		zap.String("image", image))
+
+	return handle, nil
+}
+
+// rolloutInProgress сообщает, что контроллер еще заменяет поды прежнего
+// шаблона
+func rolloutInProgress(deployment *appsv1.Deployment) bool {
+	status := deployment.Status
+	if status.ObservedGeneration > 0 && deployment.Generation > status.ObservedGeneration {
+		return true
+	}
+	return status.Replicas > status.UpdatedReplicas
+}
+
+// rolloutFailed сообщает, что выкатка не уложилась в progressDeadlineSeconds
+func rolloutFailed(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == progressDeadlineExceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// deploymentImage образ основного контейнера Deployment
+func deploymentImage(deployment *appsv1.Deployment) string {
+	if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
+		return containers[0].Image
+	}
+	return ""
+}
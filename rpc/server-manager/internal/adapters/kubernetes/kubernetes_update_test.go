@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestKubernetesAdapter_RolloutHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		status appsv1.DeploymentStatus
+		want   domain.ServerStatus
+	}{
+		{
+			name:   "rolled out",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2},
+			want:   domain.ServerStatusRunning,
+		},
+		{
+			name:   "not observed",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2},
+			want:   domain.ServerStatusUpdating,
+		},
+		{
+			name:   "old pods remain",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, ReadyReplicas: 2},
+			want:   domain.ServerStatusUpdating,
+		},
+		{
+			name: "deadline exceeded",
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, ReadyReplicas: 2,
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:   appsv1.DeploymentProgressing,
+					Status: corev1.ConditionFalse,
+					Reason: progressDeadlineExceeded,
+				}},
+			},
+			want: domain.ServerStatusError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "silence-vpn-1",
+					Namespace:  "silence",
+					Generation: 2,
+					Labels:     map[string]string{managedLabel: managedValue},
+				},
+				Spec:   appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+				Status: tt.status,
+			}
+			adapter := NewKubernetesAdapterWithClient(fake.NewSimpleClientset(deployment), nil, nil, "silence", zap.NewNop())
+
+			health, err := adapter.GetServerHealth(context.Background(), "silence-vpn-1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, health.Status)
+
+			servers, err := adapter.ListServers(context.Background())
+			require.NoError(t, err)
+			require.Len(t, servers, 1)
+			assert.Equal(t, tt.want, servers[0].Status)
+		})
+	}
+}
+
+func TestKubernetesAdapter_SingleReplicaWithData(t *testing.T) {
+	ctx := context.Background()
+	adapter := NewKubernetesAdapterWithClient(fake.NewSimpleClientset(), nil, nil, "silence", zap.NewNop())
+
+	handle, err := adapter.CreateServer(ctx,
+		&domain.Server{ID: "server-1", Name: "vpn-1", Type: domain.ServerTypeVPN},
+		&domain.ServerSpec{Image: "silence/vpn-core:1.0.0"})
+	require.NoError(t, err)
+
+	// Том ReadWriteOnce освобождается до запуска нового пода
+	deployment, err := adapter.clientset.AppsV1().Deployments("silence").Get(ctx, handle, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, deployment.Spec.Strategy.Type)
+	assert.Equal(t, int32(1), ptr.Deref(deployment.Spec.Replicas, 0))
+
+	assert.ErrorIs(t, adapter.ScaleServer(ctx, handle, 2), domain.ErrNotSupported)
+	require.NoError(t, adapter.ScaleServer(ctx, handle, 1))
+}
@@ -0,0 +1,503 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/docker"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+)
+
+var _ ports.NodeOrchestrator = (*MultiHostOrchestrator)(nil)
+
+// NodeConnector создает клиент Docker Engine узла
+type NodeConnector func(node *domain.Node) (docker.Client, error)
+
+// nodeHost соединение с Docker Engine узла. Соединение, замененное после
+// смены адреса или сертификатов, закрывается, когда завершится последняя
+// операция, которая его получила
+type nodeHost struct {
+	key          string // адрес и сертификаты, при их смене соединение пересоздается
+	client       docker.Client
+	adapter      *docker.DockerAdapter
+	orchestrator *DockerOrchestrator
+	refs         int  // операции, использующие соединение
+	retired      bool // соединение заменено новым
+}
+
+// MultiHostOrchestrator оркестратор поверх Docker Engine узлов из реестра.
+// Handle сервера - "<ID узла>/<ID контейнера>"
+type MultiHostOrchestrator struct {
+	nodes   ports.NodeRepository
+	connect NodeConnector
+	logger  *zap.Logger
+
+	mu    sync.Mutex
+	hosts map[string]*nodeHost
+
+	// Выбор узла и создание контейнера не должны пересекаться, иначе два
+	// сервера займут последнее место на узле
+	scheduleMu sync.Mutex
+}
+
+// NewMultiHostOrchestrator создает оркестратор, подключающийся к узлам по
+// их адресам и сертификатам TLS
+func NewMultiHostOrchestrator(nodes ports.NodeRepository, apiVersion string, timeout time.Duration, logger *zap.Logger) *MultiHostOrchestrator {
+	return NewMultiHostOrchestratorWithConnector(nodes, func(node *domain.Node) (docker.Client, error) {
+		return docker.NewClient(node.Endpoint, apiVersion, timeout, node.TLSCACert, node.TLSCert, node.TLSKey)
+	}, logger)
+}
+
+// NewMultiHostOrchestratorWithConnector создает оркестратор с готовым
+// способом подключения к узлам
+func NewMultiHostOrchestratorWithConnector(nodes ports.NodeRepository, connect NodeConnector, logger *zap.Logger) *MultiHostOrchestrator {
+	return &MultiHostOrchestrator{
+		nodes:   nodes,
+		connect: connect,
+		logger:  logger,
+		hosts:   make(map[string]*nodeHost),
+	}
+}
+
+// nodeHandle собирает handle сервера из ID узла и ID контейнера
+func nodeHandle(nodeID, containerID string) string {
+	return nodeID + "/" + containerID
+}
+
+// splitNodeHandle разбирает handle сервера на ID узла и ID контейнера
+func splitNodeHandle(handle string) (string, string, error) {
+	nodeID, containerID, found := strings.Cut(handle, "/")
+	if !found || nodeID == "" || containerID == "" {
+		return "", "", fmt.Errorf("invalid server handle: %s", handle)
+	}
+	return nodeID, containerID, nil
+}
+
+// host возвращает соединение с узлом, создавая его при первом обращении
+// или после смены адреса и сертификатов. Полученное соединение нужно
+// вернуть через release
+func (m *MultiHostOrchestrator) host(node *domain.Node) (*nodeHost, error) {
+	key := strings.Join([]string{node.Endpoint, node.TLSCACert, node.TLSCert, node.TLSKey}, "|")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if h, ok := m.hosts[node.ID]; ok {
+		if h.key == key {
+			h.refs++
+			return h, nil
+		}
+		// Прежним соединением могут пользоваться выполняемые операции
+		h.retired = true
+		delete(m.hosts, node.ID)
+		if h.refs == 0 {
+			m.closeHost(node.ID, h)
+		}
+	}
+
+	client, err := m.connect(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to node %s: %w", node.ID, err)
+	}
+	adapter := docker.NewDockerAdapterWithClient(client, m.logger.With(zap.String("node_id", node.ID)))
+	h := &nodeHost{
+		key:          key,
+		client:       client,
+		adapter:      adapter,
+		orchestrator: NewDockerOrchestrator(adapter, m.logger),
+		refs:         1,
+	}
+	m.hosts[node.ID] = h
+	return h, nil
+}
+
+// release возвращает соединение, полученное через host. Замененное
+// соединение закрывается после возврата последней операцией
+func (m *MultiHostOrchestrator) release(nodeID string, h *nodeHost) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h.refs--
+	if h.retired && h.refs == 0 {
+		m.closeHost(nodeID, h)
+	}
+}
+
+// closeHost закрывает соединение с узлом
+func (m *MultiHostOrchestrator) closeHost(nodeID string, h *nodeHost) {
+	if err := h.client.Close(); err != nil {
+		m.logger.Warn("failed to close node connection", zap.String("node_id", nodeID), zap.Error(err))
+	}
+}
+
+// resolve находит узел и ID контейнера сервера по handle. Соединение с
+// узлом нужно вернуть через release
+func (m *MultiHostOrchestrator) resolve(ctx context.Context, handle string) (*domain.Node, *nodeHost, string, error) {
+	nodeID, containerID, err := splitNodeHandle(handle)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	node, err := m.nodes.GetNode(ctx, nodeID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	h, err := m.host(node)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return node, h, containerID, nil
+}
+
+// schedule выбирает для сервера региона region узел с наименьшим числом
+// серверов среди доступных узлов с метками selector и свободным местом.
+// Узел без региона принимает серверы любого региона. Соединение с
+// выбранным узлом нужно вернуть через release
+func (m *MultiHostOrchestrator) schedule(ctx context.Context, region string, selector map[string]string, exclude string) (*domain.Node, *nodeHost, error) {
+	nodes, err := m.nodes.ListNodes(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var best *domain.Node
+	var bestHost *nodeHost
+	bestCount := 0
+	for _, node := range nodes {
+		if node.ID == exclude || !node.Docker() || !node.Schedulable() || !node.MatchLabels(selector) {
+			continue
+		}
+		if node.Region != "" && node.Region != region {
+			continue
+		}
+
+		h, err := m.host(node)
+		if err != nil {
+			m.logger.Warn("skipping node", zap.String("node_id", node.ID), zap.Error(err))
+			continue
+		}
+		servers, err := h.orchestrator.ListServers(ctx)
+		if err != nil {
+			m.release(node.ID, h)
+			m.logger.Warn("skipping node", zap.String("node_id", node.ID), zap.Error(err))
+			continue
+		}
+		count := len(servers)
+		if (node.Capacity > 0 && count >= node.Capacity) || (best != nil && count >= bestCount) {
+			m.release(node.ID, h)
+			continue
+		}
+		if best != nil {
+			m.release(best.ID, bestHost)
+		}
+		best, bestHost, bestCount = node, h, count
+	}
+
+	if best == nil {
+		return nil, nil, fmt.Errorf("%w: no schedulable node for region %s", domain.ErrNoCapacity, region)
+	}
+	return best, bestHost, nil
+}
+
+// CreateServer размещает сервер на выбранном узле и записывает узел в
+// server.NodeID
+func (m *MultiHostOrchestrator) CreateServer(ctx context.Context, server *domain.Server, spec *domain.ServerSpec) (string, error) {
+	m.scheduleMu.Lock()
+	defer m.scheduleMu.Unlock()
+
+	// Docker запрещает одинаковые имена контейнеров только в пределах
+	// одного Engine, повторное создание проверяется по всем узлам
+	existing, err := m.ListServers(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range existing {
+		if s.ID == server.ID {
+			return "", fmt.Errorf("server %s already exists on node %s", server.ID, s.NodeID)
+		}
+	}
+
+	node, h, err := m.schedule(ctx, server.Region, spec.NodeSelector, "")
+	if err != nil {
+		return "", err
+	}
+	defer m.release(node.ID, h)
+
+	containerID, err := h.orchestrator.CreateServer(ctx, server, spec)
+	if err != nil {
+		return "", fmt.Errorf("node %s: %w", node.ID, err)
+	}
+
+	server.NodeID = node.ID
+	m.logger.Info("server scheduled", zap.String("server_id", server.ID), zap.String("node_id", node.ID))
+	return nodeHandle(node.ID, containerID), nil
+}
+
+// StartServer запускает контейнер сервера на его узле
+func (m *MultiHostOrchestrator) StartServer(ctx context.Context, handle string) error {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return err
+	}
+	defer m.release(node.ID, h)
+	return h.orchestrator.StartServer(ctx, containerID)
+}
+
+// StopServer останавливает контейнер сервера на его узле
+func (m *MultiHostOrchestrator) StopServer(ctx context.Context, handle string) error {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return err
+	}
+	defer m.release(node.ID, h)
+	return h.orchestrator.StopServer(ctx, containerID)
+}
+
+// DeleteServer удаляет контейнер сервера на его узле
+func (m *MultiHostOrchestrator) DeleteServer(ctx context.Context, handle string) error {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return err
+	}
+	defer m.release(node.ID, h)
+	return h.orchestrator.DeleteServer(ctx, containerID)
+}
+
+// GetServerStats получает статистику контейнера сервера
+func (m *MultiHostOrchestrator) GetServerStats(ctx context.Context, handle string) (*domain.ServerStats, error) {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	defer m.release(node.ID, h)
+	return h.orchestrator.GetServerStats(ctx, containerID)
+}
+
+// GetServerHealth получает здоровье контейнера сервера
+func (m *MultiHostOrchestrator) GetServerHealth(ctx context.Context, handle string) (*domain.ServerHealth, error) {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	defer m.release(node.ID, h)
+	return h.orchestrator.GetServerHealth(ctx, containerID)
+}
+
+// ScaleServer масштабирует сервер (не поддерживается)
+func (m *MultiHostOrchestrator) ScaleServer(ctx context.Context, handle string, replicas int32) error {
+	return fmt.Errorf("docker-multihost: %w", domain.ErrNotSupported)
+}
+
+// UpdateServer заменяет контейнер сервера на том же узле
+func (m *MultiHostOrchestrator) UpdateServer(ctx context.Context, handle string, image string) (string, error) {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return "", err
+	}
+	defer m.release(node.ID, h)
+	newID, err := h.orchestrator.UpdateServer(ctx, containerID, image)
+	if err != nil {
+		return "", err
+	}
+	return nodeHandle(node.ID, newID), nil
+}
+
+// WatchEvents объединяет события доступных узлов. Канал закрывается при
+// отмене ctx или обрыве потока любого узла, после переподключения
+// подписка охватывает и новые узлы
+func (m *MultiHostOrchestrator) WatchEvents(ctx context.Context) (<-chan *domain.ServerMonitorEvent, error) {
+	nodes, err := m.nodes.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	var streams []<-chan *domain.ServerMonitorEvent
+	var releases []func()
+	for _, node := range nodes {
+		if !node.Docker() || !node.Healthy {
+			continue
+		}
+		h, err := m.host(node)
+		if err != nil {
+			m.logger.Warn("failed to watch node events", zap.String("node_id", node.ID), zap.Error(err))
+			continue
+		}
+		events, err := h.orchestrator.WatchEvents(watchCtx)
+		if err != nil {
+			m.release(node.ID, h)
+			m.logger.Warn("failed to watch node events", zap.String("node_id", node.ID), zap.Error(err))
+			continue
+		}
+		streams = append(streams, events)
+		releases = append(releases, func() { m.release(node.ID, h) })
+	}
+	if len(streams) == 0 {
+		cancel()
+		return nil, fmt.Errorf("no healthy nodes to watch")
+	}
+
+	// Соединения узлов нужны, пока потоки не завершатся
+	return mergeEvents(watchCtx, cancel, streams, func() {
+		for _, release := range releases {
+			release()
+		}
+	}), nil
+}
+
+// mergeEvents передает события всех потоков в один канал. Завершение
+// любого потока отменяет остальные через cancel; после завершения всех
+// потоков вызывается done
+func mergeEvents(ctx context.Context, cancel context.CancelFunc, streams []<-chan *domain.ServerMonitorEvent, done func()) <-chan *domain.ServerMonitorEvent {
+	out := make(chan *domain.ServerMonitorEvent)
+	var wg sync.WaitGroup
+	for _, events := range streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancel()
+			for event := range events {
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		done()
+		close(out)
+	}()
+
+	return out
+}
+
+// ListServers получает серверы со всех доступных узлов. Узлы, не прошедшие
+// последнюю проверку, пропускаются; ошибка доступного узла возвращается,
+// чтобы его серверы не сочли пропавшими
+func (m *MultiHostOrchestrator) ListServers(ctx context.Context) ([]*domain.Server, error) {
+	nodes, err := m.nodes.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var servers []*domain.Server
+	for _, node := range nodes {
+		if !node.Docker() || !node.Healthy {
+			continue
+		}
+		h, err := m.host(node)
+		if err != nil {
+			return nil, err
+		}
+		nodeServers, err := h.orchestrator.ListServers(ctx)
+		m.release(node.ID, h)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		for _, server := range nodeServers {
+			server.NodeID = node.ID
+			server.OrchestratorHandle = nodeHandle(node.ID, server.OrchestratorHandle)
+		}
+		servers = append(servers, nodeServers...)
+	}
+
+	return servers, nil
+}
+
+// SnapshotServer архивирует каталог данных контейнера сервера
+func (m *MultiHostOrchestrator) SnapshotServer(ctx context.Context, handle string, w io.Writer) error {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return err
+	}
+	defer m.release(node.ID, h)
+	return h.orchestrator.SnapshotServer(ctx, containerID, w)
+}
+
+// ResetServerData заменяет контейнер сервера на том же узле таким же с
+// пустым каталогом данных
+func (m *MultiHostOrchestrator) ResetServerData(ctx context.Context, handle string) (string, error) {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return "", err
+	}
+	defer m.release(node.ID, h)
+	newID, err := h.orchestrator.ResetServerData(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	return nodeHandle(node.ID, newID), nil
+}
+
+// RestoreServer распаковывает архив в каталог данных контейнера сервера
+func (m *MultiHostOrchestrator) RestoreServer(ctx context.Context, handle string, r io.Reader) error {
+	node, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return err
+	}
+	defer m.release(node.ID, h)
+	return h.orchestrator.RestoreServer(ctx, containerID, r)
+}
+
+// PingNode проверяет доступность Docker Engine узла
+func (m *MultiHostOrchestrator) PingNode(ctx context.Context, node *domain.Node) error {
+	h, err := m.host(node)
+	if err != nil {
+		return err
+	}
+	defer m.release(node.ID, h)
+	return h.adapter.Ping(ctx)
+}
+
+// MoveServer переносит контейнер сервера на другой узел региона сервера с
+// метками selector
+func (m *MultiHostOrchestrator) MoveServer(ctx context.Context, handle string, selector map[string]string) (string, string, error) {
+	source, h, containerID, err := m.resolve(ctx, handle)
+	if err != nil {
+		return "", "", err
+	}
+	defer m.release(source.ID, h)
+
+	// Регион и тип берутся из меток контейнера
+	servers, err := h.orchestrator.ListServers(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("node %s: %w", source.ID, err)
+	}
+	var server *domain.Server
+	for _, s := range servers {
+		if s.OrchestratorHandle == containerID {
+			server = s
+			break
+		}
+	}
+	if server == nil {
+		return "", "", fmt.Errorf("server container not found on node %s: %s", source.ID, containerID)
+	}
+
+	m.scheduleMu.Lock()
+	defer m.scheduleMu.Unlock()
+
+	target, targetHost, err := m.schedule(ctx, server.Region, selector, source.ID)
+	if err != nil {
+		return "", "", err
+	}
+	defer m.release(target.ID, targetHost)
+
+	newID, err := h.adapter.MoveContainer(ctx, containerID, targetHost.adapter)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to move server to node %s: %w", target.ID, err)
+	}
+
+	m.logger.Info("server moved",
+		zap.String("server_id", server.ID),
+		zap.String("from_node", source.ID),
+		zap.String("to_node", target.ID))
+	return nodeHandle(target.ID, newID), target.ID, nil
+}
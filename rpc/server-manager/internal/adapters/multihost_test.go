@@ -0,0 +1,352 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/docker"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeNodeRepository реестр узлов в памяти
+type fakeNodeRepository struct {
+	mu    sync.Mutex
+	nodes map[string]*domain.Node
+}
+
+func (r *fakeNodeRepository) SaveNode(_ context.Context, node *domain.Node) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *node
+	r.nodes[node.ID] = &copied
+	return nil
+}
+
+func (r *fakeNodeRepository) GetNode(_ context.Context, id string) (*domain.Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	node, ok := r.nodes[id]
+	if !ok {
+		return nil, fmt.Errorf("node not found: %s", id)
+	}
+	copied := *node
+	return &copied, nil
+}
+
+func (r *fakeNodeRepository) ListNodes(_ context.Context) ([]*domain.Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nodes := make([]*domain.Node, 0, len(r.nodes))
+	for _, node := range r.nodes {
+		copied := *node
+		nodes = append(nodes, &copied)
+	}
+	slices.SortFunc(nodes, func(a, b *domain.Node) int { return strings.Compare(a.ID, b.ID) })
+	return nodes, nil
+}
+
+func (r *fakeNodeRepository) DeleteNode(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.nodes, id)
+	return nil
+}
+
+func (r *fakeNodeRepository) UpdateNodeState(_ context.Context, id string, state domain.NodeState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nodes[id].State = state
+	return nil
+}
+
+func (r *fakeNodeRepository) UpdateNodeHealth(_ context.Context, id string, healthy bool, message string, checkedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nodes[id].Healthy = healthy
+	r.nodes[id].Message = message
+	r.nodes[id].LastCheckAt = &checkedAt
+	return nil
+}
+
+// multiHostFixture оркестратор узлов поверх фейковых Docker Engine,
+// по одному на адрес узла
+type multiHostFixture struct {
+	orchestrator *MultiHostOrchestrator
+	nodes        *fakeNodeRepository
+	clients      map[string]*fakeDockerClient // по адресу узла
+}
+
+func newMultiHostFixture(t *testing.T, nodes ...*domain.Node) *multiHostFixture {
+	fixture := &multiHostFixture{
+		nodes:   &fakeNodeRepository{nodes: make(map[string]*domain.Node)},
+		clients: make(map[string]*fakeDockerClient),
+	}
+	for _, node := range nodes {
+		fixture.clients[node.Endpoint] = newFakeDockerClient()
+		require.NoError(t, fixture.nodes.SaveNode(context.Background(), node))
+	}
+	fixture.orchestrator = NewMultiHostOrchestratorWithConnector(fixture.nodes,
+		func(node *domain.Node) (docker.Client, error) {
+			client, ok := fixture.clients[node.Endpoint]
+			if !ok {
+				return nil, fmt.Errorf("unable to resolve docker endpoint: %s", node.Endpoint)
+			}
+			return client, nil
+		}, zap.NewNop())
+	return fixture
+}
+
+// container находит контейнер по handle сервера
+func (f *multiHostFixture) container(t *testing.T, handle string) (*fakeDockerClient, *fakeContainer) {
+	nodeID, containerID, err := splitNodeHandle(handle)
+	require.NoError(t, err)
+	node, err := f.nodes.GetNode(context.Background(), nodeID)
+	require.NoError(t, err)
+	client := f.clients[node.Endpoint]
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	c, err := client.get(containerID)
+	require.NoError(t, err)
+	return client, c
+}
+
+func testNode(id, region string) *domain.Node {
+	return &domain.Node{
+		ID:       id,
+		Endpoint: fmt.Sprintf("tcp://%s.internal:2376", id),
+		Region:   region,
+		State:    domain.NodeStateActive,
+		Healthy:  true,
+	}
+}
+
+func TestMultiHostOrchestrator_Conformance(t *testing.T) {
+	testOrchestratorConformance(t, func(t *testing.T) *orchestratorFixture {
+		fixture := newMultiHostFixture(t, testNode("node-a", "eu-west-1"), testNode("node-b", ""))
+
+		return &orchestratorFixture{
+			orchestrator: fixture.orchestrator,
+			settle: func(t *testing.T, handle string, cpu, memory float64) {
+				client, c := fixture.container(t, handle)
+				client.mu.Lock()
+				defer client.mu.Unlock()
+				c.cpu, c.memory = cpu, memory
+			},
+			crash: func(t *testing.T, handle string, oom bool) {
+				client, c := fixture.container(t, handle)
+				client.mu.Lock()
+				defer client.mu.Unlock()
+
+				c.summary.State = container.StateExited
+				exitCode := "1"
+				if oom {
+					client.emit(c, events.ActionOOM, nil)
+					exitCode = "137"
+				}
+				client.emit(c, events.ActionDie, map[string]string{"exitCode": exitCode})
+			},
+		}
+	})
+}
+
+func TestMultiHostOrchestrator_Schedule(t *testing.T) {
+	ctx := context.Background()
+	small := testNode("eu-a", "eu-west-1")
+	small.Capacity = 1
+	ssd := testNode("eu-b", "eu-west-1")
+	ssd.Labels = map[string]string{"disk": "ssd"}
+	cordoned := testNode("eu-c", "eu-west-1")
+	cordoned.State = domain.NodeStateCordoned
+	unhealthy := testNode("eu-d", "eu-west-1")
+	unhealthy.Healthy = false
+	// Хост, подготовленный по SSH, не получает контейнеров
+	host := testNode("eu-0", "eu-west-1")
+	host.Kind = domain.NodeKindSSH
+	fixture := newMultiHostFixture(t, host, small, ssd, cordoned, unhealthy, testNode("us-a", "us-east-1"))
+	orchestrator := fixture.orchestrator
+
+	create := func(id, region string, selector map[string]string) (*domain.Server, error) {
+		server := &domain.Server{ID: id, Type: domain.ServerTypeVPN, Region: region}
+		_, err := orchestrator.CreateServer(ctx, server, &domain.ServerSpec{
+			Image:        "silence/vpn-core:latest",
+			NodeSelector: selector,
+		})
+		return server, err
+	}
+
+	// Оба узла пусты, выбирается первый по ID
+	server, err := create("vpn-1", "eu-west-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-a", server.NodeID)
+
+	// eu-a заполнен
+	server, err = create("vpn-2", "eu-west-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-b", server.NodeID)
+
+	server, err = create("vpn-3", "eu-west-1", map[string]string{"disk": "ssd"})
+	require.NoError(t, err)
+	assert.Equal(t, "eu-b", server.NodeID)
+
+	_, err = create("vpn-4", "eu-west-1", map[string]string{"disk": "hdd"})
+	assert.ErrorIs(t, err, domain.ErrNoCapacity)
+
+	server, err = create("vpn-5", "us-east-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "us-a", server.NodeID)
+
+	_, err = create("vpn-6", "ap-south-1", nil)
+	assert.ErrorIs(t, err, domain.ErrNoCapacity)
+
+	servers, err := orchestrator.ListServers(ctx)
+	require.NoError(t, err)
+	nodes := make(map[string]string, len(servers))
+	for _, server := range servers {
+		assert.True(t, strings.HasPrefix(server.OrchestratorHandle, server.NodeID+"/"))
+		nodes[server.ID] = server.NodeID
+	}
+	assert.Equal(t, map[string]string{"vpn-1": "eu-a", "vpn-2": "eu-b", "vpn-3": "eu-b", "vpn-5": "us-a"}, nodes)
+}
+
+func TestMultiHostOrchestrator_MoveServer(t *testing.T) {
+	ctx := context.Background()
+	fixture := newMultiHostFixture(t, testNode("eu-a", "eu-west-1"), testNode("eu-b", "eu-west-1"), testNode("us-a", "us-east-1"))
+	orchestrator := fixture.orchestrator
+	server := &domain.Server{ID: "vpn-1", Type: domain.ServerTypeVPN, Region: "eu-west-1"}
+
+	handle, err := orchestrator.CreateServer(ctx, server, &domain.ServerSpec{Image: "silence/vpn-core:1.0.0"})
+	require.NoError(t, err)
+	require.Equal(t, "eu-a", server.NodeID)
+	require.NoError(t, orchestrator.RestoreServer(ctx, handle, archive(t, map[string]string{
+		"wg0.conf": "PrivateKey = first",
+	})))
+
+	newHandle, nodeID, err := orchestrator.MoveServer(ctx, handle, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-b", nodeID)
+	assert.True(t, strings.HasPrefix(newHandle, "eu-b/"))
+
+	// Контейнер работает на новом узле с теми же данными, на прежнем его нет
+	health, err := orchestrator.GetServerHealth(ctx, newHandle)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ServerStatusRunning, health.Status)
+	var snapshot bytes.Buffer
+	require.NoError(t, orchestrator.SnapshotServer(ctx, newHandle, &snapshot))
+	assert.Equal(t, map[string]string{"wg0.conf": "PrivateKey = first"}, archiveFiles(t, &snapshot))
+	_, err = orchestrator.GetServerHealth(ctx, handle)
+	assert.Error(t, err)
+
+	servers, err := orchestrator.ListServers(ctx)
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, server.ResourceName(), servers[0].Name)
+	assert.Equal(t, "eu-b", servers[0].NodeID)
+
+	// Других узлов в регионе нет, сервер остается на месте
+	require.NoError(t, fixture.nodes.UpdateNodeState(ctx, "eu-a", domain.NodeStateCordoned))
+	_, _, err = orchestrator.MoveServer(ctx, newHandle, nil)
+	assert.ErrorIs(t, err, domain.ErrNoCapacity)
+	health, err = orchestrator.GetServerHealth(ctx, newHandle)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ServerStatusRunning, health.Status)
+}
+
+func TestMultiHostOrchestrator_MoveServerSelector(t *testing.T) {
+	ctx := context.Background()
+	labeled := func(id string, labels map[string]string) *domain.Node {
+		node := testNode(id, "eu-west-1")
+		node.Labels = labels
+		return node
+	}
+	fixture := newMultiHostFixture(t,
+		labeled("eu-a", map[string]string{"zone": "a"}),
+		labeled("eu-b", map[string]string{"zone": "b"}),
+		labeled("eu-c", map[string]string{"zone": "a"}))
+	orchestrator := fixture.orchestrator
+	selector := map[string]string{"zone": "a"}
+
+	handle, err := orchestrator.CreateServer(ctx, &domain.Server{ID: "vpn-1", Type: domain.ServerTypeVPN, Region: "eu-west-1"},
+		&domain.ServerSpec{Image: "silence/vpn-core:1.0.0", NodeSelector: selector})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(handle, "eu-a/"))
+
+	// Свободный узел eu-b не подходит по меткам
+	_, nodeID, err := orchestrator.MoveServer(ctx, handle, selector)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-c", nodeID)
+}
+
+func TestMultiHostOrchestrator_ReplacedConnection(t *testing.T) {
+	ctx := context.Background()
+	fixture := newMultiHostFixture(t, testNode("eu-a", "eu-west-1"))
+	orchestrator := fixture.orchestrator
+	node, err := fixture.nodes.GetNode(ctx, "eu-a")
+	require.NoError(t, err)
+	old := fixture.clients[node.Endpoint]
+
+	// Операция держит соединение, пока адрес узла меняется
+	h, err := orchestrator.host(node)
+	require.NoError(t, err)
+	node.Endpoint = "tcp://eu-a-new.internal:2376"
+	fixture.clients[node.Endpoint] = newFakeDockerClient()
+	require.NoError(t, orchestrator.PingNode(ctx, node))
+
+	old.mu.Lock()
+	assert.False(t, old.closed, "connection closed while in use")
+	old.mu.Unlock()
+
+	orchestrator.release(node.ID, h)
+	old.mu.Lock()
+	assert.True(t, old.closed)
+	old.mu.Unlock()
+	assert.False(t, fixture.clients[node.Endpoint].closed)
+}
+
+func TestMultiHostOrchestrator_UnhealthyNode(t *testing.T) {
+	ctx := context.Background()
+	fixture := newMultiHostFixture(t, testNode("eu-a", "eu-west-1"), testNode("eu-b", "eu-west-1"))
+	orchestrator := fixture.orchestrator
+
+	for _, id := range []string{"vpn-1", "vpn-2"} {
+		_, err := orchestrator.CreateServer(ctx, &domain.Server{ID: id, Type: domain.ServerTypeVPN, Region: "eu-west-1"},
+			&domain.ServerSpec{Image: "silence/vpn-core:latest"})
+		require.NoError(t, err)
+	}
+
+	node, err := fixture.nodes.GetNode(ctx, "eu-b")
+	require.NoError(t, err)
+	require.NoError(t, orchestrator.PingNode(ctx, node))
+	client := fixture.clients[node.Endpoint]
+	client.mu.Lock()
+	client.down = true
+	client.mu.Unlock()
+	assert.Error(t, orchestrator.PingNode(ctx, node))
+
+	// Серверы узла, не прошедшего проверку, не попадают в список
+	require.NoError(t, fixture.nodes.UpdateNodeHealth(ctx, "eu-b", false, "unreachable", time.Now()))
+	servers, err := orchestrator.ListServers(ctx)
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, "eu-a", servers[0].NodeID)
+
+	// Неизвестный адрес узла
+	assert.Error(t, orchestrator.PingNode(ctx, testNode("eu-c", "eu-west-1")))
+}
@@ -0,0 +1,357 @@
+package adapters
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orchestratorFixture оркестратор поверх фейкового клиента
+type orchestratorFixture struct {
+	orchestrator ports.Orchestrator
+	// settle доводит ресурс до работающего состояния с заданной загрузкой
+	// CPU и памяти в процентах, как это сделал бы реальный бэкенд
+	settle func(t *testing.T, handle string, cpu, memory float64)
+	// replicas возвращает желаемое число реплик, nil если масштабирование
+	// не поддерживается
+	replicas func(t *testing.T, handle string) int32
+	// crash аварийно завершает контейнер сервера, oom - из-за нехватки
+	// памяти
+	crash func(t *testing.T, handle string, oom bool)
+}
+
+// testOrchestratorConformance общие проверки реализаций ports.Orchestrator
+func testOrchestratorConformance(t *testing.T, newFixture func(t *testing.T) *orchestratorFixture) {
+	ctx := context.Background()
+	server := &domain.Server{
+		ID:     "3f6c1a52-0b8e-4d7a-9a51-6f0d2c9e4b17",
+		Name:   "vpn-eu-1",
+		Type:   domain.ServerTypeVPN,
+		Region: "eu-west-1",
+	}
+	spec := &domain.ServerSpec{
+		Image: "silence/vpn-core:latest",
+		Env:   map[string]string{"LOG_LEVEL": "debug"},
+	}
+
+	t.Run("lifecycle", func(t *testing.T) {
+		fixture := newFixture(t)
+		orchestrator := fixture.orchestrator
+
+		handle, err := orchestrator.CreateServer(ctx, server, spec)
+		require.NoError(t, err)
+		require.NotEmpty(t, handle)
+		fixture.settle(t, handle, 40, 25)
+
+		servers, err := orchestrator.ListServers(ctx)
+		require.NoError(t, err)
+		require.Len(t, servers, 1)
+		assert.Equal(t, server.ID, servers[0].ID)
+		assert.Equal(t, handle, servers[0].OrchestratorHandle)
+		assert.Equal(t, server.Type, servers[0].Type)
+		assert.Equal(t, server.Region, servers[0].Region)
+		assert.Equal(t, domain.ServerStatusRunning, servers[0].Status)
+
+		health, err := orchestrator.GetServerHealth(ctx, handle)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ServerStatusRunning, health.Status)
+
+		stats, err := orchestrator.GetServerStats(ctx, handle)
+		require.NoError(t, err)
+		assert.InDelta(t, 40, stats.CPUUsage, 0.5)
+		assert.InDelta(t, 25, stats.MemoryUsage, 0.5)
+		assert.Positive(t, stats.Uptime)
+
+		require.NoError(t, orchestrator.StopServer(ctx, handle))
+		health, err = orchestrator.GetServerHealth(ctx, handle)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ServerStatusStopped, health.Status)
+
+		require.NoError(t, orchestrator.StartServer(ctx, handle))
+		health, err = orchestrator.GetServerHealth(ctx, handle)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ServerStatusRunning, health.Status)
+
+		require.NoError(t, orchestrator.DeleteServer(ctx, handle))
+		servers, err = orchestrator.ListServers(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, servers)
+		_, err = orchestrator.GetServerHealth(ctx, handle)
+		assert.Error(t, err)
+		assert.Error(t, orchestrator.StartServer(ctx, handle))
+	})
+
+	t.Run("scale", func(t *testing.T) {
+		fixture := newFixture(t)
+		orchestrator := fixture.orchestrator
+
+		handle, err := orchestrator.CreateServer(ctx, server, spec)
+		require.NoError(t, err)
+
+		// Каталог данных сервера принадлежит одному экземпляру
+		err = orchestrator.ScaleServer(ctx, handle, 3)
+		assert.ErrorIs(t, err, domain.ErrNotSupported)
+		if fixture.replicas == nil {
+			return
+		}
+
+		require.NoError(t, orchestrator.ScaleServer(ctx, handle, 0))
+		assert.Equal(t, int32(0), fixture.replicas(t, handle))
+		require.NoError(t, orchestrator.StartServer(ctx, handle))
+		assert.Equal(t, int32(1), fixture.replicas(t, handle))
+		require.NoError(t, orchestrator.StopServer(ctx, handle))
+		assert.Equal(t, int32(0), fixture.replicas(t, handle))
+
+		assert.Error(t, orchestrator.ScaleServer(ctx, handle, -1))
+	})
+
+	t.Run("events", func(t *testing.T) {
+		fixture := newFixture(t)
+
+		handle, err := fixture.orchestrator.CreateServer(ctx, server, spec)
+		require.NoError(t, err)
+		fixture.settle(t, handle, 10, 10)
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := fixture.orchestrator.WatchEvents(watchCtx)
+		require.NoError(t, err)
+
+		fixture.crash(t, handle, true)
+		event := nextEvent(t, events, func(event *domain.ServerMonitorEvent) bool {
+			return event.Type == domain.MonitorEventOOMKilled
+		})
+		assert.Equal(t, server.ID, event.ServerID)
+		assert.False(t, event.Timestamp.IsZero())
+
+		// Docker сообщает о завершении контейнера, Kubernetes - о его
+		// перезапуске
+		fixture.crash(t, handle, false)
+		event = nextEvent(t, events, func(event *domain.ServerMonitorEvent) bool {
+			return strings.Contains(event.Message, "exit code 1")
+		})
+		assert.Equal(t, server.ID, event.ServerID)
+		assert.Contains(t, []string{domain.MonitorEventExited, domain.MonitorEventRestarted}, event.Type)
+
+		// Отмена закрывает канал
+		cancel()
+		nextEvent(t, events, nil)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		orchestrator := newFixture(t).orchestrator
+
+		_, err := orchestrator.CreateServer(ctx, server, spec)
+		require.NoError(t, err)
+		_, err = orchestrator.CreateServer(ctx, server, spec)
+		assert.Error(t, err)
+
+		servers, err := orchestrator.ListServers(ctx)
+		require.NoError(t, err)
+		assert.Len(t, servers, 1)
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		orchestrator := newFixture(t).orchestrator
+
+		handle, err := orchestrator.CreateServer(ctx, server, spec)
+		require.NoError(t, err)
+
+		require.NoError(t, orchestrator.RestoreServer(ctx, handle, archive(t, map[string]string{
+			"wg0.conf":         "PrivateKey = first",
+			"peers/alice.conf": "AllowedIPs = 10.0.0.2/32",
+		})))
+		require.NoError(t, orchestrator.RestoreServer(ctx, handle, archive(t, map[string]string{
+			"wg0.conf": "PrivateKey = second",
+		})))
+
+		// Восстановление поверх сохраняет остальные файлы
+		var snapshot bytes.Buffer
+		require.NoError(t, orchestrator.SnapshotServer(ctx, handle, &snapshot))
+		assert.Equal(t, map[string]string{
+			"wg0.conf":         "PrivateKey = second",
+			"peers/alice.conf": "AllowedIPs = 10.0.0.2/32",
+		}, archiveFiles(t, &snapshot))
+
+		// Снимок доступен и у остановленного сервера
+		require.NoError(t, orchestrator.StopServer(ctx, handle))
+		snapshot.Reset()
+		require.NoError(t, orchestrator.SnapshotServer(ctx, handle, &snapshot))
+		assert.Len(t, archiveFiles(t, &snapshot), 2)
+
+		// После очистки остаются только файлы восстановленного архива
+		handle, err = orchestrator.ResetServerData(ctx, handle)
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.RestoreServer(ctx, handle, archive(t, map[string]string{
+			"wg0.conf": "PrivateKey = third",
+		})))
+		snapshot.Reset()
+		require.NoError(t, orchestrator.SnapshotServer(ctx, handle, &snapshot))
+		assert.Equal(t, map[string]string{"wg0.conf": "PrivateKey = third"}, archiveFiles(t, &snapshot))
+
+		servers, err := orchestrator.ListServers(ctx)
+		require.NoError(t, err)
+		require.Len(t, servers, 1)
+		assert.Equal(t, handle, servers[0].OrchestratorHandle)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		fixture := newFixture(t)
+		orchestrator := fixture.orchestrator
+
+		handle, err := orchestrator.CreateServer(ctx, server, spec)
+		require.NoError(t, err)
+		fixture.settle(t, handle, 10, 10)
+		require.NoError(t, orchestrator.RestoreServer(ctx, handle, archive(t, map[string]string{
+			"wg0.conf": "PrivateKey = first",
+		})))
+
+		const image = "silence/vpn-core:1.2.0"
+		newHandle, err := orchestrator.UpdateServer(ctx, handle, image)
+		require.NoError(t, err)
+		require.NotEmpty(t, newHandle)
+		fixture.settle(t, newHandle, 10, 10)
+
+		servers, err := orchestrator.ListServers(ctx)
+		require.NoError(t, err)
+		require.Len(t, servers, 1)
+		assert.Equal(t, server.ID, servers[0].ID)
+		assert.Equal(t, newHandle, servers[0].OrchestratorHandle)
+		assert.Equal(t, image, servers[0].Image)
+		assert.Equal(t, domain.ServerStatusRunning, servers[0].Status)
+
+		health, err := orchestrator.GetServerHealth(ctx, newHandle)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ServerStatusRunning, health.Status)
+
+		// Данные переживают смену образа
+		var snapshot bytes.Buffer
+		require.NoError(t, orchestrator.SnapshotServer(ctx, newHandle, &snapshot))
+		assert.Equal(t, map[string]string{"wg0.conf": "PrivateKey = first"}, archiveFiles(t, &snapshot))
+	})
+
+	t.Run("unknown handle", func(t *testing.T) {
+		orchestrator := newFixture(t).orchestrator
+		const handle = "silence-vpn-missing"
+
+		assert.Error(t, orchestrator.StartServer(ctx, handle))
+		assert.Error(t, orchestrator.StopServer(ctx, handle))
+		assert.Error(t, orchestrator.DeleteServer(ctx, handle))
+		_, err := orchestrator.GetServerStats(ctx, handle)
+		assert.Error(t, err)
+		_, err = orchestrator.GetServerHealth(ctx, handle)
+		assert.Error(t, err)
+		assert.Error(t, orchestrator.ScaleServer(ctx, handle, 2))
+		assert.Error(t, orchestrator.SnapshotServer(ctx, handle, io.Discard))
+		assert.Error(t, orchestrator.RestoreServer(ctx, handle, archive(t, nil)))
+		_, err = orchestrator.UpdateServer(ctx, handle, spec.Image)
+		assert.Error(t, err)
+	})
+}
+
+// archive собирает tar из файлов
+func archive(t *testing.T, files map[string]string) io.Reader {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, writer.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return &buf
+}
+
+// archiveFiles читает обычные файлы tar, пути нормализуются
+func archiveFiles(t *testing.T, r io.Reader) map[string]string {
+	files := make(map[string]string)
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		require.NoError(t, err)
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		files[path.Clean(header.Name)] = string(content)
+	}
+}
+
+func TestDockerOrchestrator_Conformance(t *testing.T) {
+	testOrchestratorConformance(t, newDockerFixture)
+}
+
+func TestDockerOrchestrator_UpdateServerFailure(t *testing.T) {
+	ctx := context.Background()
+	server := &domain.Server{ID: "8d2f4b90-5c1e-4a3b-b6d7-2e9f0a1c3b45", Type: domain.ServerTypeVPN, Region: "eu-west-1"}
+	spec := &domain.ServerSpec{Image: "silence/vpn-core:1.0.0"}
+
+	tests := []struct {
+		name  string
+		image string
+	}{
+		{name: "pull fails", image: "silence/vpn-core:missing"},
+		{name: "create fails", image: "silence/vpn-core:broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := newDockerFixture(t).orchestrator
+
+			handle, err := orchestrator.CreateServer(ctx, server, spec)
+			require.NoError(t, err)
+
+			_, err = orchestrator.UpdateServer(ctx, handle, tt.image)
+			require.Error(t, err)
+
+			// Прежний контейнер остается на месте и работает
+			servers, err := orchestrator.ListServers(ctx)
+			require.NoError(t, err)
+			require.Len(t, servers, 1)
+			assert.Equal(t, handle, servers[0].OrchestratorHandle)
+			assert.Equal(t, server.ResourceName(), servers[0].Name)
+			assert.Equal(t, spec.Image, servers[0].Image)
+			assert.Equal(t, domain.ServerStatusRunning, servers[0].Status)
+		})
+	}
+}
+
+func TestKubernetesAdapter_Conformance(t *testing.T) {
+	testOrchestratorConformance(t, newKubernetesFixture)
+}
+
+// nextEvent ждет события, для которого match возвращает true. С nil match
+// ждет закрытия канала
+func nextEvent(t *testing.T, events <-chan *domain.ServerMonitorEvent, match func(*domain.ServerMonitorEvent) bool) *domain.ServerMonitorEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				require.Nil(t, match, "event channel closed")
+				return nil
+			}
+			if match != nil && match(event) {
+				return event
+			}
+		case <-timeout:
+			require.FailNow(t, "timed out waiting for event")
+		}
+	}
+}
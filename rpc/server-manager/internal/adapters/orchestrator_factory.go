@@ -3,8 +3,10 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
+	"github.com/docker/docker/api/types/events"
 	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/docker"
 	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/kubernetes"
 	"github.com/par1ram/silence/rpc/server-manager/internal/config"
@@ -13,17 +15,33 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// managedLabel метка контейнеров, созданных server-manager
+	managedLabel = "managed"
+	managedValue = "server-manager"
+	// serverIDLabel метка с ID сервера в базе данных
+	serverIDLabel = "server-id"
+)
+
+var (
+	_ ports.Orchestrator = (*DockerOrchestrator)(nil)
+	_ ports.Orchestrator = (*kubernetes.KubernetesAdapter)(nil)
+)
+
 // OrchestratorFactory фабрика для создания оркестраторов
 type OrchestratorFactory struct {
-	config *config.Config
-	logger *zap.Logger
+	config   *config.Config
+	nodeRepo ports.NodeRepository
+	logger   *zap.Logger
 }
 
-// NewOrchestratorFactory создает новую фабрику оркестраторов
-func NewOrchestratorFactory(config *config.Config, logger *zap.Logger) *OrchestratorFactory {
+// NewOrchestratorFactory создает новую фабрику оркестраторов. Реестр
+// узлов нужен только оркестратору docker-multihost
+func NewOrchestratorFactory(config *config.Config, nodeRepo ports.NodeRepository, logger *zap.Logger) *OrchestratorFactory {
 	return &OrchestratorFactory{
-		config: config,
-		logger: logger,
+		config:   config,
+		nodeRepo: nodeRepo,
+		logger:   logger,
 	}
 }
 
@@ -32,6 +50,8 @@ func (f *OrchestratorFactory) CreateOrchestrator() (ports.Orchestrator, error) {
 	switch f.config.Orchestrator.Type {
 	case "docker":
 		return f.createDockerOrchestrator()
+	case "docker-multihost":
+		return f.createMultiHostOrchestrator()
 	case "kubernetes":
 		return f.createKubernetesOrchestrator()
 	default:
@@ -51,10 +71,16 @@ func (f *OrchestratorFactory) createDockerOrchestrator() (ports.Orchestrator, er
 		return nil, fmt.Errorf("failed to create docker adapter: %w", err)
 	}
 
-	return &DockerOrchestrator{
-		adapter: dockerAdapter,
-		logger:  f.logger,
-	}, nil
+	return NewDockerOrchestrator(dockerAdapter, f.logger), nil
+}
+
+// createMultiHostOrchestrator создает оркестратор узлов Docker из реестра
+func (f *OrchestratorFactory) createMultiHostOrchestrator() (ports.Orchestrator, error) {
+	if f.nodeRepo == nil {
+		return nil, fmt.Errorf("node repository not initialized")
+	}
+
+	return NewMultiHostOrchestrator(f.nodeRepo, f.config.Docker.APIVersion, f.config.Docker.Timeout, f.logger), nil
 }
 
 // createKubernetesOrchestrator создает Kubernetes оркестратор
@@ -68,151 +94,164 @@ func (f *OrchestratorFactory) createKubernetesOrchestrator() (ports.Orchestrator
 		return nil, fmt.Errorf("failed to create kubernetes adapter: %w", err)
 	}
 
-	return &KubernetesOrchestrator{
-		adapter: k8sAdapter,
-		logger:  f.logger,
-	}, nil
+	return k8sAdapter, nil
 }
 
-// DockerOrchestrator обертка для Docker адаптера
+// DockerOrchestrator оркестратор поверх Docker адаптера. Handle сервера -
+// ID контейнера
 type DockerOrchestrator struct {
 	adapter *docker.DockerAdapter
 	logger  *zap.Logger
 }
 
-// CreateServer создает сервер в Docker
-func (d *DockerOrchestrator) CreateServer(ctx context.Context, server *domain.Server) error {
-	// Создаем конфигурацию контейнера
-	config := map[string]interface{}{
-		"environment": map[string]interface{}{
-			"SERVER_ID":   server.ID,
-			"SERVER_TYPE": string(server.Type),
-			"REGION":      server.Region,
-		},
+// NewDockerOrchestrator создает Docker оркестратор
+func NewDockerOrchestrator(adapter *docker.DockerAdapter, logger *zap.Logger) *DockerOrchestrator {
+	return &DockerOrchestrator{
+		adapter: adapter,
+		logger:  logger,
 	}
+}
 
-	containerID, err := d.adapter.CreateContainer(ctx, server.Name, "silence/vpn-core:latest", config)
-	if err != nil {
-		return err
+// CreateServer создает и запускает контейнер сервера
+func (d *DockerOrchestrator) CreateServer(ctx context.Context, server *domain.Server, spec *domain.ServerSpec) (string, error) {
+	environment := map[string]interface{}{
+		"SERVER_ID":   server.ID,
+		"SERVER_TYPE": string(server.Type),
+		"REGION":      server.Region,
+	}
+	for key, value := range spec.Env {
+		environment[key] = value
 	}
 
-	// Запускаем контейнер
-	return d.adapter.StartContainer(ctx, containerID)
-}
+	config := map[string]interface{}{
+		"environment": environment,
+		"labels": map[string]string{
+			managedLabel:  managedValue,
+			serverIDLabel: server.ID,
+			"type":        string(server.Type),
+			"region":      server.Region,
+		},
+		"volumes": []string{domain.ServerDataPath},
+	}
 
-// StartServer запускает сервер в Docker
-func (d *DockerOrchestrator) StartServer(ctx context.Context, serverID string) error {
-	// В Docker нужно найти контейнер по имени и запустить его
-	containers, err := d.adapter.ListContainers(ctx)
+	containerID, err := d.adapter.CreateContainer(ctx, server.ResourceName(), spec.Image, config)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	for _, container := range containers {
-		for _, name := range container.Names {
-			if name == "/"+serverID {
-				return d.adapter.StartContainer(ctx, container.ID)
-			}
+	// Запускаем контейнер, не оставляя созданный при ошибке
+	if err := d.adapter.StartContainer(ctx, containerID); err != nil {
+		if removeErr := d.adapter.RemoveContainer(ctx, containerID, true); removeErr != nil {
+			d.logger.Warn("failed to remove container after start failure",
+				zap.String("id", containerID), zap.Error(removeErr))
 		}
+		return "", err
 	}
 
-	return fmt.Errorf("container not found: %s", serverID)
+	return containerID, nil
 }
 
-// StopServer останавливает сервер в Docker
-func (d *DockerOrchestrator) StopServer(ctx context.Context, serverID string) error {
-	containers, err := d.adapter.ListContainers(ctx)
-	if err != nil {
-		return err
-	}
+// StartServer запускает контейнер сервера
+func (d *DockerOrchestrator) StartServer(ctx context.Context, handle string) error {
+	return d.adapter.StartContainer(ctx, handle)
+}
 
-	for _, container := range containers {
-		for _, name := range container.Names {
-			if name == "/"+serverID {
-				timeout := 30 * time.Second
-				return d.adapter.StopContainer(ctx, container.ID, &timeout)
-			}
-		}
-	}
+// StopServer останавливает контейнер сервера
+func (d *DockerOrchestrator) StopServer(ctx context.Context, handle string) error {
+	timeout := 30 * time.Second
+	return d.adapter.StopContainer(ctx, handle, &timeout)
+}
 
-	return fmt.Errorf("container not found: %s", serverID)
+// DeleteServer удаляет контейнер сервера
+func (d *DockerOrchestrator) DeleteServer(ctx context.Context, handle string) error {
+	return d.adapter.RemoveContainer(ctx, handle, true)
 }
 
-// DeleteServer удаляет сервер в Docker
-func (d *DockerOrchestrator) DeleteServer(ctx context.Context, serverID string) error {
-	containers, err := d.adapter.ListContainers(ctx)
-	if err != nil {
-		return err
-	}
+// GetServerStats получает статистику сервера из Docker
+func (d *DockerOrchestrator) GetServerStats(ctx context.Context, handle string) (*domain.ServerStats, error) {
+	return d.adapter.GetContainerStats(ctx, handle)
+}
 
-	for _, container := range containers {
-		for _, name := range container.Names {
-			if name == "/"+serverID {
-				return d.adapter.RemoveContainer(ctx, container.ID, true)
-			}
-		}
-	}
+// GetServerHealth получает здоровье сервера из Docker
+func (d *DockerOrchestrator) GetServerHealth(ctx context.Context, handle string) (*domain.ServerHealth, error) {
+	return d.adapter.GetContainerHealth(ctx, handle)
+}
 
-	return fmt.Errorf("container not found: %s", serverID)
+// ScaleServer масштабирует сервер в Docker (не поддерживается)
+func (d *DockerOrchestrator) ScaleServer(ctx context.Context, handle string, replicas int32) error {
+	return fmt.Errorf("docker: %w", domain.ErrNotSupported)
 }
 
-// GetServerStats получает статистику сервера из Docker
-func (d *DockerOrchestrator) GetServerStats(ctx context.Context, serverID string) (*domain.ServerStats, error) {
-	containers, err := d.adapter.ListContainers(ctx)
-	if err != nil {
-		return nil, err
-	}
+// UpdateServer заменяет контейнер сервера контейнером из нового образа
+func (d *DockerOrchestrator) UpdateServer(ctx context.Context, handle string, image string) (string, error) {
+	return d.adapter.ReplaceContainer(ctx, handle, image)
+}
 
-	for _, container := range containers {
-		for _, name := range container.Names {
-			if name == "/"+serverID {
-				return d.adapter.GetContainerStats(ctx, container.ID)
+// WatchEvents передает события контейнеров серверов из потока событий
+// Docker
+func (d *DockerOrchestrator) WatchEvents(ctx context.Context) (<-chan *domain.ServerMonitorEvent, error) {
+	messages := d.adapter.WatchContainers(ctx, managedLabel+"="+managedValue)
+
+	out := make(chan *domain.ServerMonitorEvent)
+	go func() {
+		defer close(out)
+		for message := range messages {
+			event := containerEvent(message)
+			if event == nil {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
 			}
 		}
-	}
+	}()
 
-	return &domain.ServerStats{
-		ServerID:     serverID,
-		CPUUsage:     0.0,
-		MemoryUsage:  0.0,
-		StorageUsage: 0.0,
-		NetworkIn:    0,
-		NetworkOut:   0,
-		Uptime:       0,
-		RequestCount: 0,
-		ResponseTime: 0.0,
-		ErrorRate:    0.0,
-		Timestamp:    time.Now(),
-	}, nil
+	return out, nil
 }
 
-// GetServerHealth получает здоровье сервера из Docker
-func (d *DockerOrchestrator) GetServerHealth(ctx context.Context, serverID string) (*domain.ServerHealth, error) {
-	containers, err := d.adapter.ListContainers(ctx)
-	if err != nil {
-		return nil, err
+// containerEvent переводит событие Docker в событие мониторинга, nil для
+// событий без сервера
+func containerEvent(message events.Message) *domain.ServerMonitorEvent {
+	serverID := message.Actor.Attributes[serverIDLabel]
+	if serverID == "" {
+		return nil
 	}
 
-	for _, container := range containers {
-		for _, name := range container.Names {
-			if name == "/"+serverID {
-				return d.adapter.GetContainerHealth(ctx, container.ID)
-			}
-		}
+	event := &domain.ServerMonitorEvent{
+		ServerID:  serverID,
+		Timestamp: time.Unix(0, message.TimeNano),
+	}
+	switch message.Action {
+	case events.ActionDie:
+		event.Type = domain.MonitorEventExited
+		event.Message = fmt.Sprintf("container exited, exit code %s", message.Actor.Attributes["exitCode"])
+	case events.ActionOOM:
+		event.Type = domain.MonitorEventOOMKilled
+		event.Message = "container killed: out of memory"
+	case events.ActionRestart:
+		event.Type = domain.MonitorEventRestarted
+		event.Message = "container restarted"
+	default:
+		return nil
 	}
+	return event
+}
 
-	return &domain.ServerHealth{
-		ServerID:    serverID,
-		Status:      domain.ServerStatusError,
-		Message:     "Container not found",
-		LastCheckAt: time.Now(),
-		Checks:      []map[string]interface{}{},
-	}, nil
+// SnapshotServer архивирует каталог данных контейнера
+func (d *DockerOrchestrator) SnapshotServer(ctx context.Context, handle string, w io.Writer) error {
+	return d.adapter.ArchiveDirectory(ctx, handle, domain.ServerDataPath, w)
 }
 
-// ScaleServer масштабирует сервер в Docker (не поддерживается)
-func (d *DockerOrchestrator) ScaleServer(ctx context.Context, serverID string, replicas int32) error {
-	return fmt.Errorf("scaling not supported in Docker mode")
+// ResetServerData заменяет контейнер таким же с пустым каталогом данных
+func (d *DockerOrchestrator) ResetServerData(ctx context.Context, handle string) (string, error) {
+	return d.adapter.ResetContainer(ctx, handle)
+}
+
+// RestoreServer распаковывает архив в каталог данных контейнера
+func (d *DockerOrchestrator) RestoreServer(ctx context.Context, handle string, r io.Reader) error {
+	return d.adapter.ExtractArchive(ctx, handle, domain.ServerDataPath, r)
 }
 
 // ListServers получает список серверов из Docker
@@ -225,63 +264,27 @@ func (d *DockerOrchestrator) ListServers(ctx context.Context) ([]*domain.Server,
 	var servers []*domain.Server
 	for _, container := range containers {
 		// Фильтруем только наши контейнеры
+		if container.Labels[managedLabel] != managedValue {
+			continue
+		}
+
+		name := container.ID
 		if len(container.Names) > 0 {
-			server := &domain.Server{
-				ID:        container.Names[0][1:], // Убираем "/" в начале
-				Name:      container.Names[0][1:],
-				Status:    domain.ServerStatus(container.State),
-				CreatedAt: time.Unix(container.Created, 0),
-				UpdatedAt: time.Now(),
-			}
-			servers = append(servers, server)
+			name = container.Names[0][1:] // Убираем "/" в начале
 		}
+		servers = append(servers, &domain.Server{
+			ID:                 container.Labels[serverIDLabel],
+			Name:               name,
+			Type:               domain.ServerType(container.Labels["type"]),
+			Region:             container.Labels["region"],
+			Status:             docker.ContainerStatus(container.State),
+			OrchestratorHandle: container.ID,
+			Image:              container.Image,
+			Replicas:           1,
+			CreatedAt:          time.Unix(container.Created, 0),
+			UpdatedAt:          time.Now(),
+		})
 	}
 
 	return servers, nil
 }
-
-// KubernetesOrchestrator обертка для Kubernetes адаптера
-type KubernetesOrchestrator struct {
-	adapter *kubernetes.KubernetesAdapter
-	logger  *zap.Logger
-}
-
-// CreateServer создает сервер в Kubernetes
-func (k *KubernetesOrchestrator) CreateServer(ctx context.Context, server *domain.Server) error {
-	return k.adapter.CreateServer(ctx, server)
-}
-
-// StartServer запускает сервер в Kubernetes
-func (k *KubernetesOrchestrator) StartServer(ctx context.Context, serverID string) error {
-	return k.adapter.StartServer(ctx, serverID)
-}
-
-// StopServer останавливает сервер в Kubernetes
-func (k *KubernetesOrchestrator) StopServer(ctx context.Context, serverID string) error {
-	return k.adapter.StopServer(ctx, serverID)
-}
-
-// DeleteServer удаляет сервер в Kubernetes
-func (k *KubernetesOrchestrator) DeleteServer(ctx context.Context, serverID string) error {
-	return k.adapter.DeleteServer(ctx, serverID)
-}
-
-// GetServerStats получает статистику сервера из Kubernetes
-func (k *KubernetesOrchestrator) GetServerStats(ctx context.Context, serverID string) (*domain.ServerStats, error) {
-	return k.adapter.GetServerStats(ctx, serverID)
-}
-
-// GetServerHealth получает здоровье сервера из Kubernetes
-func (k *KubernetesOrchestrator) GetServerHealth(ctx context.Context, serverID string) (*domain.ServerHealth, error) {
-	return k.adapter.GetServerHealth(ctx, serverID)
-}
-
-// ScaleServer масштабирует сервер в Kubernetes
-func (k *KubernetesOrchestrator) ScaleServer(ctx context.Context, serverID string, replicas int32) error {
-	return k.adapter.ScaleServer(ctx, serverID, replicas)
-}
-
-// ListServers получает список серверов из Kubernetes
-func (k *KubernetesOrchestrator) ListServers(ctx context.Context) ([]*domain.Server, error) {
-	return k.adapter.ListServers(ctx)
-}
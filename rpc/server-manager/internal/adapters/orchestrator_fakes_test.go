@@ -0,0 +1,538 @@
+package adapters
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/docker"
+	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/kubernetes"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+// fakeVolume содержимое тома: путь относительно корня тома -> данные
+type fakeVolume map[string][]byte
+
+// writeTar пишет файлы тома в tar, prefix добавляется к путям записей
+func (v fakeVolume) writeTar(w io.Writer, prefix string) error {
+	writer := tar.NewWriter(w)
+	if prefix != "" {
+		if err := writer.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: prefix + "/", Mode: 0o755}); err != nil {
+			return err
+		}
+	}
+	for name, data := range v {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(prefix, name),
+			Mode:     0o644,
+			Size:     int64(len(data)),
+			ModTime:  time.Now(),
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// readTar распаковывает обычные файлы tar в том. Если задан prefix,
+// распаковываются только записи под ним, prefix отрезается
+func (v fakeVolume) readTar(r io.Reader, prefix string) error {
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if prefix != "" {
+			var found bool
+			if name, found = strings.CutPrefix(name, prefix+"/"); !found {
+				continue
+			}
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		v[name] = data
+	}
+}
+
+// fakeContainer контейнер фейкового Docker Engine
+type fakeContainer struct {
+	summary   container.Summary
+	config    *container.Config
+	startedAt time.Time
+	cpu       float64
+	memory    float64
+	data      fakeVolume // анонимный том domain.ServerDataPath
+}
+
+// fakeDockerClient Docker Engine в памяти
+type fakeDockerClient struct {
+	mu         sync.Mutex
+	containers map[string]*fakeContainer
+	nextID     int
+	watchers   []chan events.Message
+	down       bool // Engine не отвечает на Ping
+	closed     bool
+}
+
+func newFakeDockerClient() *fakeDockerClient {
+	return &fakeDockerClient{containers: make(map[string]*fakeContainer)}
+}
+
+func (f *fakeDockerClient) get(containerID string) (*fakeContainer, error) {
+	c, ok := f.containers[containerID]
+	if !ok {
+		return nil, fmt.Errorf("No such container: %s", containerID)
+	}
+	return c, nil
+}
+
+func (f *fakeDockerClient) ContainerCreate(_ context.Context, config *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ *ocispec.Platform, name string) (container.CreateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, c := range f.containers {
+		if c.summary.Names[0] == "/"+name {
+			return container.CreateResponse{}, fmt.Errorf("Conflict. The container name %q is already in use", name)
+		}
+	}
+	// Образ, который не удается запустить
+	if strings.HasSuffix(config.Image, ":broken") {
+		return container.CreateResponse{}, fmt.Errorf("invalid image %q", config.Image)
+	}
+	f.nextID++
+	id := fmt.Sprintf("%064x", f.nextID)
+	f.containers[id] = &fakeContainer{summary: container.Summary{
+		ID:      id,
+		Names:   []string{"/" + name},
+		Image:   config.Image,
+		Labels:  config.Labels,
+		State:   container.StateCreated,
+		Created: time.Now().Unix(),
+	}, config: config, data: fakeVolume{}}
+	return container.CreateResponse{ID: id}, nil
+}
+
+func (f *fakeDockerClient) ContainerStart(_ context.Context, containerID string, _ container.StartOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.get(containerID)
+	if err != nil {
+		return err
+	}
+	c.summary.State = container.StateRunning
+	c.startedAt = time.Now().Add(-time.Minute)
+	return nil
+}
+
+func (f *fakeDockerClient) ContainerStop(_ context.Context, containerID string, _ container.StopOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.get(containerID)
+	if err != nil {
+		return err
+	}
+	c.summary.State = container.StateExited
+	return nil
+}
+
+func (f *fakeDockerClient) ContainerRemove(_ context.Context, containerID string, _ container.RemoveOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.get(containerID); err != nil {
+		return err
+	}
+	delete(f.containers, containerID)
+	return nil
+}
+
+// ContainerStats отдает один замер с загрузкой, заданной settle
+func (f *fakeDockerClient) ContainerStats(_ context.Context, containerID string, _ bool) (container.StatsResponseReader, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.get(containerID)
+	if err != nil {
+		return container.StatsResponseReader{}, err
+	}
+
+	var sample container.StatsResponse
+	sample.CPUStats.OnlineCPUs = 1
+	sample.CPUStats.SystemUsage = 2_000_000_000
+	sample.CPUStats.CPUUsage.TotalUsage = 1_000_000_000 + uint64(c.cpu*10_000_000)
+	sample.PreCPUStats.SystemUsage = 1_000_000_000
+	sample.PreCPUStats.CPUUsage.TotalUsage = 1_000_000_000
+	sample.MemoryStats.Limit = 1 << 30
+	sample.MemoryStats.Usage = uint64(c.memory/100*(1<<30)) + 1<<20
+	sample.MemoryStats.Stats = map[string]uint64{"inactive_file": 1 << 20}
+	sample.Networks = map[string]container.NetworkStats{"eth0": {RxBytes: 1024, TxBytes: 2048}}
+
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return container.StatsResponseReader{}, err
+	}
+	return container.StatsResponseReader{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeDockerClient) ContainerInspect(_ context.Context, containerID string) (container.InspectResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.get(containerID)
+	if err != nil {
+		return container.InspectResponse{}, err
+	}
+
+	var inspect container.InspectResponse
+	inspect.ContainerJSONBase = &container.ContainerJSONBase{
+		ID:   c.summary.ID,
+		Name: c.summary.Names[0],
+		State: &container.State{
+			Status:    c.summary.State,
+			Running:   c.summary.State == container.StateRunning,
+			StartedAt: c.startedAt.Format(time.RFC3339Nano),
+		},
+	}
+	inspect.Config = c.config
+	return inspect, nil
+}
+
+func (f *fakeDockerClient) ContainerList(_ context.Context, _ container.ListOptions) ([]container.Summary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	containers := make([]container.Summary, 0, len(f.containers))
+	for _, c := range f.containers {
+		containers = append(containers, c.summary)
+	}
+	return containers, nil
+}
+
+func (f *fakeDockerClient) ContainerRename(_ context.Context, containerID, newContainerName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.get(containerID)
+	if err != nil {
+		return err
+	}
+	c.summary.Names = []string{"/" + newContainerName}
+	return nil
+}
+
+// CopyFromContainer отдает каталог, как Docker: записи с префиксом имени
+// каталога
+func (f *fakeDockerClient) CopyFromContainer(_ context.Context, containerID, srcPath string) (io.ReadCloser, container.PathStat, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.get(containerID)
+	if err != nil {
+		return nil, container.PathStat{}, err
+	}
+	if srcPath != domain.ServerDataPath {
+		return nil, container.PathStat{}, fmt.Errorf("Could not find the file %s in container", srcPath)
+	}
+
+	var archive bytes.Buffer
+	if err := c.data.writeTar(&archive, path.Base(srcPath)); err != nil {
+		return nil, container.PathStat{}, err
+	}
+	return io.NopCloser(&archive), container.PathStat{Name: path.Base(srcPath)}, nil
+}
+
+func (f *fakeDockerClient) CopyToContainer(_ context.Context, containerID, dstPath string, content io.Reader, _ container.CopyToContainerOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.get(containerID)
+	if err != nil {
+		return err
+	}
+	// Каталог данных можно распаковать и из архива с записями
+	// "silence/...", указав родительский каталог
+	switch dstPath {
+	case domain.ServerDataPath:
+		return c.data.readTar(content, "")
+	case path.Dir(domain.ServerDataPath):
+		return c.data.readTar(content, path.Base(domain.ServerDataPath))
+	default:
+		return fmt.Errorf("Could not find the file %s in container", dstPath)
+	}
+}
+
+// ImagePull отдает поток прогресса, для тега missing - с ошибкой
+func (f *fakeDockerClient) ImagePull(_ context.Context, ref string, _ image.PullOptions) (io.ReadCloser, error) {
+	stream := `{"status":"Pulling from silence"}` + "\n" + `{"status":"Download complete"}` + "\n"
+	if strings.HasSuffix(ref, ":missing") {
+		stream += fmt.Sprintf(`{"errorDetail":{"message":"manifest for %[1]s not found"},"error":"manifest for %[1]s not found"}`, ref)
+	}
+	return io.NopCloser(strings.NewReader(stream)), nil
+}
+
+// Events подписывает на события, которые отправляет emit. Фильтры не
+// применяются
+func (f *fakeDockerClient) Events(_ context.Context, _ events.ListOptions) (<-chan events.Message, <-chan error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	messages := make(chan events.Message, 16)
+	f.watchers = append(f.watchers, messages)
+	return messages, make(chan error, 1)
+}
+
+// emit отправляет подписчикам событие контейнера с его метками
+func (f *fakeDockerClient) emit(c *fakeContainer, action events.Action, attributes map[string]string) {
+	message := events.Message{
+		Type:     events.ContainerEventType,
+		Action:   action,
+		Actor:    events.Actor{ID: c.summary.ID, Attributes: maps.Clone(c.summary.Labels)},
+		TimeNano: time.Now().UnixNano(),
+	}
+	maps.Copy(message.Actor.Attributes, attributes)
+	for _, watcher := range f.watchers {
+		watcher <- message
+	}
+}
+
+func (f *fakeDockerClient) Ping(_ context.Context) (types.Ping, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.down {
+		return types.Ping{}, fmt.Errorf("Cannot connect to the Docker daemon")
+	}
+	return types.Ping{APIVersion: "1.41"}, nil
+}
+
+func (f *fakeDockerClient) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	return nil
+}
+
+// newDockerFixture Docker оркестратор поверх фейкового Engine
+func newDockerFixture(t *testing.T) *orchestratorFixture {
+	client := newFakeDockerClient()
+	adapter := docker.NewDockerAdapterWithClient(client, zap.NewNop())
+
+	return &orchestratorFixture{
+		orchestrator: NewDockerOrchestrator(adapter, zap.NewNop()),
+		settle: func(t *testing.T, handle string, cpu, memory float64) {
+			client.mu.Lock()
+			defer client.mu.Unlock()
+
+			c, err := client.get(handle)
+			require.NoError(t, err)
+			c.cpu, c.memory = cpu, memory
+		},
+		crash: func(t *testing.T, handle string, oom bool) {
+			client.mu.Lock()
+			defer client.mu.Unlock()
+
+			c, err := client.get(handle)
+			require.NoError(t, err)
+			c.summary.State = container.StateExited
+			exitCode := "1"
+			if oom {
+				client.emit(c, events.ActionOOM, nil)
+				exitCode = "137"
+			}
+			client.emit(c, events.ActionDie, map[string]string{"exitCode": exitCode})
+		},
+	}
+}
+
+// fakeMetricsSource metrics-server в памяти
+type fakeMetricsSource struct {
+	mu   sync.Mutex
+	pods []kubernetes.PodMetrics
+}
+
+func (f *fakeMetricsSource) PodMetrics(_ context.Context, _, _ string) ([]kubernetes.PodMetrics, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]kubernetes.PodMetrics(nil), f.pods...), nil
+}
+
+// fakePodExecutor выполняет tar в подах Job копирования над томами в
+// памяти, том определяется по PersistentVolumeClaim пода
+type fakePodExecutor struct {
+	clientset *fake.Clientset
+	mu        sync.Mutex
+	volumes   map[string]fakeVolume
+}
+
+func (f *fakePodExecutor) Exec(ctx context.Context, namespace, pod, _ string, command []string, stdin io.Reader, stdout io.Writer) error {
+	p, err := f.clientset.CoreV1().Pods(namespace).Get(ctx, pod, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	claim := p.Spec.Volumes[0].PersistentVolumeClaim.ClaimName
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	volume, ok := f.volumes[claim]
+	if !ok {
+		volume = fakeVolume{}
+		f.volumes[claim] = volume
+	}
+
+	switch strings.Join(command[:2], " ") {
+	case "tar cf":
+		return volume.writeTar(stdout, ".")
+	case "tar xf":
+		return volume.readTar(stdin, "")
+	case "find /data":
+		clear(volume)
+		return nil
+	default:
+		return fmt.Errorf("unexpected command %q", command)
+	}
+}
+
+// newKubernetesFixture Kubernetes адаптер поверх фейкового API сервера.
+// Контроллеров нет, поэтому settle сам создает поды Deployment и
+// отмечает реплики готовыми, а для Job сразу создается работающий под
+func newKubernetesFixture(t *testing.T) *orchestratorFixture {
+	const namespace = "silence"
+	clientset := fake.NewSimpleClientset()
+	metrics := &fakeMetricsSource{}
+	executor := &fakePodExecutor{clientset: clientset, volumes: make(map[string]fakeVolume)}
+	ctx := context.Background()
+
+	clientset.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      job.Name + "-pod",
+				Namespace: namespace,
+				Labels:    job.Spec.Template.Labels,
+			},
+			Spec:   job.Spec.Template.Spec,
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		// Сборщика мусора нет, под предыдущей Job удаляется здесь
+		_ = clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), namespace, pod.Name)
+		return false, nil, clientset.Tracker().Add(pod)
+	})
+
+	return &orchestratorFixture{
+		orchestrator: kubernetes.NewKubernetesAdapterWithClient(clientset, metrics, executor, namespace, zap.NewNop()),
+		settle: func(t *testing.T, handle string, cpu, memory float64) {
+			deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, handle, metav1.GetOptions{})
+			require.NoError(t, err)
+
+			replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+			deployment.Status.Replicas = replicas
+			deployment.Status.UpdatedReplicas = replicas
+			deployment.Status.ReadyReplicas = replicas
+			_, err = clientset.AppsV1().Deployments(namespace).UpdateStatus(ctx, deployment, metav1.UpdateOptions{})
+			require.NoError(t, err)
+
+			template := deployment.Spec.Template
+			for i := int32(0); i < replicas; i++ {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("%s-%d", handle, i),
+						Namespace: namespace,
+						Labels:    template.Labels,
+					},
+					Spec: template.Spec,
+					Status: corev1.PodStatus{
+						Phase:     corev1.PodRunning,
+						StartTime: ptr.To(metav1.NewTime(time.Now().Add(-time.Minute))),
+						ContainerStatuses: []corev1.ContainerStatus{{
+							Name:  template.Spec.Containers[0].Name,
+							Ready: true,
+						}},
+					},
+				}
+				// Повторный settle после смены образа заменяет поды
+				_ = clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), namespace, pod.Name)
+				_, err := clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+				require.NoError(t, err)
+
+				limits := template.Spec.Containers[0].Resources.Limits
+				metrics.mu.Lock()
+				metrics.pods = slices.DeleteFunc(metrics.pods, func(m kubernetes.PodMetrics) bool {
+					return m.Name == pod.Name
+				})
+				metrics.pods = append(metrics.pods, kubernetes.PodMetrics{
+					Name:   pod.Name,
+					CPU:    *resource.NewMilliQuantity(int64(float64(limits.Cpu().MilliValue())*cpu/100), resource.DecimalSI),
+					Memory: *resource.NewQuantity(int64(float64(limits.Memory().Value())*memory/100), resource.BinarySI),
+				})
+				metrics.mu.Unlock()
+			}
+		},
+		crash: func(t *testing.T, handle string, oom bool) {
+			pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, handle+"-0", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			// kubelet перезапускает контейнер и запоминает причину завершения
+			terminated := &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}
+			if oom {
+				terminated = &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}
+			}
+			status := &pod.Status.ContainerStatuses[0]
+			status.RestartCount++
+			status.LastTerminationState = corev1.ContainerState{Terminated: terminated}
+			_, err = clientset.CoreV1().Pods(namespace).UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+			require.NoError(t, err)
+		},
+		replicas: func(t *testing.T, handle string) int32 {
+			deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, handle, metav1.GetOptions{})
+			require.NoError(t, err)
+			return ptr.Deref(deployment.Spec.Replicas, 1)
+		},
+	}
+}
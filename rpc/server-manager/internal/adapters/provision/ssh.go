@@ -0,0 +1,348 @@
+package provision
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// binaryPath путь бинарного файла vpn-core на хосте
+	binaryPath = "/usr/local/bin/vpn-core"
+	// configDir каталог файлов окружения серверов на хосте
+	configDir = "/etc/silence"
+	// unitDir каталог unit-файлов systemd
+	unitDir = "/etc/systemd/system"
+	// wireguardDir каталог конфигурации WireGuard на хосте
+	wireguardDir = "/etc/wireguard"
+	// wireguardInterface интерфейс WireGuard сервера
+	wireguardInterface = "wg0"
+	// outputLimit сколько последних байт вывода команды попадает в ошибку
+	outputLimit = 512
+)
+
+var _ ports.Provisioner = (*SSHProvisioner)(nil)
+
+// SSHProvisioner подготавливает хосты VPN по SSH: устанавливает WireGuard и
+// vpn-core, записывает конфигурацию WireGuard, окружение и unit systemd и
+// запускает сервис
+type SSHProvisioner struct {
+	signer         ssh.Signer
+	connectTimeout time.Duration
+	logger         *zap.Logger
+}
+
+// NewSSHProvisioner создает провижинер с закрытым ключом SSH
+// server-manager в формате PEM. connectTimeout ограничивает установку
+// соединения и обмен ключами
+func NewSSHProvisioner(privateKey []byte, connectTimeout time.Duration, logger *zap.Logger) (*SSHProvisioner, error) {
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return &SSHProvisioner{
+		signer:         signer,
+		connectTimeout: connectTimeout,
+		logger:         logger,
+	}, nil
+}
+
+// Provision подготавливает хост сервера. Шаги повторяемы: повторная
+// подготовка обновляет vpn-core и конфигурацию и перезапускает сервис
+func (p *SSHProvisioner) Provision(ctx context.Context, server *domain.Server, req *domain.ProvisionRequest, progress func(step domain.ProvisionStep)) error {
+	progress(domain.ProvisionStepConnect)
+	client, err := p.connect(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", req.Host, err)
+	}
+	defer client.Close()
+
+	host := &remoteHost{client: client, sudo: req.User != "root"}
+	unit := server.ResourceName()
+
+	progress(domain.ProvisionStepPrerequisites)
+	if err := host.run(ctx, prerequisitesScript(req.Mode), nil); err != nil {
+		return fmt.Errorf("failed to install prerequisites: %w", err)
+	}
+
+	progress(domain.ProvisionStepInstall)
+	if err := host.run(ctx, installScript(req), nil); err != nil {
+		return fmt.Errorf("failed to install vpn-core: %w", err)
+	}
+
+	progress(domain.ProvisionStepConfigure)
+	if err := host.run(ctx, wireguardScript(req), nil); err != nil {
+		return fmt.Errorf("failed to configure wireguard: %w", err)
+	}
+	if err := host.upload(ctx, envPath(unit), "0600", envFile(server, req)); err != nil {
+		return fmt.Errorf("failed to write environment: %w", err)
+	}
+	if err := host.upload(ctx, path.Join(unitDir, unit+".service"), "0644", unitFile(server, req)); err != nil {
+		return fmt.Errorf("failed to write systemd unit: %w", err)
+	}
+	if err := host.run(ctx, "systemctl daemon-reload", nil); err != nil {
+		return fmt.Errorf("failed to reload systemd: %w", err)
+	}
+
+	progress(domain.ProvisionStepStart)
+	start := fmt.Sprintf("set -e\nsystemctl enable %[1]s\nsystemctl restart %[1]s\nsystemctl is-active --quiet %[1]s", unit)
+	if err := host.run(ctx, start, nil); err != nil {
+		return fmt.Errorf("failed to start %s: %w", unit, err)
+	}
+
+	p.logger.Info("host provisioned",
+		zap.String("server_id", server.ID),
+		zap.String("host", req.Host),
+		zap.String("mode", string(req.Mode)))
+	return nil
+}
+
+// connect устанавливает SSH соединение с проверкой ключа хоста
+func (p *SSHProvisioner) connect(ctx context.Context, req *domain.ProvisionRequest) (*ssh.Client, error) {
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid host key: %w", err)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            req.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(p.signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         p.connectTimeout,
+	}
+
+	addr := net.JoinHostPort(req.Host, strconv.Itoa(req.SSHPort))
+	dialer := net.Dialer{Timeout: p.connectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Обмен ключами не учитывает контекст, его ограничивает deadline
+	if p.connectTimeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(p.connectTimeout))
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// remoteHost выполняет команды на подготавливаемом хосте
+type remoteHost struct {
+	client *ssh.Client
+	sudo   bool // пользователь не root
+}
+
+// run выполняет скрипт через sh. Ошибка содержит конец вывода скрипта
+func (h *remoteHost) run(ctx context.Context, script string, stdin io.Reader) error {
+	session, err := h.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdin = stdin
+
+	command := "sh -c " + shellQuote(script)
+	if h.sudo {
+		command = "sudo -n " + command
+	}
+
+	type result struct {
+		output []byte
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := session.CombinedOutput(command)
+		done <- result{output, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		session.Close()
+		return ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			return fmt.Errorf("%w: %s", res.err, tail(res.output))
+		}
+		return nil
+	}
+}
+
+// upload записывает файл на хосте атомарно, через временный файл
+func (h *remoteHost) upload(ctx context.Context, target, mode, content string) error {
+	tmp := shellQuote(target + ".tmp")
+	script := fmt.Sprintf("set -e\numask 077\nmkdir -p %s\ncat > %s\nchmod %s %s\nmv -f %s %s",
+		shellQuote(path.Dir(target)), tmp, mode, tmp, tmp, shellQuote(target))
+	return h.run(ctx, script, strings.NewReader(content))
+}
+
+// prerequisitesScript устанавливает недостающие WireGuard, curl и Docker
+// через apt или dnf
+func prerequisitesScript(mode domain.ProvisionMode) string {
+	// Команда и ее пакеты в apt и dnf
+	requirements := [][3]string{{"wg", "wireguard-tools", "wireguard-tools"}}
+	if mode == domain.ProvisionModeContainer {
+		requirements = append(requirements, [3]string{"docker", "docker.io", "moby-engine"})
+	} else {
+		requirements = append(requirements, [3]string{"curl", "curl", "curl"})
+	}
+
+	var script strings.Builder
+	script.WriteString(`set -e
+command -v systemctl >/dev/null || { echo "systemd is required" >&2; exit 1; }
+apt=""
+dnf=""
+`)
+	for _, requirement := range requirements {
+		fmt.Fprintf(&script, "command -v %s >/dev/null || { apt=\"$apt %s\"; dnf=\"$dnf %s\"; }\n",
+			requirement[0], requirement[1], requirement[2])
+	}
+	script.WriteString(`if [ -n "$apt" ]; then
+  if command -v apt-get >/dev/null; then
+    export DEBIAN_FRONTEND=noninteractive
+    apt-get update -q
+    apt-get install -y -q $apt
+  elif command -v dnf >/dev/null; then
+    dnf install -y -q $dnf
+  else
+    echo "unsupported package manager" >&2
+    exit 1
+  fi
+fi
+`)
+	if mode == domain.ProvisionModeContainer {
+		script.WriteString("systemctl enable --now docker\n")
+	}
+	return script.String()
+}
+
+// installScript загружает бинарный файл или образ vpn-core. Бинарный файл
+// устанавливается, только если его sha256 совпадает с заданным
+func installScript(req *domain.ProvisionRequest) string {
+	if req.Mode == domain.ProvisionModeContainer {
+		return "docker pull " + shellQuote(req.Image)
+	}
+	tmp := shellQuote(binaryPath + ".new")
+	checksum := shellQuote(req.BinarySHA256 + "  " + binaryPath + ".new")
+	return fmt.Sprintf(`set -e
+curl -fsSL --retry 3 -o %[1]s %[2]s
+if ! echo %[3]s | sha256sum -c - >/dev/null; then
+  rm -f %[1]s
+  echo "vpn-core checksum mismatch" >&2
+  exit 1
+fi
+chmod 0755 %[1]s
+mv -f %[1]s %[4]s`, tmp, shellQuote(req.BinaryURL), checksum, shellQuote(binaryPath))
+}
+
+// wireguardScript записывает конфигурацию интерфейса WireGuard. Закрытый
+// ключ создается на хосте и не покидает его; повторная подготовка
+// сохраняет ключ, чтобы клиенты не теряли соединение
+func wireguardScript(req *domain.ProvisionRequest) string {
+	key := shellQuote(path.Join(wireguardDir, wireguardInterface+".key"))
+	config := path.Join(wireguardDir, wireguardInterface+".conf")
+	return fmt.Sprintf(`set -e
+umask 077
+mkdir -p %[1]s
+[ -s %[2]s ] || wg genkey > %[2]s
+printf '[Interface]\nPrivateKey = %%s\nListenPort = %[3]d\n' "$(cat %[2]s)" > %[4]s
+mv -f %[4]s %[5]s`, shellQuote(wireguardDir), key, req.ListenPort, shellQuote(config+".tmp"), shellQuote(config))
+}
+
+// envPath путь файла окружения сервера на хосте
+func envPath(unit string) string {
+	return path.Join(configDir, unit+".env")
+}
+
+// envFile окружение vpn-core. Значения из запроса переопределяют
+// стандартные, как при создании контейнера
+func envFile(server *domain.Server, req *domain.ProvisionRequest) string {
+	environment := map[string]string{
+		"SERVER_ID":             server.ID,
+		"SERVER_TYPE":           string(server.Type),
+		"REGION":                server.Region,
+		"WIREGUARD_DIR":         wireguardDir,
+		"WIREGUARD_INTERFACE":   wireguardInterface,
+		"WIREGUARD_LISTEN_PORT": strconv.Itoa(req.ListenPort),
+	}
+	for key, value := range req.Env {
+		environment[key] = value
+	}
+
+	keys := make([]string, 0, len(environment))
+	for key := range environment {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var file strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&file, "%s=%s\n", key, environment[key])
+	}
+	return file.String()
+}
+
+// unitFile unit systemd, запускающий vpn-core напрямую или в контейнере
+func unitFile(server *domain.Server, req *domain.ProvisionRequest) string {
+	unit := server.ResourceName()
+
+	var dependencies, service string
+	if req.Mode == domain.ProvisionModeContainer {
+		dependencies = "Requires=docker.service\nAfter=docker.service network-online.target"
+		service = fmt.Sprintf(`ExecStartPre=-/usr/bin/docker rm -f %[1]s
+ExecStart=/usr/bin/docker run --rm --name %[1]s --network host --cap-add NET_ADMIN --device /dev/net/tun --env-file %[2]s -v /etc/wireguard:/etc/wireguard -v /var/lib/silence/%[1]s:%[3]s %[4]s
+ExecStop=/usr/bin/docker stop %[1]s`, unit, envPath(unit), domain.ServerDataPath, req.Image)
+	} else {
+		dependencies = "After=network-online.target"
+		service = fmt.Sprintf(`EnvironmentFile=%s
+ExecStart=%s
+AmbientCapabilities=CAP_NET_ADMIN`, envPath(unit), binaryPath)
+	}
+
+	return fmt.Sprintf(`[Unit]
+Description=Silence vpn-core %s
+Wants=network-online.target
+%s
+
+[Service]
+%s
+Restart=always
+RestartSec=5
+
+[Install]
+WantedBy=multi-user.target
+`, server.ID, dependencies, service)
+}
+
+// shellQuote заключает строку в одинарные кавычки для sh
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// tail последние outputLimit байт вывода команды
+func tail(output []byte) string {
+	output = bytes.TrimSpace(output)
+	if len(output) > outputLimit {
+		output = output[len(output)-outputLimit:]
+	}
+	return string(output)
+}
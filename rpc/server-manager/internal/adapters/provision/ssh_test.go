@@ -0,0 +1,315 @@
+package provision
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeHost SSH сервер в процессе теста. Скрипты не выполняются, а
+// записываются; содержимое файлов, записанных через cat, сохраняется по
+// пути назначения
+type fakeHost struct {
+	listener net.Listener
+	hostKey  ssh.PublicKey
+	config   *ssh.ServerConfig
+
+	mu       sync.Mutex
+	commands []string          // команды в том виде, в каком пришли
+	scripts  []string          // скрипты, переданные sh -c
+	files    map[string]string // по пути назначения
+	failures map[string]string // фрагмент скрипта -> вывод ошибки
+}
+
+func newFakeHost(t *testing.T, authorized ssh.PublicKey) *fakeHost {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	host := &fakeHost{
+		hostKey:  signer.PublicKey(),
+		files:    make(map[string]string),
+		failures: make(map[string]string),
+	}
+	host.config = &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(authorized.Marshal()) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, nil
+		},
+	}
+	host.config.AddHostKey(signer)
+
+	host.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { host.listener.Close() })
+
+	go host.serve()
+	return host
+}
+
+func (h *fakeHost) serve() {
+	for {
+		conn, err := h.listener.Accept()
+		if err != nil {
+			return
+		}
+		go h.handle(conn)
+	}
+}
+
+func (h *fakeHost) handle(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, h.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go h.session(channel, requests)
+	}
+}
+
+func (h *fakeHost) session(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			_ = req.Reply(false, nil)
+			return
+		}
+		_ = req.Reply(true, nil)
+
+		stdin, _ := io.ReadAll(channel)
+		status := h.exec(payload.Command, string(stdin), channel.Stderr())
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+// exec записывает команду и возвращает код завершения
+func (h *fakeHost) exec(command, stdin string, stderr io.Writer) uint32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.commands = append(h.commands, command)
+	script := unquote(command[strings.Index(command, "sh -c ")+len("sh -c "):])
+	h.scripts = append(h.scripts, script)
+
+	for fragment, output := range h.failures {
+		if strings.Contains(script, fragment) {
+			_, _ = io.WriteString(stderr, output)
+			return 1
+		}
+	}
+	if i := strings.Index(script, "cat > "); i >= 0 {
+		target := strings.Fields(script[i+len("cat > "):])[0]
+		h.files[strings.TrimSuffix(unquote(target), ".tmp")] = stdin
+	}
+	return 0
+}
+
+func (h *fakeHost) fail(fragment, output string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[fragment] = output
+}
+
+func (h *fakeHost) port() int {
+	return h.listener.Addr().(*net.TCPAddr).Port
+}
+
+// unquote обратное shellQuote
+func unquote(value string) string {
+	value = strings.TrimPrefix(strings.TrimSuffix(value, "'"), "'")
+	return strings.ReplaceAll(value, `'\''`, "'")
+}
+
+// clientKey ключ server-manager в формате PEM
+func clientKey(t *testing.T) ([]byte, ssh.PublicKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(private, "")
+	require.NoError(t, err)
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(block), sshPublic
+}
+
+// provisionFixture хост, принимающий ключ провижинера, и запрос на его
+// подготовку
+func provisionFixture(t *testing.T) (*fakeHost, *SSHProvisioner, *domain.Server, *domain.ProvisionRequest) {
+	key, publicKey := clientKey(t)
+	host := newFakeHost(t, publicKey)
+	provisioner, err := NewSSHProvisioner(key, 5*time.Second, zap.NewNop())
+	require.NoError(t, err)
+
+	server := &domain.Server{ID: "vpn-1", Type: domain.ServerTypeVPN, Region: "eu-west-1"}
+	req := &domain.ProvisionRequest{
+		Name:         "eu-vps-1",
+		Host:         "127.0.0.1",
+		SSHPort:      host.port(),
+		User:         "root",
+		HostKey:      string(ssh.MarshalAuthorizedKey(host.hostKey)),
+		Mode:         domain.ProvisionModeBinary,
+		BinaryURL:    "https://releases.example.com/vpn-core/1.2.0/vpn-core-linux-amd64",
+		BinarySHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		ListenPort:   51820,
+		Env:          map[string]string{"LOG_LEVEL": "debug"},
+	}
+	return host, provisioner, server, req
+}
+
+func TestSSHProvisioner_Binary(t *testing.T) {
+	host, provisioner, server, req := provisionFixture(t)
+
+	var steps []domain.ProvisionStep
+	err := provisioner.Provision(context.Background(), server, req, func(step domain.ProvisionStep) {
+		steps = append(steps, step)
+	})
+	require.NoError(t, err)
+
+	// Шаг регистрации выполняет сервис
+	assert.Equal(t, domain.ProvisionSteps[:len(domain.ProvisionSteps)-1], steps)
+
+	host.mu.Lock()
+	defer host.mu.Unlock()
+
+	for _, command := range host.commands {
+		assert.True(t, strings.HasPrefix(command, "sh -c '"), command)
+	}
+	require.Len(t, host.scripts, 7)
+	assert.Contains(t, host.scripts[0], "wireguard-tools")
+	assert.Contains(t, host.scripts[0], `apt="$apt curl"`)
+	assert.NotContains(t, host.scripts[0], "docker")
+	assert.Contains(t, host.scripts[1], "curl -fsSL --retry 3 -o '/usr/local/bin/vpn-core.new' '"+req.BinaryURL+"'")
+	assert.Contains(t, host.scripts[1], "echo '"+req.BinarySHA256+"  /usr/local/bin/vpn-core.new' | sha256sum -c -")
+	assert.Less(t, strings.Index(host.scripts[1], "sha256sum"), strings.Index(host.scripts[1], "mv -f"))
+	assert.Contains(t, host.scripts[2], "[ -s '/etc/wireguard/wg0.key' ] || wg genkey > '/etc/wireguard/wg0.key'")
+	assert.Contains(t, host.scripts[2], `printf '[Interface]\nPrivateKey = %s\nListenPort = 51820\n' "$(cat '/etc/wireguard/wg0.key')"`)
+	assert.Contains(t, host.scripts[2], "mv -f '/etc/wireguard/wg0.conf.tmp' '/etc/wireguard/wg0.conf'")
+	assert.Equal(t, "systemctl daemon-reload", host.scripts[5])
+	assert.Contains(t, host.scripts[6], "systemctl restart silence-vpn-vpn-1")
+
+	assert.Equal(t, "LOG_LEVEL=debug\n"+
+		"REGION=eu-west-1\n"+
+		"SERVER_ID=vpn-1\n"+
+		"SERVER_TYPE=vpn\n"+
+		"WIREGUARD_DIR=/etc/wireguard\n"+
+		"WIREGUARD_INTERFACE=wg0\n"+
+		"WIREGUARD_LISTEN_PORT=51820\n", host.files["/etc/silence/silence-vpn-vpn-1.env"])
+	unit := host.files["/etc/systemd/system/silence-vpn-vpn-1.service"]
+	assert.Contains(t, unit, "EnvironmentFile=/etc/silence/silence-vpn-vpn-1.env\n")
+	assert.Contains(t, unit, "ExecStart=/usr/local/bin/vpn-core\n")
+	assert.Contains(t, unit, "WantedBy=multi-user.target\n")
+}
+
+func TestSSHProvisioner_ContainerWithSudo(t *testing.T) {
+	host, provisioner, server, req := provisionFixture(t)
+	req.User = "deploy"
+	req.Mode = domain.ProvisionModeContainer
+	req.Image = "silence/vpn-core:1.2.0"
+	req.ListenPort = 443
+
+	require.NoError(t, provisioner.Provision(context.Background(), server, req, func(domain.ProvisionStep) {}))
+
+	host.mu.Lock()
+	defer host.mu.Unlock()
+
+	for _, command := range host.commands {
+		assert.True(t, strings.HasPrefix(command, "sudo -n sh -c '"), command)
+	}
+	assert.Contains(t, host.scripts[0], "systemctl enable --now docker")
+	assert.Equal(t, "docker pull 'silence/vpn-core:1.2.0'", host.scripts[1])
+	assert.Contains(t, host.scripts[2], "ListenPort = 443")
+	assert.Contains(t, host.files["/etc/silence/silence-vpn-vpn-1.env"], "WIREGUARD_LISTEN_PORT=443\n")
+
+	unit := host.files["/etc/systemd/system/silence-vpn-vpn-1.service"]
+	assert.Contains(t, unit, "Requires=docker.service\n")
+	assert.Contains(t, unit, "--env-file /etc/silence/silence-vpn-vpn-1.env")
+	assert.Contains(t, unit, "-v /var/lib/silence/silence-vpn-vpn-1:/var/lib/silence silence/vpn-core:1.2.0\n")
+}
+
+func TestSSHProvisioner_Failures(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("failed step", func(t *testing.T) {
+		host, provisioner, server, req := provisionFixture(t)
+		host.fail("curl -fsSL", "curl: (22) The requested URL returned error: 404")
+
+		var steps []domain.ProvisionStep
+		err := provisioner.Provision(ctx, server, req, func(step domain.ProvisionStep) {
+			steps = append(steps, step)
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to install vpn-core")
+		assert.Contains(t, err.Error(), "returned error: 404")
+		assert.Equal(t, domain.ProvisionStepInstall, steps[len(steps)-1])
+	})
+
+	t.Run("unknown host key", func(t *testing.T) {
+		_, provisioner, server, req := provisionFixture(t)
+		_, otherKey := clientKey(t)
+		req.HostKey = string(ssh.MarshalAuthorizedKey(otherKey))
+
+		err := provisioner.Provision(ctx, server, req, func(domain.ProvisionStep) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to connect to 127.0.0.1")
+	})
+
+	t.Run("unauthorized key", func(t *testing.T) {
+		_, _, server, req := provisionFixture(t)
+		otherKey, _ := clientKey(t)
+		provisioner, err := NewSSHProvisioner(otherKey, 5*time.Second, zap.NewNop())
+		require.NoError(t, err)
+
+		err = provisioner.Provision(ctx, server, req, func(domain.ProvisionStep) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to authenticate")
+	})
+
+	t.Run("invalid private key", func(t *testing.T) {
+		_, err := NewSSHProvisioner([]byte("not a key"), 5*time.Second, zap.NewNop())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse private key")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		_, provisioner, server, req := provisionFixture(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := provisioner.Provision(canceled, server, req, func(domain.ProvisionStep) {})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
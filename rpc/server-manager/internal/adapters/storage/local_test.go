@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewLocalStore(root)
+
+	require.NoError(t, store.Put(ctx, "server-1/backup-1.tar", strings.NewReader("archive")))
+
+	content, err := store.Get(ctx, "server-1/backup-1.tar")
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, "archive", string(data))
+
+	// Временных файлов загрузки не остается
+	entries, err := os.ReadDir(filepath.Join(root, "server-1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, store.Delete(ctx, "server-1/backup-1.tar"))
+	require.NoError(t, store.Delete(ctx, "server-1/backup-1.tar"))
+	_, err = store.Get(ctx, "server-1/backup-1.tar")
+	assert.Error(t, err)
+}
+
+func TestLocalStore_RejectsKeysOutsideRoot(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+
+	assert.Error(t, store.Put(context.Background(), "../escape.tar", strings.NewReader("archive")))
+	_, err := store.Get(context.Background(), "/etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestProvider_Store(t *testing.T) {
+	provider := NewProvider("file:///var/backups", S3Config{Endpoint: "http://minio:9000"}, nil)
+
+	store, err := provider.Store("")
+	require.NoError(t, err)
+	assert.Equal(t, "/var/backups", store.(*LocalStore).root)
+
+	store, err = provider.Store("s3://backups/silence/prod")
+	require.NoError(t, err)
+	assert.Equal(t, "http://minio:9000/backups/silence/prod/server-1/b.tar", store.(*S3Store).objectURL("server-1/b.tar"))
+
+	for _, destination := range []string{"ftp://host/path", "s3:///prefix", "file://"} {
+		_, err := provider.Store(destination)
+		assert.Error(t, err, destination)
+	}
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+)
+
+var (
+	_ ports.BackupStore         = (*LocalStore)(nil)
+	_ ports.BackupStore         = (*S3Store)(nil)
+	_ ports.BackupStoreProvider = (*Provider)(nil)
+)
+
+// Provider выбирает хранилище по схеме URL назначения:
+// file:///var/backups или s3://bucket/prefix
+type Provider struct {
+	defaultDestination string
+	s3                 S3Config
+	client             *http.Client
+}
+
+// NewProvider создает провайдер хранилищ. defaultDestination
+// используется, когда назначение не задано
+func NewProvider(defaultDestination string, s3 S3Config, client *http.Client) *Provider {
+	return &Provider{
+		defaultDestination: defaultDestination,
+		s3:                 s3,
+		client:             client,
+	}
+}
+
+// DefaultDestination возвращает назначение по умолчанию
+func (p *Provider) DefaultDestination() string {
+	return p.defaultDestination
+}
+
+// Store возвращает хранилище для назначения destination
+func (p *Provider) Store(destination string) (ports.BackupStore, error) {
+	if destination == "" {
+		destination = p.defaultDestination
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup destination %q: %w", destination, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("invalid backup destination %q: empty path", destination)
+		}
+		return NewLocalStore(u.Path), nil
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid backup destination %q: empty bucket", destination)
+		}
+		return NewS3Store(p.s3, u.Host, u.Path, p.client), nil
+	default:
+		return nil, fmt.Errorf("unsupported backup destination scheme: %q", u.Scheme)
+	}
+}
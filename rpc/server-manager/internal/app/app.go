@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -12,10 +13,13 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/par1ram/silence/rpc/server-manager/internal/adapters"
 	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/database"
-	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/docker"
 	grpcadapter "github.com/par1ram/silence/rpc/server-manager/internal/adapters/grpc"
+	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/provision"
+	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/storage"
 	"github.com/par1ram/silence/rpc/server-manager/internal/config"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
 	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
 	"github.com/par1ram/silence/rpc/server-manager/internal/services"
 	"github.com/par1ram/silence/shared/logger"
@@ -28,6 +32,11 @@ type App struct {
 	logger          *zap.Logger
 	grpcServer      *grpcadapter.Server
 	serverService   ports.ServerService
+	autoScaler      *services.AutoScaler
+	backupScheduler *services.BackupScheduler
+	statsCollector  *services.StatsCollector
+	nodeMonitor     *services.NodeMonitor
+	reconciler      *services.Reconciler
 	shutdownTimeout time.Duration
 }
 
@@ -58,6 +67,11 @@ func resolveMigrationsDir(logger *zap.Logger) string {
 func New(logger *zap.Logger) (*App, error) {
 	cfg := config.Load()
 
+	// Ошибка в политике иначе проявилась бы только при первой сверке
+	if cfg.Reconcile.Enabled && !domain.OrphanPolicy(cfg.Reconcile.OrphanPolicy).Valid() {
+		return nil, fmt.Errorf("unknown orphan policy: %s", cfg.Reconcile.OrphanPolicy)
+	}
+
 	// Подключаемся к базе данных
 	db, err := sql.Open("postgres", fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -81,49 +95,107 @@ func New(logger *zap.Logger) (*App, error) {
 	}
 	// === END ===
 
-	// Создаем Docker адаптер
-	dockerAdapter, err := docker.NewDockerAdapter(
-		cfg.Docker.Host,
-		cfg.Docker.APIVersion,
-		cfg.Docker.Timeout,
-		logger,
-	)
+	// Создаем репозитории (заглушки для остальных репозиториев)
+	serverRepo := database.NewPostgresRepository(db, logger)
+	scalingRepo := database.NewScalingRepository(db, logger)
+	backupRepo := database.NewBackupRepository(db, logger)
+	updateRepo := database.NewUpdateRepository(db, logger)
+	statsRepo := database.NewStatsRepository(db, logger)
+	healthRepo := database.NewHealthRepository(db, logger)
+	regionRepo := database.NewRegionRepository(db, logger)
+	nodeRepo := database.NewNodeRepository(db, logger)
+	provisionRepo := database.NewProvisionRepository(db, logger)
+
+	// Создаем оркестратор (docker, docker-multihost или kubernetes,
+	// ORCHESTRATOR_TYPE); docker-multihost размещает серверы на узлах
+	// реестра
+	orchestrator, err := adapters.NewOrchestratorFactory(cfg, nodeRepo, logger).CreateOrchestrator()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create docker adapter: %w", err)
+		return nil, fmt.Errorf("failed to create orchestrator: %w", err)
 	}
 
-	// Создаем репозитории (заглушки для остальных репозиториев)
-	serverRepo := database.NewPostgresRepository(db, logger)
+	// Хранилища резервных копий (BACKUP_DESTINATION, S3_*)
+	backupStores := storage.NewProvider(cfg.Backup.Destination, storage.S3Config{
+		Endpoint:  cfg.Backup.S3.Endpoint,
+		Region:    cfg.Backup.S3.Region,
+		AccessKey: cfg.Backup.S3.AccessKey,
+		SecretKey: cfg.Backup.S3.SecretKey,
+	}, http.DefaultClient)
 
-	// TODO: создать остальные репозитории
-	// statsRepo := database.NewStatsRepository(db, logger)
-	// healthRepo := database.NewHealthRepository(db, logger)
-	// scalingRepo := database.NewScalingRepository(db, logger)
-	// backupRepo := database.NewBackupRepository(db, logger)
-	// updateRepo := database.NewUpdateRepository(db, logger)
+	// Подготовка хостов VPN по SSH ключом PROVISION_SSH_KEY
+	// (PROVISION_SSH_TIMEOUT); без ключа подготовка отключена
+	var provisioner ports.Provisioner
+	if cfg.Provision.SSHKey != "" {
+		key, err := os.ReadFile(cfg.Provision.SSHKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provision ssh key: %w", err)
+		}
+		sshProvisioner, err := provision.NewSSHProvisioner(key, cfg.Provision.SSHTimeout, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provisioner: %w", err)
+		}
+		provisioner = sshProvisioner
+	}
 
 	// Создаем сервисы
-	serverService := services.NewServerService(
-		serverRepo,
-		nil, // statsRepo
-		nil, // healthRepo
-		nil, // scalingRepo
-		nil, // backupRepo
-		nil, // updateRepo
-		dockerAdapter,
-		logger,
-	)
+	serverService := services.NewServerService(services.ServerServiceOptions{
+		ServerRepo:       serverRepo,
+		StatsRepo:        statsRepo,
+		HealthRepo:       healthRepo,
+		ScalingRepo:      scalingRepo,
+		BackupRepo:       backupRepo,
+		UpdateRepo:       updateRepo,
+		RegionRepo:       regionRepo,
+		NodeRepo:         nodeRepo,
+		ProvisionRepo:    provisionRepo,
+		Orchestrator:     orchestrator,
+		Provisioner:      provisioner,
+		BackupStores:     backupStores,
+		Logger:           logger,
+		ProvisionTimeout: cfg.Provision.Timeout,
+	})
+
+	// Подготовки, прерванные предыдущей остановкой, не продолжаются
+	if err := serverService.FailInterruptedProvisions(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to fail interrupted provisions: %w", err)
+	}
+
+	// Регионы из DEFAULT_REGION и REGIONS создаются, если их еще нет;
+	// остальные параметры регионов задаются через API
+	regions := append([]string{cfg.Geographic.DefaultRegion}, cfg.Geographic.Regions...)
+	if err := serverService.EnsureRegions(context.Background(), regions); err != nil {
+		return nil, fmt.Errorf("failed to create regions: %w", err)
+	}
 
 	// Создаем gRPC сервер
 	grpcServer := grpcadapter.NewServer(serverService, logger, cfg)
 
-	return &App{
+	app := &App{
 		config:          cfg,
 		logger:          logger,
 		grpcServer:      grpcServer,
 		serverService:   serverService,
 		shutdownTimeout: 30 * time.Second,
-	}, nil
+	}
+	if cfg.Geographic.AutoScaling {
+		app.autoScaler = services.NewAutoScaler(serverService, cfg.Geographic.ScalingInterval, cfg.Geographic.ScalingDryRun, logger)
+	}
+	if cfg.Backup.Scheduler {
+		app.backupScheduler = services.NewBackupScheduler(serverService, cfg.Backup.SchedulerInterval, logger)
+	}
+	if cfg.Monitoring.StatsCollector {
+		app.statsCollector = services.NewStatsCollector(serverService, cfg.Monitoring.MetricsInterval,
+			cfg.Monitoring.StatsRawRetention, cfg.Monitoring.StatsRetention, logger)
+	}
+	if cfg.Orchestrator.Type == "docker-multihost" {
+		app.nodeMonitor = services.NewNodeMonitor(serverService, cfg.Monitoring.HealthCheckInterval, logger)
+	}
+	if cfg.Reconcile.Enabled {
+		app.reconciler = services.NewReconciler(serverService, cfg.Reconcile.Interval, cfg.Reconcile.DryRun,
+			domain.OrphanPolicy(cfg.Reconcile.OrphanPolicy), logger)
+	}
+
+	return app, nil
 }
 
 // Start запускает приложение
@@ -133,6 +205,31 @@ func (a *App) Start() error {
 		zap.String("version", a.config.Version),
 	)
 
+	// Запускаем автомасштабирование
+	if a.autoScaler != nil {
+		a.autoScaler.Start(context.Background())
+	}
+
+	// Запускаем расписания резервного копирования
+	if a.backupScheduler != nil {
+		a.backupScheduler.Start(context.Background())
+	}
+
+	// Запускаем сбор статистики
+	if a.statsCollector != nil {
+		a.statsCollector.Start(context.Background())
+	}
+
+	// Запускаем проверку узлов
+	if a.nodeMonitor != nil {
+		a.nodeMonitor.Start(context.Background())
+	}
+
+	// Запускаем сверку серверов
+	if a.reconciler != nil {
+		a.reconciler.Start(context.Background())
+	}
+
 	// Запускаем gRPC сервер
 	if err := a.grpcServer.Start(context.Background()); err != nil {
 		return fmt.Errorf("failed to start gRPC server: %w", err)
@@ -146,11 +243,31 @@ func (a *App) Start() error {
 func (a *App) Shutdown(ctx context.Context) error {
 	a.logger.Info("Shutting down Server Manager service...")
 
+	// Останавливаем автомасштабирование до закрытия соединений
+	if a.autoScaler != nil {
+		a.autoScaler.Stop()
+	}
+	if a.backupScheduler != nil {
+		a.backupScheduler.Stop()
+	}
+	if a.statsCollector != nil {
+		a.statsCollector.Stop()
+	}
+	if a.nodeMonitor != nil {
+		a.nodeMonitor.Stop()
+	}
+	if a.reconciler != nil {
+		a.reconciler.Stop()
+	}
+
 	// Останавливаем gRPC сервер
 	if err := a.grpcServer.Stop(ctx); err != nil {
 		a.logger.Error("Failed to stop gRPC server", zap.Error(err))
 	}
 
+	// Новые подготовки уже не запускаются; текущие отменяются
+	a.serverService.Stop()
+
 	a.logger.Info("Server Manager service stopped gracefully")
 	return nil
 }
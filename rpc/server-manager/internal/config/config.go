@@ -36,6 +36,15 @@ type Config struct {
 
 	// Географическое распределение
 	Geographic GeographicConfig
+
+	// Резервное копирование
+	Backup BackupConfig
+
+	// Сверка серверов с оркестратором
+	Reconcile ReconcileConfig
+
+	// Подготовка хостов по SSH
+	Provision ProvisionConfig
 }
 
 // DatabaseConfig конфигурация базы данных
@@ -57,7 +66,7 @@ type DockerConfig struct {
 
 // OrchestratorConfig конфигурация оркестратора
 type OrchestratorConfig struct {
-	Type       string // "docker" или "kubernetes"
+	Type       string // "docker", "docker-multihost" или "kubernetes"
 	Kubeconfig string // путь к kubeconfig файлу
 	Namespace  string // namespace для Kubernetes
 }
@@ -65,8 +74,11 @@ type OrchestratorConfig struct {
 // MonitoringConfig конфигурация мониторинга
 type MonitoringConfig struct {
 	HealthCheckInterval time.Duration
-	MetricsInterval     time.Duration
+	MetricsInterval     time.Duration // период сбора статистики серверов
 	AlertThreshold      float64
+	StatsCollector      bool          // сохранять историю статистики и здоровья
+	StatsRawRetention   time.Duration // срок хранения исходных замеров до усреднения по часам
+	StatsRetention      time.Duration // срок хранения истории
 }
 
 // GRPCConfig конфигурация gRPC сервера
@@ -76,9 +88,42 @@ type GRPCConfig struct {
 
 // GeographicConfig конфигурация географического распределения
 type GeographicConfig struct {
-	DefaultRegion string
-	Regions       []string
-	AutoScaling   bool
+	DefaultRegion   string   // создается при запуске вместе с Regions
+	Regions         []string // регионы, которых нет в базе, создаются при запуске
+	AutoScaling     bool
+	ScalingInterval time.Duration // период оценки политик масштабирования
+	ScalingDryRun   bool          // только логировать решения масштабирования
+}
+
+// BackupConfig конфигурация резервного копирования
+type BackupConfig struct {
+	Destination       string        // хранилище по умолчанию: file:///path или s3://bucket/prefix
+	Scheduler         bool          // выполнять расписания резервного копирования
+	SchedulerInterval time.Duration // период проверки расписаний
+	S3                S3Config
+}
+
+// ReconcileConfig конфигурация сверки серверов
+type ReconcileConfig struct {
+	Enabled      bool          // периодически сверять серверы с оркестратором
+	Interval     time.Duration // период сверки
+	DryRun       bool          // только логировать расхождения
+	OrphanPolicy string        // "ignore", "adopt" или "remove"
+}
+
+// ProvisionConfig конфигурация подготовки хостов по SSH
+type ProvisionConfig struct {
+	SSHKey     string        // файл закрытого ключа SSH; пустой - подготовка отключена
+	SSHTimeout time.Duration // ожидание соединения и обмена ключами
+	Timeout    time.Duration // ограничение всей подготовки хоста
+}
+
+// S3Config доступ к S3-совместимому хранилищу
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
 }
 
 // Load загружает конфигурацию из переменных окружения
@@ -121,12 +166,42 @@ func Load() *Config {
 			HealthCheckInterval: getEnvDuration("HEALTH_CHECK_INTERVAL", 30*time.Second),
 			MetricsInterval:     getEnvDuration("METRICS_INTERVAL", 60*time.Second),
 			AlertThreshold:      getEnvFloat("ALERT_THRESHOLD", 0.8),
+			StatsCollector:      getEnvBool("STATS_COLLECTOR", true),
+			StatsRawRetention:   getEnvDuration("STATS_RAW_RETENTION", 24*time.Hour),
+			StatsRetention:      getEnvDuration("STATS_RETENTION", 30*24*time.Hour),
 		},
 
 		Geographic: GeographicConfig{
-			DefaultRegion: getEnv("DEFAULT_REGION", "us-east-1"),
-			Regions:       getEnvSlice("REGIONS", []string{"us-east-1", "us-west-1", "eu-west-1"}),
-			AutoScaling:   getEnvBool("AUTO_SCALING", true),
+			DefaultRegion:   getEnv("DEFAULT_REGION", "us-east-1"),
+			Regions:         getEnvSlice("REGIONS", []string{"us-east-1", "us-west-1", "eu-west-1"}),
+			AutoScaling:     getEnvBool("AUTO_SCALING", true),
+			ScalingInterval: getEnvDuration("SCALING_INTERVAL", time.Minute),
+			ScalingDryRun:   getEnvBool("SCALING_DRY_RUN", false),
+		},
+
+		Backup: BackupConfig{
+			Destination:       getEnv("BACKUP_DESTINATION", "file:///var/lib/server-manager/backups"),
+			Scheduler:         getEnvBool("BACKUP_SCHEDULER", true),
+			SchedulerInterval: getEnvDuration("BACKUP_SCHEDULER_INTERVAL", time.Minute),
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				AccessKey: getEnv("S3_ACCESS_KEY_ID", ""),
+				SecretKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			},
+		},
+
+		Reconcile: ReconcileConfig{
+			Enabled:      getEnvBool("RECONCILER", true),
+			Interval:     getEnvDuration("RECONCILE_INTERVAL", 5*time.Minute),
+			DryRun:       getEnvBool("RECONCILE_DRY_RUN", true),
+			OrphanPolicy: getEnv("ORPHAN_POLICY", "ignore"),
+		},
+
+		Provision: ProvisionConfig{
+			SSHKey:     getEnv("PROVISION_SSH_KEY", ""),
+			SSHTimeout: getEnvDuration("PROVISION_SSH_TIMEOUT", 30*time.Second),
+			Timeout:    getEnvDuration("PROVISION_TIMEOUT", 30*time.Minute),
 		},
 	}
 
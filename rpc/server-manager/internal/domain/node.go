@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+// NodeState состояние узла для размещения серверов
+type NodeState string
+
+const (
+	// NodeStateActive узел принимает новые серверы
+	NodeStateActive NodeState = "active"
+	// NodeStateCordoned новые серверы на узел не размещаются, работающие
+	// остаются
+	NodeStateCordoned NodeState = "cordoned"
+	// NodeStateDraining серверы переносятся с узла на другие узлы; после
+	// переноса узел остается cordoned
+	NodeStateDraining NodeState = "draining"
+)
+
+// NodeKind способ управления узлом
+type NodeKind string
+
+const (
+	// NodeKindDocker узел Docker Engine, серверы размещает оркестратор
+	NodeKindDocker NodeKind = "docker"
+	// NodeKindSSH хост, подготовленный по SSH под один сервер; оркестратор
+	// его не использует
+	NodeKindSSH NodeKind = "ssh"
+)
+
+// Node узел, на котором размещаются серверы. Пустые пути TLS - соединение
+// без TLS
+type Node struct {
+	ID          string            `json:"id"`
+	Kind        NodeKind          `json:"kind"`
+	Endpoint    string            `json:"endpoint"` // tcp://host:2376, unix:///var/run/docker.sock, ssh://root@host:22
+	TLSCACert   string            `json:"tls_ca_cert,omitempty"`
+	TLSCert     string            `json:"tls_cert,omitempty"`
+	TLSKey      string            `json:"tls_key,omitempty"`
+	Region      string            `json:"region"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Capacity    int               `json:"capacity"` // число серверов, 0 - без ограничения
+	State       NodeState         `json:"state"`
+	Healthy     bool              `json:"healthy"`
+	Message     string            `json:"message,omitempty"` // результат последней проверки
+	LastCheckAt *time.Time        `json:"last_check_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Docker управляется ли узел через Docker Engine. Узлы без вида
+// зарегистрированы до появления SSH-узлов
+func (n *Node) Docker() bool {
+	return n.Kind != NodeKindSSH
+}
+
+// Schedulable можно ли размещать на узле новые серверы
+func (n *Node) Schedulable() bool {
+	return n.State == NodeStateActive && n.Healthy
+}
+
+// MatchLabels есть ли у узла все метки selector
+func (n *Node) MatchLabels(selector map[string]string) bool {
+	for key, value := range selector {
+		if n.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
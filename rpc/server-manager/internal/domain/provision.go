@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// ProvisionMode способ установки vpn-core на хост
+type ProvisionMode string
+
+const (
+	// ProvisionModeBinary бинарный файл vpn-core под управлением systemd
+	ProvisionModeBinary ProvisionMode = "binary"
+	// ProvisionModeContainer контейнер vpn-core в локальном Docker хоста
+	// под управлением systemd
+	ProvisionModeContainer ProvisionMode = "container"
+)
+
+// ProvisionStep шаг подготовки хоста
+type ProvisionStep string
+
+const (
+	ProvisionStepConnect       ProvisionStep = "connect"
+	ProvisionStepPrerequisites ProvisionStep = "prerequisites" // WireGuard и Docker
+	ProvisionStepInstall       ProvisionStep = "install"       // бинарный файл или образ vpn-core
+	ProvisionStepConfigure     ProvisionStep = "configure"     // окружение и unit systemd
+	ProvisionStepStart         ProvisionStep = "start"
+	ProvisionStepRegister      ProvisionStep = "register" // сервер становится running
+)
+
+// ProvisionSteps шаги подготовки в порядке выполнения
+var ProvisionSteps = []ProvisionStep{
+	ProvisionStepConnect,
+	ProvisionStepPrerequisites,
+	ProvisionStepInstall,
+	ProvisionStepConfigure,
+	ProvisionStepStart,
+	ProvisionStepRegister,
+}
+
+// Статусы подготовки хоста
+const (
+	ProvisionStatusInProgress = "in_progress"
+	ProvisionStatusCompleted  = "completed"
+	ProvisionStatusFailed     = "failed"
+)
+
+// ProvisionRequest запрос на подготовку хоста VPN по SSH. Соединение
+// использует ключ SSH из конфигурации server-manager; HostKey - открытый
+// ключ хоста в формате authorized_keys, с которым сверяется соединение
+type ProvisionRequest struct {
+	Name         string            `json:"name" validate:"required"`
+	Region       string            `json:"region"` // пустой - выбирается размещением
+	Host         string            `json:"host" validate:"required"`
+	SSHPort      int               `json:"ssh_port"` // по умолчанию 22
+	User         string            `json:"user"`     // по умолчанию root, иначе команды выполняются через sudo
+	HostKey      string            `json:"host_key" validate:"required"`
+	Mode         ProvisionMode     `json:"mode"`          // по умолчанию binary
+	BinaryURL    string            `json:"binary_url"`    // обязателен для binary
+	BinarySHA256 string            `json:"binary_sha256"` // обязателен для binary, hex
+	Image        string            `json:"image"`         // для container; по умолчанию образ VPN
+	ListenPort   int               `json:"listen_port"`   // порт WireGuard, по умолчанию 51820
+	Env          map[string]string `json:"env,omitempty"`
+}
+
+// Provision ход подготовки хоста сервера. Хранится последняя подготовка
+type Provision struct {
+	ServerID    string        `json:"server_id"`
+	Host        string        `json:"host"`
+	Mode        ProvisionMode `json:"mode"`
+	Status      string        `json:"status"`
+	Step        ProvisionStep `json:"step"`
+	Progress    int           `json:"progress"`
+	Message     string        `json:"message"`
+	StartedAt   time.Time     `json:"started_at"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,85 @@
+package domain
+
+import "time"
+
+// OrphanPolicy что делать с ресурсами оркестратора, созданными
+// server-manager, у которых нет сервера в базе данных
+type OrphanPolicy string
+
+const (
+	// OrphanPolicyIgnore ресурс только попадает в отчет
+	OrphanPolicyIgnore OrphanPolicy = "ignore"
+	// OrphanPolicyAdopt для ресурса создается сервер с ID из его меток
+	OrphanPolicyAdopt OrphanPolicy = "adopt"
+	// OrphanPolicyRemove ресурс удаляется вместе с данными
+	OrphanPolicyRemove OrphanPolicy = "remove"
+)
+
+// Valid известна ли политика; пустая политика означает ignore
+func (p OrphanPolicy) Valid() bool {
+	switch p {
+	case "", OrphanPolicyIgnore, OrphanPolicyAdopt, OrphanPolicyRemove:
+		return true
+	}
+	return false
+}
+
+// DriftType вид расхождения базы данных и оркестратора
+type DriftType string
+
+const (
+	// DriftStatus статус в базе не совпадает с фактическим
+	DriftStatus DriftType = "status"
+	// DriftStopped сервер должен работать, но его ресурс остановлен или
+	// завершился с ошибкой
+	DriftStopped DriftType = "stopped"
+	// DriftMissing ресурса сервера нет в оркестраторе
+	DriftMissing DriftType = "missing"
+	// DriftHandle handle или узел сервера в базе устарели
+	DriftHandle DriftType = "handle"
+	// DriftOrphan ресурс без сервера в базе или лишний ресурс сервера
+	DriftOrphan DriftType = "orphan"
+)
+
+// DriftAction действие, устраняющее расхождение
+type DriftAction string
+
+const (
+	DriftActionNone         DriftAction = "none"
+	DriftActionUpdateStatus DriftAction = "update_status"
+	DriftActionUpdateHandle DriftAction = "update_handle"
+	DriftActionRestart      DriftAction = "restart"
+	DriftActionAdopt        DriftAction = "adopt"
+	DriftActionRemove       DriftAction = "remove"
+)
+
+// Drift расхождение сервера в базе и ресурса оркестратора
+type Drift struct {
+	ServerID string      `json:"server_id"`
+	Name     string      `json:"name"`
+	NodeID   string      `json:"node_id,omitempty"`
+	Handle   string      `json:"handle,omitempty"` // ресурс оркестратора
+	Type     DriftType   `json:"type"`
+	Expected string      `json:"expected,omitempty"` // значение в базе
+	Actual   string      `json:"actual,omitempty"`   // значение в оркестраторе
+	Action   DriftAction `json:"action"`
+	Applied  bool        `json:"applied"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// ReconcileRequest параметры сверки серверов. В режиме DryRun расхождения
+// только попадают в отчет
+type ReconcileRequest struct {
+	OrphanPolicy OrphanPolicy `json:"orphan_policy"` // по умолчанию ignore
+	DryRun       bool         `json:"dry_run"`
+}
+
+// DriftReport результат сверки серверов
+type DriftReport struct {
+	Drifts       []*Drift     `json:"drifts"`
+	Checked      int          `json:"checked"` // сверено серверов
+	Skipped      int          `json:"skipped"` // серверы недоступных узлов и серверы в процессе изменения
+	OrphanPolicy OrphanPolicy `json:"orphan_policy"`
+	DryRun       bool         `json:"dry_run"`
+	CheckedAt    time.Time    `json:"checked_at"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoCapacity в подходящих регионах нет свободных серверов или места
+// для новых
+var ErrNoCapacity = errors.New("no capacity for placement")
+
+// DefaultRegionMaxLoad загрузка сервера, %, выше которой он не получает
+// новых пользователей, если у региона не задан MaxLoad. Загрузка - наиболее
+// занятый из ресурсов CPU и памяти
+const DefaultRegionMaxLoad = 80.0
+
+// Region регион размещения серверов. Нулевые координаты означают, что
+// расположение региона неизвестно
+type Region struct {
+	Code       string    `json:"code"`
+	Name       string    `json:"name"`
+	Country    string    `json:"country,omitempty"` // код страны ISO 3166-1 alpha-2
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	MaxServers int       `json:"max_servers"` // 0 - без ограничения
+	MaxLoad    float64   `json:"max_load"`    // 0 - DefaultRegionMaxLoad
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// HasLocation известно ли расположение региона
+func (r *Region) HasLocation() bool {
+	return r.Latitude != 0 || r.Longitude != 0
+}
+
+// LoadLimit загрузка сервера, %, выше которой он не получает новых
+// пользователей
+func (r *Region) LoadLimit() float64 {
+	if r.MaxLoad > 0 {
+		return r.MaxLoad
+	}
+	return DefaultRegionMaxLoad
+}
+
+// PlacementRequest запрос на выбор сервера для пользователя. Подсказки о
+// расположении клиента необязательны: без координат используется страна,
+// без страны - только загрузка
+type PlacementRequest struct {
+	ServerType  ServerType `json:"server_type"`      // по умолчанию vpn
+	Region      string     `json:"region,omitempty"` // предпочтительный регион
+	Country     string     `json:"country,omitempty"`
+	Latitude    float64    `json:"latitude"`
+	Longitude   float64    `json:"longitude"`
+	HasLocation bool       `json:"has_location"`
+}
+
+// Placement выбранный для пользователя сервер
+type Placement struct {
+	Server     *Server `json:"server"`
+	Region     *Region `json:"region"`
+	Load       float64 `json:"load"`        // загрузка сервера (CPU или памяти), %
+	DistanceKm float64 `json:"distance_km"` // 0, если расстояние неизвестно
+	LatencyMs  float64 `json:"latency_ms"`  // оценка задержки по расстоянию
+	Reason     string  `json:"reason"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// ScalingAction действие масштабирования
+type ScalingAction string
+
+const (
+	ScalingActionScaleUp   ScalingAction = "scale_up"
+	ScalingActionScaleDown ScalingAction = "scale_down"
+	ScalingActionNone      ScalingAction = "none"
+)
+
+// ScalingDecision решение политики масштабирования для группы серверов
+// одного типа и региона. Записывается в историю масштабирования.
+type ScalingDecision struct {
+	ID            int64         `json:"id"`
+	PolicyID      string        `json:"policy_id"`
+	ServerType    ServerType    `json:"server_type"`
+	Region        string        `json:"region"`
+	Action        ScalingAction `json:"action"`
+	Reason        string        `json:"reason"`
+	ServersBefore int           `json:"servers_before"`
+	ServersAfter  int           `json:"servers_after"`
+	CPUUsage      float64       `json:"cpu_usage"`    // средняя загрузка группы, %
+	MemoryUsage   float64       `json:"memory_usage"` // среднее использование памяти группы, %
+	DryRun        bool          `json:"dry_run"`
+	TriggeredAt   time.Time     `json:"triggered_at"`
+}
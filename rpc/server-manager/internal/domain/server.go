@@ -1,9 +1,24 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
+// ErrNotSupported операция не поддерживается оркестратором
+var ErrNotSupported = errors.New("operation not supported by orchestrator")
+
+// ErrServerExists сервер с таким ID уже есть, в том числе удаленный
+var ErrServerExists = errors.New("server already exists")
+
+// ErrServerNotFound сервера нет или он удален
+var ErrServerNotFound = errors.New("server not found")
+
+// ServerDataPath каталог состояния сервера внутри контейнера, который
+// попадает в резервные копии
+const ServerDataPath = "/var/lib/silence"
+
 // ServerStatus статус сервера
 type ServerStatus string
 
@@ -13,6 +28,7 @@ const (
 	ServerStatusStopped  ServerStatus = "stopped"
 	ServerStatusError    ServerStatus = "error"
 	ServerStatusDeleting ServerStatus = "deleting"
+	ServerStatusUpdating ServerStatus = "updating"
 )
 
 // ServerType тип сервера
@@ -27,28 +43,46 @@ const (
 
 // Server модель сервера
 type Server struct {
-	ID        string       `json:"id" db:"id"`
-	Name      string       `json:"name" db:"name"`
-	Type      ServerType   `json:"type" db:"type"`
-	Status    ServerStatus `json:"status" db:"status"`
-	Region    string       `json:"region" db:"region"`
-	IP        string       `json:"ip" db:"ip"`
-	Port      int          `json:"port" db:"port"`
-	CPU       float64      `json:"cpu" db:"cpu"`
-	Memory    float64      `json:"memory" db:"memory"`
-	Disk      float64      `json:"disk" db:"disk"`
-	Network   float64      `json:"network" db:"network"`
-	CreatedAt time.Time    `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
-	DeletedAt *time.Time   `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID                 string            `json:"id" db:"id"`
+	Name               string            `json:"name" db:"name"`
+	Type               ServerType        `json:"type" db:"type"`
+	Status             ServerStatus      `json:"status" db:"status"`
+	Region             string            `json:"region" db:"region"`
+	IP                 string            `json:"ip" db:"ip"`
+	Port               int               `json:"port" db:"port"`
+	CPU                float64           `json:"cpu" db:"cpu"`
+	Memory             float64           `json:"memory" db:"memory"`
+	Disk               float64           `json:"disk" db:"disk"`
+	Network            float64           `json:"network" db:"network"`
+	OrchestratorHandle string            `json:"orchestrator_handle,omitempty" db:"orchestrator_handle"` // ID контейнера или имя Deployment
+	Image              string            `json:"image,omitempty" db:"image"`                             // образ ПО сервера
+	NodeID             string            `json:"node_id,omitempty" db:"node_id"`                         // узел Docker, на котором работает сервер
+	NodeSelector       map[string]string `json:"node_selector,omitempty" db:"node_selector"`             // метки узла для размещения и переноса
+	Replicas           int32             `json:"replicas,omitempty" db:"-"`                              // желаемое число реплик по данным оркестратора
+	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at" db:"updated_at"`
+	DeletedAt          *time.Time        `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// ResourceName имя ресурса сервера в оркестраторе
+func (s *Server) ResourceName() string {
+	return fmt.Sprintf("silence-%s-%s", s.Type, s.ID)
+}
+
+// ServerSpec параметры запуска сервера в оркестраторе
+type ServerSpec struct {
+	Image        string            `json:"image"`
+	Env          map[string]string `json:"env,omitempty"`
+	NodeSelector map[string]string `json:"node_selector,omitempty"` // метки узла для размещения
 }
 
 // CreateServerRequest запрос на создание сервера
 type CreateServerRequest struct {
-	Name   string            `json:"name" validate:"required"`
-	Type   ServerType        `json:"type" validate:"required"`
-	Region string            `json:"region" validate:"required"`
-	Config map[string]string `json:"config,omitempty"`
+	Name         string            `json:"name" validate:"required"`
+	Type         ServerType        `json:"type" validate:"required"`
+	Region       string            `json:"region" validate:"required"`
+	Config       map[string]string `json:"config,omitempty"`
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
 }
 
 // UpdateServerRequest запрос на обновление сервера
@@ -79,10 +113,15 @@ type ServerHealthOld struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
-// ScalingPolicy политика масштабирования
+// ScalingPolicy политика масштабирования группы серверов одного типа.
+// Пустой Region - каждый регион с серверами этого типа оценивается
+// отдельно. Пороги задаются долей от 0 до 1, число серверов считается в
+// репликах.
 type ScalingPolicy struct {
 	ID                string        `json:"id"`
 	Name              string        `json:"name"`
+	ServerType        ServerType    `json:"server_type"`
+	Region            string        `json:"region,omitempty"`
 	MinServers        int           `json:"min_servers"`
 	MaxServers        int           `json:"max_servers"`
 	CPUThreshold      float64       `json:"cpu_threshold"`
@@ -98,20 +137,37 @@ type BackupConfig struct {
 	ServerID    string     `json:"server_id"`
 	Schedule    string     `json:"schedule"`  // cron expression
 	Retention   int        `json:"retention"` // days
-	Type        string     `json:"type"`      // full, incremental
-	Destination string     `json:"destination"`
+	Type        BackupType `json:"type"`
+	Destination string     `json:"destination"` // URL хранилища: file:///path или s3://bucket/prefix
 	Enabled     bool       `json:"enabled"`
 	LastBackup  *time.Time `json:"last_backup,omitempty"`
 	NextBackup  *time.Time `json:"next_backup,omitempty"`
 }
 
-// UpdateRequest запрос на обновление
+// UpdateRequest запрос на обновление. Без ServerID обновляются все
+// серверы типа ServerType (и региона Region, если задан) партиями: первая
+// партия - CanaryPercent процентов серверов, следующие - по MaxUnavailable
 type UpdateRequest struct {
-	ServerID string `json:"server_id"`
-	Version  string `json:"version"`
-	Force    bool   `json:"force"`
+	ServerID       string        `json:"server_id,omitempty"`
+	ServerType     ServerType    `json:"server_type,omitempty"`
+	Region         string        `json:"region,omitempty"`
+	Version        string        `json:"version"`         // тег образа или полная ссылка на образ
+	Force          bool          `json:"force"`           // обновлять и неработающие серверы
+	CanaryPercent  int           `json:"canary_percent"`  // 0 - без канареечной партии
+	MaxUnavailable int           `json:"max_unavailable"` // по умолчанию 1
+	HealthTimeout  time.Duration `json:"health_timeout"`  // ожидание здоровья после замены
 }
 
+// Статусы обновления
+const (
+	UpdateStatusPending    = "pending"
+	UpdateStatusInProgress = "in_progress"
+	UpdateStatusCompleted  = "completed"
+	UpdateStatusFailed     = "failed"
+	UpdateStatusRolledBack = "rolled_back"
+	UpdateStatusCancelled  = "cancelled"
+)
+
 // UpdateStatus статус обновления
 type UpdateStatus struct {
 	ServerID    string     `json:"server_id"`
@@ -131,12 +187,39 @@ type ServerFilters struct {
 	Offset int          `json:"offset"`
 }
 
+// Типы событий мониторинга сервера
+const (
+	// MonitorEventStats периодический срез статистики и здоровья
+	MonitorEventStats = "stats"
+	// MonitorEventStatusChanged смена статуса, новый статус в Status
+	MonitorEventStatusChanged = "status_changed"
+	// MonitorEventHealthCheckFailed сервер перестал проходить проверки
+	MonitorEventHealthCheckFailed = "health_check_failed"
+	// MonitorEventRestarted перезапуск контейнера сервера
+	MonitorEventRestarted = "restarted"
+	// MonitorEventExited завершение контейнера сервера
+	MonitorEventExited = "exited"
+	// MonitorEventOOMKilled контейнер завершен из-за нехватки памяти
+	MonitorEventOOMKilled = "oom_killed"
+	// MonitorEventScaled изменение числа реплик сервера
+	MonitorEventScaled = "scaled"
+	// MonitorEventMoved сервер перенесен на другой узел, узел в Message
+	MonitorEventMoved = "moved"
+	// MonitorEventProvisioning шаг подготовки хоста сервера, шаг в Message
+	MonitorEventProvisioning = "provisioning"
+	// MonitorEventDeleted сервер удален, после события поток завершается
+	MonitorEventDeleted = "deleted"
+)
+
 // ServerMonitorEvent событие мониторинга сервера
 type ServerMonitorEvent struct {
-	ServerID  string    `json:"server_id"`
-	Type      string    `json:"type"`
-	Message   string    `json:"message"`
-	Timestamp time.Time `json:"timestamp"`
+	ServerID  string        `json:"server_id"`
+	Type      string        `json:"type"`
+	Message   string        `json:"message"`
+	Status    ServerStatus  `json:"status,omitempty"`
+	Stats     *ServerStats  `json:"stats,omitempty"`
+	Health    *ServerHealth `json:"health,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
 }
 
 // ServerStats статистика сервера для gRPC
@@ -172,14 +255,18 @@ type ScaleServerRequest struct {
 	Replicas int     `json:"replicas"`
 }
 
-// CreateBackupRequest запрос на создание резервной копии
+// CreateBackupRequest запрос на создание резервной копии. Пустые Type и
+// Destination берутся из настроек по умолчанию
 type CreateBackupRequest struct {
-	ServerID    string `json:"server_id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ServerID    string     `json:"server_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Type        BackupType `json:"type"`
+	Destination string     `json:"destination"`
 }
 
-// RestoreBackupRequest запрос на восстановление из резервной копии
+// RestoreBackupRequest запрос на восстановление из резервной копии. Пустой
+// ServerID - восстановление в новый сервер
 type RestoreBackupRequest struct {
 	BackupID string `json:"backup_id"`
 	ServerID string `json:"server_id"`
@@ -193,11 +280,27 @@ type UpdateServerSoftwareRequest struct {
 	Force    bool   `json:"force"`
 }
 
-// Backup резервная копия
+// BackupType тип резервной копии
+type BackupType string
+
+const (
+	BackupTypeFull        BackupType = "full"
+	BackupTypeIncremental BackupType = "incremental" // файлы, измененные после родительской копии
+)
+
+// Backup резервная копия данных сервера (tar-архив ServerDataPath)
 type Backup struct {
-	ID          string    `json:"id"`
-	ServerID    string    `json:"server_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          string     `json:"id"`
+	ServerID    string     `json:"server_id"`
+	ServerType  ServerType `json:"server_type"`
+	Region      string     `json:"region"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Type        BackupType `json:"type"`
+	ParentID    string     `json:"parent_id,omitempty"` // предыдущая копия цепочки для incremental
+	Destination string     `json:"destination"`
+	Key         string     `json:"key"` // ключ архива в хранилище
+	Size        int64      `json:"size"`
+	Checksum    string     `json:"checksum"` // sha256 архива
+	CreatedAt   time.Time  `json:"created_at"`
 }
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+	"io"
+)
+
+// BackupStore хранилище архивов резервных копий
+type BackupStore interface {
+	// Put сохраняет архив под ключом key
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get открывает архив по ключу
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete удаляет архив, отсутствие архива не ошибка
+	Delete(ctx context.Context, key string) error
+}
+
+// BackupStoreProvider выбирает хранилище по URL назначения из
+// domain.BackupConfig.Destination
+type BackupStoreProvider interface {
+	// Store возвращает хранилище, пустое назначение - хранилище по умолчанию
+	Store(destination string) (BackupStore, error)
+
+	// DefaultDestination возвращает назначение по умолчанию
+	DefaultDestination() string
+}
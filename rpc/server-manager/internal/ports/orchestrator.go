@@ -2,33 +2,77 @@ package ports
 
 import (
 	"context"
+	"io"
 
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
 )
 
-// Orchestrator интерфейс для управления серверами
+// Orchestrator интерфейс для управления серверами.
+// Ресурс сервера адресуется handle, который возвращает CreateServer:
+// ID контейнера в Docker или имя Deployment в Kubernetes.
 type Orchestrator interface {
-	// CreateServer создает новый сервер
-	CreateServer(ctx context.Context, server *domain.Server) error
+	// CreateServer создает и запускает сервер, возвращает handle ресурса.
+	// Оркестратор с несколькими узлами записывает выбранный узел в
+	// server.NodeID
+	CreateServer(ctx context.Context, server *domain.Server, spec *domain.ServerSpec) (string, error)
 
 	// StartServer запускает сервер
-	StartServer(ctx context.Context, serverID string) error
+	StartServer(ctx context.Context, handle string) error
 
 	// StopServer останавливает сервер
-	StopServer(ctx context.Context, serverID string) error
+	StopServer(ctx context.Context, handle string) error
 
 	// DeleteServer удаляет сервер
-	DeleteServer(ctx context.Context, serverID string) error
+	DeleteServer(ctx context.Context, handle string) error
 
 	// GetServerStats получает статистику сервера
-	GetServerStats(ctx context.Context, serverID string) (*domain.ServerStats, error)
+	GetServerStats(ctx context.Context, handle string) (*domain.ServerStats, error)
 
 	// GetServerHealth получает здоровье сервера
-	GetServerHealth(ctx context.Context, serverID string) (*domain.ServerHealth, error)
+	GetServerHealth(ctx context.Context, handle string) (*domain.ServerHealth, error)
 
-	// ScaleServer масштабирует сервер
-	ScaleServer(ctx context.Context, serverID string, replicas int32) error
+	// ScaleServer масштабирует сервер. Если оркестратор не поддерживает
+	// реплики, возвращает domain.ErrNotSupported
+	ScaleServer(ctx context.Context, handle string, replicas int32) error
 
-	// ListServers получает список серверов
+	// UpdateServer меняет образ сервера, сохраняя окружение и данные
+	// domain.ServerDataPath, и возвращает новый handle. Ошибка означает,
+	// что сервер остался на прежнем образе. Готовность нового образа
+	// проверяется через GetServerHealth: пока замена не завершена, статус
+	// domain.ServerStatusCreating или domain.ServerStatusUpdating
+	UpdateServer(ctx context.Context, handle string, image string) (string, error)
+
+	// WatchEvents передает события ресурсов, созданных server-manager:
+	// перезапуски, завершения и OOM контейнеров. ServerID события - ID
+	// сервера в базе данных. Канал закрывается при отмене ctx или обрыве
+	// потока событий бэкенда
+	WatchEvents(ctx context.Context) (<-chan *domain.ServerMonitorEvent, error)
+
+	// ListServers получает список серверов, созданных server-manager
 	ListServers(ctx context.Context) ([]*domain.Server, error)
+
+	// SnapshotServer пишет в w tar-архив каталога domain.ServerDataPath
+	// сервера. Пути в архиве относительны этого каталога
+	SnapshotServer(ctx context.Context, handle string, w io.Writer) error
+
+	// ResetServerData очищает каталог domain.ServerDataPath остановленного
+	// сервера и возвращает handle, который может измениться
+	ResetServerData(ctx context.Context, handle string) (string, error)
+
+	// RestoreServer распаковывает tar-архив из r в каталог
+	// domain.ServerDataPath сервера поверх существующих файлов
+	RestoreServer(ctx context.Context, handle string, r io.Reader) error
+}
+
+// NodeOrchestrator оркестратор, размещающий серверы на узлах реестра
+type NodeOrchestrator interface {
+	Orchestrator
+
+	// PingNode проверяет доступность Docker Engine узла
+	PingNode(ctx context.Context, node *domain.Node) error
+
+	// MoveServer переносит сервер вместе с данными domain.ServerDataPath
+	// на другой узел с метками selector и возвращает новый handle и ID
+	// узла. Ошибка означает, что сервер остался на прежнем узле
+	MoveServer(ctx context.Context, handle string, selector map[string]string) (string, string, error)
 }
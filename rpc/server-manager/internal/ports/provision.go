@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+)
+
+// Provisioner подготовка хостов без API оркестратора: установка
+// зависимостей и vpn-core, настройка и запуск сервиса
+type Provisioner interface {
+	// Provision выполняет шаги подготовки хоста сервера до
+	// domain.ProvisionStepStart включительно, сообщая о начале каждого
+	// шага через progress. Повторная подготовка хоста безопасна
+	Provision(ctx context.Context, server *domain.Server, req *domain.ProvisionRequest, progress func(step domain.ProvisionStep)) error
+}
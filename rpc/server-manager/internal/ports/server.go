@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
 )
@@ -24,26 +25,93 @@ type ServerService interface {
 	GetServerStats(ctx context.Context, id string) (*domain.ServerStats, error)
 	GetServerHealth(ctx context.Context, id string) (*domain.ServerHealth, error)
 	GetAllServersHealth(ctx context.Context) ([]*domain.ServerHealth, error)
+	// CollectStats сохраняет в историю статистику и здоровье работающих
+	// серверов
+	CollectStats(ctx context.Context) error
+	// CompactStats прореживает замеры старше rawRetention и удаляет историю
+	// старше retention
+	CompactStats(ctx context.Context, rawRetention, retention time.Duration) error
+	// MonitorServer передает события сервера: статистику с периодом
+	// interval и события жизненного цикла. Канал закрывается при отмене
+	// ctx или удалении сервера
+	MonitorServer(ctx context.Context, id string, interval time.Duration) (<-chan *domain.ServerMonitorEvent, error)
 
 	// Масштабирование
+	ScaleServer(ctx context.Context, id string, replicas int32) error
 	GetScalingPolicies(ctx context.Context) ([]*domain.ScalingPolicy, error)
 	CreateScalingPolicy(ctx context.Context, policy *domain.ScalingPolicy) error
 	UpdateScalingPolicy(ctx context.Context, id string, policy *domain.ScalingPolicy) error
 	DeleteScalingPolicy(ctx context.Context, id string) error
-	EvaluateScaling(ctx context.Context) error
+	// EvaluateScaling применяет политики масштабирования и возвращает
+	// принятые решения. В режиме dryRun действия не выполняются и не
+	// записываются в историю
+	EvaluateScaling(ctx context.Context, dryRun bool) ([]*domain.ScalingDecision, error)
 
 	// Резервное копирование
 	GetBackupConfigs(ctx context.Context) ([]*domain.BackupConfig, error)
 	CreateBackupConfig(ctx context.Context, config *domain.BackupConfig) error
 	UpdateBackupConfig(ctx context.Context, id string, config *domain.BackupConfig) error
 	DeleteBackupConfig(ctx context.Context, id string) error
-	CreateBackup(ctx context.Context, serverID string) error
-	RestoreBackup(ctx context.Context, serverID, backupID string) error
+	CreateBackup(ctx context.Context, req *domain.CreateBackupRequest) (*domain.Backup, error)
+	// RestoreBackup восстанавливает копию в существующий сервер или, если
+	// req.ServerID пуст, в новый сервер того же типа и региона
+	RestoreBackup(ctx context.Context, req *domain.RestoreBackupRequest) (*domain.Server, error)
+	ListBackups(ctx context.Context, serverID string) ([]*domain.Backup, error)
+	// RunScheduledBackups создает копии по наступившим расписаниям и
+	// удаляет копии старше срока хранения
+	RunScheduledBackups(ctx context.Context) error
 
 	// Обновления
 	GetUpdateStatus(ctx context.Context, serverID string) (*domain.UpdateStatus, error)
 	StartUpdate(ctx context.Context, req *domain.UpdateRequest) error
 	CancelUpdate(ctx context.Context, serverID string) error
+
+	// Регионы и размещение
+	ListRegions(ctx context.Context) ([]*domain.Region, error)
+	GetRegion(ctx context.Context, code string) (*domain.Region, error)
+	SaveRegion(ctx context.Context, region *domain.Region) error
+	// DeleteRegion удаляет регион без серверов
+	DeleteRegion(ctx context.Context, code string) error
+	// EnsureRegions создает включенные регионы с кодами codes, которых еще
+	// нет
+	EnsureRegions(ctx context.Context, codes []string) error
+	// RecommendServer выбирает для пользователя работающий сервер с учетом
+	// загрузки, ограничений регионов и расположения клиента
+	RecommendServer(ctx context.Context, req *domain.PlacementRequest) (*domain.Placement, error)
+
+	// Узлы
+	// RegisterNode добавляет или обновляет узел; узел должен быть доступен
+	RegisterNode(ctx context.Context, node *domain.Node) error
+	ListNodes(ctx context.Context) ([]*domain.Node, error)
+	GetNode(ctx context.Context, id string) (*domain.Node, error)
+	// RemoveNode удаляет узел без серверов
+	RemoveNode(ctx context.Context, id string) error
+	// CordonNode запрещает размещать на узле новые серверы
+	CordonNode(ctx context.Context, id string) error
+	UncordonNode(ctx context.Context, id string) error
+	// DrainNode переносит серверы узла на другие узлы и оставляет узел
+	// cordoned
+	DrainNode(ctx context.Context, id string) error
+	// CheckNodes проверяет доступность узлов и записывает результат
+	CheckNodes(ctx context.Context) error
+
+	// Сверка
+	// ReconcileServers сравнивает серверы в базе с ресурсами оркестратора
+	// и устраняет расхождения; в режиме DryRun только возвращает отчет
+	ReconcileServers(ctx context.Context, req *domain.ReconcileRequest) (*domain.DriftReport, error)
+
+	// Подготовка хостов
+	// ProvisionServer создает сервер и запускает подготовку его хоста по
+	// SSH, не дожидаясь ее окончания. Ход подготовки доступен через
+	// GetProvision
+	ProvisionServer(ctx context.Context, req *domain.ProvisionRequest) (*domain.Server, error)
+	GetProvision(ctx context.Context, serverID string) (*domain.Provision, error)
+	// FailInterruptedProvisions отмечает ошибкой подготовки, прерванные
+	// остановкой сервиса, и их серверы
+	FailInterruptedProvisions(ctx context.Context) error
+
+	// Stop отменяет фоновые подготовки хостов и ждет их завершения
+	Stop()
 }
 
 // ServerRepository интерфейс для работы с базой данных серверов
@@ -58,12 +126,41 @@ type ServerRepository interface {
 	GetByStatus(ctx context.Context, status domain.ServerStatus) ([]*domain.Server, error)
 }
 
+// RegionRepository интерфейс для работы с регионами
+type RegionRepository interface {
+	// SaveRegion создает или обновляет регион
+	SaveRegion(ctx context.Context, region *domain.Region) error
+	GetRegion(ctx context.Context, code string) (*domain.Region, error)
+	ListRegions(ctx context.Context) ([]*domain.Region, error)
+	DeleteRegion(ctx context.Context, code string) error
+}
+
+// NodeRepository интерфейс для работы с реестром узлов
+type NodeRepository interface {
+	// SaveNode создает или обновляет узел
+	SaveNode(ctx context.Context, node *domain.Node) error
+	GetNode(ctx context.Context, id string) (*domain.Node, error)
+	ListNodes(ctx context.Context) ([]*domain.Node, error)
+	DeleteNode(ctx context.Context, id string) error
+	UpdateNodeState(ctx context.Context, id string, state domain.NodeState) error
+	// UpdateNodeHealth записывает результат проверки узла
+	UpdateNodeHealth(ctx context.Context, id string, healthy bool, message string, checkedAt time.Time) error
+}
+
 // StatsRepository интерфейс для работы со статистикой
 type StatsRepository interface {
 	SaveStats(ctx context.Context, stats *domain.ServerStats) error
 	GetStats(ctx context.Context, serverID string, limit int) ([]*domain.ServerStats, error)
 	GetLatestStats(ctx context.Context, serverID string) (*domain.ServerStats, error)
+	// GetLatestStatsBatch возвращает последние замеры серверов по ID;
+	// серверов без замеров в результате нет
+	GetLatestStatsBatch(ctx context.Context, serverIDs []string) (map[string]*domain.ServerStats, error)
+	// GetAggregatedStats возвращает статистику за период 1h, 24h или 7d
 	GetAggregatedStats(ctx context.Context, serverID string, period string) (*domain.ServerStats, error)
+	// DownsampleStats заменяет исходные замеры старше before средними за
+	// интервалы длиной bucket
+	DownsampleStats(ctx context.Context, before time.Time, bucket time.Duration) error
+	DeleteStatsBefore(ctx context.Context, before time.Time) error
 }
 
 // HealthRepository интерфейс для работы с данными о здоровье
@@ -72,6 +169,7 @@ type HealthRepository interface {
 	GetHealth(ctx context.Context, serverID string) (*domain.ServerHealth, error)
 	GetAllHealth(ctx context.Context) ([]*domain.ServerHealth, error)
 	GetHealthHistory(ctx context.Context, serverID string, limit int) ([]*domain.ServerHealth, error)
+	DeleteHealthBefore(ctx context.Context, before time.Time) error
 }
 
 // ScalingRepository интерфейс для работы с политиками масштабирования
@@ -81,6 +179,10 @@ type ScalingRepository interface {
 	ListPolicies(ctx context.Context) ([]*domain.ScalingPolicy, error)
 	UpdatePolicy(ctx context.Context, policy *domain.ScalingPolicy) error
 	DeletePolicy(ctx context.Context, id string) error
+	SaveDecision(ctx context.Context, decision *domain.ScalingDecision) error
+	// GetLastAction возвращает последнее масштабирование группы политики
+	// (scale_up или scale_down), nil если его не было
+	GetLastAction(ctx context.Context, policyID, region string) (*domain.ScalingDecision, error)
 }
 
 // BackupRepository интерфейс для работы с резервными копиями
@@ -90,9 +192,11 @@ type BackupRepository interface {
 	ListConfigs(ctx context.Context) ([]*domain.BackupConfig, error)
 	UpdateConfig(ctx context.Context, config *domain.BackupConfig) error
 	DeleteConfig(ctx context.Context, id string) error
-	SaveBackup(ctx context.Context, serverID, backupID string, metadata map[string]interface{}) error
-	GetBackups(ctx context.Context, serverID string) ([]map[string]interface{}, error)
-	DeleteBackup(ctx context.Context, backupID string) error
+	SaveBackup(ctx context.Context, backup *domain.Backup) error
+	GetBackup(ctx context.Context, id string) (*domain.Backup, error)
+	// ListBackups возвращает копии сервера от старых к новым
+	ListBackups(ctx context.Context, serverID string) ([]*domain.Backup, error)
+	DeleteBackup(ctx context.Context, id string) error
 }
 
 // UpdateRepository интерфейс для работы с обновлениями
@@ -102,3 +206,14 @@ type UpdateRepository interface {
 	UpdateProgress(ctx context.Context, serverID string, progress int, message string) error
 	CompleteUpdate(ctx context.Context, serverID string, success bool, message string) error
 }
+
+// ProvisionRepository интерфейс для работы с подготовкой хостов
+type ProvisionRepository interface {
+	SaveProvision(ctx context.Context, provision *domain.Provision) error
+	GetProvision(ctx context.Context, serverID string) (*domain.Provision, error)
+	UpdateProvisionStep(ctx context.Context, serverID string, step domain.ProvisionStep, progress int, message string) error
+	CompleteProvision(ctx context.Context, serverID string, success bool, message string) error
+	// FailInterruptedProvisions завершает незавершенные подготовки ошибкой
+	// и возвращает ID их серверов
+	FailInterruptedProvisions(ctx context.Context, message string) ([]string, error)
+}
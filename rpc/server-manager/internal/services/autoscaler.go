@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+)
+
+// AutoScaler периодически применяет политики масштабирования
+type AutoScaler struct {
+	*periodic
+	service ports.ServerService
+	dryRun  bool
+}
+
+// NewAutoScaler создает цикл автомасштабирования. В режиме dryRun решения
+// только логируются
+func NewAutoScaler(service ports.ServerService, interval time.Duration, dryRun bool, logger *zap.Logger) *AutoScaler {
+	a := &AutoScaler{
+		periodic: newPeriodic("autoscaler", interval, logger, zap.Bool("dry_run", dryRun)),
+		service:  service,
+		dryRun:   dryRun,
+	}
+	a.tick = a.evaluate
+	return a
+}
+
+// evaluate выполняет одну оценку, ограниченную интервалом цикла
+func (a *AutoScaler) evaluate(ctx context.Context) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	decisions, err := a.service.EvaluateScaling(ctx, a.dryRun)
+	if err != nil {
+		a.logger.Error("scaling evaluation failed", zap.Error(err))
+	}
+
+	for _, decision := range decisions {
+		if decision.Action == domain.ScalingActionNone {
+			continue
+		}
+		a.logger.Info("scaling decision",
+			zap.String("policy_id", decision.PolicyID),
+			zap.String("region", decision.Region),
+			zap.String("action", string(decision.Action)),
+			zap.Int("servers_before", decision.ServersBefore),
+			zap.Int("servers_after", decision.ServersAfter),
+			zap.Bool("dry_run", decision.DryRun),
+			zap.String("reason", decision.Reason))
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+)
+
+// BackupScheduler периодически выполняет расписания резервного копирования
+type BackupScheduler struct {
+	*periodic
+	service ports.ServerService
+}
+
+// NewBackupScheduler создает планировщик. Расписания проверяются раз в
+// interval, поэтому копия создается с задержкой не больше interval
+func NewBackupScheduler(service ports.ServerService, interval time.Duration, logger *zap.Logger) *BackupScheduler {
+	b := &BackupScheduler{
+		periodic: newPeriodic("backup scheduler", interval, logger),
+		service:  service,
+	}
+	b.tick = b.run
+	return b
+}
+
+// run выполняет наступившие расписания. Копии не ограничены интервалом
+// цикла: остановка ждет их завершения
+func (b *BackupScheduler) run(ctx context.Context) {
+	if err := b.service.RunScheduledBackups(ctx); err != nil {
+		b.logger.Error("scheduled backups failed", zap.Error(err))
+	}
+}
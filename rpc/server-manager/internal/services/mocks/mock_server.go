@@ -1,12 +1,14 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/par1ram/silence/rpc/server-manager/internal/ports (interfaces: ServerRepository,StatsRepository,HealthRepository,ScalingRepository,BackupRepository,UpdateRepository)
+// Source: github.com/par1ram/silence/rpc/server-manager/internal/ports (interfaces: ServerRepository,StatsRepository,HealthRepository,ScalingRepository,BackupRepository,UpdateRepository,RegionRepository,NodeRepository,ProvisionRepository,Orchestrator,NodeOrchestrator,Provisioner)
 
 // Package services_test is a generated GoMock package.
 package services_test
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/par1ram/silence/rpc/server-manager/internal/domain"
@@ -175,6 +177,34 @@ func (m *MockStatsRepository) EXPECT() *MockStatsRepositoryMockRecorder {
 	return m.recorder
 }
 
+// DeleteStatsBefore mocks base method.
+func (m *MockStatsRepository) DeleteStatsBefore(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStatsBefore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStatsBefore indicates an expected call of DeleteStatsBefore.
+func (mr *MockStatsRepositoryMockRecorder) DeleteStatsBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStatsBefore", reflect.TypeOf((*MockStatsRepository)(nil).DeleteStatsBefore), arg0, arg1)
+}
+
+// DownsampleStats mocks base method.
+func (m *MockStatsRepository) DownsampleStats(arg0 context.Context, arg1 time.Time, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownsampleStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownsampleStats indicates an expected call of DownsampleStats.
+func (mr *MockStatsRepositoryMockRecorder) DownsampleStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownsampleStats", reflect.TypeOf((*MockStatsRepository)(nil).DownsampleStats), arg0, arg1, arg2)
+}
+
 // GetAggregatedStats mocks base method.
 func (m *MockStatsRepository) GetAggregatedStats(arg0 context.Context, arg1, arg2 string) (*domain.ServerStats, error) {
 	m.ctrl.T.Helper()
@@ -205,6 +235,21 @@ func (mr *MockStatsRepositoryMockRecorder) GetLatestStats(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestStats", reflect.TypeOf((*MockStatsRepository)(nil).GetLatestStats), arg0, arg1)
 }
 
+// GetLatestStatsBatch mocks base method.
+func (m *MockStatsRepository) GetLatestStatsBatch(arg0 context.Context, arg1 []string) (map[string]*domain.ServerStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestStatsBatch", arg0, arg1)
+	ret0, _ := ret[0].(map[string]*domain.ServerStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestStatsBatch indicates an expected call of GetLatestStatsBatch.
+func (mr *MockStatsRepositoryMockRecorder) GetLatestStatsBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestStatsBatch", reflect.TypeOf((*MockStatsRepository)(nil).GetLatestStatsBatch), arg0, arg1)
+}
+
 // GetStats mocks base method.
 func (m *MockStatsRepository) GetStats(arg0 context.Context, arg1 string, arg2 int) ([]*domain.ServerStats, error) {
 	m.ctrl.T.Helper()
@@ -257,6 +302,20 @@ func (m *MockHealthRepository) EXPECT() *MockHealthRepositoryMockRecorder {
 	return m.recorder
 }
 
+// DeleteHealthBefore mocks base method.
+func (m *MockHealthRepository) DeleteHealthBefore(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHealthBefore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHealthBefore indicates an expected call of DeleteHealthBefore.
+func (mr *MockHealthRepositoryMockRecorder) DeleteHealthBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHealthBefore", reflect.TypeOf((*MockHealthRepository)(nil).DeleteHealthBefore), arg0, arg1)
+}
+
 // GetAllHealth mocks base method.
 func (m *MockHealthRepository) GetAllHealth(arg0 context.Context) ([]*domain.ServerHealth, error) {
 	m.ctrl.T.Helper()
@@ -353,6 +412,21 @@ func (mr *MockScalingRepositoryMockRecorder) DeletePolicy(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePolicy", reflect.TypeOf((*MockScalingRepository)(nil).DeletePolicy), arg0, arg1)
 }
 
+// GetLastAction mocks base method.
+func (m *MockScalingRepository) GetLastAction(arg0 context.Context, arg1, arg2 string) (*domain.ScalingDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.ScalingDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAction indicates an expected call of GetLastAction.
+func (mr *MockScalingRepositoryMockRecorder) GetLastAction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAction", reflect.TypeOf((*MockScalingRepository)(nil).GetLastAction), arg0, arg1, arg2)
+}
+
 // GetPolicy mocks base method.
 func (m *MockScalingRepository) GetPolicy(arg0 context.Context, arg1 string) (*domain.ScalingPolicy, error) {
 	m.ctrl.T.Helper()
@@ -383,6 +457,20 @@ func (mr *MockScalingRepositoryMockRecorder) ListPolicies(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPolicies", reflect.TypeOf((*MockScalingRepository)(nil).ListPolicies), arg0)
 }
 
+// SaveDecision mocks base method.
+func (m *MockScalingRepository) SaveDecision(arg0 context.Context, arg1 *domain.ScalingDecision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDecision", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDecision indicates an expected call of SaveDecision.
+func (mr *MockScalingRepositoryMockRecorder) SaveDecision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDecision", reflect.TypeOf((*MockScalingRepository)(nil).SaveDecision), arg0, arg1)
+}
+
 // SavePolicy mocks base method.
 func (m *MockScalingRepository) SavePolicy(arg0 context.Context, arg1 *domain.ScalingPolicy) error {
 	m.ctrl.T.Helper()
@@ -462,19 +550,19 @@ func (mr *MockBackupRepositoryMockRecorder) DeleteConfig(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteConfig", reflect.TypeOf((*MockBackupRepository)(nil).DeleteConfig), arg0, arg1)
 }
 
-// GetBackups mocks base method.
-func (m *MockBackupRepository) GetBackups(arg0 context.Context, arg1 string) ([]map[string]interface{}, error) {
+// GetBackup mocks base method.
+func (m *MockBackupRepository) GetBackup(arg0 context.Context, arg1 string) (*domain.Backup, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBackups", arg0, arg1)
-	ret0, _ := ret[0].([]map[string]interface{})
+	ret := m.ctrl.Call(m, "GetBackup", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Backup)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBackups indicates an expected call of GetBackups.
-func (mr *MockBackupRepositoryMockRecorder) GetBackups(arg0, arg1 interface{}) *gomock.Call {
+// GetBackup indicates an expected call of GetBackup.
+func (mr *MockBackupRepositoryMockRecorder) GetBackup(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackups", reflect.TypeOf((*MockBackupRepository)(nil).GetBackups), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackup", reflect.TypeOf((*MockBackupRepository)(nil).GetBackup), arg0, arg1)
 }
 
 // GetConfig mocks base method.
@@ -492,6 +580,21 @@ func (mr *MockBackupRepositoryMockRecorder) GetConfig(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockBackupRepository)(nil).GetConfig), arg0, arg1)
 }
 
+// ListBackups mocks base method.
+func (m *MockBackupRepository) ListBackups(arg0 context.Context, arg1 string) ([]*domain.Backup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBackups", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Backup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBackups indicates an expected call of ListBackups.
+func (mr *MockBackupRepositoryMockRecorder) ListBackups(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBackups", reflect.TypeOf((*MockBackupRepository)(nil).ListBackups), arg0, arg1)
+}
+
 // ListConfigs mocks base method.
 func (m *MockBackupRepository) ListConfigs(arg0 context.Context) ([]*domain.BackupConfig, error) {
 	m.ctrl.T.Helper()
@@ -508,17 +611,17 @@ func (mr *MockBackupRepositoryMockRecorder) ListConfigs(arg0 interface{}) *gomoc
 }
 
 // SaveBackup mocks base method.
-func (m *MockBackupRepository) SaveBackup(arg0 context.Context, arg1, arg2 string, arg3 map[string]interface{}) error {
+func (m *MockBackupRepository) SaveBackup(arg0 context.Context, arg1 *domain.Backup) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveBackup", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "SaveBackup", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveBackup indicates an expected call of SaveBackup.
-func (mr *MockBackupRepositoryMockRecorder) SaveBackup(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockBackupRepositoryMockRecorder) SaveBackup(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBackup", reflect.TypeOf((*MockBackupRepository)(nil).SaveBackup), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBackup", reflect.TypeOf((*MockBackupRepository)(nil).SaveBackup), arg0, arg1)
 }
 
 // SaveConfig mocks base method.
@@ -628,3 +731,779 @@ func (mr *MockUpdateRepositoryMockRecorder) UpdateProgress(arg0, arg1, arg2, arg
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProgress", reflect.TypeOf((*MockUpdateRepository)(nil).UpdateProgress), arg0, arg1, arg2, arg3)
 }
+
+// MockRegionRepository is a mock of RegionRepository interface.
+type MockRegionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegionRepositoryMockRecorder
+}
+
+// MockRegionRepositoryMockRecorder is the mock recorder for MockRegionRepository.
+type MockRegionRepositoryMockRecorder struct {
+	mock *MockRegionRepository
+}
+
+// NewMockRegionRepository creates a new mock instance.
+func NewMockRegionRepository(ctrl *gomock.Controller) *MockRegionRepository {
+	mock := &MockRegionRepository{ctrl: ctrl}
+	mock.recorder = &MockRegionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRegionRepository) EXPECT() *MockRegionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRegion mocks base method.
+func (m *MockRegionRepository) DeleteRegion(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRegion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRegion indicates an expected call of DeleteRegion.
+func (mr *MockRegionRepositoryMockRecorder) DeleteRegion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRegion", reflect.TypeOf((*MockRegionRepository)(nil).DeleteRegion), arg0, arg1)
+}
+
+// GetRegion mocks base method.
+func (m *MockRegionRepository) GetRegion(arg0 context.Context, arg1 string) (*domain.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRegion", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRegion indicates an expected call of GetRegion.
+func (mr *MockRegionRepositoryMockRecorder) GetRegion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegion", reflect.TypeOf((*MockRegionRepository)(nil).GetRegion), arg0, arg1)
+}
+
+// ListRegions mocks base method.
+func (m *MockRegionRepository) ListRegions(arg0 context.Context) ([]*domain.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRegions", arg0)
+	ret0, _ := ret[0].([]*domain.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRegions indicates an expected call of ListRegions.
+func (mr *MockRegionRepositoryMockRecorder) ListRegions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRegions", reflect.TypeOf((*MockRegionRepository)(nil).ListRegions), arg0)
+}
+
+// SaveRegion mocks base method.
+func (m *MockRegionRepository) SaveRegion(arg0 context.Context, arg1 *domain.Region) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRegion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRegion indicates an expected call of SaveRegion.
+func (mr *MockRegionRepositoryMockRecorder) SaveRegion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRegion", reflect.TypeOf((*MockRegionRepository)(nil).SaveRegion), arg0, arg1)
+}
+
+// MockNodeRepository is a mock of NodeRepository interface.
+type MockNodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNodeRepositoryMockRecorder
+}
+
+// MockNodeRepositoryMockRecorder is the mock recorder for MockNodeRepository.
+type MockNodeRepositoryMockRecorder struct {
+	mock *MockNodeRepository
+}
+
+// NewMockNodeRepository creates a new mock instance.
+func NewMockNodeRepository(ctrl *gomock.Controller) *MockNodeRepository {
+	mock := &MockNodeRepository{ctrl: ctrl}
+	mock.recorder = &MockNodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNodeRepository) EXPECT() *MockNodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteNode mocks base method.
+func (m *MockNodeRepository) DeleteNode(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNode indicates an expected call of DeleteNode.
+func (mr *MockNodeRepositoryMockRecorder) DeleteNode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNode", reflect.TypeOf((*MockNodeRepository)(nil).DeleteNode), arg0, arg1)
+}
+
+// GetNode mocks base method.
+func (m *MockNodeRepository) GetNode(arg0 context.Context, arg1 string) (*domain.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNode", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNode indicates an expected call of GetNode.
+func (mr *MockNodeRepositoryMockRecorder) GetNode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNode", reflect.TypeOf((*MockNodeRepository)(nil).GetNode), arg0, arg1)
+}
+
+// ListNodes mocks base method.
+func (m *MockNodeRepository) ListNodes(arg0 context.Context) ([]*domain.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNodes", arg0)
+	ret0, _ := ret[0].([]*domain.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNodes indicates an expected call of ListNodes.
+func (mr *MockNodeRepositoryMockRecorder) ListNodes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNodes", reflect.TypeOf((*MockNodeRepository)(nil).ListNodes), arg0)
+}
+
+// SaveNode mocks base method.
+func (m *MockNodeRepository) SaveNode(arg0 context.Context, arg1 *domain.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNode indicates an expected call of SaveNode.
+func (mr *MockNodeRepositoryMockRecorder) SaveNode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNode", reflect.TypeOf((*MockNodeRepository)(nil).SaveNode), arg0, arg1)
+}
+
+// UpdateNodeHealth mocks base method.
+func (m *MockNodeRepository) UpdateNodeHealth(arg0 context.Context, arg1 string, arg2 bool, arg3 string, arg4 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNodeHealth", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNodeHealth indicates an expected call of UpdateNodeHealth.
+func (mr *MockNodeRepositoryMockRecorder) UpdateNodeHealth(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNodeHealth", reflect.TypeOf((*MockNodeRepository)(nil).UpdateNodeHealth), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UpdateNodeState mocks base method.
+func (m *MockNodeRepository) UpdateNodeState(arg0 context.Context, arg1 string, arg2 domain.NodeState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNodeState", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNodeState indicates an expected call of UpdateNodeState.
+func (mr *MockNodeRepositoryMockRecorder) UpdateNodeState(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNodeState", reflect.TypeOf((*MockNodeRepository)(nil).UpdateNodeState), arg0, arg1, arg2)
+}
+
+// MockProvisionRepository is a mock of ProvisionRepository interface.
+type MockProvisionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProvisionRepositoryMockRecorder
+}
+
+// MockProvisionRepositoryMockRecorder is the mock recorder for MockProvisionRepository.
+type MockProvisionRepositoryMockRecorder struct {
+	mock *MockProvisionRepository
+}
+
+// NewMockProvisionRepository creates a new mock instance.
+func NewMockProvisionRepository(ctrl *gomock.Controller) *MockProvisionRepository {
+	mock := &MockProvisionRepository{ctrl: ctrl}
+	mock.recorder = &MockProvisionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProvisionRepository) EXPECT() *MockProvisionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CompleteProvision mocks base method.
+func (m *MockProvisionRepository) CompleteProvision(arg0 context.Context, arg1 string, arg2 bool, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteProvision", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteProvision indicates an expected call of CompleteProvision.
+func (mr *MockProvisionRepositoryMockRecorder) CompleteProvision(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteProvision", reflect.TypeOf((*MockProvisionRepository)(nil).CompleteProvision), arg0, arg1, arg2, arg3)
+}
+
+// FailInterruptedProvisions mocks base method.
+func (m *MockProvisionRepository) FailInterruptedProvisions(arg0 context.Context, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailInterruptedProvisions", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailInterruptedProvisions indicates an expected call of FailInterruptedProvisions.
+func (mr *MockProvisionRepositoryMockRecorder) FailInterruptedProvisions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailInterruptedProvisions", reflect.TypeOf((*MockProvisionRepository)(nil).FailInterruptedProvisions), arg0, arg1)
+}
+
+// GetProvision mocks base method.
+func (m *MockProvisionRepository) GetProvision(arg0 context.Context, arg1 string) (*domain.Provision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvision", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Provision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProvision indicates an expected call of GetProvision.
+func (mr *MockProvisionRepositoryMockRecorder) GetProvision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvision", reflect.TypeOf((*MockProvisionRepository)(nil).GetProvision), arg0, arg1)
+}
+
+// SaveProvision mocks base method.
+func (m *MockProvisionRepository) SaveProvision(arg0 context.Context, arg1 *domain.Provision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveProvision", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveProvision indicates an expected call of SaveProvision.
+func (mr *MockProvisionRepositoryMockRecorder) SaveProvision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProvision", reflect.TypeOf((*MockProvisionRepository)(nil).SaveProvision), arg0, arg1)
+}
+
+// UpdateProvisionStep mocks base method.
+func (m *MockProvisionRepository) UpdateProvisionStep(arg0 context.Context, arg1 string, arg2 domain.ProvisionStep, arg3 int, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProvisionStep", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProvisionStep indicates an expected call of UpdateProvisionStep.
+func (mr *MockProvisionRepositoryMockRecorder) UpdateProvisionStep(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionStep", reflect.TypeOf((*MockProvisionRepository)(nil).UpdateProvisionStep), arg0, arg1, arg2, arg3, arg4)
+}
+
+// MockOrchestrator is a mock of Orchestrator interface.
+type MockOrchestrator struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrchestratorMockRecorder
+}
+
+// MockOrchestratorMockRecorder is the mock recorder for MockOrchestrator.
+type MockOrchestratorMockRecorder struct {
+	mock *MockOrchestrator
+}
+
+// NewMockOrchestrator creates a new mock instance.
+func NewMockOrchestrator(ctrl *gomock.Controller) *MockOrchestrator {
+	mock := &MockOrchestrator{ctrl: ctrl}
+	mock.recorder = &MockOrchestratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrchestrator) EXPECT() *MockOrchestratorMockRecorder {
+	return m.recorder
+}
+
+// CreateServer mocks base method.
+func (m *MockOrchestrator) CreateServer(arg0 context.Context, arg1 *domain.Server, arg2 *domain.ServerSpec) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServer indicates an expected call of CreateServer.
+func (mr *MockOrchestratorMockRecorder) CreateServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServer", reflect.TypeOf((*MockOrchestrator)(nil).CreateServer), arg0, arg1, arg2)
+}
+
+// DeleteServer mocks base method.
+func (m *MockOrchestrator) DeleteServer(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteServer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteServer indicates an expected call of DeleteServer.
+func (mr *MockOrchestratorMockRecorder) DeleteServer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServer", reflect.TypeOf((*MockOrchestrator)(nil).DeleteServer), arg0, arg1)
+}
+
+// GetServerHealth mocks base method.
+func (m *MockOrchestrator) GetServerHealth(arg0 context.Context, arg1 string) (*domain.ServerHealth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerHealth", arg0, arg1)
+	ret0, _ := ret[0].(*domain.ServerHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerHealth indicates an expected call of GetServerHealth.
+func (mr *MockOrchestratorMockRecorder) GetServerHealth(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerHealth", reflect.TypeOf((*MockOrchestrator)(nil).GetServerHealth), arg0, arg1)
+}
+
+// GetServerStats mocks base method.
+func (m *MockOrchestrator) GetServerStats(arg0 context.Context, arg1 string) (*domain.ServerStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerStats", arg0, arg1)
+	ret0, _ := ret[0].(*domain.ServerStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerStats indicates an expected call of GetServerStats.
+func (mr *MockOrchestratorMockRecorder) GetServerStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerStats", reflect.TypeOf((*MockOrchestrator)(nil).GetServerStats), arg0, arg1)
+}
+
+// ListServers mocks base method.
+func (m *MockOrchestrator) ListServers(arg0 context.Context) ([]*domain.Server, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServers", arg0)
+	ret0, _ := ret[0].([]*domain.Server)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServers indicates an expected call of ListServers.
+func (mr *MockOrchestratorMockRecorder) ListServers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServers", reflect.TypeOf((*MockOrchestrator)(nil).ListServers), arg0)
+}
+
+// ResetServerData mocks base method.
+func (m *MockOrchestrator) ResetServerData(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetServerData", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetServerData indicates an expected call of ResetServerData.
+func (mr *MockOrchestratorMockRecorder) ResetServerData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetServerData", reflect.TypeOf((*MockOrchestrator)(nil).ResetServerData), arg0, arg1)
+}
+
+// RestoreServer mocks base method.
+func (m *MockOrchestrator) RestoreServer(arg0 context.Context, arg1 string, arg2 io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreServer indicates an expected call of RestoreServer.
+func (mr *MockOrchestratorMockRecorder) RestoreServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreServer", reflect.TypeOf((*MockOrchestrator)(nil).RestoreServer), arg0, arg1, arg2)
+}
+
+// ScaleServer mocks base method.
+func (m *MockOrchestrator) ScaleServer(arg0 context.Context, arg1 string, arg2 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScaleServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScaleServer indicates an expected call of ScaleServer.
+func (mr *MockOrchestratorMockRecorder) ScaleServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleServer", reflect.TypeOf((*MockOrchestrator)(nil).ScaleServer), arg0, arg1, arg2)
+}
+
+// SnapshotServer mocks base method.
+func (m *MockOrchestrator) SnapshotServer(arg0 context.Context, arg1 string, arg2 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SnapshotServer indicates an expected call of SnapshotServer.
+func (mr *MockOrchestratorMockRecorder) SnapshotServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotServer", reflect.TypeOf((*MockOrchestrator)(nil).SnapshotServer), arg0, arg1, arg2)
+}
+
+// StartServer mocks base method.
+func (m *MockOrchestrator) StartServer(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartServer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartServer indicates an expected call of StartServer.
+func (mr *MockOrchestratorMockRecorder) StartServer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartServer", reflect.TypeOf((*MockOrchestrator)(nil).StartServer), arg0, arg1)
+}
+
+// StopServer mocks base method.
+func (m *MockOrchestrator) StopServer(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopServer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopServer indicates an expected call of StopServer.
+func (mr *MockOrchestratorMockRecorder) StopServer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopServer", reflect.TypeOf((*MockOrchestrator)(nil).StopServer), arg0, arg1)
+}
+
+// UpdateServer mocks base method.
+func (m *MockOrchestrator) UpdateServer(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateServer indicates an expected call of UpdateServer.
+func (mr *MockOrchestratorMockRecorder) UpdateServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateServer", reflect.TypeOf((*MockOrchestrator)(nil).UpdateServer), arg0, arg1, arg2)
+}
+
+// WatchEvents mocks base method.
+func (m *MockOrchestrator) WatchEvents(arg0 context.Context) (<-chan *domain.ServerMonitorEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchEvents", arg0)
+	ret0, _ := ret[0].(<-chan *domain.ServerMonitorEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchEvents indicates an expected call of WatchEvents.
+func (mr *MockOrchestratorMockRecorder) WatchEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchEvents", reflect.TypeOf((*MockOrchestrator)(nil).WatchEvents), arg0)
+}
+
+// MockNodeOrchestrator is a mock of NodeOrchestrator interface.
+type MockNodeOrchestrator struct {
+	ctrl     *gomock.Controller
+	recorder *MockNodeOrchestratorMockRecorder
+}
+
+// MockNodeOrchestratorMockRecorder is the mock recorder for MockNodeOrchestrator.
+type MockNodeOrchestratorMockRecorder struct {
+	mock *MockNodeOrchestrator
+}
+
+// NewMockNodeOrchestrator creates a new mock instance.
+func NewMockNodeOrchestrator(ctrl *gomock.Controller) *MockNodeOrchestrator {
+	mock := &MockNodeOrchestrator{ctrl: ctrl}
+	mock.recorder = &MockNodeOrchestratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNodeOrchestrator) EXPECT() *MockNodeOrchestratorMockRecorder {
+	return m.recorder
+}
+
+// CreateServer mocks base method.
+func (m *MockNodeOrchestrator) CreateServer(arg0 context.Context, arg1 *domain.Server, arg2 *domain.ServerSpec) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServer indicates an expected call of CreateServer.
+func (mr *MockNodeOrchestratorMockRecorder) CreateServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).CreateServer), arg0, arg1, arg2)
+}
+
+// DeleteServer mocks base method.
+func (m *MockNodeOrchestrator) DeleteServer(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteServer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteServer indicates an expected call of DeleteServer.
+func (mr *MockNodeOrchestratorMockRecorder) DeleteServer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).DeleteServer), arg0, arg1)
+}
+
+// GetServerHealth mocks base method.
+func (m *MockNodeOrchestrator) GetServerHealth(arg0 context.Context, arg1 string) (*domain.ServerHealth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerHealth", arg0, arg1)
+	ret0, _ := ret[0].(*domain.ServerHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerHealth indicates an expected call of GetServerHealth.
+func (mr *MockNodeOrchestratorMockRecorder) GetServerHealth(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerHealth", reflect.TypeOf((*MockNodeOrchestrator)(nil).GetServerHealth), arg0, arg1)
+}
+
+// GetServerStats mocks base method.
+func (m *MockNodeOrchestrator) GetServerStats(arg0 context.Context, arg1 string) (*domain.ServerStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerStats", arg0, arg1)
+	ret0, _ := ret[0].(*domain.ServerStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerStats indicates an expected call of GetServerStats.
+func (mr *MockNodeOrchestratorMockRecorder) GetServerStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerStats", reflect.TypeOf((*MockNodeOrchestrator)(nil).GetServerStats), arg0, arg1)
+}
+
+// ListServers mocks base method.
+func (m *MockNodeOrchestrator) ListServers(arg0 context.Context) ([]*domain.Server, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServers", arg0)
+	ret0, _ := ret[0].([]*domain.Server)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServers indicates an expected call of ListServers.
+func (mr *MockNodeOrchestratorMockRecorder) ListServers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServers", reflect.TypeOf((*MockNodeOrchestrator)(nil).ListServers), arg0)
+}
+
+// MoveServer mocks base method.
+func (m *MockNodeOrchestrator) MoveServer(arg0 context.Context, arg1 string, arg2 map[string]string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MoveServer indicates an expected call of MoveServer.
+func (mr *MockNodeOrchestratorMockRecorder) MoveServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).MoveServer), arg0, arg1, arg2)
+}
+
+// PingNode mocks base method.
+func (m *MockNodeOrchestrator) PingNode(arg0 context.Context, arg1 *domain.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PingNode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PingNode indicates an expected call of PingNode.
+func (mr *MockNodeOrchestratorMockRecorder) PingNode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingNode", reflect.TypeOf((*MockNodeOrchestrator)(nil).PingNode), arg0, arg1)
+}
+
+// ResetServerData mocks base method.
+func (m *MockNodeOrchestrator) ResetServerData(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetServerData", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetServerData indicates an expected call of ResetServerData.
+func (mr *MockNodeOrchestratorMockRecorder) ResetServerData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetServerData", reflect.TypeOf((*MockNodeOrchestrator)(nil).ResetServerData), arg0, arg1)
+}
+
+// RestoreServer mocks base method.
+func (m *MockNodeOrchestrator) RestoreServer(arg0 context.Context, arg1 string, arg2 io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreServer indicates an expected call of RestoreServer.
+func (mr *MockNodeOrchestratorMockRecorder) RestoreServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).RestoreServer), arg0, arg1, arg2)
+}
+
+// ScaleServer mocks base method.
+func (m *MockNodeOrchestrator) ScaleServer(arg0 context.Context, arg1 string, arg2 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScaleServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScaleServer indicates an expected call of ScaleServer.
+func (mr *MockNodeOrchestratorMockRecorder) ScaleServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).ScaleServer), arg0, arg1, arg2)
+}
+
+// SnapshotServer mocks base method.
+func (m *MockNodeOrchestrator) SnapshotServer(arg0 context.Context, arg1 string, arg2 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SnapshotServer indicates an expected call of SnapshotServer.
+func (mr *MockNodeOrchestratorMockRecorder) SnapshotServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).SnapshotServer), arg0, arg1, arg2)
+}
+
+// StartServer mocks base method.
+func (m *MockNodeOrchestrator) StartServer(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartServer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartServer indicates an expected call of StartServer.
+func (mr *MockNodeOrchestratorMockRecorder) StartServer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).StartServer), arg0, arg1)
+}
+
+// StopServer mocks base method.
+func (m *MockNodeOrchestrator) StopServer(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopServer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopServer indicates an expected call of StopServer.
+func (mr *MockNodeOrchestratorMockRecorder) StopServer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).StopServer), arg0, arg1)
+}
+
+// UpdateServer mocks base method.
+func (m *MockNodeOrchestrator) UpdateServer(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateServer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateServer indicates an expected call of UpdateServer.
+func (mr *MockNodeOrchestratorMockRecorder) UpdateServer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateServer", reflect.TypeOf((*MockNodeOrchestrator)(nil).UpdateServer), arg0, arg1, arg2)
+}
+
+// WatchEvents mocks base method.
+func (m *MockNodeOrchestrator) WatchEvents(arg0 context.Context) (<-chan *domain.ServerMonitorEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchEvents", arg0)
+	ret0, _ := ret[0].(<-chan *domain.ServerMonitorEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchEvents indicates an expected call of WatchEvents.
+func (mr *MockNodeOrchestratorMockRecorder) WatchEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchEvents", reflect.TypeOf((*MockNodeOrchestrator)(nil).WatchEvents), arg0)
+}
+
+// MockProvisioner is a mock of Provisioner interface.
+type MockProvisioner struct {
+	ctrl     *gomock.Controller
+	recorder *MockProvisionerMockRecorder
+}
+
+// MockProvisionerMockRecorder is the mock recorder for MockProvisioner.
+type MockProvisionerMockRecorder struct {
+	mock *MockProvisioner
+}
+
+// NewMockProvisioner creates a new mock instance.
+func NewMockProvisioner(ctrl *gomock.Controller) *MockProvisioner {
+	mock := &MockProvisioner{ctrl: ctrl}
+	mock.recorder = &MockProvisionerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProvisioner) EXPECT() *MockProvisionerMockRecorder {
+	return m.recorder
+}
+
+// Provision mocks base method.
+func (m *MockProvisioner) Provision(arg0 context.Context, arg1 *domain.Server, arg2 *domain.ProvisionRequest, arg3 func(domain.ProvisionStep)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Provision", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Provision indicates an expected call of Provision.
+func (mr *MockProvisionerMockRecorder) Provision(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Provision", reflect.TypeOf((*MockProvisioner)(nil).Provision), arg0, arg1, arg2, arg3)
+}
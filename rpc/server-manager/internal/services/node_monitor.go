@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+)
+
+// NodeMonitor периодически проверяет доступность узлов Docker Engine
+type NodeMonitor struct {
+	*periodic
+	service ports.ServerService
+}
+
+// NewNodeMonitor создает цикл проверки узлов
+func NewNodeMonitor(service ports.ServerService, interval time.Duration, logger *zap.Logger) *NodeMonitor {
+	m := &NodeMonitor{
+		periodic: newPeriodic("node monitor", interval, logger),
+		service:  service,
+	}
+	// Узлы проверяются сразу: состояние могло измениться, пока сервис не
+	// работал
+	m.start = m.check
+	m.tick = m.check
+	return m
+}
+
+// check выполняет одну проверку, ограниченную интервалом цикла
+func (m *NodeMonitor) check(ctx context.Context) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	if err := m.service.CheckNodes(ctx); err != nil {
+		m.logger.Error("node check failed", zap.Error(err))
+	}
+}
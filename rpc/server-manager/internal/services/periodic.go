@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// periodic фоновый цикл сервиса: вызывает tick раз в interval, пока цикл
+// не остановлен. Основа автомасштабирования, расписаний копий, сбора
+// статистики, проверки узлов и сверки
+type periodic struct {
+	name     string
+	interval time.Duration
+	// start вызывается один раз до первого тика, может быть nil
+	start  func(ctx context.Context)
+	tick   func(ctx context.Context)
+	fields []zap.Field // дополнительные поля сообщения о запуске
+	logger *zap.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// newPeriodic создает цикл name; tick задается владельцем цикла
+func newPeriodic(name string, interval time.Duration, logger *zap.Logger, fields ...zap.Field) *periodic {
+	return &periodic{
+		name:     name,
+		interval: interval,
+		fields:   fields,
+		logger:   logger,
+	}
+}
+
+// Start запускает цикл
+func (p *periodic) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		if p.start != nil {
+			p.start(ctx)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.tick(ctx)
+			}
+		}
+	}()
+
+	p.logger.Info(p.name+" started", append([]zap.Field{zap.Duration("interval", p.interval)}, p.fields...)...)
+}
+
+// Stop останавливает цикл и ждет завершения текущего тика
+func (p *periodic) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	p.wg.Wait()
+
+	p.logger.Info(p.name + " stopped")
+}
+
+// withTimeout ограничивает один тик интервалом цикла
+func (p *periodic) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, p.interval)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+)
+
+// Reconciler периодически сверяет серверы в базе с оркестратором
+type Reconciler struct {
+	*periodic
+	service ports.ServerService
+	request domain.ReconcileRequest
+}
+
+// NewReconciler создает цикл сверки. В режиме dryRun расхождения только
+// логируются
+func NewReconciler(service ports.ServerService, interval time.Duration, dryRun bool, policy domain.OrphanPolicy, logger *zap.Logger) *Reconciler {
+	r := &Reconciler{
+		periodic: newPeriodic("reconciler", interval, logger,
+			zap.Bool("dry_run", dryRun),
+			zap.String("orphan_policy", string(policy))),
+		service: service,
+		request: domain.ReconcileRequest{OrphanPolicy: policy, DryRun: dryRun},
+	}
+	r.tick = r.reconcile
+	return r
+}
+
+// reconcile выполняет одну сверку, ограниченную интервалом цикла
+func (r *Reconciler) reconcile(ctx context.Context) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	request := r.request
+	report, err := r.service.ReconcileServers(ctx, &request)
+	if err != nil {
+		r.logger.Error("reconciliation failed", zap.Error(err))
+		return
+	}
+
+	for _, drift := range report.Drifts {
+		r.logger.Info("server drift",
+			zap.String("server_id", drift.ServerID),
+			zap.String("node_id", drift.NodeID),
+			zap.String("type", string(drift.Type)),
+			zap.String("expected", drift.Expected),
+			zap.String("actual", drift.Actual),
+			zap.String("action", string(drift.Action)),
+			zap.Bool("applied", drift.Applied),
+			zap.String("error", drift.Error))
+	}
+}
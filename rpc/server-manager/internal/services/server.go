@@ -6,7 +6,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/docker"
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
 	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
 	"go.uber.org/zap"
@@ -20,32 +19,80 @@ type ServerService struct {
 	scalingRepo   ports.ScalingRepository
 	backupRepo    ports.BackupRepository
 	updateRepo    ports.UpdateRepository
-	dockerAdapter *docker.DockerAdapter
+	regionRepo    ports.RegionRepository
+	nodeRepo      ports.NodeRepository
+	provisionRepo ports.ProvisionRepository
+	orchestrator  ports.Orchestrator
+	provisioner   ports.Provisioner
+	backupStores  ports.BackupStoreProvider
 	logger        *zap.Logger
 	mutex         sync.RWMutex
+	// scalingMutex исключает параллельную оценку масштабирования
+	scalingMutex sync.Mutex
+	// backupMutex исключает параллельное копирование и восстановление
+	backupMutex sync.Mutex
+	// updates отмена выполняемых обновлений по ID сервера
+	updates     map[string]context.CancelFunc
+	updateMutex sync.Mutex
+	// operations длительные операции над серверами по ID сервера: перенос,
+	// копирование и восстановление данных
+	operations     map[string]string
+	operationMutex sync.Mutex
+	// events рассылка событий мониторинга
+	events *eventHub
+	// background контекст фоновых подготовок хостов, отменяется в Stop
+	background       context.Context
+	stopBackground   context.CancelFunc
+	provisions       sync.WaitGroup
+	provisionTimeout time.Duration
+}
+
+// ServerServiceOptions зависимости сервиса управления серверами. Без
+// необязательных репозиториев, провижинера и хранилищ копий
+// соответствующие операции возвращают ошибку
+type ServerServiceOptions struct {
+	ServerRepo    ports.ServerRepository
+	StatsRepo     ports.StatsRepository
+	HealthRepo    ports.HealthRepository
+	ScalingRepo   ports.ScalingRepository
+	BackupRepo    ports.BackupRepository
+	UpdateRepo    ports.UpdateRepository
+	RegionRepo    ports.RegionRepository
+	NodeRepo      ports.NodeRepository
+	ProvisionRepo ports.ProvisionRepository
+	Orchestrator  ports.Orchestrator
+	Provisioner   ports.Provisioner
+	BackupStores  ports.BackupStoreProvider
+	Logger        *zap.Logger
+	// ProvisionTimeout ограничивает подготовку хоста; 0 - 30 минут
+	ProvisionTimeout time.Duration
 }
 
 // NewServerService создает новый сервис управления серверами
-func NewServerService(
-	serverRepo ports.ServerRepository,
-	statsRepo ports.StatsRepository,
-	healthRepo ports.HealthRepository,
-	scalingRepo ports.ScalingRepository,
-	backupRepo ports.BackupRepository,
-	updateRepo ports.UpdateRepository,
-	dockerAdapter *docker.DockerAdapter,
-	logger *zap.Logger,
-) ports.ServerService {
-	return &ServerService{
-		serverRepo:    serverRepo,
-		statsRepo:     statsRepo,
-		healthRepo:    healthRepo,
-		scalingRepo:   scalingRepo,
-		backupRepo:    backupRepo,
-		updateRepo:    updateRepo,
-		dockerAdapter: dockerAdapter,
-		logger:        logger,
+func NewServerService(opts ServerServiceOptions) ports.ServerService {
+	service := &ServerService{
+		serverRepo:    opts.ServerRepo,
+		statsRepo:     opts.StatsRepo,
+		healthRepo:    opts.HealthRepo,
+		scalingRepo:   opts.ScalingRepo,
+		backupRepo:    opts.BackupRepo,
+		updateRepo:    opts.UpdateRepo,
+		regionRepo:    opts.RegionRepo,
+		nodeRepo:      opts.NodeRepo,
+		provisionRepo: opts.ProvisionRepo,
+		orchestrator:  opts.Orchestrator,
+		provisioner:   opts.Provisioner,
+		backupStores:  opts.BackupStores,
+		logger:        opts.Logger,
+		operations:    make(map[string]string),
 	}
+	service.provisionTimeout = opts.ProvisionTimeout
+	if service.provisionTimeout <= 0 {
+		service.provisionTimeout = defaultProvisionTimeout
+	}
+	service.background, service.stopBackground = context.WithCancel(context.Background())
+	service.events = newEventHub(service.watchOrchestratorEvents)
+	return service
 }
 
 // CreateServer создает новый сервер
@@ -53,47 +100,53 @@ func (s *ServerService) CreateServer(ctx context.Context, req *domain.CreateServ
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	// Без региона он выбирается размещением; заданный регион должен
+	// принимать новые серверы
+	region := req.Region
+	if region == "" {
+		selected, err := s.selectRegion(ctx, req.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select region: %w", err)
+		}
+		region = selected
+	} else if err := s.checkRegion(ctx, region); err != nil {
+		return nil, err
+	}
+
 	// Создаем сервер в базе данных
 	server := &domain.Server{
-		Name:   req.Name,
-		Type:   req.Type,
-		Status: domain.ServerStatusCreating,
-		Region: req.Region,
+		Name:         req.Name,
+		Type:         req.Type,
+		Status:       domain.ServerStatusCreating,
+		Region:       region,
+		NodeSelector: req.NodeSelector,
 	}
 
 	if err := s.serverRepo.Create(ctx, server); err != nil {
 		return nil, fmt.Errorf("failed to create server in database: %w", err)
 	}
 
-	// Определяем Docker образ в зависимости от типа сервера
-	image := s.getImageForServerType(req.Type)
-	containerName := fmt.Sprintf("silence-%s-%s", req.Type, server.ID)
-
-	// Создаем контейнер
-	configInterface := make(map[string]interface{})
-	for k, v := range req.Config {
-		configInterface[k] = v
+	// Образ определяется типом сервера, конфигурация передается в окружение
+	spec := &domain.ServerSpec{
+		Image:        s.getImageForServerType(req.Type),
+		Env:          req.Config,
+		NodeSelector: req.NodeSelector,
 	}
-	containerID, err := s.dockerAdapter.CreateContainer(ctx, containerName, image, configInterface)
+	server.Image = spec.Image
+
+	handle, err := s.orchestrator.CreateServer(ctx, server, spec)
 	if err != nil {
 		// Обновляем статус на ошибку
 		server.Status = domain.ServerStatusError
 		if err := s.serverRepo.Update(ctx, server); err != nil {
 			s.logger.Error("failed to update server status to error", zap.String("server_id", server.ID), zap.Error(err))
 		}
-		return nil, fmt.Errorf("failed to create docker container: %w", err)
-	}
-
-	// Запускаем контейнер
-	if err := s.dockerAdapter.StartContainer(ctx, containerID); err != nil {
-		server.Status = domain.ServerStatusError
-		if err := s.serverRepo.Update(ctx, server); err != nil {
-			s.logger.Error("failed to update server status to error", zap.String("server_id", server.ID), zap.Error(err))
-		}
-		return nil, fmt.Errorf("failed to start docker container: %w", err)
+		return nil, fmt.Errorf("failed to create server in orchestrator: %w", err)
 	}
 
-	// Обновляем статус на запущенный
+	// Обновляем статус на запущенный; узел, выбранный оркестратором, уже
+	// записан в server.NodeID
+	server.OrchestratorHandle = handle
 	server.Status = domain.ServerStatusRunning
 	if err := s.serverRepo.Update(ctx, server); err != nil {
 		s.logger.Error("failed to update server status", zap.String("server_id", server.ID), zap.Error(err))
@@ -103,7 +156,7 @@ func (s *ServerService) CreateServer(ctx context.Context, req *domain.CreateServ
 		zap.String("server_id", server.ID),
 		zap.String("name", server.Name),
 		zap.String("type", string(server.Type)),
-		zap.String("container_id", containerID))
+		zap.String("handle", handle))
 
 	return server, nil
 }
@@ -152,11 +205,15 @@ func (s *ServerService) DeleteServer(ctx context.Context, id string) error {
 	if err != nil {
 		return err
 	}
+	if err := s.checkOperation(id); err != nil {
+		return err
+	}
 
-	// Останавливаем контейнер если он запущен
-	if server.Status == domain.ServerStatusRunning {
-		// TODO: получить container ID из метаданных сервера и остановить контейнер
-		s.logger.Info("server is running, container stop not implemented yet", zap.String("server_id", id))
+	// Удаляем ресурсы оркестратора; сервер без handle ресурсов не имеет
+	if server.OrchestratorHandle != "" {
+		if err := s.orchestrator.DeleteServer(ctx, server.OrchestratorHandle); err != nil {
+			return fmt.Errorf("failed to delete server in orchestrator: %w", err)
+		}
 	}
 
 	// Удаляем из базы данных (soft delete)
@@ -164,6 +221,7 @@ func (s *ServerService) DeleteServer(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete server: %w", err)
 	}
 
+	s.publishEvent(id, domain.MonitorEventDeleted, "server deleted")
 	s.logger.Info("server deleted", zap.String("server_id", id))
 	return nil
 }
@@ -181,17 +239,23 @@ func (s *ServerService) StartServer(ctx context.Context, id string) error {
 	if server.Status == domain.ServerStatusRunning {
 		return fmt.Errorf("server is already running")
 	}
+	if err := s.checkOperation(id); err != nil {
+		return err
+	}
 
-	// TODO: запустить контейнер
-	// containerID := getContainerID(server)
-	// if err := s.dockerAdapter.StartContainer(ctx, containerID); err != nil {
-	//     return fmt.Errorf("failed to start container: %w", err)
-	// }
+	handle, err := orchestratorHandle(server)
+	if err != nil {
+		return err
+	}
+	if err := s.orchestrator.StartServer(ctx, handle); err != nil {
+		return fmt.Errorf("failed to start server in orchestrator: %w", err)
+	}
 
 	server.Status = domain.ServerStatusRunning
 	if err := s.serverRepo.Update(ctx, server); err != nil {
 		return fmt.Errorf("failed to update server status: %w", err)
 	}
+	s.publishStatus(server)
 
 	s.logger.Info("server started", zap.String("server_id", id))
 	return nil
@@ -210,17 +274,23 @@ func (s *ServerService) StopServer(ctx context.Context, id string) error {
 	if server.Status == domain.ServerStatusStopped {
 		return fmt.Errorf("server is already stopped")
 	}
+	if err := s.checkOperation(id); err != nil {
+		return err
+	}
 
-	// TODO: остановить контейнер
-	// containerID := getContainerID(server)
-	// if err := s.dockerAdapter.StopContainer(ctx, containerID, nil); err != nil {
-	//     return fmt.Errorf("failed to stop container: %w", err)
-	// }
+	handle, err := orchestratorHandle(server)
+	if err != nil {
+		return err
+	}
+	if err := s.orchestrator.StopServer(ctx, handle); err != nil {
+		return fmt.Errorf("failed to stop server in orchestrator: %w", err)
+	}
 
 	server.Status = domain.ServerStatusStopped
 	if err := s.serverRepo.Update(ctx, server); err != nil {
 		return fmt.Errorf("failed to update server status: %w", err)
 	}
+	s.publishStatus(server)
 
 	s.logger.Info("server stopped", zap.String("server_id", id))
 	return nil
@@ -237,6 +307,71 @@ func (s *ServerService) RestartServer(ctx context.Context, id string) error {
 	return s.StartServer(ctx, id)
 }
 
+// ScaleServer изменяет число реплик сервера
+func (s *ServerService) ScaleServer(ctx context.Context, id string, replicas int32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkOperation(id); err != nil {
+		return err
+	}
+
+	handle, err := orchestratorHandle(server)
+	if err != nil {
+		return err
+	}
+	if err := s.orchestrator.ScaleServer(ctx, handle, replicas); err != nil {
+		return fmt.Errorf("failed to scale server: %w", err)
+	}
+
+	s.publishEvent(id, domain.MonitorEventScaled, fmt.Sprintf("scaled to %d replicas", replicas))
+	s.logger.Info("server scaled", zap.String("server_id", id), zap.Int32("replicas", replicas))
+	return nil
+}
+
+// orchestratorHandle возвращает handle ресурса сервера в оркестраторе
+func orchestratorHandle(server *domain.Server) (string, error) {
+	if server.OrchestratorHandle == "" {
+		return "", fmt.Errorf("server %s has no orchestrator resource", server.ID)
+	}
+	return server.OrchestratorHandle, nil
+}
+
+// beginOperation отмечает длительную операцию op над сервером. Пока
+// операция не завершена вызовом возвращенной функции, другие длительные
+// операции, запуск, остановка, масштабирование и удаление сервера
+// отклоняются, а сверка его пропускает
+func (s *ServerService) beginOperation(serverID, op string) (func(), error) {
+	s.operationMutex.Lock()
+	defer s.operationMutex.Unlock()
+
+	if current, ok := s.operations[serverID]; ok {
+		return nil, fmt.Errorf("server %s is busy: %s in progress", serverID, current)
+	}
+	s.operations[serverID] = op
+	return func() {
+		s.operationMutex.Lock()
+		defer s.operationMutex.Unlock()
+		delete(s.operations, serverID)
+	}, nil
+}
+
+// checkOperation проверяет, что над сервером не выполняется длительная
+// операция
+func (s *ServerService) checkOperation(serverID string) error {
+	s.operationMutex.Lock()
+	defer s.operationMutex.Unlock()
+
+	if current, ok := s.operations[serverID]; ok {
+		return fmt.Errorf("server %s is busy: %s in progress", serverID, current)
+	}
+	return nil
+}
+
 // getImageForServerType возвращает Docker образ для типа сервера
 func (s *ServerService) getImageForServerType(serverType domain.ServerType) string {
 	switch serverType {
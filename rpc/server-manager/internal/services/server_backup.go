@@ -1,11 +1,20 @@
 package services
 
 import (
+	"archive/tar"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
 	"go.uber.org/zap"
 )
 
@@ -17,11 +26,15 @@ func (s *ServerService) GetBackupConfigs(ctx context.Context) ([]*domain.BackupC
 	return s.backupRepo.ListConfigs(ctx)
 }
 
-// CreateBackupConfig создает конфигурацию резервного копирования
+// CreateBackupConfig создает конфигурацию резервного копирования и
+// планирует первую копию
 func (s *ServerService) CreateBackupConfig(ctx context.Context, config *domain.BackupConfig) error {
 	if s.backupRepo == nil {
 		return fmt.Errorf("backup repository not initialized")
 	}
+	if err := s.prepareBackupConfig(config); err != nil {
+		return err
+	}
 	config.ID = uuid.New().String()
 	return s.backupRepo.SaveConfig(ctx, config)
 }
@@ -31,10 +44,44 @@ func (s *ServerService) UpdateBackupConfig(ctx context.Context, id string, confi
 	if s.backupRepo == nil {
 		return fmt.Errorf("backup repository not initialized")
 	}
+	if err := s.prepareBackupConfig(config); err != nil {
+		return err
+	}
 	config.ID = id
 	return s.backupRepo.UpdateConfig(ctx, config)
 }
 
+// prepareBackupConfig проверяет конфигурацию, подставляет значения по
+// умолчанию и вычисляет время следующей копии
+func (s *ServerService) prepareBackupConfig(config *domain.BackupConfig) error {
+	schedule, err := parseSchedule(config.Schedule)
+	if err != nil {
+		return err
+	}
+	if config.Retention < 0 {
+		return fmt.Errorf("invalid retention: %d", config.Retention)
+	}
+
+	switch config.Type {
+	case "":
+		config.Type = domain.BackupTypeFull
+	case domain.BackupTypeFull, domain.BackupTypeIncremental:
+	default:
+		return fmt.Errorf("invalid backup type: %s", config.Type)
+	}
+
+	if config.Destination == "" && s.backupStores != nil {
+		config.Destination = s.backupStores.DefaultDestination()
+	}
+
+	next := schedule.next(time.Now().UTC())
+	if next.IsZero() {
+		return fmt.Errorf("schedule %q never fires", config.Schedule)
+	}
+	config.NextBackup = &next
+	return nil
+}
+
 // DeleteBackupConfig удаляет конфигурацию резервного копирования
 func (s *ServerService) DeleteBackupConfig(ctx context.Context, id string) error {
 	if s.backupRepo == nil {
@@ -43,16 +90,496 @@ func (s *ServerService) DeleteBackupConfig(ctx context.Context, id string) error
 	return s.backupRepo.DeleteConfig(ctx, id)
 }
 
-// CreateBackup создает резервную копию
-func (s *ServerService) CreateBackup(ctx context.Context, serverID string) error {
-	// TODO: реализовать создание резервной копии
-	s.logger.Info("creating backup", zap.String("server_id", serverID))
+// CreateBackup архивирует каталог данных сервера в хранилище. Для
+// incremental копии в архив попадают только файлы, измененные после
+// предыдущей копии в том же хранилище; без нее создается полная копия.
+// Удаления файлов incremental копия не отражает
+func (s *ServerService) CreateBackup(ctx context.Context, req *domain.CreateBackupRequest) (*domain.Backup, error) {
+	if s.backupRepo == nil || s.backupStores == nil {
+		return nil, fmt.Errorf("backup storage not initialized")
+	}
+
+	s.backupMutex.Lock()
+	defer s.backupMutex.Unlock()
+
+	server, err := s.serverRepo.GetByID(ctx, req.ServerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server: %w", err)
+	}
+	done, err := s.beginOperation(server.ID, "backup")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	handle, err := orchestratorHandle(server)
+	if err != nil {
+		return nil, err
+	}
+
+	backupType := req.Type
+	switch backupType {
+	case "":
+		backupType = domain.BackupTypeFull
+	case domain.BackupTypeFull, domain.BackupTypeIncremental:
+	default:
+		return nil, fmt.Errorf("invalid backup type: %s", backupType)
+	}
+
+	destination := req.Destination
+	if destination == "" {
+		destination = s.backupStores.DefaultDestination()
+	}
+	store, err := s.backupStores.Store(destination)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &domain.Backup{
+		ID:          uuid.New().String(),
+		ServerID:    server.ID,
+		ServerType:  server.Type,
+		Region:      server.Region,
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        backupType,
+		Destination: destination,
+		// Время до снимка, чтобы следующая incremental копия захватила
+		// изменения во время архивации
+		CreatedAt: time.Now().UTC(),
+	}
+	if backup.Name == "" {
+		backup.Name = fmt.Sprintf("%s-%s", server.Name, backup.CreatedAt.Format("20060102-150405"))
+	}
+	backup.Key = path.Join(server.ID, backup.ID+".tar")
+
+	var since time.Time
+	if backupType == domain.BackupTypeIncremental {
+		parent, err := s.latestBackup(ctx, server.ID, destination)
+		if err != nil {
+			return nil, err
+		}
+		if parent != nil {
+			backup.ParentID = parent.ID
+			since = parent.CreatedAt
+		} else {
+			backup.Type = domain.BackupTypeFull
+		}
+	}
+
+	if err := s.uploadSnapshot(ctx, handle, store, backup, since); err != nil {
+		s.deleteArchive(ctx, store, backup)
+		return nil, err
+	}
+
+	if err := s.backupRepo.SaveBackup(ctx, backup); err != nil {
+		s.deleteArchive(ctx, store, backup)
+		return nil, err
+	}
+
+	s.logger.Info("backup created",
+		zap.String("backup_id", backup.ID),
+		zap.String("server_id", server.ID),
+		zap.String("type", string(backup.Type)),
+		zap.Int64("size", backup.Size))
+
+	return backup, nil
+}
+
+// latestBackup последняя копия сервера в хранилище, nil если ее нет
+func (s *ServerService) latestBackup(ctx context.Context, serverID, destination string) (*domain.Backup, error) {
+	backups, err := s.backupRepo.ListBackups(ctx, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	for i := len(backups) - 1; i >= 0; i-- {
+		if backups[i].Destination == destination {
+			return backups[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// uploadSnapshot передает снимок сервера в хранилище, считая размер и
+// контрольную сумму архива
+func (s *ServerService) uploadSnapshot(ctx context.Context, handle string, store ports.BackupStore, backup *domain.Backup, since time.Time) error {
+	snapshot, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(s.orchestrator.SnapshotServer(ctx, handle, writer))
+	}()
+	defer snapshot.Close()
+
+	var archive io.Reader = snapshot
+	if !since.IsZero() {
+		filtered, filteredWriter := io.Pipe()
+		go func() {
+			filteredWriter.CloseWithError(filterArchive(snapshot, filteredWriter, since))
+		}()
+		defer filtered.Close()
+		archive = filtered
+	}
+
+	hash := sha256.New()
+	var size byteCounter
+	if err := store.Put(ctx, backup.Key, io.TeeReader(archive, io.MultiWriter(hash, &size))); err != nil {
+		return fmt.Errorf("failed to store backup: %w", err)
+	}
+
+	backup.Size = int64(size)
+	backup.Checksum = hex.EncodeToString(hash.Sum(nil))
 	return nil
 }
 
-// RestoreBackup восстанавливает из резервной копии
-func (s *ServerService) RestoreBackup(ctx context.Context, serverID, backupID string) error {
-	// TODO: реализовать восстановление из резервной копии
-	s.logger.Info("restoring backup", zap.String("server_id", serverID), zap.String("backup_id", backupID))
+// filterArchive копирует из архива каталоги и файлы, измененные не раньше
+// since. Время в tar хранится с точностью до секунды
+func filterArchive(r io.Reader, w io.Writer, since time.Time) error {
+	since = since.Truncate(time.Second)
+	reader := tar.NewReader(r)
+	writer := tar.NewWriter(w)
+
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		if header.Typeflag != tar.TypeDir && header.ModTime.Before(since) {
+			continue
+		}
+
+		if err := writer.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		if _, err := io.Copy(writer, reader); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+
+	return writer.Close()
+}
+
+// byteCounter считает записанные байты
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// deleteArchive удаляет архив несохраненной копии
+func (s *ServerService) deleteArchive(ctx context.Context, store ports.BackupStore, backup *domain.Backup) {
+	if err := store.Delete(context.WithoutCancel(ctx), backup.Key); err != nil {
+		s.logger.Warn("failed to delete backup archive", zap.String("key", backup.Key), zap.Error(err))
+	}
+}
+
+// RestoreBackup восстанавливает данные сервера из копии. Архивы цепочки
+// (полная копия и incremental копии после нее) скачиваются и проверяются
+// до остановки сервера, затем распаковываются по порядку
+func (s *ServerService) RestoreBackup(ctx context.Context, req *domain.RestoreBackupRequest) (*domain.Server, error) {
+	if s.backupRepo == nil || s.backupStores == nil {
+		return nil, fmt.Errorf("backup storage not initialized")
+	}
+
+	s.backupMutex.Lock()
+	defer s.backupMutex.Unlock()
+
+	backup, err := s.backupRepo.GetBackup(ctx, req.BackupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+
+	var server *domain.Server
+	if req.ServerID != "" {
+		if server, err = s.serverRepo.GetByID(ctx, req.ServerID); err != nil {
+			return nil, fmt.Errorf("failed to get target server: %w", err)
+		}
+		// Каталог данных другого типа сервера имеет другое содержимое
+		if server.Type != backup.ServerType {
+			return nil, fmt.Errorf("backup %s of %s server cannot be restored to %s server %s",
+				backup.ID, backup.ServerType, server.Type, server.ID)
+		}
+		done, err := s.beginOperation(server.ID, "restore")
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+	}
+
+	chain, err := s.backupChain(ctx, backup)
+	if err != nil {
+		return nil, err
+	}
+
+	archives := make([]*os.File, 0, len(chain))
+	defer func() {
+		for _, archive := range archives {
+			archive.Close()
+			os.Remove(archive.Name())
+		}
+	}()
+	for _, item := range chain {
+		archive, err := s.downloadBackup(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, archive)
+	}
+
+	if server == nil {
+		server, err = s.CreateServer(ctx, &domain.CreateServerRequest{
+			Name:   backup.Name + "-restore",
+			Type:   backup.ServerType,
+			Region: backup.Region,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get target server: %w", err)
+		}
+	}
+	handle, err := orchestratorHandle(server)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сервер не должен писать в каталог данных во время распаковки
+	running := server.Status == domain.ServerStatusRunning
+	if running {
+		if err := s.orchestrator.StopServer(ctx, handle); err != nil {
+			return nil, fmt.Errorf("failed to stop server: %w", err)
+		}
+	}
+
+	// Копия распаковывается в пустой каталог: файлы, которых в ней нет, не
+	// должны остаться от прежних данных
+	var restoreErr error
+	if req.ServerID != "" {
+		restoreErr = s.resetServerData(ctx, server)
+		handle = server.OrchestratorHandle
+	}
+	for i := 0; restoreErr == nil && i < len(archives); i++ {
+		if err := s.orchestrator.RestoreServer(ctx, handle, archives[i]); err != nil {
+			restoreErr = fmt.Errorf("failed to restore backup %s: %w", chain[i].ID, err)
+		}
+	}
+
+	if running {
+		if err := s.orchestrator.StartServer(ctx, handle); err != nil {
+			return nil, errors.Join(restoreErr, fmt.Errorf("failed to start server: %w", err))
+		}
+	}
+	if restoreErr != nil {
+		return nil, restoreErr
+	}
+
+	s.logger.Info("backup restored",
+		zap.String("backup_id", backup.ID),
+		zap.String("server_id", server.ID),
+		zap.Int("archives", len(archives)))
+
+	return server, nil
+}
+
+// resetServerData очищает каталог данных остановленного сервера и
+// сохраняет новый handle, если оркестратор заменил ресурс
+func (s *ServerService) resetServerData(ctx context.Context, server *domain.Server) error {
+	handle, err := s.orchestrator.ResetServerData(ctx, server.OrchestratorHandle)
+	if err != nil {
+		return fmt.Errorf("failed to reset server data: %w", err)
+	}
+	if handle == server.OrchestratorHandle {
+		return nil
+	}
+
+	server.OrchestratorHandle = handle
+	if err := s.serverRepo.Update(ctx, server); err != nil {
+		return fmt.Errorf("failed to update server: %w", err)
+	}
 	return nil
 }
+
+// backupChain цепочка копий от полной до backup включительно
+func (s *ServerService) backupChain(ctx context.Context, backup *domain.Backup) ([]*domain.Backup, error) {
+	chain := []*domain.Backup{backup}
+	for current := backup; current.ParentID != ""; current = chain[0] {
+		parent, err := s.backupRepo.GetBackup(ctx, current.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent backup: %w", err)
+		}
+		chain = append([]*domain.Backup{parent}, chain...)
+	}
+	return chain, nil
+}
+
+// downloadBackup скачивает архив во временный файл и проверяет его
+// контрольную сумму
+func (s *ServerService) downloadBackup(ctx context.Context, backup *domain.Backup) (*os.File, error) {
+	store, err := s.backupStores.Store(backup.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := store.Get(ctx, backup.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup archive: %w", err)
+	}
+	defer content.Close()
+
+	file, err := os.CreateTemp("", "backup-*.tar")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), content)
+	if err == nil {
+		if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != backup.Checksum {
+			err = fmt.Errorf("backup %s checksum mismatch: expected %s, got %s", backup.ID, backup.Checksum, checksum)
+		}
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to download backup: %w", err)
+	}
+
+	return file, nil
+}
+
+// ListBackups получает резервные копии сервера от старых к новым
+func (s *ServerService) ListBackups(ctx context.Context, serverID string) ([]*domain.Backup, error) {
+	if s.backupRepo == nil {
+		return []*domain.Backup{}, nil
+	}
+	return s.backupRepo.ListBackups(ctx, serverID)
+}
+
+// RunScheduledBackups создает копии по наступившим расписаниям и удаляет
+// копии старше срока хранения. Ошибки отдельных конфигураций не
+// прерывают обработку остальных
+func (s *ServerService) RunScheduledBackups(ctx context.Context) error {
+	if s.backupRepo == nil || s.backupStores == nil {
+		return nil
+	}
+
+	configs, err := s.backupRepo.ListConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list backup configs: %w", err)
+	}
+
+	now := time.Now().UTC()
+	var errs []error
+	for _, config := range configs {
+		if !config.Enabled {
+			continue
+		}
+		if err := s.runBackupConfig(ctx, config, now); err != nil {
+			errs = append(errs, fmt.Errorf("backup config %s: %w", config.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runBackupConfig выполняет одну конфигурацию: копия, если подошло время,
+// перенос следующего запуска и очистка старых копий
+func (s *ServerService) runBackupConfig(ctx context.Context, config *domain.BackupConfig, now time.Time) error {
+	schedule, err := parseSchedule(config.Schedule)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	// Без запланированного времени только планируем, копию не создаем
+	if config.NextBackup == nil || !config.NextBackup.After(now) {
+		if config.NextBackup != nil {
+			_, err := s.CreateBackup(ctx, &domain.CreateBackupRequest{
+				ServerID:    config.ServerID,
+				Description: "scheduled backup",
+				Type:        config.Type,
+				Destination: config.Destination,
+			})
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				config.LastBackup = &now
+			}
+		}
+
+		next := schedule.next(now)
+		config.NextBackup = &next
+		if err := s.backupRepo.UpdateConfig(ctx, config); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if config.Retention > 0 {
+		cutoff := now.AddDate(0, 0, -config.Retention)
+		if err := s.pruneBackups(ctx, config.ServerID, config.Destination, cutoff); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// pruneBackups удаляет копии сервера в хранилище, созданные до cutoff.
+// Копии, от которых зависят оставшиеся incremental копии, сохраняются
+func (s *ServerService) pruneBackups(ctx context.Context, serverID, destination string, cutoff time.Time) error {
+	if destination == "" {
+		destination = s.backupStores.DefaultDestination()
+	}
+
+	backups, err := s.backupRepo.ListBackups(ctx, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	byID := make(map[string]*domain.Backup, len(backups))
+	for _, backup := range backups {
+		byID[backup.ID] = backup
+	}
+
+	keep := make(map[string]bool)
+	for _, backup := range backups {
+		if backup.Destination == destination && backup.CreatedAt.Before(cutoff) {
+			continue
+		}
+		for current := backup; current != nil && !keep[current.ID]; current = byID[current.ParentID] {
+			keep[current.ID] = true
+		}
+	}
+
+	store, err := s.backupStores.Store(destination)
+	if err != nil {
+		return err
+	}
+
+	// От новых к старым, чтобы incremental копии удалялись раньше родителей
+	var errs []error
+	for i := len(backups) - 1; i >= 0; i-- {
+		backup := backups[i]
+		if keep[backup.ID] {
+			continue
+		}
+
+		if err := store.Delete(ctx, backup.Key); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.backupRepo.DeleteBackup(ctx, backup.ID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		s.logger.Info("expired backup deleted",
+			zap.String("backup_id", backup.ID),
+			zap.String("server_id", serverID))
+	}
+
+	return errors.Join(errs...)
+}
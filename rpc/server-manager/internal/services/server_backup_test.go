@@ -0,0 +1,437 @@
+package services_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/adapters/storage"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+// archiveFile файл tar-архива с временем изменения
+type archiveFile struct {
+	content string
+	modTime time.Time
+}
+
+func tarArchive(files map[string]archiveFile) []byte {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for name, file := range files {
+		Expect(writer.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(file.content)),
+			ModTime: file.modTime,
+		})).To(Succeed())
+		_, err := writer.Write([]byte(file.content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(writer.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+func archiveNames(data []byte) []string {
+	var names []string
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return names
+		}
+		Expect(err).NotTo(HaveOccurred())
+		names = append(names, header.Name)
+	}
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("ServerService backups", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var mockServerRepo *MockServerRepository
+	var mockBackupRepo *MockBackupRepository
+	var mockOrchestrator *MockOrchestrator
+	var root, destination string
+	var server *domain.Server
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockBackupRepo = NewMockBackupRepository(ctrl)
+		mockOrchestrator = NewMockOrchestrator(ctrl)
+		root = GinkgoT().TempDir()
+		destination = "file://" + root
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			BackupRepo:   mockBackupRepo,
+			Orchestrator: mockOrchestrator,
+			BackupStores: storage.NewProvider(destination, storage.S3Config{}, nil),
+			Logger:       zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+
+		server = &domain.Server{
+			ID:                 "server-1",
+			Name:               "vpn-eu-1",
+			Type:               domain.ServerTypeVPN,
+			Region:             "eu-west-1",
+			Status:             domain.ServerStatusRunning,
+			OrchestratorHandle: "handle-1",
+		}
+	})
+
+	snapshot := func(data []byte) {
+		mockOrchestrator.EXPECT().SnapshotServer(gomock.Any(), "handle-1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, w io.Writer) error {
+				_, err := w.Write(data)
+				return err
+			})
+	}
+
+	storedArchive := func(backup *domain.Backup) []byte {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(backup.Key)))
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	Describe("CreateBackup", func() {
+		BeforeEach(func() {
+			mockServerRepo.EXPECT().GetByID(ctx, "server-1").Return(server, nil)
+		})
+
+		It("should store full snapshot with checksum", func() {
+			data := tarArchive(map[string]archiveFile{"wg0.conf": {content: "PrivateKey = x", modTime: time.Now()}})
+			snapshot(data)
+			mockBackupRepo.EXPECT().SaveBackup(ctx, gomock.Any()).Return(nil)
+
+			backup, err := serverService.CreateBackup(ctx, &domain.CreateBackupRequest{ServerID: "server-1"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backup.Type).To(Equal(domain.BackupTypeFull))
+			Expect(backup.Destination).To(Equal(destination))
+			Expect(backup.ServerType).To(Equal(domain.ServerTypeVPN))
+			Expect(backup.Name).To(HavePrefix("vpn-eu-1-"))
+			Expect(backup.Size).To(Equal(int64(len(data))))
+			Expect(backup.Checksum).To(Equal(checksum(data)))
+			Expect(storedArchive(backup)).To(Equal(data))
+		})
+
+		It("should store only files changed since parent for incremental backup", func() {
+			parent := &domain.Backup{ID: "backup-0", Destination: destination, CreatedAt: time.Now().Add(-time.Hour)}
+			mockBackupRepo.EXPECT().ListBackups(ctx, "server-1").Return([]*domain.Backup{parent}, nil)
+			snapshot(tarArchive(map[string]archiveFile{
+				"wg0.conf":         {content: "unchanged", modTime: time.Now().Add(-2 * time.Hour)},
+				"peers/alice.conf": {content: "added", modTime: time.Now()},
+			}))
+			mockBackupRepo.EXPECT().SaveBackup(ctx, gomock.Any()).Return(nil)
+
+			backup, err := serverService.CreateBackup(ctx, &domain.CreateBackupRequest{
+				ServerID: "server-1",
+				Type:     domain.BackupTypeIncremental,
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backup.Type).To(Equal(domain.BackupTypeIncremental))
+			Expect(backup.ParentID).To(Equal("backup-0"))
+			Expect(archiveNames(storedArchive(backup))).To(Equal([]string{"peers/alice.conf"}))
+		})
+
+		It("should fall back to full backup without parent", func() {
+			mockBackupRepo.EXPECT().ListBackups(ctx, "server-1").Return([]*domain.Backup{
+				{ID: "elsewhere", Destination: "s3://other"},
+			}, nil)
+			snapshot(tarArchive(nil))
+			mockBackupRepo.EXPECT().SaveBackup(ctx, gomock.Any()).Return(nil)
+
+			backup, err := serverService.CreateBackup(ctx, &domain.CreateBackupRequest{
+				ServerID: "server-1",
+				Type:     domain.BackupTypeIncremental,
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backup.Type).To(Equal(domain.BackupTypeFull))
+			Expect(backup.ParentID).To(BeEmpty())
+		})
+
+		It("should not keep archive when snapshot fails", func() {
+			mockOrchestrator.EXPECT().SnapshotServer(gomock.Any(), "handle-1", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, w io.Writer) error {
+					_, _ = w.Write([]byte("partial"))
+					return errors.New("exec failed")
+				})
+
+			_, err := serverService.CreateBackup(ctx, &domain.CreateBackupRequest{ServerID: "server-1"})
+
+			Expect(err).To(MatchError(ContainSubstring("exec failed")))
+			entries, _ := os.ReadDir(filepath.Join(root, "server-1"))
+			Expect(entries).To(BeEmpty())
+		})
+	})
+
+	Describe("RestoreBackup", func() {
+		var full, incremental *domain.Backup
+		var fullData, incrementalData []byte
+
+		BeforeEach(func() {
+			store := storage.NewLocalStore(root)
+			fullData = tarArchive(map[string]archiveFile{"wg0.conf": {content: "full"}})
+			incrementalData = tarArchive(map[string]archiveFile{"peers/alice.conf": {content: "incremental"}})
+			Expect(store.Put(ctx, "server-1/full.tar", bytes.NewReader(fullData))).To(Succeed())
+			Expect(store.Put(ctx, "server-1/incremental.tar", bytes.NewReader(incrementalData))).To(Succeed())
+
+			full = &domain.Backup{
+				ID: "full", ServerID: "server-1", ServerType: domain.ServerTypeVPN, Region: "eu-west-1",
+				Name: "vpn-eu-1-full", Type: domain.BackupTypeFull, Destination: destination,
+				Key: "server-1/full.tar", Checksum: checksum(fullData),
+			}
+			incremental = &domain.Backup{
+				ID: "incremental", ServerID: "server-1", ServerType: domain.ServerTypeVPN, Region: "eu-west-1",
+				Name: "vpn-eu-1-incremental", Type: domain.BackupTypeIncremental, ParentID: "full",
+				Destination: destination, Key: "server-1/incremental.tar", Checksum: checksum(incrementalData),
+			}
+			mockBackupRepo.EXPECT().GetBackup(ctx, "incremental").Return(incremental, nil)
+			// Цепочка не читается, если копия не подходит серверу
+			mockBackupRepo.EXPECT().GetBackup(ctx, "full").Return(full, nil).MaxTimes(1)
+		})
+
+		restored := func(expected []byte) func(context.Context, string, io.Reader) error {
+			return func(_ context.Context, _ string, r io.Reader) error {
+				data, err := io.ReadAll(r)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(Equal(expected))
+				return nil
+			}
+		}
+
+		It("should apply chain to emptied stopped server and start it again", func() {
+			mockServerRepo.EXPECT().GetByID(ctx, "server-1").Return(server, nil)
+			gomock.InOrder(
+				mockOrchestrator.EXPECT().StopServer(ctx, "handle-1").Return(nil),
+				// Оркестратор заменил ресурс сервера, новый handle сохраняется
+				mockOrchestrator.EXPECT().ResetServerData(ctx, "handle-1").Return("handle-1-reset", nil),
+				mockServerRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, updated *domain.Server) error {
+					Expect(updated.OrchestratorHandle).To(Equal("handle-1-reset"))
+					return nil
+				}),
+				mockOrchestrator.EXPECT().RestoreServer(ctx, "handle-1-reset", gomock.Any()).DoAndReturn(restored(fullData)),
+				mockOrchestrator.EXPECT().RestoreServer(ctx, "handle-1-reset", gomock.Any()).DoAndReturn(restored(incrementalData)),
+				mockOrchestrator.EXPECT().StartServer(ctx, "handle-1-reset").Return(nil),
+			)
+
+			target, err := serverService.RestoreBackup(ctx, &domain.RestoreBackupRequest{
+				BackupID: "incremental",
+				ServerID: "server-1",
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target.ID).To(Equal("server-1"))
+		})
+
+		It("should not extract into server whose data could not be reset", func() {
+			mockServerRepo.EXPECT().GetByID(ctx, "server-1").Return(server, nil)
+			gomock.InOrder(
+				mockOrchestrator.EXPECT().StopServer(ctx, "handle-1").Return(nil),
+				mockOrchestrator.EXPECT().ResetServerData(ctx, "handle-1").Return("", errors.New("copy job did not start")),
+				mockOrchestrator.EXPECT().StartServer(ctx, "handle-1").Return(nil),
+			)
+
+			_, err := serverService.RestoreBackup(ctx, &domain.RestoreBackupRequest{
+				BackupID: "incremental",
+				ServerID: "server-1",
+			})
+
+			Expect(err).To(MatchError(ContainSubstring("failed to reset server data")))
+		})
+
+		It("should refuse backup of another server type", func() {
+			dpi := *server
+			dpi.Type = domain.ServerTypeDPI
+			mockServerRepo.EXPECT().GetByID(ctx, "server-1").Return(&dpi, nil)
+
+			_, err := serverService.RestoreBackup(ctx, &domain.RestoreBackupRequest{
+				BackupID: "incremental",
+				ServerID: "server-1",
+			})
+
+			Expect(err).To(MatchError(ContainSubstring("cannot be restored to dpi server")))
+		})
+
+		It("should restore into new server of the same type and region", func() {
+			mockServerRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, created *domain.Server) error {
+				Expect(created.Name).To(Equal("vpn-eu-1-incremental-restore"))
+				Expect(created.Type).To(Equal(domain.ServerTypeVPN))
+				Expect(created.Region).To(Equal("eu-west-1"))
+				created.ID = "server-2"
+				return nil
+			})
+			mockOrchestrator.EXPECT().CreateServer(ctx, gomock.Any(), gomock.Any()).Return("handle-2", nil)
+			mockServerRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+			gomock.InOrder(
+				mockOrchestrator.EXPECT().StopServer(ctx, "handle-2").Return(nil),
+				mockOrchestrator.EXPECT().RestoreServer(ctx, "handle-2", gomock.Any()).Times(2).Return(nil),
+				mockOrchestrator.EXPECT().StartServer(ctx, "handle-2").Return(nil),
+			)
+
+			target, err := serverService.RestoreBackup(ctx, &domain.RestoreBackupRequest{BackupID: "incremental"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target.ID).To(Equal("server-2"))
+		})
+
+		It("should refuse corrupted archive before touching server", func() {
+			Expect(os.WriteFile(filepath.Join(root, "server-1", "full.tar"), []byte("corrupted"), 0o600)).To(Succeed())
+			mockServerRepo.EXPECT().GetByID(ctx, "server-1").Return(server, nil)
+
+			_, err := serverService.RestoreBackup(ctx, &domain.RestoreBackupRequest{
+				BackupID: "incremental",
+				ServerID: "server-1",
+			})
+
+			Expect(err).To(MatchError(ContainSubstring("checksum mismatch")))
+		})
+	})
+
+	Describe("BackupConfig", func() {
+		It("should schedule next backup and fill defaults", func() {
+			mockBackupRepo.EXPECT().SaveConfig(ctx, gomock.Any()).Return(nil)
+			config := &domain.BackupConfig{ServerID: "server-1", Schedule: "30 3 * * *", Retention: 7, Enabled: true}
+
+			Expect(serverService.CreateBackupConfig(ctx, config)).To(Succeed())
+
+			Expect(config.ID).NotTo(BeEmpty())
+			Expect(config.Type).To(Equal(domain.BackupTypeFull))
+			Expect(config.Destination).To(Equal(destination))
+			Expect(config.NextBackup).NotTo(BeNil())
+			Expect(config.NextBackup.Hour()).To(Equal(3))
+			Expect(config.NextBackup.Minute()).To(Equal(30))
+			Expect(*config.NextBackup).To(BeTemporally("~", time.Now().Add(12*time.Hour), 12*time.Hour))
+		})
+
+		DescribeTable("should follow cron schedule",
+			func(schedule string, matches func(time.Time) bool, within time.Duration) {
+				mockBackupRepo.EXPECT().SaveConfig(ctx, gomock.Any()).Return(nil)
+				config := &domain.BackupConfig{ServerID: "server-1", Schedule: schedule}
+
+				Expect(serverService.CreateBackupConfig(ctx, config)).To(Succeed())
+
+				next := *config.NextBackup
+				Expect(matches(next)).To(BeTrue(), next.String())
+				Expect(next).To(BeTemporally(">", time.Now()))
+				Expect(next).To(BeTemporally("<=", time.Now().Add(within)))
+			},
+			Entry("hourly macro", "@hourly", func(t time.Time) bool { return t.Minute() == 0 }, time.Hour),
+			Entry("minute step", "*/15 * * * *", func(t time.Time) bool { return t.Minute()%15 == 0 }, 15*time.Minute),
+			Entry("hour range and list", "0,30 9-17 * * *", func(t time.Time) bool {
+				return (t.Minute() == 0 || t.Minute() == 30) && t.Hour() >= 9 && t.Hour() <= 17
+			}, 24*time.Hour),
+			Entry("sunday as 7", "0 0 * * 7", func(t time.Time) bool {
+				return t.Weekday() == time.Sunday && t.Hour() == 0
+			}, 7*24*time.Hour),
+			Entry("day of month or weekday", "0 0 1 * 1", func(t time.Time) bool {
+				return t.Day() == 1 || t.Weekday() == time.Monday
+			}, 7*24*time.Hour),
+		)
+
+		DescribeTable("should reject invalid config",
+			func(config *domain.BackupConfig) {
+				Expect(serverService.CreateBackupConfig(ctx, config)).NotTo(Succeed())
+			},
+			Entry("too few fields", &domain.BackupConfig{Schedule: "0 3 * *"}),
+			Entry("minute out of range", &domain.BackupConfig{Schedule: "60 * * * *"}),
+			Entry("zero step", &domain.BackupConfig{Schedule: "*/0 * * * *"}),
+			Entry("never fires", &domain.BackupConfig{Schedule: "0 0 30 2 *"}),
+			Entry("negative retention", &domain.BackupConfig{Schedule: "@daily", Retention: -1}),
+			Entry("unknown type", &domain.BackupConfig{Schedule: "@daily", Type: "differential"}),
+		)
+	})
+
+	Describe("RunScheduledBackups", func() {
+		var config *domain.BackupConfig
+
+		BeforeEach(func() {
+			config = &domain.BackupConfig{
+				ID:          "config-1",
+				ServerID:    "server-1",
+				Schedule:    "@daily",
+				Retention:   7,
+				Type:        domain.BackupTypeFull,
+				Destination: destination,
+				Enabled:     true,
+			}
+		})
+
+		It("should back up when schedule is due and delete expired backups", func() {
+			due := time.Now().Add(-time.Minute)
+			config.NextBackup = &due
+			mockBackupRepo.EXPECT().ListConfigs(ctx).Return([]*domain.BackupConfig{config}, nil)
+			mockServerRepo.EXPECT().GetByID(ctx, "server-1").Return(server, nil)
+			snapshot(tarArchive(nil))
+			mockBackupRepo.EXPECT().SaveBackup(ctx, gomock.Any()).Return(nil)
+			mockBackupRepo.EXPECT().UpdateConfig(ctx, config).DoAndReturn(func(_ context.Context, updated *domain.BackupConfig) error {
+				Expect(updated.LastBackup).NotTo(BeNil())
+				Expect(*updated.NextBackup).To(BeTemporally(">", time.Now()))
+				return nil
+			})
+
+			store := storage.NewLocalStore(root)
+			Expect(store.Put(ctx, "server-1/old-full.tar", strings.NewReader("old"))).To(Succeed())
+			Expect(store.Put(ctx, "server-1/old-incremental.tar", strings.NewReader("old"))).To(Succeed())
+			mockBackupRepo.EXPECT().ListBackups(ctx, "server-1").Return([]*domain.Backup{
+				{ID: "old-full", Destination: destination, Key: "server-1/old-full.tar", CreatedAt: time.Now().AddDate(0, 0, -10)},
+				{ID: "old-incremental", ParentID: "old-full", Destination: destination, Key: "server-1/old-incremental.tar", CreatedAt: time.Now().AddDate(0, 0, -9)},
+				{ID: "other-store", Destination: "s3://other", CreatedAt: time.Now().AddDate(0, 0, -30)},
+				{ID: "recent", Destination: destination, CreatedAt: time.Now().Add(-time.Hour)},
+			}, nil)
+			gomock.InOrder(
+				mockBackupRepo.EXPECT().DeleteBackup(ctx, "old-incremental").Return(nil),
+				mockBackupRepo.EXPECT().DeleteBackup(ctx, "old-full").Return(nil),
+			)
+
+			Expect(serverService.RunScheduledBackups(ctx)).To(Succeed())
+
+			_, err := os.Stat(filepath.Join(root, "server-1", "old-full.tar"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("should keep expired parents of retained incremental backups", func() {
+			later := time.Now().Add(time.Hour)
+			config.NextBackup = &later
+			mockBackupRepo.EXPECT().ListConfigs(ctx).Return([]*domain.BackupConfig{config}, nil)
+			mockBackupRepo.EXPECT().ListBackups(ctx, "server-1").Return([]*domain.Backup{
+				{ID: "old-full", Destination: destination, CreatedAt: time.Now().AddDate(0, 0, -10)},
+				{ID: "incremental", ParentID: "old-full", Destination: destination, CreatedAt: time.Now().Add(-time.Hour)},
+			}, nil)
+
+			Expect(serverService.RunScheduledBackups(ctx)).To(Succeed())
+		})
+
+		It("should only schedule config without planned time", func() {
+			config.Retention = 0
+			mockBackupRepo.EXPECT().ListConfigs(ctx).Return([]*domain.BackupConfig{config}, nil)
+			mockBackupRepo.EXPECT().UpdateConfig(ctx, config).Return(nil)
+
+			Expect(serverService.RunScheduledBackups(ctx)).To(Succeed())
+			Expect(config.LastBackup).To(BeNil())
+			Expect(config.NextBackup).NotTo(BeNil())
+		})
+	})
+})
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultMonitorInterval период статистики, если клиент его не задал
+	defaultMonitorInterval = 5 * time.Second
+	// minMonitorInterval нижняя граница периода статистики
+	minMonitorInterval = time.Second
+	// monitorBufferSize очередь событий подписчика. При переполнении события
+	// отбрасываются, чтобы медленный клиент не задерживал остальных
+	monitorBufferSize = 64
+	// monitorRetryInterval пауза перед переподключением к событиям
+	// оркестратора
+	monitorRetryInterval = 5 * time.Second
+)
+
+// eventHub рассылает события мониторинга подписчикам серверов. Источник
+// watch работает, пока есть хотя бы один подписчик
+type eventHub struct {
+	mutex       sync.Mutex
+	subscribers map[string]map[chan *domain.ServerMonitorEvent]struct{}
+	count       int
+	watch       func(ctx context.Context)
+	cancel      context.CancelFunc
+}
+
+// newEventHub создает рассылку событий с источником watch
+func newEventHub(watch func(ctx context.Context)) *eventHub {
+	return &eventHub{
+		subscribers: make(map[string]map[chan *domain.ServerMonitorEvent]struct{}),
+		watch:       watch,
+	}
+}
+
+// subscribe подписывается на события сервера
+func (h *eventHub) subscribe(serverID string) chan *domain.ServerMonitorEvent {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	events := make(chan *domain.ServerMonitorEvent, monitorBufferSize)
+	if h.subscribers[serverID] == nil {
+		h.subscribers[serverID] = make(map[chan *domain.ServerMonitorEvent]struct{})
+	}
+	h.subscribers[serverID][events] = struct{}{}
+
+	h.count++
+	if h.count == 1 && h.watch != nil {
+		var ctx context.Context
+		ctx, h.cancel = context.WithCancel(context.Background())
+		go h.watch(ctx)
+	}
+	return events
+}
+
+// unsubscribe отменяет подписку; с последним подписчиком останавливается
+// источник событий
+func (h *eventHub) unsubscribe(serverID string, events chan *domain.ServerMonitorEvent) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.subscribers[serverID][events]; !ok {
+		return
+	}
+	delete(h.subscribers[serverID], events)
+	if len(h.subscribers[serverID]) == 0 {
+		delete(h.subscribers, serverID)
+	}
+
+	h.count--
+	if h.count == 0 && h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+}
+
+// publish передает событие подписчикам его сервера
+func (h *eventHub) publish(event *domain.ServerMonitorEvent) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for events := range h.subscribers[event.ServerID] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// MonitorServer передает статистику и здоровье сервера с периодом interval
+// вместе с событиями жизненного цикла: сменой статуса, непрохождением
+// проверок, перезапусками и OOM контейнеров, масштабированием и удалением
+func (s *ServerService) MonitorServer(ctx context.Context, id string, interval time.Duration) (<-chan *domain.ServerMonitorEvent, error) {
+	if interval <= 0 {
+		interval = defaultMonitorInterval
+	}
+	interval = max(interval, minMonitorInterval)
+
+	if _, err := s.serverRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	events := s.events.subscribe(id)
+	out := make(chan *domain.ServerMonitorEvent)
+	go func() {
+		defer close(out)
+		defer s.events.unsubscribe(id, events)
+
+		send := func(event *domain.ServerMonitorEvent) bool {
+			select {
+			case out <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		state := &monitorState{}
+		for {
+			for _, event := range s.pollServer(ctx, id, state) {
+				if !send(event) {
+					return
+				}
+			}
+
+		wait:
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					break wait
+				case event := <-events:
+					if event.Type == domain.MonitorEventStatusChanged {
+						// Статус уже мог прийти из опроса оркестратора
+						if event.Status == state.status {
+							continue
+						}
+						state.status = event.Status
+					}
+					if !send(event) || event.Type == domain.MonitorEventDeleted {
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// monitorState состояние сервера, известное подписчику
+type monitorState struct {
+	status  domain.ServerStatus
+	failing bool
+}
+
+// pollServer снимает статистику и здоровье сервера. За срезом следуют
+// события смены статуса и непрохождения проверок относительно state
+func (s *ServerService) pollServer(ctx context.Context, id string, state *monitorState) []*domain.ServerMonitorEvent {
+	now := time.Now()
+	snapshot := &domain.ServerMonitorEvent{
+		ServerID:  id,
+		Type:      domain.MonitorEventStats,
+		Timestamp: now,
+	}
+
+	// Опрос подписчика не пишет в историю, ее ведет сборщик статистики
+	stats, err := s.serverStats(ctx, id, false)
+	if err != nil {
+		s.logger.Debug("failed to get stats for monitoring", zap.String("server_id", id), zap.Error(err))
+	} else {
+		snapshot.Stats = stats
+	}
+
+	health, err := s.GetServerHealth(ctx, id)
+	if err != nil {
+		s.logger.Debug("failed to get health for monitoring", zap.String("server_id", id), zap.Error(err))
+		return []*domain.ServerMonitorEvent{snapshot}
+	}
+	snapshot.Health = health
+	snapshot.Status = health.Status
+
+	events := []*domain.ServerMonitorEvent{snapshot}
+	if state.status != "" && health.Status != state.status {
+		events = append(events, &domain.ServerMonitorEvent{
+			ServerID:  id,
+			Type:      domain.MonitorEventStatusChanged,
+			Message:   fmt.Sprintf("status changed from %s to %s", state.status, health.Status),
+			Status:    health.Status,
+			Timestamp: now,
+		})
+	}
+	state.status = health.Status
+
+	failing := health.Status == domain.ServerStatusError
+	if failing && !state.failing {
+		events = append(events, &domain.ServerMonitorEvent{
+			ServerID:  id,
+			Type:      domain.MonitorEventHealthCheckFailed,
+			Message:   health.Message,
+			Status:    health.Status,
+			Health:    health,
+			Timestamp: now,
+		})
+	}
+	state.failing = failing
+
+	return events
+}
+
+// watchOrchestratorEvents передает подписчикам события оркестратора,
+// переподключаясь при обрыве потока
+func (s *ServerService) watchOrchestratorEvents(ctx context.Context) {
+	for {
+		events, err := s.orchestrator.WatchEvents(ctx)
+		if err != nil {
+			s.logger.Warn("failed to watch orchestrator events", zap.Error(err))
+		} else {
+			for event := range events {
+				s.events.publish(event)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(monitorRetryInterval):
+		}
+	}
+}
+
+// publishEvent передает событие сервера подписчикам
+func (s *ServerService) publishEvent(serverID, eventType, message string) {
+	s.events.publish(&domain.ServerMonitorEvent{
+		ServerID:  serverID,
+		Type:      eventType,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// publishStatus передает подписчикам новый статус сервера
+func (s *ServerService) publishStatus(server *domain.Server) {
+	s.events.publish(&domain.ServerMonitorEvent{
+		ServerID:  server.ID,
+		Type:      domain.MonitorEventStatusChanged,
+		Message:   fmt.Sprintf("status changed to %s", server.Status),
+		Status:    server.Status,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,184 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Server monitoring", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var mockServerRepo *MockServerRepository
+	var mockOrchestrator *MockOrchestrator
+
+	// Поток событий оркестратора и контекст подписки на него
+	var orchestratorEvents chan *domain.ServerMonitorEvent
+	var watchCtx context.Context
+
+	// Статус, который возвращает оркестратор
+	var mu sync.Mutex
+	var status domain.ServerStatus
+
+	server := &domain.Server{
+		ID:                 "server-1",
+		Name:               "vpn-eu-1",
+		Type:               domain.ServerTypeVPN,
+		Status:             domain.ServerStatusRunning,
+		OrchestratorHandle: "container-1",
+	}
+
+	hasType := func(eventType string) OmegaMatcher {
+		return HaveField("Type", eventType)
+	}
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockOrchestrator = NewMockOrchestrator(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+
+		orchestratorEvents = make(chan *domain.ServerMonitorEvent)
+		status = domain.ServerStatusRunning
+
+		mockServerRepo.EXPECT().GetByID(gomock.Any(), server.ID).
+			DoAndReturn(func(_ context.Context, _ string) (*domain.Server, error) {
+				copied := *server
+				return &copied, nil
+			}).AnyTimes()
+		mockServerRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("server not found")).AnyTimes()
+		mockOrchestrator.EXPECT().GetServerStats(gomock.Any(), server.OrchestratorHandle).
+			Return(&domain.ServerStats{CPUUsage: 42, Timestamp: time.Now()}, nil).AnyTimes()
+		mockOrchestrator.EXPECT().GetServerHealth(gomock.Any(), server.OrchestratorHandle).
+			DoAndReturn(func(_ context.Context, _ string) (*domain.ServerHealth, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return &domain.ServerHealth{Status: status, Message: "container is " + string(status)}, nil
+			}).AnyTimes()
+	})
+
+	expectWatch := func() {
+		mockOrchestrator.EXPECT().WatchEvents(gomock.Any()).
+			DoAndReturn(func(ctx context.Context) (<-chan *domain.ServerMonitorEvent, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				watchCtx = ctx
+				return orchestratorEvents, nil
+			}).Times(1)
+	}
+
+	watchDone := func() <-chan struct{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return watchCtx.Done()
+	}
+
+	It("streams stats and lifecycle events to every subscriber", func() {
+		expectWatch()
+		mockOrchestrator.EXPECT().ScaleServer(gomock.Any(), server.OrchestratorHandle, int32(3)).Return(nil)
+
+		firstCtx, cancelFirst := context.WithCancel(ctx)
+		defer cancelFirst()
+		secondCtx, cancelSecond := context.WithCancel(ctx)
+		defer cancelSecond()
+
+		first, err := serverService.MonitorServer(firstCtx, server.ID, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := serverService.MonitorServer(secondCtx, server.ID, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		var event *domain.ServerMonitorEvent
+		Eventually(first).Should(Receive(&event))
+		Expect(event.Type).To(Equal(domain.MonitorEventStats))
+		Expect(event.Stats.CPUUsage).To(Equal(42.0))
+		Expect(event.Health.Status).To(Equal(domain.ServerStatusRunning))
+		Eventually(second).Should(Receive(hasType(domain.MonitorEventStats)))
+
+		// Одна подписка на оркестратор обслуживает всех подписчиков
+		orchestratorEvents <- &domain.ServerMonitorEvent{
+			ServerID: server.ID,
+			Type:     domain.MonitorEventOOMKilled,
+			Message:  "container killed: out of memory",
+		}
+		orchestratorEvents <- &domain.ServerMonitorEvent{ServerID: "server-2", Type: domain.MonitorEventRestarted}
+		Eventually(first).Should(Receive(hasType(domain.MonitorEventOOMKilled)))
+		Eventually(second).Should(Receive(hasType(domain.MonitorEventOOMKilled)))
+
+		Expect(serverService.ScaleServer(ctx, server.ID, 3)).To(Succeed())
+		Eventually(first).Should(Receive(And(hasType(domain.MonitorEventScaled), HaveField("Message", "scaled to 3 replicas"))))
+		Eventually(second).Should(Receive(hasType(domain.MonitorEventScaled)))
+		Consistently(second, 200*time.Millisecond).ShouldNot(Receive(hasType(domain.MonitorEventRestarted)))
+
+		// Отключение одного подписчика не влияет на остальных
+		cancelFirst()
+		Eventually(first).Should(BeClosed())
+		Consistently(watchDone(), 200*time.Millisecond).ShouldNot(BeClosed())
+
+		// С последним подписчиком останавливается подписка на оркестратор
+		cancelSecond()
+		Eventually(second).Should(BeClosed())
+		Eventually(watchDone()).Should(BeClosed())
+	})
+
+	It("reports status changes and health check failures once", func() {
+		expectWatch()
+
+		monitorCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := serverService.MonitorServer(monitorCtx, server.ID, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(events).Should(Receive(hasType(domain.MonitorEventStats)))
+
+		// Следующий опрос через секунду увидит ошибку
+		mu.Lock()
+		status = domain.ServerStatusError
+		mu.Unlock()
+
+		var event *domain.ServerMonitorEvent
+		Eventually(events, 3*time.Second).Should(Receive(&event, hasType(domain.MonitorEventStatusChanged)))
+		Expect(event.Status).To(Equal(domain.ServerStatusError))
+		Eventually(events).Should(Receive(And(
+			hasType(domain.MonitorEventHealthCheckFailed),
+			HaveField("Message", "container is error"),
+		)))
+		Consistently(events, 1500*time.Millisecond).ShouldNot(Receive(Or(
+			hasType(domain.MonitorEventStatusChanged),
+			hasType(domain.MonitorEventHealthCheckFailed),
+		)))
+	})
+
+	It("ends the stream when the server is deleted", func() {
+		expectWatch()
+		mockOrchestrator.EXPECT().DeleteServer(gomock.Any(), server.OrchestratorHandle).Return(nil)
+		mockServerRepo.EXPECT().Delete(gomock.Any(), server.ID).Return(nil)
+
+		events, err := serverService.MonitorServer(ctx, server.ID, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(events).Should(Receive(hasType(domain.MonitorEventStats)))
+
+		Expect(serverService.DeleteServer(ctx, server.ID)).To(Succeed())
+		Eventually(events).Should(Receive(hasType(domain.MonitorEventDeleted)))
+		Eventually(events).Should(BeClosed())
+		Eventually(watchDone()).Should(BeClosed())
+	})
+
+	It("rejects unknown servers", func() {
+		_, err := serverService.MonitorServer(ctx, "missing", time.Second)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+)
+
+// nodeOrchestrator оркестратор с реестром узлов
+func (s *ServerService) nodeOrchestrator() (ports.NodeOrchestrator, error) {
+	if s.nodeRepo == nil {
+		return nil, fmt.Errorf("node repository not initialized")
+	}
+	orchestrator, ok := s.orchestrator.(ports.NodeOrchestrator)
+	if !ok {
+		return nil, fmt.Errorf("node registry: %w", domain.ErrNotSupported)
+	}
+	return orchestrator, nil
+}
+
+// RegisterNode добавляет или обновляет узел. Узел должен отвечать на
+// проверку; состояние существующего узла сохраняется
+func (s *ServerService) RegisterNode(ctx context.Context, node *domain.Node) error {
+	orchestrator, err := s.nodeOrchestrator()
+	if err != nil {
+		return err
+	}
+	if err := validateNode(node); err != nil {
+		return err
+	}
+
+	if node.Region != "" {
+		regions, err := s.placementRegions(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list regions: %w", err)
+		}
+		if regions != nil && regions[node.Region] == nil {
+			return fmt.Errorf("unknown region: %s", node.Region)
+		}
+	}
+
+	node.Kind = domain.NodeKindDocker
+	node.State = domain.NodeStateActive
+	if existing, err := s.nodeRepo.GetNode(ctx, node.ID); err == nil {
+		node.State = existing.State
+	}
+
+	if err := orchestrator.PingNode(ctx, node); err != nil {
+		return fmt.Errorf("node %s is unreachable: %w", node.ID, err)
+	}
+	checkedAt := time.Now()
+	node.Healthy = true
+	node.Message = ""
+	node.LastCheckAt = &checkedAt
+
+	if err := s.nodeRepo.SaveNode(ctx, node); err != nil {
+		return err
+	}
+
+	s.logger.Info("node registered",
+		zap.String("node_id", node.ID),
+		zap.String("endpoint", node.Endpoint),
+		zap.String("region", node.Region))
+	return nil
+}
+
+// ListNodes получает узлы реестра
+func (s *ServerService) ListNodes(ctx context.Context) ([]*domain.Node, error) {
+	if s.nodeRepo == nil {
+		return []*domain.Node{}, nil
+	}
+	return s.nodeRepo.ListNodes(ctx)
+}
+
+// GetNode получает узел по ID
+func (s *ServerService) GetNode(ctx context.Context, id string) (*domain.Node, error) {
+	if s.nodeRepo == nil {
+		return nil, fmt.Errorf("node repository not initialized")
+	}
+	return s.nodeRepo.GetNode(ctx, id)
+}
+
+// RemoveNode удаляет узел, на котором не осталось серверов
+func (s *ServerService) RemoveNode(ctx context.Context, id string) error {
+	if s.nodeRepo == nil {
+		return fmt.Errorf("node repository not initialized")
+	}
+
+	servers, err := s.serverRepo.List(ctx, map[string]interface{}{"node_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to get node servers: %w", err)
+	}
+	if len(servers) > 0 {
+		return fmt.Errorf("node %s has %d servers", id, len(servers))
+	}
+
+	return s.nodeRepo.DeleteNode(ctx, id)
+}
+
+// CordonNode запрещает размещать на узле новые серверы
+func (s *ServerService) CordonNode(ctx context.Context, id string) error {
+	if s.nodeRepo == nil {
+		return fmt.Errorf("node repository not initialized")
+	}
+	return s.nodeRepo.UpdateNodeState(ctx, id, domain.NodeStateCordoned)
+}
+
+// UncordonNode снова разрешает размещать на узле новые серверы
+func (s *ServerService) UncordonNode(ctx context.Context, id string) error {
+	if s.nodeRepo == nil {
+		return fmt.Errorf("node repository not initialized")
+	}
+	return s.nodeRepo.UpdateNodeState(ctx, id, domain.NodeStateActive)
+}
+
+// DrainNode переносит серверы узла на другие узлы. Пока перенос не
+// завершен, узел остается draining и DrainNode можно повторить; после
+// переноса всех серверов узел становится cordoned
+func (s *ServerService) DrainNode(ctx context.Context, id string) error {
+	orchestrator, err := s.nodeOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	if err := s.nodeRepo.UpdateNodeState(ctx, id, domain.NodeStateDraining); err != nil {
+		return err
+	}
+
+	servers, err := s.serverRepo.List(ctx, map[string]interface{}{"node_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to get node servers: %w", err)
+	}
+
+	var errs []error
+	for _, server := range servers {
+		if server.OrchestratorHandle == "" {
+			continue
+		}
+		if err := s.moveServer(ctx, orchestrator, server.ID, id); err != nil {
+			errs = append(errs, fmt.Errorf("server %s: %w", server.ID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if err := s.nodeRepo.UpdateNodeState(ctx, id, domain.NodeStateCordoned); err != nil {
+		return err
+	}
+
+	s.logger.Info("node drained", zap.String("node_id", id), zap.Int("servers", len(servers)))
+	return nil
+}
+
+// moveServer переносит сервер с узла nodeID и записывает новый handle и
+// узел. На время переноса сервер отмечен операцией move, поэтому другие
+// операции над ним отклоняются, а остальные серверы не ждут копирования
+func (s *ServerService) moveServer(ctx context.Context, orchestrator ports.NodeOrchestrator, serverID, nodeID string) error {
+	server, done, err := s.beginMove(ctx, serverID, nodeID)
+	if err != nil || server == nil {
+		return err
+	}
+	defer done()
+
+	handle, targetID, err := orchestrator.MoveServer(ctx, server.OrchestratorHandle, server.NodeSelector)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Другие операции над сервером отклонялись, но строку могли изменить
+	// вне их, например при переименовании
+	if server, err = s.serverRepo.GetByID(ctx, serverID); err != nil {
+		return fmt.Errorf("failed to get server: %w", err)
+	}
+	server.OrchestratorHandle = handle
+	server.NodeID = targetID
+	if err := s.serverRepo.Update(ctx, server); err != nil {
+		return fmt.Errorf("failed to update server: %w", err)
+	}
+
+	s.publishEvent(server.ID, domain.MonitorEventMoved, fmt.Sprintf("server moved from node %s to node %s", nodeID, targetID))
+	s.logger.Info("server moved",
+		zap.String("server_id", server.ID),
+		zap.String("from_node", nodeID),
+		zap.String("to_node", targetID))
+	return nil
+}
+
+// beginMove отмечает перенос сервера с узла nodeID. Nil сервер - сервер
+// уже перенесен или удален после получения списка
+func (s *ServerService) beginMove(ctx context.Context, serverID, nodeID string) (*domain.Server, func(), error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	server, err := s.serverRepo.GetByID(ctx, serverID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if server.NodeID != nodeID || server.OrchestratorHandle == "" {
+		return nil, nil, nil
+	}
+	if server.Status == domain.ServerStatusUpdating || s.updateInProgress(server.ID) {
+		return nil, nil, fmt.Errorf("server is updating")
+	}
+
+	done, err := s.beginOperation(server.ID, "move")
+	if err != nil {
+		return nil, nil, err
+	}
+	return server, done, nil
+}
+
+// CheckNodes проверяет доступность всех узлов и записывает результат.
+// Недоступный узел не получает новых серверов, пока проверка не пройдет
+func (s *ServerService) CheckNodes(ctx context.Context) error {
+	orchestrator, err := s.nodeOrchestrator()
+	if err != nil {
+		return err
+	}
+
+	nodes, err := s.nodeRepo.ListNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var errs []error
+	for _, node := range nodes {
+		// Хосты, подготовленные по SSH, проверяет мониторинг их серверов
+		if !node.Docker() {
+			continue
+		}
+		healthy, message := true, ""
+		if err := orchestrator.PingNode(ctx, node); err != nil {
+			healthy, message = false, err.Error()
+		}
+
+		if healthy != node.Healthy {
+			if healthy {
+				s.logger.Info("node recovered", zap.String("node_id", node.ID))
+			} else {
+				s.logger.Warn("node is unreachable", zap.String("node_id", node.ID), zap.String("error", message))
+			}
+		}
+
+		if err := s.nodeRepo.UpdateNodeHealth(ctx, node.ID, healthy, message, time.Now()); err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", node.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateNode проверяет параметры узла
+func validateNode(node *domain.Node) error {
+	switch {
+	case node.ID == "":
+		return fmt.Errorf("node id is required")
+	case strings.Contains(node.ID, "/"):
+		return fmt.Errorf("node id must not contain '/'")
+	case node.Endpoint == "":
+		return fmt.Errorf("node endpoint is required")
+	case node.Capacity < 0:
+		return fmt.Errorf("node capacity must not be negative")
+	}
+	return nil
+}
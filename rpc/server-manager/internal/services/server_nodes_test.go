@@ -0,0 +1,180 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Nodes", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var ctrl *gomock.Controller
+	var mockServerRepo *MockServerRepository
+	var mockNodeRepo *MockNodeRepository
+	var mockOrchestrator *MockNodeOrchestrator
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockNodeRepo = NewMockNodeRepository(ctrl)
+		mockOrchestrator = NewMockNodeOrchestrator(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			NodeRepo:     mockNodeRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+	})
+
+	Describe("RegisterNode", func() {
+		It("checks the node before saving it", func() {
+			node := &domain.Node{ID: "eu-node-1", Endpoint: "tcp://10.0.0.5:2376", Region: "eu-west-1"}
+			mockNodeRepo.EXPECT().GetNode(gomock.Any(), "eu-node-1").Return(nil, fmt.Errorf("node not found: eu-node-1"))
+			mockOrchestrator.EXPECT().PingNode(gomock.Any(), node).Return(nil)
+			mockNodeRepo.EXPECT().SaveNode(gomock.Any(), node).Return(nil)
+
+			Expect(serverService.RegisterNode(ctx, node)).To(Succeed())
+			Expect(node.State).To(Equal(domain.NodeStateActive))
+			Expect(node.Healthy).To(BeTrue())
+			Expect(node.LastCheckAt).NotTo(BeNil())
+		})
+
+		It("keeps the state of a registered node", func() {
+			node := &domain.Node{ID: "eu-node-1", Endpoint: "tcp://10.0.0.6:2376"}
+			mockNodeRepo.EXPECT().GetNode(gomock.Any(), "eu-node-1").
+				Return(&domain.Node{ID: "eu-node-1", State: domain.NodeStateCordoned}, nil)
+			mockOrchestrator.EXPECT().PingNode(gomock.Any(), node).Return(nil)
+			mockNodeRepo.EXPECT().SaveNode(gomock.Any(), node).Return(nil)
+
+			Expect(serverService.RegisterNode(ctx, node)).To(Succeed())
+			Expect(node.State).To(Equal(domain.NodeStateCordoned))
+		})
+
+		It("rejects invalid and unreachable nodes", func() {
+			Expect(serverService.RegisterNode(ctx, &domain.Node{ID: "eu/1", Endpoint: "tcp://10.0.0.5:2376"})).
+				To(MatchError("node id must not contain '/'"))
+			Expect(serverService.RegisterNode(ctx, &domain.Node{ID: "eu-node-1"})).
+				To(MatchError("node endpoint is required"))
+
+			node := &domain.Node{ID: "eu-node-1", Endpoint: "tcp://10.0.0.5:2376"}
+			mockNodeRepo.EXPECT().GetNode(gomock.Any(), "eu-node-1").Return(nil, fmt.Errorf("node not found: eu-node-1"))
+			mockOrchestrator.EXPECT().PingNode(gomock.Any(), node).Return(fmt.Errorf("connection refused"))
+
+			Expect(serverService.RegisterNode(ctx, node)).
+				To(MatchError("node eu-node-1 is unreachable: connection refused"))
+		})
+
+		It("requires an orchestrator with nodes", func() {
+			service := services.NewServerService(services.ServerServiceOptions{
+				ServerRepo:   mockServerRepo,
+				NodeRepo:     mockNodeRepo,
+				Orchestrator: NewMockOrchestrator(ctrl),
+				Logger:       zap.NewNop(),
+			})
+
+			err := service.RegisterNode(ctx, &domain.Node{ID: "eu-node-1", Endpoint: "tcp://10.0.0.5:2376"})
+			Expect(err).To(MatchError(domain.ErrNotSupported))
+		})
+	})
+
+	Describe("RemoveNode", func() {
+		It("keeps nodes that still have servers", func() {
+			mockServerRepo.EXPECT().List(gomock.Any(), map[string]interface{}{"node_id": "eu-node-1"}).
+				Return([]*domain.Server{{ID: "vpn-1", NodeID: "eu-node-1"}}, nil)
+
+			Expect(serverService.RemoveNode(ctx, "eu-node-1")).To(MatchError("node eu-node-1 has 1 servers"))
+		})
+	})
+
+	Describe("DrainNode", func() {
+		var servers map[string]*domain.Server
+
+		BeforeEach(func() {
+			servers = map[string]*domain.Server{
+				"vpn-1": {ID: "vpn-1", NodeID: "eu-node-1", OrchestratorHandle: "eu-node-1/c1", Status: domain.ServerStatusRunning,
+					NodeSelector: map[string]string{"zone": "a"}},
+				"vpn-2": {ID: "vpn-2", NodeID: "eu-node-1", OrchestratorHandle: "eu-node-1/c2", Status: domain.ServerStatusStopped},
+			}
+			mockServerRepo.EXPECT().List(gomock.Any(), map[string]interface{}{"node_id": "eu-node-1"}).
+				Return([]*domain.Server{servers["vpn-1"], servers["vpn-2"]}, nil)
+			mockServerRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, id string) (*domain.Server, error) {
+					server := *servers[id]
+					return &server, nil
+				}).AnyTimes()
+			mockNodeRepo.EXPECT().UpdateNodeState(gomock.Any(), "eu-node-1", domain.NodeStateDraining).Return(nil)
+		})
+
+		It("moves servers with their node selectors and cordons the node", func() {
+			mockOrchestrator.EXPECT().MoveServer(gomock.Any(), "eu-node-1/c1", map[string]string{"zone": "a"}).
+				Return("eu-node-2/c7", "eu-node-2", nil)
+			mockOrchestrator.EXPECT().MoveServer(gomock.Any(), "eu-node-1/c2", gomock.Nil()).Return("eu-node-3/c1", "eu-node-3", nil)
+			var moved []*domain.Server
+			mockServerRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, server *domain.Server) error {
+					moved = append(moved, server)
+					return nil
+				}).Times(2)
+			mockNodeRepo.EXPECT().UpdateNodeState(gomock.Any(), "eu-node-1", domain.NodeStateCordoned).Return(nil)
+
+			Expect(serverService.DrainNode(ctx, "eu-node-1")).To(Succeed())
+			Expect(moved).To(HaveLen(2))
+			Expect(moved[0].NodeID).To(Equal("eu-node-2"))
+			Expect(moved[0].OrchestratorHandle).To(Equal("eu-node-2/c7"))
+			Expect(moved[1].NodeID).To(Equal("eu-node-3"))
+		})
+
+		It("locks only the moving server while it is copied", func() {
+			mockOrchestrator.EXPECT().MoveServer(gomock.Any(), "eu-node-1/c1", gomock.Any()).DoAndReturn(
+				func(ctx context.Context, _ string, _ map[string]string) (string, string, error) {
+					// Операции над другими серверами не ждут переноса
+					Expect(serverService.StartServer(ctx, "vpn-2")).To(Succeed())
+					Expect(serverService.StopServer(ctx, "vpn-1")).
+						To(MatchError("server vpn-1 is busy: move in progress"))
+					return "eu-node-2/c7", "eu-node-2", nil
+				})
+			mockOrchestrator.EXPECT().StartServer(gomock.Any(), "eu-node-1/c2").Return(nil)
+			mockOrchestrator.EXPECT().MoveServer(gomock.Any(), "eu-node-1/c2", gomock.Any()).Return("eu-node-3/c1", "eu-node-3", nil)
+			mockServerRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+			mockNodeRepo.EXPECT().UpdateNodeState(gomock.Any(), "eu-node-1", domain.NodeStateCordoned).Return(nil)
+
+			Expect(serverService.DrainNode(ctx, "eu-node-1")).To(Succeed())
+		})
+
+		It("leaves the node draining when a server cannot be moved", func() {
+			mockOrchestrator.EXPECT().MoveServer(gomock.Any(), "eu-node-1/c1", gomock.Any()).Return("eu-node-2/c7", "eu-node-2", nil)
+			mockOrchestrator.EXPECT().MoveServer(gomock.Any(), "eu-node-1/c2", gomock.Any()).
+				Return("", "", fmt.Errorf("%w: no schedulable node for region eu-west-1", domain.ErrNoCapacity))
+			mockServerRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+			err := serverService.DrainNode(ctx, "eu-node-1")
+			Expect(err).To(MatchError(domain.ErrNoCapacity))
+			Expect(err.Error()).To(HavePrefix("server vpn-2:"))
+		})
+	})
+
+	Describe("CheckNodes", func() {
+		It("records the result of each check", func() {
+			healthy := &domain.Node{ID: "eu-node-1", Healthy: true}
+			lost := &domain.Node{ID: "eu-node-2", Healthy: true}
+			// Хост без Docker API не проверяется
+			host := &domain.Node{ID: "203.0.113.10", Kind: domain.NodeKindSSH, Healthy: true}
+			mockNodeRepo.EXPECT().ListNodes(gomock.Any()).Return([]*domain.Node{healthy, lost, host}, nil)
+			mockOrchestrator.EXPECT().PingNode(gomock.Any(), healthy).Return(nil)
+			mockOrchestrator.EXPECT().PingNode(gomock.Any(), lost).Return(fmt.Errorf("connection refused"))
+			mockNodeRepo.EXPECT().UpdateNodeHealth(gomock.Any(), "eu-node-1", true, "", gomock.Any()).Return(nil)
+			mockNodeRepo.EXPECT().UpdateNodeHealth(gomock.Any(), "eu-node-2", false, "connection refused", gomock.Any()).Return(nil)
+
+			Expect(serverService.CheckNodes(ctx)).To(Succeed())
+		})
+	})
+})
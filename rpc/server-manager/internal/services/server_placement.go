@@ -0,0 +1,395 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+// placementKmPerMs расстояние, км, на которое задержка до региона растет на
+// миллисекунду: свет в оптоволокне проходит около 200 км за миллисекунду,
+// туда и обратно - 100 км
+const placementKmPerMs = 100.0
+
+// placementCountryPenalty оценка задержки, ms, до региона в другой стране,
+// когда координаты клиента неизвестны
+const placementCountryPenalty = 50.0
+
+// earthRadiusKm средний радиус Земли
+const earthRadiusKm = 6371.0
+
+// ListRegions получает регионы размещения
+func (s *ServerService) ListRegions(ctx context.Context) ([]*domain.Region, error) {
+	if s.regionRepo == nil {
+		return []*domain.Region{}, nil
+	}
+	return s.regionRepo.ListRegions(ctx)
+}
+
+// GetRegion получает регион по коду
+func (s *ServerService) GetRegion(ctx context.Context, code string) (*domain.Region, error) {
+	if s.regionRepo == nil {
+		return nil, fmt.Errorf("region repository not initialized")
+	}
+	return s.regionRepo.GetRegion(ctx, code)
+}
+
+// SaveRegion создает или обновляет регион
+func (s *ServerService) SaveRegion(ctx context.Context, region *domain.Region) error {
+	if s.regionRepo == nil {
+		return fmt.Errorf("region repository not initialized")
+	}
+	if err := validateRegion(region); err != nil {
+		return err
+	}
+	if region.Name == "" {
+		region.Name = region.Code
+	}
+	region.Country = strings.ToUpper(region.Country)
+	return s.regionRepo.SaveRegion(ctx, region)
+}
+
+// DeleteRegion удаляет регион, в котором не осталось серверов
+func (s *ServerService) DeleteRegion(ctx context.Context, code string) error {
+	if s.regionRepo == nil {
+		return fmt.Errorf("region repository not initialized")
+	}
+
+	servers, err := s.serverRepo.GetByRegion(ctx, code)
+	if err != nil {
+		return fmt.Errorf("failed to get region servers: %w", err)
+	}
+	if len(servers) > 0 {
+		return fmt.Errorf("region %s has %d servers", code, len(servers))
+	}
+
+	return s.regionRepo.DeleteRegion(ctx, code)
+}
+
+// EnsureRegions создает отсутствующие регионы из списка кодов. Созданные
+// регионы включены и не имеют ограничений и расположения
+func (s *ServerService) EnsureRegions(ctx context.Context, codes []string) error {
+	if s.regionRepo == nil {
+		return nil
+	}
+
+	regions, err := s.regionRepo.ListRegions(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		existing[region.Code] = true
+	}
+
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" || existing[code] {
+			continue
+		}
+		if err := s.regionRepo.SaveRegion(ctx, &domain.Region{Code: code, Name: code, Enabled: true}); err != nil {
+			return err
+		}
+		existing[code] = true
+	}
+
+	return nil
+}
+
+// RecommendServer выбирает для пользователя работающий сервер. Серверы с
+// загрузкой выше предела региона и серверы отключенных регионов не
+// рассматриваются; из остальных выбирается сервер с наименьшей суммой
+// оценки задержки, ms, и загрузки, %. Загрузкой считается наиболее занятый
+// из ресурсов CPU и памяти. Предпочтительный регион учитывается, если в нем
+// есть подходящие серверы
+func (s *ServerService) RecommendServer(ctx context.Context, req *domain.PlacementRequest) (*domain.Placement, error) {
+	serverType := req.ServerType
+	if serverType == "" {
+		serverType = domain.ServerTypeVPN
+	}
+
+	regions, err := s.placementRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := s.serverRepo.List(ctx, map[string]interface{}{
+		"type":   serverType,
+		"status": domain.ServerStatusRunning,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	loads := s.serverLoads(ctx, servers)
+	candidates := []*domain.Placement{}
+	for _, server := range servers {
+		region := placementRegion(regions, server.Region)
+		if region == nil {
+			continue
+		}
+		load := loads[server.ID]
+		if load >= region.LoadLimit() {
+			continue
+		}
+
+		placement := &domain.Placement{Server: server, Region: region, Load: load}
+		estimateLatency(req, placement)
+		candidates = append(candidates, placement)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: no %s servers available", domain.ErrNoCapacity, serverType)
+	}
+
+	preferred := false
+	if req.Region != "" {
+		inRegion := []*domain.Placement{}
+		for _, candidate := range candidates {
+			if candidate.Region.Code == req.Region {
+				inRegion = append(inRegion, candidate)
+			}
+		}
+		if len(inRegion) > 0 {
+			candidates, preferred = inRegion, true
+		}
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if betterPlacement(candidate, best) {
+			best = candidate
+		}
+	}
+	best.Reason = placementReason(req, best, preferred)
+
+	s.logger.Info("server recommended",
+		zap.String("server_id", best.Server.ID),
+		zap.String("region", best.Region.Code),
+		zap.String("reason", best.Reason))
+
+	return best, nil
+}
+
+// selectRegion выбирает регион для нового сервера: включенный регион со
+// свободным местом и наименьшей средней загрузкой серверов типа
+// serverType. Пустой результат - регионы не настроены
+func (s *ServerService) selectRegion(ctx context.Context, serverType domain.ServerType) (string, error) {
+	regions, err := s.placementRegions(ctx)
+	if err != nil || regions == nil {
+		return "", err
+	}
+
+	regionServers := map[string][]*domain.Server{}
+	running := []*domain.Server{}
+	for _, region := range regions {
+		if !region.Enabled {
+			continue
+		}
+		servers, err := s.serverRepo.GetByRegion(ctx, region.Code)
+		if err != nil {
+			return "", fmt.Errorf("failed to get region servers: %w", err)
+		}
+		regionServers[region.Code] = servers
+		for _, server := range servers {
+			if server.Type == serverType && server.Status == domain.ServerStatusRunning {
+				running = append(running, server)
+			}
+		}
+	}
+	loads := s.serverLoads(ctx, running)
+
+	var selected string
+	var selectedLoad float64
+	var selectedServers int
+	for _, region := range regions {
+		servers, ok := regionServers[region.Code]
+		if !ok || (region.MaxServers > 0 && len(servers) >= region.MaxServers) {
+			continue
+		}
+
+		var load float64
+		var count int
+		for _, server := range servers {
+			if server.Type != serverType || server.Status != domain.ServerStatusRunning {
+				continue
+			}
+			load += loads[server.ID]
+			count++
+		}
+		if count > 0 {
+			load /= float64(count)
+		}
+
+		better := selected == "" || load < selectedLoad ||
+			(load == selectedLoad && (len(servers) < selectedServers ||
+				(len(servers) == selectedServers && region.Code < selected)))
+		if better {
+			selected, selectedLoad, selectedServers = region.Code, load, len(servers)
+		}
+	}
+
+	if selected == "" {
+		return "", fmt.Errorf("%w: all regions are disabled or full", domain.ErrNoCapacity)
+	}
+	return selected, nil
+}
+
+// checkRegion проверяет, что регион принимает новые серверы. Без
+// настроенных регионов подходит любой
+func (s *ServerService) checkRegion(ctx context.Context, code string) error {
+	regions, err := s.placementRegions(ctx)
+	if err != nil || regions == nil {
+		return err
+	}
+
+	region, ok := regions[code]
+	if !ok {
+		return fmt.Errorf("unknown region: %s", code)
+	}
+	if !region.Enabled {
+		return fmt.Errorf("region %s is disabled", code)
+	}
+	if region.MaxServers > 0 {
+		servers, err := s.serverRepo.GetByRegion(ctx, code)
+		if err != nil {
+			return fmt.Errorf("failed to get region servers: %w", err)
+		}
+		if len(servers) >= region.MaxServers {
+			return fmt.Errorf("%w: region %s has reached %d servers", domain.ErrNoCapacity, code, region.MaxServers)
+		}
+	}
+	return nil
+}
+
+// placementRegions регионы по коду; nil - регионы не настроены
+func (s *ServerService) placementRegions(ctx context.Context) (map[string]*domain.Region, error) {
+	if s.regionRepo == nil {
+		return nil, nil
+	}
+
+	regions, err := s.regionRepo.ListRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(regions) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]*domain.Region, len(regions))
+	for _, region := range regions {
+		result[region.Code] = region
+	}
+	return result, nil
+}
+
+// placementRegion регион сервера для размещения, nil - регион не
+// принимает пользователей. Без настроенных регионов регион сервера
+// считается включенным и без ограничений
+func placementRegion(regions map[string]*domain.Region, code string) *domain.Region {
+	if regions == nil {
+		return &domain.Region{Code: code, Name: code, Enabled: true}
+	}
+	region, ok := regions[code]
+	if !ok || !region.Enabled {
+		return nil
+	}
+	return region
+}
+
+// serverLoads последняя сохраненная загрузка серверов, %: наибольшая из
+// загрузок CPU и памяти. Серверы без истории, например только что
+// созданные, считаются свободными
+func (s *ServerService) serverLoads(ctx context.Context, servers []*domain.Server) map[string]float64 {
+	loads := make(map[string]float64, len(servers))
+	if s.statsRepo == nil || len(servers) == 0 {
+		return loads
+	}
+
+	ids := make([]string, len(servers))
+	for i, server := range servers {
+		ids[i] = server.ID
+	}
+	latest, err := s.statsRepo.GetLatestStatsBatch(ctx, ids)
+	if err != nil {
+		s.logger.Warn("failed to get stats for placement", zap.Error(err))
+		return loads
+	}
+	for id, stats := range latest {
+		loads[id] = math.Max(stats.CPUUsage, stats.MemoryUsage)
+	}
+	return loads
+}
+
+// estimateLatency оценивает расстояние и задержку до региона по
+// координатам клиента или, без них, по стране
+func estimateLatency(req *domain.PlacementRequest, placement *domain.Placement) {
+	region := placement.Region
+	switch {
+	case req.HasLocation && region.HasLocation():
+		placement.DistanceKm = haversineKm(req.Latitude, req.Longitude, region.Latitude, region.Longitude)
+		placement.LatencyMs = placement.DistanceKm / placementKmPerMs
+	case req.Country != "" && !strings.EqualFold(region.Country, req.Country):
+		placement.LatencyMs = placementCountryPenalty
+	}
+}
+
+// betterPlacement лучше ли размещение a размещения b
+func betterPlacement(a, b *domain.Placement) bool {
+	scoreA, scoreB := a.LatencyMs+a.Load, b.LatencyMs+b.Load
+	if scoreA != scoreB {
+		return scoreA < scoreB
+	}
+	if a.Load != b.Load {
+		return a.Load < b.Load
+	}
+	return a.Server.ID < b.Server.ID
+}
+
+// placementReason объяснение выбора сервера
+func placementReason(req *domain.PlacementRequest, placement *domain.Placement, preferred bool) string {
+	region := placement.Region
+	var reason string
+	switch {
+	case preferred:
+		reason = fmt.Sprintf("preferred region %s", region.Code)
+	case req.HasLocation && region.HasLocation():
+		reason = fmt.Sprintf("region %s at %.0f km", region.Code, placement.DistanceKm)
+	case req.Country != "" && strings.EqualFold(region.Country, req.Country):
+		reason = fmt.Sprintf("region %s in client country %s", region.Code, region.Country)
+	default:
+		reason = fmt.Sprintf("least loaded region %s", region.Code)
+	}
+	return fmt.Sprintf("%s, server load %.0f%%", reason, placement.Load)
+}
+
+// haversineKm расстояние по поверхности Земли между двумя точками
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// validateRegion проверяет параметры региона
+func validateRegion(region *domain.Region) error {
+	switch {
+	case region.Code == "":
+		return fmt.Errorf("region code is required")
+	case region.Country != "" && len(region.Country) != 2:
+		return fmt.Errorf("country must be an ISO 3166-1 alpha-2 code: %s", region.Country)
+	case region.Latitude < -90 || region.Latitude > 90:
+		return fmt.Errorf("latitude must be between -90 and 90")
+	case region.Longitude < -180 || region.Longitude > 180:
+		return fmt.Errorf("longitude must be between -180 and 180")
+	case region.MaxServers < 0:
+		return fmt.Errorf("max servers must not be negative")
+	case region.MaxLoad < 0 || region.MaxLoad > 100:
+		return fmt.Errorf("max load must be between 0 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Placement", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var mockServerRepo *MockServerRepository
+	var mockStatsRepo *MockStatsRepository
+	var mockRegionRepo *MockRegionRepository
+	var mockOrchestrator *MockOrchestrator
+	var regions []*domain.Region
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockStatsRepo = NewMockStatsRepository(ctrl)
+		mockRegionRepo = NewMockRegionRepository(ctrl)
+		mockOrchestrator = NewMockOrchestrator(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			StatsRepo:    mockStatsRepo,
+			RegionRepo:   mockRegionRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+
+		regions = []*domain.Region{
+			{Code: "eu-west-1", Name: "Dublin", Country: "IE", Latitude: 53.35, Longitude: -6.26, Enabled: true},
+			{Code: "us-east-1", Name: "Virginia", Country: "US", Latitude: 38.9, Longitude: -77.04, MaxLoad: 60, Enabled: true},
+			{Code: "ap-south-1", Name: "Mumbai", Country: "IN", Latitude: 19.08, Longitude: 72.88, Enabled: false},
+		}
+		mockRegionRepo.EXPECT().ListRegions(gomock.Any()).DoAndReturn(
+			func(_ context.Context) ([]*domain.Region, error) {
+				return regions, nil
+			}).AnyTimes()
+	})
+
+	// expectStats настраивает работающие серверы и их последние замеры,
+	// которые читаются одним запросом
+	expectStats := func(stats map[string]*domain.ServerStats, servers ...*domain.Server) {
+		mockServerRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(servers, nil).AnyTimes()
+		mockStatsRepo.EXPECT().GetLatestStatsBatch(gomock.Any(), gomock.Any()).Return(stats, nil).AnyTimes()
+	}
+
+	// expectServers настраивает работающие серверы с загрузкой CPU
+	expectServers := func(loads map[string]float64, servers ...*domain.Server) {
+		stats := map[string]*domain.ServerStats{}
+		for id, load := range loads {
+			stats[id] = &domain.ServerStats{ServerID: id, CPUUsage: load}
+		}
+		expectStats(stats, servers...)
+	}
+
+	newServer := func(id, region string) *domain.Server {
+		return &domain.Server{ID: id, Type: domain.ServerTypeVPN, Status: domain.ServerStatusRunning, Region: region}
+	}
+
+	Describe("RecommendServer", func() {
+		It("prefers the nearest region", func() {
+			expectServers(map[string]float64{"eu": 40, "us": 10},
+				newServer("eu", "eu-west-1"), newServer("us", "us-east-1"))
+
+			// Лондон: до Дублина около 460 км, до Вирджинии около 5900 км
+			placement, err := serverService.RecommendServer(ctx, &domain.PlacementRequest{
+				Latitude: 51.5, Longitude: -0.13, HasLocation: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placement.Server.ID).To(Equal("eu"))
+			Expect(placement.DistanceKm).To(BeNumerically("~", 460, 20))
+			Expect(placement.LatencyMs).To(BeNumerically("~", 4.6, 0.2))
+			Expect(placement.Reason).To(Equal("region eu-west-1 at 464 km, server load 40%"))
+		})
+
+		It("skips overloaded servers and disabled regions", func() {
+			expectServers(map[string]float64{"eu": 85, "us-busy": 65, "us": 30, "ap": 0},
+				newServer("eu", "eu-west-1"), newServer("us-busy", "us-east-1"),
+				newServer("us", "us-east-1"), newServer("ap", "ap-south-1"))
+
+			placement, err := serverService.RecommendServer(ctx, &domain.PlacementRequest{
+				Latitude: 19, Longitude: 72.8, HasLocation: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placement.Server.ID).To(Equal("us"))
+			Expect(placement.Region.Code).To(Equal("us-east-1"))
+		})
+
+		It("counts memory as load", func() {
+			expectStats(map[string]*domain.ServerStats{
+				"eu-1": {ServerID: "eu-1", CPUUsage: 10, MemoryUsage: 90},
+				"eu-2": {ServerID: "eu-2", CPUUsage: 30, MemoryUsage: 40},
+			}, newServer("eu-1", "eu-west-1"), newServer("eu-2", "eu-west-1"))
+
+			placement, err := serverService.RecommendServer(ctx, &domain.PlacementRequest{Country: "IE"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placement.Server.ID).To(Equal("eu-2"))
+			Expect(placement.Load).To(Equal(40.0))
+		})
+
+		It("treats servers as free when stats are unavailable", func() {
+			mockServerRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+				Return([]*domain.Server{newServer("eu", "eu-west-1")}, nil)
+			mockStatsRepo.EXPECT().GetLatestStatsBatch(gomock.Any(), []string{"eu"}).
+				Return(nil, fmt.Errorf("connection refused"))
+
+			placement, err := serverService.RecommendServer(ctx, &domain.PlacementRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placement.Load).To(BeZero())
+		})
+
+		It("balances load within a region", func() {
+			expectServers(map[string]float64{"eu-1": 50},
+				newServer("eu-1", "eu-west-1"), newServer("eu-2", "eu-west-1"))
+
+			placement, err := serverService.RecommendServer(ctx, &domain.PlacementRequest{Country: "ie"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placement.Server.ID).To(Equal("eu-2"))
+			Expect(placement.Reason).To(Equal("region eu-west-1 in client country IE, server load 0%"))
+		})
+
+		It("uses the preferred region while it has capacity", func() {
+			expectServers(map[string]float64{"eu": 10, "us": 50},
+				newServer("eu", "eu-west-1"), newServer("us", "us-east-1"))
+
+			placement, err := serverService.RecommendServer(ctx, &domain.PlacementRequest{Region: "us-east-1", Country: "IE"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placement.Server.ID).To(Equal("us"))
+			Expect(placement.Reason).To(HavePrefix("preferred region us-east-1"))
+
+			placement, err = serverService.RecommendServer(ctx, &domain.PlacementRequest{Region: "ap-south-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(placement.Server.ID).To(Equal("eu"))
+			Expect(placement.Reason).To(Equal("least loaded region eu-west-1, server load 10%"))
+		})
+
+		It("reports missing capacity", func() {
+			expectServers(map[string]float64{"eu": 95}, newServer("eu", "eu-west-1"))
+
+			_, err := serverService.RecommendServer(ctx, &domain.PlacementRequest{})
+			Expect(err).To(MatchError(domain.ErrNoCapacity))
+		})
+	})
+
+	Describe("CreateServer", func() {
+		expectCreate := func() {
+			mockServerRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			mockOrchestrator.EXPECT().CreateServer(gomock.Any(), gomock.Any(), gomock.Any()).Return("container-1", nil)
+			mockServerRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+		}
+
+		It("places a server without region into the least loaded region", func() {
+			mockServerRepo.EXPECT().GetByRegion(gomock.Any(), "eu-west-1").
+				Return([]*domain.Server{newServer("eu", "eu-west-1")}, nil)
+			mockServerRepo.EXPECT().GetByRegion(gomock.Any(), "us-east-1").Return([]*domain.Server{}, nil)
+			mockStatsRepo.EXPECT().GetLatestStatsBatch(gomock.Any(), []string{"eu"}).
+				Return(map[string]*domain.ServerStats{"eu": {ServerID: "eu", CPUUsage: 20}}, nil)
+			expectCreate()
+
+			server, err := serverService.CreateServer(ctx, &domain.CreateServerRequest{Name: "vpn", Type: domain.ServerTypeVPN})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.Region).To(Equal("us-east-1"))
+		})
+
+		It("rejects full, disabled and unknown regions", func() {
+			regions[0].MaxServers = 1
+			mockServerRepo.EXPECT().GetByRegion(gomock.Any(), "eu-west-1").
+				Return([]*domain.Server{newServer("eu", "eu-west-1")}, nil)
+
+			_, err := serverService.CreateServer(ctx, &domain.CreateServerRequest{Name: "vpn", Type: domain.ServerTypeVPN, Region: "eu-west-1"})
+			Expect(err).To(MatchError(domain.ErrNoCapacity))
+			_, err = serverService.CreateServer(ctx, &domain.CreateServerRequest{Name: "vpn", Type: domain.ServerTypeVPN, Region: "ap-south-1"})
+			Expect(err).To(MatchError("region ap-south-1 is disabled"))
+			_, err = serverService.CreateServer(ctx, &domain.CreateServerRequest{Name: "vpn", Type: domain.ServerTypeVPN, Region: "mars-1"})
+			Expect(err).To(MatchError("unknown region: mars-1"))
+		})
+	})
+
+	Describe("regions", func() {
+		It("creates only missing regions", func() {
+			mockRegionRepo.EXPECT().SaveRegion(gomock.Any(), &domain.Region{Code: "eu-central-1", Name: "eu-central-1", Enabled: true}).Return(nil)
+
+			Expect(serverService.EnsureRegions(ctx, []string{"eu-west-1", "eu-central-1", " ", "eu-central-1"})).To(Succeed())
+		})
+
+		It("validates regions before saving", func() {
+			Expect(serverService.SaveRegion(ctx, &domain.Region{Code: "eu", Latitude: 91})).
+				To(MatchError("latitude must be between -90 and 90"))
+
+			region := &domain.Region{Code: "eu-north-1", Country: "se", MaxLoad: 70, Enabled: true}
+			mockRegionRepo.EXPECT().SaveRegion(gomock.Any(), region).Return(nil)
+			Expect(serverService.SaveRegion(ctx, region)).To(Succeed())
+			Expect(region.Name).To(Equal("eu-north-1"))
+			Expect(region.Country).To(Equal("SE"))
+		})
+
+		It("keeps regions that still have servers", func() {
+			mockServerRepo.EXPECT().GetByRegion(gomock.Any(), "eu-west-1").
+				Return([]*domain.Server{newServer("eu", "eu-west-1")}, nil)
+
+			Expect(serverService.DeleteRegion(ctx, "eu-west-1")).To(MatchError("region eu-west-1 has 1 servers"))
+		})
+	})
+})
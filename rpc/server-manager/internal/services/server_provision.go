@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultProvisionSSHPort порт SSH подготавливаемого хоста по умолчанию
+	defaultProvisionSSHPort = 22
+	// defaultProvisionUser пользователь SSH по умолчанию
+	defaultProvisionUser = "root"
+	// defaultProvisionListenPort порт WireGuard по умолчанию
+	defaultProvisionListenPort = 51820
+	// defaultProvisionTimeout ограничение подготовки хоста по умолчанию
+	defaultProvisionTimeout = 30 * time.Minute
+)
+
+// ProvisionServer подготавливает хост VPN по SSH и регистрирует его как
+// сервер. Сервер создается в статусе creating и возвращается сразу, ход
+// подготовки доступен через GetProvision и события мониторинга. Такой
+// сервер не управляется оркестратором
+func (s *ServerService) ProvisionServer(ctx context.Context, req *domain.ProvisionRequest) (*domain.Server, error) {
+	if s.provisioner == nil {
+		return nil, fmt.Errorf("provisioner not initialized")
+	}
+	if err := validateProvisionRequest(req); err != nil {
+		return nil, err
+	}
+	req = withProvisionDefaults(req, s.getImageForServerType(domain.ServerTypeVPN))
+
+	server, err := s.createProvisionedServer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	s.saveProvision(&domain.Provision{
+		ServerID:  server.ID,
+		Host:      req.Host,
+		Mode:      req.Mode,
+		Status:    domain.ProvisionStatusInProgress,
+		Step:      domain.ProvisionStepConnect,
+		Message:   "waiting for connection",
+		StartedAt: startedAt,
+	})
+
+	s.logger.Info("starting provisioning",
+		zap.String("server_id", server.ID),
+		zap.String("host", req.Host),
+		zap.String("mode", string(req.Mode)))
+
+	// Подготовка переживает завершение запроса, который ее запустил, и
+	// отменяется по таймауту или остановкой сервиса
+	provisioned := *server
+	s.provisions.Add(1)
+	go func() {
+		defer s.provisions.Done()
+		provisionCtx, cancel := context.WithTimeout(s.background, s.provisionTimeout)
+		defer cancel()
+		s.runProvision(provisionCtx, &provisioned, req)
+	}()
+
+	return server, nil
+}
+
+// Stop отменяет фоновые подготовки хостов и ждет, пока они отметят
+// серверы ошибкой
+func (s *ServerService) Stop() {
+	s.stopBackground()
+	s.provisions.Wait()
+}
+
+// FailInterruptedProvisions отмечает ошибкой подготовки, прерванные
+// остановкой server-manager, и их серверы: иначе серверы навсегда
+// остались бы в статусе creating
+func (s *ServerService) FailInterruptedProvisions(ctx context.Context) error {
+	if s.provisionRepo == nil {
+		return nil
+	}
+
+	serverIDs, err := s.provisionRepo.FailInterruptedProvisions(ctx, "interrupted by server-manager restart")
+	if err != nil {
+		return err
+	}
+	for _, serverID := range serverIDs {
+		server, err := s.serverRepo.GetByID(ctx, serverID)
+		if err != nil {
+			s.logger.Warn("failed to get interrupted server", zap.String("server_id", serverID), zap.Error(err))
+			continue
+		}
+		if server.Status != domain.ServerStatusCreating {
+			continue
+		}
+		server.Status = domain.ServerStatusError
+		if err := s.serverRepo.Update(ctx, server); err != nil {
+			return fmt.Errorf("failed to update server: %w", err)
+		}
+		s.logger.Warn("provisioning interrupted", zap.String("server_id", serverID))
+	}
+	return nil
+}
+
+// GetProvision получает ход подготовки хоста сервера
+func (s *ServerService) GetProvision(ctx context.Context, serverID string) (*domain.Provision, error) {
+	if s.provisionRepo == nil {
+		return nil, fmt.Errorf("provision repository not initialized")
+	}
+	return s.provisionRepo.GetProvision(ctx, serverID)
+}
+
+// validateProvisionRequest проверяет запрос на подготовку хоста
+func validateProvisionRequest(req *domain.ProvisionRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if req.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if req.HostKey == "" {
+		return fmt.Errorf("host key is required")
+	}
+	switch req.Mode {
+	case "", domain.ProvisionModeBinary:
+		if req.BinaryURL == "" {
+			return fmt.Errorf("binary url is required for binary mode")
+		}
+		// Бинарный файл устанавливается как сервис root, только с известной
+		// контрольной суммой
+		if sum, err := hex.DecodeString(req.BinarySHA256); err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("binary sha256 is required for binary mode")
+		}
+	case domain.ProvisionModeContainer:
+		// Образ попадает в ExecStart unit-файла
+		if req.Image != "" {
+			if _, err := reference.ParseNormalizedNamed(req.Image); err != nil {
+				return fmt.Errorf("invalid image: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown provision mode: %s", req.Mode)
+	}
+	if req.SSHPort < 0 || req.SSHPort > 65535 {
+		return fmt.Errorf("invalid ssh port: %d", req.SSHPort)
+	}
+	if req.ListenPort < 0 || req.ListenPort > 65535 {
+		return fmt.Errorf("invalid listen port: %d", req.ListenPort)
+	}
+	// Окружение записывается в файл построчно
+	for key, value := range req.Env {
+		if key == "" || strings.ContainsAny(key, "=\n") || strings.Contains(value, "\n") {
+			return fmt.Errorf("invalid environment variable: %q", key)
+		}
+	}
+	return nil
+}
+
+// withProvisionDefaults копия запроса с заполненными значениями по умолчанию
+func withProvisionDefaults(req *domain.ProvisionRequest, image string) *domain.ProvisionRequest {
+	result := *req
+	if result.SSHPort == 0 {
+		result.SSHPort = defaultProvisionSSHPort
+	}
+	if result.User == "" {
+		result.User = defaultProvisionUser
+	}
+	if result.Mode == "" {
+		result.Mode = domain.ProvisionModeBinary
+	}
+	if result.Mode == domain.ProvisionModeContainer && result.Image == "" {
+		result.Image = image
+	}
+	if result.ListenPort == 0 {
+		result.ListenPort = defaultProvisionListenPort
+	}
+	return &result
+}
+
+// createProvisionedServer создает запись сервера подготавливаемого хоста в
+// выбранном или заданном регионе
+func (s *ServerService) createProvisionedServer(ctx context.Context, req *domain.ProvisionRequest) (*domain.Server, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	region := req.Region
+	if region == "" {
+		selected, err := s.selectRegion(ctx, domain.ServerTypeVPN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select region: %w", err)
+		}
+		region = selected
+	} else if err := s.checkRegion(ctx, region); err != nil {
+		return nil, err
+	}
+
+	server := &domain.Server{
+		Name:   req.Name,
+		Type:   domain.ServerTypeVPN,
+		Status: domain.ServerStatusCreating,
+		Region: region,
+		IP:     req.Host,
+		Port:   req.ListenPort,
+	}
+	if req.Mode == domain.ProvisionModeContainer {
+		server.Image = req.Image
+	}
+
+	if err := s.serverRepo.Create(ctx, server); err != nil {
+		return nil, fmt.Errorf("failed to create server in database: %w", err)
+	}
+	return server, nil
+}
+
+// runProvision выполняет шаги подготовки и регистрирует сервер
+func (s *ServerService) runProvision(ctx context.Context, server *domain.Server, req *domain.ProvisionRequest) {
+	err := s.provisioner.Provision(ctx, server, req, func(step domain.ProvisionStep) {
+		s.reportProvisionStep(server, req, step)
+	})
+	if err != nil {
+		s.logger.Error("provisioning failed",
+			zap.String("server_id", server.ID),
+			zap.String("host", req.Host),
+			zap.Error(err))
+
+		s.saveServer(server, serverStatus(domain.ServerStatusError))
+		s.completeProvision(server.ID, false, err.Error())
+		return
+	}
+
+	s.reportProvisionStep(server, req, domain.ProvisionStepRegister)
+	nodeID, err := s.registerProvisionedNode(ctx, server, req)
+	if err != nil {
+		s.logger.Error("failed to register provisioned node",
+			zap.String("server_id", server.ID),
+			zap.String("host", req.Host),
+			zap.Error(err))
+
+		s.saveServer(server, serverStatus(domain.ServerStatusError))
+		s.completeProvision(server.ID, false, err.Error())
+		return
+	}
+	s.saveServer(server, func(server *domain.Server) {
+		server.NodeID = nodeID
+		server.Status = domain.ServerStatusRunning
+	})
+	s.completeProvision(server.ID, true, "provisioned")
+
+	s.logger.Info("server provisioned successfully",
+		zap.String("server_id", server.ID),
+		zap.String("name", server.Name),
+		zap.String("host", req.Host))
+}
+
+// registerProvisionedNode добавляет подготовленный хост в реестр узлов и
+// возвращает ID узла сервера, пустой без реестра. Состояние уже известного
+// хоста сохраняется
+func (s *ServerService) registerProvisionedNode(ctx context.Context, server *domain.Server, req *domain.ProvisionRequest) (string, error) {
+	if s.nodeRepo == nil {
+		return "", nil
+	}
+
+	checkedAt := time.Now()
+	node := &domain.Node{
+		ID:          req.Host,
+		Kind:        domain.NodeKindSSH,
+		Endpoint:    fmt.Sprintf("ssh://%s@%s", req.User, net.JoinHostPort(req.Host, strconv.Itoa(req.SSHPort))),
+		Region:      server.Region,
+		Capacity:    1,
+		State:       domain.NodeStateActive,
+		Healthy:     true,
+		LastCheckAt: &checkedAt,
+	}
+	if existing, err := s.nodeRepo.GetNode(ctx, node.ID); err == nil {
+		if existing.Docker() {
+			return "", fmt.Errorf("node %s is a docker node", node.ID)
+		}
+		node.State = existing.State
+		node.Labels = existing.Labels
+	}
+	if err := s.nodeRepo.SaveNode(ctx, node); err != nil {
+		return "", err
+	}
+	return node.ID, nil
+}
+
+// reportProvisionStep сохраняет начатый шаг подготовки и сообщает о нем
+// подписчикам
+func (s *ServerService) reportProvisionStep(server *domain.Server, req *domain.ProvisionRequest, step domain.ProvisionStep) {
+	progress := slices.Index(domain.ProvisionSteps, step) * 100 / len(domain.ProvisionSteps)
+	message := provisionStepMessage(server, req, step)
+
+	if s.provisionRepo != nil {
+		if err := s.provisionRepo.UpdateProvisionStep(context.Background(), server.ID, step, progress, message); err != nil {
+			s.logger.Error("failed to report provision step", zap.String("server_id", server.ID), zap.Error(err))
+		}
+	}
+	s.publishEvent(server.ID, domain.MonitorEventProvisioning, message)
+}
+
+// provisionStepMessage описание шага подготовки
+func provisionStepMessage(server *domain.Server, req *domain.ProvisionRequest, step domain.ProvisionStep) string {
+	switch step {
+	case domain.ProvisionStepConnect:
+		return "connecting to " + req.Host
+	case domain.ProvisionStepPrerequisites:
+		return "installing prerequisites"
+	case domain.ProvisionStepInstall:
+		return "installing vpn-core " + string(req.Mode)
+	case domain.ProvisionStepConfigure:
+		return "writing configuration"
+	case domain.ProvisionStepStart:
+		return "starting " + server.ResourceName()
+	case domain.ProvisionStepRegister:
+		return "registering server"
+	}
+	return string(step)
+}
+
+// saveProvision сохраняет ход подготовки, если есть репозиторий
+func (s *ServerService) saveProvision(provision *domain.Provision) {
+	if s.provisionRepo == nil {
+		return
+	}
+	if err := s.provisionRepo.SaveProvision(context.Background(), provision); err != nil {
+		s.logger.Error("failed to save provision", zap.String("server_id", provision.ServerID), zap.Error(err))
+	}
+}
+
+// completeProvision завершает подготовку со статусом completed или failed
+func (s *ServerService) completeProvision(serverID string, success bool, message string) {
+	if s.provisionRepo == nil {
+		return
+	}
+	if err := s.provisionRepo.CompleteProvision(context.Background(), serverID, success, message); err != nil {
+		s.logger.Error("failed to complete provision", zap.String("server_id", serverID), zap.Error(err))
+	}
+}
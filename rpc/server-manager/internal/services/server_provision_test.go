@@ -0,0 +1,359 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Host provisioning", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var ctrl *gomock.Controller
+	var mockServerRepo *MockServerRepository
+	var mockProvisionRepo *MockProvisionRepository
+	var mockProvisioner *MockProvisioner
+
+	// Ход подготовки, шаги с прогрессом, строки серверов и последний
+	// сохраненный сервер
+	var mu sync.Mutex
+	var provision domain.Provision
+	var steps []string
+	var rows map[string]domain.Server
+	var saved domain.Server
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockProvisionRepo = NewMockProvisionRepository(ctrl)
+		mockProvisioner = NewMockProvisioner(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:    mockServerRepo,
+			ProvisionRepo: mockProvisionRepo,
+			Provisioner:   mockProvisioner,
+			Logger:        zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+
+		provision = domain.Provision{}
+		steps = nil
+		rows = make(map[string]domain.Server)
+		saved = domain.Server{}
+
+		mockServerRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, server *domain.Server) error {
+				mu.Lock()
+				defer mu.Unlock()
+				server.ID = "vpn-1"
+				rows[server.ID] = *server
+				return nil
+			}).AnyTimes()
+		mockServerRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id string) (*domain.Server, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				row, ok := rows[id]
+				if !ok {
+					return nil, fmt.Errorf("server not found: %s", id)
+				}
+				return &row, nil
+			}).AnyTimes()
+		mockServerRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, server *domain.Server) error {
+				mu.Lock()
+				defer mu.Unlock()
+				rows[server.ID] = *server
+				saved = *server
+				return nil
+			}).AnyTimes()
+		mockProvisionRepo.EXPECT().SaveProvision(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, saved *domain.Provision) error {
+				mu.Lock()
+				defer mu.Unlock()
+				provision = *saved
+				return nil
+			}).AnyTimes()
+		mockProvisionRepo.EXPECT().UpdateProvisionStep(gomock.Any(), "vpn-1", gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, step domain.ProvisionStep, progress int, message string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				provision.Step, provision.Progress, provision.Message = step, progress, message
+				steps = append(steps, fmt.Sprintf("%s %d", step, progress))
+				return nil
+			}).AnyTimes()
+		mockProvisionRepo.EXPECT().CompleteProvision(gomock.Any(), "vpn-1", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, success bool, message string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				provision.Status, provision.Message = domain.ProvisionStatusFailed, message
+				if success {
+					provision.Status, provision.Progress = domain.ProvisionStatusCompleted, 100
+				}
+				return nil
+			}).AnyTimes()
+	})
+
+	provisionStatus := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return provision.Status
+	}
+	savedServer := func() domain.Server {
+		mu.Lock()
+		defer mu.Unlock()
+		return saved
+	}
+
+	newRequest := func() *domain.ProvisionRequest {
+		return &domain.ProvisionRequest{
+			Name:         "eu-vps-1",
+			Region:       "eu-west-1",
+			Host:         "203.0.113.10",
+			HostKey:      "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHostKey",
+			BinaryURL:    "https://releases.example.com/vpn-core-linux-amd64",
+			BinarySHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		}
+	}
+
+	It("runs every step and registers the server", func() {
+		var received *domain.ProvisionRequest
+		mockProvisioner.EXPECT().Provision(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, server *domain.Server, req *domain.ProvisionRequest, progress func(domain.ProvisionStep)) error {
+				Expect(server.ID).To(Equal("vpn-1"))
+				received = req
+				for _, step := range domain.ProvisionSteps[:len(domain.ProvisionSteps)-1] {
+					progress(step)
+				}
+				return nil
+			})
+
+		server, err := serverService.ProvisionServer(ctx, newRequest())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Status).To(Equal(domain.ServerStatusCreating))
+		Expect(server.Type).To(Equal(domain.ServerTypeVPN))
+		Expect(server.IP).To(Equal("203.0.113.10"))
+		Expect(server.Port).To(Equal(51820))
+		Expect(server.OrchestratorHandle).To(BeEmpty())
+
+		Eventually(provisionStatus).Should(Equal(domain.ProvisionStatusCompleted))
+		Expect(savedServer().Status).To(Equal(domain.ServerStatusRunning))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(steps).To(Equal([]string{
+			"connect 0", "prerequisites 16", "install 33", "configure 50", "start 66", "register 83",
+		}))
+		Expect(provision.Progress).To(Equal(100))
+		Expect(provision.Host).To(Equal("203.0.113.10"))
+
+		// Значения по умолчанию
+		Expect(received.SSHPort).To(Equal(22))
+		Expect(received.User).To(Equal("root"))
+		Expect(received.Mode).To(Equal(domain.ProvisionModeBinary))
+	})
+
+	It("registers the host in the node registry", func() {
+		mockNodeRepo := NewMockNodeRepository(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:    mockServerRepo,
+			NodeRepo:      mockNodeRepo,
+			ProvisionRepo: mockProvisionRepo,
+			Provisioner:   mockProvisioner,
+			Logger:        zap.NewNop(),
+		}).(*services.ServerService)
+		mockProvisioner.EXPECT().Provision(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		// Хост уже подготавливался и был выведен из размещения
+		mockNodeRepo.EXPECT().GetNode(gomock.Any(), "203.0.113.10").
+			Return(&domain.Node{ID: "203.0.113.10", Kind: domain.NodeKindSSH, State: domain.NodeStateCordoned}, nil)
+		var node *domain.Node
+		mockNodeRepo.EXPECT().SaveNode(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, saved *domain.Node) error {
+				node = saved
+				return nil
+			})
+
+		req := newRequest()
+		req.SSHPort = 2222
+		_, err := serverService.ProvisionServer(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(provisionStatus).Should(Equal(domain.ProvisionStatusCompleted))
+		Expect(savedServer().NodeID).To(Equal("203.0.113.10"))
+		Expect(node.Kind).To(Equal(domain.NodeKindSSH))
+		Expect(node.Endpoint).To(Equal("ssh://root@203.0.113.10:2222"))
+		Expect(node.Region).To(Equal("eu-west-1"))
+		Expect(node.Capacity).To(Equal(1))
+		Expect(node.State).To(Equal(domain.NodeStateCordoned))
+		Expect(node.Healthy).To(BeTrue())
+	})
+
+	It("fails provisioning when the host is a docker node", func() {
+		mockNodeRepo := NewMockNodeRepository(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:    mockServerRepo,
+			NodeRepo:      mockNodeRepo,
+			ProvisionRepo: mockProvisionRepo,
+			Provisioner:   mockProvisioner,
+			Logger:        zap.NewNop(),
+		}).(*services.ServerService)
+		mockProvisioner.EXPECT().Provision(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockNodeRepo.EXPECT().GetNode(gomock.Any(), "203.0.113.10").
+			Return(&domain.Node{ID: "203.0.113.10", Kind: domain.NodeKindDocker}, nil)
+
+		_, err := serverService.ProvisionServer(ctx, newRequest())
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(provisionStatus).Should(Equal(domain.ProvisionStatusFailed))
+		Expect(savedServer().Status).To(Equal(domain.ServerStatusError))
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(provision.Message).To(Equal("node 203.0.113.10 is a docker node"))
+	})
+
+	It("keeps changes made to the server while provisioning", func() {
+		mockProvisioner.EXPECT().Provision(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, server *domain.Server, _ *domain.ProvisionRequest, _ func(domain.ProvisionStep)) error {
+				mu.Lock()
+				defer mu.Unlock()
+				row := rows[server.ID]
+				row.Name = "eu-vps-renamed"
+				rows[server.ID] = row
+				return nil
+			})
+
+		_, err := serverService.ProvisionServer(ctx, newRequest())
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(provisionStatus).Should(Equal(domain.ProvisionStatusCompleted))
+		Expect(savedServer().Name).To(Equal("eu-vps-renamed"))
+		Expect(savedServer().Status).To(Equal(domain.ServerStatusRunning))
+	})
+
+	It("marks the server as failed when a step fails", func() {
+		mockProvisioner.EXPECT().Provision(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *domain.Server, req *domain.ProvisionRequest, progress func(domain.ProvisionStep)) error {
+				Expect(req.Image).To(Equal("silence/vpn-core:latest"))
+				progress(domain.ProvisionStepConnect)
+				progress(domain.ProvisionStepPrerequisites)
+				return fmt.Errorf("failed to install prerequisites: unsupported package manager")
+			})
+
+		req := newRequest()
+		req.Mode = domain.ProvisionModeContainer
+		req.BinaryURL = ""
+		server, err := serverService.ProvisionServer(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Image).To(Equal("silence/vpn-core:latest"))
+
+		Eventually(provisionStatus).Should(Equal(domain.ProvisionStatusFailed))
+		Expect(savedServer().Status).To(Equal(domain.ServerStatusError))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(provision.Step).To(Equal(domain.ProvisionStepPrerequisites))
+		Expect(provision.Message).To(ContainSubstring("unsupported package manager"))
+	})
+
+	It("cancels provisioning on Stop and waits for it", func() {
+		mockProvisioner.EXPECT().Provision(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ *domain.Server, _ *domain.ProvisionRequest, progress func(domain.ProvisionStep)) error {
+				progress(domain.ProvisionStepConnect)
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+		_, err := serverService.ProvisionServer(ctx, newRequest())
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() domain.ProvisionStep {
+			mu.Lock()
+			defer mu.Unlock()
+			return provision.Step
+		}).Should(Equal(domain.ProvisionStepConnect))
+
+		serverService.Stop()
+
+		// Stop возвращается, когда подготовка уже отмечена ошибкой
+		Expect(provisionStatus()).To(Equal(domain.ProvisionStatusFailed))
+		Expect(savedServer().Status).To(Equal(domain.ServerStatusError))
+	})
+
+	It("fails provisioning after the timeout", func() {
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:       mockServerRepo,
+			ProvisionRepo:    mockProvisionRepo,
+			Provisioner:      mockProvisioner,
+			Logger:           zap.NewNop(),
+			ProvisionTimeout: 50 * time.Millisecond,
+		}).(*services.ServerService)
+		mockProvisioner.EXPECT().Provision(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ *domain.Server, _ *domain.ProvisionRequest, _ func(domain.ProvisionStep)) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+		_, err := serverService.ProvisionServer(ctx, newRequest())
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(provisionStatus).Should(Equal(domain.ProvisionStatusFailed))
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(provision.Message).To(ContainSubstring("deadline exceeded"))
+	})
+
+	It("fails provisions interrupted by a restart", func() {
+		mockProvisionRepo.EXPECT().FailInterruptedProvisions(ctx, "interrupted by server-manager restart").
+			Return([]string{"vpn-1", "vpn-2"}, nil)
+		rows["vpn-1"] = domain.Server{ID: "vpn-1", Status: domain.ServerStatusCreating}
+		// Сервер, уже удаленный или измененный, не трогается
+		rows["vpn-2"] = domain.Server{ID: "vpn-2", Status: domain.ServerStatusDeleting}
+
+		Expect(serverService.FailInterruptedProvisions(ctx)).To(Succeed())
+		Expect(savedServer().ID).To(Equal("vpn-1"))
+		Expect(savedServer().Status).To(Equal(domain.ServerStatusError))
+	})
+
+	DescribeTable("rejects invalid requests",
+		func(modify func(req *domain.ProvisionRequest), message string) {
+			req := newRequest()
+			modify(req)
+			_, err := serverService.ProvisionServer(ctx, req)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("without host", func(req *domain.ProvisionRequest) { req.Host = "" }, "host is required"),
+		Entry("without host key", func(req *domain.ProvisionRequest) { req.HostKey = "" }, "host key is required"),
+		Entry("without binary url", func(req *domain.ProvisionRequest) { req.BinaryURL = "" },
+			"binary url is required for binary mode"),
+		Entry("without binary checksum", func(req *domain.ProvisionRequest) { req.BinarySHA256 = "" },
+			"binary sha256 is required for binary mode"),
+		Entry("with short binary checksum", func(req *domain.ProvisionRequest) { req.BinarySHA256 = "9f86d081" },
+			"binary sha256 is required for binary mode"),
+		Entry("with invalid image", func(req *domain.ProvisionRequest) {
+			req.Mode = domain.ProvisionModeContainer
+			req.Image = "silence/vpn-core --privileged"
+		}, "invalid image: invalid reference format"),
+		Entry("with unknown mode", func(req *domain.ProvisionRequest) { req.Mode = "ansible" },
+			"unknown provision mode: ansible"),
+		Entry("with multiline environment", func(req *domain.ProvisionRequest) {
+			req.Env = map[string]string{"LOG_LEVEL": "debug\nExecStart=/bin/sh"}
+		}, `invalid environment variable: "LOG_LEVEL"`),
+	)
+
+	It("requires a provisioner", func() {
+		service := services.NewServerService(services.ServerServiceOptions{
+			ServerRepo: mockServerRepo,
+			Logger:     zap.NewNop(),
+		})
+
+		_, err := service.ProvisionServer(ctx, newRequest())
+		Expect(err).To(MatchError("provisioner not initialized"))
+	})
+})
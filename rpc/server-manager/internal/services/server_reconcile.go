@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+)
+
+// ReconcileServers сравнивает серверы в базе данных с ресурсами
+// оркестратора. База задает желаемое состояние: остановленный сервер со
+// статусом running перезапускается, остальные статусы приводятся к
+// фактическим. Серверы недоступных узлов, серверы в процессе создания,
+// обновления, переноса или удаления и серверы вне оркестратора, например
+// подготовленные по SSH, не сверяются
+func (s *ServerService) ReconcileServers(ctx context.Context, req *domain.ReconcileRequest) (*domain.DriftReport, error) {
+	if !req.OrphanPolicy.Valid() {
+		return nil, fmt.Errorf("unknown orphan policy: %s", req.OrphanPolicy)
+	}
+	policy := req.OrphanPolicy
+	if policy == "" {
+		policy = domain.OrphanPolicyIgnore
+	}
+
+	// Сверка не должна видеть промежуточное состояние копирования и
+	// операций над серверами; серверы в процессе переноса и других
+	// длительных операций пропускаются
+	s.backupMutex.Lock()
+	defer s.backupMutex.Unlock()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	servers, err := s.serverRepo.List(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+	live, err := s.orchestrator.ListServers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orchestrator servers: %w", err)
+	}
+	unavailable, err := s.unavailableNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[string][]*domain.Server, len(live))
+	for _, resource := range live {
+		resources[resource.ID] = append(resources[resource.ID], resource)
+	}
+
+	report := &domain.DriftReport{
+		Drifts:       []*domain.Drift{},
+		OrphanPolicy: policy,
+		DryRun:       req.DryRun,
+		CheckedAt:    time.Now(),
+	}
+	var duplicates []*domain.Server
+	for _, server := range servers {
+		candidates := resources[server.ID]
+		delete(resources, server.ID)
+
+		unmanaged := server.OrchestratorHandle == "" && len(candidates) == 0
+		if unmanaged || unavailable[server.NodeID] || reconcileInProgress(server.Status) ||
+			s.checkOperation(server.ID) != nil {
+			report.Skipped++
+			continue
+		}
+		report.Checked++
+
+		actual, extra := matchResource(server, candidates)
+		duplicates = append(duplicates, extra...)
+		s.reconcileServer(ctx, report, server, actual)
+	}
+	for _, duplicate := range duplicates {
+		s.reconcileOrphan(ctx, report, duplicate, policy, true)
+	}
+	// В resources остались ресурсы без серверов в базе
+	for _, resource := range live {
+		if _, ok := resources[resource.ID]; ok {
+			s.reconcileOrphan(ctx, report, resource, policy, false)
+		}
+	}
+
+	s.logger.Info("servers reconciled",
+		zap.Int("checked", report.Checked),
+		zap.Int("skipped", report.Skipped),
+		zap.Int("drifts", len(report.Drifts)),
+		zap.Bool("dry_run", report.DryRun))
+	return report, nil
+}
+
+// unavailableNodes узлы, не прошедшие последнюю проверку. Без реестра
+// узлов все серверы считаются доступными
+func (s *ServerService) unavailableNodes(ctx context.Context) (map[string]bool, error) {
+	if s.nodeRepo == nil {
+		return nil, nil
+	}
+	if _, ok := s.orchestrator.(ports.NodeOrchestrator); !ok {
+		return nil, nil
+	}
+
+	nodes, err := s.nodeRepo.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	unavailable := make(map[string]bool)
+	for _, node := range nodes {
+		if !node.Healthy {
+			unavailable[node.ID] = true
+		}
+	}
+	return unavailable, nil
+}
+
+// reconcileInProgress изменяется ли сервер другой операцией
+func reconcileInProgress(status domain.ServerStatus) bool {
+	switch status {
+	case domain.ServerStatusCreating, domain.ServerStatusUpdating, domain.ServerStatusDeleting:
+		return true
+	}
+	return false
+}
+
+// matchResource выбирает ресурс сервера: с handle из базы, иначе первый.
+// Остальные ресурсы с ID сервера считаются лишними
+func matchResource(server *domain.Server, candidates []*domain.Server) (*domain.Server, []*domain.Server) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	for i, candidate := range candidates {
+		if candidate.OrchestratorHandle == server.OrchestratorHandle {
+			extra := append(append([]*domain.Server(nil), candidates[:i]...), candidates[i+1:]...)
+			return candidate, extra
+		}
+	}
+	return candidates[0], candidates[1:]
+}
+
+// reconcileServer сверяет сервер с его ресурсом, nil - ресурса нет
+func (s *ServerService) reconcileServer(ctx context.Context, report *domain.DriftReport, server, actual *domain.Server) {
+	if actual == nil {
+		// Сервер без ресурса, уже отмеченный ошибкой, не меняется
+		if server.Status == domain.ServerStatusError {
+			return
+		}
+		drift := s.addDrift(report, server, domain.DriftMissing, domain.DriftActionUpdateStatus,
+			string(server.Status), "missing")
+		s.applyDrift(report, drift, func() error {
+			return s.setReconciledStatus(ctx, server, domain.ServerStatusError)
+		})
+		return
+	}
+
+	if actual.OrchestratorHandle != server.OrchestratorHandle || actual.NodeID != server.NodeID {
+		drift := s.addDrift(report, server, domain.DriftHandle, domain.DriftActionUpdateHandle,
+			server.OrchestratorHandle, actual.OrchestratorHandle)
+		drift.NodeID = actual.NodeID
+		updated := s.applyDrift(report, drift, func() error {
+			previousHandle, previousNode := server.OrchestratorHandle, server.NodeID
+			server.OrchestratorHandle, server.NodeID = actual.OrchestratorHandle, actual.NodeID
+			if err := s.serverRepo.Update(ctx, server); err != nil {
+				server.OrchestratorHandle, server.NodeID = previousHandle, previousNode
+				return fmt.Errorf("failed to update server: %w", err)
+			}
+			return nil
+		})
+		// Без верного handle остальные действия адресовали бы не тот ресурс
+		if !updated {
+			return
+		}
+	}
+
+	switch {
+	case server.Status == actual.Status:
+	case server.Status == domain.ServerStatusRunning &&
+		(actual.Status == domain.ServerStatusStopped || actual.Status == domain.ServerStatusError):
+		drift := s.addDrift(report, server, domain.DriftStopped, domain.DriftActionRestart,
+			string(server.Status), string(actual.Status))
+		s.applyDrift(report, drift, func() error {
+			if err := s.orchestrator.StartServer(ctx, server.OrchestratorHandle); err != nil {
+				if statusErr := s.setReconciledStatus(ctx, server, domain.ServerStatusError); statusErr != nil {
+					s.logger.Error("failed to update server status", zap.String("server_id", server.ID), zap.Error(statusErr))
+				}
+				return fmt.Errorf("failed to restart server: %w", err)
+			}
+			s.publishEvent(server.ID, domain.MonitorEventRestarted, "server restarted by reconciler")
+			return nil
+		})
+	case actual.Status == domain.ServerStatusRunning || actual.Status == domain.ServerStatusStopped ||
+		actual.Status == domain.ServerStatusError:
+		drift := s.addDrift(report, server, domain.DriftStatus, domain.DriftActionUpdateStatus,
+			string(server.Status), string(actual.Status))
+		s.applyDrift(report, drift, func() error {
+			return s.setReconciledStatus(ctx, server, actual.Status)
+		})
+	}
+}
+
+// reconcileOrphan применяет политику к ресурсу без сервера в базе.
+// Лишний ресурс существующего сервера (duplicate) можно только удалить
+func (s *ServerService) reconcileOrphan(ctx context.Context, report *domain.DriftReport, orphan *domain.Server, policy domain.OrphanPolicy, duplicate bool) {
+	action := domain.DriftActionNone
+	switch {
+	case policy == domain.OrphanPolicyAdopt && !duplicate:
+		action = domain.DriftActionAdopt
+	case policy == domain.OrphanPolicyRemove:
+		action = domain.DriftActionRemove
+	}
+	drift := s.addDrift(report, orphan, domain.DriftOrphan, action, "", string(orphan.Status))
+
+	s.applyDrift(report, drift, func() error {
+		switch action {
+		case domain.DriftActionAdopt:
+			if orphan.ID == "" {
+				return fmt.Errorf("resource has no server id")
+			}
+			server := &domain.Server{
+				ID:                 orphan.ID,
+				Name:               orphan.Name,
+				Type:               orphan.Type,
+				Status:             orphan.Status,
+				Region:             orphan.Region,
+				OrchestratorHandle: orphan.OrchestratorHandle,
+				Image:              orphan.Image,
+				Replicas:           orphan.Replicas,
+				NodeID:             orphan.NodeID,
+			}
+			if err := s.serverRepo.Create(ctx, server); err != nil {
+				// Ресурс остался от удаленного сервера: его нужно удалить, а
+				// не принять. Удаление выполняет сверка с политикой remove
+				if errors.Is(err, domain.ErrServerExists) {
+					drift.Action = domain.DriftActionRemove
+					return fmt.Errorf("server %s was deleted", orphan.ID)
+				}
+				return fmt.Errorf("failed to adopt server: %w", err)
+			}
+		case domain.DriftActionRemove:
+			if err := s.orchestrator.DeleteServer(ctx, orphan.OrchestratorHandle); err != nil {
+				return fmt.Errorf("failed to remove orphan: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// addDrift добавляет расхождение в отчет
+func (s *ServerService) addDrift(report *domain.DriftReport, server *domain.Server, driftType domain.DriftType, action domain.DriftAction, expected, actual string) *domain.Drift {
+	drift := &domain.Drift{
+		ServerID: server.ID,
+		Name:     server.Name,
+		NodeID:   server.NodeID,
+		Handle:   server.OrchestratorHandle,
+		Type:     driftType,
+		Expected: expected,
+		Actual:   actual,
+		Action:   action,
+	}
+	report.Drifts = append(report.Drifts, drift)
+	return drift
+}
+
+// applyDrift выполняет действие расхождения, если это не dry run.
+// Возвращает true, если действие выполнено
+func (s *ServerService) applyDrift(report *domain.DriftReport, drift *domain.Drift, apply func() error) bool {
+	if report.DryRun || drift.Action == domain.DriftActionNone {
+		return false
+	}
+	if err := apply(); err != nil {
+		drift.Error = err.Error()
+		s.logger.Warn("failed to reconcile server",
+			zap.String("server_id", drift.ServerID),
+			zap.String("drift", string(drift.Type)),
+			zap.Error(err))
+		return false
+	}
+	drift.Applied = true
+	s.logger.Info("server reconciled",
+		zap.String("server_id", drift.ServerID),
+		zap.String("drift", string(drift.Type)),
+		zap.String("action", string(drift.Action)))
+	return true
+}
+
+// setReconciledStatus записывает фактический статус сервера
+func (s *ServerService) setReconciledStatus(ctx context.Context, server *domain.Server, status domain.ServerStatus) error {
+	server.Status = status
+	if err := s.serverRepo.Update(ctx, server); err != nil {
+		return fmt.Errorf("failed to update server: %w", err)
+	}
+	s.publishStatus(server)
+	return nil
+}
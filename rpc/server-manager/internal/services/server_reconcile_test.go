@@ -0,0 +1,216 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("ReconcileServers", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var ctrl *gomock.Controller
+	var mockServerRepo *MockServerRepository
+	var mockOrchestrator *MockOrchestrator
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockOrchestrator = NewMockOrchestrator(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+	})
+
+	drifts := func(report *domain.DriftReport) map[string]domain.DriftType {
+		result := make(map[string]domain.DriftType, len(report.Drifts))
+		for _, drift := range report.Drifts {
+			result[drift.ServerID] = drift.Type
+		}
+		return result
+	}
+
+	Context("with drifted servers", func() {
+		var crashed, stale, lost, moved, busy *domain.Server
+
+		BeforeEach(func() {
+			crashed = &domain.Server{ID: "vpn-1", Name: "crashed", Status: domain.ServerStatusRunning, OrchestratorHandle: "c1"}
+			stale = &domain.Server{ID: "vpn-2", Name: "stale", Status: domain.ServerStatusStopped, OrchestratorHandle: "c2"}
+			lost = &domain.Server{ID: "vpn-3", Name: "lost", Status: domain.ServerStatusRunning, OrchestratorHandle: "c3"}
+			moved = &domain.Server{ID: "vpn-4", Name: "moved", Status: domain.ServerStatusRunning, OrchestratorHandle: "c4"}
+			busy = &domain.Server{ID: "vpn-5", Name: "busy", Status: domain.ServerStatusUpdating, OrchestratorHandle: "c5"}
+
+			mockServerRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+				Return([]*domain.Server{crashed, stale, lost, moved, busy}, nil)
+			mockOrchestrator.EXPECT().ListServers(gomock.Any()).Return([]*domain.Server{
+				{ID: "vpn-1", Status: domain.ServerStatusStopped, OrchestratorHandle: "c1"},
+				{ID: "vpn-2", Status: domain.ServerStatusRunning, OrchestratorHandle: "c2"},
+				{ID: "vpn-4", Status: domain.ServerStatusRunning, OrchestratorHandle: "c9"},
+				{ID: "vpn-5", Status: domain.ServerStatusStopped, OrchestratorHandle: "c5"},
+				{ID: "vpn-9", Name: "silence-vpn-vpn-9", Type: domain.ServerTypeVPN, Status: domain.ServerStatusRunning, OrchestratorHandle: "c7"},
+			}, nil)
+		})
+
+		It("reports drifts without changes in dry run", func() {
+			report, err := serverService.ReconcileServers(ctx, &domain.ReconcileRequest{DryRun: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Checked).To(Equal(4))
+			Expect(report.Skipped).To(Equal(1))
+			Expect(report.OrphanPolicy).To(Equal(domain.OrphanPolicyIgnore))
+			Expect(drifts(report)).To(Equal(map[string]domain.DriftType{
+				"vpn-1": domain.DriftStopped,
+				"vpn-2": domain.DriftStatus,
+				"vpn-3": domain.DriftMissing,
+				"vpn-4": domain.DriftHandle,
+				"vpn-9": domain.DriftOrphan,
+			}))
+			for _, drift := range report.Drifts {
+				Expect(drift.Applied).To(BeFalse())
+			}
+		})
+
+		It("restarts stopped servers and records actual state", func() {
+			mockOrchestrator.EXPECT().StartServer(gomock.Any(), "c1").Return(nil)
+			updated := make(map[string]domain.Server)
+			mockServerRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, server *domain.Server) error {
+					updated[server.ID] = *server
+					return nil
+				}).Times(3)
+			mockServerRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, server *domain.Server) error {
+					Expect(server.ID).To(Equal("vpn-9"))
+					Expect(server.OrchestratorHandle).To(Equal("c7"))
+					return nil
+				})
+
+			report, err := serverService.ReconcileServers(ctx, &domain.ReconcileRequest{OrphanPolicy: domain.OrphanPolicyAdopt})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, drift := range report.Drifts {
+				Expect(drift.Applied).To(BeTrue(), drift.ServerID)
+			}
+			Expect(updated).To(HaveLen(3))
+			Expect(updated["vpn-2"].Status).To(Equal(domain.ServerStatusRunning))
+			Expect(updated["vpn-3"].Status).To(Equal(domain.ServerStatusError))
+			Expect(updated["vpn-4"].OrchestratorHandle).To(Equal("c9"))
+		})
+
+		It("marks servers that fail to restart", func() {
+			mockOrchestrator.EXPECT().StartServer(gomock.Any(), "c1").Return(fmt.Errorf("no such image"))
+			var failed *domain.Server
+			mockServerRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, server *domain.Server) error {
+					if server.ID == "vpn-1" {
+						failed = server
+					}
+					return nil
+				}).Times(4)
+			mockOrchestrator.EXPECT().DeleteServer(gomock.Any(), "c7").Return(nil)
+
+			report, err := serverService.ReconcileServers(ctx, &domain.ReconcileRequest{OrphanPolicy: domain.OrphanPolicyRemove})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(failed).NotTo(BeNil())
+			Expect(failed.Status).To(Equal(domain.ServerStatusError))
+			Expect(report.Drifts[0].ServerID).To(Equal("vpn-1"))
+			Expect(report.Drifts[0].Applied).To(BeFalse())
+			Expect(report.Drifts[0].Error).To(Equal("failed to restart server: no such image"))
+		})
+	})
+
+	It("removes duplicate resources but never adopts them", func() {
+		server := &domain.Server{ID: "vpn-1", Status: domain.ServerStatusRunning, OrchestratorHandle: "c1"}
+		mockServerRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*domain.Server{server}, nil).Times(2)
+		mockOrchestrator.EXPECT().ListServers(gomock.Any()).Return([]*domain.Server{
+			{ID: "vpn-1", Status: domain.ServerStatusRunning, OrchestratorHandle: "c8"},
+			{ID: "vpn-1", Status: domain.ServerStatusRunning, OrchestratorHandle: "c1"},
+		}, nil).Times(2)
+
+		report, err := serverService.ReconcileServers(ctx, &domain.ReconcileRequest{OrphanPolicy: domain.OrphanPolicyAdopt})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Drifts).To(HaveLen(1))
+		Expect(report.Drifts[0].Handle).To(Equal("c8"))
+		Expect(report.Drifts[0].Action).To(Equal(domain.DriftActionNone))
+
+		mockOrchestrator.EXPECT().DeleteServer(gomock.Any(), "c8").Return(nil)
+		report, err = serverService.ReconcileServers(ctx, &domain.ReconcileRequest{OrphanPolicy: domain.OrphanPolicyRemove})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Drifts[0].Applied).To(BeTrue())
+	})
+
+	It("does not adopt resources of deleted servers", func() {
+		mockServerRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*domain.Server{}, nil)
+		mockOrchestrator.EXPECT().ListServers(gomock.Any()).Return([]*domain.Server{
+			{ID: "vpn-9", Status: domain.ServerStatusRunning, OrchestratorHandle: "c9"},
+		}, nil)
+		mockServerRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			Return(fmt.Errorf("%w: vpn-9", domain.ErrServerExists))
+
+		report, err := serverService.ReconcileServers(ctx, &domain.ReconcileRequest{OrphanPolicy: domain.OrphanPolicyAdopt})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Drifts).To(HaveLen(1))
+		Expect(report.Drifts[0].Type).To(Equal(domain.DriftOrphan))
+		Expect(report.Drifts[0].Action).To(Equal(domain.DriftActionRemove))
+		Expect(report.Drifts[0].Applied).To(BeFalse())
+		Expect(report.Drifts[0].Error).To(Equal("server vpn-9 was deleted"))
+	})
+
+	It("skips servers on unhealthy nodes", func() {
+		mockNodeRepo := NewMockNodeRepository(ctrl)
+		mockNodeOrchestrator := NewMockNodeOrchestrator(ctrl)
+		service := services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			NodeRepo:     mockNodeRepo,
+			Orchestrator: mockNodeOrchestrator,
+			Logger:       zap.NewNop(),
+		})
+
+		mockServerRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*domain.Server{
+			{ID: "vpn-1", NodeID: "eu-a", Status: domain.ServerStatusRunning, OrchestratorHandle: "eu-a/c1"},
+			{ID: "vpn-2", NodeID: "eu-b", Status: domain.ServerStatusRunning, OrchestratorHandle: "eu-b/c2"},
+		}, nil)
+		mockNodeOrchestrator.EXPECT().ListServers(gomock.Any()).Return([]*domain.Server{
+			{ID: "vpn-1", NodeID: "eu-a", Status: domain.ServerStatusRunning, OrchestratorHandle: "eu-a/c1"},
+		}, nil)
+		mockNodeRepo.EXPECT().ListNodes(gomock.Any()).Return([]*domain.Node{
+			{ID: "eu-a", Healthy: true},
+			{ID: "eu-b", Healthy: false},
+		}, nil)
+
+		report, err := service.ReconcileServers(ctx, &domain.ReconcileRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Checked).To(Equal(1))
+		Expect(report.Skipped).To(Equal(1))
+		Expect(report.Drifts).To(BeEmpty())
+	})
+
+	It("skips servers outside the orchestrator", func() {
+		mockServerRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*domain.Server{
+			{ID: "vpn-1", Status: domain.ServerStatusRunning, IP: "203.0.113.10"},
+		}, nil)
+		mockOrchestrator.EXPECT().ListServers(gomock.Any()).Return([]*domain.Server{}, nil)
+
+		report, err := serverService.ReconcileServers(ctx, &domain.ReconcileRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Checked).To(Equal(0))
+		Expect(report.Skipped).To(Equal(1))
+		Expect(report.Drifts).To(BeEmpty())
+	})
+
+	It("rejects unknown orphan policies", func() {
+		_, err := serverService.ReconcileServers(ctx, &domain.ReconcileRequest{OrphanPolicy: "keep"})
+		Expect(err).To(MatchError("unknown orphan policy: keep"))
+	})
+})
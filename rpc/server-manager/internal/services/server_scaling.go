@@ -2,12 +2,21 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
 )
 
+// scaleDownHeadroom доля порога, ниже которой должна остаться нагрузка
+// после удаления реплики. Разрыв с порогом увеличения дает гистерезис и
+// исключает колебания между scale_up и scale_down
+const scaleDownHeadroom = 0.75
+
 // GetScalingPolicies получает политики масштабирования
 func (s *ServerService) GetScalingPolicies(ctx context.Context) ([]*domain.ScalingPolicy, error) {
 	if s.scalingRepo == nil {
@@ -42,9 +51,380 @@ func (s *ServerService) DeleteScalingPolicy(ctx context.Context, id string) erro
 	return s.scalingRepo.DeletePolicy(ctx, id)
 }
 
-// EvaluateScaling оценивает необходимость масштабирования
-func (s *ServerService) EvaluateScaling(ctx context.Context) error {
-	// TODO: реализовать логику масштабирования
-	s.logger.Info("evaluating scaling policies")
+// EvaluateScaling оценивает политики масштабирования: агрегирует нагрузку
+// серверов каждой группы (тип и регион), принимает решение с учетом
+// гистерезиса и кулдаунов и выполняет его через оркестратор
+func (s *ServerService) EvaluateScaling(ctx context.Context, dryRun bool) ([]*domain.ScalingDecision, error) {
+	if s.scalingRepo == nil {
+		return nil, fmt.Errorf("scaling repository not initialized")
+	}
+
+	// Параллельная оценка приняла бы решения по одной и той же нагрузке
+	s.scalingMutex.Lock()
+	defer s.scalingMutex.Unlock()
+
+	policies, err := s.scalingRepo.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scaling policies: %w", err)
+	}
+
+	live, err := s.orchestrator.ListServers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orchestrator servers: %w", err)
+	}
+	replicas := make(map[string]int32, len(live))
+	for _, server := range live {
+		replicas[server.OrchestratorHandle] = server.Replicas
+	}
+
+	var decisions []*domain.ScalingDecision
+	var errs []error
+	for _, policy := range policies {
+		if !policy.Enabled {
+			continue
+		}
+
+		groups, err := s.scalingGroups(ctx, policy, replicas)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policy %s: %w", policy.Name, err))
+			continue
+		}
+
+		for _, group := range groups {
+			decision, err := s.evaluateGroup(ctx, policy, group, dryRun)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("policy %s, region %s: %w", policy.Name, group.region, err))
+			}
+			decisions = append(decisions, decision)
+		}
+	}
+
+	return decisions, errors.Join(errs...)
+}
+
+// scalingGroup работающие серверы одного типа и региона
+type scalingGroup struct {
+	region  string
+	servers []*domain.Server
+	stats   map[string]*domain.ServerStats
+}
+
+// capacity число реплик группы
+func (g *scalingGroup) capacity() int {
+	total := 0
+	for _, server := range g.servers {
+		total += int(server.Replicas)
+	}
+	return total
+}
+
+// load средняя загрузка CPU и памяти группы, взвешенная по репликам
+func (g *scalingGroup) load() (cpu, memory float64, sampled bool) {
+	var weight float64
+	for _, server := range g.servers {
+		stats, ok := g.stats[server.ID]
+		if !ok {
+			continue
+		}
+		replicas := float64(max(server.Replicas, 1))
+		cpu += stats.CPUUsage * replicas
+		memory += stats.MemoryUsage * replicas
+		weight += replicas
+	}
+	if weight == 0 {
+		return 0, 0, false
+	}
+	return cpu / weight, memory / weight, true
+}
+
+// cpuUsage загрузка CPU сервера; без статистики сервер считается занятым
+func (g *scalingGroup) cpuUsage(server *domain.Server) float64 {
+	if stats, ok := g.stats[server.ID]; ok {
+		return stats.CPUUsage
+	}
+	return 100
+}
+
+// scalingGroups собирает группы серверов политики с актуальной статистикой
+func (s *ServerService) scalingGroups(ctx context.Context, policy *domain.ScalingPolicy, replicas map[string]int32) ([]*scalingGroup, error) {
+	filters := map[string]interface{}{
+		"type":   policy.ServerType,
+		"status": domain.ServerStatusRunning,
+	}
+	if policy.Region != "" {
+		filters["region"] = policy.Region
+	}
+
+	servers, err := s.serverRepo.List(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	groups := make(map[string]*scalingGroup)
+	if policy.Region != "" {
+		// Пустой регион политики все равно должен достичь min_servers
+		groups[policy.Region] = &scalingGroup{region: policy.Region, stats: map[string]*domain.ServerStats{}}
+	}
+	for _, server := range servers {
+		group, ok := groups[server.Region]
+		if !ok {
+			group = &scalingGroup{region: server.Region, stats: map[string]*domain.ServerStats{}}
+			groups[server.Region] = group
+		}
+
+		server.Replicas = 1
+		if value, ok := replicas[server.OrchestratorHandle]; ok {
+			server.Replicas = value
+		}
+		group.servers = append(group.servers, server)
+
+		stats, err := s.GetServerStats(ctx, server.ID)
+		if err != nil {
+			s.logger.Warn("failed to get server stats for scaling", zap.String("server_id", server.ID), zap.Error(err))
+			continue
+		}
+		group.stats[server.ID] = stats
+	}
+
+	if len(groups) == 0 && policy.MinServers > 0 {
+		// Политике без региона и серверов регион выбирает размещение
+		region, err := s.selectRegion(ctx, policy.ServerType)
+		if err != nil {
+			return nil, err
+		}
+		if region != "" {
+			groups[region] = &scalingGroup{region: region, stats: map[string]*domain.ServerStats{}}
+		}
+	}
+
+	result := make([]*scalingGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].region < result[j].region })
+	return result, nil
+}
+
+// evaluateGroup принимает решение по группе, выполняет его и записывает в
+// историю. Ошибка выполнения отражается в причине решения
+func (s *ServerService) evaluateGroup(ctx context.Context, policy *domain.ScalingPolicy, group *scalingGroup, dryRun bool) (*domain.ScalingDecision, error) {
+	now := time.Now()
+	current := group.capacity()
+	cpu, memory, sampled := group.load()
+
+	decision := &domain.ScalingDecision{
+		PolicyID:      policy.ID,
+		ServerType:    policy.ServerType,
+		Region:        group.region,
+		ServersBefore: current,
+		ServersAfter:  current,
+		CPUUsage:      cpu,
+		MemoryUsage:   memory,
+		DryRun:        dryRun,
+		TriggeredAt:   now,
+	}
+
+	last, err := s.scalingRepo.GetLastAction(ctx, policy.ID, group.region)
+	if err != nil {
+		return decision, fmt.Errorf("failed to get last scaling action: %w", err)
+	}
+
+	var target int
+	decision.Action, target, decision.Reason = decideScaling(policy, current, cpu, memory, sampled, last, now)
+	if dryRun {
+		decision.ServersAfter = target
+		return decision, nil
+	}
+
+	// История хранит только выполненные масштабирования
+	if decision.Action == domain.ScalingActionNone {
+		return decision, nil
+	}
+
+	decision.ServersAfter, err = s.applyScaling(ctx, policy, group, target)
+	actionErr := err
+	if actionErr != nil {
+		decision.Reason = fmt.Sprintf("%s; failed: %v", decision.Reason, actionErr)
+	}
+	s.logger.Info("scaling decision applied",
+		zap.String("policy", policy.Name),
+		zap.String("region", group.region),
+		zap.String("action", string(decision.Action)),
+		zap.Int("servers_before", decision.ServersBefore),
+		zap.Int("servers_after", decision.ServersAfter),
+		zap.String("reason", decision.Reason))
+
+	if err := s.scalingRepo.SaveDecision(ctx, decision); err != nil {
+		return decision, errors.Join(actionErr, fmt.Errorf("failed to record scaling decision: %w", err))
+	}
+	return decision, actionErr
+}
+
+// decideScaling выбирает действие и целевое число реплик. Границы
+// min/max_servers соблюдаются сразу, по нагрузке группа меняется на одну
+// реплику с учетом кулдаунов
+func decideScaling(policy *domain.ScalingPolicy, current int, cpu, memory float64, sampled bool, last *domain.ScalingDecision, now time.Time) (domain.ScalingAction, int, string) {
+	if current < policy.MinServers {
+		// Кулдаун ограничивает повторы, когда создание серверов не удается
+		if inCooldown(last, policy.ScaleUpCooldown, now) {
+			return domain.ScalingActionNone, current,
+				fmt.Sprintf("%d replicas below min_servers %d, scale up cooldown", current, policy.MinServers)
+		}
+		return domain.ScalingActionScaleUp, policy.MinServers,
+			fmt.Sprintf("%d replicas below min_servers %d", current, policy.MinServers)
+	}
+	if policy.MaxServers > 0 && current > policy.MaxServers {
+		return domain.ScalingActionScaleDown, policy.MaxServers,
+			fmt.Sprintf("%d replicas above max_servers %d", current, policy.MaxServers)
+	}
+	if !sampled {
+		return domain.ScalingActionNone, current, "no server stats available"
+	}
+
+	// Пороги политики заданы долей, статистика - в процентах
+	cpuLimit := policy.CPUThreshold * 100
+	memoryLimit := policy.MemoryThreshold * 100
+	load := fmt.Sprintf("cpu %.1f%%, memory %.1f%%", cpu, memory)
+
+	if (cpuLimit > 0 && cpu > cpuLimit) || (memoryLimit > 0 && memory > memoryLimit) {
+		switch {
+		case policy.MaxServers > 0 && current >= policy.MaxServers:
+			return domain.ScalingActionNone, current, load + " above threshold, max_servers reached"
+		case inCooldown(last, policy.ScaleUpCooldown, now):
+			return domain.ScalingActionNone, current, load + " above threshold, scale up cooldown"
+		}
+		return domain.ScalingActionScaleUp, current + 1,
+			fmt.Sprintf("%s above threshold (cpu %.0f%%, memory %.0f%%)", load, cpuLimit, memoryLimit)
+	}
+
+	// Уменьшаем группу, только если оставшиеся реплики выдержат нагрузку с
+	// запасом относительно порога
+	if current > max(policy.MinServers, 1) && (cpuLimit > 0 || memoryLimit > 0) {
+		ratio := float64(current) / float64(current-1)
+		cpuFits := cpuLimit <= 0 || cpu*ratio < cpuLimit*scaleDownHeadroom
+		memoryFits := memoryLimit <= 0 || memory*ratio < memoryLimit*scaleDownHeadroom
+		if cpuFits && memoryFits {
+			if inCooldown(last, policy.ScaleDownCooldown, now) {
+				return domain.ScalingActionNone, current, load + " below threshold, scale down cooldown"
+			}
+			return domain.ScalingActionScaleDown, current - 1,
+				fmt.Sprintf("%s, projected cpu %.1f%%, memory %.1f%% after removing a replica", load, cpu*ratio, memory*ratio)
+		}
+	}
+
+	return domain.ScalingActionNone, current, load + " within thresholds"
+}
+
+// inCooldown проверяет, прошел ли кулдаун после последнего масштабирования
+func inCooldown(last *domain.ScalingDecision, cooldown time.Duration, now time.Time) bool {
+	return last != nil && now.Sub(last.TriggeredAt) < cooldown
+}
+
+// applyScaling доводит группу до target реплик и возвращает достигнутое
+// число. Сначала меняются реплики существующих серверов; если оркестратор
+// их не поддерживает, серверы создаются и удаляются
+func (s *ServerService) applyScaling(ctx context.Context, policy *domain.ScalingPolicy, group *scalingGroup, target int) (int, error) {
+	for group.capacity() < target {
+		if err := s.scaleGroupUp(ctx, policy, group); err != nil {
+			return group.capacity(), err
+		}
+	}
+	for group.capacity() > target {
+		if err := s.scaleGroupDown(ctx, group); err != nil {
+			return group.capacity(), err
+		}
+	}
+	return group.capacity(), nil
+}
+
+// scaleGroupUp добавляет группе одну реплику
+func (s *ServerService) scaleGroupUp(ctx context.Context, policy *domain.ScalingPolicy, group *scalingGroup) error {
+	if len(group.servers) > 0 {
+		// Реплика добавляется серверу с наименьшим их числом
+		server := group.servers[0]
+		for _, candidate := range group.servers[1:] {
+			if candidate.Replicas < server.Replicas {
+				server = candidate
+			}
+		}
+
+		err := s.orchestrator.ScaleServer(ctx, server.OrchestratorHandle, server.Replicas+1)
+		if err == nil {
+			server.Replicas++
+			s.publishEvent(server.ID, domain.MonitorEventScaled, fmt.Sprintf("scaled to %d replicas", server.Replicas))
+			return nil
+		}
+		if !errors.Is(err, domain.ErrNotSupported) {
+			return fmt.Errorf("failed to scale server %s: %w", server.ID, err)
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s-%s", policy.Name, group.region, uuid.New().String()[:8])
+	server, err := s.CreateServer(ctx, &domain.CreateServerRequest{
+		Name:   name,
+		Type:   policy.ServerType,
+		Region: group.region,
+	})
+	if err != nil {
+		s.removeFailedServer(ctx, policy, group.region, name)
+		return err
+	}
+	server.Replicas = 1
+	group.servers = append(group.servers, server)
+	return nil
+}
+
+// removeFailedServer удаляет сервер, оставленный неудачным созданием в
+// статусе error: группа считает только работающие серверы, и каждая
+// следующая оценка добавляла бы новую запись
+func (s *ServerService) removeFailedServer(ctx context.Context, policy *domain.ScalingPolicy, region, name string) {
+	servers, err := s.serverRepo.List(ctx, map[string]interface{}{
+		"type":   policy.ServerType,
+		"region": region,
+		"status": domain.ServerStatusError,
+	})
+	if err != nil {
+		s.logger.Warn("failed to list failed servers", zap.String("name", name), zap.Error(err))
+		return
+	}
+	for _, server := range servers {
+		if server.Name != name {
+			continue
+		}
+		if err := s.DeleteServer(ctx, server.ID); err != nil {
+			s.logger.Warn("failed to remove failed server", zap.String("server_id", server.ID), zap.Error(err))
+		}
+	}
+}
+
+// scaleGroupDown убирает у группы одну реплику
+func (s *ServerService) scaleGroupDown(ctx context.Context, group *scalingGroup) error {
+	// Реплика убирается у сервера с наибольшим их числом
+	server := group.servers[0]
+	for _, candidate := range group.servers[1:] {
+		if candidate.Replicas > server.Replicas {
+			server = candidate
+		}
+	}
+	if server.Replicas > 1 {
+		if err := s.orchestrator.ScaleServer(ctx, server.OrchestratorHandle, server.Replicas-1); err != nil {
+			return fmt.Errorf("failed to scale server %s: %w", server.ID, err)
+		}
+		server.Replicas--
+		s.publishEvent(server.ID, domain.MonitorEventScaled, fmt.Sprintf("scaled to %d replicas", server.Replicas))
+		return nil
+	}
+
+	// Удаляется наименее загруженный сервер
+	index := 0
+	for i, candidate := range group.servers {
+		if group.cpuUsage(candidate) < group.cpuUsage(group.servers[index]) {
+			index = i
+		}
+	}
+	if err := s.DeleteServer(ctx, group.servers[index].ID); err != nil {
+		return err
+	}
+	group.servers = append(group.servers[:index], group.servers[index+1:]...)
 	return nil
 }
@@ -0,0 +1,275 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("EvaluateScaling", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var mockServerRepo *MockServerRepository
+	var mockStatsRepo *MockStatsRepository
+	var mockScalingRepo *MockScalingRepository
+	var mockOrchestrator *MockOrchestrator
+	var policy *domain.ScalingPolicy
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockStatsRepo = NewMockStatsRepository(ctrl)
+		mockScalingRepo = NewMockScalingRepository(ctrl)
+		mockOrchestrator = NewMockOrchestrator(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			StatsRepo:    mockStatsRepo,
+			ScalingRepo:  mockScalingRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+
+		policy = &domain.ScalingPolicy{
+			ID:                "policy-1",
+			Name:              "vpn-eu",
+			ServerType:        domain.ServerTypeVPN,
+			Region:            "eu-west-1",
+			MinServers:        1,
+			MaxServers:        5,
+			CPUThreshold:      0.7,
+			MemoryThreshold:   0.8,
+			ScaleUpCooldown:   5 * time.Minute,
+			ScaleDownCooldown: 10 * time.Minute,
+			Enabled:           true,
+		}
+	})
+
+	// expectGroup настраивает группу серверов политики с загрузкой CPU
+	// каждого сервера и числом реплик по данным оркестратора
+	expectGroup := func(cpu map[string]float64, replicas map[string]int32) {
+		var servers, live []*domain.Server
+		for _, id := range []string{"server-1", "server-2", "server-3"} {
+			usage, ok := cpu[id]
+			if !ok {
+				continue
+			}
+			server := &domain.Server{
+				ID:                 id,
+				Type:               domain.ServerTypeVPN,
+				Status:             domain.ServerStatusRunning,
+				Region:             "eu-west-1",
+				OrchestratorHandle: "handle-" + id,
+			}
+			servers = append(servers, server)
+			live = append(live, &domain.Server{ID: id, OrchestratorHandle: server.OrchestratorHandle, Replicas: replicas[id]})
+
+			mockServerRepo.EXPECT().GetByID(ctx, id).Return(server, nil)
+			mockOrchestrator.EXPECT().GetServerStats(ctx, server.OrchestratorHandle).
+				Return(&domain.ServerStats{CPUUsage: usage, MemoryUsage: 30}, nil)
+		}
+
+		mockScalingRepo.EXPECT().ListPolicies(ctx).Return([]*domain.ScalingPolicy{policy}, nil)
+		mockOrchestrator.EXPECT().ListServers(ctx).Return(live, nil)
+		mockServerRepo.EXPECT().List(ctx, map[string]interface{}{
+			"type":   domain.ServerTypeVPN,
+			"status": domain.ServerStatusRunning,
+			"region": "eu-west-1",
+		}).Return(servers, nil)
+		mockStatsRepo.EXPECT().SaveStats(ctx, gomock.Any()).Return(nil).AnyTimes()
+	}
+
+	It("should create a server when load is above threshold and replicas are not supported", func() {
+		expectGroup(map[string]float64{"server-1": 90, "server-2": 85}, map[string]int32{"server-1": 1, "server-2": 1})
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(nil, nil)
+
+		mockOrchestrator.EXPECT().ScaleServer(ctx, "handle-server-1", int32(2)).Return(domain.ErrNotSupported)
+		mockServerRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, server *domain.Server) error {
+			Expect(server.Type).To(Equal(domain.ServerTypeVPN))
+			Expect(server.Region).To(Equal("eu-west-1"))
+			server.ID = "server-3"
+			return nil
+		})
+		mockOrchestrator.EXPECT().CreateServer(ctx, gomock.Any(), gomock.Any()).Return("handle-server-3", nil)
+		mockServerRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+		mockScalingRepo.EXPECT().SaveDecision(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, decision *domain.ScalingDecision) error {
+			Expect(decision.Action).To(Equal(domain.ScalingActionScaleUp))
+			Expect(decision.ServersBefore).To(Equal(2))
+			Expect(decision.ServersAfter).To(Equal(3))
+			Expect(decision.CPUUsage).To(BeNumerically("~", 87.5))
+			Expect(decision.Reason).To(ContainSubstring("above threshold"))
+			return nil
+		})
+
+		decisions, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).To(BeNil())
+		Expect(decisions).To(HaveLen(1))
+	})
+
+	It("should hold during scale up cooldown", func() {
+		expectGroup(map[string]float64{"server-1": 90}, map[string]int32{"server-1": 1})
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(&domain.ScalingDecision{
+			Action:      domain.ScalingActionScaleUp,
+			TriggeredAt: time.Now().Add(-time.Minute),
+		}, nil)
+
+		decisions, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).To(BeNil())
+		Expect(decisions[0].Action).To(Equal(domain.ScalingActionNone))
+		Expect(decisions[0].ServersAfter).To(Equal(1))
+		Expect(decisions[0].Reason).To(ContainSubstring("cooldown"))
+	})
+
+	It("should remove a replica when remaining replicas fit below threshold", func() {
+		expectGroup(map[string]float64{"server-1": 20}, map[string]int32{"server-1": 3})
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(&domain.ScalingDecision{
+			Action:      domain.ScalingActionScaleUp,
+			TriggeredAt: time.Now().Add(-time.Hour),
+		}, nil)
+		mockOrchestrator.EXPECT().ScaleServer(ctx, "handle-server-1", int32(2)).Return(nil)
+		mockScalingRepo.EXPECT().SaveDecision(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, decision *domain.ScalingDecision) error {
+			Expect(decision.Action).To(Equal(domain.ScalingActionScaleDown))
+			Expect(decision.ServersBefore).To(Equal(3))
+			Expect(decision.ServersAfter).To(Equal(2))
+			return nil
+		})
+
+		_, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).To(BeNil())
+	})
+
+	It("should keep replicas inside the hysteresis band", func() {
+		// 50% на двух репликах дает 100% на одной: выше порога
+		expectGroup(map[string]float64{"server-1": 50}, map[string]int32{"server-1": 2})
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(nil, nil)
+
+		decisions, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).To(BeNil())
+		Expect(decisions[0].Action).To(Equal(domain.ScalingActionNone))
+		Expect(decisions[0].Reason).To(ContainSubstring("within thresholds"))
+	})
+
+	It("should create servers up to min_servers", func() {
+		policy.MinServers = 1
+		expectGroup(map[string]float64{}, nil)
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(nil, nil)
+		mockServerRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+		mockOrchestrator.EXPECT().CreateServer(ctx, gomock.Any(), gomock.Any()).Return("handle-new", nil)
+		mockServerRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+		mockScalingRepo.EXPECT().SaveDecision(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, decision *domain.ScalingDecision) error {
+			Expect(decision.Action).To(Equal(domain.ScalingActionScaleUp))
+			Expect(decision.ServersBefore).To(Equal(0))
+			Expect(decision.ServersAfter).To(Equal(1))
+			Expect(decision.Reason).To(ContainSubstring("min_servers"))
+			return nil
+		})
+
+		_, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).To(BeNil())
+	})
+
+	It("should hold below min_servers during scale up cooldown", func() {
+		policy.MinServers = 1
+		expectGroup(map[string]float64{}, nil)
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(&domain.ScalingDecision{
+			Action:      domain.ScalingActionScaleUp,
+			TriggeredAt: time.Now().Add(-time.Minute),
+		}, nil)
+
+		decisions, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).To(BeNil())
+		Expect(decisions[0].Action).To(Equal(domain.ScalingActionNone))
+		Expect(decisions[0].Reason).To(ContainSubstring("min_servers 1, scale up cooldown"))
+	})
+
+	It("should remove the server left by a failed create", func() {
+		policy.MinServers = 1
+		expectGroup(map[string]float64{}, nil)
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(nil, nil)
+
+		var created *domain.Server
+		mockServerRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, server *domain.Server) error {
+			server.ID = "server-new"
+			created = server
+			return nil
+		})
+		mockOrchestrator.EXPECT().CreateServer(ctx, gomock.Any(), gomock.Any()).Return("", errors.New("no capacity"))
+		mockServerRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+		mockServerRepo.EXPECT().List(ctx, map[string]interface{}{
+			"type":   domain.ServerTypeVPN,
+			"status": domain.ServerStatusError,
+			"region": "eu-west-1",
+		}).DoAndReturn(func(context.Context, map[string]interface{}) ([]*domain.Server, error) {
+			other := &domain.Server{ID: "server-other", Name: "other", Status: domain.ServerStatusError}
+			return []*domain.Server{other, created}, nil
+		})
+		mockServerRepo.EXPECT().GetByID(ctx, "server-new").DoAndReturn(func(context.Context, string) (*domain.Server, error) {
+			return created, nil
+		})
+		mockServerRepo.EXPECT().Delete(ctx, "server-new").Return(nil)
+		mockScalingRepo.EXPECT().SaveDecision(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, decision *domain.ScalingDecision) error {
+			Expect(decision.Action).To(Equal(domain.ScalingActionScaleUp))
+			Expect(decision.ServersAfter).To(Equal(0))
+			Expect(decision.Reason).To(ContainSubstring("no capacity"))
+			return nil
+		})
+
+		_, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).To(MatchError(ContainSubstring("no capacity")))
+	})
+
+	It("should record a failed action", func() {
+		expectGroup(map[string]float64{"server-1": 95}, map[string]int32{"server-1": 1})
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(nil, nil)
+		mockOrchestrator.EXPECT().ScaleServer(ctx, "handle-server-1", int32(2)).Return(context.DeadlineExceeded)
+		mockScalingRepo.EXPECT().SaveDecision(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, decision *domain.ScalingDecision) error {
+			Expect(decision.Action).To(Equal(domain.ScalingActionScaleUp))
+			Expect(decision.ServersAfter).To(Equal(1))
+			Expect(decision.Reason).To(ContainSubstring("failed"))
+			return nil
+		})
+
+		_, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should only plan in dry run mode", func() {
+		expectGroup(map[string]float64{"server-1": 90, "server-2": 85}, map[string]int32{"server-1": 1, "server-2": 1})
+		mockScalingRepo.EXPECT().GetLastAction(ctx, "policy-1", "eu-west-1").Return(nil, nil)
+
+		decisions, err := serverService.EvaluateScaling(ctx, true)
+
+		Expect(err).To(BeNil())
+		Expect(decisions).To(HaveLen(1))
+		Expect(decisions[0].DryRun).To(BeTrue())
+		Expect(decisions[0].Action).To(Equal(domain.ScalingActionScaleUp))
+		Expect(decisions[0].ServersAfter).To(Equal(3))
+	})
+
+	It("should skip disabled policies", func() {
+		policy.Enabled = false
+		mockScalingRepo.EXPECT().ListPolicies(ctx).Return([]*domain.ScalingPolicy{policy}, nil)
+		mockOrchestrator.EXPECT().ListServers(ctx).Return(nil, nil)
+
+		decisions, err := serverService.EvaluateScaling(ctx, false)
+
+		Expect(err).To(BeNil())
+		Expect(decisions).To(BeEmpty())
+	})
+})
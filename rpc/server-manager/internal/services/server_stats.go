@@ -2,19 +2,46 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"go.uber.org/zap"
 )
 
+// statsDownsampleBucket интервал, до которого прореживаются старые замеры
+const statsDownsampleBucket = time.Hour
+
 // GetServerStats получает статистику сервера
 func (s *ServerService) GetServerStats(ctx context.Context, id string) (*domain.ServerStats, error) {
+	return s.serverStats(ctx, id, true)
+}
+
+// serverStats получает статистику сервера; save сохраняет актуальный замер
+// в историю
+func (s *ServerService) serverStats(ctx context.Context, id string, save bool) (*domain.ServerStats, error) {
 	// Проверяем существование сервера
-	if _, err := s.serverRepo.GetByID(ctx, id); err != nil {
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
 		return nil, err
 	}
 
+	// Актуальная статистика берется из оркестратора и сохраняется в историю
+	if server.OrchestratorHandle != "" {
+		stats, err := s.orchestrator.GetServerStats(ctx, server.OrchestratorHandle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get server stats: %w", err)
+		}
+		stats.ServerID = id
+		if save && s.statsRepo != nil {
+			if err := s.statsRepo.SaveStats(ctx, stats); err != nil {
+				s.logger.Warn("failed to save server stats", zap.String("server_id", id), zap.Error(err))
+			}
+		}
+		return stats, nil
+	}
+
 	// Получаем последнюю статистику из базы данных
 	if s.statsRepo == nil {
 		return &domain.ServerStats{
@@ -43,10 +70,21 @@ func (s *ServerService) GetServerStats(ctx context.Context, id string) (*domain.
 // GetServerHealth получает здоровье сервера
 func (s *ServerService) GetServerHealth(ctx context.Context, id string) (*domain.ServerHealth, error) {
 	// Проверяем существование сервера
-	if _, err := s.serverRepo.GetByID(ctx, id); err != nil {
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
 		return nil, err
 	}
 
+	// Состояние ресурса проверяется в оркестраторе
+	if server.OrchestratorHandle != "" {
+		health, err := s.orchestrator.GetServerHealth(ctx, server.OrchestratorHandle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get server health: %w", err)
+		}
+		health.ServerID = id
+		return health, nil
+	}
+
 	// Получаем данные о здоровье из базы данных
 	if s.healthRepo == nil {
 		return &domain.ServerHealth{
@@ -73,3 +111,69 @@ func (s *ServerService) GetAllServersHealth(ctx context.Context) ([]*domain.Serv
 	}
 	return s.healthRepo.GetAllHealth(ctx)
 }
+
+// CollectStats сохраняет в историю статистику и здоровье работающих
+// серверов. Ошибка одного сервера не прерывает сбор остальных
+func (s *ServerService) CollectStats(ctx context.Context) error {
+	if s.statsRepo == nil || s.healthRepo == nil {
+		return fmt.Errorf("stats repositories not initialized")
+	}
+
+	servers, err := s.serverRepo.GetByStatus(ctx, domain.ServerStatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to list running servers: %w", err)
+	}
+
+	var errs []error
+	for _, server := range servers {
+		if server.OrchestratorHandle == "" {
+			continue
+		}
+		if err := s.collectServerStats(ctx, server); err != nil {
+			errs = append(errs, fmt.Errorf("server %s: %w", server.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// collectServerStats сохраняет замер одного сервера. Недоступность
+// оркестратора записывается в историю здоровья как ошибка
+func (s *ServerService) collectServerStats(ctx context.Context, server *domain.Server) error {
+	health, err := s.orchestrator.GetServerHealth(ctx, server.OrchestratorHandle)
+	if err != nil {
+		health = &domain.ServerHealth{
+			Status:      domain.ServerStatusError,
+			Message:     err.Error(),
+			LastCheckAt: time.Now(),
+		}
+	}
+	health.ServerID = server.ID
+	if err := s.healthRepo.SaveHealth(ctx, health); err != nil {
+		return err
+	}
+
+	stats, err := s.orchestrator.GetServerStats(ctx, server.OrchestratorHandle)
+	if err != nil {
+		return fmt.Errorf("failed to get server stats: %w", err)
+	}
+	stats.ServerID = server.ID
+	return s.statsRepo.SaveStats(ctx, stats)
+}
+
+// CompactStats прореживает замеры старше rawRetention до средних за час и
+// удаляет статистику и историю здоровья старше retention
+func (s *ServerService) CompactStats(ctx context.Context, rawRetention, retention time.Duration) error {
+	if s.statsRepo == nil || s.healthRepo == nil {
+		return fmt.Errorf("stats repositories not initialized")
+	}
+
+	now := time.Now()
+	if err := s.statsRepo.DownsampleStats(ctx, now.Add(-rawRetention), statsDownsampleBucket); err != nil {
+		return err
+	}
+	if err := s.statsRepo.DeleteStatsBefore(ctx, now.Add(-retention)); err != nil {
+		return err
+	}
+	return s.healthRepo.DeleteHealthBefore(ctx, now.Add(-retention))
+}
@@ -0,0 +1,111 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Stats history", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var mockServerRepo *MockServerRepository
+	var mockStatsRepo *MockStatsRepository
+	var mockHealthRepo *MockHealthRepository
+	var mockOrchestrator *MockOrchestrator
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockStatsRepo = NewMockStatsRepository(ctrl)
+		mockHealthRepo = NewMockHealthRepository(ctrl)
+		mockOrchestrator = NewMockOrchestrator(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			StatsRepo:    mockStatsRepo,
+			HealthRepo:   mockHealthRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+	})
+
+	It("should sample every running server and keep going on errors", func() {
+		mockServerRepo.EXPECT().GetByStatus(ctx, domain.ServerStatusRunning).Return([]*domain.Server{
+			{ID: "server-1", OrchestratorHandle: "handle-1"},
+			{ID: "server-2", OrchestratorHandle: "handle-2"},
+			{ID: "server-3"},
+		}, nil)
+
+		mockOrchestrator.EXPECT().GetServerHealth(ctx, "handle-1").
+			Return(&domain.ServerHealth{ServerID: "handle-1", Status: domain.ServerStatusRunning}, nil)
+		mockOrchestrator.EXPECT().GetServerStats(ctx, "handle-1").
+			Return(&domain.ServerStats{ServerID: "handle-1", CPUUsage: 42}, nil)
+
+		// Недоступный контейнер попадает в историю здоровья как ошибка
+		mockOrchestrator.EXPECT().GetServerHealth(ctx, "handle-2").Return(nil, fmt.Errorf("no such container"))
+		mockOrchestrator.EXPECT().GetServerStats(ctx, "handle-2").Return(nil, fmt.Errorf("no such container"))
+
+		var saved []*domain.ServerHealth
+		mockHealthRepo.EXPECT().SaveHealth(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, health *domain.ServerHealth) error {
+				saved = append(saved, health)
+				return nil
+			}).Times(2)
+		mockStatsRepo.EXPECT().SaveStats(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, stats *domain.ServerStats) error {
+				Expect(stats.ServerID).To(Equal("server-1"))
+				Expect(stats.CPUUsage).To(Equal(42.0))
+				return nil
+			})
+
+		err := serverService.CollectStats(ctx)
+
+		Expect(err).To(MatchError(ContainSubstring("server server-2")))
+		Expect(saved).To(HaveLen(2))
+		Expect(saved[0].ServerID).To(Equal("server-1"))
+		Expect(saved[1].ServerID).To(Equal("server-2"))
+		Expect(saved[1].Status).To(Equal(domain.ServerStatusError))
+		Expect(saved[1].Message).To(ContainSubstring("no such container"))
+	})
+
+	It("should downsample raw samples and delete expired history", func() {
+		now := time.Now()
+		mockStatsRepo.EXPECT().DownsampleStats(ctx, gomock.Any(), time.Hour).
+			DoAndReturn(func(_ context.Context, before time.Time, _ time.Duration) error {
+				Expect(before).To(BeTemporally("~", now.Add(-24*time.Hour), time.Second))
+				return nil
+			})
+		mockStatsRepo.EXPECT().DeleteStatsBefore(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, before time.Time) error {
+				Expect(before).To(BeTemporally("~", now.Add(-30*24*time.Hour), time.Second))
+				return nil
+			})
+		mockHealthRepo.EXPECT().DeleteHealthBefore(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, before time.Time) error {
+				Expect(before).To(BeTemporally("~", now.Add(-30*24*time.Hour), time.Second))
+				return nil
+			})
+
+		Expect(serverService.CompactStats(ctx, 24*time.Hour, 30*24*time.Hour)).To(Succeed())
+	})
+
+	It("should require stats repositories", func() {
+		service := services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       zap.NewNop(),
+		})
+
+		Expect(service.CollectStats(ctx)).NotTo(Succeed())
+		Expect(service.CompactStats(ctx, time.Hour, time.Hour)).NotTo(Succeed())
+	})
+})
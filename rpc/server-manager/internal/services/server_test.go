@@ -2,6 +2,7 @@ package services_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -14,7 +15,7 @@ import (
 	"go.uber.org/zap"
 )
 
-//go:generate mockgen -destination=mock_server.go -package=services_test github.com/par1ram/silence/rpc/server-manager/internal/ports ServerRepository,StatsRepository,HealthRepository,ScalingRepository,BackupRepository,UpdateRepository
+//go:generate mockgen -destination=mock_server.go -package=services_test github.com/par1ram/silence/rpc/server-manager/internal/ports ServerRepository,StatsRepository,HealthRepository,ScalingRepository,BackupRepository,UpdateRepository,Orchestrator
 
 func TestServerService(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -31,6 +32,7 @@ var _ = Describe("ServerService", func() {
 	var mockScalingRepo *MockScalingRepository
 	var mockBackupRepo *MockBackupRepository
 	var mockUpdateRepo *MockUpdateRepository
+	var mockOrchestrator *MockOrchestrator
 	var ctrl *gomock.Controller
 
 	BeforeEach(func() {
@@ -41,17 +43,18 @@ var _ = Describe("ServerService", func() {
 		mockScalingRepo = NewMockScalingRepository(ctrl)
 		mockBackupRepo = NewMockBackupRepository(ctrl)
 		mockUpdateRepo = NewMockUpdateRepository(ctrl)
+		mockOrchestrator = NewMockOrchestrator(ctrl)
 		logger = zap.NewNop()
-		serverService = services.NewServerService(
-			mockServerRepo,
-			mockStatsRepo,
-			mockHealthRepo,
-			mockScalingRepo,
-			mockBackupRepo,
-			mockUpdateRepo,
-			nil, // docker adapter - will be mocked in tests that need it
-			logger,
-		).(*services.ServerService)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			StatsRepo:    mockStatsRepo,
+			HealthRepo:   mockHealthRepo,
+			ScalingRepo:  mockScalingRepo,
+			BackupRepo:   mockBackupRepo,
+			UpdateRepo:   mockUpdateRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       logger,
+		}).(*services.ServerService)
 		ctx = context.Background()
 	})
 
@@ -126,4 +129,139 @@ var _ = Describe("ServerService", func() {
 			Expect(len(servers)).To(Equal(0))
 		})
 	})
+
+	Describe("CreateServer", func() {
+		It("should create server in orchestrator and store handle", func() {
+			req := &domain.CreateServerRequest{
+				Name:   "vpn-1",
+				Type:   domain.ServerTypeVPN,
+				Region: "eu-west-1",
+				Config: map[string]string{"PORT": "51820"},
+			}
+
+			mockServerRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, server *domain.Server) error {
+				server.ID = "server-1"
+				return nil
+			})
+			mockOrchestrator.EXPECT().CreateServer(ctx, gomock.Any(), &domain.ServerSpec{
+				Image: "silence/vpn-core:latest",
+				Env:   map[string]string{"PORT": "51820"},
+			}).Return("silence-vpn-server-1", nil)
+			mockServerRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, server *domain.Server) error {
+				Expect(server.OrchestratorHandle).To(Equal("silence-vpn-server-1"))
+				Expect(server.Status).To(Equal(domain.ServerStatusRunning))
+				return nil
+			})
+
+			server, err := serverService.CreateServer(ctx, req)
+
+			Expect(err).To(BeNil())
+			Expect(server.OrchestratorHandle).To(Equal("silence-vpn-server-1"))
+		})
+
+		It("should mark server as failed when orchestrator fails", func() {
+			req := &domain.CreateServerRequest{Name: "vpn-1", Type: domain.ServerTypeVPN}
+
+			mockServerRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+			mockOrchestrator.EXPECT().CreateServer(ctx, gomock.Any(), gomock.Any()).Return("", fmt.Errorf("quota exceeded"))
+			mockServerRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, server *domain.Server) error {
+				Expect(server.Status).To(Equal(domain.ServerStatusError))
+				Expect(server.OrchestratorHandle).To(BeEmpty())
+				return nil
+			})
+
+			server, err := serverService.CreateServer(ctx, req)
+
+			Expect(err).NotTo(BeNil())
+			Expect(server).To(BeNil())
+		})
+	})
+
+	Describe("Lifecycle", func() {
+		var server *domain.Server
+
+		BeforeEach(func() {
+			server = &domain.Server{
+				ID:                 "server-1",
+				Type:               domain.ServerTypeVPN,
+				Status:             domain.ServerStatusRunning,
+				OrchestratorHandle: "handle-1",
+			}
+			mockServerRepo.EXPECT().GetByID(ctx, "server-1").Return(server, nil)
+		})
+
+		It("should stop server by handle", func() {
+			mockOrchestrator.EXPECT().StopServer(ctx, "handle-1").Return(nil)
+			mockServerRepo.EXPECT().Update(ctx, server).Return(nil)
+
+			Expect(serverService.StopServer(ctx, "server-1")).To(Succeed())
+			Expect(server.Status).To(Equal(domain.ServerStatusStopped))
+		})
+
+		It("should start server by handle", func() {
+			server.Status = domain.ServerStatusStopped
+			mockOrchestrator.EXPECT().StartServer(ctx, "handle-1").Return(nil)
+			mockServerRepo.EXPECT().Update(ctx, server).Return(nil)
+
+			Expect(serverService.StartServer(ctx, "server-1")).To(Succeed())
+			Expect(server.Status).To(Equal(domain.ServerStatusRunning))
+		})
+
+		It("should keep status when orchestrator fails to stop", func() {
+			mockOrchestrator.EXPECT().StopServer(ctx, "handle-1").Return(fmt.Errorf("timeout"))
+
+			Expect(serverService.StopServer(ctx, "server-1")).NotTo(Succeed())
+			Expect(server.Status).To(Equal(domain.ServerStatusRunning))
+		})
+
+		It("should fail without orchestrator handle", func() {
+			server.OrchestratorHandle = ""
+
+			Expect(serverService.StopServer(ctx, "server-1")).NotTo(Succeed())
+		})
+
+		It("should delete orchestrator resources before record", func() {
+			gomock.InOrder(
+				mockOrchestrator.EXPECT().DeleteServer(ctx, "handle-1").Return(nil),
+				mockServerRepo.EXPECT().Delete(ctx, "server-1").Return(nil),
+			)
+
+			Expect(serverService.DeleteServer(ctx, "server-1")).To(Succeed())
+		})
+
+		It("should scale server by handle", func() {
+			mockOrchestrator.EXPECT().ScaleServer(ctx, "handle-1", int32(3)).Return(nil)
+
+			Expect(serverService.ScaleServer(ctx, "server-1", 3)).To(Succeed())
+		})
+
+		It("should report unsupported scaling", func() {
+			mockOrchestrator.EXPECT().ScaleServer(ctx, "handle-1", int32(3)).Return(fmt.Errorf("docker: %w", domain.ErrNotSupported))
+
+			err := serverService.ScaleServer(ctx, "server-1", 3)
+
+			Expect(errors.Is(err, domain.ErrNotSupported)).To(BeTrue())
+		})
+
+		It("should read live stats from orchestrator", func() {
+			mockOrchestrator.EXPECT().GetServerStats(ctx, "handle-1").Return(&domain.ServerStats{ServerID: "handle-1", CPUUsage: 42}, nil)
+			mockStatsRepo.EXPECT().SaveStats(ctx, gomock.Any()).Return(nil)
+
+			stats, err := serverService.GetServerStats(ctx, "server-1")
+
+			Expect(err).To(BeNil())
+			Expect(stats.ServerID).To(Equal("server-1"))
+			Expect(stats.CPUUsage).To(Equal(42.0))
+		})
+
+		It("should read live health from orchestrator", func() {
+			mockOrchestrator.EXPECT().GetServerHealth(ctx, "handle-1").Return(&domain.ServerHealth{ServerID: "handle-1", Status: domain.ServerStatusStopped}, nil)
+
+			health, err := serverService.GetServerHealth(ctx, "server-1")
+
+			Expect(err).To(BeNil())
+			Expect(health.ServerID).To(Equal("server-1"))
+			Expect(health.Status).To(Equal(domain.ServerStatusStopped))
+		})
+	})
 })
@@ -2,12 +2,23 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
 	"go.uber.org/zap"
 )
 
+const (
+	// defaultUpdateHealthTimeout ожидание здоровья сервера после замены образа
+	defaultUpdateHealthTimeout = 5 * time.Minute
+	// updateHealthInterval период опроса здоровья во время обновления
+	updateHealthInterval = 2 * time.Second
+)
+
 // GetUpdateStatus получает статус обновления
 func (s *ServerService) GetUpdateStatus(ctx context.Context, serverID string) (*domain.UpdateStatus, error) {
 	if s.updateRepo == nil {
@@ -22,16 +33,472 @@ func (s *ServerService) GetUpdateStatus(ctx context.Context, serverID string) (*
 	return s.updateRepo.GetUpdateStatus(ctx, serverID)
 }
 
-// StartUpdate запускает обновление
+// StartUpdate запускает обновление сервера или группы серверов и
+// возвращается, не дожидаясь его окончания. Ход обновления каждого сервера
+// доступен через GetUpdateStatus
 func (s *ServerService) StartUpdate(ctx context.Context, req *domain.UpdateRequest) error {
-	// TODO: реализовать запуск обновления
-	s.logger.Info("starting update", zap.String("server_id", req.ServerID), zap.String("version", req.Version))
+	if req.Version == "" {
+		return fmt.Errorf("version is required")
+	}
+	if req.CanaryPercent < 0 || req.CanaryPercent > 100 {
+		return fmt.Errorf("invalid canary percent: %d", req.CanaryPercent)
+	}
+	if req.MaxUnavailable < 0 {
+		return fmt.Errorf("invalid max unavailable: %d", req.MaxUnavailable)
+	}
+
+	servers, err := s.updateTargets(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	s.updateMutex.Lock()
+	for _, server := range servers {
+		if _, ok := s.updates[server.ID]; ok {
+			s.updateMutex.Unlock()
+			return fmt.Errorf("update already in progress for server %s", server.ID)
+		}
+	}
+
+	// Обновление переживает завершение запроса, который его запустил
+	rolloutCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if s.updates == nil {
+		s.updates = make(map[string]context.CancelFunc)
+	}
+	for _, server := range servers {
+		s.updates[server.ID] = cancel
+	}
+	s.updateMutex.Unlock()
+
+	startedAt := time.Now()
+	for _, server := range servers {
+		s.saveUpdateStatus(newUpdateStatus(server.ID, domain.UpdateStatusPending, 0, "waiting for rollout", startedAt, false))
+	}
+
+	s.logger.Info("starting update",
+		zap.String("server_id", req.ServerID),
+		zap.String("server_type", string(req.ServerType)),
+		zap.String("version", req.Version),
+		zap.Int("servers", len(servers)))
+
+	go func() {
+		defer cancel()
+		defer s.finishRollout(servers)
+		s.runRollout(rolloutCtx, servers, req, startedAt)
+	}()
+
 	return nil
 }
 
-// CancelUpdate отменяет обновление
+// CancelUpdate отменяет обновление, в которое входит сервер. Серверы,
+// которые обновляются в момент отмены, откатываются, а ожидающие своей
+// партии не обновляются
 func (s *ServerService) CancelUpdate(ctx context.Context, serverID string) error {
-	// TODO: реализовать отмену обновления
+	s.updateMutex.Lock()
+	cancel, ok := s.updates[serverID]
+	s.updateMutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no update in progress for server %s", serverID)
+	}
+	cancel()
+
 	s.logger.Info("canceling update", zap.String("server_id", serverID))
 	return nil
 }
+
+// updateTargets выбирает серверы для обновления. Неработающие серверы
+// обновляются только с Force: одиночный сервер без него - ошибка, в группе
+// он пропускается
+func (s *ServerService) updateTargets(ctx context.Context, req *domain.UpdateRequest) ([]*domain.Server, error) {
+	if req.ServerID != "" {
+		if s.updateInProgress(req.ServerID) {
+			return nil, fmt.Errorf("update already in progress for server %s", req.ServerID)
+		}
+		server, err := s.serverRepo.GetByID(ctx, req.ServerID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := orchestratorHandle(server); err != nil {
+			return nil, err
+		}
+		if server.Status != domain.ServerStatusRunning && !req.Force {
+			return nil, fmt.Errorf("server %s is %s, use force to update it", server.ID, server.Status)
+		}
+		return []*domain.Server{server}, nil
+	}
+
+	if req.ServerType == "" {
+		return nil, fmt.Errorf("server id or server type is required")
+	}
+	filters := map[string]interface{}{"type": req.ServerType}
+	if req.Region != "" {
+		filters["region"] = req.Region
+	}
+	candidates, err := s.serverRepo.List(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	var servers []*domain.Server
+	for _, server := range candidates {
+		if server.OrchestratorHandle == "" {
+			continue
+		}
+		if server.Status != domain.ServerStatusRunning && !req.Force {
+			s.logger.Info("skipping server that is not running",
+				zap.String("server_id", server.ID), zap.String("status", string(server.Status)))
+			continue
+		}
+		servers = append(servers, server)
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no servers to update")
+	}
+	return servers, nil
+}
+
+// updatedServer сервер, обновленный в ходе выкатки, и его прежний образ
+type updatedServer struct {
+	server   *domain.Server
+	previous string
+}
+
+// runRollout обновляет серверы партиями. Если сервер не прошел проверку
+// здоровья, выкатка останавливается и уже обновленные серверы
+// откатываются на прежний образ
+func (s *ServerService) runRollout(ctx context.Context, servers []*domain.Server, req *domain.UpdateRequest, startedAt time.Time) {
+	timeout := req.HealthTimeout
+	if timeout <= 0 {
+		timeout = defaultUpdateHealthTimeout
+	}
+
+	var updated []updatedServer
+	next := 0
+	for _, size := range rolloutBatches(len(servers), req.CanaryPercent, req.MaxUnavailable) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		batch := servers[next : next+size]
+		next += size
+
+		errs := make([]error, len(batch))
+		previous := make([]string, len(batch))
+		var wg sync.WaitGroup
+		for i, server := range batch {
+			previous[i] = s.currentImage(server)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = s.updateServer(ctx, server, s.imageForVersion(server.Type, req.Version), timeout, startedAt)
+			}()
+		}
+		wg.Wait()
+
+		var failed error
+		for i, err := range errs {
+			if err == nil {
+				updated = append(updated, updatedServer{server: batch[i], previous: previous[i]})
+			} else if failed == nil && !errors.Is(err, context.Canceled) {
+				failed = fmt.Errorf("server %s: %w", batch[i].ID, err)
+			}
+		}
+
+		if failed != nil {
+			reason := fmt.Sprintf("rollout failed: %v", failed)
+			s.logger.Error("rollout failed, rolling back updated servers",
+				zap.Int("updated", len(updated)), zap.Error(failed))
+			for _, u := range updated {
+				s.revertServer(ctx, u.server, u.previous, timeout, startedAt, domain.UpdateStatusRolledBack, reason)
+			}
+			s.cancelPending(servers[next:], startedAt, reason)
+			return
+		}
+	}
+
+	if next < len(servers) {
+		s.cancelPending(servers[next:], startedAt, "update cancelled")
+		return
+	}
+
+	s.logger.Info("rollout completed", zap.Int("servers", len(updated)))
+}
+
+// rolloutBatches делит n серверов на партии: первая - canaryPercent
+// процентов серверов (не меньше одного), следующие - по maxUnavailable
+// (по умолчанию один сервер)
+func rolloutBatches(n, canaryPercent, maxUnavailable int) []int {
+	if maxUnavailable <= 0 {
+		maxUnavailable = 1
+	}
+
+	var sizes []int
+	if canaryPercent > 0 && n > 0 {
+		canary := max(n*canaryPercent/100, 1)
+		sizes = append(sizes, canary)
+		n -= canary
+	}
+	for n > 0 {
+		size := min(maxUnavailable, n)
+		sizes = append(sizes, size)
+		n -= size
+	}
+	return sizes
+}
+
+// updateServer переводит сервер на образ image и ждет его здоровья. Если
+// сервер не стал здоровым, он откатывается на прежний образ
+func (s *ServerService) updateServer(ctx context.Context, server *domain.Server, image string, timeout time.Duration, startedAt time.Time) error {
+	if ctx.Err() != nil {
+		s.saveUpdateStatus(newUpdateStatus(server.ID, domain.UpdateStatusCancelled, 0, "update cancelled", startedAt, true))
+		return ctx.Err()
+	}
+
+	previous := s.currentImage(server)
+	previousStatus := server.Status
+
+	s.saveUpdateStatus(newUpdateStatus(server.ID, domain.UpdateStatusInProgress, 10,
+		fmt.Sprintf("replacing %s with %s", previous, image), startedAt, false))
+	s.saveServer(server, serverStatus(domain.ServerStatusUpdating))
+
+	handle, err := s.orchestrator.UpdateServer(ctx, server.OrchestratorHandle, image)
+	if err != nil {
+		// Оркестратор оставил сервер на прежнем образе
+		s.saveServer(server, serverStatus(previousStatus))
+		if errors.Is(err, context.Canceled) {
+			s.saveUpdateStatus(newUpdateStatus(server.ID, domain.UpdateStatusCancelled, 10, "update cancelled", startedAt, true))
+		} else {
+			s.completeUpdate(server.ID, false, fmt.Sprintf("failed to update: %v", err))
+		}
+		return err
+	}
+	s.saveServer(server, func(server *domain.Server) {
+		server.OrchestratorHandle = handle
+		server.Image = image
+	})
+
+	// Остановленный сервер не запускается, проверять нечего
+	if previousStatus == domain.ServerStatusRunning {
+		s.reportProgress(server.ID, 50, "waiting for health checks")
+		if err := s.waitHealthy(ctx, handle, timeout); err != nil {
+			server.Status = previousStatus
+			if errors.Is(err, context.Canceled) {
+				s.revertServer(ctx, server, previous, timeout, startedAt, domain.UpdateStatusCancelled, "update cancelled")
+			} else {
+				s.revertServer(ctx, server, previous, timeout, startedAt, domain.UpdateStatusRolledBack,
+					fmt.Sprintf("health check failed: %v", err))
+			}
+			return err
+		}
+	}
+
+	s.saveServer(server, serverStatus(previousStatus))
+	s.completeUpdate(server.ID, true, fmt.Sprintf("updated to %s", image))
+
+	s.logger.Info("server updated",
+		zap.String("server_id", server.ID),
+		zap.String("image", image),
+		zap.String("handle", handle))
+	return nil
+}
+
+// revertServer возвращает сервер на образ previous и завершает его
+// обновление со статусом status. Здоровье проверяется, если сервер в
+// статусе running. Откат выполняется и после отмены обновления
+func (s *ServerService) revertServer(ctx context.Context, server *domain.Server, previous string, timeout time.Duration, startedAt time.Time, status, reason string) {
+	ctx = context.WithoutCancel(ctx)
+	previousStatus := server.Status
+	s.saveUpdateStatus(newUpdateStatus(server.ID, domain.UpdateStatusInProgress, 75,
+		fmt.Sprintf("%s, rolling back to %s", reason, previous), startedAt, false))
+
+	// Без отката сервер остается на образе и handle обновления
+	handle, image := server.OrchestratorHandle, server.Image
+	newHandle, err := s.orchestrator.UpdateServer(ctx, server.OrchestratorHandle, previous)
+	if err == nil {
+		handle, image = newHandle, previous
+		if previousStatus == domain.ServerStatusRunning {
+			err = s.waitHealthy(ctx, handle, timeout)
+		}
+	}
+	if err != nil {
+		s.logger.Error("failed to roll back server", zap.String("server_id", server.ID), zap.Error(err))
+		s.saveServer(server, func(server *domain.Server) {
+			server.OrchestratorHandle = handle
+			server.Image = image
+			server.Status = domain.ServerStatusError
+		})
+		s.completeUpdate(server.ID, false, fmt.Sprintf("%s, rollback failed: %v", reason, err))
+		return
+	}
+
+	s.saveServer(server, func(server *domain.Server) {
+		server.OrchestratorHandle = handle
+		server.Image = image
+		server.Status = previousStatus
+	})
+	s.saveUpdateStatus(newUpdateStatus(server.ID, status, 100,
+		fmt.Sprintf("%s, rolled back to %s", reason, previous), startedAt, true))
+
+	s.logger.Warn("server rolled back",
+		zap.String("server_id", server.ID),
+		zap.String("image", previous),
+		zap.String("reason", reason))
+}
+
+// waitHealthy ждет статуса running. Ошибка или остановка сервера прерывают
+// ожидание сразу, creating и updating ожидаются до timeout
+func (s *ServerService) waitHealthy(ctx context.Context, handle string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(updateHealthInterval)
+	defer ticker.Stop()
+
+	var last error
+	for {
+		health, err := s.orchestrator.GetServerHealth(ctx, handle)
+		switch {
+		case err != nil:
+			last = err
+		case health.Status == domain.ServerStatusRunning:
+			return nil
+		case health.Status == domain.ServerStatusCreating, health.Status == domain.ServerStatusUpdating:
+			last = fmt.Errorf("server is %s: %s", health.Status, health.Message)
+		default:
+			return fmt.Errorf("server is %s: %s", health.Status, health.Message)
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && last != nil {
+				return fmt.Errorf("server is not healthy after %s: %w", timeout, last)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// cancelPending отмечает отмененными серверы, до которых не дошла выкатка
+func (s *ServerService) cancelPending(servers []*domain.Server, startedAt time.Time, message string) {
+	for _, server := range servers {
+		s.saveUpdateStatus(newUpdateStatus(server.ID, domain.UpdateStatusCancelled, 0, message, startedAt, true))
+	}
+}
+
+// updateInProgress сообщает, что сервер входит в выполняемое обновление
+func (s *ServerService) updateInProgress(serverID string) bool {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+
+	_, ok := s.updates[serverID]
+	return ok
+}
+
+// finishRollout снимает регистрацию выкатки
+func (s *ServerService) finishRollout(servers []*domain.Server) {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+
+	for _, server := range servers {
+		delete(s.updates, server.ID)
+	}
+}
+
+// imageForVersion образ сервера версии version: тег образа типа сервера
+// или полная ссылка на образ, если version ее содержит
+func (s *ServerService) imageForVersion(serverType domain.ServerType, version string) string {
+	if strings.ContainsAny(version, "/:@") {
+		return version
+	}
+	image := s.getImageForServerType(serverType)
+	if i := strings.LastIndex(image, ":"); i >= 0 {
+		image = image[:i]
+	}
+	return image + ":" + version
+}
+
+// currentImage образ сервера. Серверы, созданные до учета образов,
+// работают на образе по умолчанию для своего типа
+func (s *ServerService) currentImage(server *domain.Server) string {
+	if server.Image != "" {
+		return server.Image
+	}
+	return s.getImageForServerType(server.Type)
+}
+
+// saveServer применяет change к серверу во время обновления или
+// подготовки. Строка сервера перечитывается, чтобы не затереть изменения,
+// сделанные за время операции, например переименование
+func (s *ServerService) saveServer(server *domain.Server, change func(server *domain.Server)) {
+	change(server)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, err := s.serverRepo.GetByID(context.Background(), server.ID)
+	if err != nil {
+		s.logger.Error("failed to get server", zap.String("server_id", server.ID), zap.Error(err))
+		return
+	}
+	change(current)
+	if err := s.serverRepo.Update(context.Background(), current); err != nil {
+		s.logger.Error("failed to save server", zap.String("server_id", server.ID), zap.Error(err))
+	}
+	s.publishStatus(current)
+}
+
+// serverStatus изменение статуса сервера для saveServer
+func serverStatus(status domain.ServerStatus) func(server *domain.Server) {
+	return func(server *domain.Server) {
+		server.Status = status
+	}
+}
+
+// newUpdateStatus статус обновления сервера, completed - с временем
+// завершения
+func newUpdateStatus(serverID, status string, progress int, message string, startedAt time.Time, completed bool) *domain.UpdateStatus {
+	update := &domain.UpdateStatus{
+		ServerID:  serverID,
+		Status:    status,
+		Progress:  progress,
+		Message:   message,
+		StartedAt: startedAt,
+	}
+	if completed {
+		now := time.Now()
+		update.CompletedAt = &now
+	}
+	return update
+}
+
+// saveUpdateStatus сохраняет статус обновления, если есть репозиторий
+func (s *ServerService) saveUpdateStatus(status *domain.UpdateStatus) {
+	if s.updateRepo == nil {
+		return
+	}
+	if err := s.updateRepo.SaveUpdateStatus(context.Background(), status); err != nil {
+		s.logger.Error("failed to save update status", zap.String("server_id", status.ServerID), zap.Error(err))
+	}
+}
+
+// reportProgress сохраняет прогресс обновления сервера
+func (s *ServerService) reportProgress(serverID string, progress int, message string) {
+	if s.updateRepo == nil {
+		return
+	}
+	if err := s.updateRepo.UpdateProgress(context.Background(), serverID, progress, message); err != nil {
+		s.logger.Error("failed to report update progress", zap.String("server_id", serverID), zap.Error(err))
+	}
+}
+
+// completeUpdate завершает обновление сервера со статусом completed или failed
+func (s *ServerService) completeUpdate(serverID string, success bool, message string) {
+	if s.updateRepo == nil {
+		return
+	}
+	if err := s.updateRepo.CompleteUpdate(context.Background(), serverID, success, message); err != nil {
+		s.logger.Error("failed to complete update", zap.String("server_id", serverID), zap.Error(err))
+	}
+}
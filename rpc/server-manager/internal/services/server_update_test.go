@@ -0,0 +1,361 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/par1ram/silence/rpc/server-manager/internal/domain"
+	"github.com/par1ram/silence/rpc/server-manager/internal/services"
+	. "github.com/par1ram/silence/rpc/server-manager/internal/services/mocks"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Software updates", func() {
+	var serverService *services.ServerService
+	var ctx context.Context
+	var mockServerRepo *MockServerRepository
+	var mockUpdateRepo *MockUpdateRepository
+	var mockOrchestrator *MockOrchestrator
+
+	// Статусы обновления и сохраненные серверы по ID
+	var mu sync.Mutex
+	var statuses map[string]domain.UpdateStatus
+	var saved map[string]domain.Server
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockServerRepo = NewMockServerRepository(ctrl)
+		mockUpdateRepo = NewMockUpdateRepository(ctrl)
+		mockOrchestrator = NewMockOrchestrator(ctrl)
+		serverService = services.NewServerService(services.ServerServiceOptions{
+			ServerRepo:   mockServerRepo,
+			UpdateRepo:   mockUpdateRepo,
+			Orchestrator: mockOrchestrator,
+			Logger:       zap.NewNop(),
+		}).(*services.ServerService)
+		ctx = context.Background()
+
+		statuses = make(map[string]domain.UpdateStatus)
+		saved = make(map[string]domain.Server)
+
+		mockUpdateRepo.EXPECT().SaveUpdateStatus(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, status *domain.UpdateStatus) error {
+				mu.Lock()
+				defer mu.Unlock()
+				statuses[status.ServerID] = *status
+				return nil
+			}).AnyTimes()
+		mockUpdateRepo.EXPECT().UpdateProgress(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, serverID string, progress int, message string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				status := statuses[serverID]
+				status.Progress, status.Message = progress, message
+				statuses[serverID] = status
+				return nil
+			}).AnyTimes()
+		mockUpdateRepo.EXPECT().CompleteUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, serverID string, success bool, message string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				status := statuses[serverID]
+				status.Status, status.Message = domain.UpdateStatusFailed, message
+				if success {
+					status.Status, status.Progress = domain.UpdateStatusCompleted, 100
+				}
+				now := time.Now()
+				status.CompletedAt = &now
+				statuses[serverID] = status
+				return nil
+			}).AnyTimes()
+		mockServerRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, server *domain.Server) error {
+				mu.Lock()
+				defer mu.Unlock()
+				saved[server.ID] = *server
+				return nil
+			}).AnyTimes()
+	})
+
+	statusOf := func(serverID string) func() string {
+		return func() string {
+			mu.Lock()
+			defer mu.Unlock()
+			return statuses[serverID].Status
+		}
+	}
+	savedServer := func(serverID string) domain.Server {
+		mu.Lock()
+		defer mu.Unlock()
+		return saved[serverID]
+	}
+
+	newServer := func(id string) *domain.Server {
+		return &domain.Server{
+			ID:                 id,
+			Type:               domain.ServerTypeVPN,
+			Status:             domain.ServerStatusRunning,
+			Region:             "eu-west-1",
+			OrchestratorHandle: id + "-v1",
+			Image:              "silence/vpn-core:1.0.0",
+		}
+	}
+
+	// expectRows отдает строки серверов: последнюю сохраненную, а до
+	// сохранения - исходную, как при чтении из базы
+	expectRows := func(servers ...*domain.Server) {
+		for _, server := range servers {
+			mockServerRepo.EXPECT().GetByID(gomock.Any(), server.ID).
+				DoAndReturn(func(context.Context, string) (*domain.Server, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					row, ok := saved[server.ID]
+					if !ok {
+						row = *server
+					}
+					return &row, nil
+				}).AnyTimes()
+		}
+	}
+
+	// expectHealth отвечает статусом status на проверки handle
+	expectHealth := func(handle string, status domain.ServerStatus) {
+		mockOrchestrator.EXPECT().GetServerHealth(gomock.Any(), handle).
+			Return(&domain.ServerHealth{ServerID: handle, Status: status}, nil).AnyTimes()
+	}
+
+	Describe("single server", func() {
+		var server *domain.Server
+
+		BeforeEach(func() {
+			server = newServer("server-1")
+			expectRows(server)
+		})
+
+		It("replaces the image and completes after health checks pass", func() {
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v1", "silence/vpn-core:1.1.0").
+				Return("server-1-v2", nil)
+			expectHealth("server-1-v2", domain.ServerStatusRunning)
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{ServerID: "server-1", Version: "1.1.0"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(statusOf("server-1")).Should(Equal(domain.UpdateStatusCompleted))
+			updated := savedServer("server-1")
+			Expect(updated.OrchestratorHandle).To(Equal("server-1-v2"))
+			Expect(updated.Image).To(Equal("silence/vpn-core:1.1.0"))
+			Expect(updated.Status).To(Equal(domain.ServerStatusRunning))
+		})
+
+		It("keeps changes made to the server during the update", func() {
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v1", "silence/vpn-core:1.1.0").
+				DoAndReturn(func(context.Context, string, string) (string, error) {
+					// Переименование, пока заменяется контейнер
+					mu.Lock()
+					defer mu.Unlock()
+					row := saved["server-1"]
+					row.Name = "eu-vpn-renamed"
+					saved["server-1"] = row
+					return "server-1-v2", nil
+				})
+			expectHealth("server-1-v2", domain.ServerStatusRunning)
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{ServerID: "server-1", Version: "1.1.0"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(statusOf("server-1")).Should(Equal(domain.UpdateStatusCompleted))
+			updated := savedServer("server-1")
+			Expect(updated.Name).To(Equal("eu-vpn-renamed"))
+			Expect(updated.OrchestratorHandle).To(Equal("server-1-v2"))
+			Expect(updated.Status).To(Equal(domain.ServerStatusRunning))
+		})
+
+		It("rolls back when the new version is unhealthy", func() {
+			gomock.InOrder(
+				mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v1", "registry.local/vpn:2.0.0").
+					Return("server-1-v2", nil),
+				mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v2", "silence/vpn-core:1.0.0").
+					Return("server-1-v3", nil),
+			)
+			expectHealth("server-1-v2", domain.ServerStatusError)
+			expectHealth("server-1-v3", domain.ServerStatusRunning)
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{ServerID: "server-1", Version: "registry.local/vpn:2.0.0"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(statusOf("server-1")).Should(Equal(domain.UpdateStatusRolledBack))
+			updated := savedServer("server-1")
+			Expect(updated.OrchestratorHandle).To(Equal("server-1-v3"))
+			Expect(updated.Image).To(Equal("silence/vpn-core:1.0.0"))
+			Expect(updated.Status).To(Equal(domain.ServerStatusRunning))
+		})
+
+		It("fails without rollback when the orchestrator keeps the old image", func() {
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v1", "silence/vpn-core:1.1.0").
+				Return("", fmt.Errorf("failed to pull image: manifest unknown"))
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{ServerID: "server-1", Version: "1.1.0"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(statusOf("server-1")).Should(Equal(domain.UpdateStatusFailed))
+			Expect(savedServer("server-1").OrchestratorHandle).To(Equal("server-1-v1"))
+			Expect(savedServer("server-1").Status).To(Equal(domain.ServerStatusRunning))
+		})
+
+		It("rejects a stopped server without force", func() {
+			server.Status = domain.ServerStatusStopped
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{ServerID: "server-1", Version: "1.1.0"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects a second update and cancels the running one", func() {
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v1", "silence/vpn-core:1.1.0").
+				Return("server-1-v2", nil)
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v2", "silence/vpn-core:1.0.0").
+				Return("server-1-v3", nil)
+			expectHealth("server-1-v2", domain.ServerStatusUpdating)
+			expectHealth("server-1-v3", domain.ServerStatusRunning)
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{ServerID: "server-1", Version: "1.1.0"})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() int {
+				mu.Lock()
+				defer mu.Unlock()
+				return statuses["server-1"].Progress
+			}).Should(Equal(50))
+
+			err = serverService.StartUpdate(ctx, &domain.UpdateRequest{ServerID: "server-1", Version: "1.2.0"})
+			Expect(err).To(MatchError(ContainSubstring("already in progress")))
+
+			Expect(serverService.CancelUpdate(ctx, "server-1")).To(Succeed())
+			Eventually(statusOf("server-1")).Should(Equal(domain.UpdateStatusCancelled))
+			Expect(savedServer("server-1").Image).To(Equal("silence/vpn-core:1.0.0"))
+
+			Eventually(func() error {
+				return serverService.CancelUpdate(ctx, "server-1")
+			}).Should(HaveOccurred())
+		})
+	})
+
+	Describe("fleet rollout", func() {
+		var servers []*domain.Server
+
+		BeforeEach(func() {
+			servers = nil
+			for i := 1; i <= 4; i++ {
+				servers = append(servers, newServer(fmt.Sprintf("server-%d", i)))
+			}
+			expectRows(servers...)
+		})
+
+		expectFleet := func() {
+			mockServerRepo.EXPECT().List(ctx, map[string]interface{}{"type": domain.ServerTypeVPN, "region": "eu-west-1"}).
+				Return(servers, nil)
+		}
+
+		It("updates a canary first and then batches of max unavailable", func() {
+			expectFleet()
+
+			var inFlight, maxInFlight int
+			var order []string
+			for _, server := range servers {
+				handle := server.ID + "-v2"
+				mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), server.OrchestratorHandle, "silence/vpn-core:1.1.0").
+					DoAndReturn(func(context.Context, string, string) (string, error) {
+						mu.Lock()
+						inFlight++
+						maxInFlight = max(maxInFlight, inFlight)
+						order = append(order, server.ID)
+						mu.Unlock()
+
+						time.Sleep(20 * time.Millisecond)
+
+						mu.Lock()
+						inFlight--
+						mu.Unlock()
+						return handle, nil
+					})
+				expectHealth(handle, domain.ServerStatusRunning)
+			}
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{
+				ServerType:     domain.ServerTypeVPN,
+				Region:         "eu-west-1",
+				Version:        "1.1.0",
+				CanaryPercent:  25,
+				MaxUnavailable: 2,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, server := range servers {
+				Eventually(statusOf(server.ID)).Should(Equal(domain.UpdateStatusCompleted))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(order[0]).To(Equal("server-1"))
+			Expect(order[3]).To(Equal("server-4"))
+			Expect(maxInFlight).To(Equal(2))
+		})
+
+		It("halts and rolls back updated servers when a server fails", func() {
+			expectFleet()
+
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v1", "silence/vpn-core:1.1.0").Return("server-1-v2", nil)
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-2-v1", "silence/vpn-core:1.1.0").Return("server-2-v2", nil)
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-2-v2", "silence/vpn-core:1.0.0").Return("server-2-v3", nil)
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v2", "silence/vpn-core:1.0.0").Return("server-1-v3", nil)
+			expectHealth("server-1-v2", domain.ServerStatusRunning)
+			expectHealth("server-2-v2", domain.ServerStatusStopped)
+			expectHealth("server-1-v3", domain.ServerStatusRunning)
+			expectHealth("server-2-v3", domain.ServerStatusRunning)
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{
+				ServerType: domain.ServerTypeVPN,
+				Region:     "eu-west-1",
+				Version:    "1.1.0",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(statusOf("server-4")).Should(Equal(domain.UpdateStatusCancelled))
+			Expect(statusOf("server-1")()).To(Equal(domain.UpdateStatusRolledBack))
+			Expect(statusOf("server-2")()).To(Equal(domain.UpdateStatusRolledBack))
+			Expect(statusOf("server-3")()).To(Equal(domain.UpdateStatusCancelled))
+			Expect(savedServer("server-1").Image).To(Equal("silence/vpn-core:1.0.0"))
+			Expect(savedServer("server-1").OrchestratorHandle).To(Equal("server-1-v3"))
+		})
+
+		It("skips servers that are not running", func() {
+			servers[1].Status = domain.ServerStatusError
+			servers = servers[:2]
+			expectFleet()
+
+			mockOrchestrator.EXPECT().UpdateServer(gomock.Any(), "server-1-v1", "silence/vpn-core:1.1.0").Return("server-1-v2", nil)
+			expectHealth("server-1-v2", domain.ServerStatusRunning)
+
+			err := serverService.StartUpdate(ctx, &domain.UpdateRequest{
+				ServerType: domain.ServerTypeVPN,
+				Region:     "eu-west-1",
+				Version:    "1.1.0",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(statusOf("server-1")).Should(Equal(domain.UpdateStatusCompleted))
+			Expect(statusOf("server-2")()).To(BeEmpty())
+		})
+	})
+
+	It("validates the request", func() {
+		Expect(serverService.StartUpdate(ctx, &domain.UpdateRequest{ServerID: "server-1"})).To(HaveOccurred())
+		Expect(serverService.StartUpdate(ctx, &domain.UpdateRequest{Version: "1.1.0"})).To(HaveOccurred())
+		Expect(serverService.StartUpdate(ctx, &domain.UpdateRequest{
+			ServerType: domain.ServerTypeVPN, Version: "1.1.0", CanaryPercent: 150,
+		})).To(HaveOccurred())
+		Expect(serverService.CancelUpdate(ctx, "server-1")).To(HaveOccurred())
+	})
+})
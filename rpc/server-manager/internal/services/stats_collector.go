@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/par1ram/silence/rpc/server-manager/internal/ports"
+	"go.uber.org/zap"
+)
+
+// statsCompactInterval период прореживания и удаления старой статистики
+const statsCompactInterval = time.Hour
+
+// StatsCollector периодически сохраняет статистику и здоровье работающих
+// серверов и ограничивает размер истории
+type StatsCollector struct {
+	*periodic
+	service      ports.ServerService
+	rawRetention time.Duration
+	retention    time.Duration
+	lastCompact  time.Time
+}
+
+// NewStatsCollector создает сборщик статистики. Исходные замеры хранятся
+// rawRetention, затем усредняются по часам; история старше retention
+// удаляется
+func NewStatsCollector(service ports.ServerService, interval, rawRetention, retention time.Duration, logger *zap.Logger) *StatsCollector {
+	c := &StatsCollector{
+		periodic: newPeriodic("stats collector", interval, logger,
+			zap.Duration("raw_retention", rawRetention),
+			zap.Duration("retention", retention)),
+		service:      service,
+		rawRetention: rawRetention,
+		retention:    retention,
+	}
+	// Прореживание при запуске учитывает время простоя сервиса
+	c.start = c.compact
+	c.tick = c.collect
+	return c
+}
+
+// collect выполняет один сбор, ограниченный интервалом цикла, и раз в
+// statsCompactInterval прореживает историю
+func (c *StatsCollector) collect(ctx context.Context) {
+	collectCtx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if err := c.service.CollectStats(collectCtx); err != nil {
+		c.logger.Error("stats collection failed", zap.Error(err))
+	}
+
+	if time.Since(c.lastCompact) >= statsCompactInterval {
+		c.compact(ctx)
+	}
+}
+
+// compact прореживает и удаляет старую историю
+func (c *StatsCollector) compact(ctx context.Context) {
+	if err := c.service.CompactStats(ctx, c.rawRetention, c.retention); err != nil {
+		c.logger.Error("stats compaction failed", zap.Error(err))
+	}
+	c.lastCompact = time.Now()
+}